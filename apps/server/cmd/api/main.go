@@ -4,17 +4,23 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sync/atomic"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/redis/go-redis/v9"
 	echoSwagger "github.com/swaggo/echo-swagger"
 	"github.com/what-cse/server/internal/config"
+	"github.com/what-cse/server/internal/crawler"
 	"github.com/what-cse/server/internal/database"
 	"github.com/what-cse/server/internal/handler"
 	customMiddleware "github.com/what-cse/server/internal/middleware"
+	"github.com/what-cse/server/internal/parser"
 	"github.com/what-cse/server/internal/repository"
+	"github.com/what-cse/server/internal/scheduler"
 	"github.com/what-cse/server/internal/service"
+	"github.com/what-cse/server/internal/storage"
 	"github.com/what-cse/server/pkg/logger"
 
 	_ "github.com/what-cse/server/docs"
@@ -49,6 +55,11 @@ func main() {
 	}
 	log.Info("Database migrations completed")
 
+	// FULLTEXT (ngram) indexes used by the MySQL search backend
+	if err := database.CreateFullTextIndexes(db); err != nil {
+		log.Warn(fmt.Sprintf("Failed to create full-text indexes: %v", err))
+	}
+
 	// Seed Fenbi categories if not present
 	if err := database.SeedFenbiCategories(db); err != nil {
 		log.Warn(fmt.Sprintf("Failed to seed Fenbi categories: %v", err))
@@ -85,6 +96,7 @@ func main() {
 	notificationRepo := repository.NewNotificationRepository(db)
 	adminRepo := repository.NewAdminRepository(db)
 	listPageRepo := repository.NewListPageRepository(db)
+	crawlURLRepo := repository.NewCrawlURLRepository(db)
 
 	// Fenbi repositories
 	fenbiCredRepo := repository.NewFenbiCredentialRepository(db)
@@ -107,6 +119,12 @@ func main() {
 	// Position history repository
 	positionHistoryRepo := repository.NewPositionHistoryRepository(db)
 
+	// Recruitment campaign repository
+	recruitmentCampaignRepo := repository.NewRecruitmentCampaignRepository(db)
+
+	// Registration data repository
+	registrationDataRepo := repository.NewRegistrationDataRepository(db)
+
 	// Exam tools repositories
 	examLocationRepo := repository.NewExamLocationRepository(db)
 	scoreEstimateRepo := repository.NewScoreEstimateRepository(db)
@@ -187,6 +205,20 @@ func main() {
 	// Learning content repository (学习内容通用API)
 	learningContentRepo := repository.NewLearningContentRepository(db)
 
+	// Search outbox repository (搜索索引同步)
+	searchOutboxRepo := repository.NewSearchOutboxRepository(db)
+
+	// Learning search repository (学习内容统一搜索)
+	learningSearchRepo := repository.NewLearningSearchRepository(db)
+
+	// Content embedding repository (相似题检索)
+	contentEmbeddingRepo := repository.NewContentEmbeddingRepository(db)
+	questionDedupRepo := repository.NewQuestionDedupRepository(db)
+	paperImportRepo := repository.NewPaperImportRepository(db)
+	irtRepo := repository.NewIRTRepository(db)
+	knowledgeTracingRepo := repository.NewKnowledgeTracingRepository(db)
+	reviewRepo := repository.NewReviewRepository(db)
+
 	// ============================================
 	// Initialize Services
 	// ============================================
@@ -197,7 +229,14 @@ func main() {
 	announcementService := service.NewAnnouncementService(announcementRepo)
 	notificationService := service.NewNotificationService(notificationRepo)
 	adminService := service.NewAdminService(adminRepo, userRepo, positionRepo, &cfg.JWT)
+	if cfg.Crawler.RecordDir != "" {
+		if err := crawler.SetRecordDir(cfg.Crawler.RecordDir); err != nil {
+			log.Fatal(fmt.Sprintf("Failed to enable crawler recording: %v", err))
+		}
+		log.Info(fmt.Sprintf("Recording crawler traffic to %s", cfg.Crawler.RecordDir))
+	}
 	crawlerService := service.NewCrawlerServiceSimple(listPageRepo)
+	crawlerService.SetSpider(spiderConfig(&cfg.Crawler), crawlURLRepo, log.Logger)
 	favoriteService := service.NewFavoriteService(favoriteRepo, positionRepo)
 	subscriptionService := service.NewSubscriptionService(subscriptionRepo)
 
@@ -213,6 +252,10 @@ func main() {
 	// Position history service
 	positionHistoryService := service.NewPositionHistoryService(positionHistoryRepo, positionRepo)
 
+	// Registration data and competition forecast services
+	registrationDataService := service.NewRegistrationDataService(registrationDataRepo, positionRepo)
+	registrationForecastService := service.NewRegistrationForecastService(registrationDataRepo, positionRepo, positionHistoryRepo)
+
 	// Major service
 	majorService := service.NewMajorService(majorRepo)
 
@@ -231,11 +274,16 @@ func main() {
 		userCourseProgressRepo,
 	)
 
+	// Admission probability service (上岸概率)
+	admissionService := service.NewAdmissionService(positionRepo, favoriteRepo, positionHistoryRepo, positionHistoryService, learningStatsService, registrationForecastService)
+	admissionService.SetMatchService(matchService)
+	matchService.SetAdmissionService(admissionService)
+
 	// Study tools services
 	studyPlanService := service.NewStudyPlanService(studyPlanRepo)
 	studyTimeService := service.NewStudyTimeService(studyTimeRepo)
 	learningFavoriteService := service.NewLearningFavoriteService(learningFavoriteRepo)
-	knowledgeMasteryService := service.NewKnowledgeMasteryService(knowledgeMasteryRepo)
+	knowledgeMasteryService := service.NewKnowledgeMasteryService(knowledgeMasteryRepo, knowledgeTracingRepo, cfg.Knowledge, log.Logger)
 
 	// Daily practice service
 	dailyPracticeService := service.NewDailyPracticeService(db, dailyPracticeRepo, userDailyStreakRepo, userWeakCategoryRepo, questionRepo, questionRecordRepo)
@@ -249,6 +297,10 @@ func main() {
 	// Study note and wrong question service (错题本与笔记)
 	studyNoteService := service.NewStudyNoteService(wrongQuestionRepo, studyNoteRepo, noteLikeRepo, questionRepo)
 
+	// Spaced-repetition review scheduler (速记卡片与错题统一复习)
+	reviewSchedulerService := service.NewReviewSchedulerService(reviewRepo, cfg.Review, log.Logger)
+	studyNoteService.SetReviewScheduler(reviewSchedulerService)
+
 	// Learning material service (素材库 §25.4)
 	materialService := service.NewMaterialService(materialRepo, materialCategoryRepo)
 
@@ -258,6 +310,9 @@ func main() {
 	// Knowledge content services (知识点内容生成 §25.3)
 	knowledgeDetailService := service.NewKnowledgeDetailService(knowledgeDetailRepo)
 	flashCardService := service.NewFlashCardService(flashCardRepo, userFlashCardRecordRepo)
+	flashCardService.SetReviewScheduler(reviewSchedulerService)
+	flashCardDeckService := service.NewFlashCardDeckService(flashCardRepo, userFlashCardRecordRepo, reviewRepo, log.Logger)
+	flashCardDeckService.SetReviewScheduler(reviewSchedulerService)
 	mindMapService := service.NewMindMapService(mindMapRepo)
 	knowledgeContentService := service.NewKnowledgeContentService(knowledgeDetailService, flashCardService, mindMapService, knowledgeContentStatsRepo)
 
@@ -272,11 +327,56 @@ func main() {
 	// Learning content service (学习内容通用API)
 	learningContentService := service.NewLearningContentService(learningContentRepo)
 
+	// Embedding service (语义相似题 / 重复检测 / 专项练习选题)
+	embeddingService := service.NewEmbeddingService(contentEmbeddingRepo, llmConfigService, cfg.Embedding, log.Logger)
+	studyNoteService.SetEmbeddingService(embeddingService)
+	aiWeaknessService.SetEmbeddingService(embeddingService)
+
+	// Question dedup service (MinHash/LSH 题库近似重复检测)
+	questionDedupService := service.NewQuestionDedupService(questionDedupRepo, questionRepo, cfg.QuestionDedup, log.Logger)
+	questionService.SetDedupService(questionDedupService)
+
+	// IRT service (题目 2PL 标定 / 在线能力估计 / 自适应练习)
+	irtService := service.NewIRTService(irtRepo, questionRepo, courseCategoryRepo, cfg.IRT, log.Logger)
+	questionService.SetIRTService(irtService)
+	dailyPracticeService.SetIRTService(irtService)
+	practiceSessionService.SetIRTService(irtService)
+
+	// Announcement linker (招录批次关联 / 进面分数线、拟录用结果写入历年数据)
+	announcementLinkerService := service.NewAnnouncementLinkerService(db, announcementRepo, recruitmentCampaignRepo, positionRepo, positionHistoryRepo, cfg.AnnouncementLink, log.Logger)
+	announcementLinkerService.SetLLMConfigService(llmConfigService)
+
+	// Knowledge tracing (BKT 知识点掌握度，所有练习入口作答后更新)
+	questionService.SetKnowledgeMasteryService(knowledgeMasteryService)
+	dailyPracticeService.SetKnowledgeMasteryService(knowledgeMasteryService)
+	practiceSessionService.SetKnowledgeMasteryService(knowledgeMasteryService)
+	aiWeaknessService.SetKnowledgeMasteryService(knowledgeMasteryService)
+	aiLearningPathService.SetKnowledgeMasteryService(knowledgeMasteryService)
+
+	// Paper import service (Word/PDF 真题试卷导入)
+	paperImportService := service.NewPaperImportService(db, paperImportRepo, examPaperRepo, questionRepo, llmConfigService, log.Logger)
+	paperImportService.SetDedupService(questionDedupService)
+
+	// Position import service (职位表合并表头解析、列映射方案、预览导入)
+	positionImportService := service.NewPositionImportService(repository.NewPositionColumnProfileRepository(db), positionRepo, log.Logger)
+
+	// Print export service (试卷/错题本 PDF、DOCX 打印导出)
+	printExportService := service.NewPrintExportService(examPaperRepo, questionRepo, questionMaterialRepo, studyNoteService, cfg.Export, log.Logger)
+
+	// Content quality service (内容质量检查)
+	if err := db.AutoMigrate(&service.QualityCheckResult{}); err != nil {
+		log.Warn(fmt.Sprintf("Failed to migrate quality check results: %v", err))
+	}
+	contentQualityService := service.NewContentQualityService(db, questionRepo, courseRepo)
+	contentQualityService.SetEmbeddingService(embeddingService)
+	contentQualityService.SetDedupService(questionDedupService)
+
 	// LLM Generator service (LLM 内容生成 V2)
 	llmGeneratorService := service.NewLLMGeneratorService(llmConfigService, courseCategoryRepo, courseRepo, courseChapterRepo, generationTaskRepo, log.Logger)
 
 	// Content import service (内容导入服务)
 	contentImportService := service.NewContentImportService(db, courseChapterRepo, courseRepo, courseCategoryRepo, questionRepo, materialRepo, aiContentRepo, log.Logger)
+	contentImportService.SetDedupService(questionDedupService)
 
 	// 设置 LLM 生成服务的内容导入服务（用于自动导入）
 	llmGeneratorService.SetContentImportService(contentImportService)
@@ -284,11 +384,49 @@ func main() {
 	// Fenbi service
 	fenbiService := service.NewFenbiService(fenbiCredRepo, fenbiCategoryRepo, fenbiAnnouncementRepo, fenbiParseTaskRepo, positionRepo, nil, llmConfigService, log.Logger)
 
+	// OCR 配置（扫描版 PDF 职位表、附件）
+	ocrConfig := &parser.OCRConfig{
+		Engine:        cfg.OCR.Engine,
+		TesseractCmd:  cfg.OCR.TesseractCmd,
+		Language:      cfg.OCR.Language,
+		APIURL:        cfg.OCR.APIURL,
+		APIKey:        cfg.OCR.APIKey,
+		Timeout:       time.Duration(cfg.OCR.Timeout) * time.Second,
+		DPI:           cfg.OCR.DPI,
+		PdftoppmCmd:   cfg.OCR.PdftoppmCmd,
+		LowConfidence: cfg.OCR.LowConfidence,
+	}
+	positionImportService.SetOCR(ocrConfig, service.NewLLMOCRCellCleaner(llmConfigService))
+	fenbiService.SetOCRParser(parser.NewOCRParser(ocrConfig, log.Logger))
+	fenbiService.SetImportService(positionImportService)
+
+	// 附件归档（按内容 SHA-256 去重，本地磁盘或 S3 兼容存储）
+	attachmentStore, err := storage.New(storage.Config{
+		Backend:   cfg.AttachmentStore.Backend,
+		LocalDir:  cfg.AttachmentStore.LocalDir,
+		Endpoint:  cfg.AttachmentStore.Endpoint,
+		Region:    cfg.AttachmentStore.Region,
+		Bucket:    cfg.AttachmentStore.Bucket,
+		AccessKey: cfg.AttachmentStore.AccessKey,
+		SecretKey: cfg.AttachmentStore.SecretKey,
+		UseSSL:    cfg.AttachmentStore.UseSSL,
+		PathStyle: cfg.AttachmentStore.PathStyle,
+		Timeout:   time.Duration(cfg.AttachmentStore.TimeoutSeconds) * time.Second,
+	})
+	if err != nil {
+		log.Warn(fmt.Sprintf("Attachment store unavailable, attachments will not be archived: %v", err))
+	} else {
+		attachmentService := service.NewAttachmentService(repository.NewAttachmentRepository(db), attachmentStore, log.Logger)
+		attachmentService.SetImportService(positionImportService)
+		fenbiService.SetAttachmentService(attachmentService)
+	}
+
 	// Migration service
 	migrateService := service.NewMigrateService(fenbiParseTaskRepo, positionRepo, log.Logger)
 
 	// Compare service
 	compareService := service.NewCompareService(positionRepo)
+	compareService.SetForecastService(registrationForecastService)
 
 	// Exam tools services
 	examLocationService := service.NewExamLocationService(examLocationRepo)
@@ -302,15 +440,15 @@ func main() {
 	wechatRSSService := service.NewWechatRSSService(wechatRSSSourceRepo, wechatRSSArticleRepo, log.Logger)
 	// Inject MP auth service for wechat_api source type support
 	wechatRSSService.SetMPAuthService(wechatMPAuthService)
+	// Poll RSS/Atom/JSON Feed sources on their crawl frequency
+	wechatRSSService.SetFeedConfig(cfg.ArticleFeed)
 
-	// Initialize SearchService (optional - requires Elasticsearch)
-	var searchService *service.SearchService
-	searchService, err = service.NewSearchService(&cfg.Elasticsearch)
-	if err != nil {
-		log.Warn(fmt.Sprintf("Elasticsearch not available, search disabled: %v", err))
-	} else {
-		log.Info("Elasticsearch connected successfully")
-	}
+	// Public feeds (公告与公众号文章的 RSS/Atom/JSON Feed)
+	feedService := service.NewFeedService(announcementRepo, wechatRSSSourceRepo, wechatRSSArticleRepo, subscriptionRepo, repository.NewFeedTokenRepository(db), cfg.Feed, log.Logger)
+
+	// Search router: Elasticsearch 优先，不可用时降级为 MySQL 全文索引，恢复后自动切回
+	searchRouter := service.NewSearchRouter(nil, service.NewMySQLSearchBackend(positionRepo), log.Logger)
+	positionService.SetSearchRouter(searchRouter)
 
 	// ============================================
 	// Initialize Handlers
@@ -319,7 +457,9 @@ func main() {
 	userHandler := handler.NewUserHandler(userService)
 	positionHandler := handler.NewPositionHandler(positionService)
 	positionHandler.SetMatchService(matchService) // 启用推荐职位功能
+	positionHandler.SetForecastService(registrationForecastService)
 	matchHandler := handler.NewMatchHandler(matchService)
+	matchHandler.SetAdmissionService(admissionService)
 	announcementHandler := handler.NewAnnouncementHandler(announcementService)
 	notificationHandler := handler.NewNotificationHandler(notificationService)
 	adminHandler := handler.NewAdminHandler(adminService)
@@ -347,6 +487,11 @@ func main() {
 
 	// Position history handler
 	positionHistoryHandler := handler.NewPositionHistoryHandler(positionHistoryService, positionService)
+	announcementLinkHandler := handler.NewAnnouncementLinkHandler(announcementLinkerService)
+
+	// Registration data handler
+	registrationDataHandler := handler.NewRegistrationDataHandler(registrationDataService)
+	registrationDataHandler.SetForecastService(registrationForecastService)
 
 	// Membership handler
 	membershipHandler := handler.NewMembershipHandler(membershipService)
@@ -354,6 +499,9 @@ func main() {
 	// Calendar handler
 	calendarHandler := handler.NewCalendarHandler(calendarService)
 
+	// Feed handler
+	feedHandler := handler.NewFeedHandler(feedService)
+
 	// Course learning system handler
 	courseHandler := handler.NewCourseHandler(courseService, courseCategoryService, knowledgePointService)
 
@@ -374,12 +522,30 @@ func main() {
 
 	// Study note and wrong question handler (错题本与笔记)
 	studyNoteHandler := handler.NewStudyNoteHandler(studyNoteService)
+	// 导出需启动浏览器渲染，按用户单独限流
+	exportRateLimit := customMiddleware.RateLimitMiddleware(customMiddleware.NewRateLimiter(redisClient, customMiddleware.RateLimiterConfig{
+		Rate:      cfg.Export.RateLimit,
+		Period:    time.Minute,
+		KeyPrefix: "ratelimit:export:",
+	}))
+	studyNoteHandler.SetPrintExportService(printExportService, exportRateLimit)
+	printExportHandler := handler.NewPrintExportHandler(printExportService)
+	irtHandler := handler.NewIRTHandler(irtService)
+	reviewHandler := handler.NewReviewHandler(reviewSchedulerService, flashCardService, studyNoteService)
+	flashCardDeckHandler := handler.NewFlashCardDeckHandler(flashCardDeckService)
 
 	// Learning material handler (素材库 §25.4)
 	materialHandler := handler.NewMaterialHandler(materialService)
 
 	// Content generator handler (内容生成)
 	contentGeneratorHandler := handler.NewContentGeneratorHandler(contentGeneratorService, llmGeneratorService)
+	contentGeneratorHandler.SetQualityService(contentQualityService)
+
+	// Embedding admin handler (内容向量管理)
+	embeddingHandler := handler.NewEmbeddingHandler(embeddingService)
+	questionDedupHandler := handler.NewQuestionDedupHandler(questionDedupService)
+	paperImportHandler := handler.NewPaperImportHandler(paperImportService)
+	positionImportHandler := handler.NewPositionImportHandler(positionImportService)
 
 	// Knowledge content handler (知识点内容生成 §25.3)
 	knowledgeContentHandler := handler.NewKnowledgeContentHandler(knowledgeDetailService, flashCardService, mindMapService, knowledgeContentService)
@@ -387,6 +553,7 @@ func main() {
 	// Knowledge content seeder (知识点内容种子数据生成器)
 	knowledgeContentSeeder := service.NewKnowledgeContentSeeder(knowledgeDetailRepo, flashCardRepo, mindMapRepo)
 	knowledgeContentHandler.SetSeeder(knowledgeContentSeeder)
+	mindMapInterchangeHandler := handler.NewMindMapInterchangeHandler(mindMapService)
 	// NOTE: knowledgeContentHandler.RegisterRoutes() is called later in route registration section
 
 	// AI content handler (AI内容预生成 §26.1)
@@ -403,11 +570,77 @@ func main() {
 
 	// Content import handler (MCP内容导入)
 	contentImportHandler := handler.NewContentImportHandler(db, courseService, questionService, materialService)
+	contentImportHandler.SetDedupService(questionDedupService)
+
+	// Learning search (学习内容统一搜索，按水位增量刷新投影表)
+	learningSearchService := service.NewLearningSearchService(learningSearchRepo, membershipService, log.Logger)
+	learningSearchHandler := handler.NewLearningSearchHandler(learningSearchService)
+
+	// Initialize SearchHandler
+	searchHandler := handler.NewSearchHandler(searchRouter)
+
+	// Elasticsearch 连接成功（启动时或恢复后）时确保别名存在，并交给发件箱同步任务；
+	// 新建的空索引需要全量重建
+	var searchSync atomic.Pointer[service.SearchSyncService]
+	searchRouter.SetConnector(
+		func() (*service.SearchService, error) {
+			return service.NewSearchService(&cfg.Elasticsearch)
+		},
+		func(searchService *service.SearchService) {
+			log.Info("Elasticsearch connected successfully")
+			created, err := searchService.EnsureIndex(context.Background())
+			if err != nil {
+				log.Warn(fmt.Sprintf("Failed to ensure search index: %v", err))
+			}
+			searchSyncService := service.NewSearchSyncService(searchService, positionRepo, searchOutboxRepo,
+				cfg.Elasticsearch.SyncBatchSize, cfg.Elasticsearch.KeepIndices, log.Logger)
+			searchSync.Store(searchSyncService)
+			if created {
+				go searchSyncService.Reindex(context.Background())
+			}
+			searchHandler.SetSyncService(searchSyncService)
+		},
+	)
+	searchRouter.CheckHealth(context.Background())
+	if backend := searchRouter.Active().Name(); backend != service.SearchBackendElasticsearch {
+		log.Warn(fmt.Sprintf("Elasticsearch not available, search using %s backend", backend))
+	}
+	searchRouter.StartHealthCheck(30 * time.Second)
 
-	// Initialize SearchHandler (only if Elasticsearch is available)
-	var searchHandler *handler.SearchHandler
-	if searchService != nil {
-		searchHandler = handler.NewSearchHandler(searchService)
+	// ============================================
+	// Periodic maintenance tasks (asynq, 多实例部署时每个周期只执行一次)
+	// ============================================
+	taskScheduler := scheduler.NewScheduler((*scheduler.SchedulerConfig)(&cfg.Scheduler), log.Logger)
+	seconds := func(n int) time.Duration { return time.Duration(n) * time.Second }
+	periodicTasks := []scheduler.PeriodicTask{
+		{Type: scheduler.TypeSearchSync, Interval: seconds(cfg.Elasticsearch.SyncIntervalSeconds), Run: func(ctx context.Context) error {
+			if searchSyncService := searchSync.Load(); searchSyncService != nil {
+				return searchSyncService.RunScheduled(ctx)
+			}
+			return nil
+		}},
+		// 发件箱清理不依赖 Elasticsearch，仅使用 MySQL 检索时同样执行
+		{Type: scheduler.TypeSearchOutboxPurge, Interval: time.Hour, Run: func(context.Context) error {
+			return service.PurgeSearchOutbox(searchOutboxRepo, log.Logger)
+		}},
+		{Type: scheduler.TypeEmbeddingRefresh, Interval: seconds(cfg.Embedding.IntervalSeconds), RunOnStart: true, Run: embeddingService.RunScheduled},
+		{Type: scheduler.TypeQuestionDedupSync, Interval: seconds(cfg.QuestionDedup.IntervalSeconds), RunOnStart: true, Run: questionDedupService.RunScheduled},
+		{Type: scheduler.TypeIRTCalibrate, Interval: seconds(cfg.IRT.IntervalSeconds), Run: irtService.RunScheduled},
+		{Type: scheduler.TypeKnowledgeTracingFit, Interval: seconds(cfg.Knowledge.IntervalSeconds), Run: knowledgeMasteryService.RunScheduled},
+		{Type: scheduler.TypeReviewOptimize, Interval: seconds(cfg.Review.OptimizeIntervalSeconds), Run: reviewSchedulerService.RunScheduled},
+		{Type: scheduler.TypeAnnouncementLink, Interval: seconds(cfg.AnnouncementLink.IntervalSeconds), Run: announcementLinkerService.RunScheduled},
+		{Type: scheduler.TypeLearningSearch, Interval: time.Minute, RunOnStart: true, Run: learningSearchService.RunScheduled},
+		{Type: scheduler.TypeFeedPoll, Interval: seconds(cfg.ArticleFeed.IntervalSeconds), Run: wechatRSSService.RunScheduled},
+	}
+	for _, task := range periodicTasks {
+		if err := taskScheduler.SchedulePeriodic(task); err != nil {
+			log.Warn(fmt.Sprintf("Failed to schedule %s: %v", task.Type, err))
+		}
+	}
+	if err := taskScheduler.Start(); err != nil {
+		log.Warn(fmt.Sprintf("Failed to start task scheduler, periodic tasks disabled: %v", err))
+	} else if err := taskScheduler.StartScheduler(); err != nil {
+		log.Warn(fmt.Sprintf("Failed to start cron scheduler, periodic tasks disabled: %v", err))
 	}
 
 	// ============================================
@@ -457,11 +690,15 @@ func main() {
 	positionHandler.RegisterRoutes(positionGroup, authMiddleware.JWT())
 	// Register position history routes (public)
 	positionHistoryHandler.RegisterPositionRoutes(positionGroup)
+	announcementLinkHandler.RegisterPositionRoutes(positionGroup)
 
 	// History routes (public)
 	historyGroup := v1.Group("/history")
 	positionHistoryHandler.RegisterRoutes(historyGroup)
 
+	// Registration data routes (public)
+	registrationDataHandler.RegisterRoutes(v1)
+
 	// User favorites (protected) - legacy route, kept for backwards compatibility
 	v1.GET("/user/favorites", positionHandler.GetFavorites, authMiddleware.JWT())
 
@@ -471,6 +708,9 @@ func main() {
 	// Subscription routes
 	subscriptionHandler.RegisterRoutes(v1, authMiddleware.JWT())
 
+	// Feed routes (public + tokenized user feeds)
+	feedHandler.RegisterRoutes(v1, authMiddleware.JWT())
+
 	// Membership routes (public + protected)
 	membershipHandler.RegisterRoutes(v1, authMiddleware.JWT())
 
@@ -505,9 +745,15 @@ func main() {
 	// Position admin routes (admin only)
 	positionHandler.RegisterAdminRoutes(adminGroup, adminAuthMiddleware.JWT())
 
+	// Registration data admin routes (admin only)
+	registrationDataHandler.RegisterAdminRoutes(adminGroup, adminAuthMiddleware.JWT())
+
 	// Position history admin routes (admin only)
 	positionHistoryHandler.RegisterAdminRoutes(adminGroup, adminAuthMiddleware.JWT())
 
+	// Announcement campaign linking routes (admin only)
+	announcementLinkHandler.RegisterAdminRoutes(adminGroup, adminAuthMiddleware.JWT())
+
 	// Fenbi routes (admin only)
 	fenbiHandler.RegisterRoutes(adminGroup, adminAuthMiddleware.JWT())
 
@@ -553,6 +799,9 @@ func main() {
 
 	// Practice session routes (专项练习、随机练习、计时练习)
 	practiceSessionHandler.RegisterRoutes(e, authMiddleware.JWT())
+	irtHandler.RegisterRoutes(e, authMiddleware.JWT())
+	reviewHandler.RegisterRoutes(e, authMiddleware.JWT())
+	flashCardDeckHandler.RegisterRoutes(e, authMiddleware.JWT())
 
 	// Question bank (题库) routes
 	questionHandler.RegisterRoutes(e, authMiddleware.JWT())
@@ -561,6 +810,9 @@ func main() {
 	// Study note and wrong question routes (错题本与笔记)
 	studyNoteHandler.RegisterRoutes(e, authMiddleware.JWT())
 
+	// Printable paper export routes (试卷打印导出)
+	printExportHandler.RegisterRoutes(e, authMiddleware.JWT(), exportRateLimit)
+
 	// Learning material routes (素材库 §25.4)
 	materialHandler.RegisterRoutes(v1, authMiddleware.JWT())
 
@@ -584,6 +836,8 @@ func main() {
 
 	// Knowledge content routes (知识点内容生成 §25.3) - admin only
 	knowledgeContentHandler.RegisterRoutes(adminGroup, adminAuthMiddleware.JWT())
+	mindMapInterchangeHandler.RegisterRoutes(e, authMiddleware.JWT())
+	mindMapInterchangeHandler.RegisterAdminRoutes(adminGroup, adminAuthMiddleware.JWT())
 
 	// AI content routes (AI内容预生成 §26.1)
 	aiContentHandler.RegisterRoutes(e, authMiddleware.JWT(), adminAuthMiddleware.JWT())
@@ -616,11 +870,25 @@ func main() {
 	// Major admin routes
 	majorHandler.RegisterAdminRoutes(adminGroup)
 
-	// Search routes (public, only if Elasticsearch is available)
-	if searchHandler != nil {
-		searchGroup := v1.Group("/search")
-		searchHandler.RegisterRoutes(searchGroup)
-	}
+	// Search routes (public, Elasticsearch or MySQL full-text backend)
+	searchGroup := v1.Group("/search")
+	searchHandler.RegisterRoutes(searchGroup)
+	searchHandler.RegisterAdminRoutes(adminGroup, adminAuthMiddleware.JWT())
+
+	// Learning search routes (questions, materials, courses, knowledge points, public notes)
+	learningSearchHandler.RegisterRoutes(v1, authMiddleware.OptionalJWT())
+	learningSearchHandler.RegisterAdminRoutes(adminGroup, adminAuthMiddleware.JWT())
+
+	// Embedding admin routes (similar questions, semantic duplicates)
+	embeddingHandler.RegisterAdminRoutes(adminGroup, adminAuthMiddleware.JWT())
+
+	// Question dedup admin routes (near-duplicate clusters, merge)
+	questionDedupHandler.RegisterAdminRoutes(adminGroup, adminAuthMiddleware.JWT())
+	irtHandler.RegisterAdminRoutes(adminGroup, adminAuthMiddleware.JWT())
+
+	// Paper import admin routes (Word/PDF real-exam papers → draft papers)
+	paperImportHandler.RegisterAdminRoutes(adminGroup, adminAuthMiddleware.JWT())
+	positionImportHandler.RegisterAdminRoutes(adminGroup, adminAuthMiddleware.JWT())
 
 	// Start server
 	addr := fmt.Sprintf(":%d", cfg.Server.Port)
@@ -629,3 +897,28 @@ func main() {
 		log.Fatal(fmt.Sprintf("Failed to start server: %v", err))
 	}
 }
+
+// spiderConfig 将配置文件中的爬虫配置转换为 crawler.SpiderConfig
+func spiderConfig(c *config.CrawlerConfig) *crawler.SpiderConfig {
+	sc := &crawler.SpiderConfig{
+		ConcurrentRequests: c.ConcurrentRequests,
+		DownloadDelay:      c.DownloadDelay,
+		RetryTimes:         c.RetryTimes,
+		UserAgent:          c.UserAgent,
+		ProxyEnabled:       c.ProxyEnabled,
+		ProxyURL:           c.ProxyURL,
+		Timeout:            c.Timeout,
+		DomainParallelism:  c.DomainParallelism,
+		RespectRobotsTxt:   c.RespectRobotsTxt,
+		BreakerThreshold:   c.BreakerThreshold,
+		BreakerCooldown:    c.BreakerCooldown,
+	}
+	for _, limit := range c.DomainLimits {
+		sc.DomainLimits = append(sc.DomainLimits, crawler.DomainLimit{
+			Domain:      limit.Domain,
+			Parallelism: limit.Parallelism,
+			Delay:       limit.Delay,
+		})
+	}
+	return sc
+}
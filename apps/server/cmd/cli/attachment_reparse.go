@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/what-cse/server/internal/config"
+	"github.com/what-cse/server/internal/parser"
+	"github.com/what-cse/server/internal/repository"
+	"github.com/what-cse/server/internal/service"
+	"github.com/what-cse/server/internal/storage"
+	"gorm.io/gorm"
+)
+
+func init() {
+	commands["attachment-reparse"] = command{
+		Usage: "用当前解析器版本重新解析归档附件并更新职位 (-type pdf|excel|word|html -all -limit 100 -dry-run)",
+		Run:   runAttachmentReparse,
+	}
+}
+
+func runAttachmentReparse(db *gorm.DB, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("attachment-reparse", flag.ExitOnError)
+	fileType := fs.String("type", "", "附件类型 pdf/excel/word/html，留空为全部")
+	all := fs.Bool("all", false, "包括已用当前版本解析过的附件")
+	limit := fs.Int("limit", 0, "最多处理的附件数，0 不限")
+	dryRun := fs.Bool("dry-run", false, "只解析不写入职位")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	store, err := storage.New(storage.Config{
+		Backend:   cfg.AttachmentStore.Backend,
+		LocalDir:  cfg.AttachmentStore.LocalDir,
+		Endpoint:  cfg.AttachmentStore.Endpoint,
+		Region:    cfg.AttachmentStore.Region,
+		Bucket:    cfg.AttachmentStore.Bucket,
+		AccessKey: cfg.AttachmentStore.AccessKey,
+		SecretKey: cfg.AttachmentStore.SecretKey,
+		UseSSL:    cfg.AttachmentStore.UseSSL,
+		PathStyle: cfg.AttachmentStore.PathStyle,
+		Timeout:   time.Duration(cfg.AttachmentStore.TimeoutSeconds) * time.Second,
+	})
+	if err != nil {
+		return err
+	}
+
+	logger := zap.NewNop()
+	importService := service.NewPositionImportService(repository.NewPositionColumnProfileRepository(db), repository.NewPositionRepository(db), logger)
+	importService.SetOCR(&parser.OCRConfig{
+		Engine:        cfg.OCR.Engine,
+		TesseractCmd:  cfg.OCR.TesseractCmd,
+		Language:      cfg.OCR.Language,
+		APIURL:        cfg.OCR.APIURL,
+		APIKey:        cfg.OCR.APIKey,
+		Timeout:       time.Duration(cfg.OCR.Timeout) * time.Second,
+		DPI:           cfg.OCR.DPI,
+		PdftoppmCmd:   cfg.OCR.PdftoppmCmd,
+		LowConfidence: cfg.OCR.LowConfidence,
+	}, nil)
+	svc := service.NewAttachmentService(repository.NewAttachmentRepository(db), store, logger)
+	svc.SetImportService(importService)
+
+	result, err := svc.Reparse(context.Background(), service.AttachmentReparseOptions{
+		FileType: *fileType,
+		All:      *all,
+		Limit:    *limit,
+		DryRun:   *dryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("解析器版本: %s  附件类型: %s\n", result.ParserVersion, orDefault(*fileType, "全部"))
+	fmt.Printf("附件: %d  成功: %d  失败: %d\n", result.Attachments, result.Parsed, result.Failed)
+	fmt.Printf("职位: %d  新增: %d  覆盖: %d\n", result.Positions, result.Created, result.Updated)
+	for _, e := range result.Errors {
+		fmt.Printf("  %s\n", e)
+	}
+	fmt.Printf("耗时: %.1fs\n", float64(result.DurationMs)/1000)
+	return nil
+}
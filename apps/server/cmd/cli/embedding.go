@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/what-cse/server/internal/ai"
+	"github.com/what-cse/server/internal/config"
+	"github.com/what-cse/server/internal/model"
+	"github.com/what-cse/server/internal/repository"
+	"github.com/what-cse/server/internal/service"
+	"gorm.io/gorm"
+)
+
+func init() {
+	commands["embedding-refresh"] = command{
+		Usage: "对新增或变更的题目、素材向量化 (-local 使用本地哈希向量)",
+		Run:   runEmbeddingRefresh,
+	}
+	commands["embedding-server"] = command{
+		Usage: "启动 OpenAI 兼容的本地嵌入接口，供开发测试 (-addr :8089 -dim 256)",
+		Run:   runEmbeddingServer,
+	}
+}
+
+func runEmbeddingRefresh(db *gorm.DB, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("embedding-refresh", flag.ExitOnError)
+	local := fs.Bool("local", false, "使用本地哈希向量")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	embeddingCfg := cfg.Embedding
+	if *local {
+		embeddingCfg.Provider = "local"
+	}
+
+	llmConfigService := service.NewLLMConfigService(repository.NewLLMConfigRepository(db), zap.NewNop())
+	svc := service.NewEmbeddingService(repository.NewContentEmbeddingRepository(db), llmConfigService, embeddingCfg, nil)
+
+	result, err := svc.Refresh(context.Background())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("模型:     %s\n", result.Model)
+	for _, entityType := range []string{model.EmbeddingEntityQuestion, model.EmbeddingEntityMaterial} {
+		fmt.Printf("%-9s 向量化: %-6d 未变化: %-6d 移除: %d\n", entityType,
+			result.Embedded[entityType], result.Unchanged[entityType], result.Removed[entityType])
+	}
+	if result.StaleModels > 0 {
+		fmt.Printf("清理旧模型向量: %d\n", result.StaleModels)
+	}
+	fmt.Printf("耗时:     %.1fs\n", result.DurationSec)
+	return nil
+}
+
+func runEmbeddingServer(db *gorm.DB, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("embedding-server", flag.ExitOnError)
+	addr := fs.String("addr", ":8089", "监听地址")
+	dim := fs.Int("dim", orInt(cfg.Embedding.Dimensions, 256), "向量维度")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	embedder := ai.NewHashEmbedder(*dim)
+	fmt.Printf("OpenAI-compatible embeddings on http://localhost%s/v1/embeddings (model %s)\n", *addr, embedder.Model())
+	return http.ListenAndServe(*addr, ai.NewEmbeddingHandler(embedder))
+}
+
+func orInt(v, fallback int) int {
+	if v > 0 {
+		return v
+	}
+	return fallback
+}
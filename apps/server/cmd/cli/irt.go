@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/what-cse/server/internal/config"
+	"github.com/what-cse/server/internal/repository"
+	"github.com/what-cse/server/internal/service"
+	"gorm.io/gorm"
+)
+
+func init() {
+	commands["irt-calibrate"] = command{
+		Usage: "按作答记录标定题目 2PL 参数（区分度、难度），并重新估计用户能力",
+		Run:   runIRTCalibrate,
+	}
+}
+
+func runIRTCalibrate(db *gorm.DB, cfg *config.Config, args []string) error {
+	svc := service.NewIRTService(repository.NewIRTRepository(db), repository.NewQuestionRepository(db), repository.NewCourseCategoryRepository(db), cfg.IRT, zap.NewNop())
+
+	result, err := svc.Calibrate(context.Background())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("作答记录:   %d\n", result.Responses)
+	fmt.Printf("用户/题目:  %d / %d\n", result.Users, result.Questions)
+	fmt.Printf("标定题目:   %d\n", result.Calibrated)
+	fmt.Printf("能力估计:   %d\n", result.Abilities)
+	fmt.Printf("迭代轮数:   %d (收敛: %v)\n", result.Iterations, result.Converged)
+	fmt.Printf("耗时:       %.1fs\n", float64(result.DurationMs)/1000)
+	return nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/what-cse/server/internal/config"
+	"github.com/what-cse/server/internal/repository"
+	"github.com/what-cse/server/internal/service"
+	"gorm.io/gorm"
+)
+
+func init() {
+	commands["knowledge-mastery-backfill"] = command{
+		Usage: "按知识点拟合 BKT 参数，并用历史作答记录重新计算全部用户的知识点掌握度",
+		Run:   runKnowledgeMasteryBackfill,
+	}
+}
+
+func runKnowledgeMasteryBackfill(db *gorm.DB, cfg *config.Config, args []string) error {
+	svc := service.NewKnowledgeMasteryService(repository.NewKnowledgeMasteryRepository(db), repository.NewKnowledgeTracingRepository(db), cfg.Knowledge, zap.NewNop())
+
+	result, err := svc.Backfill(context.Background())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("作答记录:   %d\n", result.Responses)
+	fmt.Printf("知识点:     %d (拟合参数 %d)\n", result.KnowledgePoints, result.Fitted)
+	fmt.Printf("掌握度记录: %d\n", result.Masteries)
+	fmt.Printf("耗时:       %.1fs\n", float64(result.DurationMs)/1000)
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/what-cse/server/internal/config"
+	"github.com/what-cse/server/internal/repository"
+	"github.com/what-cse/server/internal/service"
+	"gorm.io/gorm"
+)
+
+func init() {
+	commands["learning-search-refresh"] = command{
+		Usage: "刷新学习内容搜索投影表 (-rebuild 清空后全量重建)",
+		Run:   runLearningSearchRefresh,
+	}
+}
+
+func runLearningSearchRefresh(db *gorm.DB, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("learning-search-refresh", flag.ExitOnError)
+	rebuild := fs.Bool("rebuild", false, "清空后全量重建")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	svc := service.NewLearningSearchService(repository.NewLearningSearchRepository(db), nil, nil)
+
+	var results []service.LearningSearchRefreshResult
+	var err error
+	if *rebuild {
+		results, err = svc.Rebuild(context.Background())
+	} else {
+		results, err = svc.Refresh(context.Background())
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		fmt.Printf("%-18s 写入: %-6d 删除: %d\n", r.DocType, r.Upserted, r.Removed)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+// Command cli 运维命令行工具，与 API 服务共用配置和数据库
+//
+// 用法:
+//
+//	go run ./cmd/cli <command> [flags]
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/what-cse/server/internal/config"
+	"github.com/what-cse/server/internal/database"
+	"gorm.io/gorm"
+)
+
+// command 一个子命令
+type command struct {
+	Usage string
+	Run   func(db *gorm.DB, cfg *config.Config, args []string) error
+}
+
+// commands 已注册的子命令
+var commands = map[string]command{}
+
+func main() {
+	if len(os.Args) < 2 || commands[os.Args[1]].Run == nil {
+		printUsage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	db, err := database.NewMySQL(&cfg.Database)
+	if err != nil {
+		fmt.Printf("Failed to connect to database: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := commands[os.Args[1]].Run(db, cfg, os.Args[2:]); err != nil {
+		fmt.Printf("%s failed: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Println("Usage: cli <command> [flags]")
+	fmt.Println()
+	fmt.Println("Commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("  %-24s %s\n", name, commands[name].Usage)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/what-cse/server/internal/config"
+	"github.com/what-cse/server/internal/model"
+	"github.com/what-cse/server/internal/repository"
+	"github.com/what-cse/server/internal/service"
+	"gorm.io/gorm"
+)
+
+func init() {
+	commands["mind-map-import"] = command{
+		Usage: "批量导入目录下的 XMind/FreeMind/OPML/Markdown 导图 (-dir ./maps -type knowledge)",
+		Run:   runMindMapImport,
+	}
+}
+
+func runMindMapImport(db *gorm.DB, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("mind-map-import", flag.ExitOnError)
+	dir := fs.String("dir", "", "导图文件所在目录（含子目录）")
+	mapType := fs.String("type", string(model.MindMapTypeKnowledge), "导图类型")
+	categoryID := fs.Uint("category", 0, "分类ID")
+	public := fs.Bool("public", true, "是否公开")
+	tags := fs.String("tags", "", "标签，逗号分隔")
+	userID := fs.Uint("user", 0, "创建人（管理员ID）")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *dir == "" {
+		return errors.New("请指定 -dir")
+	}
+
+	req := &service.ImportMindMapRequest{
+		MapType:  model.MindMapType(*mapType),
+		IsPublic: *public,
+	}
+	if *categoryID > 0 {
+		id := uint(*categoryID)
+		req.CategoryID = &id
+	}
+	if *tags != "" {
+		req.Tags = strings.Split(*tags, ",")
+	}
+
+	svc := service.NewMindMapService(repository.NewMindMapRepository(db))
+	result, err := svc.ImportMindMapDir(uint(*userID), *dir, req)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("文件:     %d\n", result.Total)
+	fmt.Printf("导入:     %d\n", result.Created)
+	for _, f := range result.Failures {
+		fmt.Printf("失败:     %s: %s\n", f.File, f.Error)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/what-cse/server/internal/config"
+	"github.com/what-cse/server/internal/repository"
+	"github.com/what-cse/server/internal/service"
+	"gorm.io/gorm"
+)
+
+func init() {
+	commands["question-dedup-backfill"] = command{
+		Usage: "为缺少或过期的题目补齐 MinHash 签名，并记录存量疑似重复题对",
+		Run:   runQuestionDedupBackfill,
+	}
+}
+
+func runQuestionDedupBackfill(db *gorm.DB, cfg *config.Config, args []string) error {
+	svc := service.NewQuestionDedupService(repository.NewQuestionDedupRepository(db), repository.NewQuestionRepository(db), cfg.QuestionDedup, zap.NewNop())
+
+	result, err := svc.Sync(context.Background())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("签名题目:   %d\n", result.Signed)
+	fmt.Printf("疑似重复对: %d\n", result.Flagged)
+	fmt.Printf("清理签名:   %d\n", result.Purged)
+	fmt.Printf("耗时:       %.1fs\n", result.DurationSec)
+	return nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/what-cse/server/internal/config"
+	"github.com/what-cse/server/internal/repository"
+	"github.com/what-cse/server/internal/service"
+	"gorm.io/gorm"
+)
+
+func init() {
+	commands["srs-optimize"] = command{
+		Usage: "用复习日志为复习记录足够的用户重新优化个人 FSRS 参数",
+		Run:   runSRSOptimize,
+	}
+}
+
+func runSRSOptimize(db *gorm.DB, cfg *config.Config, args []string) error {
+	svc := service.NewReviewSchedulerService(repository.NewReviewRepository(db), cfg.Review, zap.NewNop())
+
+	started := time.Now()
+	optimized, err := svc.OptimizeAll(context.Background())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("优化用户: %d\n", optimized)
+	fmt.Printf("耗时:     %.1fs\n", time.Since(started).Seconds())
+	return nil
+}
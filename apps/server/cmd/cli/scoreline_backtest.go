@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/what-cse/server/internal/config"
+	"github.com/what-cse/server/internal/repository"
+	"github.com/what-cse/server/internal/service"
+	"gorm.io/gorm"
+)
+
+func init() {
+	commands["scoreline-backtest"] = command{
+		Usage: "回测进面分数线预测模型 (-exam-type 国考 -years 2023,2024)",
+		Run:   runScoreLineBacktest,
+	}
+}
+
+func runScoreLineBacktest(db *gorm.DB, _ *config.Config, args []string) error {
+	fs := flag.NewFlagSet("scoreline-backtest", flag.ExitOnError)
+	examType := fs.String("exam-type", "", "考试类型，为空时使用全部数据")
+	yearsFlag := fs.String("years", "", "留出年份，逗号分隔；为空时回测最近三年")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var years []int
+	for _, y := range strings.Split(*yearsFlag, ",") {
+		if y = strings.TrimSpace(y); y == "" {
+			continue
+		}
+		year, err := strconv.Atoi(y)
+		if err != nil {
+			return fmt.Errorf("invalid year %q", y)
+		}
+		years = append(years, year)
+	}
+
+	predictor := service.NewScoreLinePredictor(repository.NewPositionHistoryRepository(db))
+	report, err := predictor.Backtest(*examType, years)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("考试类型: %s  区间名义覆盖率: %.0f%%\n\n", orDefault(report.ExamType, "全部"), report.IntervalLevel*100)
+	fmt.Printf("%-6s %6s %7s %7s %7s %8s %8s %9s  %s\n", "年份", "样本", "MAE", "RMSE", "偏差", "覆盖率", "区间宽", "基线MAE", "池化层级")
+	for _, y := range report.Years {
+		printBacktestRow(strconv.Itoa(y.Year), y)
+	}
+	printBacktestRow("合计", report.Overall)
+
+	return nil
+}
+
+func printBacktestRow(label string, y *service.ScoreLineBacktestYear) {
+	levels := make([]string, 0, len(y.CountByLevel))
+	for level, n := range y.CountByLevel {
+		levels = append(levels, fmt.Sprintf("%s=%d", level, n))
+	}
+	sort.Strings(levels)
+
+	fmt.Printf("%-6s %6d %7.2f %7.2f %7.2f %7.0f%% %8.2f %9.2f  %s\n",
+		label, y.Count, y.MAE, y.RMSE, y.Bias, y.Coverage*100, y.AvgWidth, y.BaselineMAE, strings.Join(levels, " "))
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
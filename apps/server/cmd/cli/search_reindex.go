@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/what-cse/server/internal/config"
+	"github.com/what-cse/server/internal/repository"
+	"github.com/what-cse/server/internal/service"
+	"gorm.io/gorm"
+)
+
+func init() {
+	commands["search-reindex"] = command{
+		Usage: "不停机全量重建职位搜索索引 (-keep 1 -batch 200)",
+		Run:   runSearchReindex,
+	}
+	commands["search-sync"] = command{
+		Usage: "立即同步搜索发件箱中的职位变更 (-retry)",
+		Run:   runSearchSync,
+	}
+}
+
+func newSearchSyncService(db *gorm.DB, cfg *config.Config, batch, keep int) (*service.SearchSyncService, error) {
+	search, err := service.NewSearchService(&cfg.Elasticsearch)
+	if err != nil {
+		return nil, err
+	}
+	return service.NewSearchSyncService(search,
+		repository.NewPositionRepository(db),
+		repository.NewSearchOutboxRepository(db),
+		batch, keep, nil), nil
+}
+
+func runSearchReindex(db *gorm.DB, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("search-reindex", flag.ExitOnError)
+	keep := fs.Int("keep", cfg.Elasticsearch.KeepIndices, "保留的旧版本索引数")
+	batch := fs.Int("batch", cfg.Elasticsearch.SyncBatchSize, "切换后补同步的批大小")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	syncer, err := newSearchSyncService(db, cfg, *batch, *keep)
+	if err != nil {
+		return err
+	}
+
+	result, err := syncer.Reindex(context.Background())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("新索引:   %s\n", result.Index)
+	fmt.Printf("原索引:   %s\n", orDefault(strings.Join(result.Previous, ","), "无"))
+	fmt.Printf("已删除:   %s\n", orDefault(strings.Join(result.Deleted, ","), "无"))
+	fmt.Printf("写入:     %d  失败: %d  补同步: %d\n", result.Indexed, result.Failed, result.CatchUp)
+	fmt.Printf("耗时:     %.1fs\n", result.DurationSec)
+	return nil
+}
+
+func runSearchSync(db *gorm.DB, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("search-sync", flag.ExitOnError)
+	retry := fs.Bool("retry", false, "先重置失败记录再同步")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	syncer, err := newSearchSyncService(db, cfg, cfg.Elasticsearch.SyncBatchSize, cfg.Elasticsearch.KeepIndices)
+	if err != nil {
+		return err
+	}
+
+	if *retry {
+		n, err := syncer.RetryFailed()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("已重置失败记录: %d\n", n)
+	}
+
+	processed, err := syncer.SyncPending(context.Background())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("已同步: %d\n", processed)
+	return nil
+}
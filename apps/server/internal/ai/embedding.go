@@ -0,0 +1,193 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Embedder converts texts into dense vectors
+type Embedder interface {
+	// Embed returns one vector per input text, in input order
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	// Model returns the embedding model name, vectors from different models are not comparable
+	Model() string
+}
+
+// =====================================================
+// OpenAI-compatible /embeddings endpoint
+// =====================================================
+
+// embeddingRequest is the OpenAI /embeddings request body as served by NewEmbeddingHandler
+type embeddingRequest struct {
+	Model string          `json:"model"`
+	Input json.RawMessage `json:"input"` // string or []string
+}
+
+// OpenAIEmbedder calls any OpenAI-compatible /embeddings endpoint
+// (OpenAI, DeepSeek, Ollama /v1, vLLM, or the local stand-in served by NewEmbeddingHandler)
+type OpenAIEmbedder struct {
+	client *OpenAIClient
+	model  string
+}
+
+// NewOpenAIEmbedder creates an embedder, baseURL is the API root such as https://api.openai.com/v1
+func NewOpenAIEmbedder(baseURL, apiKey, model string, timeout time.Duration) *OpenAIEmbedder {
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+	return &OpenAIEmbedder{
+		client: newOpenAIClient(apiKey, strings.TrimRight(baseURL, "/"), model, timeout, nil),
+		model:  model,
+	}
+}
+
+// Model returns the embedding model name
+func (e *OpenAIEmbedder) Model() string {
+	return e.model
+}
+
+// Embed creates embeddings for the given texts in one request
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	return e.client.CreateEmbeddings(ctx, e.model, texts)
+}
+
+// =====================================================
+// Local stand-in
+// =====================================================
+
+// HashEmbedder is a deterministic, dependency-free embedder based on hashed
+// character unigrams and bigrams. It captures lexical overlap only, which is
+// enough for near-duplicate detection and for running the pipeline without an
+// external embedding provider.
+type HashEmbedder struct {
+	dim int
+}
+
+// NewHashEmbedder creates a hash embedder with the given dimension
+func NewHashEmbedder(dim int) *HashEmbedder {
+	if dim <= 0 {
+		dim = 256
+	}
+	return &HashEmbedder{dim: dim}
+}
+
+// Model returns the embedding model name
+func (e *HashEmbedder) Model() string {
+	return fmt.Sprintf("local-hash-%d", e.dim)
+}
+
+// Embed creates L2-normalized hashed n-gram vectors
+func (e *HashEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = e.embedOne(text)
+	}
+	return vectors, nil
+}
+
+func (e *HashEmbedder) embedOne(text string) []float32 {
+	vec := make([]float32, e.dim)
+
+	runes := make([]rune, 0, len(text))
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			runes = append(runes, r)
+		}
+	}
+
+	add := func(feature string, weight float32) {
+		h := fnv.New32a()
+		h.Write([]byte(feature))
+		sum := h.Sum32()
+		idx := int(sum % uint32(e.dim))
+		if sum&(1<<31) != 0 {
+			weight = -weight
+		}
+		vec[idx] += weight
+	}
+	for i, r := range runes {
+		add(string(r), 0.5)
+		if i+1 < len(runes) {
+			add(string(runes[i:i+2]), 1)
+		}
+	}
+
+	var norm float64
+	for _, v := range vec {
+		norm += float64(v) * float64(v)
+	}
+	if norm > 0 {
+		inv := float32(1 / math.Sqrt(norm))
+		for i := range vec {
+			vec[i] *= inv
+		}
+	}
+	return vec
+}
+
+// NewEmbeddingHandler serves POST /v1/embeddings in the OpenAI wire format on top
+// of any Embedder, so an LLM config can point at a local stand-in
+func NewEmbeddingHandler(embedder Embedder) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/embeddings", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req embeddingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeEmbeddingError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		var texts []string
+		if err := json.Unmarshal(req.Input, &texts); err != nil {
+			var single string
+			if err := json.Unmarshal(req.Input, &single); err != nil {
+				writeEmbeddingError(w, http.StatusBadRequest, "input must be a string or an array of strings")
+				return
+			}
+			texts = []string{single}
+		}
+
+		vectors, err := embedder.Embed(r.Context(), texts)
+		if err != nil {
+			writeEmbeddingError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		resp := openai.EmbeddingResponse{
+			Object: "list",
+			Model:  openai.EmbeddingModel(embedder.Model()),
+		}
+		for i, vec := range vectors {
+			resp.Data = append(resp.Data, openai.Embedding{Object: "embedding", Index: i, Embedding: vec})
+			resp.Usage.PromptTokens += len([]rune(texts[i]))
+		}
+		resp.Usage.TotalTokens = resp.Usage.PromptTokens
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+	return mux
+}
+
+func writeEmbeddingError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]string{"message": message},
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
@@ -25,6 +26,7 @@ type AIConfig struct {
 	Temperature         float32       `mapstructure:"temperature"`
 	ConfidenceThreshold int           `mapstructure:"confidence_threshold"`
 	Timeout             time.Duration `mapstructure:"timeout"`
+	ChunkConcurrency    int           `mapstructure:"chunk_concurrency"`
 }
 
 // DefaultAIConfig returns default AI configuration
@@ -39,6 +41,7 @@ func DefaultAIConfig() *AIConfig {
 		Temperature:         0.1,
 		ConfidenceThreshold: 85,
 		Timeout:             60 * time.Second,
+		ChunkConcurrency:    4,
 	}
 }
 
@@ -79,6 +82,20 @@ type PositionExtractionResult struct {
 	ExamInfo   *ExtractedExamInfo  `json:"exam_info,omitempty"`
 	Confidence int                 `json:"confidence"`
 	Warnings   []string            `json:"warnings,omitempty"`
+
+	// 分块提取与完整性检查
+	Chunks       int `json:"chunks,omitempty"`        // 分块数
+	FailedChunks int `json:"failed_chunks,omitempty"` // 提取失败的分块数
+	ExpectedRows int `json:"expected_rows,omitempty"` // 结构化解析识别到的职位行数
+}
+
+// PositionExtractionOptions 职位提取选项
+type PositionExtractionOptions struct {
+	// ExpectedRows 结构化解析器（Excel/HTML/PDF/Word）在同一文档中识别到的职位行数，
+	// 用于完整性检查；为 0 时使用分块时检测到的表格行数
+	ExpectedRows int
+	// Complete 发送提取提示词并返回模型输出，用于接入其他 LLM 配置；为空时使用 OpenAI 客户端
+	Complete func(ctx context.Context, prompt string) (string, error)
 }
 
 // ExtractedPosition represents a position extracted by AI
@@ -117,42 +134,80 @@ type ExtractedExamInfo struct {
 
 // ExtractPositions extracts position information from announcement content
 func (e *AIExtractor) ExtractPositions(ctx context.Context, content string) (*PositionExtractionResult, error) {
-	if e.OpenAI == nil {
-		return nil, fmt.Errorf("OpenAI client not initialized")
+	return e.ExtractPositionsWithOptions(ctx, content, nil)
+}
+
+// ExtractPositionsWithOptions extracts positions map-reduce style: content that
+// does not fit MaxInputTokens is split on sheet/page/table/row boundaries,
+// each chunk carries the table header and the announcement preamble, chunks
+// are extracted concurrently and the results merged with dedup and a
+// completeness check against the expected row count.
+func (e *AIExtractor) ExtractPositionsWithOptions(ctx context.Context, content string, opts *PositionExtractionOptions) (*PositionExtractionResult, error) {
+	if opts == nil {
+		opts = &PositionExtractionOptions{}
+	}
+	complete := opts.Complete
+	if complete == nil {
+		if e.OpenAI == nil {
+			return nil, fmt.Errorf("OpenAI client not initialized")
+		}
+		complete = func(ctx context.Context, prompt string) (string, error) {
+			return e.OpenAI.ChatCompletion(ctx, prompt, e.Config.Temperature, e.Config.MaxOutputTokens)
+		}
 	}
 
-	// Truncate content if too long
-	if len(content) > e.Config.MaxInputTokens*2 {
-		content = content[:e.Config.MaxInputTokens*2]
-		if e.Logger != nil {
-			e.Logger.Warn("Content truncated due to length limit",
-				zap.Int("original_length", len(content)),
-				zap.Int("max_tokens", e.Config.MaxInputTokens),
-			)
-		}
+	budget := max(e.Config.MaxInputTokens-positionPromptTokens, minChunkTokens)
+	doc := splitPositionDocument(content, budget)
+	if len(doc.Chunks) > 1 && e.Logger != nil {
+		e.Logger.Info("Content split into chunks for position extraction",
+			zap.Int("content_length", len(content)),
+			zap.Int("chunks", len(doc.Chunks)),
+			zap.Int("detected_rows", doc.Rows),
+		)
 	}
 
-	prompt := buildPositionExtractionPrompt(content)
+	results := make([]*PositionExtractionResult, len(doc.Chunks))
+	errs := make([]error, len(doc.Chunks))
+	sem := make(chan struct{}, max(e.Config.ChunkConcurrency, 1))
+	var wg sync.WaitGroup
+	for i := range doc.Chunks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			results[i], errs[i] = e.extractPositionChunk(ctx, complete, buildPositionChunkContent(doc, i))
+		}(i)
+	}
+	wg.Wait()
 
-	response, err := e.OpenAI.ChatCompletion(ctx, prompt, e.Config.Temperature, e.Config.MaxOutputTokens)
-	if err != nil {
-		return nil, fmt.Errorf("AI extraction failed: %w", err)
+	result := mergePositionChunkResults(results, errs)
+	if result.FailedChunks == len(doc.Chunks) {
+		return nil, errs[0]
 	}
 
-	result, err := parsePositionExtractionResponse(response)
-	if err != nil {
-		if e.Logger != nil {
-			e.Logger.Error("Failed to parse AI response",
-				zap.String("response", response[:min(500, len(response))]),
-				zap.Error(err),
-			)
-		}
-		return nil, fmt.Errorf("failed to parse AI response: %w", err)
+	// 完整性检查：提取到的职位数少于结构化解析的行数时降低置信度
+	result.ExpectedRows = opts.ExpectedRows
+	if result.ExpectedRows == 0 {
+		result.ExpectedRows = doc.Rows
+	}
+	if got := len(result.Positions); result.ExpectedRows > 0 && got < result.ExpectedRows {
+		result.Warnings = append(result.Warnings,
+			fmt.Sprintf("仅提取到 %d 个职位，结构化解析识别到 %d 行，结果可能不完整", got, result.ExpectedRows))
+		result.Confidence = result.Confidence * got / result.ExpectedRows
 	}
 
 	if e.Logger != nil {
 		e.Logger.Info("Position extraction completed",
 			zap.Int("positions_count", len(result.Positions)),
+			zap.Int("expected_rows", result.ExpectedRows),
+			zap.Int("chunks", result.Chunks),
+			zap.Int("failed_chunks", result.FailedChunks),
 			zap.Int("confidence", result.Confidence),
 		)
 	}
@@ -160,6 +215,82 @@ func (e *AIExtractor) ExtractPositions(ctx context.Context, content string) (*Po
 	return result, nil
 }
 
+// extractPositionChunk runs the extraction prompt on one chunk, retrying once
+// when the response cannot be parsed
+func (e *AIExtractor) extractPositionChunk(ctx context.Context, complete func(context.Context, string) (string, error), content string) (*PositionExtractionResult, error) {
+	prompt := buildPositionExtractionPrompt(content)
+
+	var lastErr error
+	for attempt := 0; attempt < 2; attempt++ {
+		response, err := complete(ctx, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("AI extraction failed: %w", err)
+		}
+
+		result, err := parsePositionExtractionResponse(response)
+		if err == nil {
+			return result, nil
+		}
+		if e.Logger != nil {
+			e.Logger.Error("Failed to parse AI response",
+				zap.String("response", response[:min(500, len(response))]),
+				zap.Int("attempt", attempt+1),
+				zap.Error(err),
+			)
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("failed to parse AI response: %w", lastErr)
+}
+
+// mergePositionChunkResults merges chunk results in document order, dropping
+// positions repeated across chunks
+func mergePositionChunkResults(results []*PositionExtractionResult, errs []error) *PositionExtractionResult {
+	merged := &PositionExtractionResult{
+		Positions: make([]ExtractedPosition, 0),
+		Chunks:    len(results),
+	}
+	index := make(map[string]int)
+	seenWarnings := make(map[string]bool)
+	confidenceSum, confidenceWeight := 0, 0
+
+	for i, r := range results {
+		if errs[i] != nil || r == nil {
+			merged.FailedChunks++
+			merged.Warnings = append(merged.Warnings, fmt.Sprintf("第 %d/%d 部分提取失败: %v", i+1, len(results), errs[i]))
+			continue
+		}
+		for _, p := range r.Positions {
+			key := positionKey(&p)
+			if j, ok := index[key]; ok {
+				mergeExtractedPosition(&merged.Positions[j], &p)
+				continue
+			}
+			index[key] = len(merged.Positions)
+			merged.Positions = append(merged.Positions, p)
+		}
+		merged.ExamInfo = mergeExamInfo(merged.ExamInfo, r.ExamInfo)
+		for _, w := range r.Warnings {
+			if !seenWarnings[w] {
+				seenWarnings[w] = true
+				merged.Warnings = append(merged.Warnings, w)
+			}
+		}
+		weight := max(len(r.Positions), 1)
+		confidenceSum += r.Confidence * weight
+		confidenceWeight += weight
+	}
+
+	if confidenceWeight > 0 {
+		merged.Confidence = confidenceSum / confidenceWeight
+	}
+	// 有分块失败时整体结果必然不完整
+	if merged.FailedChunks > 0 {
+		merged.Confidence = merged.Confidence * (merged.Chunks - merged.FailedChunks) / merged.Chunks
+	}
+	return merged
+}
+
 // IdentifyAnnouncementType identifies the type of announcement
 func (e *AIExtractor) IdentifyAnnouncementType(ctx context.Context, title, content string) (string, int, error) {
 	if e.OpenAI == nil {
@@ -406,6 +537,9 @@ type ExtractedScoreLine struct {
 	PublicBasicScore  *float64 `json:"public_basic_score,omitempty"`
 	ProfessionalScore *float64 `json:"professional_score,omitempty"`
 	ApplicableScope   string   `json:"applicable_scope,omitempty"`
+	PositionCode      string   `json:"position_code,omitempty"`
+	PositionName      string   `json:"position_name,omitempty"`
+	DepartmentName    string   `json:"department_name,omitempty"`
 	Confidence        int      `json:"confidence"`
 }
 
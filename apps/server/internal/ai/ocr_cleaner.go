@@ -0,0 +1,99 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// OCRCellInput is a table cell recognized with low confidence
+type OCRCellInput struct {
+	ID     int    `json:"id"`
+	Column string `json:"column"` // header of the cell's column
+	Text   string `json:"text"`
+	Row    string `json:"row"` // whole row for context
+}
+
+// ocrCellCleaningResult is the LLM response of OCR cell cleanup
+type ocrCellCleaningResult struct {
+	Cells []struct {
+		ID   int    `json:"id"`
+		Text string `json:"text"`
+	} `json:"cells"`
+}
+
+// CleanOCRCells asks the LLM to correct misrecognized characters of OCR table
+// cells and returns the corrected text by cell ID
+func (e *AIExtractor) CleanOCRCells(ctx context.Context, cells []OCRCellInput) (map[int]string, error) {
+	if e.OpenAI == nil {
+		return nil, fmt.Errorf("OpenAI client not initialized")
+	}
+	if len(cells) == 0 {
+		return map[int]string{}, nil
+	}
+
+	data, err := json.Marshal(cells)
+	if err != nil {
+		return nil, err
+	}
+	prompt := strings.Replace(ocrCellCleaningPrompt, "{{content}}", string(data), 1)
+
+	response, err := e.OpenAI.ChatCompletion(ctx, prompt, e.Config.Temperature, e.Config.MaxOutputTokens)
+	if err != nil {
+		return nil, fmt.Errorf("LLM OCR cell cleaning failed: %w", err)
+	}
+
+	jsonStr := extractJSON(response)
+	if jsonStr == "" {
+		return nil, fmt.Errorf("no JSON found in response")
+	}
+	var result ocrCellCleaningResult
+	if err := json.Unmarshal([]byte(jsonStr), &result); err != nil {
+		if err := json.Unmarshal([]byte(fixCommonJSONIssues(jsonStr)), &result); err != nil {
+			if e.Logger != nil {
+				e.Logger.Error("Failed to parse OCR cell cleaning response",
+					zap.String("response", response[:min(500, len(response))]),
+					zap.Error(err),
+				)
+			}
+			return nil, fmt.Errorf("failed to parse LLM response: %w", err)
+		}
+	}
+
+	fixes := make(map[int]string, len(result.Cells))
+	for _, c := range result.Cells {
+		fixes[c.ID] = c.Text
+	}
+
+	if e.Logger != nil {
+		e.Logger.Debug("OCR cell cleaning completed",
+			zap.Int("cells", len(cells)),
+			zap.Int("fixed", len(fixes)),
+		)
+	}
+
+	return fixes, nil
+}
+
+const ocrCellCleaningPrompt = `你是公务员/事业单位职位表的 OCR 校对助手。以下单元格来自扫描件识别，置信度较低，可能存在形近字、错字、多余空格或标点错误。
+
+## 要求：
+1. 结合列名（column）和整行内容（row）校正每个单元格的文字（text）
+2. 只修正识别错误，不要补充、改写或合并原文没有的内容
+3. 职位代码、人数等数字按原样保留，仅修正明显的识别错误（如 O→0、l→1）
+4. 无法确定时保持原文
+
+## 输出JSON格式：
+{
+  "cells": [
+    {"id": 单元格id, "text": "校正后的文字"}
+  ]
+}
+
+## 待校正单元格：
+{{content}}
+
+请输出JSON：`
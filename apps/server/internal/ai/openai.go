@@ -3,6 +3,8 @@ package ai
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/sashabaranov/go-openai"
 	"go.uber.org/zap"
@@ -17,10 +19,18 @@ type OpenAIClient struct {
 
 // NewOpenAIClient creates a new OpenAI client
 func NewOpenAIClient(apiKey, baseURL, model string, logger *zap.Logger) *OpenAIClient {
+	return newOpenAIClient(apiKey, baseURL, model, 0, logger)
+}
+
+// newOpenAIClient creates a client whose requests time out after timeout, 0 for no limit
+func newOpenAIClient(apiKey, baseURL, model string, timeout time.Duration, logger *zap.Logger) *OpenAIClient {
 	config := openai.DefaultConfig(apiKey)
 	if baseURL != "" && baseURL != "https://api.openai.com/v1" {
 		config.BaseURL = baseURL
 	}
+	if timeout > 0 {
+		config.HTTPClient = &http.Client{Timeout: timeout}
+	}
 
 	return &OpenAIClient{
 		client: openai.NewClientWithConfig(config),
@@ -105,21 +115,37 @@ func (c *OpenAIClient) ChatCompletionWithHistory(ctx context.Context, messages [
 
 // CreateEmbedding creates an embedding for the given text
 func (c *OpenAIClient) CreateEmbedding(ctx context.Context, text string) ([]float32, error) {
+	vectors, err := c.CreateEmbeddings(ctx, string(openai.AdaEmbeddingV2), []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return vectors[0], nil
+}
+
+// CreateEmbeddings creates embeddings for the given texts in one request,
+// returning one vector per text in input order
+func (c *OpenAIClient) CreateEmbeddings(ctx context.Context, model string, texts []string) ([][]float32, error) {
 	req := openai.EmbeddingRequest{
-		Model: openai.AdaEmbeddingV2,
-		Input: []string{text},
+		Model: openai.EmbeddingModel(model),
+		Input: texts,
 	}
 
 	resp, err := c.client.CreateEmbeddings(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("embedding API error: %w", err)
 	}
-
-	if len(resp.Data) == 0 {
-		return nil, fmt.Errorf("no embedding returned")
+	if len(resp.Data) != len(texts) {
+		return nil, fmt.Errorf("embedding API returned %d vectors for %d inputs", len(resp.Data), len(texts))
 	}
 
-	return resp.Data[0].Embedding, nil
+	vectors := make([][]float32, len(texts))
+	for _, item := range resp.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding API returned invalid index %d", item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	return vectors, nil
 }
 
 // StreamChatCompletion streams a chat completion response
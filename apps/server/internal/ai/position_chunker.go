@@ -0,0 +1,406 @@
+package ai
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ============================================
+// 职位表分块 - Position Document Chunking
+// ============================================
+
+// positionPromptTokens is the rough token cost of the extraction prompt itself
+const positionPromptTokens = 1200
+
+// minChunkTokens keeps chunks useful when the configured budget is tiny
+const minChunkTokens = 500
+
+var (
+	// sectionMarkerRe matches the sheet/page/table markers written by the
+	// parser package, e.g. "=== Sheet: 职位表 ===" or "=== 第 3 页 ==="
+	sectionMarkerRe = regexp.MustCompile(`^=+\s*(.+?)\s*=+$`)
+
+	// positionHeaderKeywords are words found in the header row of a position table
+	positionHeaderKeywords = []string{
+		"职位", "岗位", "招录机关", "用人单位", "招聘单位", "部门", "代码",
+		"人数", "学历", "学位", "专业", "政治面貌", "年龄", "户籍", "备注", "工作地点",
+	}
+)
+
+// positionDocument is an announcement or attachment split for map-reduce extraction
+type positionDocument struct {
+	Preamble string          // announcement text before the first table, repeated as context
+	Chunks   []positionChunk // pieces sent to the model one by one
+	Rows     int             // table data rows detected while splitting
+}
+
+// positionChunk is one token-budgeted piece of a document
+type positionChunk struct {
+	Section string // sheet/page/table the chunk starts in
+	Header  string // table header row in effect, repeated at the top of the chunk
+	Body    string
+	Rows    int  // table data rows in Body
+	Inline  bool // Header already appears in Body, after the text preceding the table
+}
+
+// positionUnit is the smallest piece that is never split across chunks unless
+// it alone exceeds the budget: a table row or a paragraph of text
+type positionUnit struct {
+	section string
+	header  string
+	text    string
+	tokens  int
+	row     bool
+}
+
+// splitPositionDocument splits content on section, table and row boundaries
+// into chunks of at most budget tokens. A document that fits the budget is
+// returned as a single chunk without any context repetition.
+func splitPositionDocument(content string, budget int) *positionDocument {
+	content = strings.ReplaceAll(strings.ReplaceAll(content, "\r\n", "\n"), "\f", "\n")
+	doc := &positionDocument{}
+
+	var (
+		units                  []positionUnit
+		section, header        string
+		headerCells            int
+		paragraph, preamble    []string
+		seenHeader, seenMarker bool
+	)
+	flush := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+		text := strings.Join(paragraph, "\n")
+		units = append(units, positionUnit{section: section, header: header, text: text, tokens: estimateTokens(text)})
+		paragraph = paragraph[:0]
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, " \t")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			flush()
+			continue
+		}
+		if m := sectionMarkerRe.FindStringSubmatch(trimmed); m != nil {
+			// a new sheet, page or table; a page keeps the header of the table
+			// it continues, a sheet or table starts over
+			flush()
+			seenMarker = true
+			section = m[1]
+			if !strings.Contains(section, "页") {
+				header, headerCells = "", 0
+			}
+			continue
+		}
+		if !seenHeader && !seenMarker {
+			preamble = append(preamble, trimmed)
+		}
+
+		cells := splitCells(line)
+		if isPositionHeader(trimmed, cells) {
+			flush()
+			seenHeader = true
+			if len(cells) > 1 {
+				trimmed = strings.Join(cells, "\t")
+			}
+			header, headerCells = trimmed, len(cells)
+			continue
+		}
+		if header != "" && headerCells > 1 && len(cells) >= max(2, headerCells/2) {
+			flush()
+			text := strings.Join(cells, "\t")
+			units = append(units, positionUnit{section: section, header: header, text: text, tokens: estimateTokens(text), row: true})
+			doc.Rows++
+			continue
+		}
+		paragraph = append(paragraph, trimmed)
+	}
+	flush()
+
+	if estimateTokens(content) <= budget {
+		doc.Chunks = []positionChunk{{Body: strings.TrimSpace(content), Rows: doc.Rows}}
+		return doc
+	}
+
+	// the text before the first table usually names the exam and its dates;
+	// without tables the opening paragraphs serve the same purpose
+	contextBudget := budget / 4
+	if !seenHeader {
+		contextBudget = budget / 8
+	}
+	doc.Preamble = truncateTokens(strings.Join(preamble, "\n"), contextBudget)
+
+	var cur *positionChunk
+	curTokens := 0
+	for _, u := range units {
+		limit := budget - estimateTokens(u.header) - estimateTokens(doc.Preamble)
+		if limit < minChunkTokens {
+			limit = minChunkTokens
+		}
+		if cur != nil && cur.Header == "" && u.header != "" && curTokens+u.tokens+estimateTokens(u.header) <= limit {
+			// the text before a table shares the chunk with its first rows
+			cur.Body += "\n" + u.header
+			cur.Header, cur.Inline = u.header, true
+			curTokens += estimateTokens(u.header)
+		}
+		if cur != nil && (cur.Header != u.header || curTokens+u.tokens > limit) {
+			doc.Chunks = append(doc.Chunks, *cur)
+			cur = nil
+		}
+		for _, piece := range splitTokens(u.text, limit) {
+			if cur != nil && curTokens+estimateTokens(piece) > limit {
+				doc.Chunks = append(doc.Chunks, *cur)
+				cur = nil
+			}
+			if cur == nil {
+				cur = &positionChunk{Section: u.section, Header: u.header}
+				curTokens = 0
+			}
+			if cur.Body != "" {
+				cur.Body += "\n"
+			}
+			cur.Body += piece
+			curTokens += estimateTokens(piece)
+		}
+		if u.row {
+			cur.Rows++
+		}
+	}
+	if cur != nil {
+		doc.Chunks = append(doc.Chunks, *cur)
+	}
+	return doc
+}
+
+// splitCells splits a table line written by the parsers (tab separated) or a
+// Markdown style "| a | b |" row into trimmed cells
+func splitCells(line string) []string {
+	var parts []string
+	switch {
+	case strings.Contains(line, "\t"):
+		parts = strings.Split(line, "\t")
+	case strings.Count(line, "|") >= 2:
+		parts = strings.Split(strings.Trim(strings.TrimSpace(line), "|"), "|")
+	default:
+		return []string{strings.TrimSpace(line)}
+	}
+	cells := make([]string, 0, len(parts))
+	for _, p := range parts {
+		cells = append(cells, strings.TrimSpace(p))
+	}
+	// trailing empty cells come from padded spreadsheet rows
+	for len(cells) > 0 && cells[len(cells)-1] == "" {
+		cells = cells[:len(cells)-1]
+	}
+	return cells
+}
+
+// isPositionHeader reports whether a line is the header row of a position table
+func isPositionHeader(line string, cells []string) bool {
+	if utf8.RuneCountInString(line) > 300 {
+		return false
+	}
+	hits := 0
+	for _, kw := range positionHeaderKeywords {
+		if strings.Contains(line, kw) {
+			hits++
+		}
+	}
+	if len(cells) > 1 {
+		// most cells of a header are short labels, data rows carry longer values
+		// and numbers
+		short := 0
+		for _, c := range cells {
+			if c != "" && utf8.RuneCountInString(c) <= 12 && !strings.ContainsAny(c, "0123456789") {
+				short++
+			}
+		}
+		return hits >= 3 && short*2 >= len(cells)
+	}
+	return hits >= 4 && utf8.RuneCountInString(line) <= 120
+}
+
+// estimateTokens approximates the token count of s: CJK characters are about
+// one token each, other text about four bytes per token
+func estimateTokens(s string) int {
+	cjk, other := 0, 0
+	for _, r := range s {
+		if r > unicode.MaxASCII && (unicode.Is(unicode.Han, r) || unicode.IsPunct(r)) {
+			cjk++
+		} else {
+			other += utf8.RuneLen(r)
+		}
+	}
+	return cjk + (other+3)/4
+}
+
+// truncateTokens cuts s to about budget tokens on a rune boundary
+func truncateTokens(s string, budget int) string {
+	if pieces := splitTokens(s, budget); len(pieces) > 0 {
+		return pieces[0]
+	}
+	return ""
+}
+
+// splitTokens splits s into pieces of about budget tokens, preferring line
+// boundaries and never cutting a UTF-8 character
+func splitTokens(s string, budget int) []string {
+	if s == "" {
+		return nil
+	}
+	if budget <= 0 || estimateTokens(s) <= budget {
+		return []string{s}
+	}
+
+	var pieces []string
+	var cur strings.Builder
+	curTokens := 0
+	emit := func() {
+		if cur.Len() > 0 {
+			pieces = append(pieces, strings.TrimRight(cur.String(), "\n"))
+			cur.Reset()
+			curTokens = 0
+		}
+	}
+	for _, line := range strings.SplitAfter(s, "\n") {
+		if t := estimateTokens(line); curTokens+t <= budget {
+			cur.WriteString(line)
+			curTokens += t
+			continue
+		}
+		emit()
+		for _, r := range line {
+			t := estimateTokens(string(r))
+			if curTokens+t > budget {
+				emit()
+			}
+			cur.WriteRune(r)
+			curTokens += t
+		}
+	}
+	emit()
+	return pieces
+}
+
+// buildPositionChunkContent lays out a chunk with its repeated context for
+// buildPositionExtractionPrompt
+func buildPositionChunkContent(doc *positionDocument, index int) string {
+	chunk := doc.Chunks[index]
+	if len(doc.Chunks) == 1 {
+		return chunk.Body
+	}
+
+	var b strings.Builder
+	if doc.Preamble != "" && index > 0 {
+		b.WriteString("【公告背景（节选，仅用于识别考试信息，不要从中提取职位）】\n")
+		b.WriteString(doc.Preamble)
+		b.WriteString("\n\n")
+	}
+	fmt.Fprintf(&b, "【以下为公告内容第 %d/%d 部分", index+1, len(doc.Chunks))
+	if chunk.Section != "" {
+		fmt.Fprintf(&b, "，来自 %s", chunk.Section)
+	}
+	b.WriteString("，只提取本部分中的职位】\n")
+	if chunk.Header != "" && !chunk.Inline {
+		b.WriteString("表头：")
+		b.WriteString(chunk.Header)
+		b.WriteString("\n")
+	}
+	b.WriteString(chunk.Body)
+	return b.String()
+}
+
+// ============================================
+// 分块结果合并 - Merging Chunk Results
+// ============================================
+
+// positionKey identifies a position across chunks: department and position
+// code when the table has codes, otherwise the descriptive fields
+func positionKey(p *ExtractedPosition) string {
+	norm := func(s string) string {
+		return strings.Join(strings.Fields(s), "")
+	}
+	dept := norm(p.DepartmentCode)
+	if dept == "" {
+		dept = norm(p.DepartmentName)
+	}
+	if code := norm(p.PositionCode); code != "" {
+		return dept + "#" + code
+	}
+	return strings.Join([]string{
+		norm(p.DepartmentName), norm(p.PositionName), norm(p.WorkLocation),
+		norm(p.EducationMin), norm(strings.Join(p.MajorSpecific, ",")), fmt.Sprint(p.RecruitCount),
+	}, "#")
+}
+
+// mergeExtractedPosition fills fields of dst that src knows and dst does not
+func mergeExtractedPosition(dst, src *ExtractedPosition) {
+	fill := func(d *string, s string) {
+		if *d == "" {
+			*d = s
+		}
+	}
+	fillInt := func(d **int, s *int) {
+		if *d == nil {
+			*d = s
+		}
+	}
+	fill(&dst.PositionName, src.PositionName)
+	fill(&dst.DepartmentName, src.DepartmentName)
+	fill(&dst.DepartmentCode, src.DepartmentCode)
+	fill(&dst.PositionCode, src.PositionCode)
+	fill(&dst.WorkLocation, src.WorkLocation)
+	fill(&dst.EducationMin, src.EducationMin)
+	fill(&dst.DegreeRequired, src.DegreeRequired)
+	fill(&dst.PoliticalStatus, src.PoliticalStatus)
+	fill(&dst.GenderRequired, src.GenderRequired)
+	fill(&dst.OtherRequirements, src.OtherRequirements)
+	fill(&dst.Notes, src.Notes)
+	fillInt(&dst.AgeMin, src.AgeMin)
+	fillInt(&dst.AgeMax, src.AgeMax)
+	fillInt(&dst.WorkExpYearsMin, src.WorkExpYearsMin)
+	fillInt(&dst.GrassrootsExpYears, src.GrassrootsExpYears)
+	if dst.RecruitCount == 0 {
+		dst.RecruitCount = src.RecruitCount
+	}
+	if len(dst.MajorSpecific) == 0 {
+		dst.MajorSpecific = src.MajorSpecific
+	}
+	if len(dst.HukouProvinces) == 0 {
+		dst.HukouProvinces = src.HukouProvinces
+	}
+	dst.MajorUnlimited = dst.MajorUnlimited || src.MajorUnlimited
+	dst.HukouRequired = dst.HukouRequired || src.HukouRequired
+	dst.FreshGraduateOnly = dst.FreshGraduateOnly || src.FreshGraduateOnly
+	dst.Confidence = max(dst.Confidence, src.Confidence)
+}
+
+// mergeExamInfo fills fields of dst that src knows and dst does not
+func mergeExamInfo(dst, src *ExtractedExamInfo) *ExtractedExamInfo {
+	if src == nil {
+		return dst
+	}
+	if dst == nil {
+		info := *src
+		return &info
+	}
+	for _, f := range []struct {
+		d *string
+		s string
+	}{
+		{&dst.ExamType, src.ExamType},
+		{&dst.RegistrationStart, src.RegistrationStart},
+		{&dst.RegistrationEnd, src.RegistrationEnd},
+		{&dst.ExamDateWritten, src.ExamDateWritten},
+	} {
+		if *f.d == "" {
+			*f.d = f.s
+		}
+	}
+	return dst
+}
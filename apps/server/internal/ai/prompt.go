@@ -314,6 +314,7 @@ var PromptTemplates = map[string]string{
 2. 区分不同类型的分数线（省级以上/市地级/行政执法类等）
 3. 提取分数线对应的职位或类别
 4. 注意识别合格分数线和实际录取分数线的区别
+5. 如果公告按职位列出分数（如进面最低分、拟录用人员成绩），每个职位单独输出一条，并填写职位代码、职位名称和招录单位
 
 ## 分数线类型：
 - 笔试合格线：最低通过笔试的分数
@@ -334,6 +335,9 @@ var PromptTemplates = map[string]string{
       "public_basic_score": 公共基础分数要求(如有),
       "professional_score": 专业科目分数要求(如有),
       "applicable_scope": "适用范围描述",
+      "position_code": "职位代码(按职位列出时)",
+      "position_name": "职位名称(按职位列出时)",
+      "department_name": "招录单位(按职位列出时)",
       "confidence": 置信度(0-100)
     }
   ],
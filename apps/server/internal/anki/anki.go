@@ -0,0 +1,104 @@
+// Package anki reads and writes Anki deck packages (.apkg) and Anki-style
+// delimited text exports. Packages are written in the legacy schema 11 layout
+// (collection.anki2 plus a JSON media map), which every Anki version imports;
+// reading also accepts the newer collection.anki21 and zstd-compressed
+// collection.anki21b layouts.
+package anki
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// Card types as stored in cards.type
+const (
+	CardNew        = 0
+	CardLearning   = 1
+	CardReview     = 2
+	CardRelearning = 3
+)
+
+// Review log types as stored in revlog.type
+const (
+	ReviewLearn    = 0
+	ReviewReview   = 1
+	ReviewRelearn  = 2
+	ReviewFiltered = 3 // early review in a filtered deck
+	ReviewManual   = 4 // rescheduled by hand, not an actual review
+)
+
+// Model describes the note type used for every note of an exported deck
+type Model struct {
+	Name           string
+	Fields         []string
+	QuestionFormat string // front template, e.g. {{Front}}
+	AnswerFormat   string // back template
+	CSS            string
+}
+
+// Note is one Anki note together with the scheduling state of its first card
+type Note struct {
+	GUID       string
+	ModelName  string
+	FieldNames []string // filled when reading
+	Fields     []string // HTML, one per model field
+	Tags       []string
+	Card       *CardState // nil for a new card
+	Reviews    []Review   // oldest first
+}
+
+// Field returns the field with the given name, or "" if the note has none
+func (n *Note) Field(name string) string {
+	for i, f := range n.FieldNames {
+		if f == name && i < len(n.Fields) {
+			return n.Fields[i]
+		}
+	}
+	return ""
+}
+
+// CardState is the scheduling state of a card
+type CardState struct {
+	Type      int
+	Due       *time.Time // nil for new cards
+	Interval  int        // days
+	Ease      float64    // ease factor, e.g. 2.5
+	Reps      int
+	Lapses    int
+	Suspended bool
+}
+
+// Review is one revlog entry
+type Review struct {
+	Time         time.Time
+	Ease         int // answer button, 1 (again) - 4 (easy)
+	Interval     int // days, 0 for a (re)learning step
+	LastInterval int
+	Factor       float64
+	Duration     time.Duration
+	Type         int
+}
+
+// Deck is the content of a package
+type Deck struct {
+	Name  string
+	Model Model
+	Notes []Note
+	Media map[string][]byte // file name -> content
+}
+
+// GUID derives a stable note GUID from a key, so re-exporting the same card
+// updates the note in Anki instead of duplicating it
+func GUID(key string) string {
+	sum := sha1.Sum([]byte(key))
+	return hex.EncodeToString(sum[:])[:10]
+}
+
+// checksum is the first-field checksum Anki uses for duplicate detection
+func checksum(field string) int64 {
+	sum := sha1.Sum([]byte(StripHTML(field)))
+	v, _ := strconv.ParseInt(hex.EncodeToString(sum[:])[:8], 16, 64)
+	return v
+}
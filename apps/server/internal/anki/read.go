@@ -0,0 +1,432 @@
+package anki
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ErrNoCollection is returned for zip files that contain no Anki collection
+var ErrNoCollection = errors.New("anki: package contains no collection")
+
+// Size limits of the entries read from a package, applied both to the zip
+// entry and to its zstd-decompressed content
+const (
+	maxCollectionFile = 512 << 20
+	maxMediaMapFile   = 16 << 20
+	maxMediaFile      = 20 << 20
+)
+
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+// Package is the content read from an .apkg file
+type Package struct {
+	Notes []Note
+	media map[string]*zip.File
+}
+
+// Media returns the content of a media file referenced by a note field
+func (p *Package) Media(name string) ([]byte, error) {
+	f, ok := p.media[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	data, err := readZipFile(f, maxMediaFile)
+	if err != nil {
+		return nil, err
+	}
+	return decompress(data, maxMediaFile)
+}
+
+// ReadPackage reads the notes, first-card scheduling state and review history
+// of an .apkg file. The newest collection format present in the package is used.
+func ReadPackage(r io.ReaderAt, size int64) (*Package, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("anki: open package: %w", err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var collection *zip.File
+	for _, name := range []string{"collection.anki21b", "collection.anki21", "collection.anki2"} {
+		if f, ok := files[name]; ok {
+			collection = f
+			break
+		}
+	}
+	if collection == nil {
+		return nil, ErrNoCollection
+	}
+	data, err := readZipFile(collection, maxCollectionFile)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = decompress(data, maxCollectionFile); err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "apkg-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "collection.db")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return nil, err
+	}
+
+	notes, err := readCollection(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := &Package{Notes: notes, media: make(map[string]*zip.File)}
+	if f, ok := files["media"]; ok {
+		names, err := readMediaMap(f)
+		if err != nil {
+			return nil, err
+		}
+		for key, name := range names {
+			if zf, ok := files[key]; ok {
+				pkg.media[name] = zf
+			}
+		}
+	}
+	return pkg, nil
+}
+
+// readZipFile reads a zip entry of at most limit bytes. The size in the
+// entry header is not trusted.
+func readZipFile(f *zip.File, limit int64) ([]byte, error) {
+	if f.UncompressedSize64 > uint64(limit) {
+		return nil, fmt.Errorf("anki: %s too large", f.Name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("anki: %s too large", f.Name)
+	}
+	return data, nil
+}
+
+// decompress undoes the zstd compression used by Anki 2.1.50+ packages,
+// refusing output larger than limit bytes
+func decompress(data []byte, limit int64) ([]byte, error) {
+	if !bytes.HasPrefix(data, zstdMagic) {
+		return data, nil
+	}
+	dec, err := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(uint64(limit)))
+	if err != nil {
+		return nil, err
+	}
+	defer dec.Close()
+	out, err := dec.DecodeAll(data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("anki: decompress: %w", err)
+	}
+	return out, nil
+}
+
+// readMediaMap returns zip entry name -> media file name. Legacy packages store
+// a JSON object; newer ones a zstd-compressed protobuf list whose index is the
+// entry name.
+func readMediaMap(f *zip.File) (map[string]string, error) {
+	data, err := readZipFile(f, maxMediaMapFile)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string)
+	if json.Unmarshal(data, &names) == nil {
+		return names, nil
+	}
+	if data, err = decompress(data, maxMediaMapFile); err != nil {
+		return nil, err
+	}
+	entries, err := parseMediaEntries(data)
+	if err != nil {
+		return nil, fmt.Errorf("anki: read media map: %w", err)
+	}
+	for i, name := range entries {
+		names[strconv.Itoa(i)] = name
+	}
+	return names, nil
+}
+
+// parseMediaEntries decodes MediaEntries{repeated MediaEntry entries = 1},
+// keeping the name (field 1) of each entry
+func parseMediaEntries(data []byte) ([]string, error) {
+	var names []string
+	err := walkProto(data, func(field int, value []byte) error {
+		if field != 1 {
+			return nil
+		}
+		name := ""
+		err := walkProto(value, func(field int, value []byte) error {
+			if field == 1 {
+				name = string(value)
+			}
+			return nil
+		})
+		names = append(names, name)
+		return err
+	})
+	return names, err
+}
+
+// walkProto calls fn for every length-delimited field of a protobuf message,
+// skipping varint and fixed-width fields
+func walkProto(data []byte, fn func(field int, value []byte) error) error {
+	for len(data) > 0 {
+		key, n := uvarint(data)
+		if n <= 0 {
+			return errors.New("malformed protobuf")
+		}
+		data = data[n:]
+		field, wire := int(key>>3), key&7
+		switch wire {
+		case 0:
+			_, n = uvarint(data)
+			if n <= 0 {
+				return errors.New("malformed protobuf")
+			}
+			data = data[n:]
+		case 1:
+			if len(data) < 8 {
+				return errors.New("malformed protobuf")
+			}
+			data = data[8:]
+		case 5:
+			if len(data) < 4 {
+				return errors.New("malformed protobuf")
+			}
+			data = data[4:]
+		case 2:
+			length, n := uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < length {
+				return errors.New("malformed protobuf")
+			}
+			value := data[n : n+int(length)]
+			data = data[n+int(length):]
+			if err := fn(field, value); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %d", wire)
+		}
+	}
+	return nil
+}
+
+func uvarint(data []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < len(data) && i < 10; i++ {
+		b := data[i]
+		v |= uint64(b&0x7f) << (7 * i)
+		if b < 0x80 {
+			return v, i + 1
+		}
+	}
+	return 0, 0
+}
+
+type noteType struct {
+	name   string
+	fields []string
+}
+
+func readCollection(path string) ([]Note, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var crt int64
+	var modelsJSON string
+	if err := db.QueryRow(`SELECT crt, models FROM col`).Scan(&crt, &modelsJSON); err != nil {
+		return nil, fmt.Errorf("anki: read collection: %w", err)
+	}
+	types, err := readNoteTypes(db, modelsJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT id, guid, mid, tags, flds FROM notes ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	var notes []Note
+	index := make(map[int64]int)
+	for rows.Next() {
+		var id, mid int64
+		var guid, tags, flds string
+		if err := rows.Scan(&id, &guid, &mid, &tags, &flds); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		note := Note{GUID: guid, Fields: strings.Split(flds, "\x1f"), Tags: strings.Fields(tags)}
+		if t, ok := types[mid]; ok {
+			note.ModelName, note.FieldNames = t.name, t.fields
+		}
+		index[id] = len(notes)
+		notes = append(notes, note)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// scheduling state of the first card of every note
+	rows, err = db.Query(`SELECT id, nid, type, queue, due, ivl, factor, reps, lapses FROM cards ORDER BY nid, ord`)
+	if err != nil {
+		return nil, err
+	}
+	cardNote := make(map[int64]int)
+	start := time.Unix(crt, 0)
+	for rows.Next() {
+		var id, nid, due int64
+		var cardType, queue, ivl, factor, reps, lapses int
+		if err := rows.Scan(&id, &nid, &cardType, &queue, &due, &ivl, &factor, &reps, &lapses); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		i, ok := index[nid]
+		if !ok || notes[i].Card != nil {
+			continue
+		}
+		cardNote[id] = i
+		state := &CardState{Type: cardType, Interval: ivl, Ease: float64(factor) / 1000, Reps: reps, Lapses: lapses, Suspended: queue == -1}
+		if cardType != CardNew {
+			var dueAt time.Time
+			if queue == 1 || (queue < 0 && due > 1_000_000_000) {
+				dueAt = time.Unix(due, 0) // learning cards are due at a timestamp
+			} else {
+				dueAt = start.AddDate(0, 0, int(due))
+			}
+			state.Due = &dueAt
+		}
+		notes[i].Card = state
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.Query(`SELECT id, cid, ease, ivl, lastIvl, factor, time, type FROM revlog ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, cid int64
+		var ease, ivl, lastIvl, factor, ms, revType int
+		if err := rows.Scan(&id, &cid, &ease, &ivl, &lastIvl, &factor, &ms, &revType); err != nil {
+			return nil, err
+		}
+		i, ok := cardNote[cid]
+		if !ok {
+			continue
+		}
+		notes[i].Reviews = append(notes[i].Reviews, Review{
+			Time:         time.UnixMilli(id),
+			Ease:         ease,
+			Interval:     max(ivl, 0),
+			LastInterval: max(lastIvl, 0),
+			Factor:       float64(factor) / 1000,
+			Duration:     time.Duration(ms) * time.Millisecond,
+			Type:         revType,
+		})
+	}
+	return notes, rows.Err()
+}
+
+// readNoteTypes reads field names per note type, from the notetypes/fields
+// tables of schema 18 collections or the col.models JSON of older ones
+func readNoteTypes(db *sql.DB, modelsJSON string) (map[int64]noteType, error) {
+	types := make(map[int64]noteType)
+
+	var table string
+	err := db.QueryRow(`SELECT name FROM sqlite_master WHERE type = 'table' AND name = 'notetypes'`).Scan(&table)
+	if err == nil {
+		rows, err := db.Query(`SELECT id, name FROM notetypes`)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var id int64
+			var name string
+			if err := rows.Scan(&id, &name); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			types[id] = noteType{name: name}
+		}
+		rows.Close()
+
+		rows, err = db.Query(`SELECT ntid, name FROM fields ORDER BY ntid, ord`)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var id int64
+			var name string
+			if err := rows.Scan(&id, &name); err != nil {
+				return nil, err
+			}
+			t := types[id]
+			t.fields = append(t.fields, name)
+			types[id] = t
+		}
+		return types, rows.Err()
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	var models map[string]struct {
+		Name   string `json:"name"`
+		Fields []struct {
+			Name string `json:"name"`
+			Ord  int    `json:"ord"`
+		} `json:"flds"`
+	}
+	if err := json.Unmarshal([]byte(modelsJSON), &models); err != nil {
+		return nil, fmt.Errorf("anki: read note types: %w", err)
+	}
+	for key, m := range models {
+		id, err := strconv.ParseInt(key, 10, 64)
+		if err != nil {
+			continue
+		}
+		sort.Slice(m.Fields, func(i, j int) bool { return m.Fields[i].Ord < m.Fields[j].Ord })
+		t := noteType{name: m.Name}
+		for _, f := range m.Fields {
+			t.fields = append(t.fields, f.Name)
+		}
+		types[id] = t
+	}
+	return types, nil
+}
@@ -0,0 +1,204 @@
+package anki
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	soundRe     = regexp.MustCompile(`\[sound:[^\]]*\]`)
+	breakRe     = regexp.MustCompile(`(?i)<br\s*/?>|</(div|p|li|tr|h[1-6])>`)
+	imgRe       = regexp.MustCompile(`(?i)<img[^>]*?\bsrc\s*=\s*["']?([^"'\s>]+)["']?[^>]*>`)
+	tagRe       = regexp.MustCompile(`<[^>]*>`)
+	blankLineRe = regexp.MustCompile(`\n{3,}`)
+)
+
+// StripHTML removes tags, media references and entities from a field
+func StripHTML(field string) string {
+	s := soundRe.ReplaceAllString(field, "")
+	s = tagRe.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	return strings.TrimSpace(strings.ReplaceAll(s, "\u00a0", " "))
+}
+
+// HTMLToText converts a field to plain text with line breaks. Images are
+// replaced by image(src); return "" to drop an image. Sound tags are dropped.
+func HTMLToText(field string, image func(src string) string) string {
+	s := soundRe.ReplaceAllString(field, "")
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = breakRe.ReplaceAllString(s, "\n")
+	s = imgRe.ReplaceAllStringFunc(s, func(tag string) string {
+		if image == nil {
+			return ""
+		}
+		m := imgRe.FindStringSubmatch(tag)
+		return image(html.UnescapeString(m[1]))
+	})
+	s = tagRe.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	s = strings.ReplaceAll(s, "\u00a0", " ")
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	s = blankLineRe.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(s)
+}
+
+// TextToHTML escapes plain text for an Anki field, keeping line breaks
+func TextToHTML(text string) string {
+	s := html.EscapeString(strings.ReplaceAll(text, "\r\n", "\n"))
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+// Table is a parsed delimited text file
+type Table struct {
+	Header     []string // column names, nil when the file has none
+	Rows       [][]string
+	HTML       bool // fields contain HTML (#html:true)
+	TagsColumn int  // 1-based column holding space-separated tags, 0 if none
+	GUIDColumn int  // 1-based column holding note GUIDs, 0 if none
+}
+
+// Column resolves a column reference, either a header name or a 1-based
+// index, to a 0-based index; it returns -1 when there is no such column.
+func (t *Table) Column(ref string) int {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return -1
+	}
+	for i, name := range t.Header {
+		if strings.EqualFold(strings.TrimSpace(name), ref) {
+			return i
+		}
+	}
+	if n, err := strconv.Atoi(ref); err == nil && n >= 1 {
+		return n - 1
+	}
+	return -1
+}
+
+// ReadText parses a CSV/TSV file. Anki's file headers (#separator:, #html:,
+// #columns:, #tags column:, #guid column:) are honoured; otherwise a zero
+// delimiter is sniffed from the first line and hasHeader decides whether the
+// first row names the columns.
+func ReadText(r io.Reader, delimiter rune, hasHeader bool) (*Table, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))
+
+	table := &Table{}
+	var columns string
+	for len(data) > 0 && data[0] == '#' {
+		end := bytes.IndexByte(data, '\n')
+		if end < 0 {
+			end = len(data)
+		}
+		line := strings.TrimSpace(string(data[1:end]))
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			break
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "separator":
+			delimiter = parseSeparator(value)
+		case "html":
+			table.HTML = strings.EqualFold(strings.TrimSpace(value), "true")
+		case "columns":
+			columns = strings.TrimSpace(value) // split once the delimiter is known
+		case "tags column":
+			table.TagsColumn, _ = strconv.Atoi(strings.TrimSpace(value))
+		case "guid column":
+			table.GUIDColumn, _ = strconv.Atoi(strings.TrimSpace(value))
+		}
+		if end == len(data) {
+			data = nil
+		} else {
+			data = data[end+1:]
+		}
+	}
+
+	if delimiter == 0 {
+		delimiter = sniffDelimiter(data)
+	}
+	if columns != "" {
+		table.Header = splitLine(columns, delimiter)
+		hasHeader = false
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = delimiter
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parse delimited text: %w", err)
+	}
+
+	for _, row := range rows {
+		if len(row) == 1 && strings.TrimSpace(row[0]) == "" {
+			continue
+		}
+		if hasHeader && table.Header == nil {
+			table.Header = row
+			continue
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table, nil
+}
+
+func parseSeparator(value string) rune {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "comma":
+		return ','
+	case "semicolon":
+		return ';'
+	case "tab":
+		return '\t'
+	case "space":
+		return ' '
+	case "pipe":
+		return '|'
+	case "colon":
+		return ':'
+	}
+	if r := []rune(value); len(r) == 1 {
+		return r[0]
+	}
+	return 0
+}
+
+// sniffDelimiter picks the most frequent of tab, comma and semicolon on the first line
+func sniffDelimiter(data []byte) rune {
+	line := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		line = data[:i]
+	}
+	best, bestCount := ',', 0
+	for _, d := range []rune{'\t', ',', ';'} {
+		if n := bytes.Count(line, []byte(string(d))); n > bestCount {
+			best, bestCount = d, n
+		}
+	}
+	return best
+}
+
+func splitLine(line string, delimiter rune) []string {
+	reader := csv.NewReader(strings.NewReader(line))
+	reader.Comma = delimiter
+	reader.LazyQuotes = true
+	if fields, err := reader.Read(); err == nil {
+		return fields
+	}
+	return strings.Split(line, string(delimiter))
+}
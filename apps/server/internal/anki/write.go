@@ -0,0 +1,313 @@
+package anki
+
+import (
+	"archive/zip"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const schema11 = `
+CREATE TABLE col (
+	id integer primary key, crt integer not null, mod integer not null, scm integer not null,
+	ver integer not null, dty integer not null, usn integer not null, ls integer not null,
+	conf text not null, models text not null, decks text not null, dconf text not null, tags text not null
+);
+CREATE TABLE notes (
+	id integer primary key, guid text not null, mid integer not null, mod integer not null,
+	usn integer not null, tags text not null, flds text not null, sfld integer not null,
+	csum integer not null, flags integer not null, data text not null
+);
+CREATE TABLE cards (
+	id integer primary key, nid integer not null, did integer not null, ord integer not null,
+	mod integer not null, usn integer not null, type integer not null, queue integer not null,
+	due integer not null, ivl integer not null, factor integer not null, reps integer not null,
+	lapses integer not null, left integer not null, odue integer not null, odid integer not null,
+	flags integer not null, data text not null
+);
+CREATE TABLE revlog (
+	id integer primary key, cid integer not null, usn integer not null, ease integer not null,
+	ivl integer not null, lastIvl integer not null, factor integer not null, time integer not null,
+	type integer not null
+);
+CREATE TABLE graves (usn integer not null, oid integer not null, type integer not null);
+CREATE INDEX ix_notes_usn on notes (usn);
+CREATE INDEX ix_cards_usn on cards (usn);
+CREATE INDEX ix_revlog_usn on revlog (usn);
+CREATE INDEX ix_cards_nid on cards (nid);
+CREATE INDEX ix_cards_sched on cards (did, queue, due);
+CREATE INDEX ix_revlog_cid on revlog (cid);
+CREATE INDEX ix_notes_csum on notes (csum);
+`
+
+// learningStepSeconds is written as the interval of (re)learning revlog entries
+const learningStepSeconds = 600
+
+// WritePackage writes deck as an .apkg package. Notes with a Card keep their
+// scheduling state and review history; the others are imported as new cards.
+func WritePackage(w io.Writer, deck *Deck) error {
+	dir, err := os.MkdirTemp("", "apkg-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "collection.anki2")
+	if err := writeCollection(path, deck); err != nil {
+		return err
+	}
+	collection, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	if err := writeZipFile(zw, "collection.anki2", collection); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(deck.Media))
+	for name := range deck.Media {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	mediaMap := make(map[string]string, len(names))
+	for i, name := range names {
+		key := strconv.Itoa(i)
+		mediaMap[key] = name
+		if err := writeZipFile(zw, key, deck.Media[name]); err != nil {
+			return err
+		}
+	}
+	mediaJSON, err := json.Marshal(mediaMap)
+	if err != nil {
+		return err
+	}
+	if err := writeZipFile(zw, "media", mediaJSON); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func writeZipFile(zw *zip.Writer, name string, data []byte) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+func writeCollection(path string, deck *Deck) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(schema11); err != nil {
+		return fmt.Errorf("create collection: %w", err)
+	}
+
+	now := time.Now()
+	y, m, d := now.Date()
+	crt := time.Date(y, m, d, 4, 0, 0, 0, now.Location()) // Anki's day starts at 4am
+	if crt.After(now) {
+		crt = crt.AddDate(0, 0, -1)
+	}
+	modelID := now.UnixMilli()
+	deckID := modelID + 1
+
+	colJSON, err := collectionJSON(deck, modelID, deckID, now, len(deck.Notes))
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT INTO col VALUES (1, ?, ?, ?, 11, 0, 0, 0, ?, ?, ?, ?, '{}')`,
+		crt.Unix(), now.UnixMilli(), now.UnixMilli(),
+		colJSON.conf, colJSON.models, colJSON.decks, colJSON.dconf); err != nil {
+		return err
+	}
+
+	noteStmt, err := tx.Prepare(`INSERT INTO notes VALUES (?, ?, ?, ?, -1, ?, ?, ?, ?, 0, '')`)
+	if err != nil {
+		return err
+	}
+	defer noteStmt.Close()
+	cardStmt, err := tx.Prepare(`INSERT INTO cards VALUES (?, ?, ?, 0, ?, -1, ?, ?, ?, ?, ?, ?, ?, 0, 0, 0, 0, '')`)
+	if err != nil {
+		return err
+	}
+	defer cardStmt.Close()
+	revStmt, err := tx.Prepare(`INSERT INTO revlog VALUES (?, ?, -1, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer revStmt.Close()
+
+	fieldCount := len(deck.Model.Fields)
+	usedRevIDs := make(map[int64]bool)
+	for i, note := range deck.Notes {
+		noteID := modelID - int64(len(deck.Notes)) + int64(i) // unique, before the model id
+		cardID := noteID
+		fields := make([]string, fieldCount)
+		copy(fields, note.Fields)
+		guid := note.GUID
+		if guid == "" {
+			guid = GUID(strings.Join(fields, "\x1f"))
+		}
+		tags := ""
+		if len(note.Tags) > 0 {
+			tags = " " + strings.Join(sanitizeTags(note.Tags), " ") + " "
+		}
+		if _, err := noteStmt.Exec(noteID, guid, modelID, now.Unix(), tags,
+			strings.Join(fields, "\x1f"), StripHTML(fields[0]), checksum(fields[0])); err != nil {
+			return err
+		}
+
+		cardType, queue, due, ivl, factor, reps, lapses := CardNew, 0, int64(i+1), 0, 0, 0, 0
+		if c := note.Card; c != nil && c.Type != CardNew && c.Due != nil {
+			cardType, ivl, reps, lapses = c.Type, c.Interval, c.Reps, c.Lapses
+			factor = int(c.Ease * 1000)
+			if factor < 1300 {
+				factor = 2500
+			}
+			if cardType == CardReview {
+				queue = 2
+				due = int64(c.Due.Sub(crt).Hours() / 24)
+			} else {
+				queue = 1
+				due = c.Due.Unix()
+			}
+			if c.Suspended {
+				queue = -1
+			}
+		}
+		if _, err := cardStmt.Exec(cardID, noteID, deckID, now.Unix(), cardType, queue, due, ivl, factor, reps, lapses); err != nil {
+			return err
+		}
+
+		for _, r := range note.Reviews {
+			id := r.Time.UnixMilli()
+			for usedRevIDs[id] {
+				id++
+			}
+			usedRevIDs[id] = true
+			if _, err := revStmt.Exec(id, cardID, r.Ease, revlogInterval(r.Interval), revlogInterval(r.LastInterval),
+				int(r.Factor*1000), r.Duration.Milliseconds(), r.Type); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// revlogInterval stores learning steps as negative seconds, like Anki does
+func revlogInterval(days int) int {
+	if days <= 0 {
+		return -learningStepSeconds
+	}
+	return days
+}
+
+// sanitizeTags replaces whitespace inside tags, which Anki uses as separator
+func sanitizeTags(tags []string) []string {
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = strings.Join(strings.Fields(t), "_")
+		if t != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+type colValues struct {
+	conf, models, decks, dconf string
+}
+
+func collectionJSON(deck *Deck, modelID, deckID int64, now time.Time, noteCount int) (*colValues, error) {
+	fields := make([]map[string]interface{}, len(deck.Model.Fields))
+	for i, name := range deck.Model.Fields {
+		fields[i] = map[string]interface{}{
+			"name": name, "ord": i, "sticky": false, "rtl": false,
+			"font": "Arial", "size": 20, "media": []string{},
+		}
+	}
+	models := map[string]interface{}{
+		strconv.FormatInt(modelID, 10): map[string]interface{}{
+			"id": modelID, "name": deck.Model.Name, "type": 0, "mod": now.Unix(), "usn": -1,
+			"sortf": 0, "did": deckID, "flds": fields, "css": deck.Model.CSS,
+			"tmpls": []map[string]interface{}{{
+				"name": "Card 1", "ord": 0, "qfmt": deck.Model.QuestionFormat,
+				"afmt": deck.Model.AnswerFormat, "did": nil, "bqfmt": "", "bafmt": "",
+			}},
+			"latexPre":  "\\documentclass[12pt]{article}\n\\special{papersize=3in,5in}\n\\usepackage[utf8]{inputenc}\n\\usepackage{amssymb,amsmath}\n\\pagestyle{empty}\n\\setlength{\\parindent}{0in}\n\\begin{document}\n",
+			"latexPost": "\\end{document}",
+			"latexsvg":  false,
+			"req":       []interface{}{[]interface{}{0, "any", []int{0}}},
+			"tags":      []string{},
+			"vers":      []int{},
+		},
+	}
+	deckJSON := func(id int64, name string) map[string]interface{} {
+		return map[string]interface{}{
+			"id": id, "name": name, "mod": now.Unix(), "usn": -1, "desc": "", "dyn": 0, "conf": 1,
+			"collapsed": false, "browserCollapsed": false, "extendNew": 0, "extendRev": 0,
+			"newToday": []int{0, 0}, "revToday": []int{0, 0}, "lrnToday": []int{0, 0}, "timeToday": []int{0, 0},
+		}
+	}
+	decks := map[string]interface{}{
+		"1":                           deckJSON(1, "Default"),
+		strconv.FormatInt(deckID, 10): deckJSON(deckID, deck.Name),
+	}
+	dconf := map[string]interface{}{
+		"1": map[string]interface{}{
+			"id": 1, "name": "Default", "mod": 0, "usn": 0, "maxTaken": 60, "autoplay": true,
+			"timer": 0, "replayq": true, "dyn": false,
+			"new": map[string]interface{}{
+				"bury": false, "delays": []int{1, 10}, "initialFactor": 2500,
+				"ints": []int{1, 4, 0}, "order": 1, "perDay": 20,
+			},
+			"lapse": map[string]interface{}{
+				"delays": []int{10}, "leechAction": 1, "leechFails": 8, "minInt": 1, "mult": 0,
+			},
+			"rev": map[string]interface{}{
+				"bury": false, "ease4": 1.3, "ivlFct": 1, "maxIvl": 36500, "perDay": 200, "hardFactor": 1.2,
+			},
+		},
+	}
+	conf := map[string]interface{}{
+		"activeDecks": []int64{deckID}, "curDeck": deckID, "newSpread": 0, "collapseTime": 1200,
+		"timeLim": 0, "estTimes": true, "dueCounts": true, "curModel": modelID,
+		"nextPos": noteCount + 1, "sortType": "noteFld", "sortBackwards": false, "addToCur": true,
+	}
+
+	values := &colValues{}
+	for _, item := range []struct {
+		dst *string
+		v   interface{}
+	}{{&values.conf, conf}, {&values.models, models}, {&values.decks, decks}, {&values.dconf, dconf}} {
+		data, err := json.Marshal(item.v)
+		if err != nil {
+			return nil, err
+		}
+		*item.dst = string(data)
+	}
+	return values, nil
+}
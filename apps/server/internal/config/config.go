@@ -8,24 +8,40 @@ import (
 )
 
 type Config struct {
-	Server        ServerConfig        `mapstructure:"server"`
-	Database      DatabaseConfig      `mapstructure:"database"`
-	Redis         RedisConfig         `mapstructure:"redis"`
-	JWT           JWTConfig           `mapstructure:"jwt"`
-	Log           LogConfig           `mapstructure:"log"`
-	Elasticsearch ElasticsearchConfig `mapstructure:"elasticsearch"`
-	Crawler       CrawlerConfig       `mapstructure:"crawler"`
-	AI            AIConfig            `mapstructure:"ai"`
-	Scheduler     SchedulerConfig     `mapstructure:"scheduler"`
-	Schedule      ScheduleConfig      `mapstructure:"schedule"`
-	OCR           OCRConfig           `mapstructure:"ocr"`
+	Server           ServerConfig           `mapstructure:"server"`
+	Database         DatabaseConfig         `mapstructure:"database"`
+	Redis            RedisConfig            `mapstructure:"redis"`
+	JWT              JWTConfig              `mapstructure:"jwt"`
+	Log              LogConfig              `mapstructure:"log"`
+	Elasticsearch    ElasticsearchConfig    `mapstructure:"elasticsearch"`
+	Crawler          CrawlerConfig          `mapstructure:"crawler"`
+	AI               AIConfig               `mapstructure:"ai"`
+	Scheduler        SchedulerConfig        `mapstructure:"scheduler"`
+	Schedule         ScheduleConfig         `mapstructure:"schedule"`
+	OCR              OCRConfig              `mapstructure:"ocr"`
+	Embedding        EmbeddingConfig        `mapstructure:"embedding"`
+	QuestionDedup    QuestionDedupConfig    `mapstructure:"question_dedup"`
+	Export           ExportConfig           `mapstructure:"export"`
+	IRT              IRTConfig              `mapstructure:"irt"`
+	Knowledge        KnowledgeConfig        `mapstructure:"knowledge_tracing"`
+	Review           ReviewConfig           `mapstructure:"review"`
+	AnnouncementLink AnnouncementLinkConfig `mapstructure:"announcement_link"`
+	AttachmentStore  AttachmentStoreConfig  `mapstructure:"attachment_store"`
+	Feed             FeedConfig             `mapstructure:"feed"`
+	ArticleFeed      ArticleFeedConfig      `mapstructure:"article_feed"`
 }
 
 type ElasticsearchConfig struct {
 	Addresses []string `mapstructure:"addresses"`
 	Username  string   `mapstructure:"username"`
 	Password  string   `mapstructure:"password"`
-	IndexName string   `mapstructure:"index_name"`
+	IndexName string   `mapstructure:"index_name"` // 索引别名，实际索引为 <index_name>_v<时间戳>
+	Shards    int      `mapstructure:"shards"`
+	Replicas  int      `mapstructure:"replicas"`
+
+	SyncIntervalSeconds int `mapstructure:"sync_interval_seconds"` // 发件箱同步间隔
+	SyncBatchSize       int `mapstructure:"sync_batch_size"`       // 每批同步数量
+	KeepIndices         int `mapstructure:"keep_indices"`          // 重建后保留的旧版本索引数
 }
 
 type ServerConfig struct {
@@ -70,6 +86,21 @@ type CrawlerConfig struct {
 	UserAgent          string        `mapstructure:"user_agent"`
 	ProxyEnabled       bool          `mapstructure:"proxy_enabled"`
 	ProxyURL           string        `mapstructure:"proxy_url"`
+	// 按域名限速、robots.txt 与 5xx 熔断
+	DomainParallelism int                  `mapstructure:"domain_parallelism"`
+	DomainLimits      []CrawlerDomainLimit `mapstructure:"domain_limits"`
+	RespectRobotsTxt  bool                 `mapstructure:"respect_robots_txt"`
+	BreakerThreshold  int                  `mapstructure:"breaker_threshold"`
+	BreakerCooldown   time.Duration        `mapstructure:"breaker_cooldown"`
+	// 非空时把所有爬虫请求与响应录制到该目录，作为离线回放测试的 fixture
+	RecordDir string `mapstructure:"record_dir"`
+}
+
+// CrawlerDomainLimit 单个域名（支持 *.gov.cn 通配）的并发与请求间隔
+type CrawlerDomainLimit struct {
+	Domain      string        `mapstructure:"domain"`
+	Parallelism int           `mapstructure:"parallelism"`
+	Delay       time.Duration `mapstructure:"delay"`
 }
 
 // AIConfig holds AI-related configuration
@@ -81,6 +112,7 @@ type AIConfig struct {
 	Temperature         float32       `mapstructure:"temperature"`
 	ConfidenceThreshold int           `mapstructure:"confidence_threshold"`
 	Timeout             time.Duration `mapstructure:"timeout"`
+	ChunkConcurrency    int           `mapstructure:"chunk_concurrency"` // 长公告分块提取的并发数
 }
 
 // OpenAIConfig holds OpenAI-specific configuration
@@ -113,9 +145,118 @@ type ListMonitorSchedule struct {
 
 // OCRConfig holds OCR-related configuration
 type OCRConfig struct {
-	Engine       string `mapstructure:"engine"`
-	TesseractCmd string `mapstructure:"tesseract_cmd"`
-	Language     string `mapstructure:"language"`
+	Engine        string  `mapstructure:"engine"`
+	TesseractCmd  string  `mapstructure:"tesseract_cmd"`
+	Language      string  `mapstructure:"language"`
+	APIURL        string  `mapstructure:"api_url"`
+	APIKey        string  `mapstructure:"api_key"`
+	Timeout       int     `mapstructure:"timeout"`        // 单页识别超时（秒）
+	DPI           int     `mapstructure:"dpi"`            // 扫描版 PDF 渲染分辨率
+	PdftoppmCmd   string  `mapstructure:"pdftoppm_cmd"`   // poppler pdftoppm，未安装时直接提取页面内嵌图片
+	LowConfidence float64 `mapstructure:"low_confidence"` // 低于该置信度的单元格交给 LLM 校对，0 关闭
+}
+
+// EmbeddingConfig holds text embedding configuration (similar questions, dedup)
+type EmbeddingConfig struct {
+	Provider           string  `mapstructure:"provider"`            // llm: 使用 LLM 配置; local: 本地哈希向量
+	LLMConfigID        uint    `mapstructure:"llm_config_id"`       // 0 表示默认 LLM 配置
+	Model              string  `mapstructure:"model"`               // 嵌入模型，LLM 配置 extra_params.embedding_model 优先
+	Dimensions         int     `mapstructure:"dimensions"`          // 本地哈希向量维度
+	BatchSize          int     `mapstructure:"batch_size"`          // 每次请求的文本数
+	IntervalSeconds    int     `mapstructure:"interval_seconds"`    // 增量向量化间隔
+	DuplicateThreshold float64 `mapstructure:"duplicate_threshold"` // 判定重复的余弦相似度
+}
+
+// QuestionDedupConfig holds MinHash/LSH near-duplicate detection configuration
+type QuestionDedupConfig struct {
+	ShingleSize     int     `mapstructure:"shingle_size"`     // 字符 n-gram 长度
+	NumHashes       int     `mapstructure:"num_hashes"`       // MinHash 签名长度，须为 bands 的整数倍
+	Bands           int     `mapstructure:"bands"`            // LSH 分段数
+	RejectThreshold float64 `mapstructure:"reject_threshold"` // 估计 Jaccard 不低于该值时拒绝导入
+	FlagThreshold   float64 `mapstructure:"flag_threshold"`   // 不低于该值时导入并标记待审核
+	Action          string  `mapstructure:"action"`           // reject: 拒绝高相似题; flag: 只标记不拒绝
+	IntervalSeconds int     `mapstructure:"interval_seconds"` // 签名补齐/更新间隔
+}
+
+// ExportConfig holds printable PDF/DOCX export configuration
+type ExportConfig struct {
+	FontPath       string `mapstructure:"font_path"`       // 嵌入的中文字体 (.ttf/.otf)，DOCX 仅能嵌入 .ttf
+	FontFamily     string `mapstructure:"font_family"`     // 字体族名，默认取文件名
+	ChromePath     string `mapstructure:"chrome_path"`     // Chrome/Chromium 路径，留空自动查找
+	TimeoutSeconds int    `mapstructure:"timeout_seconds"` // 单次 PDF 渲染超时
+	MaxQuestions   int    `mapstructure:"max_questions"`   // 单次导出题目上限
+	MaxConcurrent  int    `mapstructure:"max_concurrent"`  // 同时渲染的 PDF 数（共享一个浏览器）
+	RateLimit      int    `mapstructure:"rate_limit"`      // 每个用户每分钟导出次数
+}
+
+// IRTConfig holds item response theory (2PL) calibration and adaptive practice configuration
+type IRTConfig struct {
+	IntervalSeconds   int     `mapstructure:"interval_seconds"`   // 题目参数标定间隔
+	LookbackDays      int     `mapstructure:"lookback_days"`      // 参与标定的作答记录时间范围
+	MaxResponses      int     `mapstructure:"max_responses"`      // 单次标定最多使用的作答记录数
+	MinResponses      int     `mapstructure:"min_responses"`      // 题目至少多少人作答才标定
+	TargetProbability float64 `mapstructure:"target_probability"` // 自适应选题的目标答对概率
+	ConvergeSE        float64 `mapstructure:"converge_se"`        // 能力估计标准误低于该值视为收敛
+	ConvergeDelta     float64 `mapstructure:"converge_delta"`     // 连续三题能力变化都小于该值视为收敛
+	MinAdaptive       int     `mapstructure:"min_adaptive"`       // 自适应练习最少题数
+}
+
+// KnowledgeConfig holds Bayesian knowledge tracing configuration for per-knowledge-point mastery
+type KnowledgeConfig struct {
+	IntervalSeconds    int     `mapstructure:"interval_seconds"`      // 知识点参数拟合间隔
+	MaxResponses       int     `mapstructure:"max_responses"`         // 单次拟合最多使用的最新作答记录数，回放掌握度时使用全部记录
+	MinResponses       int     `mapstructure:"min_responses"`         // 知识点至少多少条作答才拟合参数
+	ForgetHalfLifeDays float64 `mapstructure:"forget_half_life_days"` // 掌握度遗忘半衰期（天）
+	WeakThreshold      float64 `mapstructure:"weak_threshold"`        // 掌握度低于该值视为薄弱（0-100）
+}
+
+// ReviewConfig holds spaced-repetition scheduling configuration for flashcards and wrong questions
+type ReviewConfig struct {
+	Algorithm               string  `mapstructure:"algorithm"`                 // 默认调度算法: fsrs | sm2
+	RequestRetention        float64 `mapstructure:"request_retention"`         // FSRS 目标记忆保持率
+	MaximumInterval         int     `mapstructure:"maximum_interval"`          // 最大复习间隔（天）
+	DailyReviewLimit        int     `mapstructure:"daily_review_limit"`        // 每日复习上限
+	DailyNewLimit           int     `mapstructure:"daily_new_limit"`           // 每日新卡片上限
+	LoadBalance             bool    `mapstructure:"load_balance"`              // 在模糊区间内选择复习量最少的一天
+	OptimizeIntervalSeconds int     `mapstructure:"optimize_interval_seconds"` // 用户参数优化间隔
+	MinReviewsToOptimize    int     `mapstructure:"min_reviews_to_optimize"`   // 至少多少条复习记录才优化个人参数
+}
+
+// AnnouncementLinkConfig holds the settings for grouping announcements into recruitment campaigns
+type AnnouncementLinkConfig struct {
+	IntervalSeconds int     `mapstructure:"interval_seconds"` // 待关联公告处理间隔
+	BatchSize       int     `mapstructure:"batch_size"`       // 每轮处理的公告数
+	MinScore        float64 `mapstructure:"min_score"`        // 归入已有批次的最低相似度（0-1）
+}
+
+// AttachmentStoreConfig holds the content-addressed attachment archive configuration
+type AttachmentStoreConfig struct {
+	Backend        string `mapstructure:"backend"`   // local | s3
+	LocalDir       string `mapstructure:"local_dir"` // local 后端的存储目录
+	Endpoint       string `mapstructure:"endpoint"`  // S3 兼容服务地址 host:port，AWS 留空
+	Region         string `mapstructure:"region"`
+	Bucket         string `mapstructure:"bucket"`
+	AccessKey      string `mapstructure:"access_key"`
+	SecretKey      string `mapstructure:"secret_key"`
+	UseSSL         bool   `mapstructure:"use_ssl"`
+	PathStyle      bool   `mapstructure:"path_style"`      // MinIO 需要 path-style 访问
+	TimeoutSeconds int    `mapstructure:"timeout_seconds"` // 单次上传/下载超时
+}
+
+// FeedConfig 公开 RSS/Atom/JSON Feed 订阅源
+type FeedConfig struct {
+	BaseURL       string `mapstructure:"base_url"`       // 站点地址，用于生成条目链接；留空时链接到公告原文
+	Title         string `mapstructure:"title"`          // 公告订阅源标题
+	ItemLimit     int    `mapstructure:"item_limit"`     // 每个订阅源的条目数
+	SummaryLength int    `mapstructure:"summary_length"` // 摘要模式的字数
+	CacheSeconds  int    `mapstructure:"cache_seconds"`  // Cache-Control max-age
+}
+
+// ArticleFeedConfig 文章聚合中 RSS/Atom 类型订阅源的轮询设置
+type ArticleFeedConfig struct {
+	IntervalSeconds  int `mapstructure:"interval_seconds"`   // 检查到期订阅源的间隔，各源按自身抓取频率抓取
+	MaxErrors        int `mapstructure:"max_errors"`         // 连续失败达到该次数后标记为错误并停止轮询
+	ContentMinLength int `mapstructure:"content_min_length"` // 开启抓取全文时，条目正文少于该字数才抓取原文
 }
 
 func Load() (*Config, error) {
@@ -180,6 +321,11 @@ func setDefaults() {
 	viper.SetDefault("elasticsearch.username", "")
 	viper.SetDefault("elasticsearch.password", "")
 	viper.SetDefault("elasticsearch.index_name", "positions")
+	viper.SetDefault("elasticsearch.shards", 1)
+	viper.SetDefault("elasticsearch.replicas", 0)
+	viper.SetDefault("elasticsearch.sync_interval_seconds", 5)
+	viper.SetDefault("elasticsearch.sync_batch_size", 200)
+	viper.SetDefault("elasticsearch.keep_indices", 1)
 
 	// Crawler defaults
 	viper.SetDefault("crawler.concurrent_requests", 16)
@@ -188,6 +334,11 @@ func setDefaults() {
 	viper.SetDefault("crawler.timeout", "30s")
 	viper.SetDefault("crawler.user_agent", "random")
 	viper.SetDefault("crawler.proxy_enabled", false)
+	viper.SetDefault("crawler.domain_parallelism", 2)
+	viper.SetDefault("crawler.respect_robots_txt", true)
+	viper.SetDefault("crawler.breaker_threshold", 5)
+	viper.SetDefault("crawler.breaker_cooldown", "10m")
+	viper.SetDefault("crawler.record_dir", "")
 
 	// AI defaults
 	viper.SetDefault("ai.provider", "openai")
@@ -198,6 +349,7 @@ func setDefaults() {
 	viper.SetDefault("ai.temperature", 0.1)
 	viper.SetDefault("ai.confidence_threshold", 85)
 	viper.SetDefault("ai.timeout", "60s")
+	viper.SetDefault("ai.chunk_concurrency", 4)
 
 	// Scheduler defaults
 	viper.SetDefault("scheduler.redis_addr", "localhost:6379")
@@ -214,4 +366,81 @@ func setDefaults() {
 	viper.SetDefault("ocr.engine", "tesseract")
 	viper.SetDefault("ocr.tesseract_cmd", "tesseract")
 	viper.SetDefault("ocr.language", "chi_sim+eng")
+	viper.SetDefault("ocr.timeout", 60)
+	viper.SetDefault("ocr.dpi", 300)
+	viper.SetDefault("ocr.pdftoppm_cmd", "pdftoppm")
+	viper.SetDefault("ocr.low_confidence", 60)
+
+	// Embedding defaults
+	viper.SetDefault("embedding.provider", "llm")
+	viper.SetDefault("embedding.llm_config_id", 0)
+	viper.SetDefault("embedding.model", "text-embedding-3-small")
+	viper.SetDefault("embedding.dimensions", 256)
+	viper.SetDefault("embedding.batch_size", 64)
+	viper.SetDefault("embedding.interval_seconds", 300)
+	viper.SetDefault("embedding.duplicate_threshold", 0.95)
+
+	// Question dedup defaults
+	viper.SetDefault("question_dedup.shingle_size", 3)
+	viper.SetDefault("question_dedup.num_hashes", 128)
+	viper.SetDefault("question_dedup.bands", 32)
+	viper.SetDefault("question_dedup.reject_threshold", 0.85)
+	viper.SetDefault("question_dedup.flag_threshold", 0.6)
+	viper.SetDefault("question_dedup.action", "reject")
+	viper.SetDefault("question_dedup.interval_seconds", 600)
+
+	// Export defaults
+	viper.SetDefault("export.timeout_seconds", 60)
+	viper.SetDefault("export.max_questions", 500)
+	viper.SetDefault("export.max_concurrent", 2)
+	viper.SetDefault("export.rate_limit", 5)
+
+	// IRT defaults
+	viper.SetDefault("irt.interval_seconds", 86400)
+	viper.SetDefault("irt.lookback_days", 365)
+	viper.SetDefault("irt.max_responses", 500000)
+	viper.SetDefault("irt.min_responses", 30)
+	viper.SetDefault("irt.target_probability", 0.7)
+	viper.SetDefault("irt.converge_se", 0.45)
+	viper.SetDefault("irt.converge_delta", 0.05)
+	viper.SetDefault("irt.min_adaptive", 5)
+
+	// Knowledge tracing defaults
+	viper.SetDefault("knowledge_tracing.interval_seconds", 86400)
+	viper.SetDefault("knowledge_tracing.max_responses", 1000000)
+	viper.SetDefault("knowledge_tracing.min_responses", 50)
+	viper.SetDefault("knowledge_tracing.forget_half_life_days", 30)
+	viper.SetDefault("knowledge_tracing.weak_threshold", 60)
+
+	viper.SetDefault("review.algorithm", "fsrs")
+	viper.SetDefault("review.request_retention", 0.9)
+	viper.SetDefault("review.maximum_interval", 365)
+	viper.SetDefault("review.daily_review_limit", 200)
+	viper.SetDefault("review.daily_new_limit", 20)
+	viper.SetDefault("review.load_balance", true)
+	viper.SetDefault("review.optimize_interval_seconds", 86400)
+	viper.SetDefault("review.min_reviews_to_optimize", 400)
+
+	// Announcement campaign linking defaults
+	viper.SetDefault("announcement_link.interval_seconds", 3600)
+	viper.SetDefault("announcement_link.batch_size", 200)
+	viper.SetDefault("announcement_link.min_score", 0.55)
+
+	// Attachment archive defaults
+	viper.SetDefault("attachment_store.backend", "local")
+	viper.SetDefault("attachment_store.local_dir", "./data/attachments")
+	viper.SetDefault("attachment_store.region", "us-east-1")
+	viper.SetDefault("attachment_store.path_style", true)
+	viper.SetDefault("attachment_store.timeout_seconds", 120)
+
+	// Public feed defaults
+	viper.SetDefault("feed.title", "公考公告")
+	viper.SetDefault("feed.item_limit", 50)
+	viper.SetDefault("feed.summary_length", 200)
+	viper.SetDefault("feed.cache_seconds", 600)
+
+	// Article aggregator feed source defaults
+	viper.SetDefault("article_feed.interval_seconds", 300)
+	viper.SetDefault("article_feed.max_errors", 8)
+	viper.SetDefault("article_feed.content_min_length", 200)
 }
@@ -1,8 +1,10 @@
 package crawler
 
 import (
+	"context"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
@@ -38,6 +40,8 @@ type AnnouncementSpider struct {
 	Articles      []Article
 	Announcements []Announcement
 	datePattern   *regexp.Regexp
+	adapters      map[uint]*SiteAdapter // by source list page ID
+	resultMu      sync.Mutex
 }
 
 // NewAnnouncementSpider creates a new announcement spider
@@ -56,6 +60,21 @@ func (s *AnnouncementSpider) SetArticles(articles []Article) {
 	s.Articles = articles
 }
 
+// SetAdapters sets the site adapters of the articles' list pages; their detail
+// selectors take precedence over the heuristics
+func (s *AnnouncementSpider) SetAdapters(adapters map[uint]*SiteAdapter) {
+	s.adapters = adapters
+}
+
+// detailAdapter returns the adapter for an article's detail page, or nil
+func (s *AnnouncementSpider) detailAdapter(article *Article) *SiteAdapter {
+	adapter := s.adapters[article.SourceListID]
+	if adapter == nil || !adapter.HasDetail() {
+		return nil
+	}
+	return adapter
+}
+
 // Run executes the announcement crawl
 func (s *AnnouncementSpider) Run() (*AnnouncementCrawlResult, error) {
 	startTime := time.Now()
@@ -71,16 +90,16 @@ func (s *AnnouncementSpider) Run() (*AnnouncementCrawlResult, error) {
 		}
 
 		announcement := s.extractAnnouncement(e, article)
-		if announcement != nil {
-			s.Announcements = append(s.Announcements, *announcement)
-			result.SuccessCount++
-		} else {
-			result.FailedCount++
-		}
+		s.addResult(announcement, result)
 	})
 
 	// Visit all article URLs
-	for _, article := range s.Articles {
+	for i := range s.Articles {
+		article := &s.Articles[i]
+		if adapter := s.detailAdapter(article); adapter != nil && adapter.Detail.Headless {
+			s.addResult(s.renderAnnouncement(adapter, article, result), result)
+			continue
+		}
 		if err := s.Visit(article.URL); err != nil {
 			s.Logger.Error("Failed to visit article",
 				zap.String("url", article.URL),
@@ -107,6 +126,45 @@ func (s *AnnouncementSpider) Run() (*AnnouncementCrawlResult, error) {
 	return result, nil
 }
 
+// addResult records an extracted announcement, or a failure when nil
+func (s *AnnouncementSpider) addResult(announcement *Announcement, result *AnnouncementCrawlResult) {
+	s.resultMu.Lock()
+	defer s.resultMu.Unlock()
+	if announcement != nil {
+		s.Announcements = append(s.Announcements, *announcement)
+		result.SuccessCount++
+	} else {
+		result.FailedCount++
+	}
+}
+
+// renderAnnouncement extracts an announcement from a headless-rendered page
+func (s *AnnouncementSpider) renderAnnouncement(adapter *SiteAdapter, article *Article, result *AnnouncementCrawlResult) *Announcement {
+	pageHTML, err := s.renderHTML(context.Background(), article.URL, AdapterRender{WaitMS: adapter.Render.WaitMS})
+	if err != nil {
+		s.Logger.Error("Failed to render article",
+			zap.String("url", article.URL),
+			zap.Error(err),
+		)
+		s.resultMu.Lock()
+		result.Errors = append(result.Errors, err.Error())
+		s.resultMu.Unlock()
+		return nil
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(pageHTML))
+	if err != nil {
+		return nil
+	}
+	page := adapter.ExtractDetail(doc.Selection, article.URL)
+	announcement := s.newAnnouncement(article)
+	announcement.Title = firstNonEmpty(page.Title, article.Title)
+	announcement.Content = page.Content
+	announcement.ContentHTML = page.ContentHTML
+	announcement.PublishDate = firstNonEmpty(page.Date, article.PublishDate)
+	announcement.Attachments = page.Attachments
+	return s.validAnnouncement(announcement)
+}
+
 // findArticleByURL finds the article by URL
 func (s *AnnouncementSpider) findArticleByURL(url string) *Article {
 	for i := range s.Articles {
@@ -119,32 +177,57 @@ func (s *AnnouncementSpider) findArticleByURL(url string) *Article {
 
 // extractAnnouncement extracts announcement content from a page
 func (s *AnnouncementSpider) extractAnnouncement(e *colly.HTMLElement, article *Article) *Announcement {
-	announcement := &Announcement{
-		URL:          article.URL,
-		SourceName:   article.SourceName,
-		SourceListID: article.SourceListID,
-		Category:     article.Category,
-		CrawledAt:    time.Now(),
+	announcement := s.newAnnouncement(article)
+
+	// Adapter selectors first, heuristics for whatever they leave empty
+	page := &AdapterDetailPage{}
+	if adapter := s.detailAdapter(article); adapter != nil {
+		page = adapter.ExtractDetail(e.DOM, article.URL)
 	}
 
 	// Extract title
-	announcement.Title = s.extractTitle(e, article.Title)
+	announcement.Title = page.Title
+	if announcement.Title == "" {
+		announcement.Title = s.extractTitle(e, article.Title)
+	}
 
 	// Extract content
-	content, contentHTML := s.extractContent(e)
-	announcement.Content = content
-	announcement.ContentHTML = contentHTML
+	announcement.Content, announcement.ContentHTML = page.Content, page.ContentHTML
+	if announcement.Content == "" {
+		announcement.Content, announcement.ContentHTML = s.extractContent(e)
+	}
 
 	// Extract publish date
-	announcement.PublishDate = s.extractPublishDate(e, article.PublishDate)
+	announcement.PublishDate = page.Date
+	if announcement.PublishDate == "" {
+		announcement.PublishDate = s.extractPublishDate(e, article.PublishDate)
+	}
 
 	// Extract attachments
-	announcement.Attachments = s.extractAttachments(e)
+	announcement.Attachments = page.Attachments
+	if announcement.Attachments == nil {
+		announcement.Attachments = s.extractAttachments(e)
+	}
+
+	return s.validAnnouncement(announcement)
+}
 
-	// Validate
+// newAnnouncement creates an announcement carrying the article's source
+func (s *AnnouncementSpider) newAnnouncement(article *Article) *Announcement {
+	return &Announcement{
+		URL:          article.URL,
+		SourceName:   article.SourceName,
+		SourceListID: article.SourceListID,
+		Category:     article.Category,
+		CrawledAt:    time.Now(),
+	}
+}
+
+// validAnnouncement returns the announcement, or nil when it lacks a title or content
+func (s *AnnouncementSpider) validAnnouncement(announcement *Announcement) *Announcement {
 	if announcement.Title == "" || len(announcement.Content) < 50 {
 		s.Logger.Warn("Invalid announcement content",
-			zap.String("url", article.URL),
+			zap.String("url", announcement.URL),
 			zap.String("title", announcement.Title),
 			zap.Int("content_length", len(announcement.Content)),
 		)
@@ -192,28 +275,29 @@ func (s *AnnouncementSpider) extractTitle(e *colly.HTMLElement, fallback string)
 	return fallback
 }
 
+// contentSelectors are tried in order to find the main content of an article page
+var contentSelectors = []string{
+	"article",
+	".article-content",
+	".content",
+	".post-content",
+	"#content",
+	".main-content",
+	".detail-content",
+	".news-content",
+	".zwContent",
+	".TRS_Editor",
+	".pages_content",
+	".content_txt",
+	".Custom_UnifyPageContent",
+}
+
 // extractContent extracts the main content from the page
 func (s *AnnouncementSpider) extractContent(e *colly.HTMLElement) (string, string) {
 	// Remove unwanted elements
 	e.DOM.Find("script, style, nav, header, footer, aside, .sidebar, .comment, .ad").Remove()
 
 	// Try common content selectors
-	contentSelectors := []string{
-		"article",
-		".article-content",
-		".content",
-		".post-content",
-		"#content",
-		".main-content",
-		".detail-content",
-		".news-content",
-		".zwContent",
-		".TRS_Editor",
-		".pages_content",
-		".content_txt",
-		".Custom_UnifyPageContent",
-	}
-
 	for _, sel := range contentSelectors {
 		contentElem := e.DOM.Find(sel).First()
 		if contentElem.Length() > 0 {
@@ -291,9 +375,9 @@ func (s *AnnouncementSpider) extractAttachments(e *colly.HTMLElement) []Attachme
 			return
 		}
 
-		href = strings.ToLower(href)
+		hrefLower := strings.ToLower(href)
 		for ext, fileType := range extensions {
-			if strings.Contains(href, ext) {
+			if strings.Contains(hrefLower, ext) {
 				fullURL, err := JoinURL(e.Request.URL.String(), href)
 				if err != nil {
 					continue
@@ -0,0 +1,243 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"go.uber.org/zap"
+	"golang.org/x/net/html/charset"
+
+	"github.com/what-cse/server/internal/feed"
+)
+
+const (
+	feedUserAgent    = "Mozilla/5.0 (compatible; WhatCSE-FeedFetcher/1.0; +https://github.com/what-cse)"
+	maxFeedBodySize  = 10 << 20
+	maxFeedRedirects = 10
+)
+
+// ErrBlockedAddress is returned when a feed or article URL is not a public
+// http(s) address. Feeds are third-party input, so their links must not reach
+// loopback, private or metadata endpoints.
+var ErrBlockedAddress = errors.New("blocked non-public address")
+
+// carrierNAT is the shared address space of RFC 6598, used by some cloud
+// metadata services
+var carrierNAT = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// isPublicIP reports whether ip may be fetched on behalf of a feed
+func isPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || carrierNAT.Contains(ip))
+}
+
+// publicOnlyControl rejects connections to non-public addresses. It runs after
+// DNS resolution for every dial, so redirects and rebinding are covered too.
+func publicOnlyControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+	}
+	return nil
+}
+
+// checkFeedURL allows only absolute http and https URLs
+func checkFeedURL(u *url.URL) error {
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, u.Redacted())
+	}
+	return nil
+}
+
+// publicTransport dials public addresses only. Proxies are not used, because
+// a proxy would resolve and connect on our behalf and bypass the check.
+func publicTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   publicOnlyControl,
+	}
+	return &http.Transport{
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// FeedFetcher polls RSS, Atom and JSON Feed URLs and fetches the full text
+// of linked articles
+type FeedFetcher struct {
+	client *http.Client
+	logger *zap.Logger
+}
+
+// FeedFetchResult is the outcome of a conditional feed request
+type FeedFetchResult struct {
+	NotModified  bool // the server answered 304; Feed is nil
+	ETag         string
+	LastModified string
+	Feed         *feed.Feed
+}
+
+// NewFeedFetcher creates a new feed fetcher
+func NewFeedFetcher(logger *zap.Logger) *FeedFetcher {
+	return &FeedFetcher{
+		client: &http.Client{
+			Transport: recordingTransport(publicTransport()),
+			Timeout:   30 * time.Second,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= maxFeedRedirects {
+					return fmt.Errorf("stopped after %d redirects", maxFeedRedirects)
+				}
+				return checkFeedURL(req.URL)
+			},
+		},
+		logger: logger,
+	}
+}
+
+// SetTransport replaces the HTTP transport, e.g. with a ReplayTransport for offline tests
+func (f *FeedFetcher) SetTransport(rt http.RoundTripper) {
+	f.client.Transport = rt
+}
+
+// Fetch downloads and parses a feed. etag and modified are the validators of
+// the previous response and may be empty.
+func (f *FeedFetcher) Fetch(ctx context.Context, feedURL, etag, modified string) (*FeedFetchResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkFeedURL(req.URL); err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", feedUserAgent)
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/feed+json, application/xml;q=0.9, text/xml;q=0.9, */*;q=0.8")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if modified != "" {
+		req.Header.Set("If-Modified-Since", modified)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch feed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	result := &FeedFetchResult{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	if resp.StatusCode == http.StatusNotModified {
+		result.NotModified = true
+		if result.ETag == "" {
+			result.ETag = etag
+		}
+		if result.LastModified == "" {
+			result.LastModified = modified
+		}
+		return result, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch feed: unexpected status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("read feed: %w", err)
+	}
+	parsed, err := feed.Parse(body)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.FeedURL == "" {
+		parsed.FeedURL = resp.Request.URL.String()
+	}
+	result.Feed = parsed
+
+	f.logger.Debug("Fetched feed",
+		zap.String("url", feedURL),
+		zap.Int("items", len(parsed.Items)),
+	)
+	return result, nil
+}
+
+// FetchArticle downloads an article page and returns the HTML of its main
+// content, with relative links and images made absolute
+func (f *FeedFetcher) FetchArticle(ctx context.Context, articleURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, articleURL, nil)
+	if err != nil {
+		return "", err
+	}
+	if err := checkFeedURL(req.URL); err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", feedUserAgent)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetch article: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetch article: unexpected status %d", resp.StatusCode)
+	}
+
+	reader, err := charset.NewReader(io.LimitReader(resp.Body, maxFeedBodySize), resp.Header.Get("Content-Type"))
+	if err != nil {
+		return "", fmt.Errorf("decode article: %w", err)
+	}
+	doc, err := goquery.NewDocumentFromReader(reader)
+	if err != nil {
+		return "", fmt.Errorf("parse article: %w", err)
+	}
+	doc.Find("script, style, nav, header, footer, aside, .sidebar, .comment, .ad").Remove()
+
+	// WeChat articles keep their body in #js_content
+	selectors := append([]string{"#js_content"}, contentSelectors...)
+	for _, sel := range selectors {
+		elem := doc.Find(sel).First()
+		if elem.Length() == 0 || len(strings.TrimSpace(elem.Text())) <= 100 {
+			continue
+		}
+		absolutizeLinks(elem, resp.Request.URL)
+		return elem.Html()
+	}
+	return "", fmt.Errorf("no article content found")
+}
+
+// absolutizeLinks resolves relative href and src attributes against base.
+// Lazy-loaded images carry their address in data-src.
+func absolutizeLinks(sel *goquery.Selection, base *url.URL) {
+	sel.Find("img[data-src]").Each(func(_ int, img *goquery.Selection) {
+		if src, _ := img.Attr("src"); src == "" || strings.HasPrefix(src, "data:") {
+			img.SetAttr("src", img.AttrOr("data-src", ""))
+		}
+	})
+	for _, attr := range []string{"href", "src"} {
+		sel.Find("[" + attr + "]").Each(func(_ int, node *goquery.Selection) {
+			value, _ := node.Attr(attr)
+			if ref, err := url.Parse(strings.TrimSpace(value)); err == nil && !ref.IsAbs() {
+				node.SetAttr(attr, base.ResolveReference(ref).String())
+			}
+		})
+	}
+}
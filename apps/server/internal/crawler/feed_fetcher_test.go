@@ -0,0 +1,98 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	cases := map[string]bool{
+		"127.0.0.1":        false,
+		"10.1.2.3":         false,
+		"172.16.0.1":       false,
+		"192.168.1.1":      false,
+		"169.254.169.254":  false, // cloud metadata
+		"100.100.100.200":  false, // carrier-grade NAT, Alibaba Cloud metadata
+		"0.0.0.0":          false,
+		"::1":              false,
+		"fe80::1":          false,
+		"fd00::1":          false,
+		"::":               false,
+		"::ffff:127.0.0.1": false,
+		"8.8.8.8":          true,
+		"114.114.114.114":  true,
+		"2001:4860::8888":  true,
+	}
+	for addr, want := range cases {
+		if got := isPublicIP(net.ParseIP(addr)); got != want {
+			t.Errorf("isPublicIP(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+// redirectOnce answers the first request with a redirect to target and sends
+// every later request through the real transport
+type redirectOnce struct {
+	target string
+	next   http.RoundTripper
+	done   bool
+}
+
+func (r *redirectOnce) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.done {
+		return r.next.RoundTrip(req)
+	}
+	r.done = true
+	return &http.Response{
+		StatusCode: http.StatusFound,
+		Header:     http.Header{"Location": {r.target}},
+		Body:       io.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}, nil
+}
+
+func TestFeedFetcherBlocksNonPublicAddresses(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Write([]byte("<html><body>internal</body></html>"))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	fetcher := NewFeedFetcher(zap.NewNop())
+
+	if _, err := fetcher.FetchArticle(ctx, server.URL+"/latest/meta-data"); !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("loopback article: err = %v, want ErrBlockedAddress", err)
+	}
+	if _, err := fetcher.Fetch(ctx, server.URL+"/feed.xml", "", ""); !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("loopback feed: err = %v, want ErrBlockedAddress", err)
+	}
+	for _, link := range []string{"file:///etc/passwd", "gopher://example.com/", "ftp://example.com/a"} {
+		if _, err := fetcher.FetchArticle(ctx, link); !errors.Is(err, ErrBlockedAddress) {
+			t.Errorf("%s: err = %v, want ErrBlockedAddress", link, err)
+		}
+	}
+
+	// A public page redirecting to an internal address is blocked at the second hop
+	fetcher.SetTransport(&redirectOnce{target: server.URL + "/admin", next: publicTransport()})
+	if _, err := fetcher.FetchArticle(ctx, "https://news.example.com/a.html"); !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("redirect to loopback: err = %v, want ErrBlockedAddress", err)
+	}
+	fetcher.SetTransport(&redirectOnce{target: "file:///etc/passwd", next: publicTransport()})
+	if _, err := fetcher.FetchArticle(ctx, "https://news.example.com/a.html"); !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("redirect to file: err = %v, want ErrBlockedAddress", err)
+	}
+
+	if hits != 0 {
+		t.Errorf("internal server received %d requests", hits)
+	}
+}
@@ -121,6 +121,7 @@ type FenbiSpider struct {
 	logger        *zap.Logger
 	aiCleaner     AIContentCleaner
 	useLLMCleaner bool
+	transport     http.RoundTripper // replaces the network for every client when set
 }
 
 // FenbiListItem represents an announcement item from the list page
@@ -167,8 +168,9 @@ func NewFenbiSpider(config *SpiderConfig, logger *zap.Logger) *FenbiSpider {
 	jar, _ := cookiejar.New(nil)
 
 	client := &http.Client{
-		Jar:     jar,
-		Timeout: 30 * time.Second,
+		Transport: recordingTransport(nil),
+		Jar:       jar,
+		Timeout:   30 * time.Second,
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			return http.ErrUseLastResponse
 		},
@@ -183,6 +185,31 @@ func NewFenbiSpider(config *SpiderConfig, logger *zap.Logger) *FenbiSpider {
 	return spider
 }
 
+// SetTransport routes all requests of the spider, including the TLS client
+// and the fallback clients, through rt
+func (s *FenbiSpider) SetTransport(rt http.RoundTripper) {
+	s.transport = rt
+	s.httpClient.Transport = rt
+	s.Spider.SetTransport(rt)
+}
+
+// roundTripper returns the transport for ad-hoc clients, nil meaning the default
+func (s *FenbiSpider) roundTripper() http.RoundTripper {
+	if s.transport != nil {
+		return s.transport
+	}
+	return recordingTransport(nil)
+}
+
+// newTLSClient creates the redirect-following TLS client, replaying through
+// the injected transport when one is set
+func (s *FenbiSpider) newTLSClient() (*TLSClientWrapper, error) {
+	if s.transport != nil {
+		return NewReplayTLSClient(s.transport, true), nil
+	}
+	return NewTLSClientWithRedirects()
+}
+
 // SetAIContentCleaner sets the AI content cleaner for LLM-based content extraction
 func (s *FenbiSpider) SetAIContentCleaner(cleaner AIContentCleaner) {
 	s.aiCleaner = cleaner
@@ -1162,7 +1189,7 @@ func (s *FenbiSpider) ResolveShortURL(shortURL string) (string, error) {
 	)
 
 	// Create TLS client that follows redirects
-	tlsClient, err := NewTLSClientWithRedirects()
+	tlsClient, err := s.newTLSClient()
 	if err != nil {
 		s.logger.Warn("Failed to create TLS client for short URL, falling back", zap.Error(err))
 		return s.resolveShortURLFallback(shortURL)
@@ -1219,7 +1246,8 @@ func (s *FenbiSpider) resolveShortURLFallback(shortURL string) (string, error) {
 	)
 
 	client := &http.Client{
-		Timeout: 30 * time.Second,
+		Transport: s.roundTripper(),
+		Timeout:   30 * time.Second,
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			if len(via) >= 10 {
 				return errors.New("too many redirects")
@@ -1331,7 +1359,7 @@ func (s *FenbiSpider) FetchPageContent(targetURL string) (*PageContent, error) {
 	s.logger.Info("Fetching page content with TLS client", zap.String("url", targetURL))
 
 	// Create TLS client with Chrome fingerprint (follows redirects by default)
-	tlsClient, err := NewTLSClientWithRedirects()
+	tlsClient, err := s.newTLSClient()
 	if err != nil {
 		s.logger.Warn("Failed to create TLS client, falling back to standard client", zap.Error(err))
 		return s.fetchPageContentFallback(targetURL)
@@ -1541,7 +1569,8 @@ func (s *FenbiSpider) fetchPageContentFallback(targetURL string) (*PageContent,
 
 	// Use a client that follows redirects
 	client := &http.Client{
-		Timeout: 30 * time.Second,
+		Transport: s.roundTripper(),
+		Timeout:   30 * time.Second,
 	}
 
 	resp, err := client.Do(req)
@@ -1806,20 +1835,25 @@ func (s *FenbiSpider) extractPageAttachments(doc *goquery.Document, baseURL stri
 	return attachments
 }
 
-// DownloadFile downloads a file from URL to the specified path
-func (s *FenbiSpider) DownloadFile(fileURL, savePath string) error {
+// maxFileSize caps attachment downloads held in memory
+const maxFileSize = 200 << 20
+
+// DownloadedFile is an attachment fetched into memory
+type DownloadedFile struct {
+	URL         string // final URL after redirects
+	ContentType string
+	Data        []byte
+}
+
+// FetchFile downloads a file into memory
+func (s *FenbiSpider) FetchFile(fileURL string) (*DownloadedFile, error) {
 	if fileURL == "" {
-		return errors.New("file URL is empty")
+		return nil, errors.New("file URL is empty")
 	}
 
-	s.logger.Info("Downloading file",
-		zap.String("url", fileURL),
-		zap.String("save_path", savePath),
-	)
-
 	req, err := http.NewRequest("GET", fileURL, nil)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set headers
@@ -1827,49 +1861,73 @@ func (s *FenbiSpider) DownloadFile(fileURL, savePath string) error {
 	req.Header.Set("Accept", "*/*")
 
 	client := &http.Client{
-		Timeout: 120 * time.Second, // Longer timeout for file downloads
+		Transport: s.roundTripper(),
+		Timeout:   120 * time.Second, // Longer timeout for file downloads
 	}
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to download file: %w", err)
+		return nil, fmt.Errorf("failed to download file: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download failed with status code: %d", resp.StatusCode)
-	}
-
-	// Create the directory if it doesn't exist
-	dir := filepath.Dir(savePath)
-	if dir != "" && dir != "." {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("failed to create directory: %w", err)
-		}
+		return nil, fmt.Errorf("download failed with status code: %d", resp.StatusCode)
 	}
 
-	// Create the file
-	out, err := os.Create(savePath)
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFileSize+1))
 	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
-	defer out.Close()
+	if len(data) > maxFileSize {
+		return nil, fmt.Errorf("file exceeds %d bytes", maxFileSize)
+	}
+
+	return &DownloadedFile{
+		URL:         resp.Request.URL.String(),
+		ContentType: resp.Header.Get("Content-Type"),
+		Data:        data,
+	}, nil
+}
 
-	// Write the body to file
-	written, err := io.Copy(out, resp.Body)
+// DownloadFile downloads a file from URL to the specified path
+func (s *FenbiSpider) DownloadFile(fileURL, savePath string) error {
+	s.logger.Info("Downloading file",
+		zap.String("url", fileURL),
+		zap.String("save_path", savePath),
+	)
+
+	file, err := s.FetchFile(fileURL)
 	if err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+		return err
+	}
+	if err := writeFile(savePath, file.Data); err != nil {
+		return err
 	}
 
 	s.logger.Info("Downloaded file successfully",
 		zap.String("url", fileURL),
 		zap.String("save_path", savePath),
-		zap.Int64("bytes", written),
+		zap.Int("bytes", len(file.Data)),
 	)
 
 	return nil
 }
 
+// writeFile writes data to path, creating the parent directory
+func writeFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if dir != "" && dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
 // Helper functions
 
 func parseCookieString(cookieStr string) []*http.Cookie {
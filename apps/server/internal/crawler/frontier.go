@@ -0,0 +1,194 @@
+package crawler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+	"go.uber.org/zap"
+)
+
+// URL crawl states kept in the URL store
+const (
+	URLStatusSeen    = "seen"    // discovered on a list page, not fetched yet
+	URLStatusFetched = "fetched" // last request succeeded or was not modified
+	URLStatusFailed  = "failed"  // last request failed after all retries
+)
+
+// maxRetryBackoff caps the wait between retries of one request
+const maxRetryBackoff = time.Minute
+
+// URLRecord is the persisted crawl state of a URL
+type URLRecord struct {
+	URLHash      string
+	URL          string
+	Domain       string
+	Status       string
+	StatusCode   int
+	ContentHash  string // sha256 of the last fetched body
+	ETag         string
+	LastModified string
+	FailCount    int
+	LastError    string
+	FetchedAt    *time.Time
+}
+
+// URLStore persists the crawl frontier so restarts do not re-discover every
+// article and unchanged pages can be requested conditionally
+type URLStore interface {
+	// GetURL returns the record of a URL hash, or nil when it was never seen
+	GetURL(urlHash string) (*URLRecord, error)
+	// SaveURL creates or updates the record of record.URLHash
+	SaveURL(record *URLRecord) error
+}
+
+// SetURLStore makes the spider remember URLs across runs, send conditional
+// requests and keep the fetch outcome of every URL
+func (s *Spider) SetURLStore(store URLStore) {
+	s.store = store
+}
+
+// loadURL returns the stored record of a URL, or nil
+func (s *Spider) loadURL(urlStr string) *URLRecord {
+	if s.store == nil {
+		return nil
+	}
+	record, err := s.store.GetURL(HashURL(urlStr))
+	if err != nil {
+		s.Logger.Warn("Failed to load URL record", zap.String("url", urlStr), zap.Error(err))
+		return nil
+	}
+	return record
+}
+
+// saveURL persists a record, logging failures
+func (s *Spider) saveURL(record *URLRecord) {
+	if err := s.store.SaveURL(record); err != nil {
+		s.Logger.Warn("Failed to save URL record", zap.String("url", record.URL), zap.Error(err))
+	}
+}
+
+// urlRecord returns the record loaded for the request, or a new one
+func (s *Spider) urlRecord(r *colly.Request) *URLRecord {
+	if record, ok := r.Ctx.GetAny("url_record").(*URLRecord); ok && record != nil {
+		return record
+	}
+	urlStr := r.URL.String()
+	return &URLRecord{URLHash: HashURL(urlStr), URL: urlStr, Domain: r.URL.Host}
+}
+
+// prepareConditionalRequest loads the URL record and adds the validators of
+// the last fetch so unchanged pages answer 304
+func (s *Spider) prepareConditionalRequest(r *colly.Request) {
+	if s.store == nil {
+		return
+	}
+	if _, ok := r.Ctx.GetAny("url_record").(*URLRecord); !ok {
+		r.Ctx.Put("url_record", s.loadURL(r.URL.String()))
+	}
+	record := s.urlRecord(r)
+	if record.Status != URLStatusFetched {
+		return
+	}
+	if record.ETag != "" {
+		r.Headers.Set("If-None-Match", record.ETag)
+	}
+	if record.LastModified != "" {
+		r.Headers.Set("If-Modified-Since", record.LastModified)
+	}
+}
+
+// recordFetched stores the validators and content hash of a response
+func (s *Spider) recordFetched(r *colly.Response) {
+	if s.store == nil {
+		return
+	}
+	record := s.urlRecord(r.Request)
+	now := time.Now()
+	record.Status = URLStatusFetched
+	record.StatusCode = r.StatusCode
+	record.FailCount = 0
+	record.LastError = ""
+	record.FetchedAt = &now
+
+	if r.StatusCode != http.StatusNotModified {
+		sum := sha256.Sum256(r.Body)
+		hash := hex.EncodeToString(sum[:])
+		if record.ContentHash != "" && record.ContentHash != hash {
+			r.Ctx.Put("content_changed", "1")
+		}
+		record.ContentHash = hash
+		record.ETag = r.Headers.Get("ETag")
+		record.LastModified = r.Headers.Get("Last-Modified")
+	}
+	s.saveURL(record)
+}
+
+// recordFailed stores a request that failed after all retries
+func (s *Spider) recordFailed(r *colly.Response, err error) {
+	if s.store == nil {
+		return
+	}
+	record := s.urlRecord(r.Request)
+	record.Status = URLStatusFailed
+	record.StatusCode = r.StatusCode
+	record.FailCount++
+	record.LastError = err.Error()
+	s.saveURL(record)
+}
+
+// ContentChanged reports whether a response differs from the previous fetch
+// of the same URL; first fetches report false
+func ContentChanged(r *colly.Response) bool {
+	return r.Ctx.Get("content_changed") == "1"
+}
+
+// retryRequest re-queues a failed request with exponential backoff while
+// retries remain; client errors, robots.txt and paused domains are final
+func (s *Spider) retryRequest(r *colly.Response, err error) bool {
+	if r.StatusCode != 0 && r.StatusCode < 500 && r.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+	if errors.Is(err, colly.ErrRobotsTxtBlocked) || errors.Is(err, ErrDomainPaused) {
+		return false
+	}
+
+	attempt, _ := strconv.Atoi(r.Ctx.Get("retry_attempt"))
+	if attempt >= s.Config.RetryTimes {
+		return false
+	}
+	attempt++
+	r.Ctx.Put("retry_attempt", strconv.Itoa(attempt))
+
+	backoff := time.Second << (attempt - 1)
+	// Transport errors (timeouts, refused connections) come without headers
+	if r.Headers != nil {
+		if seconds, convErr := strconv.Atoi(r.Headers.Get("Retry-After")); convErr == nil && seconds > 0 {
+			backoff = time.Duration(seconds) * time.Second
+		}
+	}
+	if backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+	backoff += time.Duration(rand.Int63n(int64(backoff/2) + 1))
+
+	s.Logger.Info("Retrying request",
+		zap.String("spider", s.Name),
+		zap.String("url", r.Request.URL.String()),
+		zap.Int("attempt", attempt),
+		zap.Duration("backoff", backoff),
+		zap.Error(err),
+	)
+	time.Sleep(backoff)
+
+	if retryErr := r.Request.Retry(); retryErr != nil {
+		s.Logger.Warn("Retry failed to start", zap.String("url", r.Request.URL.String()), zap.Error(retryErr))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,130 @@
+package crawler
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+	"go.uber.org/zap"
+)
+
+// A refused connection has no response headers; retrying it must not panic
+func TestRetryRefusedConnection(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	config := testSpiderConfig()
+	config.RetryTimes = 1
+	config.RespectRobotsTxt = false
+	spider := NewSpider("retry-test", config, zap.NewNop())
+
+	var failures int32
+	spider.Collector.OnError(func(r *colly.Response, err error) {
+		if r.Headers != nil {
+			t.Errorf("expected no headers for a refused connection, got %v", *r.Headers)
+		}
+		atomic.AddInt32(&failures, 1)
+	})
+
+	if err := spider.Visit("http://" + addr + "/"); err != nil {
+		t.Fatalf("visit: %v", err)
+	}
+	spider.Wait()
+
+	if got := atomic.LoadInt32(&failures); got != 2 {
+		t.Errorf("failures = %d, want 2 (first attempt and one retry)", got)
+	}
+}
+
+// Spiders built per task share one breaker per host
+func TestDomainStateSharedAcrossSpiders(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	config := testSpiderConfig()
+	config.BreakerThreshold = 2
+	config.BreakerCooldown = time.Minute
+	first := newPoliteTransport(http.DefaultTransport, config, zap.NewNop())
+	second := newPoliteTransport(http.DefaultTransport, testSpiderConfig(), zap.NewNop())
+
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		resp, err := first.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		resp.Body.Close()
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	if _, err := second.RoundTrip(req); !errors.Is(err, ErrDomainPaused) {
+		t.Errorf("second spider: err = %v, want ErrDomainPaused", err)
+	}
+	if got := atomic.LoadInt32(&hits); got != 2 {
+		t.Errorf("server hits = %d, want 2", got)
+	}
+}
+
+// memoryURLStore is an in-memory URLStore
+type memoryURLStore map[string]*URLRecord
+
+func (m memoryURLStore) GetURL(urlHash string) (*URLRecord, error) { return m[urlHash], nil }
+
+func (m memoryURLStore) SaveURL(record *URLRecord) error {
+	m[record.URLHash] = record
+	return nil
+}
+
+// Only successfully fetched URLs count as crawled across runs
+func TestIsURLCrawledFromStore(t *testing.T) {
+	store := memoryURLStore{}
+	for status, url := range map[string]string{
+		URLStatusSeen:    "https://example.com/seen",
+		URLStatusFetched: "https://example.com/fetched",
+		URLStatusFailed:  "https://example.com/failed",
+	} {
+		store.SaveURL(&URLRecord{URLHash: HashURL(url), URL: url, Status: status})
+	}
+
+	spider := NewSpider("store-test", testSpiderConfig(), zap.NewNop())
+	spider.SetURLStore(store)
+
+	for url, want := range map[string]bool{
+		"https://example.com/seen":    false,
+		"https://example.com/fetched": true,
+		"https://example.com/failed":  false,
+		"https://example.com/new":     false,
+	} {
+		if got := spider.IsURLCrawled(url); got != want {
+			t.Errorf("IsURLCrawled(%s) = %v, want %v", url, got, want)
+		}
+	}
+
+	// marking a failed URL keeps its record so the failure count survives
+	spider.MarkURLCrawled("https://example.com/failed")
+	if record := store[HashURL("https://example.com/failed")]; record.Status != URLStatusFailed {
+		t.Errorf("status after MarkURLCrawled = %q, want %q", record.Status, URLStatusFailed)
+	}
+}
+
+// testSpiderConfig disables delays and retries so tests run instantly
+func testSpiderConfig() *SpiderConfig {
+	config := DefaultSpiderConfig()
+	config.DownloadDelay = 0
+	config.RetryTimes = 0
+	config.UserAgent = "what-cse-test"
+	return config
+}
@@ -1,8 +1,12 @@
 package crawler
 
 import (
+	"context"
+	"io"
+	"net/http"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
@@ -12,13 +16,14 @@ import (
 
 // ListPage represents a monitored list page configuration
 type ListPage struct {
-	ID                uint   `json:"id"`
-	URL               string `json:"url"`
-	SourceName        string `json:"source_name"`
-	Category          string `json:"category"`
-	ArticleSelector   string `json:"article_selector"`
-	PaginationPattern string `json:"pagination_pattern"`
-	Status            string `json:"status"`
+	ID                uint         `json:"id"`
+	URL               string       `json:"url"`
+	SourceName        string       `json:"source_name"`
+	Category          string       `json:"category"`
+	ArticleSelector   string       `json:"article_selector"`
+	PaginationPattern string       `json:"pagination_pattern"`
+	Status            string       `json:"status"`
+	Adapter           *SiteAdapter `json:"adapter,omitempty"` // replaces the heuristics when set
 }
 
 // ListMonitorSpider crawls list pages to discover new articles
@@ -27,6 +32,11 @@ type ListMonitorSpider struct {
 	ListPages   []ListPage
 	NewArticles []Article
 	datePattern *regexp.Regexp
+
+	// resultMu guards the result while adapter pages are handled concurrently
+	resultMu sync.Mutex
+	// fullScan keeps paginating adapter lists when a page has no new articles
+	fullScan bool
 }
 
 // NewListMonitorSpider creates a new list monitor spider
@@ -59,6 +69,9 @@ func (s *ListMonitorSpider) Run() (*CrawlResult, error) {
 
 	// Setup HTML callback for article extraction
 	s.Collector.OnHTML("html", func(e *colly.HTMLElement) {
+		if e.Request.Ctx.GetAny("adapter_list") != nil {
+			return
+		}
 		listPage := s.findListPageByURL(e.Request.URL.String())
 		if listPage == nil {
 			return
@@ -78,11 +91,26 @@ func (s *ListMonitorSpider) Run() (*CrawlResult, error) {
 		s.handlePagination(e, listPage)
 	})
 
+	// Adapter list pages are matched by request context, not by URL prefix
+	s.Collector.OnResponse(func(r *colly.Response) {
+		listPage, ok := r.Ctx.GetAny("adapter_list").(*ListPage)
+		if !ok {
+			return
+		}
+		index, _ := r.Ctx.GetAny("adapter_page").(int)
+		s.handleAdapterPage(listPage, index, r.Request.URL.String(), r.Body, result)
+	})
+
 	// Visit all active list pages
-	for _, page := range s.ListPages {
+	for i := range s.ListPages {
+		page := &s.ListPages[i]
 		if page.Status != "active" {
 			continue
 		}
+		if page.Adapter != nil {
+			s.visitAdapterPage(page, 0, page.URL, result)
+			continue
+		}
 		if err := s.Visit(page.URL); err != nil {
 			s.Logger.Error("Failed to visit list page",
 				zap.String("url", page.URL),
@@ -108,6 +136,105 @@ func (s *ListMonitorSpider) Run() (*CrawlResult, error) {
 	return result, nil
 }
 
+// visitAdapterPage requests one page of an adapter list. Headless pages are
+// rendered synchronously, the others go through the collector.
+func (s *ListMonitorSpider) visitAdapterPage(listPage *ListPage, index int, pageURL string, result *CrawlResult) {
+	adapter := listPage.Adapter
+	_, body := adapter.PageRequest(listPage.URL, index)
+
+	if adapter.Render.Headless {
+		pageHTML, err := s.renderHTML(context.Background(), pageURL, adapter.Render)
+		if err != nil {
+			s.addAdapterError(listPage, index, pageURL, err, result)
+			return
+		}
+		s.handleAdapterPage(listPage, index, pageURL, []byte(pageHTML), result)
+		return
+	}
+
+	ctx := colly.NewContext()
+	ctx.Put("adapter_list", listPage)
+	ctx.Put("adapter_page", index)
+	headers := http.Header{}
+	for k, v := range adapter.Request.Headers {
+		headers.Set(k, v)
+	}
+	if adapter.Request.Method == "POST" && headers.Get("Content-Type") == "" {
+		headers.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	var reqBody io.Reader
+	if adapter.Request.Method == "POST" {
+		reqBody = strings.NewReader(body)
+	}
+	if err := s.Collector.Request(adapter.Request.Method, pageURL, reqBody, ctx, headers); err != nil {
+		s.addAdapterError(listPage, index, pageURL, err, result)
+	}
+}
+
+// handleAdapterPage extracts the articles of an adapter list page and
+// requests the next page while it still yields new articles
+func (s *ListMonitorSpider) handleAdapterPage(listPage *ListPage, index int, pageURL string, body []byte, result *CrawlResult) {
+	adapter := listPage.Adapter
+	items, next, err := adapter.ExtractList(body, pageURL)
+	if err != nil {
+		s.addAdapterError(listPage, index, pageURL, err, result)
+		return
+	}
+
+	report := AdapterPageReport{Page: index + 1, URL: pageURL, Items: len(items)}
+	s.resultMu.Lock()
+	for _, item := range items {
+		result.TotalFound++
+		if s.IsURLCrawled(item.URL) {
+			continue
+		}
+		s.MarkURLCrawled(item.URL)
+		title := item.Title
+		if title == "" {
+			title = item.URL
+		}
+		s.NewArticles = append(s.NewArticles, Article{
+			URL:          item.URL,
+			Title:        title,
+			PublishDate:  item.Date,
+			SourceListID: listPage.ID,
+			SourceName:   listPage.SourceName,
+			Category:     listPage.Category,
+			DiscoveredAt: time.Now(),
+		})
+		result.NewArticles++
+		report.New++
+	}
+	result.Pages = append(result.Pages, report)
+	s.resultMu.Unlock()
+
+	if index+1 >= adapter.Pagination.MaxPages || len(items) == 0 || (report.New == 0 && !s.fullScan) {
+		return
+	}
+	switch adapter.Pagination.Type {
+	case AdapterPaginationNext:
+		if next != "" && next != pageURL {
+			s.visitAdapterPage(listPage, index+1, next, result)
+		}
+	case AdapterPaginationTemplate:
+		nextURL, _ := adapter.PageRequest(listPage.URL, index+1)
+		s.visitAdapterPage(listPage, index+1, nextURL, result)
+	}
+}
+
+// addAdapterError records a failed adapter list page
+func (s *ListMonitorSpider) addAdapterError(listPage *ListPage, index int, pageURL string, err error, result *CrawlResult) {
+	s.Logger.Error("Failed to crawl adapter list page",
+		zap.String("url", pageURL),
+		zap.Int("page", index+1),
+		zap.Error(err),
+	)
+	s.resultMu.Lock()
+	result.Errors = append(result.Errors, err.Error())
+	result.Pages = append(result.Pages, AdapterPageReport{Page: index + 1, URL: pageURL, Error: err.Error()})
+	s.resultMu.Unlock()
+}
+
 // findListPageByURL finds the list page configuration by URL
 func (s *ListMonitorSpider) findListPageByURL(url string) *ListPage {
 	for i := range s.ListPages {
@@ -0,0 +1,220 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrDomainPaused is returned for requests to a domain whose circuit breaker is open
+var ErrDomainPaused = errors.New("domain paused after repeated server errors")
+
+// DomainLimit overrides the politeness settings of matching domains
+type DomainLimit struct {
+	Domain      string        `mapstructure:"domain"`      // host, or glob such as *.gov.cn
+	Parallelism int           `mapstructure:"parallelism"` // concurrent requests to one host
+	Delay       time.Duration `mapstructure:"delay"`       // pause between requests to one host
+}
+
+// politeTransport applies per-domain parallelism, delays and a circuit breaker
+// to every request of a collector, including its robots.txt fetches
+type politeTransport struct {
+	base    http.RoundTripper
+	config  *SpiderConfig
+	logger  *zap.Logger
+	domains *domainRegistry
+}
+
+// domainRegistry holds the state of every host. One registry is shared by the
+// whole process, so spiders built per task and concurrent tasks draw on the
+// same slots, delay and breaker for a host.
+type domainRegistry struct {
+	mu      sync.Mutex
+	domains map[string]*domainState
+}
+
+var sharedDomains = &domainRegistry{domains: make(map[string]*domainState)}
+
+// domainState is the politeness and breaker state of one host
+type domainState struct {
+	slots chan struct{}
+	delay time.Duration
+
+	mu          sync.Mutex
+	next        time.Time // earliest start of the next request
+	failures    int       // consecutive server errors
+	pausedUntil time.Time
+	probing     bool // a request is testing the domain after the pause
+}
+
+func newPoliteTransport(base http.RoundTripper, config *SpiderConfig, logger *zap.Logger) *politeTransport {
+	return &politeTransport{
+		base:    base,
+		config:  config,
+		logger:  logger,
+		domains: sharedDomains,
+	}
+}
+
+// get returns the state of a host (with port, if the URL has one). The first
+// spider to contact the host sizes it from its matching limit.
+func (r *domainRegistry) get(host string, config *SpiderConfig) *domainState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if d, ok := r.domains[host]; ok {
+		return d
+	}
+
+	name := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		name = h
+	}
+	parallelism := config.DomainParallelism
+	if parallelism <= 0 {
+		parallelism = 2
+	}
+	delay := config.DownloadDelay
+	for _, limit := range config.DomainLimits {
+		if ok, _ := path.Match(limit.Domain, name); ok || limit.Domain == name {
+			if limit.Parallelism > 0 {
+				parallelism = limit.Parallelism
+			}
+			if limit.Delay > 0 {
+				delay = limit.Delay
+			}
+			break
+		}
+	}
+
+	d := &domainState{slots: make(chan struct{}, parallelism), delay: delay}
+	r.domains[host] = d
+	return d
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *politeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var resp *http.Response
+	var err error
+	if gateErr := t.domains.do(req.Context(), req.URL.Host, t.config, t.logger, func() bool {
+		resp, err = t.base.RoundTrip(req)
+		// connection failures count like 5xx: both mean the site is struggling
+		return err != nil || resp.StatusCode >= 500
+	}); gateErr != nil {
+		return nil, gateErr
+	}
+	return resp, err
+}
+
+// do runs a request to host once the breaker, parallelism and delay allow it.
+// fetch reports whether the request failed in a way that counts for the breaker.
+func (r *domainRegistry) do(ctx context.Context, host string, config *SpiderConfig, logger *zap.Logger, fetch func() bool) error {
+	d := r.get(host, config)
+
+	if err := d.allow(time.Now()); err != nil {
+		return err
+	}
+	if err := d.acquire(ctx); err != nil {
+		d.endProbe()
+		return err
+	}
+	failed := fetch()
+	<-d.slots
+
+	// a cancelled request says nothing about the site
+	if ctx.Err() != nil {
+		d.endProbe()
+		return nil
+	}
+	if d.report(failed, config.BreakerThreshold, config.BreakerCooldown) && logger != nil {
+		logger.Warn("Pausing domain after repeated server errors",
+			zap.String("domain", host),
+			zap.Duration("cooldown", config.BreakerCooldown),
+		)
+	}
+	return nil
+}
+
+// allow rejects requests while the breaker is open; after the cooldown one
+// request at a time probes the domain
+func (d *domainState) allow(now time.Time) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.pausedUntil.IsZero() {
+		return nil
+	}
+	if now.Before(d.pausedUntil) || d.probing {
+		return ErrDomainPaused
+	}
+	d.probing = true
+	return nil
+}
+
+// endProbe lets another request probe the domain
+func (d *domainState) endProbe() {
+	d.mu.Lock()
+	d.probing = false
+	d.mu.Unlock()
+}
+
+// acquire waits for a free slot and the delay since the previous request
+func (d *domainState) acquire(ctx context.Context) error {
+	select {
+	case d.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	d.mu.Lock()
+	now := time.Now()
+	start := now
+	if d.next.After(now) {
+		start = d.next
+	}
+	d.next = start.Add(d.delay)
+	if d.delay > 0 {
+		d.next = d.next.Add(time.Duration(rand.Int63n(int64(d.delay/2) + 1)))
+	}
+	d.mu.Unlock()
+
+	if wait := start.Sub(now); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			<-d.slots
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// report records the outcome of a request and reports whether the breaker opened
+func (d *domainState) report(failed bool, threshold int, cooldown time.Duration) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !failed {
+		d.failures = 0
+		d.pausedUntil = time.Time{}
+		d.probing = false
+		return false
+	}
+
+	d.failures++
+	if threshold <= 0 || (!d.probing && d.failures < threshold) {
+		return false
+	}
+	d.pausedUntil = time.Now().Add(cooldown)
+	d.probing = false
+	return true
+}
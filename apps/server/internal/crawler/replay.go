@@ -0,0 +1,448 @@
+package crawler
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrFixtureNotFound is returned by ReplayTransport for unrecorded requests
+var ErrFixtureNotFound = errors.New("no recorded fixture for request")
+
+// fixtureManifest is the index file of a fixture directory
+const fixtureManifest = "fixtures.json"
+
+// defaultIgnoredParams are query parameters that change on every request and
+// are ignored when matching fixtures
+var defaultIgnoredParams = []string{"_", "client_context_id", "timestamp"}
+
+// Fixture is one recorded HTTP exchange
+type Fixture struct {
+	Method      string            `json:"method"`
+	URL         string            `json:"url"`
+	RequestBody string            `json:"request_body,omitempty"` // matched when set
+	Status      int               `json:"status"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	File        string            `json:"file,omitempty"` // body file in the fixture directory
+	Body        []byte            `json:"-"`
+}
+
+// ReplayTransport serves recorded fixtures instead of the network, so
+// crawlers can be tested offline against saved HAR captures and pages. It
+// works for colly (Spider.SetTransport), net/http clients and, through
+// NewReplayTLSClient, the TLS fingerprint client.
+type ReplayTransport struct {
+	// IgnoreParams lists query parameters left out of request matching
+	IgnoreParams []string
+
+	mu       sync.Mutex
+	fixtures map[string][]*Fixture
+	served   map[*Fixture]int
+	misses   []string
+}
+
+// NewReplayTransport creates an empty replay transport
+func NewReplayTransport() *ReplayTransport {
+	return &ReplayTransport{
+		IgnoreParams: defaultIgnoredParams,
+		fixtures:     make(map[string][]*Fixture),
+		served:       make(map[*Fixture]int),
+	}
+}
+
+// Add registers fixtures; repeated requests are answered in recording order,
+// the last fixture being reused once all were served
+func (t *ReplayTransport) Add(fixtures ...*Fixture) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, f := range fixtures {
+		if f.Method == "" {
+			f.Method = http.MethodGet
+		}
+		if f.Status == 0 {
+			f.Status = http.StatusOK
+		}
+		key := t.key(f.Method, f.URL)
+		t.fixtures[key] = append(t.fixtures[key], f)
+	}
+}
+
+// AddPage registers a page body for a GET request
+func (t *ReplayTransport) AddPage(pageURL, contentType string, body []byte) {
+	t.Add(&Fixture{URL: pageURL, Headers: map[string]string{"Content-Type": contentType}, Body: body})
+}
+
+// Misses returns the requests that had no fixture
+func (t *ReplayTransport) Misses() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.misses...)
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody string
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		reqBody = string(data)
+	}
+
+	f := t.match(req.Method, req.URL.String(), reqBody)
+	if f == nil {
+		// a missing robots.txt means everything is allowed
+		if req.URL.Path == "/robots.txt" {
+			return replayResponse(req, &Fixture{Status: http.StatusNotFound}), nil
+		}
+		t.mu.Lock()
+		t.misses = append(t.misses, req.Method+" "+req.URL.String())
+		t.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s %s", ErrFixtureNotFound, req.Method, req.URL)
+	}
+	return replayResponse(req, f), nil
+}
+
+// match picks the fixture for a request: same method and URL (ignoring
+// volatile parameters), preferring one with the same request body
+func (t *ReplayTransport) match(method, rawURL, body string) *Fixture {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	candidates := t.fixtures[t.key(method, rawURL)]
+	var withBody, anyBody []*Fixture
+	for _, f := range candidates {
+		switch f.RequestBody {
+		case body:
+			withBody = append(withBody, f)
+		case "":
+			anyBody = append(anyBody, f)
+		}
+	}
+	if len(withBody) == 0 {
+		withBody = anyBody
+	}
+	if len(withBody) == 0 {
+		// bodies that differ on every run (encrypted passwords, nonces)
+		withBody = candidates
+	}
+	if len(withBody) == 0 {
+		return nil
+	}
+
+	for _, f := range withBody {
+		if t.served[f] == 0 {
+			t.served[f]++
+			return f
+		}
+	}
+	last := withBody[len(withBody)-1]
+	t.served[last]++
+	return last
+}
+
+// key normalizes a request to method, URL without fragment and sorted query
+func (t *ReplayTransport) key(method, rawURL string) string {
+	u, err := url.Parse(NormalizeURL(rawURL))
+	if err != nil {
+		return strings.ToUpper(method) + " " + rawURL
+	}
+	u.Fragment = ""
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	query := u.Query()
+	for _, p := range t.IgnoreParams {
+		query.Del(p)
+	}
+	u.RawQuery = query.Encode() // Encode sorts by key
+	return strings.ToUpper(method) + " " + u.String()
+}
+
+// replayResponse builds the response of a fixture
+func replayResponse(req *http.Request, f *Fixture) *http.Response {
+	header := make(http.Header)
+	for k, v := range f.Headers {
+		header.Set(k, v)
+	}
+	// recorded bodies are stored decoded
+	header.Del("Content-Encoding")
+	header.Del("Content-Length")
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(f.Body)),
+		ContentLength: int64(len(f.Body)),
+		Request:       req,
+	}
+}
+
+// LoadHAR registers the entries of a HAR capture (browser "Save all as HAR")
+func (t *ReplayTransport) LoadHAR(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var har struct {
+		Log struct {
+			Entries []struct {
+				Request struct {
+					Method   string `json:"method"`
+					URL      string `json:"url"`
+					PostData *struct {
+						Text string `json:"text"`
+					} `json:"postData"`
+				} `json:"request"`
+				Response struct {
+					Status  int `json:"status"`
+					Headers []struct {
+						Name  string `json:"name"`
+						Value string `json:"value"`
+					} `json:"headers"`
+					Content struct {
+						Text     string `json:"text"`
+						Encoding string `json:"encoding"`
+					} `json:"content"`
+				} `json:"response"`
+			} `json:"entries"`
+		} `json:"log"`
+	}
+	if err := json.Unmarshal(data, &har); err != nil {
+		return fmt.Errorf("invalid HAR file %s: %w", path, err)
+	}
+
+	for _, e := range har.Log.Entries {
+		f := &Fixture{
+			Method:  e.Request.Method,
+			URL:     e.Request.URL,
+			Status:  e.Response.Status,
+			Headers: make(map[string]string),
+			Body:    []byte(e.Response.Content.Text),
+		}
+		if e.Request.PostData != nil {
+			f.RequestBody = e.Request.PostData.Text
+		}
+		if e.Response.Content.Encoding == "base64" {
+			if f.Body, err = base64.StdEncoding.DecodeString(e.Response.Content.Text); err != nil {
+				return fmt.Errorf("invalid base64 body for %s: %w", e.Request.URL, err)
+			}
+		}
+		for _, h := range e.Response.Headers {
+			// HTTP/2 pseudo headers and repeated Vary lines are not needed
+			if !strings.HasPrefix(h.Name, ":") {
+				f.Headers[http.CanonicalHeaderKey(h.Name)] = h.Value
+			}
+		}
+		t.Add(f)
+	}
+	return nil
+}
+
+// LoadFixtureDir registers the fixtures of a directory written by
+// RecordingTransport or by hand: fixtures.json plus one body file per entry
+func (t *ReplayTransport) LoadFixtureDir(dir string) error {
+	data, err := os.ReadFile(filepath.Join(dir, fixtureManifest))
+	if err != nil {
+		return err
+	}
+	var fixtures []*Fixture
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return fmt.Errorf("invalid fixture manifest in %s: %w", dir, err)
+	}
+	for _, f := range fixtures {
+		if f.File != "" {
+			if f.Body, err = os.ReadFile(filepath.Join(dir, f.File)); err != nil {
+				return err
+			}
+		}
+	}
+	t.Add(fixtures...)
+	return nil
+}
+
+// RecordingTransport passes requests through and saves every exchange as a
+// fixture, producing directories LoadFixtureDir can replay
+type RecordingTransport struct {
+	Base http.RoundTripper
+	Dir  string
+
+	log *fixtureLog
+}
+
+// fixtureLog is the manifest shared by recorders writing to one directory
+type fixtureLog struct {
+	mu       sync.Mutex
+	fixtures []*Fixture
+}
+
+// recordedHeaders are the response headers kept in fixtures
+var recordedHeaders = []string{"Content-Type", "Location", "ETag", "Last-Modified", "Set-Cookie", "Retry-After"}
+
+// NewRecordingTransport records into dir, appending to fixtures already there
+func NewRecordingTransport(base http.RoundTripper, dir string) (*RecordingTransport, error) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	t := &RecordingTransport{Base: base, Dir: dir, log: &fixtureLog{}}
+	if data, err := os.ReadFile(filepath.Join(dir, fixtureManifest)); err == nil {
+		if err := json.Unmarshal(data, &t.log.fixtures); err != nil {
+			return nil, fmt.Errorf("invalid fixture manifest in %s: %w", dir, err)
+		}
+	}
+	return t, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		reqBody = data
+		req.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := t.Record(req.Method, req.URL.String(), string(reqBody), resp.StatusCode, resp.Header, body); err != nil {
+		return nil, fmt.Errorf("recording %s: %w", req.URL, err)
+	}
+	return resp, nil
+}
+
+// Record saves one exchange; headers may be net/http or fhttp headers
+func (t *RecordingTransport) Record(method, rawURL, reqBody string, status int, header map[string][]string, body []byte) error {
+	f := &Fixture{
+		Method:      method,
+		URL:         rawURL,
+		RequestBody: reqBody,
+		Status:      status,
+		Headers:     make(map[string]string),
+	}
+	for _, name := range recordedHeaders {
+		for k, v := range header {
+			if strings.EqualFold(k, name) && len(v) > 0 {
+				f.Headers[name] = v[0]
+			}
+		}
+	}
+	if cookie, ok := f.Headers["Set-Cookie"]; ok {
+		f.Headers["Set-Cookie"] = redactSetCookie(cookie)
+	}
+
+	t.log.mu.Lock()
+	defer t.log.mu.Unlock()
+
+	f.File = fmt.Sprintf("%04d%s", len(t.log.fixtures)+1, fixtureExt(f.Headers["Content-Type"]))
+	if err := os.WriteFile(filepath.Join(t.Dir, f.File), body, 0644); err != nil {
+		return err
+	}
+	t.log.fixtures = append(t.log.fixtures, f)
+
+	data, err := json.MarshalIndent(t.log.fixtures, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(t.Dir, fixtureManifest), data, 0644)
+}
+
+// redactSetCookie replaces the cookie value so session tokens of a recorded
+// run do not end up in fixtures; the name and attributes stay for replay
+func redactSetCookie(cookie string) string {
+	pair, attrs, hasAttrs := strings.Cut(cookie, ";")
+	name, _, _ := strings.Cut(pair, "=")
+	redacted := strings.TrimSpace(name) + "=REDACTED"
+	if hasAttrs {
+		redacted += ";" + attrs
+	}
+	return redacted
+}
+
+// fixtureExt picks a body file extension from the content type
+func fixtureExt(contentType string) string {
+	ct := strings.ToLower(contentType)
+	switch {
+	case strings.Contains(ct, "html"):
+		return ".html"
+	case strings.Contains(ct, "json"):
+		return ".json"
+	case strings.Contains(ct, "xml"):
+		return ".xml"
+	case strings.Contains(ct, "pdf"):
+		return ".pdf"
+	case strings.HasPrefix(ct, "text/"):
+		return ".txt"
+	}
+	return ".bin"
+}
+
+var (
+	recorderMu sync.RWMutex
+	recorder   *RecordingTransport
+)
+
+// SetRecordDir makes every crawler client created afterwards record its
+// traffic into dir, to capture fixtures from a real run; empty disables it
+func SetRecordDir(dir string) error {
+	recorderMu.Lock()
+	defer recorderMu.Unlock()
+	if dir == "" {
+		recorder = nil
+		return nil
+	}
+	rec, err := NewRecordingTransport(http.DefaultTransport, dir)
+	if err != nil {
+		return err
+	}
+	recorder = rec
+	return nil
+}
+
+// activeRecorder returns the recorder set by SetRecordDir, or nil
+func activeRecorder() *RecordingTransport {
+	recorderMu.RLock()
+	defer recorderMu.RUnlock()
+	return recorder
+}
+
+// recordingTransport wraps base with the active recorder, if any
+func recordingTransport(base http.RoundTripper) http.RoundTripper {
+	rec := activeRecorder()
+	if rec == nil {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &RecordingTransport{Base: base, Dir: rec.Dir, log: rec.log}
+}
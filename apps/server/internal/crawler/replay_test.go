@@ -0,0 +1,264 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/what-cse/server/internal/parser"
+	"go.uber.org/zap"
+)
+
+// Run `go test ./internal/crawler -run Golden -update` after an intended
+// extraction change and review the diff of testdata/golden.
+var updateGolden = flag.Bool("update", false, "rewrite golden files")
+
+const govSite = "https://rsj.example.gov.cn"
+
+// newReplay loads the named fixture directories into one transport
+func newReplay(t *testing.T, dirs ...string) *ReplayTransport {
+	t.Helper()
+	rt := NewReplayTransport()
+	for _, dir := range dirs {
+		if err := rt.LoadFixtureDir(filepath.Join("testdata", "fixtures", dir)); err != nil {
+			t.Fatalf("load fixtures %s: %v", dir, err)
+		}
+	}
+	return rt
+}
+
+// assertGolden compares got, as indented JSON, with testdata/golden/<name>.json
+func assertGolden(t *testing.T, name string, got interface{}) {
+	t.Helper()
+	data, err := json.MarshalIndent(got, "", "  ")
+	if err != nil {
+		t.Fatalf("marshal %s: %v", name, err)
+	}
+	data = append(data, '\n')
+
+	path := filepath.Join("testdata", "golden", name+".json")
+	if *updateGolden {
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatalf("write golden %s: %v", path, err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden %s (run with -update to create it): %v", path, err)
+	}
+	if !bytes.Equal(want, data) {
+		t.Errorf("%s differs from golden file %s\n--- got ---\n%s", name, path, data)
+	}
+}
+
+func assertNoMisses(t *testing.T, rt *ReplayTransport) {
+	t.Helper()
+	if misses := rt.Misses(); len(misses) > 0 {
+		t.Errorf("requests without fixture: %v", misses)
+	}
+}
+
+func TestGoldenFetchPageContent(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{"page_content_utf8", govSite + "/tzgg/202510/t20251015_1001.html"},
+		{"page_content_gbk", govSite + "/tzgg/202510/t20251012_1002.html"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := newReplay(t, "gov_site")
+			spider := NewFenbiSpider(testSpiderConfig(), zap.NewNop())
+			spider.SetTransport(rt)
+
+			content, err := spider.FetchPageContent(tt.url)
+			if err != nil {
+				t.Fatalf("FetchPageContent: %v", err)
+			}
+			content.HTML = "" // covered by the fixture itself
+			assertGolden(t, tt.name, content)
+			assertNoMisses(t, rt)
+		})
+	}
+}
+
+func TestGoldenResolveShortURL(t *testing.T) {
+	rt := newReplay(t, "fenbi", "gov_site")
+	spider := NewFenbiSpider(testSpiderConfig(), zap.NewNop())
+	spider.SetTransport(rt)
+
+	finalURL, err := spider.ResolveShortURL("https://t.fenbi.com/s/AbC123")
+	if err != nil {
+		t.Fatalf("ResolveShortURL: %v", err)
+	}
+	if want := govSite + "/tzgg/202510/t20251015_1001.html"; finalURL != want {
+		t.Errorf("final URL = %q, want %q", finalURL, want)
+	}
+}
+
+func TestGoldenFenbiAnnouncementList(t *testing.T) {
+	rt := newReplay(t, "fenbi")
+	spider := NewFenbiSpider(testSpiderConfig(), zap.NewNop())
+	spider.SetTransport(rt)
+
+	result, err := spider.CrawlAnnouncementList("all", "all", 2025, 1)
+	if err != nil {
+		t.Fatalf("CrawlAnnouncementList: %v", err)
+	}
+	assertGolden(t, "fenbi_announcement_list", result)
+	assertNoMisses(t, rt)
+}
+
+func TestGoldenWechatArticleList(t *testing.T) {
+	rt := newReplay(t, "wechat")
+	mp := NewWechatMPCrawler(zap.NewNop())
+	mp.SetTransport(rt)
+
+	result, err := mp.GetArticleList(context.Background(), "MzA3NjY2NTQ4MQ==", "1234567890", nil, 0, 5)
+	if err != nil {
+		t.Fatalf("GetArticleList: %v", err)
+	}
+	assertGolden(t, "wechat_article_list", result)
+	assertNoMisses(t, rt)
+}
+
+// crawlGovList runs the list monitor over the recorded government site
+func crawlGovList(t *testing.T, rt *ReplayTransport) []Article {
+	t.Helper()
+	lister := NewListMonitorSpider(testSpiderConfig(), zap.NewNop())
+	lister.SetTransport(rt)
+	lister.SetListPages([]ListPage{{
+		ID:              7,
+		URL:             govSite + "/tzgg/",
+		SourceName:      "示例市人社局",
+		Category:        "公务员",
+		ArticleSelector: ".news-list li a",
+		Status:          "active",
+	}})
+	result, err := lister.Run()
+	if err != nil {
+		t.Fatalf("list monitor: %v", err)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("list monitor errors: %v", result.Errors)
+	}
+
+	articles := result.Articles
+	sort.Slice(articles, func(i, j int) bool { return articles[i].URL < articles[j].URL })
+	for i := range articles {
+		articles[i].DiscoveredAt = time.Time{}
+	}
+	return articles
+}
+
+func TestGoldenListPageCandidates(t *testing.T) {
+	rt := newReplay(t, "gov_site")
+	assertGolden(t, "list_page_candidates", crawlGovList(t, rt))
+	assertNoMisses(t, rt)
+}
+
+func TestGoldenAnnouncements(t *testing.T) {
+	rt := newReplay(t, "gov_site")
+	articles := crawlGovList(t, rt)
+
+	detailer := NewAnnouncementSpider(testSpiderConfig(), zap.NewNop())
+	detailer.SetTransport(rt)
+	detailer.SetArticles(articles)
+	result, err := detailer.Run()
+	if err != nil {
+		t.Fatalf("announcement spider: %v", err)
+	}
+
+	type golden struct {
+		Announcement
+		Positions []parser.ParsedPosition `json:"positions,omitempty"`
+	}
+	tableParser := parser.NewHTMLTableParser(zap.NewNop())
+	var got []golden
+	for _, a := range result.Announcements {
+		positions, err := tableParser.ParseTables(a.ContentHTML)
+		if err != nil {
+			t.Fatalf("parse positions of %s: %v", a.URL, err)
+		}
+		a.ContentHTML = ""
+		a.CrawledAt = time.Time{}
+		got = append(got, golden{Announcement: a, Positions: positions})
+	}
+	sort.Slice(got, func(i, j int) bool { return got[i].URL < got[j].URL })
+
+	assertGolden(t, "announcements", got)
+	assertNoMisses(t, rt)
+}
+
+func TestFenbiLoginReplayHAR(t *testing.T) {
+	rt := NewReplayTransport()
+	if err := rt.LoadHAR(filepath.Join("..", "..", "..", "..", "har", "fenbi-login.har")); err != nil {
+		t.Fatalf("LoadHAR: %v", err)
+	}
+	spider := NewFenbiSpider(testSpiderConfig(), zap.NewNop())
+	spider.SetTransport(rt)
+
+	// the capture holds an empty login response, the case behind 服务器返回空响应
+	result, err := spider.Login("13800000000", "secret")
+	if err == nil {
+		t.Fatal("Login succeeded on an empty recorded response")
+	}
+	if result.Success || result.Message != "服务器返回空响应" {
+		t.Errorf("Login result = %+v", result)
+	}
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("<html><title>" + r.URL.Path + "</title></html>"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	recorder, err := NewRecordingTransport(http.DefaultTransport, dir)
+	if err != nil {
+		t.Fatalf("NewRecordingTransport: %v", err)
+	}
+	client := &http.Client{Transport: recorder}
+	for _, path := range []string{"/a.html?_=1", "/b.html"} {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("record %s: %v", path, err)
+		}
+		resp.Body.Close()
+	}
+
+	rt := NewReplayTransport()
+	if err := rt.LoadFixtureDir(dir); err != nil {
+		t.Fatalf("LoadFixtureDir: %v", err)
+	}
+	replayed := &http.Client{Transport: rt}
+	// volatile cache-busting parameters do not affect matching
+	resp, err := replayed.Get(server.URL + "/a.html?_=2")
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	var body bytes.Buffer
+	body.ReadFrom(resp.Body)
+	resp.Body.Close()
+	if body.String() != "<html><title>/a.html</title></html>" || resp.Header.Get("ETag") != `"v1"` {
+		t.Errorf("replayed %q with ETag %q", body.String(), resp.Header.Get("ETag"))
+	}
+
+	if _, err := replayed.Get(server.URL + "/missing.html"); !errors.Is(err, ErrFixtureNotFound) {
+		t.Errorf("unrecorded request error = %v, want ErrFixtureNotFound", err)
+	}
+}
@@ -0,0 +1,637 @@
+package crawler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+	"golang.org/x/net/html"
+	"gopkg.in/yaml.v3"
+)
+
+// Site adapter list types
+const (
+	AdapterListHTML = "html"
+	AdapterListJSON = "json"
+)
+
+// Site adapter pagination types
+const (
+	AdapterPaginationNone     = "none"     // only the list URL
+	AdapterPaginationNext     = "next"     // follow a next-page link or JSON field
+	AdapterPaginationTemplate = "template" // page numbers in the URL or POST body
+)
+
+const (
+	defaultAdapterMaxPages = 5
+	maxAdapterPages        = 50
+)
+
+var defaultAttachmentPattern = regexp.MustCompile(`(?i)\.(pdf|xlsx?|docx?|et|wps|zip|rar)(\?|$)`)
+
+// SiteAdapter declares how to crawl the announcement list and detail pages of
+// one site, for sites the generic heuristics cannot handle. It is stored as
+// YAML or JSON on the list page.
+type SiteAdapter struct {
+	Name        string            `json:"name,omitempty" yaml:"name,omitempty"`
+	Request     AdapterRequest    `json:"request" yaml:"request"`
+	Render      AdapterRender     `json:"render" yaml:"render"`
+	List        AdapterList       `json:"list" yaml:"list"`
+	Pagination  AdapterPagination `json:"pagination" yaml:"pagination"`
+	Detail      AdapterDetail     `json:"detail" yaml:"detail"`
+	DateFormats []string          `json:"date_formats,omitempty" yaml:"date_formats,omitempty"` // Go layouts, "unix" or "unix_ms"
+}
+
+// AdapterRequest describes how list pages are requested
+type AdapterRequest struct {
+	Method  string            `json:"method,omitempty" yaml:"method,omitempty"` // GET (default) or POST
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Body    string            `json:"body,omitempty" yaml:"body,omitempty"` // POST body, {page} is the page number
+}
+
+// AdapterRender enables headless browser rendering of JS-built list pages
+type AdapterRender struct {
+	Headless     bool   `json:"headless,omitempty" yaml:"headless,omitempty"`
+	WaitSelector string `json:"wait_selector,omitempty" yaml:"wait_selector,omitempty"` // CSS selector to wait for
+	WaitMS       int    `json:"wait_ms,omitempty" yaml:"wait_ms,omitempty"`             // extra wait after loading
+}
+
+// Selector locates nodes with CSS or XPath in HTML, or a dot path such as
+// data.list or data.items.0.url in JSON
+type Selector struct {
+	CSS   string `json:"css,omitempty" yaml:"css,omitempty"`
+	XPath string `json:"xpath,omitempty" yaml:"xpath,omitempty"`
+	JSON  string `json:"json,omitempty" yaml:"json,omitempty"`
+}
+
+// FieldRule extracts one value relative to a list item or detail page
+type FieldRule struct {
+	Selector `yaml:",inline"`
+	Attr     string `json:"attr,omitempty" yaml:"attr,omitempty"`         // attribute instead of text, e.g. href
+	Regex    string `json:"regex,omitempty" yaml:"regex,omitempty"`       // keeps the first group, or the match
+	Template string `json:"template,omitempty" yaml:"template,omitempty"` // e.g. /detail.html?id={value}
+
+	regex *regexp.Regexp
+}
+
+// AdapterList describes the items of a list page
+type AdapterList struct {
+	Type  string    `json:"type,omitempty" yaml:"type,omitempty"` // html (default) or json
+	Items Selector  `json:"items" yaml:"items"`
+	Link  FieldRule `json:"link" yaml:"link"`
+	Title FieldRule `json:"title" yaml:"title"`
+	Date  FieldRule `json:"date" yaml:"date"`
+}
+
+// AdapterPagination describes how further list pages are reached
+type AdapterPagination struct {
+	Type        string   `json:"type,omitempty" yaml:"type,omitempty"`
+	Next        Selector `json:"next" yaml:"next"`                                     // type next
+	URLTemplate string   `json:"url_template,omitempty" yaml:"url_template,omitempty"` // type template, {page} is the page number
+	Start       int      `json:"start,omitempty" yaml:"start,omitempty"`               // page number of the list URL, default 1
+	MaxPages    int      `json:"max_pages,omitempty" yaml:"max_pages,omitempty"`
+}
+
+// AdapterDetail describes announcement detail pages
+type AdapterDetail struct {
+	Headless    bool               `json:"headless,omitempty" yaml:"headless,omitempty"`
+	Title       FieldRule          `json:"title" yaml:"title"`
+	Content     Selector           `json:"content" yaml:"content"`
+	Date        FieldRule          `json:"date" yaml:"date"`
+	Remove      []string           `json:"remove,omitempty" yaml:"remove,omitempty"` // CSS selectors dropped before extraction
+	Attachments AdapterAttachments `json:"attachments" yaml:"attachments"`
+}
+
+// AdapterAttachments describes attachment links of detail pages
+type AdapterAttachments struct {
+	Selector `yaml:",inline"` // links to consider, default a[href]
+	Patterns []string         `json:"patterns,omitempty" yaml:"patterns,omitempty"` // regexes on the href or link text
+
+	patterns []*regexp.Regexp
+}
+
+// AdapterItem is one entry of a list page
+type AdapterItem struct {
+	URL   string `json:"url"`
+	Title string `json:"title"`
+	Date  string `json:"date,omitempty"`
+}
+
+// AdapterDetailPage is the content extracted from a detail page
+type AdapterDetailPage struct {
+	Title       string       `json:"title"`
+	Content     string       `json:"content"`
+	ContentHTML string       `json:"content_html"`
+	Date        string       `json:"date,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+// ParseSiteAdapter parses and validates a YAML or JSON adapter definition
+func ParseSiteAdapter(data []byte) (*SiteAdapter, error) {
+	var adapter SiteAdapter
+	if err := yaml.Unmarshal(data, &adapter); err != nil {
+		return nil, fmt.Errorf("invalid adapter definition: %w", err)
+	}
+	if err := adapter.Validate(); err != nil {
+		return nil, err
+	}
+	return &adapter, nil
+}
+
+// Validate fills in defaults and checks selectors, regexes and strategies
+func (a *SiteAdapter) Validate() error {
+	a.Request.Method = strings.ToUpper(a.Request.Method)
+	if a.Request.Method == "" {
+		a.Request.Method = "GET"
+	}
+	if a.Request.Method != "GET" && a.Request.Method != "POST" {
+		return fmt.Errorf("unsupported request method %q", a.Request.Method)
+	}
+	if a.Render.Headless && a.Request.Method != "GET" {
+		return errors.New("headless rendering only supports GET list pages")
+	}
+
+	if a.List.Type == "" {
+		a.List.Type = AdapterListHTML
+	}
+	switch a.List.Type {
+	case AdapterListHTML:
+		if a.List.Items.CSS == "" && a.List.Items.XPath == "" {
+			return errors.New("list.items needs a css or xpath selector")
+		}
+	case AdapterListJSON:
+		if a.List.Items.JSON == "" {
+			return errors.New("list.items needs a json path")
+		}
+		if a.List.Link.JSON == "" {
+			return errors.New("list.link needs a json path")
+		}
+		if a.Render.Headless {
+			return errors.New("headless rendering only applies to html lists")
+		}
+	default:
+		return fmt.Errorf("unsupported list type %q", a.List.Type)
+	}
+
+	if a.Pagination.Type == "" {
+		a.Pagination.Type = AdapterPaginationNone
+	}
+	switch a.Pagination.Type {
+	case AdapterPaginationNone:
+	case AdapterPaginationNext:
+		if a.Pagination.Next == (Selector{}) {
+			return errors.New("pagination.next needs a selector")
+		}
+	case AdapterPaginationTemplate:
+		if !strings.Contains(a.Pagination.URLTemplate, "{page}") && !strings.Contains(a.Request.Body, "{page}") {
+			return errors.New("template pagination needs {page} in pagination.url_template or request.body")
+		}
+	default:
+		return fmt.Errorf("unsupported pagination type %q", a.Pagination.Type)
+	}
+	if a.Pagination.Start <= 0 {
+		a.Pagination.Start = 1
+	}
+	if a.Pagination.MaxPages <= 0 {
+		a.Pagination.MaxPages = defaultAdapterMaxPages
+	}
+	a.Pagination.MaxPages = min(a.Pagination.MaxPages, maxAdapterPages)
+
+	for name, sel := range map[string]Selector{
+		"list.items":         a.List.Items,
+		"list.link":          a.List.Link.Selector,
+		"list.title":         a.List.Title.Selector,
+		"list.date":          a.List.Date.Selector,
+		"pagination.next":    a.Pagination.Next,
+		"detail.title":       a.Detail.Title.Selector,
+		"detail.content":     a.Detail.Content,
+		"detail.date":        a.Detail.Date.Selector,
+		"detail.attachments": a.Detail.Attachments.Selector,
+	} {
+		if sel.XPath != "" {
+			if _, err := xpath.Compile(sel.XPath); err != nil {
+				return fmt.Errorf("%s: invalid xpath: %w", name, err)
+			}
+		}
+	}
+
+	for name, rule := range map[string]*FieldRule{
+		"list.link":    &a.List.Link,
+		"list.title":   &a.List.Title,
+		"list.date":    &a.List.Date,
+		"detail.title": &a.Detail.Title,
+		"detail.date":  &a.Detail.Date,
+	} {
+		if rule.Regex == "" {
+			continue
+		}
+		re, err := regexp.Compile(rule.Regex)
+		if err != nil {
+			return fmt.Errorf("%s: invalid regex: %w", name, err)
+		}
+		rule.regex = re
+	}
+	a.Detail.Attachments.patterns = nil
+	for _, p := range a.Detail.Attachments.Patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("detail.attachments: invalid regex: %w", err)
+		}
+		a.Detail.Attachments.patterns = append(a.Detail.Attachments.patterns, re)
+	}
+	return nil
+}
+
+// HasDetail reports whether the adapter defines detail page selectors
+func (a *SiteAdapter) HasDetail() bool {
+	d := a.Detail
+	return d.Headless || d.Title.Selector != (Selector{}) || d.Content != (Selector{}) ||
+		d.Date.Selector != (Selector{}) || d.Attachments.Selector != (Selector{}) || len(d.Attachments.Patterns) > 0
+}
+
+// PageRequest returns the URL and POST body of a list page; index 0 is the
+// list URL itself
+func (a *SiteAdapter) PageRequest(listURL string, index int) (string, string) {
+	page := strconv.Itoa(a.Pagination.Start + index)
+	pageURL := listURL
+	if index > 0 && a.Pagination.URLTemplate != "" {
+		pageURL = strings.ReplaceAll(a.Pagination.URLTemplate, "{page}", page)
+		if resolved, err := JoinURL(listURL, pageURL); err == nil {
+			pageURL = resolved
+		}
+	}
+	return pageURL, strings.ReplaceAll(a.Request.Body, "{page}", page)
+}
+
+// ExtractList extracts the items and the next page URL of a list page
+func (a *SiteAdapter) ExtractList(body []byte, pageURL string) ([]AdapterItem, string, error) {
+	if a.List.Type == AdapterListJSON {
+		return a.extractJSONList(body, pageURL)
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, "", err
+	}
+	return a.extractHTMLList(doc, pageURL)
+}
+
+func (a *SiteAdapter) extractHTMLList(doc *goquery.Document, pageURL string) ([]AdapterItem, string, error) {
+	items := selectHTML(doc.Selection, a.List.Items)
+	result := make([]AdapterItem, 0, items.Length())
+	items.Each(func(_ int, item *goquery.Selection) {
+		link := fieldHTML(item, a.List.Link, "href")
+		if a.List.Link.Selector == (Selector{}) && a.List.Link.Template == "" {
+			link = itemLink(item)
+		}
+		if link == "" || strings.HasPrefix(link, "#") || strings.HasPrefix(strings.ToLower(link), "javascript:") {
+			return
+		}
+		fullURL, err := JoinURL(pageURL, link)
+		if err != nil {
+			return
+		}
+
+		title := fieldHTML(item, a.List.Title, "")
+		if a.List.Title.Selector == (Selector{}) && a.List.Title.Attr == "" {
+			anchor := item.Filter("a").AddSelection(item.Find("a")).First()
+			title = strings.TrimSpace(anchor.AttrOr("title", ""))
+			if title == "" {
+				title = collapseSpace(anchor.Text())
+			}
+		}
+
+		var date string
+		if a.List.Date.Selector != (Selector{}) || a.List.Date.Attr != "" {
+			date = fieldHTML(item, a.List.Date, "")
+		} else {
+			date = datePattern.FindString(item.Text())
+		}
+
+		result = append(result, AdapterItem{URL: fullURL, Title: title, Date: a.NormalizeDate(date)})
+	})
+
+	var next string
+	if a.Pagination.Type == AdapterPaginationNext {
+		next = valueHTML(selectHTML(doc.Selection, a.Pagination.Next).First(), "href")
+		if next != "" && !strings.HasPrefix(strings.ToLower(next), "javascript:") {
+			if resolved, err := JoinURL(pageURL, next); err == nil {
+				next = resolved
+			}
+		} else {
+			next = ""
+		}
+	}
+	return result, next, nil
+}
+
+func (a *SiteAdapter) extractJSONList(body []byte, pageURL string) ([]AdapterItem, string, error) {
+	var root interface{}
+	if err := json.Unmarshal(body, &root); err != nil {
+		return nil, "", fmt.Errorf("list response is not JSON: %w", err)
+	}
+	list, ok := jsonPath(root, a.List.Items.JSON).([]interface{})
+	if !ok {
+		return nil, "", fmt.Errorf("list.items %q is not an array", a.List.Items.JSON)
+	}
+
+	result := make([]AdapterItem, 0, len(list))
+	for _, item := range list {
+		link := fieldJSON(item, a.List.Link)
+		if link == "" {
+			continue
+		}
+		fullURL, err := JoinURL(pageURL, link)
+		if err != nil {
+			continue
+		}
+		result = append(result, AdapterItem{
+			URL:   fullURL,
+			Title: fieldJSON(item, a.List.Title),
+			Date:  a.NormalizeDate(fieldJSON(item, a.List.Date)),
+		})
+	}
+
+	var next string
+	if a.Pagination.Type == AdapterPaginationNext && a.Pagination.Next.JSON != "" {
+		if next = jsonString(jsonPath(root, a.Pagination.Next.JSON)); next != "" {
+			if resolved, err := JoinURL(pageURL, next); err == nil {
+				next = resolved
+			}
+		}
+	}
+	return result, next, nil
+}
+
+// ExtractDetail extracts an announcement from a parsed detail page; fields
+// without selectors are left empty for the caller's heuristics
+func (a *SiteAdapter) ExtractDetail(doc *goquery.Selection, pageURL string) *AdapterDetailPage {
+	d := a.Detail
+	page := &AdapterDetailPage{}
+
+	for _, sel := range d.Remove {
+		doc.Find(sel).Remove()
+	}
+	if d.Title.Selector != (Selector{}) {
+		page.Title = fieldHTML(doc, d.Title, "")
+	}
+	if d.Date.Selector != (Selector{}) {
+		page.Date = a.NormalizeDate(fieldHTML(doc, d.Date, ""))
+	}
+	if d.Content != (Selector{}) {
+		content := selectHTML(doc, d.Content).First()
+		content.Find("script, style").Remove()
+		page.ContentHTML, _ = content.Html()
+		page.Content = cleanText(strings.TrimSpace(content.Text()))
+	}
+	if d.Attachments.Selector != (Selector{}) || len(d.Attachments.patterns) > 0 {
+		page.Attachments = a.extractAttachments(doc, pageURL)
+	}
+	return page
+}
+
+func (a *SiteAdapter) extractAttachments(doc *goquery.Selection, pageURL string) []Attachment {
+	rule := a.Detail.Attachments
+	links := doc.Find("a[href]")
+	if rule.Selector != (Selector{}) {
+		links = selectHTML(doc, rule.Selector)
+	}
+
+	attachments := make([]Attachment, 0)
+	seen := make(map[string]bool)
+	links.Each(func(_ int, link *goquery.Selection) {
+		href := strings.TrimSpace(link.AttrOr("href", ""))
+		name := collapseSpace(link.Text())
+		if href == "" {
+			return
+		}
+		matched := len(rule.patterns) == 0 && (rule.Selector != (Selector{}) || defaultAttachmentPattern.MatchString(href))
+		for _, re := range rule.patterns {
+			if re.MatchString(href) || re.MatchString(name) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return
+		}
+		fullURL, err := JoinURL(pageURL, href)
+		if err != nil || seen[fullURL] {
+			return
+		}
+		seen[fullURL] = true
+		if name == "" {
+			parts := strings.Split(href, "/")
+			name = parts[len(parts)-1]
+		}
+		attachments = append(attachments, Attachment{URL: fullURL, Name: name, Type: attachmentType(href + " " + name)})
+	})
+	return attachments
+}
+
+// NormalizeDate converts a date with the adapter's formats to 2006-01-02;
+// values no format matches are returned trimmed
+func (a *SiteAdapter) NormalizeDate(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+	for _, layout := range a.DateFormats {
+		switch layout {
+		case "unix", "unix_ms":
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			if layout == "unix_ms" {
+				return time.UnixMilli(n).Format("2006-01-02")
+			}
+			return time.Unix(n, 0).Format("2006-01-02")
+		default:
+			if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+				return t.Format("2006-01-02")
+			}
+		}
+	}
+	return value
+}
+
+// selectHTML finds nodes by CSS or XPath below a selection
+func selectHTML(sel *goquery.Selection, s Selector) *goquery.Selection {
+	switch {
+	case s.CSS != "":
+		return sel.Find(s.CSS)
+	case s.XPath != "":
+		var nodes []*html.Node
+		for _, n := range sel.Nodes {
+			nodes = append(nodes, htmlquery.Find(n, s.XPath)...)
+		}
+		return sel.FindNodes(nodes...).AddNodes(xpathDetached(nodes)...)
+	}
+	return sel
+}
+
+// xpathDetached returns XPath results that are not part of the document,
+// such as attribute values, which FindNodes drops
+func xpathDetached(nodes []*html.Node) []*html.Node {
+	var detached []*html.Node
+	for _, n := range nodes {
+		if n.Parent == nil {
+			detached = append(detached, n)
+		}
+	}
+	return detached
+}
+
+// fieldHTML extracts a field below an item; defaultAttr is used when the
+// selected element is an anchor and the rule names no attribute
+func fieldHTML(item *goquery.Selection, rule FieldRule, defaultAttr string) string {
+	target := item
+	if rule.Selector != (Selector{}) {
+		target = selectHTML(item, rule.Selector).First()
+	}
+	attr := rule.Attr
+	if attr == "" && defaultAttr != "" && goquery.NodeName(target) == "a" {
+		attr = defaultAttr
+	}
+	return rule.apply(valueHTML(target, attr))
+}
+
+// valueHTML returns an attribute, or the text of the first node
+func valueHTML(sel *goquery.Selection, attr string) string {
+	if sel.Length() == 0 {
+		return ""
+	}
+	node := sel.Nodes[0]
+	if node.Parent == nil && node.Type == html.ElementNode && node.FirstChild != nil && node.FirstChild.Type == html.TextNode && node.FirstChild == node.LastChild {
+		// attribute node from XPath
+		return strings.TrimSpace(node.FirstChild.Data)
+	}
+	if attr != "" {
+		return strings.TrimSpace(sel.AttrOr(attr, ""))
+	}
+	return collapseSpace(sel.Text())
+}
+
+// itemLink returns the item's own href, or that of its first link
+func itemLink(item *goquery.Selection) string {
+	if href, ok := item.Attr("href"); ok {
+		return strings.TrimSpace(href)
+	}
+	return strings.TrimSpace(item.Find("a[href]").First().AttrOr("href", ""))
+}
+
+// fieldJSON extracts a field from a JSON list item
+func fieldJSON(item interface{}, rule FieldRule) string {
+	if rule.JSON == "" && rule.Template == "" {
+		return ""
+	}
+	var value string
+	if rule.JSON != "" {
+		value = jsonString(jsonPath(item, rule.JSON))
+	}
+	return rule.apply(value)
+}
+
+// apply runs the rule's regex and template on an extracted value
+func (r FieldRule) apply(value string) string {
+	if r.regex != nil {
+		m := r.regex.FindStringSubmatch(value)
+		switch {
+		case m == nil:
+			value = ""
+		case len(m) > 1:
+			value = m[1]
+		default:
+			value = m[0]
+		}
+	}
+	if r.Template != "" && value != "" {
+		value = strings.ReplaceAll(r.Template, "{value}", value)
+	}
+	return strings.TrimSpace(value)
+}
+
+// jsonPath walks a dot path of object keys and array indexes
+func jsonPath(v interface{}, path string) interface{} {
+	if path == "" || path == "." {
+		return v
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			v = node[key]
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil
+			}
+			v = node[i]
+		default:
+			return nil
+		}
+	}
+	return v
+}
+
+// jsonString formats a JSON scalar
+func jsonString(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	default:
+		data, _ := json.Marshal(val)
+		return string(data)
+	}
+}
+
+// attachmentType classifies an attachment by the extension in its URL or name
+func attachmentType(s string) string {
+	m := attachmentExtPattern.FindStringSubmatch(s)
+	if m == nil {
+		return "other"
+	}
+	switch strings.ToLower(m[1]) {
+	case "pdf":
+		return "pdf"
+	case "xls", "xlsx", "et":
+		return "excel"
+	case "doc", "docx", "wps":
+		return "word"
+	}
+	return "archive"
+}
+
+var attachmentExtPattern = regexp.MustCompile(`(?i)\.(pdf|xlsx?|et|docx?|wps|zip|rar)\b`)
+
+var spacePattern = regexp.MustCompile(`\s+`)
+
+// collapseSpace trims text and joins whitespace runs with one space
+func collapseSpace(s string) string {
+	return strings.TrimSpace(spacePattern.ReplaceAllString(s, " "))
+}
+
+var datePattern = regexp.MustCompile(`\d{4}[-/年.]\d{1,2}[-/月.]\d{1,2}日?`)
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
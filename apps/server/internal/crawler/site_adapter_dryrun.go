@@ -0,0 +1,62 @@
+package crawler
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const maxDryRunDetails = 10
+
+// AdapterDryRunResult shows what a site adapter extracts, without saving anything
+type AdapterDryRunResult struct {
+	Pages    []AdapterPageReport `json:"pages"`
+	Items    []Article           `json:"items"`
+	Details  []Announcement      `json:"details,omitempty"`
+	Errors   []string            `json:"errors,omitempty"`
+	Requests int64               `json:"requests"`
+	Duration time.Duration       `json:"duration"`
+}
+
+// DryRunAdapter crawls up to maxPages list pages with an adapter and extracts
+// the first detailSamples detail pages. It runs the same code as the list
+// monitor but without a URL store, so every item counts as new.
+func DryRunAdapter(config *SpiderConfig, logger *zap.Logger, adapter *SiteAdapter, listURL string, maxPages, detailSamples int) (*AdapterDryRunResult, error) {
+	startTime := time.Now()
+	trial := *adapter
+	if maxPages > 0 && maxPages < trial.Pagination.MaxPages {
+		trial.Pagination.MaxPages = maxPages
+	}
+
+	lister := NewListMonitorSpider(config, logger)
+	lister.fullScan = true
+	lister.SetListPages([]ListPage{{URL: listURL, Status: "active", Adapter: &trial}})
+	crawl, err := lister.Run()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AdapterDryRunResult{
+		Pages:    crawl.Pages,
+		Items:    crawl.Articles,
+		Errors:   crawl.Errors,
+		Requests: crawl.RequestCount,
+	}
+
+	detailSamples = min(min(detailSamples, maxDryRunDetails), len(crawl.Articles))
+	if detailSamples > 0 {
+		detailer := NewAnnouncementSpider(config, logger)
+		detailer.SetAdapters(map[uint]*SiteAdapter{0: &trial})
+		detailer.SetArticles(crawl.Articles[:detailSamples])
+		details, err := detailer.Run()
+		if err != nil {
+			return nil, err
+		}
+		result.Details = details.Announcements
+		result.Errors = append(result.Errors, details.Errors...)
+		result.Requests += details.RequestCount
+	}
+
+	result.Duration = time.Since(startTime)
+	return result, nil
+}
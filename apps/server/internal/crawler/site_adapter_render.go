@@ -0,0 +1,140 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/gocolly/colly/v2"
+	"github.com/temoto/robotstxt"
+
+	"github.com/what-cse/server/internal/headless"
+)
+
+const headlessUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+
+// browsers holds one shared headless browser per proxy setting
+var (
+	browsersMu sync.Mutex
+	browsers   = make(map[string]*headless.Browser)
+)
+
+func sharedBrowser(proxyURL string) *headless.Browser {
+	browsersMu.Lock()
+	defer browsersMu.Unlock()
+	b, ok := browsers[proxyURL]
+	if !ok {
+		b = headless.New(headless.Options{ProxyURL: proxyURL, UserAgent: headlessUserAgent})
+		browsers[proxyURL] = b
+	}
+	return b
+}
+
+// renderHTML loads a page in the shared headless browser and returns the HTML
+// after its scripts ran, for lists and details built by JavaScript. The page
+// load obeys robots.txt, the per-domain politeness and circuit breaker of the
+// collector, and goes through the configured proxy.
+func (s *Spider) renderHTML(ctx context.Context, pageURL string, render AdapterRender) (string, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return "", err
+	}
+	if s.Config.RespectRobotsTxt {
+		if err := s.checkRobots(ctx, u); err != nil {
+			return "", err
+		}
+	}
+
+	proxyURL := ""
+	if s.Config.ProxyEnabled {
+		proxyURL = s.Config.ProxyURL
+	}
+	timeout := s.Config.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	var pageHTML string
+	var renderErr error
+	if err := sharedDomains.do(ctx, u.Host, s.Config, s.Logger, func() bool {
+		pageHTML, renderErr = renderPage(ctx, sharedBrowser(proxyURL), pageURL, render, timeout)
+		// a browser that cannot start says nothing about the site
+		return renderErr != nil && !errors.Is(renderErr, headless.ErrUnavailable)
+	}); err != nil {
+		return "", err
+	}
+	return pageHTML, renderErr
+}
+
+// renderPage runs the page in a new tab of the browser
+func renderPage(ctx context.Context, browser *headless.Browser, pageURL string, render AdapterRender, timeout time.Duration) (string, error) {
+	tabCtx, cancel, err := browser.Tab(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+	tabCtx, timeoutCancel := context.WithTimeout(tabCtx, timeout)
+	defer timeoutCancel()
+
+	actions := []chromedp.Action{chromedp.Navigate(pageURL)}
+	if render.WaitSelector != "" {
+		actions = append(actions, chromedp.WaitVisible(render.WaitSelector, chromedp.ByQuery))
+	}
+	if render.WaitMS > 0 {
+		actions = append(actions, chromedp.Sleep(time.Duration(render.WaitMS)*time.Millisecond))
+	}
+	var pageHTML string
+	actions = append(actions, chromedp.OuterHTML("html", &pageHTML, chromedp.ByQuery))
+
+	if err := chromedp.Run(tabCtx, actions...); err != nil {
+		return "", fmt.Errorf("headless render failed: %w", err)
+	}
+	return pageHTML, nil
+}
+
+// checkRobots applies robots.txt to a rendered page the way the collector
+// does for its own requests. robots.txt is fetched through the spider's
+// transport and cached per host.
+func (s *Spider) checkRobots(ctx context.Context, u *url.URL) error {
+	s.robotsMu.Lock()
+	robot, ok := s.robots[u.Host]
+	s.robotsMu.Unlock()
+
+	if !ok {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.Scheme+"://"+u.Host+"/robots.txt", nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("User-Agent", headlessUserAgent)
+		client := &http.Client{Transport: s.transport, Timeout: s.Config.Timeout}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if robot, err = robotstxt.FromResponse(resp); err != nil {
+			return err
+		}
+
+		s.robotsMu.Lock()
+		if s.robots == nil {
+			s.robots = make(map[string]*robotstxt.RobotsData)
+		}
+		s.robots[u.Host] = robot
+		s.robotsMu.Unlock()
+	}
+
+	path := u.EscapedPath()
+	if u.RawQuery != "" {
+		path += "?" + u.Query().Encode()
+	}
+	if !robot.FindGroup(headlessUserAgent).Test(path) {
+		return colly.ErrRobotsTxtBlocked
+	}
+	return nil
+}
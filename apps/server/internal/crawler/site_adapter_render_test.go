@@ -0,0 +1,53 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+	"go.uber.org/zap"
+)
+
+// Rendered pages obey robots.txt and the domain breaker before Chrome starts
+func TestRenderHTMLGates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	config := testSpiderConfig()
+	config.RespectRobotsTxt = true
+	config.BreakerThreshold = 1
+	config.BreakerCooldown = time.Minute
+	spider := NewSpider("render-test", config, zap.NewNop())
+	ctx := context.Background()
+
+	if _, err := spider.renderHTML(ctx, server.URL+"/private/list", AdapterRender{}); !errors.Is(err, colly.ErrRobotsTxtBlocked) {
+		t.Errorf("disallowed page: err = %v, want ErrRobotsTxtBlocked", err)
+	}
+
+	// One server error through the collector's transport opens the breaker
+	client := &http.Client{Transport: spider.transport}
+	resp, err := client.Get(server.URL + "/list")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	resp.Body.Close()
+	if _, err := spider.renderHTML(ctx, server.URL+"/list", AdapterRender{}); !errors.Is(err, ErrDomainPaused) {
+		t.Errorf("paused domain: err = %v, want ErrDomainPaused", err)
+	}
+
+	u, _ := url.Parse(server.URL)
+	if _, ok := spider.robots[u.Host]; !ok {
+		t.Error("robots.txt was not cached")
+	}
+}
@@ -4,12 +4,14 @@ package crawler
 import (
 	"crypto/md5"
 	"encoding/hex"
+	"net/http"
 	"net/url"
 	"sync"
 	"time"
 
 	"github.com/gocolly/colly/v2"
 	"github.com/gocolly/colly/v2/extensions"
+	"github.com/temoto/robotstxt"
 	"go.uber.org/zap"
 )
 
@@ -22,6 +24,14 @@ type SpiderConfig struct {
 	ProxyEnabled       bool          `mapstructure:"proxy_enabled"`
 	ProxyURL           string        `mapstructure:"proxy_url"`
 	Timeout            time.Duration `mapstructure:"timeout"`
+
+	// Politeness: per-domain parallelism and delay (DownloadDelay unless a
+	// DomainLimits entry matches), robots.txt and the 5xx circuit breaker
+	DomainParallelism int           `mapstructure:"domain_parallelism"`
+	DomainLimits      []DomainLimit `mapstructure:"domain_limits"`
+	RespectRobotsTxt  bool          `mapstructure:"respect_robots_txt"`
+	BreakerThreshold  int           `mapstructure:"breaker_threshold"` // consecutive failures before pausing, 0 disables
+	BreakerCooldown   time.Duration `mapstructure:"breaker_cooldown"`
 }
 
 // DefaultSpiderConfig returns default spider configuration
@@ -33,6 +43,10 @@ func DefaultSpiderConfig() *SpiderConfig {
 		UserAgent:          "random",
 		ProxyEnabled:       false,
 		Timeout:            30 * time.Second,
+		DomainParallelism:  2,
+		RespectRobotsTxt:   true,
+		BreakerThreshold:   5,
+		BreakerCooldown:    10 * time.Minute,
 	}
 }
 
@@ -43,8 +57,13 @@ type Spider struct {
 	Collector    *colly.Collector
 	Logger       *zap.Logger
 	CrawledURLs  map[string]bool
+	store        URLStore
+	transport    http.RoundTripper // politeness-wrapped transport of the collector
 	mu           sync.RWMutex
 	requestCount int64
+
+	robotsMu sync.Mutex
+	robots   map[string]*robotstxt.RobotsData // robots.txt per host, for headless rendering
 }
 
 // NewSpider creates a new spider instance with the given configuration
@@ -71,16 +90,14 @@ func (s *Spider) initCollector() {
 		colly.Async(true),
 	)
 
-	// Set rate limiting
+	// Global concurrency cap; per-domain limits are applied by the transport
 	s.Collector.Limit(&colly.LimitRule{
 		DomainGlob:  "*",
 		Parallelism: s.Config.ConcurrentRequests,
-		Delay:       s.Config.DownloadDelay,
-		RandomDelay: s.Config.DownloadDelay / 2,
 	})
 
-	// Set retry
 	s.Collector.SetRequestTimeout(s.Config.Timeout)
+	s.Collector.IgnoreRobotsTxt = !s.Config.RespectRobotsTxt
 
 	// Set User-Agent
 	if s.Config.UserAgent == "random" {
@@ -89,21 +106,37 @@ func (s *Spider) initCollector() {
 		s.Collector.UserAgent = s.Config.UserAgent
 	}
 
-	// Set proxy if enabled
+	// Set proxy if enabled. The proxy lives on the base transport because
+	// colly's SetProxy would replace the politeness wrapper
+	base := http.DefaultTransport.(*http.Transport).Clone()
 	if s.Config.ProxyEnabled && s.Config.ProxyURL != "" {
-		s.Collector.SetProxy(s.Config.ProxyURL)
+		if proxyURL, err := url.Parse(s.Config.ProxyURL); err == nil {
+			base.Proxy = http.ProxyURL(proxyURL)
+		} else {
+			s.Logger.Warn("Invalid proxy URL", zap.String("proxy", s.Config.ProxyURL), zap.Error(err))
+		}
 	}
+	s.transport = newPoliteTransport(recordingTransport(base), s.Config, s.Logger)
+	s.Collector.WithTransport(s.transport)
 
 	// Setup callbacks
 	s.setupCallbacks()
 }
 
+// SetTransport replaces the network transport of the collector, e.g. with a
+// ReplayTransport for offline tests; politeness limits still apply
+func (s *Spider) SetTransport(rt http.RoundTripper) {
+	s.transport = newPoliteTransport(rt, s.Config, s.Logger)
+	s.Collector.WithTransport(s.transport)
+}
+
 // setupCallbacks sets up common callbacks for the collector
 func (s *Spider) setupCallbacks() {
 	s.Collector.OnRequest(func(r *colly.Request) {
 		s.mu.Lock()
 		s.requestCount++
 		s.mu.Unlock()
+		s.prepareConditionalRequest(r)
 		s.Logger.Debug("Requesting",
 			zap.String("spider", s.Name),
 			zap.String("url", r.URL.String()),
@@ -117,14 +150,28 @@ func (s *Spider) setupCallbacks() {
 			zap.Int("status", r.StatusCode),
 			zap.Int("body_size", len(r.Body)),
 		)
+		s.recordFetched(r)
 	})
 
 	s.Collector.OnError(func(r *colly.Response, err error) {
+		// colly reports 304 as an error; the page is simply unchanged
+		if r.StatusCode == http.StatusNotModified {
+			s.Logger.Debug("Not modified",
+				zap.String("spider", s.Name),
+				zap.String("url", r.Request.URL.String()),
+			)
+			s.recordFetched(r)
+			return
+		}
+		if s.retryRequest(r, err) {
+			return
+		}
 		s.Logger.Error("Request failed",
 			zap.String("spider", s.Name),
 			zap.String("url", r.Request.URL.String()),
 			zap.Error(err),
 		)
+		s.recordFailed(r, err)
 	})
 }
 
@@ -150,20 +197,33 @@ func (s *Spider) GetRequestCount() int64 {
 	return s.requestCount
 }
 
-// IsURLCrawled checks if a URL has already been crawled
+// IsURLCrawled checks if a URL has already been crawled, in this run or,
+// with a URL store, fetched successfully in an earlier one. URLs that were
+// only discovered or whose fetch failed are crawled again.
 func (s *Spider) IsURLCrawled(url string) bool {
 	hash := HashURL(url)
 	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.CrawledURLs[hash]
+	crawled := s.CrawledURLs[hash]
+	s.mu.RUnlock()
+	if crawled || s.store == nil {
+		return crawled
+	}
+	record := s.loadURL(url)
+	return record != nil && record.Status == URLStatusFetched
 }
 
-// MarkURLCrawled marks a URL as crawled
+// MarkURLCrawled marks a URL as crawled and records it as seen in the URL store
 func (s *Spider) MarkURLCrawled(url string) {
 	hash := HashURL(url)
 	s.mu.Lock()
-	defer s.mu.Unlock()
 	s.CrawledURLs[hash] = true
+	s.mu.Unlock()
+
+	if s.store != nil && s.loadURL(url) == nil {
+		record := &URLRecord{URLHash: hash, URL: url, Status: URLStatusSeen}
+		record.Domain, _ = GetDomain(url)
+		s.saveURL(record)
+	}
 }
 
 // HashURL creates an MD5 hash of a URL
@@ -215,4 +275,14 @@ type CrawlResult struct {
 	RequestCount  int64     `json:"request_count"`
 	Duration      time.Duration `json:"duration"`
 	Errors        []string  `json:"errors,omitempty"`
+	Pages         []AdapterPageReport `json:"pages,omitempty"` // adapter list pages only
+}
+
+// AdapterPageReport summarizes one list page crawled with a site adapter
+type AdapterPageReport struct {
+	Page  int    `json:"page"`
+	URL   string `json:"url"`
+	Items int    `json:"items"`
+	New   int    `json:"new"`
+	Error string `json:"error,omitempty"`
 }
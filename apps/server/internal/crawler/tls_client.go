@@ -13,6 +13,9 @@ import (
 // TLSClientWrapper wraps tls-client for use in the crawler
 type TLSClientWrapper struct {
 	client tls_client.HttpClient
+	replay *stdClient          // serves requests through a net/http transport instead
+	record *RecordingTransport // saves responses when SetRecordDir is active
+	follow bool                // follow redirects while recording, one fixture per hop
 }
 
 // NewTLSClient creates a new TLS client that mimics Chrome browser TLS fingerprint
@@ -25,13 +28,7 @@ func NewTLSClient() (*TLSClientWrapper, error) {
 		// Follow redirects by default
 		tls_client.WithNotFollowRedirects(),
 	}
-
-	client, err := tls_client.NewHttpClient(tls_client.NewNoopLogger(), options...)
-	if err != nil {
-		return nil, err
-	}
-
-	return &TLSClientWrapper{client: client}, nil
+	return newTLSClient(false, options...)
 }
 
 // NewTLSClientWithRedirects creates a TLS client that follows redirects
@@ -43,32 +40,59 @@ func NewTLSClientWithRedirects() (*TLSClientWrapper, error) {
 		tls_client.WithCookieJar(jar),
 		// Follow redirects
 	}
+	return newTLSClient(true, options...)
+}
+
+// newTLSClient builds the wrapper. While recording, the client never follows
+// redirects itself; doRecorded follows them so every hop is saved.
+func newTLSClient(followRedirects bool, options ...tls_client.HttpClientOption) (*TLSClientWrapper, error) {
+	record := activeRecorder()
+	if record != nil && followRedirects {
+		options = append(options, tls_client.WithNotFollowRedirects())
+	}
 
 	client, err := tls_client.NewHttpClient(tls_client.NewNoopLogger(), options...)
 	if err != nil {
 		return nil, err
 	}
 
-	return &TLSClientWrapper{client: client}, nil
+	return &TLSClientWrapper{client: client, record: record, follow: followRedirects}, nil
 }
 
 // Do executes an HTTP request
 func (t *TLSClientWrapper) Do(req *http.Request) (*http.Response, error) {
+	if t.replay != nil {
+		return t.replay.do(req)
+	}
+	if t.record != nil {
+		return t.doRecorded(req)
+	}
 	return t.client.Do(req)
 }
 
 // Get performs a GET request
 func (t *TLSClientWrapper) Get(url string) (*http.Response, error) {
-	return t.client.Get(url)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return t.Do(req)
 }
 
 // SetCookies sets cookies for the given URL
 func (t *TLSClientWrapper) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	if t.replay != nil {
+		t.replay.setCookies(u, cookies)
+		return
+	}
 	t.client.SetCookies(u, cookies)
 }
 
 // GetCookies returns cookies for the given URL
 func (t *TLSClientWrapper) GetCookies(u *url.URL) []*http.Cookie {
+	if t.replay != nil {
+		return t.replay.cookies(u)
+	}
 	return t.client.GetCookies(u)
 }
 
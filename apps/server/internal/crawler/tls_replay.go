@@ -0,0 +1,179 @@
+package crawler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+
+	fhttp "github.com/bogdanfinn/fhttp"
+)
+
+// stdClient runs TLSClientWrapper requests on a net/http transport, which
+// lets recorded fixtures stand in for the fingerprinting client
+type stdClient struct {
+	client *http.Client
+}
+
+// NewReplayTLSClient creates a TLS client wrapper whose requests go through
+// rt instead of the network, typically a ReplayTransport
+func NewReplayTLSClient(rt http.RoundTripper, followRedirects bool) *TLSClientWrapper {
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Transport: rt, Jar: jar}
+	if !followRedirects {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+	return &TLSClientWrapper{replay: &stdClient{client: client}}
+}
+
+// do converts the request to net/http and the response back to fhttp
+func (c *stdClient) do(req *fhttp.Request) (*fhttp.Response, error) {
+	var body io.Reader
+	if req.Body != nil {
+		body = req.Body
+	}
+	stdReq, err := http.NewRequestWithContext(req.Context(), req.Method, req.URL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range req.Header {
+		if k != fhttp.HeaderOrderKey && k != fhttp.PHeaderOrderKey {
+			stdReq.Header[http.CanonicalHeaderKey(k)] = v
+		}
+	}
+
+	resp, err := c.client.Do(stdReq)
+	if err != nil {
+		return nil, err
+	}
+
+	// report the URL after redirects like the real client does
+	finalReq := req.Clone(req.Context())
+	finalReq.URL = resp.Request.URL
+	return &fhttp.Response{
+		Status:        resp.Status,
+		StatusCode:    resp.StatusCode,
+		Proto:         resp.Proto,
+		ProtoMajor:    resp.ProtoMajor,
+		ProtoMinor:    resp.ProtoMinor,
+		Header:        fhttp.Header(resp.Header),
+		Body:          resp.Body,
+		ContentLength: resp.ContentLength,
+		Request:       finalReq,
+	}, nil
+}
+
+func (c *stdClient) setCookies(u *url.URL, cookies []*fhttp.Cookie) {
+	converted := make([]*http.Cookie, 0, len(cookies))
+	for _, ck := range cookies {
+		converted = append(converted, &http.Cookie{Name: ck.Name, Value: ck.Value, Path: ck.Path, Domain: ck.Domain})
+	}
+	c.client.Jar.SetCookies(u, converted)
+}
+
+func (c *stdClient) cookies(u *url.URL) []*fhttp.Cookie {
+	var cookies []*fhttp.Cookie
+	for _, ck := range c.client.Jar.Cookies(u) {
+		cookies = append(cookies, &fhttp.Cookie{Name: ck.Name, Value: ck.Value})
+	}
+	return cookies
+}
+
+// maxRecordedRedirects matches the redirect limit of net/http and tls-client
+const maxRecordedRedirects = 10
+
+// doRecorded performs a request with the TLS client and saves the exchange.
+// Redirects are followed here, one request per hop, so the fixtures hold the
+// original URL with its redirect as well as the target and a replay walks the
+// same chain.
+func (t *TLSClientWrapper) doRecorded(req *fhttp.Request) (*fhttp.Response, error) {
+	for hops := 0; ; hops++ {
+		var reqBody []byte
+		if req.Body != nil {
+			data, err := io.ReadAll(req.Body)
+			req.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			reqBody = data
+			req.Body = io.NopCloser(bytes.NewReader(data))
+		}
+
+		resp, err := t.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		resp.Request = req
+
+		if recErr := t.record.Record(req.Method, req.URL.String(), string(reqBody), resp.StatusCode, resp.Header, body); recErr != nil {
+			return nil, errors.Join(errors.New("recording TLS response failed"), recErr)
+		}
+
+		location := resp.Header.Get("Location")
+		if !t.follow || location == "" || !isRedirectStatus(resp.StatusCode) {
+			return resp, nil
+		}
+		if hops >= maxRecordedRedirects {
+			return nil, fmt.Errorf("stopped after %d redirects", maxRecordedRedirects)
+		}
+		if req, err = redirectRequest(req, resp.StatusCode, location, reqBody); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func isRedirectStatus(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// redirectRequest builds the next hop the way net/http does: 307 and 308 keep
+// the method and body, other redirects turn into a GET without body, and
+// credentials are not sent to another host
+func redirectRequest(req *fhttp.Request, status int, location string, body []byte) (*fhttp.Request, error) {
+	target, err := req.URL.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redirect location %q: %w", location, err)
+	}
+
+	next := req.Clone(req.Context())
+	next.URL = target
+	next.Host = ""
+	keepBody := status == http.StatusTemporaryRedirect || status == http.StatusPermanentRedirect
+	if keepBody && body != nil {
+		next.Body = io.NopCloser(bytes.NewReader(body))
+	} else if !keepBody {
+		if req.Method != http.MethodHead {
+			next.Method = http.MethodGet
+		}
+		next.Body = nil
+		next.GetBody = nil
+		next.ContentLength = 0
+	}
+
+	for k := range next.Header {
+		switch {
+		case !keepBody && (strings.EqualFold(k, "Content-Type") || strings.EqualFold(k, "Content-Length")):
+			delete(next.Header, k)
+		case target.Host != req.URL.Host && (strings.EqualFold(k, "Authorization") || strings.EqualFold(k, "Cookie")):
+			delete(next.Header, k)
+		}
+	}
+	return next, nil
+}
@@ -0,0 +1,118 @@
+package crawler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tls_client "github.com/bogdanfinn/tls-client"
+)
+
+// Recording through the TLS client saves every redirect hop, so a replay
+// follows the same chain, and keeps session cookies out of the fixtures
+func TestTLSRecordThenReplay(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.SetCookie(w, &http.Cookie{Name: "sid", Value: "secret-token", Path: "/"})
+			http.Redirect(w, r, "/new", http.StatusFound)
+		case "/new":
+			if c, err := r.Cookie("sid"); err != nil || c.Value != "secret-token" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<html><title>new</title></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	if err := SetRecordDir(dir); err != nil {
+		t.Fatalf("SetRecordDir: %v", err)
+	}
+	client, err := newTLSClient(true, tls_client.WithCookieJar(tls_client.NewCookieJar()), tls_client.WithInsecureSkipVerify())
+	SetRecordDir("")
+	if err != nil {
+		t.Fatalf("newTLSClient: %v", err)
+	}
+
+	resp, err := client.Get(server.URL + "/old")
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Request.URL.Path != "/new" || string(body) != "<html><title>new</title></html>" {
+		t.Fatalf("recorded %d %s %q", resp.StatusCode, resp.Request.URL, body)
+	}
+
+	manifest, err := os.ReadFile(filepath.Join(dir, fixtureManifest))
+	if err != nil {
+		t.Fatalf("read manifest: %v", err)
+	}
+	if strings.Contains(string(manifest), "secret-token") {
+		t.Error("session cookie value written to fixtures")
+	}
+	var fixtures []*Fixture
+	if err := json.Unmarshal(manifest, &fixtures); err != nil {
+		t.Fatalf("parse manifest: %v", err)
+	}
+	if len(fixtures) != 2 {
+		t.Fatalf("recorded %d fixtures, want one per hop", len(fixtures))
+	}
+	if f := fixtures[0]; f.URL != server.URL+"/old" || f.Status != http.StatusFound || f.Headers["Location"] != "/new" {
+		t.Errorf("first hop = %s %d %v", f.URL, f.Status, f.Headers)
+	}
+	if got := fixtures[0].Headers["Set-Cookie"]; got != "sid=REDACTED; Path=/" {
+		t.Errorf("Set-Cookie = %q", got)
+	}
+	if f := fixtures[1]; f.URL != server.URL+"/new" || f.Status != http.StatusOK {
+		t.Errorf("second hop = %s %d", f.URL, f.Status)
+	}
+
+	rt := NewReplayTransport()
+	if err := rt.LoadFixtureDir(dir); err != nil {
+		t.Fatalf("LoadFixtureDir: %v", err)
+	}
+	replayed, err := NewReplayTLSClient(rt, true).Get(server.URL + "/old")
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	body, _ = io.ReadAll(replayed.Body)
+	replayed.Body.Close()
+	if replayed.Request.URL.Path != "/new" || string(body) != "<html><title>new</title></html>" {
+		t.Errorf("replayed %s %q", replayed.Request.URL, body)
+	}
+
+	// without following, the replay stops at the recorded redirect
+	first, err := NewReplayTLSClient(rt, false).Get(server.URL + "/old")
+	if err != nil {
+		t.Fatalf("replay without redirects: %v", err)
+	}
+	first.Body.Close()
+	if first.StatusCode != http.StatusFound || first.Header.Get("Location") != "/new" {
+		t.Errorf("replayed first hop %d to %q", first.StatusCode, first.Header.Get("Location"))
+	}
+	assertNoMisses(t, rt)
+}
+
+func TestRedactSetCookie(t *testing.T) {
+	cases := map[string]string{
+		"sid=abc123":                               "sid=REDACTED",
+		"JSESSIONID=abc; Path=/; HttpOnly":         "JSESSIONID=REDACTED; Path=/; HttpOnly",
+		"token=a=b=c; Expires=Wed, 21 Oct 2026 07": "token=REDACTED; Expires=Wed, 21 Oct 2026 07",
+	}
+	for in, want := range cases {
+		if got := redactSetCookie(in); got != want {
+			t.Errorf("redactSetCookie(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -27,7 +27,8 @@ type WechatArticleParser struct {
 func NewWechatArticleParser(logger *zap.Logger) *WechatArticleParser {
 	return &WechatArticleParser{
 		client: &http.Client{
-			Timeout: 30 * time.Second,
+			Transport: recordingTransport(nil),
+			Timeout:   30 * time.Second,
 			CheckRedirect: func(req *http.Request, via []*http.Request) error {
 				if len(via) >= 10 {
 					return fmt.Errorf("stopped after 10 redirects")
@@ -40,6 +41,11 @@ func NewWechatArticleParser(logger *zap.Logger) *WechatArticleParser {
 	}
 }
 
+// SetTransport routes the requests of the parser through rt, e.g. a ReplayTransport
+func (p *WechatArticleParser) SetTransport(rt http.RoundTripper) {
+	p.client.Transport = rt
+}
+
 // WechatArticleInfo represents parsed WeChat article information
 type WechatArticleInfo struct {
 	Biz              string `json:"biz"`               // __biz parameter (base64 encoded)
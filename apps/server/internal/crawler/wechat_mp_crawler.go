@@ -28,8 +28,9 @@ func NewWechatMPCrawler(logger *zap.Logger) *WechatMPCrawler {
 	jar, _ := cookiejar.New(nil)
 	return &WechatMPCrawler{
 		client: &http.Client{
-			Timeout: 30 * time.Second,
-			Jar:     jar,
+			Transport: recordingTransport(nil),
+			Timeout:   30 * time.Second,
+			Jar:       jar,
 			CheckRedirect: func(req *http.Request, via []*http.Request) error {
 				if len(via) >= 10 {
 					return fmt.Errorf("stopped after 10 redirects")
@@ -42,6 +43,11 @@ func NewWechatMPCrawler(logger *zap.Logger) *WechatMPCrawler {
 	}
 }
 
+// SetTransport routes the requests of the crawler through rt, e.g. a ReplayTransport
+func (c *WechatMPCrawler) SetTransport(rt http.RoundTripper) {
+	c.client.Transport = rt
+}
+
 // QRCodeInfo represents the QR code response for login
 type QRCodeInfo struct {
 	QRCodeURL string `json:"qrcode_url"` // Base64 encoded QR code image or URL
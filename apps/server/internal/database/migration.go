@@ -29,17 +29,39 @@ func AutoMigrate(db *gorm.DB) error {
 		// Position related tables (no dependencies)
 		&model.Position{},
 		&model.PositionHistory{},
+		&model.PositionColumnProfile{},
 		&model.PositionRegistrationData{},
 		&model.Announcement{},
 		&model.ListPage{},
 
 		// Junction tables (depend on Position and Announcement)
 		&model.PositionAnnouncement{},
+		&model.RecruitmentCampaign{},
+
+		// Attachment archive (附件归档，按内容 SHA-256 去重)
+		&model.Attachment{},
+		&model.AttachmentSource{},
+
+		// Search index sync outbox (搜索索引同步)
+		&model.SearchOutbox{},
+		&model.LearningSearchDoc{},
+		&model.LearningSearchSyncState{},
+		&model.ContentEmbedding{},
+		&model.QuestionSignature{},
+		&model.QuestionLSHBand{},
+		&model.QuestionDuplicate{},
+		&model.PaperImport{},
+		&model.QuestionIRTParam{},
+		&model.UserAbility{},
+		&model.KnowledgeTracingParam{},
+		&model.ReviewLog{},
+		&model.SRSUserParams{},
 
 		// User behavior tables (depend on User and Position)
 		&model.UserFavorite{},
 		&model.UserFavoriteFolder{},
 		&model.UserSubscription{},
+		&model.UserFeedToken{},
 		&model.UserView{},
 		&model.UserNotification{},
 		&model.ExamCalendar{},
@@ -47,6 +69,7 @@ func AutoMigrate(db *gorm.DB) error {
 		// Crawler related tables
 		&model.CrawlTask{},
 		&model.CrawlLog{},
+		&model.CrawlURL{},
 
 		// Fenbi related tables
 		&model.FenbiCredential{},
@@ -200,21 +223,36 @@ func CreateIndexes(db *gorm.DB) error {
 func CreateFullTextIndexes(db *gorm.DB) error {
 	// Create FULLTEXT indexes for MySQL full-text search
 	// Note: These indexes support Chinese text search when using ngram parser
-	// Note: These might already exist in the schema, errors are ignored
+	// Note: Existing indexes are skipped, so this is safe to run on every startup
 
-	// Position full-text search index
-	db.Exec("ALTER TABLE what_positions ADD FULLTEXT INDEX ft_position_name (position_name) WITH PARSER ngram")
-	db.Exec("ALTER TABLE what_positions ADD FULLTEXT INDEX ft_department_name (department_name) WITH PARSER ngram")
-	db.Exec("ALTER TABLE what_positions ADD FULLTEXT INDEX ft_position_search (position_name, department_name) WITH PARSER ngram")
+	// Position full-text search index (ft_position_search is used by the MySQL search backend)
+	addFullTextIndex(db, "what_positions", "ft_position_name", "position_name")
+	addFullTextIndex(db, "what_positions", "ft_department_name", "department_name")
+	addFullTextIndex(db, "what_positions", "ft_position_search", "position_name, department_name")
 
 	// Announcement full-text search index
-	db.Exec("ALTER TABLE what_announcements ADD FULLTEXT INDEX ft_announcement_title (title) WITH PARSER ngram")
-	db.Exec("ALTER TABLE what_announcements ADD FULLTEXT INDEX ft_announcement_content (content) WITH PARSER ngram")
-	db.Exec("ALTER TABLE what_announcements ADD FULLTEXT INDEX ft_announcement_search (title, content) WITH PARSER ngram")
+	addFullTextIndex(db, "what_announcements", "ft_announcement_title", "title")
+	addFullTextIndex(db, "what_announcements", "ft_announcement_content", "content")
+	addFullTextIndex(db, "what_announcements", "ft_announcement_search", "title, content")
+
+	// Learning search full-text index (title-only index is needed for title boosting)
+	addFullTextIndex(db, "what_learning_search_docs", "ft_learning_search_title", "title")
+	addFullTextIndex(db, "what_learning_search_docs", "ft_learning_search", "title, content")
 
 	return nil
 }
 
+// addFullTextIndex adds an ngram FULLTEXT index unless one with the same name already exists
+func addFullTextIndex(db *gorm.DB, table, name, columns string) {
+	var count int64
+	db.Raw("SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = ? AND index_name = ?",
+		table, name).Scan(&count)
+	if count > 0 {
+		return
+	}
+	db.Exec("ALTER TABLE " + table + " ADD FULLTEXT INDEX " + name + " (" + columns + ") WITH PARSER ngram")
+}
+
 // SeedFenbiCategories inserts initial Fenbi category data if the table is empty
 func SeedFenbiCategories(db *gorm.DB) error {
 	var count int64
@@ -0,0 +1,207 @@
+package export
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// AnswerMode controls where answers and analysis are printed
+type AnswerMode string
+
+const (
+	AnswerInline   AnswerMode = "inline"   // after each question
+	AnswerAppendix AnswerMode = "appendix" // collected at the end of the document
+	AnswerHidden   AnswerMode = "hidden"   // not printed at all
+)
+
+// ParseAnswerMode normalizes a user supplied answer mode, defaulting to appendix
+func ParseAnswerMode(s string) AnswerMode {
+	switch AnswerMode(strings.ToLower(strings.TrimSpace(s))) {
+	case AnswerInline:
+		return AnswerInline
+	case AnswerHidden, "none":
+		return AnswerHidden
+	default:
+		return AnswerAppendix
+	}
+}
+
+// Document is the layout-independent content of a printable paper or wrong-question set
+type Document struct {
+	Title       string
+	Subtitle    string
+	Meta        []string // short facts printed under the title, e.g. 限时 120 分钟
+	Watermark   string   // empty disables the watermark
+	AnswerMode  AnswerMode
+	AnswerSheet bool // append a separate answer sheet
+	Sections    []Section
+}
+
+// Section is a titled part of the document such as 常识判断
+type Section struct {
+	Name   string
+	Groups []Group
+}
+
+// Group is a run of questions that share an optional material printed before them
+type Group struct {
+	Material  *Material
+	Questions []Question
+}
+
+// Material is a shared passage, table or chart description
+type Material struct {
+	Title   string
+	Content string
+}
+
+// Option is a choice option
+type Option struct {
+	Key     string
+	Content string
+}
+
+// Note is an extra labelled line printed with a question, e.g. 错误原因 in a wrong-question set
+type Note struct {
+	Label string
+	Value string
+}
+
+// Question is a single numbered question
+type Question struct {
+	Number   int
+	Content  string
+	Options  []Option
+	Multi    bool    // multiple answers allowed, drawn as squares on the answer sheet
+	Score    float64 // 0 omits the score
+	Answer   string
+	Analysis string
+	Notes    []Note
+}
+
+// Choice reports whether the question is answered by filling options
+func (q Question) Choice() bool {
+	return len(q.Options) > 0
+}
+
+// Questions returns all questions in document order
+func (d *Document) Questions() []Question {
+	var out []Question
+	for _, s := range d.Sections {
+		for _, g := range s.Groups {
+			out = append(out, g.Questions...)
+		}
+	}
+	return out
+}
+
+// Font is a CJK font embedded into rendered documents
+type Font struct {
+	Family string
+	Format string // truetype or opentype
+	Data   []byte
+}
+
+// LoadFont reads a font file; family defaults to the file name without extension
+func LoadFont(path, family string) (*Font, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	if family == "" {
+		family = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	font := &Font{Family: family, Data: data}
+	switch ext {
+	case ".ttf":
+		font.Format = "truetype"
+	case ".otf":
+		font.Format = "opentype"
+	default:
+		return nil, fmt.Errorf("unsupported font file %s, use .ttf or .otf", ext)
+	}
+	return font, nil
+}
+
+// fallbackFamilies are tried after the embedded font
+const fallbackFamilies = `"Noto Serif CJK SC", "Source Han Serif SC", "SimSun", "Songti SC", serif`
+
+var (
+	blockTagPattern = regexp.MustCompile(`(?i)<\s*(br|/p|/div|/li|/tr|/h[1-6])\b[^>]*>`)
+	imgTagPattern   = regexp.MustCompile(`(?i)<\s*img\b[^>]*>`)
+	anyTagPattern   = regexp.MustCompile(`<[^>]*>`)
+	spaceRunPattern = regexp.MustCompile(`[ \t\f\v\x{00a0}\x{3000}]+`)
+)
+
+// Paragraphs converts stored rich text into plain paragraphs.
+// Block tags become line breaks, images become a [图] placeholder and other tags are dropped.
+func Paragraphs(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = blockTagPattern.ReplaceAllString(s, "\n")
+	s = imgTagPattern.ReplaceAllString(s, "[图]")
+	s = anyTagPattern.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+
+	var out []string
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(spaceRunPattern.ReplaceAllString(line, " "))
+		if line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// optionColumns picks how many options share a line: four short options fit one row,
+// medium ones go two per row and long ones get a row each
+func optionColumns(options []Option) int {
+	maxWidth := 0
+	for _, opt := range options {
+		w := displayWidth(strings.Join(Paragraphs(opt.Content), " ")) + displayWidth(opt.Key) + 2
+		if w > maxWidth {
+			maxWidth = w
+		}
+	}
+	switch {
+	case maxWidth <= 18 && len(options) <= 4:
+		return len(options)
+	case maxWidth <= 40:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// displayWidth counts wide (CJK) runes as two columns
+func displayWidth(s string) int {
+	w := 0
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			w++
+			continue
+		}
+		if unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) ||
+			unicode.Is(unicode.Hangul, r) || (r >= 0xFF00 && r <= 0xFFEF) || (r >= 0x3000 && r <= 0x303F) {
+			w += 2
+		} else {
+			w++
+		}
+	}
+	return w
+}
+
+// formatScore prints 2 as "2" and 0.5 as "0.5"
+func formatScore(score float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.1f", score), "0"), ".")
+}
@@ -0,0 +1,547 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// Page geometry in twips (1/20 pt): A4 with 18/16/20 mm margins
+const (
+	docxPageWidth    = 11906
+	docxPageHeight   = 16838
+	docxMarginTop    = 1020
+	docxMarginSide   = 907
+	docxMarginBottom = 1134
+	docxTextWidth    = docxPageWidth - 2*docxMarginSide
+	docxOptionIndent = 315  // options are indented 1.5 characters
+	docxWriteBoxRow  = 2835 // 50 mm
+)
+
+// docxDefaultFamily is referenced when no font is embedded
+const docxDefaultFamily = "SimSun"
+
+const (
+	nsW = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+	nsR = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+)
+
+// RenderDOCX renders the document as a Word file.
+// A TrueType font is embedded obfuscated as ECMA-376 requires; Word cannot embed
+// CFF-based OpenType fonts, so those are referenced by family name only.
+func RenderDOCX(doc *Document, font *Font) ([]byte, error) {
+	w := &docxWriter{family: docxDefaultFamily}
+	if font != nil {
+		w.family = font.Family
+	}
+	embed := font != nil && font.Format == "truetype"
+
+	w.title(doc)
+	for _, section := range doc.Sections {
+		if section.Name != "" {
+			w.heading(section.Name, false)
+		}
+		for _, group := range section.Groups {
+			if group.Material != nil {
+				w.material(group.Material)
+			}
+			for _, q := range group.Questions {
+				w.question(q, doc.AnswerMode == AnswerInline)
+			}
+		}
+	}
+	if doc.AnswerSheet {
+		w.answerSheet(doc)
+	}
+	if doc.AnswerMode == AnswerAppendix {
+		w.appendix(doc)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	parts := []docxPart{
+		{"[Content_Types].xml", []byte(docxContentTypes(doc.Watermark != ""))},
+		{"_rels/.rels", []byte(docxRootRels)},
+		{"docProps/core.xml", []byte(docxCore(doc.Title))},
+		{"word/_rels/document.xml.rels", []byte(docxDocumentRels(doc.Watermark != ""))},
+		{"word/document.xml", []byte(w.document(doc.Watermark != ""))},
+		{"word/styles.xml", []byte(docxStyles(w.family))},
+		{"word/settings.xml", []byte(docxSettings(embed))},
+		{"word/footer1.xml", []byte(docxFooter)},
+	}
+	if doc.Watermark != "" {
+		parts = append(parts, docxPart{"word/header1.xml", []byte(docxWatermarkHeader(doc.Watermark, w.family))})
+	}
+
+	if embed {
+		key, err := newFontKey()
+		if err != nil {
+			return nil, err
+		}
+		data, err := obfuscateFont(font.Data, key)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts,
+			docxPart{"word/fontTable.xml", []byte(docxFontTable(w.family, key))},
+			docxPart{"word/_rels/fontTable.xml.rels", []byte(docxFontTableRels)},
+			docxPart{"word/fonts/font1.odttf", data},
+		)
+	} else {
+		parts = append(parts, docxPart{"word/fontTable.xml", []byte(docxFontTable(w.family, ""))})
+	}
+
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Write(part.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// docxPart is one file inside the OOXML package
+type docxPart struct {
+	name string
+	data []byte
+}
+
+// newFontKey generates the GUID used to obfuscate an embedded font
+func newFontKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	h := strings.ToUpper(hex.EncodeToString(b))
+	return fmt.Sprintf("{%s-%s-%s-%s-%s}", h[0:8], h[8:12], h[12:16], h[16:20], h[20:32]), nil
+}
+
+// obfuscateFont XORs the first 32 bytes of the font with the GUID key read in reverse byte order (ECMA-376 Part 1, 17.8.1)
+func obfuscateFont(data []byte, key string) ([]byte, error) {
+	raw, err := hex.DecodeString(strings.NewReplacer("{", "", "}", "", "-", "").Replace(key))
+	if err != nil || len(raw) != 16 {
+		return nil, fmt.Errorf("invalid font key %s", key)
+	}
+	if len(data) < 32 {
+		return nil, fmt.Errorf("font data too short")
+	}
+	out := make([]byte, len(data))
+	copy(out, data)
+	for i := 0; i < 32; i++ {
+		out[i] ^= raw[15-i%16]
+	}
+	return out, nil
+}
+
+// docxWriter accumulates the body of word/document.xml
+type docxWriter struct {
+	family string
+	body   strings.Builder
+}
+
+// runStyle is the subset of character formatting used by the renderer
+type runStyle struct {
+	bold  bool
+	size  int // half-points, 0 keeps the default
+	color string
+}
+
+func xmlText(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func docxRun(text string, st runStyle) string {
+	var props strings.Builder
+	if st.bold {
+		props.WriteString("<w:b/>")
+	}
+	if st.color != "" {
+		fmt.Fprintf(&props, `<w:color w:val="%s"/>`, st.color)
+	}
+	if st.size > 0 {
+		fmt.Fprintf(&props, `<w:sz w:val="%d"/><w:szCs w:val="%d"/>`, st.size, st.size)
+	}
+	rPr := ""
+	if props.Len() > 0 {
+		rPr = "<w:rPr>" + props.String() + "</w:rPr>"
+	}
+	return fmt.Sprintf(`<w:r>%s<w:t xml:space="preserve">%s</w:t></w:r>`, rPr, xmlText(text))
+}
+
+func docxParagraph(pPr string, runs ...string) string {
+	if pPr != "" {
+		pPr = "<w:pPr>" + pPr + "</w:pPr>"
+	}
+	return "<w:p>" + pPr + strings.Join(runs, "") + "</w:p>"
+}
+
+// docxSideBar is a left border plus light shading used for answer and note blocks
+func docxSideBar(color, fill string) string {
+	return fmt.Sprintf(`<w:pBdr><w:left w:val="single" w:sz="18" w:space="4" w:color="%s"/></w:pBdr>`+
+		`<w:shd w:val="clear" w:color="auto" w:fill="%s"/><w:ind w:left="120"/>`, color, fill)
+}
+
+func (w *docxWriter) title(doc *Document) {
+	w.body.WriteString(docxParagraph(`<w:spacing w:after="80"/><w:jc w:val="center"/>`,
+		docxRun(doc.Title, runStyle{bold: true, size: 36})))
+	if doc.Subtitle != "" {
+		w.body.WriteString(docxParagraph(`<w:jc w:val="center"/>`, docxRun(doc.Subtitle, runStyle{color: "444444"})))
+	}
+	if len(doc.Meta) > 0 {
+		w.body.WriteString(docxParagraph(`<w:spacing w:after="120"/><w:jc w:val="center"/>`,
+			docxRun(strings.Join(doc.Meta, "    |    "), runStyle{color: "444444"})))
+	}
+}
+
+func (w *docxWriter) heading(text string, pageBreak bool) {
+	pPr := `<w:keepNext/>`
+	if pageBreak {
+		pPr += "<w:pageBreakBefore/>"
+	}
+	pPr += `<w:pBdr><w:bottom w:val="single" w:sz="6" w:space="1" w:color="333333"/></w:pBdr>` +
+		`<w:spacing w:before="280" w:after="120"/>`
+	w.body.WriteString(docxParagraph(pPr, docxRun(text, runStyle{bold: true, size: 26})))
+}
+
+func (w *docxWriter) material(m *Material) {
+	box := `<w:pBdr>` +
+		`<w:top w:val="single" w:sz="4" w:space="4" w:color="999999"/>` +
+		`<w:left w:val="single" w:sz="4" w:space="4" w:color="999999"/>` +
+		`<w:bottom w:val="single" w:sz="4" w:space="4" w:color="999999"/>` +
+		`<w:right w:val="single" w:sz="4" w:space="4" w:color="999999"/>` +
+		`</w:pBdr><w:shd w:val="clear" w:color="auto" w:fill="F7F7F7"/>`
+	if m.Title != "" {
+		w.body.WriteString(docxParagraph(box+`<w:spacing w:before="160"/><w:ind w:left="120" w:right="120"/>`,
+			docxRun(m.Title, runStyle{bold: true})))
+	}
+	for _, p := range Paragraphs(m.Content) {
+		w.body.WriteString(docxParagraph(box+`<w:ind w:left="120" w:right="120" w:firstLine="420"/>`,
+			docxRun(p, runStyle{})))
+	}
+}
+
+func (w *docxWriter) question(q Question, inlineAnswer bool) {
+	paras := Paragraphs(q.Content)
+	if len(paras) == 0 {
+		paras = []string{""}
+	}
+	runs := []string{docxRun(fmt.Sprintf("%d. ", q.Number), runStyle{bold: true}), docxRun(paras[0], runStyle{})}
+	if q.Score > 0 {
+		runs = append(runs, docxRun(fmt.Sprintf("（%s分）", formatScore(q.Score)), runStyle{size: 18, color: "666666"}))
+	}
+	keep := ""
+	if q.Choice() {
+		keep = "<w:keepNext/>"
+	}
+	w.body.WriteString(docxParagraph(keep+`<w:spacing w:before="120"/>`, runs...))
+	for _, p := range paras[1:] {
+		w.body.WriteString(docxParagraph(keep, docxRun(p, runStyle{})))
+	}
+
+	if q.Choice() {
+		w.options(q.Options)
+	}
+
+	for _, n := range q.Notes {
+		w.body.WriteString(docxParagraph(docxSideBar("C05621", "FBF0EA"),
+			docxRun(n.Label+"：", runStyle{bold: true}),
+			docxRun(strings.Join(Paragraphs(n.Value), " "), runStyle{})))
+	}
+
+	if inlineAnswer {
+		w.answer(q)
+	}
+}
+
+// options lays choice options out in a borderless table so they line up in columns
+func (w *docxWriter) options(options []Option) {
+	cols := optionColumns(options)
+	width := (docxTextWidth - docxOptionIndent) / cols
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<w:tbl><w:tblPr><w:tblW w:w="%d" w:type="dxa"/><w:tblInd w:w="%d" w:type="dxa"/>`,
+		width*cols, docxOptionIndent)
+	b.WriteString(`<w:tblBorders><w:top w:val="nil"/><w:left w:val="nil"/><w:bottom w:val="nil"/>` +
+		`<w:right w:val="nil"/><w:insideH w:val="nil"/><w:insideV w:val="nil"/></w:tblBorders>` +
+		`<w:tblLayout w:type="fixed"/><w:tblCellMar><w:left w:w="0" w:type="dxa"/><w:right w:w="113" w:type="dxa"/></w:tblCellMar>` +
+		`<w:tblLook w:val="0000"/></w:tblPr><w:tblGrid>`)
+	for i := 0; i < cols; i++ {
+		fmt.Fprintf(&b, `<w:gridCol w:w="%d"/>`, width)
+	}
+	b.WriteString("</w:tblGrid>")
+
+	for start := 0; start < len(options); start += cols {
+		b.WriteString(`<w:tr><w:trPr><w:cantSplit/></w:trPr>`)
+		for i := start; i < start+cols; i++ {
+			fmt.Fprintf(&b, `<w:tc><w:tcPr><w:tcW w:w="%d" w:type="dxa"/></w:tcPr>`, width)
+			if i < len(options) {
+				b.WriteString(docxParagraph("", docxRun(options[i].Key+". "+
+					strings.Join(Paragraphs(options[i].Content), " "), runStyle{})))
+			} else {
+				b.WriteString("<w:p/>")
+			}
+			b.WriteString("</w:tc>")
+		}
+		b.WriteString("</w:tr>")
+	}
+	b.WriteString("</w:tbl>")
+	w.body.WriteString(b.String())
+}
+
+func (w *docxWriter) answer(q Question) {
+	bar := docxSideBar("2B6CB0", "EDF3FA")
+	if q.Answer != "" {
+		w.body.WriteString(docxParagraph(bar, docxRun("【答案】", runStyle{bold: true}),
+			docxRun(strings.Join(Paragraphs(q.Answer), " "), runStyle{})))
+	}
+	for i, p := range Paragraphs(q.Analysis) {
+		runs := []string{docxRun(p, runStyle{})}
+		if i == 0 {
+			runs = append([]string{docxRun("【解析】", runStyle{bold: true})}, runs...)
+		}
+		w.body.WriteString(docxParagraph(bar, runs...))
+	}
+}
+
+// grid writes cells as a bordered table with a fixed column count
+func (w *docxWriter) grid(cells []string, cols int, border string) {
+	if len(cells) == 0 {
+		return
+	}
+	width := docxTextWidth / cols
+	var b strings.Builder
+	fmt.Fprintf(&b, `<w:tbl><w:tblPr><w:tblW w:w="%d" w:type="dxa"/><w:tblBorders>`, width*cols)
+	for _, side := range []string{"top", "left", "bottom", "right", "insideH", "insideV"} {
+		fmt.Fprintf(&b, `<w:%s w:val="single" w:sz="4" w:space="0" w:color="%s"/>`, side, border)
+	}
+	b.WriteString(`</w:tblBorders><w:tblLayout w:type="fixed"/><w:tblLook w:val="0000"/></w:tblPr><w:tblGrid>`)
+	for i := 0; i < cols; i++ {
+		fmt.Fprintf(&b, `<w:gridCol w:w="%d"/>`, width)
+	}
+	b.WriteString("</w:tblGrid>")
+	for start := 0; start < len(cells); start += cols {
+		b.WriteString("<w:tr>")
+		for i := start; i < start+cols; i++ {
+			fmt.Fprintf(&b, `<w:tc><w:tcPr><w:tcW w:w="%d" w:type="dxa"/></w:tcPr>`, width)
+			if i < len(cells) {
+				b.WriteString(cells[i])
+			} else {
+				b.WriteString("<w:p/>")
+			}
+			b.WriteString("</w:tc>")
+		}
+		b.WriteString("</w:tr>")
+	}
+	b.WriteString("</w:tbl>")
+	w.body.WriteString(b.String())
+	// Keep the next table from merging into this one
+	w.body.WriteString(docxParagraph(`<w:spacing w:after="0" w:line="120" w:lineRule="exact"/>`))
+}
+
+func (w *docxWriter) answerSheet(doc *Document) {
+	w.heading("答题卡", true)
+	w.body.WriteString(docxParagraph(`<w:spacing w:after="160"/>`,
+		docxRun("姓名：________________        准考证号：________________", runStyle{})))
+
+	var cells []string
+	var written []Question
+	for _, q := range doc.Questions() {
+		if !q.Choice() {
+			written = append(written, q)
+			continue
+		}
+		open, closing := "(", ")"
+		if q.Multi {
+			open, closing = "[", "]"
+		}
+		marks := make([]string, 0, len(q.Options))
+		for _, opt := range q.Options {
+			marks = append(marks, open+opt.Key+closing)
+		}
+		cells = append(cells, docxParagraph(`<w:spacing w:before="40" w:after="40"/>`,
+			docxRun(fmt.Sprintf("%d  ", q.Number), runStyle{bold: true}),
+			docxRun(strings.Join(marks, " "), runStyle{size: 18})))
+	}
+	w.grid(cells, 4, "BBBBBB")
+
+	for _, q := range written {
+		w.body.WriteString(fmt.Sprintf(`<w:tbl><w:tblPr><w:tblW w:w="%d" w:type="dxa"/><w:tblBorders>`+
+			`<w:top w:val="single" w:sz="6" w:space="0" w:color="333333"/><w:left w:val="single" w:sz="6" w:space="0" w:color="333333"/>`+
+			`<w:bottom w:val="single" w:sz="6" w:space="0" w:color="333333"/><w:right w:val="single" w:sz="6" w:space="0" w:color="333333"/>`+
+			`</w:tblBorders><w:tblLook w:val="0000"/></w:tblPr><w:tblGrid><w:gridCol w:w="%d"/></w:tblGrid>`+
+			`<w:tr><w:trPr><w:cantSplit/><w:trHeight w:val="%d" w:hRule="atLeast"/></w:trPr>`+
+			`<w:tc><w:tcPr><w:tcW w:w="%d" w:type="dxa"/></w:tcPr>%s</w:tc></w:tr></w:tbl>`,
+			docxTextWidth, docxTextWidth, docxWriteBoxRow, docxTextWidth,
+			docxParagraph("", docxRun(fmt.Sprintf("第 %d 题", q.Number), runStyle{}))))
+		w.body.WriteString(docxParagraph(`<w:spacing w:after="120"/>`))
+	}
+}
+
+func (w *docxWriter) appendix(doc *Document) {
+	w.heading("参考答案与解析", true)
+
+	questions := doc.Questions()
+	var cells []string
+	for _, q := range questions {
+		if q.Choice() && q.Answer != "" {
+			cells = append(cells, docxParagraph(`<w:jc w:val="center"/>`,
+				docxRun(fmt.Sprintf("%d. %s", q.Number, q.Answer), runStyle{})))
+		}
+	}
+	w.grid(cells, 10, "DDDDDD")
+
+	for _, q := range questions {
+		if q.Answer == "" && q.Analysis == "" {
+			continue
+		}
+		w.body.WriteString(docxParagraph(`<w:keepNext/><w:spacing w:before="120"/>`,
+			docxRun(fmt.Sprintf("%d.", q.Number), runStyle{bold: true})))
+		w.answer(q)
+	}
+}
+
+func (w *docxWriter) document(withHeader bool) string {
+	header := ""
+	if withHeader {
+		header = `<w:headerReference w:type="default" r:id="rId5"/>`
+	}
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+
+		`<w:document xmlns:w="%s" xmlns:r="%s"><w:body>%s`+
+		`<w:sectPr>%s<w:footerReference w:type="default" r:id="rId4"/>`+
+		`<w:pgSz w:w="%d" w:h="%d"/>`+
+		`<w:pgMar w:top="%d" w:right="%d" w:bottom="%d" w:left="%d" w:header="567" w:footer="567" w:gutter="0"/>`+
+		`</w:sectPr></w:body></w:document>`,
+		nsW, nsR, w.body.String(), header, docxPageWidth, docxPageHeight,
+		docxMarginTop, docxMarginSide, docxMarginBottom, docxMarginSide)
+}
+
+func docxContentTypes(withHeader bool) string {
+	header := ""
+	if withHeader {
+		header = `<Override PartName="/word/header1.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.header+xml"/>`
+	}
+	return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Default Extension="odttf" ContentType="application/vnd.openxmlformats-officedocument.obfuscatedFont"/>` +
+		`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+		`<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>` +
+		`<Override PartName="/word/settings.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.settings+xml"/>` +
+		`<Override PartName="/word/fontTable.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.fontTable+xml"/>` +
+		`<Override PartName="/word/footer1.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.footer+xml"/>` +
+		header +
+		`<Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>` +
+		`</Types>`
+}
+
+const docxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+	`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties" Target="docProps/core.xml"/>` +
+	`</Relationships>`
+
+func docxDocumentRels(withHeader bool) string {
+	header := ""
+	if withHeader {
+		header = `<Relationship Id="rId5" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/header" Target="header1.xml"/>`
+	}
+	return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/settings" Target="settings.xml"/>` +
+		`<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/fontTable" Target="fontTable.xml"/>` +
+		`<Relationship Id="rId4" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/footer" Target="footer1.xml"/>` +
+		header +
+		`</Relationships>`
+}
+
+const docxFontTableRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+	`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/font" Target="fonts/font1.odttf"/>` +
+	`</Relationships>`
+
+func docxCore(title string) string {
+	return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" ` +
+		`xmlns:dc="http://purl.org/dc/elements/1.1/">` +
+		`<dc:title>` + xmlText(title) + `</dc:title></cp:coreProperties>`
+}
+
+func docxStyles(family string) string {
+	f := xmlText(family)
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+
+		`<w:styles xmlns:w="%s"><w:docDefaults><w:rPrDefault><w:rPr>`+
+		`<w:rFonts w:ascii="%s" w:hAnsi="%s" w:eastAsia="%s" w:cs="%s"/>`+
+		`<w:sz w:val="21"/><w:szCs w:val="21"/><w:lang w:val="en-US" w:eastAsia="zh-CN"/>`+
+		`</w:rPr></w:rPrDefault><w:pPrDefault><w:pPr><w:spacing w:after="0" w:line="360" w:lineRule="auto"/>`+
+		`<w:jc w:val="both"/></w:pPr></w:pPrDefault></w:docDefaults>`+
+		`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/><w:qFormat/></w:style>`+
+		`<w:style w:type="table" w:default="1" w:styleId="TableNormal"><w:name w:val="Normal Table"/>`+
+		`<w:tblPr><w:tblCellMar><w:left w:w="108" w:type="dxa"/><w:right w:w="108" w:type="dxa"/></w:tblCellMar></w:tblPr></w:style>`+
+		`</w:styles>`, nsW, f, f, f, f)
+}
+
+func docxSettings(embedFonts bool) string {
+	embed := ""
+	if embedFonts {
+		embed = "<w:embedTrueTypeFonts/>"
+	}
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+
+		`<w:settings xmlns:w="%s">%s<w:defaultTabStop w:val="420"/>`+
+		`<w:compat><w:compatSetting w:name="compatibilityMode" w:uri="http://schemas.microsoft.com/office/word" w:val="15"/></w:compat>`+
+		`</w:settings>`, nsW, embed)
+}
+
+func docxFontTable(family, key string) string {
+	embed := ""
+	if key != "" {
+		embed = fmt.Sprintf(`<w:embedRegular r:id="rId1" w:fontKey="%s"/>`, key)
+	}
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+
+		`<w:fonts xmlns:w="%s" xmlns:r="%s"><w:font w:name="%s"><w:charset w:val="86"/>`+
+		`<w:family w:val="roman"/><w:pitch w:val="variable"/>%s</w:font></w:fonts>`,
+		nsW, nsR, xmlText(family), embed)
+}
+
+var docxFooter = fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+
+	`<w:ftr xmlns:w="%s"><w:p><w:pPr><w:jc w:val="center"/></w:pPr>`+
+	`<w:r><w:rPr><w:sz w:val="16"/></w:rPr><w:fldChar w:fldCharType="begin"/></w:r>`+
+	`<w:r><w:rPr><w:sz w:val="16"/></w:rPr><w:instrText xml:space="preserve"> PAGE </w:instrText></w:r>`+
+	`<w:r><w:rPr><w:sz w:val="16"/></w:rPr><w:fldChar w:fldCharType="separate"/></w:r>`+
+	`<w:r><w:rPr><w:sz w:val="16"/></w:rPr><w:t>1</w:t></w:r>`+
+	`<w:r><w:rPr><w:sz w:val="16"/></w:rPr><w:fldChar w:fldCharType="end"/></w:r>`+
+	`</w:p></w:ftr>`, nsW)
+
+// docxWatermarkHeader places a rotated VML text watermark behind every page, the same shape Word's own watermark dialog creates
+func docxWatermarkHeader(text, family string) string {
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+
+		`<w:hdr xmlns:w="%s" xmlns:r="%s" xmlns:v="urn:schemas-microsoft-com:vml" xmlns:o="urn:schemas-microsoft-com:office:office">`+
+		`<w:p><w:r><w:pict>`+
+		`<v:shapetype id="_x0000_t136" coordsize="21600,21600" o:spt="136" adj="10800" path="m@7,l@8,m@5,21600l@6,21600e">`+
+		`<v:formulas><v:f eqn="sum #0 0 10800"/><v:f eqn="prod #0 2 1"/><v:f eqn="sum 21600 0 @1"/><v:f eqn="sum 0 0 @2"/>`+
+		`<v:f eqn="sum 21600 0 @3"/><v:f eqn="if @0 @3 0"/><v:f eqn="if @0 21600 @1"/><v:f eqn="if @0 0 @2"/>`+
+		`<v:f eqn="if @0 @4 21600"/><v:f eqn="mid @5 @6"/><v:f eqn="mid @8 @5"/><v:f eqn="mid @7 @8"/>`+
+		`<v:f eqn="mid @6 @7"/><v:f eqn="sum @6 0 @5"/></v:formulas>`+
+		`<v:path textpathok="t" o:connecttype="custom" o:connectlocs="@9,0;@10,10800;@11,21600;@12,10800" o:connectangles="270,180,90,0"/>`+
+		`<v:textpath on="t" fitshape="t"/><o:lock v:ext="edit" text="t" shapetype="t"/></v:shapetype>`+
+		`<v:shape id="PowerPlusWaterMarkObject" o:spid="_x0000_s2049" type="#_x0000_t136" `+
+		`style="position:absolute;margin-left:0;margin-top:0;width:420pt;height:60pt;rotation:315;z-index:-251657216;`+
+		`mso-position-horizontal:center;mso-position-horizontal-relative:margin;mso-position-vertical:center;mso-position-vertical-relative:margin" `+
+		`o:allowincell="f" fillcolor="silver" stroked="f"><v:fill opacity=".35"/>`+
+		`<v:textpath style="font-family:&quot;%s&quot;;font-size:1pt" string="%s"/></v:shape>`+
+		`</w:pict></w:r></w:p></w:hdr>`,
+		nsW, nsR, xmlText(family), xmlText(text))
+}
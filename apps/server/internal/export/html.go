@@ -0,0 +1,243 @@
+package export
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// embeddedFamily is the @font-face name used for the embedded CJK font
+const embeddedFamily = "PrintCJK"
+
+// watermarkTiles is how many copies of the watermark text cover a page
+const watermarkTiles = 12
+
+// RenderHTML renders the document as a self-contained print HTML page.
+// The font, when given, is inlined as a data URL so the page renders the same without network access.
+func RenderHTML(doc *Document, font *Font) string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html lang=\"zh-CN\"><head><meta charset=\"utf-8\">")
+	fmt.Fprintf(&b, "<title>%s</title><style>", html.EscapeString(doc.Title))
+	writeCSS(&b, font)
+	b.WriteString("</style></head><body>")
+
+	if doc.Watermark != "" {
+		b.WriteString(`<div class="watermark" aria-hidden="true">`)
+		text := html.EscapeString(doc.Watermark)
+		for i := 0; i < watermarkTiles; i++ {
+			fmt.Fprintf(&b, "<span>%s</span>", text)
+		}
+		b.WriteString("</div>")
+	}
+
+	fmt.Fprintf(&b, "<h1>%s</h1>", html.EscapeString(doc.Title))
+	if doc.Subtitle != "" {
+		fmt.Fprintf(&b, `<p class="subtitle">%s</p>`, html.EscapeString(doc.Subtitle))
+	}
+	if len(doc.Meta) > 0 {
+		b.WriteString(`<p class="meta">`)
+		for i, m := range doc.Meta {
+			if i > 0 {
+				b.WriteString("<span class=\"sep\">|</span>")
+			}
+			b.WriteString(html.EscapeString(m))
+		}
+		b.WriteString("</p>")
+	}
+
+	for _, section := range doc.Sections {
+		if section.Name != "" {
+			fmt.Fprintf(&b, `<h2 class="section">%s</h2>`, html.EscapeString(section.Name))
+		}
+		for _, group := range section.Groups {
+			if group.Material != nil {
+				writeHTMLMaterial(&b, group.Material)
+			}
+			for _, q := range group.Questions {
+				writeHTMLQuestion(&b, q, doc.AnswerMode == AnswerInline)
+			}
+		}
+	}
+
+	if doc.AnswerSheet {
+		writeHTMLAnswerSheet(&b, doc)
+	}
+	if doc.AnswerMode == AnswerAppendix {
+		writeHTMLAppendix(&b, doc)
+	}
+
+	b.WriteString("</body></html>")
+	return b.String()
+}
+
+func writeCSS(b *strings.Builder, font *Font) {
+	families := fallbackFamilies
+	if font != nil {
+		fmt.Fprintf(b, "@font-face{font-family:%q;src:url(data:font/%s;base64,%s) format(%q);}",
+			embeddedFamily, fontMIME(font), base64.StdEncoding.EncodeToString(font.Data), font.Format)
+		families = fmt.Sprintf("%q, %q, %s", embeddedFamily, font.Family, fallbackFamilies)
+	}
+	fmt.Fprintf(b, `
+@page{size:A4;margin:18mm 16mm 20mm 16mm;}
+html,body{margin:0;padding:0;}
+body{font-family:%s;font-size:10.5pt;line-height:1.7;color:#111;-webkit-print-color-adjust:exact;print-color-adjust:exact;}
+h1{text-align:center;font-size:18pt;margin:0 0 4pt;}
+.subtitle,.meta{text-align:center;margin:0 0 4pt;color:#444;}
+.meta .sep{margin:0 8pt;color:#999;}
+h2.section{font-size:13pt;margin:14pt 0 6pt;padding-bottom:2pt;border-bottom:1px solid #333;break-after:avoid;}
+h2.page{break-before:page;}
+p{margin:0;}
+.material{margin:8pt 0;padding:6pt 8pt;border:1px solid #999;background:rgba(0,0,0,0.03);}
+.material .title{font-weight:bold;margin-bottom:2pt;}
+.material p{text-indent:2em;}
+.question{margin:6pt 0 8pt;break-inside:avoid;}
+.question .no{font-weight:bold;margin-right:2pt;}
+.question .score{color:#666;font-size:9pt;margin-left:4pt;}
+.options{display:grid;column-gap:12pt;margin:2pt 0 0 1.5em;}
+.options.c1{grid-template-columns:1fr;}
+.options.c2{grid-template-columns:repeat(2,1fr);}
+.options.c3{grid-template-columns:repeat(3,1fr);}
+.options.c4{grid-template-columns:repeat(4,1fr);}
+.answer{margin-top:3pt;padding:3pt 6pt;border-left:3px solid #2b6cb0;background:rgba(43,108,176,0.06);}
+.answer .label,.notes .label{font-weight:bold;}
+.notes{margin-top:3pt;padding:3pt 6pt;border-left:3px solid #c05621;background:rgba(192,86,33,0.06);}
+.sheet-info{margin:6pt 0 10pt;}
+.sheet-info span{display:inline-block;margin-right:24pt;}
+.sheet-grid{display:grid;grid-template-columns:repeat(4,1fr);gap:5pt 10pt;}
+.sheet-item{white-space:nowrap;}
+.sheet-item .no{display:inline-block;width:2.2em;text-align:right;margin-right:4pt;}
+.bubble{display:inline-block;min-width:1.5em;padding:0 1pt;margin-right:3pt;border:1px solid #333;border-radius:50%%;text-align:center;font-size:8pt;line-height:1.3;}
+.bubble.multi{border-radius:2px;}
+.write-box{margin:4pt 0 8pt;border:1px solid #333;min-height:50mm;padding:3pt 6pt;break-inside:avoid;}
+.key-grid{display:grid;grid-template-columns:repeat(10,1fr);gap:2pt 6pt;margin-bottom:8pt;}
+.watermark{position:fixed;top:0;left:0;right:0;bottom:0;z-index:-1;overflow:hidden;pointer-events:none;display:flex;flex-wrap:wrap;align-content:space-around;justify-content:space-around;}
+.watermark span{width:33%%;text-align:center;transform:rotate(-30deg);color:rgba(0,0,0,0.09);font-size:15pt;white-space:nowrap;}
+`, families)
+}
+
+func fontMIME(font *Font) string {
+	if font.Format == "opentype" {
+		return "otf"
+	}
+	return "ttf"
+}
+
+func writeHTMLParagraphs(b *strings.Builder, paras []string) {
+	for _, p := range paras {
+		fmt.Fprintf(b, "<p>%s</p>", html.EscapeString(p))
+	}
+}
+
+func writeHTMLMaterial(b *strings.Builder, m *Material) {
+	b.WriteString(`<div class="material">`)
+	if m.Title != "" {
+		fmt.Fprintf(b, `<div class="title">%s</div>`, html.EscapeString(m.Title))
+	}
+	writeHTMLParagraphs(b, Paragraphs(m.Content))
+	b.WriteString("</div>")
+}
+
+func writeHTMLQuestion(b *strings.Builder, q Question, inlineAnswer bool) {
+	b.WriteString(`<div class="question">`)
+	paras := Paragraphs(q.Content)
+	if len(paras) == 0 {
+		paras = []string{""}
+	}
+	fmt.Fprintf(b, `<p><span class="no">%d.</span>%s`, q.Number, html.EscapeString(paras[0]))
+	if q.Score > 0 {
+		fmt.Fprintf(b, `<span class="score">（%s分）</span>`, formatScore(q.Score))
+	}
+	b.WriteString("</p>")
+	writeHTMLParagraphs(b, paras[1:])
+
+	if q.Choice() {
+		fmt.Fprintf(b, `<div class="options c%d">`, optionColumns(q.Options))
+		for _, opt := range q.Options {
+			fmt.Fprintf(b, "<div>%s. %s</div>", html.EscapeString(opt.Key),
+				html.EscapeString(strings.Join(Paragraphs(opt.Content), " ")))
+		}
+		b.WriteString("</div>")
+	}
+
+	if len(q.Notes) > 0 {
+		b.WriteString(`<div class="notes">`)
+		for _, n := range q.Notes {
+			fmt.Fprintf(b, `<p><span class="label">%s：</span>%s</p>`, html.EscapeString(n.Label),
+				html.EscapeString(strings.Join(Paragraphs(n.Value), " ")))
+		}
+		b.WriteString("</div>")
+	}
+
+	if inlineAnswer {
+		writeHTMLAnswer(b, q)
+	}
+	b.WriteString("</div>")
+}
+
+func writeHTMLAnswer(b *strings.Builder, q Question) {
+	if q.Answer == "" && q.Analysis == "" {
+		return
+	}
+	b.WriteString(`<div class="answer">`)
+	if q.Answer != "" {
+		fmt.Fprintf(b, `<p><span class="label">【答案】</span>%s</p>`,
+			html.EscapeString(strings.Join(Paragraphs(q.Answer), " ")))
+	}
+	if analysis := Paragraphs(q.Analysis); len(analysis) > 0 {
+		fmt.Fprintf(b, `<p><span class="label">【解析】</span>%s</p>`, html.EscapeString(analysis[0]))
+		writeHTMLParagraphs(b, analysis[1:])
+	}
+	b.WriteString("</div>")
+}
+
+func writeHTMLAnswerSheet(b *strings.Builder, doc *Document) {
+	questions := doc.Questions()
+	b.WriteString(`<h2 class="section page">答题卡</h2>`)
+	b.WriteString(`<div class="sheet-info"><span>姓名：________________</span><span>准考证号：________________</span></div>`)
+
+	var written []Question
+	b.WriteString(`<div class="sheet-grid">`)
+	for _, q := range questions {
+		if !q.Choice() {
+			written = append(written, q)
+			continue
+		}
+		class := "bubble"
+		if q.Multi {
+			class += " multi"
+		}
+		fmt.Fprintf(b, `<div class="sheet-item"><span class="no">%d</span>`, q.Number)
+		for _, opt := range q.Options {
+			fmt.Fprintf(b, `<span class="%s">%s</span>`, class, html.EscapeString(opt.Key))
+		}
+		b.WriteString("</div>")
+	}
+	b.WriteString("</div>")
+
+	for _, q := range written {
+		fmt.Fprintf(b, `<div class="write-box">第 %d 题</div>`, q.Number)
+	}
+}
+
+func writeHTMLAppendix(b *strings.Builder, doc *Document) {
+	questions := doc.Questions()
+	b.WriteString(`<h2 class="section page">参考答案与解析</h2>`)
+
+	b.WriteString(`<div class="key-grid">`)
+	for _, q := range questions {
+		if q.Choice() && q.Answer != "" {
+			fmt.Fprintf(b, "<div>%d. %s</div>", q.Number, html.EscapeString(q.Answer))
+		}
+	}
+	b.WriteString("</div>")
+
+	for _, q := range questions {
+		if q.Answer == "" && q.Analysis == "" {
+			continue
+		}
+		fmt.Fprintf(b, `<div class="question"><p><span class="no">%d.</span></p>`, q.Number)
+		writeHTMLAnswer(b, q)
+		b.WriteString("</div>")
+	}
+}
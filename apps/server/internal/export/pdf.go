@@ -0,0 +1,106 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+
+	"github.com/what-cse/server/internal/headless"
+)
+
+// A4 size in inches and margins used for PrintToPDF
+const (
+	a4WidthInch  = 8.27
+	a4HeightInch = 11.69
+	marginInch   = 0.6
+)
+
+// ErrPDFUnavailable is returned when no headless Chrome could be started
+var ErrPDFUnavailable = errors.New("headless chrome unavailable for PDF rendering")
+
+// footerTemplate prints page numbers; Chrome renders header/footer templates at a tiny default size
+const footerTemplate = `<div style="width:100%;font-size:8pt;color:#666;text-align:center;">` +
+	`<span class="pageNumber"></span> / <span class="totalPages"></span></div>`
+
+// PDFRenderer prints documents to PDF through headless Chrome.
+// Chrome subsets the embedded web font into the PDF, so CJK text renders on any reader.
+// One browser is shared by all requests and the number of open tabs is bounded.
+type PDFRenderer struct {
+	browser *headless.Browser
+	timeout time.Duration
+}
+
+// NewPDFRenderer creates a renderer; Chrome starts on the first request.
+// maxConcurrent bounds the PDFs rendered at once, further requests wait.
+func NewPDFRenderer(chromePath string, timeout time.Duration, maxConcurrent int) *PDFRenderer {
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+	return &PDFRenderer{
+		browser: headless.New(headless.Options{ChromePath: chromePath, MaxTabs: maxConcurrent}),
+		timeout: timeout,
+	}
+}
+
+// Render renders the document to PDF bytes
+func (r *PDFRenderer) Render(ctx context.Context, doc *Document, font *Font) ([]byte, error) {
+	content := RenderHTML(doc, font)
+
+	tabCtx, cancel, err := r.browser.Tab(ctx)
+	if err != nil {
+		if errors.Is(err, headless.ErrUnavailable) {
+			return nil, fmt.Errorf("%w: %v", ErrPDFUnavailable, err)
+		}
+		return nil, err
+	}
+	defer cancel()
+	browserCtx, timeoutCancel := context.WithTimeout(tabCtx, r.timeout)
+	defer timeoutCancel()
+
+	var fontsReady bool
+	var pdf []byte
+	err = chromedp.Run(browserCtx,
+		chromedp.Navigate("about:blank"),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			tree, err := page.GetFrameTree().Do(ctx)
+			if err != nil {
+				return err
+			}
+			return page.SetDocumentContent(tree.Frame.ID, content).Do(ctx)
+		}),
+		// Wait for the embedded font so glyphs are not printed with a fallback face
+		chromedp.Evaluate(`document.fonts.ready.then(() => true)`, &fontsReady,
+			func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+				return p.WithAwaitPromise(true)
+			}),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			buf, _, err := page.PrintToPDF().
+				WithPaperWidth(a4WidthInch).
+				WithPaperHeight(a4HeightInch).
+				WithMarginTop(marginInch).
+				WithMarginBottom(marginInch).
+				WithMarginLeft(marginInch).
+				WithMarginRight(marginInch).
+				WithPrintBackground(true).
+				WithPreferCSSPageSize(true).
+				WithDisplayHeaderFooter(true).
+				WithHeaderTemplate("<span></span>").
+				WithFooterTemplate(footerTemplate).
+				Do(ctx)
+			if err != nil {
+				return err
+			}
+			pdf = buf
+			return nil
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("print pdf: %w", err)
+	}
+	return pdf, nil
+}
@@ -0,0 +1,162 @@
+// Package feed renders syndication feeds. A Feed is built once and can be
+// written as RSS 2.0, Atom 1.0 or JSON Feed 1.1.
+package feed
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Format is an output format
+type Format string
+
+const (
+	FormatRSS  Format = "rss"
+	FormatAtom Format = "atom"
+	FormatJSON Format = "json"
+)
+
+// ParseFormat maps a format name (or a file extension such as "xml") to a Format
+func ParseFormat(name string) (Format, bool) {
+	switch strings.ToLower(strings.TrimPrefix(name, ".")) {
+	case "rss", "xml", "rss.xml":
+		return FormatRSS, true
+	case "atom", "atom.xml":
+		return FormatAtom, true
+	case "json", "feed.json":
+		return FormatJSON, true
+	}
+	return "", false
+}
+
+// ContentType returns the media type served for the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatAtom:
+		return "application/atom+xml; charset=utf-8"
+	case FormatJSON:
+		return "application/feed+json; charset=utf-8"
+	default:
+		return "application/rss+xml; charset=utf-8"
+	}
+}
+
+// Feed is a format-independent feed
+type Feed struct {
+	ID          string // permanent Atom identifier; FeedURL when empty
+	Title       string
+	Description string
+	Link        string // home page of the feed
+	FeedURL     string // URL the feed is served from
+	Language    string
+	IconURL     string
+	Updated     time.Time
+	Items       []Item
+}
+
+// Item is a feed entry. Content is HTML and may be empty in summary mode.
+type Item struct {
+	ID         string // stable, never reused
+	Title      string
+	Link       string
+	Summary    string // plain text
+	Content    string // HTML
+	Author     string
+	ImageURL   string
+	Categories []string
+	Published  time.Time
+	Updated    time.Time
+}
+
+// Render writes the feed in the given format
+func Render(f *Feed, format Format) ([]byte, error) {
+	switch format {
+	case FormatRSS:
+		return renderRSS(f)
+	case FormatAtom:
+		return renderAtom(f)
+	case FormatJSON:
+		return renderJSON(f)
+	}
+	return nil, fmt.Errorf("unknown feed format %q", format)
+}
+
+// ETag returns a strong entity tag for a rendered feed
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// MatchETag reports whether an If-None-Match header matches etag. Weak
+// validators compare equal to their strong form, as RFC 9110 requires for
+// If-None-Match.
+func MatchETag(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// Summarize turns HTML or plain text into a single-line plain-text summary of at most n characters
+func Summarize(content string, n int) string {
+	text := content
+	if strings.Contains(content, "<") {
+		if doc, err := goquery.NewDocumentFromReader(strings.NewReader(content)); err == nil {
+			doc.Find("script, style").Remove()
+			text = doc.Text()
+		}
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	if n <= 0 || utf8.RuneCountInString(text) <= n {
+		return text
+	}
+	return strings.TrimSpace(string([]rune(text)[:n])) + "…"
+}
+
+// TextToHTML returns content unchanged when it already looks like HTML and
+// otherwise escapes it and turns its lines into paragraphs
+func TextToHTML(content string) string {
+	if strings.Contains(content, "</") || strings.Contains(content, "<br") {
+		return content
+	}
+	var b strings.Builder
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		b.WriteString("<p>" + html.EscapeString(line) + "</p>\n")
+	}
+	return b.String()
+}
+
+// LastUpdated returns the feed's update time, or its newest item's when later
+func (f *Feed) LastUpdated() time.Time {
+	updated := f.Updated
+	for _, item := range f.Items {
+		if t := item.updated(); t.After(updated) {
+			updated = t
+		}
+	}
+	return updated
+}
+
+func (i *Item) updated() time.Time {
+	if i.Updated.After(i.Published) {
+		return i.Updated
+	}
+	return i.Published
+}
@@ -0,0 +1,355 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+// ErrUnknownFormat is returned when a document is not RSS, Atom or JSON Feed
+var ErrUnknownFormat = errors.New("not an RSS, Atom or JSON feed")
+
+// Parse reads an RSS 2.0, RSS 1.0 (RDF), Atom 1.0 or JSON Feed document.
+// XML documents may declare a non-UTF-8 encoding such as GBK.
+func Parse(data []byte) (*Feed, error) {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(trimmed) == 0 {
+		return nil, ErrUnknownFormat
+	}
+	if trimmed[0] == '{' {
+		return parseJSON(trimmed)
+	}
+
+	root, err := xmlRoot(trimmed)
+	if err != nil {
+		return nil, err
+	}
+	switch root {
+	case "rss", "RDF":
+		return parseRSS(trimmed)
+	case "feed":
+		return parseAtom(trimmed)
+	}
+	return nil, fmt.Errorf("%w: root element <%s>", ErrUnknownFormat, root)
+}
+
+func newXMLDecoder(data []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charset.NewReaderLabel
+	// Many hand-written feeds contain HTML entities such as &nbsp;
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	return decoder
+}
+
+// xmlRoot returns the local name of the document element
+func xmlRoot(data []byte) (string, error) {
+	decoder := newXMLDecoder(data)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrUnknownFormat, err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+// =====================================================
+// RSS 2.0 / RSS 1.0
+// =====================================================
+
+type rssInDoc struct {
+	Channel struct {
+		Title       string      `xml:"title"`
+		Links       []rssInLink `xml:"link"`
+		Description string      `xml:"description"`
+		Language    string      `xml:"language"`
+		Image       struct {
+			URL string `xml:"url"`
+		} `xml:"image"`
+		Items []rssInItem `xml:"item"`
+	} `xml:"channel"`
+	// RSS 1.0 puts items next to the channel instead of inside it
+	Items []rssInItem `xml:"item"`
+}
+
+// rssInLink matches both <link>url</link> and <atom:link href="url"/>
+type rssInLink struct {
+	Href  string `xml:"href,attr"`
+	Rel   string `xml:"rel,attr"`
+	Value string `xml:",chardata"`
+}
+
+type rssInItem struct {
+	Title       string      `xml:"title"`
+	Links       []rssInLink `xml:"link"`
+	GUID        string      `xml:"guid"`
+	About       string      `xml:"about,attr"`
+	Description string      `xml:"description"`
+	Content     string      `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Creator     string      `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Author      string      `xml:"author"`
+	PubDate     string      `xml:"pubDate"`
+	Date        string      `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Categories  []string    `xml:"category"`
+	Enclosures  []struct {
+		URL  string `xml:"url,attr"`
+		Type string `xml:"type,attr"`
+	} `xml:"enclosure"`
+}
+
+func rssLink(links []rssInLink) string {
+	for _, link := range links {
+		if v := strings.TrimSpace(link.Value); v != "" {
+			return v
+		}
+	}
+	for _, link := range links {
+		if link.Href != "" && (link.Rel == "" || link.Rel == "alternate") {
+			return link.Href
+		}
+	}
+	return ""
+}
+
+func parseRSS(data []byte) (*Feed, error) {
+	var doc rssInDoc
+	if err := newXMLDecoder(data).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parse rss: %w", err)
+	}
+	f := &Feed{
+		Title:       strings.TrimSpace(doc.Channel.Title),
+		Description: strings.TrimSpace(doc.Channel.Description),
+		Link:        rssLink(doc.Channel.Links),
+		Language:    doc.Channel.Language,
+		IconURL:     strings.TrimSpace(doc.Channel.Image.URL),
+	}
+	items := append(doc.Channel.Items, doc.Items...)
+	for _, in := range items {
+		item := Item{
+			ID:         strings.TrimSpace(in.GUID),
+			Title:      strings.TrimSpace(in.Title),
+			Link:       rssLink(in.Links),
+			Author:     strings.TrimSpace(in.Creator),
+			Categories: in.Categories,
+			Published:  parseTime(in.PubDate),
+		}
+		if item.ID == "" {
+			item.ID = in.About
+		}
+		if item.ID == "" {
+			item.ID = item.Link
+		}
+		if item.Author == "" {
+			item.Author = strings.TrimSpace(in.Author)
+		}
+		if item.Published.IsZero() {
+			item.Published = parseTime(in.Date)
+		}
+		// description is the full text in many feeds that lack content:encoded
+		item.Content = strings.TrimSpace(in.Content)
+		if item.Content == "" {
+			item.Content = strings.TrimSpace(in.Description)
+		}
+		item.Summary = Summarize(in.Description, 0)
+		for _, enclosure := range in.Enclosures {
+			if strings.HasPrefix(enclosure.Type, "image/") {
+				item.ImageURL = enclosure.URL
+				break
+			}
+		}
+		f.Items = append(f.Items, item)
+	}
+	return f, nil
+}
+
+// =====================================================
+// Atom
+// =====================================================
+
+type atomInText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+// html returns the text construct as HTML
+func (t atomInText) html() string {
+	switch t.Type {
+	case "xhtml":
+		return strings.TrimSpace(t.Inner)
+	case "html", "text/html":
+		return strings.TrimSpace(t.Value)
+	}
+	return TextToHTML(t.Value)
+}
+
+type atomInEntry struct {
+	ID         string         `xml:"id"`
+	Title      atomInText     `xml:"title"`
+	Links      []atomLink     `xml:"link"`
+	Summary    atomInText     `xml:"summary"`
+	Content    atomInText     `xml:"content"`
+	Authors    []atomPerson   `xml:"author"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type atomInFeed struct {
+	Title    atomInText    `xml:"title"`
+	Subtitle atomInText    `xml:"subtitle"`
+	Links    []atomLink    `xml:"link"`
+	Icon     string        `xml:"icon"`
+	Logo     string        `xml:"logo"`
+	Updated  string        `xml:"updated"`
+	Entries  []atomInEntry `xml:"entry"`
+}
+
+func atomAlternate(links []atomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return link.Href
+		}
+	}
+	return ""
+}
+
+func parseAtom(data []byte) (*Feed, error) {
+	var doc atomInFeed
+	if err := newXMLDecoder(data).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parse atom: %w", err)
+	}
+	f := &Feed{
+		Title:       Summarize(doc.Title.html(), 0),
+		Description: Summarize(doc.Subtitle.html(), 0),
+		Link:        atomAlternate(doc.Links),
+		IconURL:     doc.Icon,
+		Updated:     parseTime(doc.Updated),
+	}
+	if f.IconURL == "" {
+		f.IconURL = doc.Logo
+	}
+	for _, in := range doc.Entries {
+		item := Item{
+			ID:        strings.TrimSpace(in.ID),
+			Title:     Summarize(in.Title.html(), 0),
+			Link:      atomAlternate(in.Links),
+			Summary:   Summarize(in.Summary.html(), 0),
+			Content:   in.Content.html(),
+			Published: parseTime(in.Published),
+			Updated:   parseTime(in.Updated),
+		}
+		if item.ID == "" {
+			item.ID = item.Link
+		}
+		if item.Published.IsZero() {
+			item.Published = item.Updated
+		}
+		if item.Content == "" {
+			item.Content = in.Summary.html()
+		}
+		if len(in.Authors) > 0 {
+			item.Author = strings.TrimSpace(in.Authors[0].Name)
+		}
+		for _, link := range in.Links {
+			if link.Rel == "enclosure" && strings.HasPrefix(link.Type, "image/") {
+				item.ImageURL = link.Href
+				break
+			}
+		}
+		for _, category := range in.Categories {
+			item.Categories = append(item.Categories, category.Term)
+		}
+		f.Items = append(f.Items, item)
+	}
+	return f, nil
+}
+
+// =====================================================
+// JSON Feed
+// =====================================================
+
+func parseJSON(data []byte) (*Feed, error) {
+	var doc jsonFeed
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse json feed: %w", err)
+	}
+	if !strings.HasPrefix(doc.Version, "https://jsonfeed.org/version/") {
+		return nil, ErrUnknownFormat
+	}
+	f := &Feed{
+		Title:       doc.Title,
+		Description: doc.Description,
+		Link:        doc.HomePageURL,
+		FeedURL:     doc.FeedURL,
+		Language:    doc.Language,
+		IconURL:     doc.Icon,
+	}
+	for _, in := range doc.Items {
+		item := Item{
+			ID:         in.ID,
+			Title:      in.Title,
+			Link:       in.URL,
+			Summary:    in.Summary,
+			Content:    in.ContentHTML,
+			ImageURL:   in.Image,
+			Categories: in.Tags,
+			Published:  parseTime(in.DatePublished),
+			Updated:    parseTime(in.DateModified),
+		}
+		if item.Content == "" && in.ContentText != "" {
+			item.Content = TextToHTML(in.ContentText)
+		}
+		if item.Summary == "" {
+			item.Summary = Summarize(item.Content, 0)
+		}
+		if len(in.Authors) > 0 {
+			item.Author = in.Authors[0].Name
+		} else if in.Author != nil {
+			item.Author = in.Author.Name
+		}
+		f.Items = append(f.Items, item)
+	}
+	return f, nil
+}
+
+// timeLayouts are the date formats seen in feeds, strictest first
+var timeLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC822Z,
+	time.RFC822,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006/01/02 15:04:05",
+	"2006-01-02",
+}
+
+// parseTime parses a feed date; dates without a zone are taken as local time
+func parseTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
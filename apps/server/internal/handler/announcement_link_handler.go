@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/what-cse/server/internal/service"
+)
+
+// AnnouncementLinkHandler 招录批次关联与职位时间线处理器
+type AnnouncementLinkHandler struct {
+	linkerService *service.AnnouncementLinkerService
+}
+
+// NewAnnouncementLinkHandler 创建招录批次关联处理器
+func NewAnnouncementLinkHandler(linkerService *service.AnnouncementLinkerService) *AnnouncementLinkHandler {
+	return &AnnouncementLinkHandler{linkerService: linkerService}
+}
+
+// RegisterPositionRoutes 注册职位时间线路由（公开）
+func (h *AnnouncementLinkHandler) RegisterPositionRoutes(g *echo.Group) {
+	g.GET("/:id/timeline", h.GetPositionTimeline)
+}
+
+// RegisterAdminRoutes 注册管理端路由
+func (h *AnnouncementLinkHandler) RegisterAdminRoutes(g *echo.Group, adminAuthMiddleware echo.MiddlewareFunc) {
+	admin := g.Group("/announcements", adminAuthMiddleware)
+	{
+		admin.POST("/:id/link", h.LinkAnnouncement) // 立即关联单条公告
+		admin.POST("/link-pending", h.LinkPending)  // 处理一批待关联公告
+	}
+}
+
+// GetPositionTimeline returns the recruitment campaign timeline of a position
+// @Summary Get Position Timeline
+// @Description Get the announcements of the position's recruitment campaign and its known outcome (interview score line, final score)
+// @Tags Position
+// @Accept json
+// @Produce json
+// @Param id path int true "Position ID"
+// @Success 200 {object} Response
+// @Router /api/v1/positions/{id}/timeline [get]
+func (h *AnnouncementLinkHandler) GetPositionTimeline(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return fail(c, 400, "Invalid position ID")
+	}
+
+	timeline, err := h.linkerService.GetPositionTimeline(uint(id))
+	if errors.Is(err, service.ErrPositionNotFound) {
+		return fail(c, 404, "Position not found")
+	}
+	if err != nil {
+		return fail(c, 500, "Failed to fetch position timeline: "+err.Error())
+	}
+	return success(c, timeline)
+}
+
+// LinkAnnouncement 立即把单条公告归入招录批次并提取职位结果
+// @Summary Link Announcement
+// @Tags Admin
+// @Param id path int true "Announcement ID"
+// @Success 200 {object} Response
+// @Router /api/v1/admin/announcements/{id}/link [post]
+func (h *AnnouncementLinkHandler) LinkAnnouncement(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return fail(c, 400, "无效的公告ID")
+	}
+
+	result, err := h.linkerService.LinkAnnouncement(c.Request().Context(), uint(id))
+	switch {
+	case errors.Is(err, service.ErrAnnouncementNotFound):
+		return fail(c, 404, "公告不存在")
+	case errors.Is(err, service.ErrAnnouncementLinking):
+		return fail(c, 409, err.Error())
+	case err != nil:
+		return fail(c, 500, err.Error())
+	}
+	return success(c, result)
+}
+
+// LinkPending 处理一批尚未关联的公告
+// @Summary Link Pending Announcements
+// @Tags Admin
+// @Success 200 {object} Response
+// @Router /api/v1/admin/announcements/link-pending [post]
+func (h *AnnouncementLinkHandler) LinkPending(c echo.Context) error {
+	result, err := h.linkerService.LinkPending(c.Request().Context())
+	if errors.Is(err, service.ErrAnnouncementLinking) {
+		return fail(c, 409, err.Error())
+	}
+	if err != nil {
+		return fail(c, 500, err.Error())
+	}
+	return success(c, result)
+}
@@ -13,6 +13,7 @@ import (
 type ContentGeneratorHandler struct {
 	generatorService    *service.ContentGeneratorService
 	llmGeneratorService *service.LLMGeneratorService
+	qualityService      *service.ContentQualityService
 }
 
 // NewContentGeneratorHandler 创建内容生成处理器
@@ -23,6 +24,11 @@ func NewContentGeneratorHandler(generatorService *service.ContentGeneratorServic
 	}
 }
 
+// SetQualityService 设置内容质量检查服务，未设置时质量检查接口返回模拟数据
+func (h *ContentGeneratorHandler) SetQualityService(qualityService *service.ContentQualityService) {
+	h.qualityService = qualityService
+}
+
 // RegisterRoutes 注册路由
 func (h *ContentGeneratorHandler) RegisterRoutes(e *echo.Echo, authMiddleware echo.MiddlewareFunc) {
 	admin := e.Group("/api/v1/admin/generator", authMiddleware)
@@ -958,6 +964,24 @@ func (h *ContentGeneratorHandler) RunQualityCheck(c echo.Context) error {
 
 	adminID := getAdminID(c)
 
+	if h.qualityService != nil {
+		task, err := h.qualityService.RunQualityCheck(&service.QualityCheckRequest{
+			CheckType:  req.CheckType,
+			TargetType: req.TargetType,
+		}, adminID)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"code":    500,
+				"message": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"code":    0,
+			"message": "质量检查任务已创建",
+			"data":    task.ToResponse(),
+		})
+	}
+
 	// 创建模拟任务
 	task := &model.ContentGeneratorTask{
 		TaskType:     "quality_check",
@@ -998,6 +1022,26 @@ func (h *ContentGeneratorHandler) GetQualityResults(c echo.Context) error {
 		pageSize = 20
 	}
 
+	if h.qualityService != nil {
+		results, total, err := h.qualityService.GetQualityResults(page, pageSize, checkType, severity)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"code":    500,
+				"message": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"code":    0,
+			"message": "success",
+			"data": map[string]interface{}{
+				"results":   results,
+				"total":     total,
+				"page":      page,
+				"page_size": pageSize,
+			},
+		})
+	}
+
 	// 返回模拟数据
 	results := []map[string]interface{}{}
 	if checkType != "" || severity != "" {
@@ -1031,8 +1075,14 @@ func (h *ContentGeneratorHandler) ResolveQualityIssue(c echo.Context) error {
 		})
 	}
 
-	// 在实际实现中，这里应该调用ContentQualityService
-	_ = id
+	if h.qualityService != nil {
+		if err := h.qualityService.ResolveIssue(uint(id), getAdminID(c)); err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"code":    500,
+				"message": err.Error(),
+			})
+		}
+	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"code":    0,
@@ -19,6 +19,7 @@ type ContentImportHandler struct {
 	courseService   *service.CourseService
 	questionService *service.QuestionService
 	materialService *service.MaterialService
+	dedupService    *service.QuestionDedupService
 }
 
 // NewContentImportHandler 创建内容导入处理器
@@ -36,6 +37,11 @@ func NewContentImportHandler(
 	}
 }
 
+// SetDedupService 设置题目查重服务，导入题目前做近似重复检测
+func (h *ContentImportHandler) SetDedupService(dedupService *service.QuestionDedupService) {
+	h.dedupService = dedupService
+}
+
 // RegisterRoutes 注册路由
 func (h *ContentImportHandler) RegisterRoutes(e *echo.Echo, authMiddleware echo.MiddlewareFunc) {
 	admin := e.Group("/api/v1/admin/content/import", authMiddleware)
@@ -781,11 +787,17 @@ func (h *ContentImportHandler) createPracticeQuestions(categoryID uint, problems
 		questions = append(questions, question)
 	}
 
-	if err := h.db.Create(&questions).Error; err != nil {
+	report, err := h.dedupService.ImportQuestions(questions, model.QuestionDuplicateSourceImport, h.createQuestions)
+	if err != nil {
 		return 0, err
 	}
 
-	return len(questions), nil
+	return report.Created, nil
+}
+
+// createQuestions 批量写入题目
+func (h *ContentImportHandler) createQuestions(questions []model.Question) error {
+	return h.db.Create(&questions).Error
 }
 
 // parseDifficulty 解析难度值
@@ -905,7 +917,8 @@ func (h *ContentImportHandler) ImportQuestions(c echo.Context) error {
 		questions = append(questions, question)
 	}
 
-	if err := h.db.Create(&questions).Error; err != nil {
+	report, err := h.dedupService.ImportQuestions(questions, model.QuestionDuplicateSourceImport, h.createQuestions)
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
 			"code":    500,
 			"message": "创建题目失败: " + err.Error(),
@@ -916,8 +929,11 @@ func (h *ContentImportHandler) ImportQuestions(c echo.Context) error {
 		"code":    0,
 		"message": "题目导入成功",
 		"data": map[string]interface{}{
-			"count":       len(questions),
+			"count":       report.Created,
 			"category_id": categoryID,
+			"rejected":    report.Rejected,
+			"flagged":     report.Flagged,
+			"duplicates":  report.Items,
 		},
 	})
 }
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/labstack/echo/v4"
@@ -153,6 +154,66 @@ func (h *CrawlerHandler) TestListPageCrawl(c echo.Context) error {
 	return success(c, result)
 }
 
+// DryRunAdapter runs a site adapter and shows the extracted items
+// @Summary Dry-run Site Adapter (Admin)
+// @Description Run a site adapter against a list URL and a few detail pages without saving results
+// @Tags Admin - Crawler
+// @Accept json
+// @Produce json
+// @Security AdminAuth
+// @Param request body service.AdapterDryRunRequest true "Adapter and list URL"
+// @Success 200 {object} Response
+// @Router /api/v1/admin/crawlers/adapters/dry-run [post]
+func (h *CrawlerHandler) DryRunAdapter(c echo.Context) error {
+	var req service.AdapterDryRunRequest
+	if err := c.Bind(&req); err != nil {
+		return fail(c, 400, "Invalid request parameters")
+	}
+	return h.dryRunAdapter(c, &req)
+}
+
+// DryRunListPageAdapter runs the adapter of a list page, or the one in the
+// request body, and shows the extracted items
+// @Summary Dry-run List Page Adapter (Admin)
+// @Description Run a list page's site adapter (or an edited one from the body) without saving results
+// @Tags Admin - Crawler
+// @Accept json
+// @Produce json
+// @Security AdminAuth
+// @Param id path int true "List Page ID"
+// @Param request body service.AdapterDryRunRequest false "Adapter override"
+// @Success 200 {object} Response
+// @Router /api/v1/admin/list-pages/{id}/dry-run [post]
+func (h *CrawlerHandler) DryRunListPageAdapter(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return fail(c, 400, "Invalid list page ID")
+	}
+
+	var req service.AdapterDryRunRequest
+	if err := c.Bind(&req); err != nil {
+		return fail(c, 400, "Invalid request parameters")
+	}
+	listPageID := uint(id)
+	req.ListPageID = &listPageID
+	return h.dryRunAdapter(c, &req)
+}
+
+func (h *CrawlerHandler) dryRunAdapter(c echo.Context, req *service.AdapterDryRunRequest) error {
+	result, err := h.crawlerService.DryRunAdapter(req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrListPageNotFound):
+			return fail(c, 404, "List page not found")
+		case errors.Is(err, service.ErrInvalidAdapter):
+			return fail(c, 400, err.Error())
+		}
+		return fail(c, 500, "Dry run failed: "+err.Error())
+	}
+
+	return success(c, result)
+}
+
 // GetCrawlerStatistics returns crawler statistics
 // @Summary Get Crawler Statistics (Admin)
 // @Description Get comprehensive crawler statistics
@@ -277,6 +338,9 @@ func (h *CrawlerHandler) CreateListPage(c echo.Context) error {
 		if err == service.ErrListPageExists {
 			return fail(c, 409, "List page with this URL already exists")
 		}
+		if errors.Is(err, service.ErrInvalidAdapter) {
+			return fail(c, 400, err.Error())
+		}
 		return fail(c, 500, "Failed to create list page: "+err.Error())
 	}
 
@@ -334,6 +398,9 @@ func (h *CrawlerHandler) UpdateListPage(c echo.Context) error {
 		if err == service.ErrListPageNotFound {
 			return fail(c, 404, "List page not found")
 		}
+		if errors.Is(err, service.ErrInvalidAdapter) {
+			return fail(c, 400, err.Error())
+		}
 		return fail(c, 500, "Failed to update list page: "+err.Error())
 	}
 
@@ -373,6 +440,7 @@ func (h *CrawlerHandler) RegisterRoutes(g *echo.Group, adminAuthMiddleware echo.
 	crawler.GET("/crawlers", h.ListCrawlerTasks)
 	crawler.POST("/crawlers/trigger", h.TriggerCrawler)
 	crawler.GET("/crawlers/stats", h.GetCrawlerStatistics)
+	crawler.POST("/crawlers/adapters/dry-run", h.DryRunAdapter)
 	
 	// Crawl tasks
 	crawler.GET("/crawlers/tasks", h.ListCrawlTasks)
@@ -389,4 +457,5 @@ func (h *CrawlerHandler) RegisterRoutes(g *echo.Group, adminAuthMiddleware echo.
 	crawler.PUT("/list-pages/:id", h.UpdateListPage)
 	crawler.DELETE("/list-pages/:id", h.DeleteListPage)
 	crawler.POST("/list-pages/:id/test", h.TestListPageCrawl)
+	crawler.POST("/list-pages/:id/dry-run", h.DryRunListPageAdapter)
 }
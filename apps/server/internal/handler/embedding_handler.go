@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/what-cse/server/internal/model"
+	"github.com/what-cse/server/internal/service"
+)
+
+// EmbeddingHandler 内容向量管理处理器
+type EmbeddingHandler struct {
+	embeddingService *service.EmbeddingService
+}
+
+// NewEmbeddingHandler 创建内容向量管理处理器
+func NewEmbeddingHandler(embeddingService *service.EmbeddingService) *EmbeddingHandler {
+	return &EmbeddingHandler{
+		embeddingService: embeddingService,
+	}
+}
+
+// RegisterAdminRoutes 注册管理员路由
+func (h *EmbeddingHandler) RegisterAdminRoutes(g *echo.Group, authMiddleware echo.MiddlewareFunc) {
+	admin := g.Group("/embeddings", authMiddleware)
+	{
+		admin.GET("/status", h.GetStatus)         // 向量索引状态
+		admin.POST("/refresh", h.Refresh)         // 立即增量向量化（后台执行）
+		admin.GET("/duplicates", h.GetDuplicates) // 疑似重复内容
+		admin.GET("/similar/:id", h.GetSimilar)   // 相似内容
+	}
+}
+
+// GetStatus 获取向量索引状态
+func (h *EmbeddingHandler) GetStatus(c echo.Context) error {
+	return success(c, h.embeddingService.GetStatus())
+}
+
+// Refresh 立即增量向量化
+func (h *EmbeddingHandler) Refresh(c echo.Context) error {
+	go func() {
+		if _, err := h.embeddingService.Refresh(context.Background()); err != nil && !errors.Is(err, service.ErrEmbeddingRefreshing) {
+			h.embeddingService.ResetEmbedder()
+		}
+	}()
+	return success(c, map[string]interface{}{
+		"message": "向量化任务已启动",
+	})
+}
+
+// GetDuplicates 获取疑似重复内容
+// @Param type query string false "question 或 material，默认 question"
+// @Param threshold query number false "余弦相似度阈值，默认取配置值"
+// @Param limit query int false "最多返回对数，默认 100"
+func (h *EmbeddingHandler) GetDuplicates(c echo.Context) error {
+	entityType := c.QueryParam("type")
+	if entityType == "" {
+		entityType = model.EmbeddingEntityQuestion
+	}
+	threshold, _ := strconv.ParseFloat(c.QueryParam("threshold"), 64)
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	pairs, total, err := h.embeddingService.FindDuplicates(entityType, threshold, limit)
+	if err != nil {
+		return fail(c, 400, err.Error())
+	}
+	return success(c, map[string]interface{}{
+		"pairs":   pairs,
+		"checked": total,
+	})
+}
+
+// GetSimilar 获取相似内容
+// @Param type query string false "question 或 material，默认 question"
+// @Param limit query int false "返回数量，默认 10"
+func (h *EmbeddingHandler) GetSimilar(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return fail(c, 400, "无效的ID")
+	}
+	entityType := c.QueryParam("type")
+	if entityType == "" {
+		entityType = model.EmbeddingEntityQuestion
+	}
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+
+	similar, err := h.embeddingService.SimilarByID(entityType, uint(id), limit)
+	if errors.Is(err, service.ErrEmbeddingNotReady) {
+		return fail(c, 404, err.Error())
+	}
+	if err != nil {
+		return fail(c, 400, err.Error())
+	}
+	return success(c, similar)
+}
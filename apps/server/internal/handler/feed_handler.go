@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/what-cse/server/internal/feed"
+	"github.com/what-cse/server/internal/service"
+)
+
+// FeedHandler 公开 RSS/Atom/JSON Feed 订阅源处理器
+type FeedHandler struct {
+	feedService *service.FeedService
+}
+
+// NewFeedHandler 创建订阅源处理器
+func NewFeedHandler(feedService *service.FeedService) *FeedHandler {
+	return &FeedHandler{feedService: feedService}
+}
+
+// RegisterRoutes 注册订阅源路由，格式为 rss、atom 或 json，省略时为 rss
+func (h *FeedHandler) RegisterRoutes(g *echo.Group, authMiddleware echo.MiddlewareFunc) {
+	feeds := g.Group("/feeds")
+	{
+		feeds.GET("/announcements", h.AnnouncementFeed)
+		feeds.GET("/announcements/:format", h.AnnouncementFeed)
+		feeds.GET("/wechat/:id", h.WechatSourceFeed)
+		feeds.GET("/wechat/:id/:format", h.WechatSourceFeed)
+		feeds.GET("/user/:token", h.UserFeed)
+		feeds.GET("/user/:token/:format", h.UserFeed)
+
+		// 用户专属订阅地址
+		feeds.GET("/token", h.GetToken, authMiddleware)
+		feeds.POST("/token/reset", h.ResetToken, authMiddleware)
+	}
+}
+
+// AnnouncementFeed returns the announcement feed
+// @Summary Announcement Feed
+// @Description RSS 2.0, Atom or JSON Feed of published announcements. Supports ETag / If-None-Match and If-Modified-Since.
+// @Tags Feed
+// @Produce xml
+// @Produce json
+// @Param format path string false "rss (default), atom or json"
+// @Param province query string false "Province"
+// @Param exam_type query string false "Exam type"
+// @Param type query string false "Announcement type"
+// @Param mode query string false "full (default) or summary"
+// @Success 200 {string} string
+// @Success 304 {string} string
+// @Router /api/v1/feeds/announcements/{format} [get]
+func (h *FeedHandler) AnnouncementFeed(c echo.Context) error {
+	format, ok := feedFormat(c)
+	if !ok {
+		return fail(c, 400, "Unsupported feed format, use rss, atom or json")
+	}
+	f, err := h.feedService.AnnouncementFeed(feedQuery(c))
+	if err != nil {
+		return fail(c, 500, "Failed to build feed: "+err.Error())
+	}
+	return h.serveFeed(c, f, format, false)
+}
+
+// WechatSourceFeed returns the article feed of a WeChat source
+// @Summary WeChat Source Feed
+// @Description RSS 2.0, Atom or JSON Feed of a WeChat official account's crawled articles
+// @Tags Feed
+// @Produce xml
+// @Produce json
+// @Param id path int true "Source ID"
+// @Param format path string false "rss (default), atom or json"
+// @Param mode query string false "full (default) or summary"
+// @Success 200 {string} string
+// @Success 304 {string} string
+// @Router /api/v1/feeds/wechat/{id}/{format} [get]
+func (h *FeedHandler) WechatSourceFeed(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return fail(c, 400, "Invalid source ID")
+	}
+	format, ok := feedFormat(c)
+	if !ok {
+		return fail(c, 400, "Unsupported feed format, use rss, atom or json")
+	}
+	f, err := h.feedService.WechatSourceFeed(uint(id), feedQuery(c))
+	if errors.Is(err, service.ErrFeedSourceNotFound) {
+		return fail(c, 404, "Source not found")
+	}
+	if err != nil {
+		return fail(c, 500, "Failed to build feed: "+err.Error())
+	}
+	return h.serveFeed(c, f, format, false)
+}
+
+// UserFeed returns the announcement feed filtered by a user's subscriptions
+// @Summary User Feed
+// @Description Tokenized feed of announcements matching the user's enabled subscriptions
+// @Tags Feed
+// @Produce xml
+// @Produce json
+// @Param token path string true "Feed token"
+// @Param format path string false "rss (default), atom or json"
+// @Param mode query string false "full (default) or summary"
+// @Success 200 {string} string
+// @Success 304 {string} string
+// @Router /api/v1/feeds/user/{token}/{format} [get]
+func (h *FeedHandler) UserFeed(c echo.Context) error {
+	format, ok := feedFormat(c)
+	if !ok {
+		return fail(c, 400, "Unsupported feed format, use rss, atom or json")
+	}
+	f, err := h.feedService.UserFeed(c.Param("token"), feedQuery(c))
+	if errors.Is(err, service.ErrFeedTokenInvalid) {
+		return fail(c, 404, err.Error())
+	}
+	if err != nil {
+		return fail(c, 500, "Failed to build feed: "+err.Error())
+	}
+	return h.serveFeed(c, f, format, true)
+}
+
+// GetToken 获取当前用户的专属订阅地址
+// @Summary Get Feed Token
+// @Tags Feed
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Response
+// @Router /api/v1/feeds/token [get]
+func (h *FeedHandler) GetToken(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == 0 {
+		return fail(c, 401, "Unauthorized")
+	}
+	info, err := h.feedService.GetToken(userID)
+	if err != nil {
+		return fail(c, 500, "获取订阅地址失败: "+err.Error())
+	}
+	return success(c, withFeedURLs(c, info))
+}
+
+// ResetToken 重置专属订阅地址，旧地址立即失效
+// @Summary Reset Feed Token
+// @Tags Feed
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Response
+// @Router /api/v1/feeds/token/reset [post]
+func (h *FeedHandler) ResetToken(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == 0 {
+		return fail(c, 401, "Unauthorized")
+	}
+	info, err := h.feedService.ResetToken(userID)
+	if err != nil {
+		return fail(c, 500, "重置订阅地址失败: "+err.Error())
+	}
+	return success(c, withFeedURLs(c, info))
+}
+
+// serveFeed renders the feed and answers conditional requests with 304
+func (h *FeedHandler) serveFeed(c echo.Context, f *feed.Feed, format feed.Format, private bool) error {
+	body, err := feed.Render(f, format)
+	if err != nil {
+		return fail(c, 500, "Failed to render feed: "+err.Error())
+	}
+
+	header := c.Response().Header()
+	etag := feed.ETag(body)
+	header.Set("ETag", etag)
+	lastModified := f.LastUpdated()
+	if !lastModified.IsZero() {
+		header.Set(echo.HeaderLastModified, lastModified.UTC().Format(http.TimeFormat))
+	}
+	visibility := "public"
+	if private {
+		visibility = "private"
+	}
+	header.Set("Cache-Control", fmt.Sprintf("%s, max-age=%d", visibility, h.feedService.CacheSeconds()))
+
+	req := c.Request()
+	if ifNoneMatch := req.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		if feed.MatchETag(ifNoneMatch, etag) {
+			return c.NoContent(http.StatusNotModified)
+		}
+	} else if since, err := http.ParseTime(req.Header.Get(echo.HeaderIfModifiedSince)); err == nil && !lastModified.IsZero() {
+		if !lastModified.Truncate(time.Second).After(since) {
+			return c.NoContent(http.StatusNotModified)
+		}
+	}
+	return c.Blob(http.StatusOK, format.ContentType(), body)
+}
+
+// feedFormat reads the format path parameter, defaulting to RSS
+func feedFormat(c echo.Context) (feed.Format, bool) {
+	name := c.Param("format")
+	if name == "" {
+		return feed.FormatRSS, true
+	}
+	return feed.ParseFormat(name)
+}
+
+func feedQuery(c echo.Context) *service.FeedQuery {
+	announcementType := c.QueryParam("type")
+	if announcementType == "" {
+		announcementType = c.QueryParam("announcement_type")
+	}
+	return &service.FeedQuery{
+		AnnouncementType: announcementType,
+		ExamType:         c.QueryParam("exam_type"),
+		Province:         c.QueryParam("province"),
+		Summary:          c.QueryParam("mode") == "summary",
+		FeedURL:          c.Scheme() + "://" + c.Request().Host + c.Request().RequestURI,
+		Path:             c.Request().URL.Path,
+	}
+}
+
+func withFeedURLs(c echo.Context, info *service.FeedTokenInfo) *service.FeedTokenInfo {
+	base := c.Scheme() + "://" + c.Request().Host + info.Path
+	info.URLs = map[string]string{
+		string(feed.FormatRSS):  base + "/rss",
+		string(feed.FormatAtom): base + "/atom",
+		string(feed.FormatJSON): base + "/json",
+	}
+	return info
+}
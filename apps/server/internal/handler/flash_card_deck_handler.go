@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/what-cse/server/internal/model"
+	"github.com/what-cse/server/internal/service"
+)
+
+// flashCardDeckMaxFileSize 牌组上传文件大小上限
+const flashCardDeckMaxFileSize = 100 << 20
+
+// FlashCardDeckHandler 速记卡片牌组导入导出处理器
+type FlashCardDeckHandler struct {
+	deckService *service.FlashCardDeckService
+}
+
+// NewFlashCardDeckHandler 创建牌组导入导出处理器
+func NewFlashCardDeckHandler(deckService *service.FlashCardDeckService) *FlashCardDeckHandler {
+	return &FlashCardDeckHandler{
+		deckService: deckService,
+	}
+}
+
+// RegisterRoutes 注册用户路由
+func (h *FlashCardDeckHandler) RegisterRoutes(e *echo.Echo, authMiddleware echo.MiddlewareFunc) {
+	decks := e.Group("/api/v1/flash-cards", authMiddleware)
+	{
+		decks.GET("/export", h.Export)  // 导出 Anki 牌组（apkg/csv）
+		decks.POST("/import", h.Import) // 导入 Anki 牌组为私有卡片
+	}
+}
+
+// Export 导出速记卡片牌组
+// @Summary 导出 Anki 牌组
+// @Tags FlashCard
+// @Produce application/octet-stream
+// @Param format query string false "apkg/csv，默认 apkg"
+// @Param card_type query string false "卡片类型"
+// @Param category_id query int false "分类ID"
+// @Param due query bool false "仅导出今日到期的卡片"
+// @Param deck_name query string false "牌组名称"
+// @Success 200 {file} file
+// @Router /api/v1/flash-cards/export [get]
+func (h *FlashCardDeckHandler) Export(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == 0 {
+		return fail(c, 401, "请先登录")
+	}
+
+	req := &service.FlashCardDeckExportRequest{
+		Format:   c.QueryParam("format"),
+		CardType: model.FlashCardType(c.QueryParam("card_type")),
+		DeckName: c.QueryParam("deck_name"),
+	}
+	req.Due, _ = strconv.ParseBool(c.QueryParam("due"))
+	if categoryID, err := strconv.ParseUint(c.QueryParam("category_id"), 10, 32); err == nil {
+		req.CategoryID = uint(categoryID)
+	}
+
+	file, err := h.deckService.Export(userID, req)
+	switch {
+	case errors.Is(err, service.ErrFlashCardDeckFormat), errors.Is(err, service.ErrFlashCardDeckEmpty),
+		errors.Is(err, service.ErrFlashCardDeckTooMany):
+		return fail(c, 400, err.Error())
+	case err != nil:
+		return fail(c, 500, "导出失败: "+err.Error())
+	}
+	return sendPrintFile(c, file)
+}
+
+// Import 导入 Anki 牌组
+// @Summary 导入 Anki 牌组（.apkg/.csv/.txt/.tsv）为私有卡片
+// @Tags FlashCard
+// @Accept multipart/form-data
+// @Param file formData file true "牌组文件"
+// @Param card_type formData string false "卡片类型，默认 other"
+// @Param category_id formData int false "分类ID"
+// @Param front formData string false "正面字段名或列号，默认 Front 或第 1 列"
+// @Param back formData string false "背面字段名或列号，默认 Back 或第 2 列"
+// @Param title formData string false "标题字段，默认取正面首行"
+// @Param example formData string false "例句字段"
+// @Param mnemonic formData string false "记忆技巧字段"
+// @Param tags formData string false "标签字段"
+// @Param delimiter formData string false "CSV 分隔符，默认自动识别"
+// @Param has_header formData bool false "CSV 首行为列名"
+// @Param import_history formData bool false "带入 apkg 的复习历史，默认 true"
+// @Success 200 {object} Response
+// @Router /api/v1/flash-cards/import [post]
+func (h *FlashCardDeckHandler) Import(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == 0 {
+		return fail(c, 401, "请先登录")
+	}
+
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return fail(c, 400, "请上传牌组文件")
+	}
+	ext := strings.ToLower(filepath.Ext(fh.Filename))
+	switch ext {
+	case ".apkg", ".colpkg", ".csv", ".txt", ".tsv":
+	default:
+		return fail(c, 400, service.ErrFlashCardImportFormat.Error())
+	}
+	if fh.Size > flashCardDeckMaxFileSize {
+		return fail(c, 400, fmt.Sprintf("文件超过 %dMB", flashCardDeckMaxFileSize>>20))
+	}
+
+	src, err := fh.Open()
+	if err != nil {
+		return fail(c, 400, "读取上传文件失败")
+	}
+	defer src.Close()
+	tmp, err := os.CreateTemp("", "flash-card-deck-*"+ext)
+	if err != nil {
+		return fail(c, 500, "创建临时文件失败")
+	}
+	defer os.Remove(tmp.Name())
+	_, err = io.Copy(tmp, src)
+	tmp.Close()
+	if err != nil {
+		return fail(c, 500, "保存上传文件失败")
+	}
+
+	req := &service.FlashCardDeckImportRequest{
+		Path:     tmp.Name(),
+		FileName: fh.Filename,
+		CardType: model.FlashCardType(c.FormValue("card_type")),
+		Mapping: service.FlashCardDeckMapping{
+			Front:    c.FormValue("front"),
+			Back:     c.FormValue("back"),
+			Title:    c.FormValue("title"),
+			Example:  c.FormValue("example"),
+			Mnemonic: c.FormValue("mnemonic"),
+			Tags:     c.FormValue("tags"),
+		},
+		ImportHistory: true,
+	}
+	if categoryID, err := strconv.ParseUint(c.FormValue("category_id"), 10, 32); err == nil && categoryID > 0 {
+		id := uint(categoryID)
+		req.CategoryID = &id
+	}
+	switch d := c.FormValue("delimiter"); d {
+	case "":
+	case "tab", `\t`:
+		req.Delimiter = '\t'
+	default:
+		req.Delimiter = []rune(d)[0]
+	}
+	req.HasHeader, _ = strconv.ParseBool(c.FormValue("has_header"))
+	if v, err := strconv.ParseBool(c.FormValue("import_history")); err == nil {
+		req.ImportHistory = v
+	}
+
+	result, err := h.deckService.Import(userID, req)
+	switch {
+	case errors.Is(err, service.ErrFlashCardImportFormat), errors.Is(err, service.ErrFlashCardImportEmpty),
+		errors.Is(err, service.ErrFlashCardImportTooMany):
+		return fail(c, 400, err.Error())
+	case err != nil:
+		return fail(c, 500, "导入失败: "+err.Error())
+	}
+	return success(c, result)
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/what-cse/server/internal/service"
+)
+
+// IRTHandler 能力估计与题目标定处理器
+type IRTHandler struct {
+	irtService *service.IRTService
+}
+
+// NewIRTHandler 创建能力估计处理器
+func NewIRTHandler(irtService *service.IRTService) *IRTHandler {
+	return &IRTHandler{
+		irtService: irtService,
+	}
+}
+
+// RegisterRoutes 注册用户路由
+func (h *IRTHandler) RegisterRoutes(e *echo.Echo, authMiddleware echo.MiddlewareFunc) {
+	practice := e.Group("/api/v1/practice", authMiddleware)
+	practice.GET("/abilities", h.GetAbilities) // 总体与分类能力估计
+}
+
+// RegisterAdminRoutes 注册管理员路由
+func (h *IRTHandler) RegisterAdminRoutes(g *echo.Group, authMiddleware echo.MiddlewareFunc) {
+	admin := g.Group("/irt", authMiddleware)
+	{
+		admin.GET("/status", h.GetStatus)               // 标定状态与上次结果
+		admin.GET("/questions/:id", h.GetQuestionParam) // 题目当前参数
+		admin.POST("/calibrate", h.Calibrate)           // 立即标定（后台执行）
+	}
+}
+
+// GetAbilities 获取当前用户的能力估计
+// @Summary 获取能力估计
+// @Tags Practice
+// @Success 200 {object} Response
+// @Router /api/v1/practice/abilities [get]
+func (h *IRTHandler) GetAbilities(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == 0 {
+		return fail(c, 401, "请先登录")
+	}
+
+	abilities, err := h.irtService.GetAbilities(userID)
+	if err != nil {
+		return fail(c, 500, err.Error())
+	}
+	return success(c, abilities)
+}
+
+// GetStatus 获取标定状态
+func (h *IRTHandler) GetStatus(c echo.Context) error {
+	status, err := h.irtService.Status()
+	if err != nil {
+		return fail(c, 500, err.Error())
+	}
+	return success(c, status)
+}
+
+// GetQuestionParam 获取题目当前使用的 IRT 参数
+func (h *IRTHandler) GetQuestionParam(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return fail(c, 400, "无效的题目ID")
+	}
+
+	param, err := h.irtService.GetQuestionParam(uint(id))
+	if errors.Is(err, service.ErrQuestionNotFound) {
+		return fail(c, 404, err.Error())
+	}
+	if err != nil {
+		return fail(c, 500, err.Error())
+	}
+	return success(c, param)
+}
+
+// Calibrate 立即按作答记录重新标定
+func (h *IRTHandler) Calibrate(c echo.Context) error {
+	status, err := h.irtService.Status()
+	if err == nil && status.Calibrating {
+		return fail(c, 409, service.ErrIRTCalibrating.Error())
+	}
+	go h.irtService.Calibrate(context.Background())
+	return success(c, map[string]interface{}{
+		"message": "标定任务已启动",
+	})
+}
@@ -249,7 +249,7 @@ func (h *KnowledgeContentHandler) GetCard(c echo.Context) error {
 		return c.JSON(http.StatusOK, map[string]interface{}{"code": 400, "message": "Invalid ID"})
 	}
 
-	card, err := h.cardService.GetCard(uint(id))
+	card, err := h.cardService.GetCard(uint(id), getUserIDFromContext(c))
 	if err != nil {
 		return c.JSON(http.StatusOK, map[string]interface{}{"code": 404, "message": "Not found"})
 	}
@@ -527,6 +527,15 @@ func (h *KnowledgeContentHandler) DownloadMindMap(c echo.Context) error {
 		return c.JSON(http.StatusOK, map[string]interface{}{"code": 400, "message": "Invalid ID"})
 	}
 
+	// 指定 format 时返回 XMind/FreeMind/OPML/Markdown 文件，否则返回导图 JSON
+	if format := c.QueryParam("format"); format != "" && format != "json" {
+		file, err := h.mindMapService.ExportMindMap(uint(id), format)
+		if err != nil {
+			return mindMapExportError(c, err)
+		}
+		return sendPrintFile(c, file)
+	}
+
 	mindMap, err := h.mindMapService.DownloadMindMap(uint(id))
 	if err != nil {
 		return c.JSON(http.StatusOK, map[string]interface{}{"code": 404, "message": "Not found"})
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/what-cse/server/internal/model"
+	"github.com/what-cse/server/internal/service"
+)
+
+// LearningSearchHandler 学习内容统一搜索处理器
+type LearningSearchHandler struct {
+	learningSearchService *service.LearningSearchService
+}
+
+// NewLearningSearchHandler 创建学习内容搜索处理器
+func NewLearningSearchHandler(learningSearchService *service.LearningSearchService) *LearningSearchHandler {
+	return &LearningSearchHandler{
+		learningSearchService: learningSearchService,
+	}
+}
+
+// RegisterRoutes 注册路由，optionalAuth 用于识别会员以返回 VIP 内容摘要
+func (h *LearningSearchHandler) RegisterRoutes(g *echo.Group, optionalAuth echo.MiddlewareFunc) {
+	g.GET("/learning-search", h.Search, optionalAuth) // 搜索题目、素材、课程、知识点和公开笔记
+}
+
+// RegisterAdminRoutes 注册管理员路由
+func (h *LearningSearchHandler) RegisterAdminRoutes(g *echo.Group, authMiddleware echo.MiddlewareFunc) {
+	admin := g.Group("/learning-search", authMiddleware)
+	{
+		admin.GET("/status", h.GetStatus) // 索引状态
+		admin.POST("/refresh", h.Refresh) // 立即增量刷新
+		admin.POST("/rebuild", h.Rebuild) // 清空后全量重建
+	}
+}
+
+// Search 搜索学习内容
+// @Summary 学习内容统一搜索
+// @Tags LearningSearch
+// @Param keyword query string false "关键词"
+// @Param types query string false "文档类型，逗号分隔：question,material,course,chapter,knowledge_point,knowledge_detail,note"
+// @Param subject query string false "科目"
+// @Param exam_type query string false "考试类型"
+// @Param include_vip query bool false "是否包含 VIP 内容，默认 true"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Success 200 {object} Response
+// @Router /api/v1/learning-search [get]
+func (h *LearningSearchHandler) Search(c echo.Context) error {
+	query := service.LearningSearchQuery{
+		Keyword:  c.QueryParam("keyword"),
+		Subject:  c.QueryParam("subject"),
+		ExamType: c.QueryParam("exam_type"),
+	}
+	query.Page, _ = strconv.Atoi(c.QueryParam("page"))
+	query.PageSize, _ = strconv.Atoi(c.QueryParam("page_size"))
+
+	if raw := c.QueryParam("types"); raw != "" {
+		valid := make(map[string]bool, len(model.LearningDocTypes))
+		for _, t := range model.LearningDocTypes {
+			valid[t] = true
+		}
+		for _, t := range strings.Split(raw, ",") {
+			t = strings.TrimSpace(t)
+			if t == "" {
+				continue
+			}
+			if !valid[t] {
+				return fail(c, 400, "不支持的类型: "+t)
+			}
+			query.Types = append(query.Types, t)
+		}
+	}
+	if raw := c.QueryParam("include_vip"); raw != "" {
+		include, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fail(c, 400, "include_vip 参数错误")
+		}
+		query.ExcludeVIP = !include
+	}
+
+	result, err := h.learningSearchService.Search(getUserIDFromContext(c), query)
+	if err != nil {
+		return fail(c, 500, "搜索失败: "+err.Error())
+	}
+	return success(c, result)
+}
+
+// GetStatus 获取索引状态
+func (h *LearningSearchHandler) GetStatus(c echo.Context) error {
+	status, err := h.learningSearchService.GetStatus()
+	if err != nil {
+		return fail(c, 500, "获取索引状态失败: "+err.Error())
+	}
+	return success(c, status)
+}
+
+// Refresh 立即增量刷新
+func (h *LearningSearchHandler) Refresh(c echo.Context) error {
+	results, err := h.learningSearchService.Refresh(c.Request().Context())
+	if errors.Is(err, service.ErrLearningSearchRefreshing) {
+		return fail(c, 409, err.Error())
+	}
+	if err != nil {
+		return fail(c, 500, "刷新失败: "+err.Error())
+	}
+	return success(c, results)
+}
+
+// Rebuild 清空后全量重建（后台执行）
+func (h *LearningSearchHandler) Rebuild(c echo.Context) error {
+	status, err := h.learningSearchService.GetStatus()
+	if err != nil {
+		return fail(c, 500, "获取索引状态失败: "+err.Error())
+	}
+	if status.Refreshing {
+		return fail(c, 409, service.ErrLearningSearchRefreshing.Error())
+	}
+
+	go h.learningSearchService.Rebuild(context.Background())
+	return success(c, map[string]interface{}{
+		"message": "重建任务已启动",
+	})
+}
@@ -8,13 +8,19 @@ import (
 )
 
 type MatchHandler struct {
-	matchService *service.MatchService
+	matchService     *service.MatchService
+	admissionService *service.AdmissionService
 }
 
 func NewMatchHandler(matchService *service.MatchService) *MatchHandler {
 	return &MatchHandler{matchService: matchService}
 }
 
+// SetAdmissionService 设置上岸概率服务
+func (h *MatchHandler) SetAdmissionService(admissionService *service.AdmissionService) {
+	h.admissionService = admissionService
+}
+
 // GetMatchedPositions returns positions matched to user's profile
 // @Summary Get Matched Positions
 // @Description Get positions that match current user's profile and preferences
@@ -161,6 +167,91 @@ func (h *MatchHandler) GetPositionMatchDetail(c echo.Context) error {
 	return success(c, result)
 }
 
+// GetAdmissionReport returns admission probabilities for favorited or matched positions
+// @Summary Get Admission Probability Report
+// @Description Estimate the probability of making the interview list for favorited or matched positions, ranked by expected value
+// @Tags Match
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param source query string false "Position source: favorites, matched" default(favorites)
+// @Param limit query int false "Number of positions" default(20)
+// @Success 200 {object} Response
+// @Router /api/v1/match/admission [get]
+func (h *MatchHandler) GetAdmissionReport(c echo.Context) error {
+	if h.admissionService == nil {
+		return fail(c, 503, "Admission service not available")
+	}
+
+	userID := getUserIDFromContext(c)
+	if userID == 0 {
+		return fail(c, 401, "Unauthorized")
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	report, err := h.admissionService.GetReport(userID, c.QueryParam("source"), limit)
+	if err != nil {
+		if err == service.ErrNoScorePrediction {
+			return fail(c, 400, err.Error())
+		}
+		if err == service.ErrProfileNotFound {
+			return fail(c, 400, "Please complete your profile first")
+		}
+		return fail(c, 500, "Failed to estimate admission probability: "+err.Error())
+	}
+
+	return success(c, report)
+}
+
+// GetPositionAdmission returns the admission probability for a specific position
+// @Summary Get Position Admission Probability
+// @Description Estimate the probability of making the interview list for a specific position
+// @Tags Match
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Position ID"
+// @Success 200 {object} Response
+// @Router /api/v1/positions/{id}/admission [get]
+func (h *MatchHandler) GetPositionAdmission(c echo.Context) error {
+	if h.admissionService == nil {
+		return fail(c, 503, "Admission service not available")
+	}
+
+	userID := getUserIDFromContext(c)
+	if userID == 0 {
+		return fail(c, 401, "Unauthorized")
+	}
+
+	positionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return fail(c, 400, "Invalid position ID")
+	}
+
+	estimate, err := h.admissionService.EstimatePosition(userID, uint(positionID))
+	if err != nil {
+		if err == service.ErrNoScorePrediction {
+			return fail(c, 400, err.Error())
+		}
+		if err == service.ErrPositionNotFound {
+			return fail(c, 404, "Position not found")
+		}
+		if err == service.ErrInsufficientData {
+			return success(c, map[string]interface{}{
+				"has_estimate": false,
+				"message":      "Insufficient historical data for score line prediction",
+			})
+		}
+		return fail(c, 500, "Failed to estimate admission probability: "+err.Error())
+	}
+
+	return success(c, map[string]interface{}{
+		"has_estimate": true,
+		"estimate":     estimate,
+	})
+}
+
 // GetMatchStats returns match statistics by dimension
 // @Summary Get Match Statistics
 // @Description Get match statistics grouped by various dimensions
@@ -484,6 +575,7 @@ func (h *MatchHandler) RegisterRoutes(g *echo.Group, authMiddleware echo.Middlew
 	g.GET("/preview", h.GetMatchPreview, authMiddleware)
 	g.GET("/stats", h.GetMatchStats, authMiddleware)
 	g.GET("/report", h.GetMatchReport, authMiddleware)
+	g.GET("/admission", h.GetAdmissionReport, authMiddleware)
 
 	// Weights management
 	g.GET("/weights", h.GetMatchWeights, authMiddleware)
@@ -502,6 +594,7 @@ func (h *MatchHandler) RegisterRoutes(g *echo.Group, authMiddleware echo.Middlew
 // This should be called from the position handler registration
 func (h *MatchHandler) RegisterPositionMatchRoute(g *echo.Group, authMiddleware echo.MiddlewareFunc) {
 	g.GET("/:id/match", h.GetPositionMatchDetail, authMiddleware)
+	g.GET("/:id/admission", h.GetPositionAdmission, authMiddleware)
 }
 
 // GetRecommendedPositions returns recommended positions based on user profile
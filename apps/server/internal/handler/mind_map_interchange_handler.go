@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/what-cse/server/internal/model"
+	"github.com/what-cse/server/internal/service"
+)
+
+// mindMapUploadMaxSize 单次上传（单个导图或 zip 压缩包）大小上限
+const mindMapUploadMaxSize = 100 << 20
+
+// MindMapInterchangeHandler 思维导图导入导出处理器（XMind/FreeMind/OPML/Markdown）
+type MindMapInterchangeHandler struct {
+	mindMapService *service.MindMapService
+}
+
+// NewMindMapInterchangeHandler 创建思维导图导入导出处理器
+func NewMindMapInterchangeHandler(mindMapService *service.MindMapService) *MindMapInterchangeHandler {
+	return &MindMapInterchangeHandler{
+		mindMapService: mindMapService,
+	}
+}
+
+// RegisterRoutes 注册用户路由
+func (h *MindMapInterchangeHandler) RegisterRoutes(e *echo.Echo, authMiddleware echo.MiddlewareFunc) {
+	mindMaps := e.Group("/api/v1/mind-maps", authMiddleware)
+	mindMaps.GET("/:id/export", h.Export) // 导出导图文件
+}
+
+// RegisterAdminRoutes 注册管理员路由
+func (h *MindMapInterchangeHandler) RegisterAdminRoutes(g *echo.Group, authMiddleware echo.MiddlewareFunc) {
+	admin := g.Group("/knowledge-content/mind-maps", authMiddleware)
+	admin.POST("/import", h.Import) // 批量导入导图文件或 zip 压缩的目录
+}
+
+// Export 用户导出思维导图
+// @Summary 导出思维导图
+// @Tags MindMap
+// @Produce application/octet-stream
+// @Param id path int true "导图ID"
+// @Param format query string false "xmind/mm/opml/md，默认 xmind"
+// @Success 200 {file} file
+// @Router /api/v1/mind-maps/{id}/export [get]
+func (h *MindMapInterchangeHandler) Export(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == 0 {
+		return fail(c, 401, "请先登录")
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return fail(c, 400, "无效的导图ID")
+	}
+	format := c.QueryParam("format")
+	if format == "" {
+		format = "xmind"
+	}
+
+	file, err := h.mindMapService.ExportUserMindMap(userID, uint(id), format)
+	if err != nil {
+		return mindMapExportError(c, err)
+	}
+	return sendPrintFile(c, file)
+}
+
+// mindMapExportError 把导图导出错误映射为响应
+func mindMapExportError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrMindMapNotFound):
+		return fail(c, 404, err.Error())
+	case errors.Is(err, service.ErrMindMapFormat), errors.Is(err, service.ErrMindMapInvalidData),
+		errors.Is(err, service.ErrMindMapEmpty):
+		return fail(c, 400, err.Error())
+	default:
+		return fail(c, 500, "导出失败: "+err.Error())
+	}
+}
+
+// Import 批量导入思维导图
+// @Summary 导入 XMind/FreeMind/OPML/Markdown 导图，可一次上传多个文件或一个 zip 压缩的目录
+// @Tags MindMap
+// @Accept multipart/form-data
+// @Param files formData file true "导图文件（.xmind/.mm/.opml/.md）或 .zip"
+// @Param map_type formData string false "导图类型，默认 knowledge"
+// @Param category_id formData int false "分类ID"
+// @Param knowledge_point_id formData int false "知识点ID"
+// @Param is_public formData bool false "是否公开"
+// @Success 200 {object} Response
+// @Router /api/v1/admin/knowledge-content/mind-maps/import [post]
+func (h *MindMapInterchangeHandler) Import(c echo.Context) error {
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["files"]) == 0 {
+		return fail(c, 400, "请上传导图文件")
+	}
+
+	req := &service.ImportMindMapRequest{
+		MapType: model.MindMapType(c.FormValue("map_type")),
+	}
+	if id, err := strconv.ParseUint(c.FormValue("category_id"), 10, 32); err == nil && id > 0 {
+		categoryID := uint(id)
+		req.CategoryID = &categoryID
+	}
+	if id, err := strconv.ParseUint(c.FormValue("knowledge_point_id"), 10, 32); err == nil && id > 0 {
+		knowledgePointID := uint(id)
+		req.KnowledgePointID = &knowledgePointID
+	}
+	req.IsPublic, _ = strconv.ParseBool(c.FormValue("is_public"))
+	if tags := strings.TrimSpace(c.FormValue("tags")); tags != "" {
+		req.Tags = strings.Split(tags, ",")
+	}
+
+	// 上传的文件（zip 解压后）落到同一个临时目录，再按目录批量导入
+	tmpDir, err := os.MkdirTemp("", "mind-map-import-")
+	if err != nil {
+		return fail(c, 500, "创建临时目录失败")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	var total int64
+	for i, fh := range form.File["files"] {
+		total += fh.Size
+		if total > mindMapUploadMaxSize {
+			return fail(c, 400, fmt.Sprintf("上传文件合计超过 %dMB", mindMapUploadMaxSize>>20))
+		}
+		if err := saveMindMapUpload(fh, tmpDir, i); err != nil {
+			return fail(c, 400, err.Error())
+		}
+	}
+
+	result, err := h.mindMapService.ImportMindMapDir(getAdminID(c), tmpDir, req)
+	if err != nil {
+		return fail(c, 500, "导入失败: "+err.Error())
+	}
+	return success(c, result)
+}
+
+// saveMindMapUpload 把上传文件保存到目录，zip 压缩包解压到以包名命名的子目录，
+// 重名时加上序号，导入结果中的文件名即相对该目录的路径
+func saveMindMapUpload(fh *multipart.FileHeader, tmpDir string, index int) error {
+	src, err := fh.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	name := filepath.Base(fh.Filename)
+	isZip := strings.EqualFold(filepath.Ext(name), ".zip")
+	if isZip {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	path := filepath.Join(tmpDir, name)
+	if _, err := os.Stat(path); err == nil {
+		path = filepath.Join(tmpDir, fmt.Sprintf("%d-%s", index+1, name))
+	}
+	if !isZip {
+		return copyMindMapFile(path, src)
+	}
+
+	dir := path
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		return err
+	}
+	zr, err := zip.NewReader(src, fh.Size)
+	if err != nil {
+		return fmt.Errorf("无法解压 %s: %v", fh.Filename, err)
+	}
+	var extracted uint64
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		// 忽略目录穿越的条目
+		path := filepath.Join(dir, filepath.FromSlash(f.Name))
+		if !strings.HasPrefix(path, dir+string(os.PathSeparator)) {
+			continue
+		}
+		extracted += f.UncompressedSize64
+		if extracted > mindMapUploadMaxSize {
+			return fmt.Errorf("%s 解压后超过 %dMB", fh.Filename, mindMapUploadMaxSize>>20)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return err
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = copyMindMapFile(path, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyMindMapFile(path string, src io.Reader) error {
+	dst, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+	_, err = io.Copy(dst, io.LimitReader(src, mindMapUploadMaxSize))
+	return err
+}
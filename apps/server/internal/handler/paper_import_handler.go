@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/what-cse/server/internal/model"
+	"github.com/what-cse/server/internal/service"
+)
+
+// paperImportMaxFileSize 单个上传文件大小上限
+const paperImportMaxFileSize = 30 << 20
+
+// PaperImportHandler 真题试卷导入处理器
+type PaperImportHandler struct {
+	importService *service.PaperImportService
+}
+
+// NewPaperImportHandler 创建真题试卷导入处理器
+func NewPaperImportHandler(importService *service.PaperImportService) *PaperImportHandler {
+	return &PaperImportHandler{
+		importService: importService,
+	}
+}
+
+// RegisterAdminRoutes 注册管理员路由
+func (h *PaperImportHandler) RegisterAdminRoutes(g *echo.Group, authMiddleware echo.MiddlewareFunc) {
+	admin := g.Group("/paper-imports", authMiddleware)
+	{
+		admin.POST("", h.Import)              // 上传试卷（及答案）生成草稿
+		admin.GET("", h.List)                 // 导入记录
+		admin.GET("/:id", h.GetDetail)        // 草稿详情（分区、题目、材料）
+		admin.POST("/:id/publish", h.Publish) // 发布草稿试卷及题目
+		admin.DELETE("/:id", h.Discard)       // 放弃导入并删除草稿
+	}
+}
+
+// Import 上传试卷文件生成草稿试卷
+// @Summary 导入 Word/PDF 真题试卷
+// @Tags PaperImport
+// @Accept multipart/form-data
+// @Param paper formData file true "试卷文件 (.docx/.doc/.pdf)"
+// @Param answer formData file false "答案文件 (.docx/.doc/.pdf)"
+// @Param title formData string false "试卷标题，默认取试卷首行"
+// @Param category_id formData int false "默认分类，分区名匹配不到分类时使用"
+// @Param mode formData string false "auto/structural/llm，默认 auto"
+// @Success 200 {object} Response
+// @Router /api/v1/admin/paper-imports [post]
+func (h *PaperImportHandler) Import(c echo.Context) error {
+	paperFile, err := c.FormFile("paper")
+	if err != nil {
+		return fail(c, 400, "请上传试卷文件")
+	}
+
+	tmpDir, err := os.MkdirTemp("", "paper-import-")
+	if err != nil {
+		return fail(c, 500, "创建临时目录失败")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	paperPath, err := savePaperUpload(paperFile, tmpDir, "paper")
+	if err != nil {
+		return fail(c, 400, err.Error())
+	}
+
+	req := service.PaperImportRequest{
+		PaperPath:     paperPath,
+		PaperFileName: paperFile.Filename,
+		Title:         strings.TrimSpace(c.FormValue("title")),
+		PaperType:     model.PaperType(c.FormValue("paper_type")),
+		ExamType:      c.FormValue("exam_type"),
+		Subject:       c.FormValue("subject"),
+		Region:        c.FormValue("region"),
+		Mode:          c.FormValue("mode"),
+		AdminID:       getAdminID(c),
+	}
+	req.Year, _ = strconv.Atoi(c.FormValue("year"))
+	req.TimeLimit, _ = strconv.Atoi(c.FormValue("time_limit"))
+	req.TotalScore, _ = strconv.ParseFloat(c.FormValue("total_score"), 64)
+	if categoryID, err := strconv.ParseUint(c.FormValue("category_id"), 10, 32); err == nil {
+		req.CategoryID = uint(categoryID)
+	}
+
+	if answerFile, err := c.FormFile("answer"); err == nil {
+		answerPath, err := savePaperUpload(answerFile, tmpDir, "answer")
+		if err != nil {
+			return fail(c, 400, err.Error())
+		}
+		req.AnswerPath = answerPath
+		req.AnswerFileName = answerFile.Filename
+	}
+
+	detail, err := h.importService.Import(req)
+	if errors.Is(err, service.ErrPaperImportEmpty) || errors.Is(err, service.ErrPaperImportNoQuestion) ||
+		errors.Is(err, service.ErrPaperImportCategory) {
+		return fail(c, 400, err.Error())
+	}
+	if err != nil {
+		return fail(c, 500, "导入失败: "+err.Error())
+	}
+	return success(c, detail)
+}
+
+// savePaperUpload 把上传文件保存到临时目录，保留扩展名供解析器识别格式
+func savePaperUpload(fh *multipart.FileHeader, dir, name string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(fh.Filename))
+	switch ext {
+	case ".docx", ".doc", ".pdf":
+	default:
+		return "", fmt.Errorf("不支持的文件格式 %s，请上传 .docx/.doc/.pdf", ext)
+	}
+	if fh.Size > paperImportMaxFileSize {
+		return "", fmt.Errorf("文件 %s 超过 %dMB", fh.Filename, paperImportMaxFileSize>>20)
+	}
+
+	src, err := fh.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	path := filepath.Join(dir, name+ext)
+	dst, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, src); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// List 获取导入记录
+func (h *PaperImportHandler) List(c echo.Context) error {
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	pageSize, _ := strconv.Atoi(c.QueryParam("page_size"))
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	records, total, err := h.importService.List(c.QueryParam("status"), page, pageSize)
+	if err != nil {
+		return fail(c, 500, err.Error())
+	}
+	return success(c, map[string]interface{}{
+		"records": records,
+		"total":   total,
+	})
+}
+
+// GetDetail 获取草稿详情
+func (h *PaperImportHandler) GetDetail(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return fail(c, 400, "无效的ID")
+	}
+
+	detail, err := h.importService.GetDetail(uint(id))
+	if errors.Is(err, service.ErrPaperImportNotFound) {
+		return fail(c, 404, err.Error())
+	}
+	if err != nil {
+		return fail(c, 500, err.Error())
+	}
+	return success(c, detail)
+}
+
+// Publish 发布草稿试卷
+func (h *PaperImportHandler) Publish(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return fail(c, 400, "无效的ID")
+	}
+	if err := h.importService.Publish(uint(id)); err != nil {
+		return h.resolveError(c, err)
+	}
+	return success(c, map[string]interface{}{
+		"message": "试卷已发布",
+	})
+}
+
+// Discard 放弃导入
+func (h *PaperImportHandler) Discard(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return fail(c, 400, "无效的ID")
+	}
+	if err := h.importService.Discard(uint(id)); err != nil {
+		return h.resolveError(c, err)
+	}
+	return success(c, map[string]interface{}{
+		"message": "草稿已删除",
+	})
+}
+
+func (h *PaperImportHandler) resolveError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrPaperImportNotFound), errors.Is(err, service.ErrPaperNotFound):
+		return fail(c, 404, err.Error())
+	case errors.Is(err, service.ErrPaperImportNotDraft):
+		return fail(c, 400, err.Error())
+	default:
+		return fail(c, 500, err.Error())
+	}
+}
@@ -11,6 +11,7 @@ import (
 type PositionHandler struct {
 	positionService *service.PositionService
 	matchService    *service.MatchService
+	forecastService *service.RegistrationForecastService
 }
 
 func NewPositionHandler(positionService *service.PositionService) *PositionHandler {
@@ -22,6 +23,11 @@ func (h *PositionHandler) SetMatchService(matchService *service.MatchService) {
 	h.matchService = matchService
 }
 
+// SetForecastService 设置报名预测服务（用于竞争比预测功能）
+func (h *PositionHandler) SetForecastService(forecastService *service.RegistrationForecastService) {
+	h.forecastService = forecastService
+}
+
 // ListPositions returns a paginated list of positions
 // @Summary List Positions
 // @Description Get a paginated list of positions with filters
@@ -81,10 +87,52 @@ func (h *PositionHandler) GetPosition(c echo.Context) error {
 		isFavorite = h.positionService.IsFavorite(userID, uint(id))
 	}
 
-	return success(c, map[string]interface{}{
+	result := map[string]interface{}{
 		"position":    position,
 		"is_favorite": isFavorite,
-	})
+	}
+
+	// 报名期内附带最终竞争比预测，预测结果由服务缓存
+	if h.forecastService != nil {
+		if forecast, err := h.forecastService.ForecastByID(uint(id)); err == nil {
+			result["competition_forecast"] = forecast
+		}
+	}
+
+	return success(c, result)
+}
+
+// GetPositionForecast returns the final competition ratio forecast of a position
+// @Summary Get Position Competition Forecast
+// @Description Forecast final applicant count and competition ratio with confidence bands
+// @Tags Position
+// @Accept json
+// @Produce json
+// @Param id path int true "Position ID"
+// @Success 200 {object} Response
+// @Router /api/v1/positions/{id}/forecast [get]
+func (h *PositionHandler) GetPositionForecast(c echo.Context) error {
+	if h.forecastService == nil {
+		return fail(c, 503, "Forecast service not available")
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return fail(c, 400, "Invalid position ID")
+	}
+
+	forecast, err := h.forecastService.ForecastByID(uint(id))
+	if err != nil {
+		if err == service.ErrPositionNotFound {
+			return fail(c, 404, "Position not found")
+		}
+		if err == service.ErrForecastNoWindow {
+			return fail(c, 400, "Position has no registration window")
+		}
+		return fail(c, 500, "Failed to forecast position: "+err.Error())
+	}
+
+	return success(c, forecast)
 }
 
 // AddFavorite adds a position to user's favorites
@@ -772,6 +820,7 @@ func (h *PositionHandler) RegisterRoutes(g *echo.Group, authMiddleware echo.Midd
 	g.GET("/latest", h.GetLatestPositions)
 	g.GET("/:id", h.GetPosition)
 	g.GET("/:id/similar", h.GetSimilarPositions)
+	g.GET("/:id/forecast", h.GetPositionForecast)
 	g.POST("/compare", h.ComparePositions)
 
 	// 需要登录的路由
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"strconv"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 	"github.com/what-cse/server/internal/repository"
@@ -488,6 +489,36 @@ func (h *PositionHistoryHandler) AdminImportHistories(c echo.Context) error {
 	})
 }
 
+// AdminBacktestScorePrediction runs a rolling backtest of the score line predictor
+// @Summary Backtest Score Prediction
+// @Description Fit the score line model on earlier years and report error on held-out years
+// @Tags History
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param exam_type query string false "Exam type"
+// @Param years query string false "Held-out years, comma separated"
+// @Success 200 {object} Response
+// @Router /api/v1/admin/history/score-prediction/backtest [get]
+func (h *PositionHistoryHandler) AdminBacktestScorePrediction(c echo.Context) error {
+	var years []int
+	for _, y := range strings.Split(c.QueryParam("years"), ",") {
+		if year, err := strconv.Atoi(strings.TrimSpace(y)); err == nil {
+			years = append(years, year)
+		}
+	}
+
+	report, err := h.historyService.BacktestScoreLine(c.QueryParam("exam_type"), years)
+	if err != nil {
+		if err == service.ErrInsufficientData {
+			return fail(c, 400, "Insufficient historical data for backtest")
+		}
+		return fail(c, 500, "Failed to run backtest: "+err.Error())
+	}
+
+	return success(c, report)
+}
+
 // =====================================================
 // 路由注册
 // =====================================================
@@ -529,4 +560,5 @@ func (h *PositionHistoryHandler) RegisterAdminRoutes(g *echo.Group, adminAuthMid
 	historyGroup.DELETE("/:id", h.AdminDeleteHistory)
 	historyGroup.POST("/batch-delete", h.AdminBatchDeleteHistories)
 	historyGroup.POST("/import", h.AdminImportHistories)
+	historyGroup.GET("/score-prediction/backtest", h.AdminBacktestScorePrediction)
 }
@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/what-cse/server/internal/service"
+)
+
+// positionImportMaxFileSize 职位表文件大小上限
+const positionImportMaxFileSize = 50 << 20
+
+// PositionImportHandler 职位表导入处理器（列映射方案、预览、导入）
+type PositionImportHandler struct {
+	importService *service.PositionImportService
+}
+
+// NewPositionImportHandler 创建职位表导入处理器
+func NewPositionImportHandler(importService *service.PositionImportService) *PositionImportHandler {
+	return &PositionImportHandler{
+		importService: importService,
+	}
+}
+
+// RegisterAdminRoutes 注册管理员路由
+func (h *PositionImportHandler) RegisterAdminRoutes(g *echo.Group, authMiddleware echo.MiddlewareFunc) {
+	admin := g.Group("/position-imports", authMiddleware)
+	{
+		admin.GET("/profiles", h.ListProfiles)         // 列映射方案列表
+		admin.POST("/profiles", h.CreateProfile)       // 创建方案
+		admin.PUT("/profiles/:id", h.UpdateProfile)    // 更新方案
+		admin.DELETE("/profiles/:id", h.DeleteProfile) // 删除方案
+		admin.POST("/preview", h.Preview)              // 上传职位表，预览表头映射和样例
+		admin.POST("", h.Import)                       // 上传职位表导入为待审核职位
+	}
+}
+
+// ListProfiles 列映射方案列表
+// @Summary 职位表列映射方案列表
+// @Tags PositionImport
+// @Success 200 {object} Response
+// @Router /api/v1/admin/position-imports/profiles [get]
+func (h *PositionImportHandler) ListProfiles(c echo.Context) error {
+	profiles, err := h.importService.ListProfiles()
+	if err != nil {
+		return fail(c, 500, "获取方案失败: "+err.Error())
+	}
+	return success(c, profiles)
+}
+
+// CreateProfile 创建列映射方案
+// @Summary 创建职位表列映射方案
+// @Tags PositionImport
+// @Accept json
+// @Param request body service.PositionColumnProfileRequest true "方案"
+// @Success 200 {object} Response
+// @Router /api/v1/admin/position-imports/profiles [post]
+func (h *PositionImportHandler) CreateProfile(c echo.Context) error {
+	var req service.PositionColumnProfileRequest
+	if err := c.Bind(&req); err != nil {
+		return fail(c, 400, "参数错误")
+	}
+	profile, err := h.importService.CreateProfile(getAdminID(c), &req)
+	if err != nil {
+		return positionImportError(c, err)
+	}
+	return success(c, profile)
+}
+
+// UpdateProfile 更新列映射方案
+// @Summary 更新职位表列映射方案
+// @Tags PositionImport
+// @Accept json
+// @Param id path int true "方案ID"
+// @Param request body service.PositionColumnProfileRequest true "方案"
+// @Success 200 {object} Response
+// @Router /api/v1/admin/position-imports/profiles/{id} [put]
+func (h *PositionImportHandler) UpdateProfile(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return fail(c, 400, "无效的方案ID")
+	}
+	var req service.PositionColumnProfileRequest
+	if err := c.Bind(&req); err != nil {
+		return fail(c, 400, "参数错误")
+	}
+	profile, err := h.importService.UpdateProfile(uint(id), &req)
+	if err != nil {
+		return positionImportError(c, err)
+	}
+	return success(c, profile)
+}
+
+// DeleteProfile 删除列映射方案
+// @Summary 删除职位表列映射方案
+// @Tags PositionImport
+// @Param id path int true "方案ID"
+// @Success 200 {object} Response
+// @Router /api/v1/admin/position-imports/profiles/{id} [delete]
+func (h *PositionImportHandler) DeleteProfile(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return fail(c, 400, "无效的方案ID")
+	}
+	if err := h.importService.DeleteProfile(uint(id)); err != nil {
+		return positionImportError(c, err)
+	}
+	return success(c, nil)
+}
+
+// Preview 预览职位表解析结果
+// @Summary 预览职位表的表头识别、列映射和样例职位
+// @Tags PositionImport
+// @Accept multipart/form-data
+// @Param file formData file true "职位表 (.xlsx/.xls/.html/扫描版 .pdf)"
+// @Param source_url formData string false "公告链接，用于匹配方案"
+// @Param exam_type formData string false "考试类型，用于匹配方案"
+// @Param profile_id formData int false "指定方案，默认自动匹配"
+// @Param columns formData string false "临时列映射 JSON，如 {\"招录条件/其他\":\"other_requirements\"}"
+// @Param header_row formData int false "表头起始行"
+// @Param header_rows formData int false "表头行数"
+// @Success 200 {object} Response
+// @Router /api/v1/admin/position-imports/preview [post]
+func (h *PositionImportHandler) Preview(c echo.Context) error {
+	req, cleanup, err := positionFileRequest(c)
+	if err != nil {
+		return fail(c, 400, err.Error())
+	}
+	defer cleanup()
+
+	preview, err := h.importService.Preview(req)
+	if err != nil {
+		return positionImportError(c, err)
+	}
+	return success(c, preview)
+}
+
+// Import 导入职位表
+// @Summary 导入职位表为待审核职位，参数同预览
+// @Tags PositionImport
+// @Accept multipart/form-data
+// @Param file formData file true "职位表 (.xlsx/.xls/.html/扫描版 .pdf)"
+// @Param announcement_id formData int false "关联公告ID"
+// @Success 200 {object} Response
+// @Router /api/v1/admin/position-imports [post]
+func (h *PositionImportHandler) Import(c echo.Context) error {
+	req, cleanup, err := positionFileRequest(c)
+	if err != nil {
+		return fail(c, 400, err.Error())
+	}
+	defer cleanup()
+
+	result, err := h.importService.Import(req)
+	if err != nil {
+		return positionImportError(c, err)
+	}
+	return success(c, result)
+}
+
+// positionFileRequest 读取上传的职位表和表单参数，返回的 cleanup 删除临时文件
+func positionFileRequest(c echo.Context) (*service.PositionFileRequest, func(), error) {
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return nil, nil, errors.New("请上传职位表文件")
+	}
+	if fh.Size > positionImportMaxFileSize {
+		return nil, nil, fmt.Errorf("文件超过 %dMB", positionImportMaxFileSize>>20)
+	}
+
+	req := &service.PositionFileRequest{
+		FileName:  filepath.Base(fh.Filename),
+		SourceURL: strings.TrimSpace(c.FormValue("source_url")),
+		ExamType:  strings.TrimSpace(c.FormValue("exam_type")),
+		AdminID:   getAdminID(c),
+	}
+	if id, err := strconv.ParseUint(c.FormValue("profile_id"), 10, 32); err == nil {
+		req.ProfileID = uint(id)
+	}
+	if id, err := strconv.ParseUint(c.FormValue("announcement_id"), 10, 32); err == nil && id > 0 {
+		announcementID := uint(id)
+		req.AnnouncementID = &announcementID
+	}
+	req.HeaderRow, _ = strconv.Atoi(c.FormValue("header_row"))
+	req.HeaderRows, _ = strconv.Atoi(c.FormValue("header_rows"))
+	if columns := strings.TrimSpace(c.FormValue("columns")); columns != "" {
+		if err := json.Unmarshal([]byte(columns), &req.Columns); err != nil {
+			return nil, nil, errors.New("列映射格式错误")
+		}
+	}
+
+	src, err := fh.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer src.Close()
+	// 保留扩展名，解析器按扩展名识别格式
+	dst, err := os.CreateTemp("", "position-import-*"+strings.ToLower(filepath.Ext(fh.Filename)))
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { os.Remove(dst.Name()) }
+	_, err = io.Copy(dst, src)
+	dst.Close()
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	req.FilePath = dst.Name()
+	return req, cleanup, nil
+}
+
+// positionImportError 把职位表导入错误映射为响应
+func positionImportError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrColumnProfileNotFound):
+		return fail(c, 404, err.Error())
+	case errors.Is(err, service.ErrColumnProfileName), errors.Is(err, service.ErrColumnProfileField),
+		errors.Is(err, service.ErrPositionFileFormat), errors.Is(err, service.ErrPositionFileNoTable),
+		errors.Is(err, service.ErrPositionFileColumns), errors.Is(err, service.ErrPositionOCRDisabled):
+		return fail(c, 400, err.Error())
+	default:
+		return fail(c, 500, "操作失败: "+err.Error())
+	}
+}
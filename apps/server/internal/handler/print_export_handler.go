@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/what-cse/server/internal/service"
+)
+
+// PrintExportHandler 试卷打印导出处理器
+type PrintExportHandler struct {
+	printService *service.PrintExportService
+}
+
+// NewPrintExportHandler 创建试卷打印导出处理器
+func NewPrintExportHandler(printService *service.PrintExportService) *PrintExportHandler {
+	return &PrintExportHandler{
+		printService: printService,
+	}
+}
+
+// RegisterRoutes 注册路由
+// rateLimit 按用户限制导出频率，须在 authMiddleware 之后执行
+func (h *PrintExportHandler) RegisterRoutes(e *echo.Echo, authMiddleware, rateLimit echo.MiddlewareFunc) {
+	papers := e.Group("/api/v1/papers", authMiddleware)
+	papers.GET("/:id/export", h.ExportPaper, rateLimit) // 导出可打印试卷（PDF/DOCX）
+}
+
+// ExportPaper 导出可打印试卷
+// @Summary 导出试卷 PDF/DOCX
+// @Tags Paper
+// @Produce application/pdf
+// @Param id path int true "试卷ID"
+// @Param format query string false "pdf/docx/html，默认 pdf"
+// @Param answer_mode query string false "inline/appendix/hidden，默认 appendix"
+// @Param answer_sheet query bool false "是否附答题卡"
+// @Param watermark query bool false "是否加用户ID水印"
+// @Success 200 {file} file
+// @Router /api/v1/papers/{id}/export [get]
+func (h *PrintExportHandler) ExportPaper(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return fail(c, 400, "无效的试卷ID")
+	}
+
+	opts := service.PrintExportOptions{
+		Format:     c.QueryParam("format"),
+		AnswerMode: c.QueryParam("answer_mode"),
+	}
+	opts.AnswerSheet, _ = strconv.ParseBool(c.QueryParam("answer_sheet"))
+	opts.Watermark, _ = strconv.ParseBool(c.QueryParam("watermark"))
+
+	file, err := h.printService.ExportPaper(c.Request().Context(), uint(id), getUserIDFromContext(c), opts)
+	if err != nil {
+		return printExportError(c, err)
+	}
+	return sendPrintFile(c, file)
+}
+
+// printExportError 把导出错误映射为响应
+func printExportError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrPaperNotFound):
+		return fail(c, 404, err.Error())
+	case errors.Is(err, service.ErrPaperNotPublished), errors.Is(err, service.ErrPrintExportFormat),
+		errors.Is(err, service.ErrPrintExportEmpty), errors.Is(err, service.ErrPrintExportTooMany):
+		return fail(c, 400, err.Error())
+	case errors.Is(err, service.ErrPrintExportUnavailable):
+		return fail(c, 503, err.Error())
+	default:
+		return fail(c, 500, "导出失败: "+err.Error())
+	}
+}
+
+// sendPrintFile 以附件形式返回文件，中文文件名按 RFC 5987 编码
+func sendPrintFile(c echo.Context, file *service.PrintFile) error {
+	fallback := "export" + filepath.Ext(file.Filename)
+	c.Response().Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment; filename=%q; filename*=UTF-8''%s", fallback, url.PathEscape(file.Filename)))
+	return c.Blob(http.StatusOK, file.ContentType, file.Data)
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/what-cse/server/internal/model"
+	"github.com/what-cse/server/internal/service"
+	"gorm.io/gorm"
+)
+
+// QuestionDedupHandler 题库查重管理处理器
+type QuestionDedupHandler struct {
+	dedupService *service.QuestionDedupService
+}
+
+// NewQuestionDedupHandler 创建题库查重管理处理器
+func NewQuestionDedupHandler(dedupService *service.QuestionDedupService) *QuestionDedupHandler {
+	return &QuestionDedupHandler{
+		dedupService: dedupService,
+	}
+}
+
+// RegisterAdminRoutes 注册管理员路由
+func (h *QuestionDedupHandler) RegisterAdminRoutes(g *echo.Group, authMiddleware echo.MiddlewareFunc) {
+	admin := g.Group("/question-dedup", authMiddleware)
+	{
+		admin.GET("/stats", h.GetStats)               // 签名与题对统计
+		admin.GET("/clusters", h.GetClusters)         // 疑似重复簇
+		admin.POST("/clusters/merge", h.Merge)        // 合并簇内题目
+		admin.POST("/pairs/:id/ignore", h.IgnorePair) // 确认题对不是重复
+		admin.POST("/check", h.Check)                 // 预检一道题
+		admin.POST("/backfill", h.Backfill)           // 立即补齐签名（后台执行）
+	}
+}
+
+// GetStats 获取查重统计
+func (h *QuestionDedupHandler) GetStats(c echo.Context) error {
+	stats, err := h.dedupService.Stats()
+	if err != nil {
+		return fail(c, 500, err.Error())
+	}
+	return success(c, stats)
+}
+
+// GetClusters 获取疑似重复簇
+// @Param limit query int false "最多返回簇数，默认 50"
+func (h *QuestionDedupHandler) GetClusters(c echo.Context) error {
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+	clusters, err := h.dedupService.Clusters(limit)
+	if err != nil {
+		return fail(c, 500, err.Error())
+	}
+	return success(c, map[string]interface{}{
+		"clusters": clusters,
+		"total":    len(clusters),
+	})
+}
+
+// MergeQuestionsRequest 合并题目请求
+type MergeQuestionsRequest struct {
+	KeepID   uint   `json:"keep_id"`
+	MergeIDs []uint `json:"merge_ids"`
+}
+
+// Merge 把重复题合并到保留题：做题记录、收藏、错题迁移，被合并题目归档
+func (h *QuestionDedupHandler) Merge(c echo.Context) error {
+	var req MergeQuestionsRequest
+	if err := c.Bind(&req); err != nil {
+		return fail(c, 400, "参数错误")
+	}
+
+	err := h.dedupService.Merge(req.KeepID, req.MergeIDs, getAdminID(c))
+	if errors.Is(err, service.ErrQuestionDedupInvalidMerge) {
+		return fail(c, 400, err.Error())
+	}
+	if errors.Is(err, service.ErrQuestionNotFound) {
+		return fail(c, 404, err.Error())
+	}
+	if err != nil {
+		return fail(c, 500, err.Error())
+	}
+	return success(c, map[string]interface{}{
+		"message": "合并成功",
+		"keep_id": req.KeepID,
+		"merged":  len(req.MergeIDs),
+	})
+}
+
+// IgnorePair 确认题对不是重复
+func (h *QuestionDedupHandler) IgnorePair(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return fail(c, 400, "无效的ID")
+	}
+
+	err = h.dedupService.Ignore(uint(id), getAdminID(c))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fail(c, 404, "题对不存在")
+	}
+	if errors.Is(err, service.ErrQuestionDuplicateResolved) {
+		return fail(c, 400, err.Error())
+	}
+	if err != nil {
+		return fail(c, 500, err.Error())
+	}
+	return success(c, map[string]interface{}{
+		"message": "已忽略",
+	})
+}
+
+// CheckQuestionRequest 预检题目请求
+type CheckQuestionRequest struct {
+	Content string                 `json:"content"`
+	Options []model.QuestionOption `json:"options"`
+	Limit   int                    `json:"limit"`
+}
+
+// Check 预检一道题与库中题目的相似度，不入库
+func (h *QuestionDedupHandler) Check(c echo.Context) error {
+	var req CheckQuestionRequest
+	if err := c.Bind(&req); err != nil || req.Content == "" {
+		return fail(c, 400, "题目内容不能为空")
+	}
+
+	matches, err := h.dedupService.CheckText(req.Content, req.Options, req.Limit)
+	if err != nil {
+		return fail(c, 500, err.Error())
+	}
+	return success(c, map[string]interface{}{
+		"matches": matches,
+	})
+}
+
+// Backfill 立即补齐题目签名并扫描存量重复
+func (h *QuestionDedupHandler) Backfill(c echo.Context) error {
+	go h.dedupService.Sync(context.Background())
+	return success(c, map[string]interface{}{
+		"message": "签名补齐任务已启动",
+	})
+}
@@ -798,13 +798,17 @@ func (h *QuestionHandler) AdminBatchCreateQuestions(c echo.Context) error {
 		return fail(c, 400, "Invalid request parameters")
 	}
 
-	if err := h.questionService.BatchCreateQuestions(questions); err != nil {
+	report, err := h.questionService.ImportQuestions(questions)
+	if err != nil {
 		return fail(c, 500, "Failed to create questions: "+err.Error())
 	}
 
 	return success(c, map[string]interface{}{
-		"message": "批量创建成功",
-		"count":   len(questions),
+		"message":    "批量创建成功",
+		"count":      report.Created,
+		"rejected":   report.Rejected,
+		"flagged":    report.Flagged,
+		"duplicates": report.Items,
 	})
 }
 
@@ -1269,14 +1273,17 @@ func (h *QuestionHandler) AdminSaveAIGeneratedQuestions(c echo.Context) error {
 	}
 
 	// 批量创建
-	err := h.questionService.BatchCreateQuestions(questions)
+	report, err := h.questionService.ImportQuestions(questions)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "保存失败: " + err.Error()})
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"success": len(questions),
-		"total":   len(req.Questions),
+		"success":    report.Created,
+		"total":      len(req.Questions),
+		"rejected":   report.Rejected,
+		"flagged":    report.Flagged,
+		"duplicates": report.Items,
 	})
 }
 
@@ -8,13 +8,19 @@ import (
 )
 
 type RegistrationDataHandler struct {
-	regDataService *service.RegistrationDataService
+	regDataService  *service.RegistrationDataService
+	forecastService *service.RegistrationForecastService
 }
 
 func NewRegistrationDataHandler(regDataService *service.RegistrationDataService) *RegistrationDataHandler {
 	return &RegistrationDataHandler{regDataService: regDataService}
 }
 
+// SetForecastService 设置报名预测服务（用于竞争比预测功能）
+func (h *RegistrationDataHandler) SetForecastService(forecastService *service.RegistrationForecastService) {
+	h.forecastService = forecastService
+}
+
 // GetOverview 获取报名数据总览
 // @Summary Get Registration Data Overview
 // @Description Get overall registration data statistics including hot positions
@@ -271,6 +277,67 @@ func (h *RegistrationDataHandler) CleanOldData(c echo.Context) error {
 	return success(c, map[string]string{"message": "Old data cleaned successfully"})
 }
 
+// GetPositionForecast 获取单个职位的最终竞争比预测
+// @Summary Get Position Competition Forecast
+// @Description Forecast final applicant count and competition ratio for a position during registration
+// @Tags Registration Data
+// @Accept json
+// @Produce json
+// @Param id path string true "Position ID"
+// @Success 200 {object} Response
+// @Router /api/v1/registration-data/position/{id}/forecast [get]
+func (h *RegistrationDataHandler) GetPositionForecast(c echo.Context) error {
+	if h.forecastService == nil {
+		return fail(c, 503, "Forecast service not available")
+	}
+
+	positionID := c.Param("id")
+	if positionID == "" {
+		return fail(c, 400, "Position ID is required")
+	}
+
+	result, err := h.forecastService.ForecastByPositionID(positionID)
+	if err != nil {
+		if err == service.ErrPositionNotFound {
+			return fail(c, 404, "Position not found")
+		}
+		if err == service.ErrForecastNoWindow {
+			return fail(c, 400, "Position has no registration window")
+		}
+		return fail(c, 500, "Failed to forecast position: "+err.Error())
+	}
+
+	return success(c, result)
+}
+
+// GetLikelyColdPositions 获取预测冷门职位
+// @Summary Get Likely Cold Positions
+// @Description Get registering positions whose forecast final competition ratio stays low
+// @Tags Registration Data
+// @Accept json
+// @Produce json
+// @Param max_ratio query number false "Maximum forecast competition ratio" default(10)
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} Response
+// @Router /api/v1/registration-data/cold/forecast [get]
+func (h *RegistrationDataHandler) GetLikelyColdPositions(c echo.Context) error {
+	if h.forecastService == nil {
+		return fail(c, 503, "Forecast service not available")
+	}
+
+	maxRatio, _ := strconv.ParseFloat(c.QueryParam("max_ratio"), 64)
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	pageSize, _ := strconv.Atoi(c.QueryParam("page_size"))
+
+	result, err := h.forecastService.GetLikelyColdPositions(maxRatio, page, pageSize)
+	if err != nil {
+		return fail(c, 500, "Failed to fetch likely cold positions: "+err.Error())
+	}
+
+	return success(c, result)
+}
+
 // RegisterRoutes 注册公开路由
 func (h *RegistrationDataHandler) RegisterRoutes(g *echo.Group) {
 	regDataGroup := g.Group("/registration-data")
@@ -281,8 +348,10 @@ func (h *RegistrationDataHandler) RegisterRoutes(g *echo.Group) {
 	regDataGroup.GET("/hot/competition-ratio", h.GetHotPositionsByCompetitionRatio)
 	regDataGroup.GET("/cold/no-applicant", h.GetNoApplicantPositions)
 	regDataGroup.GET("/cold/low-competition", h.GetLowCompetitionPositions)
+	regDataGroup.GET("/cold/forecast", h.GetLikelyColdPositions)
 	regDataGroup.GET("/trends", h.GetRegistrationTrends)
 	regDataGroup.GET("/position/:id/trends", h.GetPositionTrends)
+	regDataGroup.GET("/position/:id/forecast", h.GetPositionForecast)
 	regDataGroup.GET("/stats/province", h.GetStatsByProvince)
 	regDataGroup.GET("/stats/exam-type", h.GetStatsByExamType)
 }
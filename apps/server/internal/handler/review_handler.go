@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/what-cse/server/internal/service"
+	"github.com/what-cse/server/internal/srs"
+)
+
+// ReviewHandler 间隔重复复习处理器（速记卡片与错题的统一复习队列）
+type ReviewHandler struct {
+	reviewScheduler  *service.ReviewSchedulerService
+	flashCardService *service.FlashCardService
+	studyNoteService *service.StudyNoteService
+}
+
+// NewReviewHandler 创建复习处理器
+func NewReviewHandler(
+	reviewScheduler *service.ReviewSchedulerService,
+	flashCardService *service.FlashCardService,
+	studyNoteService *service.StudyNoteService,
+) *ReviewHandler {
+	return &ReviewHandler{
+		reviewScheduler:  reviewScheduler,
+		flashCardService: flashCardService,
+		studyNoteService: studyNoteService,
+	}
+}
+
+// ReviewRatingRequest 复习评分请求
+type ReviewRatingRequest struct {
+	Rating int `json:"rating"` // 1=忘记 2=困难 3=良好 4=简单
+}
+
+// RegisterRoutes 注册用户路由
+func (h *ReviewHandler) RegisterRoutes(e *echo.Echo, authMiddleware echo.MiddlewareFunc) {
+	reviews := e.Group("/api/v1/reviews", authMiddleware)
+	{
+		reviews.GET("/today", h.GetTodayQueue)                      // 今日复习队列
+		reviews.POST("/flash-cards/:id", h.ReviewFlashCard)         // 复习速记卡片
+		reviews.POST("/wrong-questions/:id", h.ReviewWrongQuestion) // 复习错题
+		reviews.GET("/settings", h.GetSettings)                     // 复习设置
+		reviews.PUT("/settings", h.UpdateSettings)                  // 更新复习设置
+		reviews.POST("/optimize", h.Optimize)                       // 优化个人参数
+		reviews.GET("/stats", h.GetStats)                           // 复习统计
+	}
+}
+
+// GetTodayQueue 获取今日复习队列
+// @Summary 今日复习队列
+// @Tags Review
+// @Success 200 {object} Response
+// @Router /api/v1/reviews/today [get]
+func (h *ReviewHandler) GetTodayQueue(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == 0 {
+		return fail(c, 401, "请先登录")
+	}
+
+	queue, err := h.reviewScheduler.TodayQueue(userID)
+	if err != nil {
+		return fail(c, 500, err.Error())
+	}
+	return success(c, queue)
+}
+
+// ReviewFlashCard 提交速记卡片复习评分
+// @Summary 复习速记卡片
+// @Tags Review
+// @Param id path int true "卡片ID"
+// @Success 200 {object} Response
+// @Router /api/v1/reviews/flash-cards/{id} [post]
+func (h *ReviewHandler) ReviewFlashCard(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == 0 {
+		return fail(c, 401, "请先登录")
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return fail(c, 400, "无效的卡片ID")
+	}
+	var req ReviewRatingRequest
+	if err := c.Bind(&req); err != nil {
+		return fail(c, 400, "参数错误")
+	}
+
+	result, err := h.flashCardService.ReviewCard(userID, uint(id), srs.Rating(req.Rating))
+	if err != nil {
+		return h.reviewError(c, err)
+	}
+	return success(c, result)
+}
+
+// ReviewWrongQuestion 提交错题复习评分
+// @Summary 复习错题
+// @Tags Review
+// @Param id path int true "错题记录ID"
+// @Success 200 {object} Response
+// @Router /api/v1/reviews/wrong-questions/{id} [post]
+func (h *ReviewHandler) ReviewWrongQuestion(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == 0 {
+		return fail(c, 401, "请先登录")
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return fail(c, 400, "无效的错题ID")
+	}
+	var req ReviewRatingRequest
+	if err := c.Bind(&req); err != nil {
+		return fail(c, 400, "参数错误")
+	}
+	if !srs.Rating(req.Rating).Valid() {
+		return fail(c, 400, service.ErrInvalidReviewRating.Error())
+	}
+
+	result, err := h.studyNoteService.RecordReview(uint(id), userID, req.Rating > int(srs.Again), req.Rating)
+	if err != nil {
+		return h.reviewError(c, err)
+	}
+	return success(c, result)
+}
+
+func (h *ReviewHandler) reviewError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidReviewRating):
+		return fail(c, 400, err.Error())
+	case errors.Is(err, service.ErrFlashCardNotFound), errors.Is(err, service.ErrWrongQuestionNotFound):
+		return fail(c, 404, err.Error())
+	}
+	return fail(c, 500, err.Error())
+}
+
+// GetSettings 获取复习设置
+// @Summary 获取复习设置
+// @Tags Review
+// @Success 200 {object} Response
+// @Router /api/v1/reviews/settings [get]
+func (h *ReviewHandler) GetSettings(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == 0 {
+		return fail(c, 401, "请先登录")
+	}
+
+	settings, err := h.reviewScheduler.GetSettings(userID)
+	if err != nil {
+		return fail(c, 500, err.Error())
+	}
+	return success(c, settings)
+}
+
+// UpdateSettings 更新复习算法与目标记忆保持率
+// @Summary 更新复习设置
+// @Tags Review
+// @Success 200 {object} Response
+// @Router /api/v1/reviews/settings [put]
+func (h *ReviewHandler) UpdateSettings(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == 0 {
+		return fail(c, 401, "请先登录")
+	}
+	var req service.UpdateReviewSettingsRequest
+	if err := c.Bind(&req); err != nil {
+		return fail(c, 400, "参数错误")
+	}
+
+	settings, err := h.reviewScheduler.UpdateSettings(userID, &req)
+	if errors.Is(err, service.ErrInvalidReviewAlgorithm) || errors.Is(err, service.ErrInvalidRequestRetention) {
+		return fail(c, 400, err.Error())
+	}
+	if err != nil {
+		return fail(c, 500, err.Error())
+	}
+	return success(c, settings)
+}
+
+// Optimize 用复习日志优化个人调度参数
+// @Summary 优化个人复习参数
+// @Tags Review
+// @Success 200 {object} Response
+// @Router /api/v1/reviews/optimize [post]
+func (h *ReviewHandler) Optimize(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == 0 {
+		return fail(c, 401, "请先登录")
+	}
+
+	settings, err := h.reviewScheduler.Optimize(context.Background(), userID)
+	if errors.Is(err, service.ErrNotEnoughReviews) {
+		return fail(c, 400, err.Error())
+	}
+	if err != nil {
+		return fail(c, 500, err.Error())
+	}
+	return success(c, settings)
+}
+
+// GetStats 获取复习统计
+// @Summary 复习统计
+// @Tags Review
+// @Success 200 {object} Response
+// @Router /api/v1/reviews/stats [get]
+func (h *ReviewHandler) GetStats(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == 0 {
+		return fail(c, 401, "请先登录")
+	}
+
+	stats, err := h.reviewScheduler.Stats(userID)
+	if err != nil {
+		return fail(c, 500, err.Error())
+	}
+	return success(c, stats)
+}
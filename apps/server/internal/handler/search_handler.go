@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"context"
 	"net/http"
 	"strconv"
+	"sync/atomic"
 
 	"github.com/labstack/echo/v4"
 
@@ -10,12 +12,13 @@ import (
 )
 
 type SearchHandler struct {
-	searchService *service.SearchService
+	router      *service.SearchRouter
+	syncService atomic.Pointer[service.SearchSyncService]
 }
 
-func NewSearchHandler(searchService *service.SearchService) *SearchHandler {
+func NewSearchHandler(router *service.SearchRouter) *SearchHandler {
 	return &SearchHandler{
-		searchService: searchService,
+		router: router,
 	}
 }
 
@@ -26,21 +29,46 @@ type SearchRequest struct {
 	Department string `query:"department"`
 	Education  string `query:"education"`
 	ExamType   string `query:"exam_type"`
-	Page       int    `query:"page"`
-	PageSize   int    `query:"page_size"`
+
+	Provinces          []string `query:"provinces"`
+	Educations         []string `query:"educations"`
+	ExamTypes          []string `query:"exam_types"`
+	DepartmentLevel    string   `query:"department_level"`
+	MajorCategory      string   `query:"major_category"`
+	RegistrationStatus string   `query:"reg_status"`
+	IsUnlimitedMajor   *bool    `query:"unlimited_major"`
+	IsForFreshGrad     *bool    `query:"fresh_graduate"`
+	MinRecruitCount    int      `query:"min_recruit"`
+
+	SortBy    string `query:"sort_by"`
+	SortOrder string `query:"sort_order"`
+	Highlight bool   `query:"highlight"`
+
+	Page     int `query:"page"`
+	PageSize int `query:"page_size"`
 }
 
 // Search godoc
 // @Summary 全文搜索职位
-// @Description 使用Elasticsearch进行职位全文搜索
+// @Description 职位全文搜索。Elasticsearch 可用时使用 Elasticsearch，否则自动降级为 MySQL 全文索引，返回的 backend 字段标明实际使用的后端
 // @Tags 搜索
 // @Accept json
 // @Produce json
 // @Param keyword query string false "搜索关键词"
 // @Param province query string false "省份代码"
 // @Param city query string false "城市代码"
+// @Param department query string false "招录单位"
 // @Param education query string false "学历要求"
 // @Param exam_type query string false "考试类型"
+// @Param department_level query string false "单位层级"
+// @Param major_category query string false "专业大类"
+// @Param reg_status query string false "报名状态 registering/upcoming/ended"
+// @Param unlimited_major query bool false "不限专业"
+// @Param fresh_graduate query bool false "应届可报"
+// @Param min_recruit query int false "最低招录人数"
+// @Param sort_by query string false "排序 relevance/created_at/registration_end/recruit_count/competition_ratio"
+// @Param sort_order query string false "asc/desc"
+// @Param highlight query bool false "返回高亮片段"
 // @Param page query int false "页码" default(1)
 // @Param page_size query int false "每页数量" default(20)
 // @Success 200 {object} map[string]interface{}
@@ -62,17 +90,29 @@ func (h *SearchHandler) Search(c echo.Context) error {
 	}
 
 	query := service.SearchQuery{
-		Keyword:    req.Keyword,
-		Province:   req.Province,
-		City:       req.City,
-		Department: req.Department,
-		Education:  req.Education,
-		ExamType:   req.ExamType,
-		Page:       req.Page,
-		PageSize:   req.PageSize,
+		Keyword:            req.Keyword,
+		Province:           req.Province,
+		City:               req.City,
+		Department:         req.Department,
+		Education:          req.Education,
+		ExamType:           req.ExamType,
+		Provinces:          req.Provinces,
+		Educations:         req.Educations,
+		ExamTypes:          req.ExamTypes,
+		DepartmentLevel:    req.DepartmentLevel,
+		MajorCategory:      req.MajorCategory,
+		RegistrationStatus: req.RegistrationStatus,
+		IsUnlimitedMajor:   req.IsUnlimitedMajor,
+		IsForFreshGrad:     req.IsForFreshGrad,
+		MinRecruitCount:    req.MinRecruitCount,
+		SortBy:             req.SortBy,
+		SortOrder:          req.SortOrder,
+		Highlight:          req.Highlight,
+		Page:               req.Page,
+		PageSize:           req.PageSize,
 	}
 
-	result, err := h.searchService.Search(c.Request().Context(), query)
+	result, err := h.router.Search(c.Request().Context(), query)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
 			"code":    500,
@@ -85,11 +125,13 @@ func (h *SearchHandler) Search(c echo.Context) error {
 		"code":    0,
 		"message": "success",
 		"data": map[string]interface{}{
-			"total":     result.Total,
-			"positions": result.Positions,
-			"took_ms":   result.Took,
-			"page":      req.Page,
-			"page_size": req.PageSize,
+			"total":      result.Total,
+			"positions":  result.Positions,
+			"highlights": result.Highlights,
+			"backend":    result.Backend,
+			"took_ms":    result.Took,
+			"page":       req.Page,
+			"page_size":  req.PageSize,
 		},
 	})
 }
@@ -120,7 +162,7 @@ func (h *SearchHandler) Suggest(c echo.Context) error {
 		}
 	}
 
-	suggestions, err := h.searchService.Suggest(c.Request().Context(), prefix, size)
+	suggestions, err := h.router.Suggest(c.Request().Context(), prefix, size)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
 			"code":    500,
@@ -135,7 +177,139 @@ func (h *SearchHandler) Suggest(c echo.Context) error {
 	})
 }
 
+// SetSyncService 设置索引同步服务，Elasticsearch 延迟连接成功后也会调用
+func (h *SearchHandler) SetSyncService(syncService *service.SearchSyncService) {
+	h.syncService.Store(syncService)
+}
+
+// requireSyncService 获取索引同步服务，Elasticsearch 尚未连接时返回 nil 并写出 503
+func (h *SearchHandler) requireSyncService(c echo.Context) (*service.SearchSyncService, error) {
+	syncService := h.syncService.Load()
+	if syncService == nil {
+		return nil, c.JSON(http.StatusServiceUnavailable, map[string]interface{}{
+			"code":    503,
+			"message": "Elasticsearch 未连接",
+			"data": map[string]interface{}{
+				"router": h.router.Status(),
+			},
+		})
+	}
+	return syncService, nil
+}
+
+// AdminGetStatus godoc
+// @Summary 搜索索引状态
+// @Description 返回别名指向的索引、版本索引、文档数、发件箱积压和最近一次重建结果
+// @Tags 搜索管理
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/search/status [get]
+func (h *SearchHandler) AdminGetStatus(c echo.Context) error {
+	syncService, err := h.requireSyncService(c)
+	if syncService == nil {
+		return err
+	}
+
+	status, err := syncService.GetStatus(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"code":    500,
+			"message": "获取索引状态失败",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"code":    0,
+		"message": "success",
+		"data": map[string]interface{}{
+			"router": h.router.Status(),
+			"sync":   status,
+		},
+	})
+}
+
+// AdminReindex godoc
+// @Summary 全量重建搜索索引
+// @Description 创建新版本索引并在写入完成后原子切换别名，重建在后台进行，可通过状态接口查看结果
+// @Tags 搜索管理
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/search/reindex [post]
+func (h *SearchHandler) AdminReindex(c echo.Context) error {
+	syncService, err := h.requireSyncService(c)
+	if syncService == nil {
+		return err
+	}
+
+	if syncService.IsReindexing() {
+		return c.JSON(http.StatusConflict, map[string]interface{}{
+			"code":    409,
+			"message": service.ErrSearchReindexRunning.Error(),
+		})
+	}
+
+	// 重建结果由服务记录日志，并在状态接口中返回
+	go syncService.Reindex(context.Background())
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"code":    0,
+		"message": "索引重建已开始",
+	})
+}
+
+// AdminSync godoc
+// @Summary 立即同步发件箱
+// @Description 立即处理待同步的职位变更，retry=true 时先重置失败记录
+// @Tags 搜索管理
+// @Produce json
+// @Param retry query bool false "是否重试失败记录"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/search/sync [post]
+func (h *SearchHandler) AdminSync(c echo.Context) error {
+	syncService, err := h.requireSyncService(c)
+	if syncService == nil {
+		return err
+	}
+
+	var reset int64
+	if c.QueryParam("retry") == "true" {
+		if reset, err = syncService.RetryFailed(); err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"code":    500,
+				"message": "重置失败记录失败",
+			})
+		}
+	}
+
+	processed, err := syncService.SyncPending(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"code":    500,
+			"message": "同步失败",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"code":    0,
+		"message": "success",
+		"data": map[string]interface{}{
+			"processed": processed,
+			"reset":     reset,
+		},
+	})
+}
+
 func (h *SearchHandler) RegisterRoutes(g *echo.Group) {
 	g.GET("/positions", h.Search)
 	g.GET("/suggest", h.Suggest)
 }
+
+// RegisterAdminRoutes 注册索引管理路由
+func (h *SearchHandler) RegisterAdminRoutes(g *echo.Group, adminAuthMiddleware echo.MiddlewareFunc) {
+	search := g.Group("/search", adminAuthMiddleware)
+	search.GET("/status", h.AdminGetStatus)
+	search.POST("/reindex", h.AdminReindex)
+	search.POST("/sync", h.AdminSync)
+}
@@ -12,6 +12,8 @@ import (
 // StudyNoteHandler handles study note and wrong question related requests
 type StudyNoteHandler struct {
 	studyNoteService *service.StudyNoteService
+	printService     *service.PrintExportService
+	exportLimit      echo.MiddlewareFunc
 }
 
 // NewStudyNoteHandler creates a new study note handler
@@ -21,6 +23,13 @@ func NewStudyNoteHandler(studyNoteService *service.StudyNoteService) *StudyNoteH
 	}
 }
 
+// SetPrintExportService sets the service used for printable PDF/DOCX exports
+// and the per-user rate limit applied to the export route
+func (h *StudyNoteHandler) SetPrintExportService(printService *service.PrintExportService, rateLimit echo.MiddlewareFunc) {
+	h.printService = printService
+	h.exportLimit = rateLimit
+}
+
 // RegisterRoutes registers study note routes
 func (h *StudyNoteHandler) RegisterRoutes(e *echo.Echo, authMiddleware echo.MiddlewareFunc) {
 	// Wrong questions routes (protected)
@@ -39,7 +48,11 @@ func (h *StudyNoteHandler) RegisterRoutes(e *echo.Echo, authMiddleware echo.Midd
 	wrongGroup.DELETE("/:id", h.RemoveWrongQuestion)
 	wrongGroup.PUT("/:id/restore", h.RestoreWrongQuestion)
 	wrongGroup.POST("/batch-mastered", h.BatchMarkAsMastered)
-	wrongGroup.POST("/export", h.ExportWrongQuestions)
+	if h.exportLimit != nil {
+		wrongGroup.POST("/export", h.ExportWrongQuestions, h.exportLimit)
+	} else {
+		wrongGroup.POST("/export", h.ExportWrongQuestions)
+	}
 
 	// Study notes routes
 	noteGroup := e.Group("/api/v1/notes")
@@ -335,7 +348,7 @@ func (h *StudyNoteHandler) UpdateWrongQuestionErrorReason(c echo.Context) error
 // @Accept json
 // @Produce json
 // @Param id path int true "Wrong question ID"
-// @Param body body object{is_correct=bool} true "Review result"
+// @Param body body object{is_correct=bool,rating=int} true "Review result"
 // @Success 200 {object} map[string]string
 // @Router /api/v1/wrong-questions/{id}/review [put]
 func (h *StudyNoteHandler) RecordReview(c echo.Context) error {
@@ -354,10 +367,13 @@ func (h *StudyNoteHandler) RecordReview(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "参数错误"})
 	}
 
-	if err := h.studyNoteService.RecordReview(uint(id), userID, req.IsCorrect); err != nil {
+	if _, err := h.studyNoteService.RecordReview(uint(id), userID, req.IsCorrect, req.Rating); err != nil {
 		if err == service.ErrWrongQuestionNotFound {
 			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
 		}
+		if err == service.ErrInvalidReviewRating {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -506,6 +522,9 @@ func (h *StudyNoteHandler) ExportWrongQuestions(c echo.Context) error {
 		StartDate    string `json:"start_date"`
 		EndDate      string `json:"end_date"`
 		IDs          []uint `json:"ids"`
+		AnswerMode   string `json:"answer_mode"`  // pdf/docx: inline, appendix, hidden
+		AnswerSheet  bool   `json:"answer_sheet"` // pdf/docx: append an answer sheet
+		Watermark    bool   `json:"watermark"`    // pdf/docx: watermark with the user ID
 	}
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "参数错误"})
@@ -528,6 +547,23 @@ func (h *StudyNoteHandler) ExportWrongQuestions(c echo.Context) error {
 		IDs:          req.IDs,
 	}
 
+	// Printable formats are rendered by the print export service
+	if req.Format == "pdf" || req.Format == "docx" {
+		if h.printService == nil {
+			return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "打印导出未启用"})
+		}
+		file, err := h.printService.ExportWrongQuestions(c.Request().Context(), userID, exportReq, service.PrintExportOptions{
+			Format:      req.Format,
+			AnswerMode:  req.AnswerMode,
+			AnswerSheet: req.AnswerSheet,
+			Watermark:   req.Watermark,
+		})
+		if err != nil {
+			return printExportError(c, err)
+		}
+		return sendPrintFile(c, file)
+	}
+
 	// Get export data
 	data, err := h.studyNoteService.ExportWrongQuestions(userID, exportReq)
 	if err != nil {
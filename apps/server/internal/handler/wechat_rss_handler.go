@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/labstack/echo/v4"
@@ -73,6 +74,45 @@ func (h *WechatRSSHandler) GetSource(c echo.Context) error {
 	return success(c, source)
 }
 
+// CreateFeedSource subscribes to an RSS/Atom/JSON Feed URL
+// @Summary Create Feed Source (Admin)
+// @Description Subscribe to any RSS, Atom or JSON Feed URL. The feed is polled on crawl_frequency and its items are stored as articles; with fetch_content the full article page is fetched for items that only carry a summary.
+// @Tags Admin - WeChat Subscription
+// @Accept json
+// @Produce json
+// @Security AdminAuth
+// @Param request body model.CreateFeedSourceRequest true "Feed source"
+// @Success 200 {object} Response
+// @Router /api/v1/admin/wechat-rss/sources/feed [post]
+func (h *WechatRSSHandler) CreateFeedSource(c echo.Context) error {
+	var req model.CreateFeedSourceRequest
+	if err := c.Bind(&req); err != nil {
+		return fail(c, 400, "无效的请求参数")
+	}
+	if req.RSSURL == "" {
+		return fail(c, 400, "订阅地址不能为空")
+	}
+	if req.CrawlFrequency != 0 && (req.CrawlFrequency < 5 || req.CrawlFrequency > 1440) {
+		return fail(c, 400, "抓取频率需在 5 到 1440 分钟之间")
+	}
+
+	source, err := h.wechatRSSService.CreateFeedSource(&req)
+	if err != nil {
+		if err == service.ErrWechatRSSSourceExists {
+			return fail(c, 409, "该订阅地址已存在")
+		}
+		if errors.Is(err, service.ErrWechatRSSFeedInvalid) {
+			return fail(c, 400, "无效的订阅地址: "+err.Error())
+		}
+		return fail(c, 500, "创建订阅源失败: "+err.Error())
+	}
+
+	return success(c, map[string]interface{}{
+		"message": "订阅源创建成功",
+		"source":  source,
+	})
+}
+
 // UpdateSource updates a source
 // @Summary Update Source (Admin)
 // @Description Update an existing source
@@ -100,6 +140,12 @@ func (h *WechatRSSHandler) UpdateSource(c echo.Context) error {
 		if err == service.ErrWechatRSSSourceNotFound {
 			return fail(c, 404, "订阅源不存在")
 		}
+		if err == service.ErrWechatRSSSourceExists {
+			return fail(c, 409, "该订阅地址已存在")
+		}
+		if errors.Is(err, service.ErrWechatRSSFeedInvalid) {
+			return fail(c, 400, "无效的订阅地址: "+err.Error())
+		}
 		return fail(c, 500, "更新订阅源失败: "+err.Error())
 	}
 
@@ -397,8 +443,9 @@ func (h *WechatRSSHandler) RegisterRoutes(g *echo.Group, adminAuthMiddleware ech
 	// Admin routes (protected)
 	wechatRSS := g.Group("/wechat-rss", adminAuthMiddleware)
 
-	// Source management (no create - use wechat-mp/create-source instead)
+	// Source management (WeChat accounts are created via wechat-mp/create-source)
 	wechatRSS.GET("/sources", h.ListSources)
+	wechatRSS.POST("/sources/feed", h.CreateFeedSource)
 	wechatRSS.GET("/sources/:id", h.GetSource)
 	wechatRSS.PUT("/sources/:id", h.UpdateSource)
 	wechatRSS.DELETE("/sources/:id", h.DeleteSource)
@@ -0,0 +1,121 @@
+// Package headless shares one headless Chrome between callers. The browser
+// starts on first use; every caller gets its own tab, and a semaphore bounds
+// the number of open tabs.
+package headless
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/chromedp/chromedp"
+)
+
+// ErrUnavailable is returned when headless Chrome could not be started
+var ErrUnavailable = errors.New("headless chrome unavailable")
+
+// Options configures a Browser
+type Options struct {
+	ChromePath string // Chrome executable; empty searches the usual locations
+	ProxyURL   string // proxy for all page loads, e.g. http://127.0.0.1:8080
+	UserAgent  string
+	MaxTabs    int // concurrently open tabs, default 2
+}
+
+// Browser is a lazily started, shared headless Chrome
+type Browser struct {
+	opts []chromedp.ExecAllocatorOption
+	tabs chan struct{}
+
+	mu            sync.Mutex
+	browserCtx    context.Context
+	browserCancel context.CancelFunc
+}
+
+// New creates a browser; Chrome is not started until the first Tab call
+func New(options Options) *Browser {
+	opts := append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.Flag("headless", true),
+		chromedp.Flag("disable-gpu", true),
+		chromedp.Flag("no-sandbox", true),
+		chromedp.Flag("disable-dev-shm-usage", true),
+		chromedp.Flag("disable-extensions", true),
+	)
+	if options.ChromePath != "" {
+		opts = append(opts, chromedp.ExecPath(options.ChromePath))
+	}
+	if options.ProxyURL != "" {
+		opts = append(opts, chromedp.ProxyServer(options.ProxyURL))
+	}
+	if options.UserAgent != "" {
+		opts = append(opts, chromedp.UserAgent(options.UserAgent))
+	}
+	maxTabs := options.MaxTabs
+	if maxTabs <= 0 {
+		maxTabs = 2
+	}
+	return &Browser{opts: opts, tabs: make(chan struct{}, maxTabs)}
+}
+
+// Tab opens a new tab once a slot is free. The tab is closed when ctx ends or
+// cancel is called; cancel must always be called.
+func (b *Browser) Tab(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	select {
+	case b.tabs <- struct{}{}:
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+
+	browserCtx, err := b.browser()
+	if err != nil {
+		<-b.tabs
+		return nil, nil, err
+	}
+	tabCtx, tabCancel := chromedp.NewContext(browserCtx)
+	stop := context.AfterFunc(ctx, tabCancel)
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			stop()
+			tabCancel()
+			<-b.tabs
+		})
+	}
+	return tabCtx, cancel, nil
+}
+
+// browser returns the running browser, starting or restarting Chrome as needed
+func (b *Browser) browser() (context.Context, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.browserCtx != nil && b.browserCtx.Err() == nil {
+		return b.browserCtx, nil
+	}
+
+	allocCtx, allocCancel := chromedp.NewExecAllocator(context.Background(), b.opts...)
+	browserCtx, browserCancel := chromedp.NewContext(allocCtx)
+	// Run without actions launches the browser
+	if err := chromedp.Run(browserCtx); err != nil {
+		browserCancel()
+		allocCancel()
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	b.browserCtx = browserCtx
+	b.browserCancel = func() {
+		browserCancel()
+		allocCancel()
+	}
+	return browserCtx, nil
+}
+
+// Close shuts Chrome down; a later Tab call starts it again
+func (b *Browser) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.browserCancel != nil {
+		b.browserCancel()
+		b.browserCtx, b.browserCancel = nil, nil
+	}
+}
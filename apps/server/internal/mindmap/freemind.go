@@ -0,0 +1,159 @@
+package mindmap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// freeMindKnowledgePoint is the node attribute holding the knowledge point id
+const freeMindKnowledgePoint = "knowledge_point_id"
+
+var (
+	htmlBreakRe = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|h[1-6])>`)
+	htmlTagRe   = regexp.MustCompile(`<[^>]*>`)
+)
+
+type freeMindDoc struct {
+	Node *freeMindNode `xml:"node"`
+}
+
+type freeMindNode struct {
+	ID         string `xml:"ID,attr"`
+	Text       string `xml:"TEXT,attr"`
+	Link       string `xml:"LINK,attr"`
+	Color      string `xml:"COLOR,attr"`
+	Background string `xml:"BACKGROUND_COLOR,attr"`
+	Folded     string `xml:"FOLDED,attr"`
+	Rich       []struct {
+		Type  string `xml:"TYPE,attr"`
+		Inner string `xml:",innerxml"`
+	} `xml:"richcontent"`
+	Attributes []struct {
+		Name  string `xml:"NAME,attr"`
+		Value string `xml:"VALUE,attr"`
+	} `xml:"attribute"`
+	Icons []struct {
+		Builtin string `xml:"BUILTIN,attr"`
+	} `xml:"icon"`
+	Children []*freeMindNode `xml:"node"`
+}
+
+func encodeFreeMind(m *Map) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(`<map version="1.0.1">` + "\n")
+	writeFreeMindNode(&buf, m.Root, 0)
+	buf.WriteString("</map>\n")
+	return buf.Bytes(), nil
+}
+
+func writeFreeMindNode(buf *bytes.Buffer, n *Node, depth int) {
+	indent := strings.Repeat("  ", depth)
+	fmt.Fprintf(buf, `%s<node ID="%s" TEXT="%s"`, indent, xmlEscape(freeMindID(n.ID)), xmlEscape(n.Text))
+	if n.Link != "" {
+		fmt.Fprintf(buf, ` LINK="%s"`, xmlEscape(n.Link))
+	}
+	if c := normalizeColor(n.Color); c != "" {
+		fmt.Fprintf(buf, ` COLOR="%s"`, c)
+	}
+	if !n.Expanded && len(n.Children) > 0 {
+		buf.WriteString(` FOLDED="true"`)
+	}
+	buf.WriteString(">\n")
+	if n.Note != "" {
+		fmt.Fprintf(buf, `%s  <richcontent TYPE="NOTE"><html><head></head><body>`, indent)
+		for _, line := range strings.Split(n.Note, "\n") {
+			fmt.Fprintf(buf, "<p>%s</p>", xmlEscape(line))
+		}
+		buf.WriteString("</body></html></richcontent>\n")
+	}
+	if n.Icon != "" {
+		fmt.Fprintf(buf, `%s  <icon BUILTIN="%s"/>`+"\n", indent, xmlEscape(n.Icon))
+	}
+	if n.KnowledgePointID > 0 {
+		fmt.Fprintf(buf, `%s  <attribute NAME="%s" VALUE="%d"/>`+"\n", indent, freeMindKnowledgePoint, n.KnowledgePointID)
+	}
+	for _, c := range n.Children {
+		writeFreeMindNode(buf, c, depth+1)
+	}
+	fmt.Fprintf(buf, "%s</node>\n", indent)
+}
+
+// freeMindID makes an id valid for FreeMind, which expects ID_ prefixed ids
+func freeMindID(id string) string {
+	if strings.HasPrefix(id, "ID_") {
+		return id
+	}
+	return "ID_" + id
+}
+
+func decodeFreeMind(data []byte) (*Map, error) {
+	var doc freeMindDoc
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("mindmap: parse freemind: %w", err)
+	}
+	if doc.Node == nil {
+		return nil, ErrEmpty
+	}
+	root := nodeOfFreeMind(doc.Node)
+	return &Map{Title: root.Text, Root: root}, nil
+}
+
+func nodeOfFreeMind(f *freeMindNode) *Node {
+	n := &Node{
+		ID:       strings.TrimPrefix(f.ID, "ID_"),
+		Text:     f.Text,
+		Expanded: f.Folded != "true",
+		Color:    normalizeColor(f.Color),
+	}
+	if n.Color == "" {
+		n.Color = normalizeColor(f.Background)
+	}
+	setLink(n, f.Link)
+	for _, rc := range f.Rich {
+		switch strings.ToUpper(rc.Type) {
+		case "NOTE":
+			n.Note = htmlText(rc.Inner)
+		case "NODE":
+			if n.Text == "" {
+				n.Text = htmlText(rc.Inner)
+			}
+		}
+	}
+	for _, a := range f.Attributes {
+		if a.Name == freeMindKnowledgePoint {
+			if id, err := strconv.ParseUint(a.Value, 10, 32); err == nil {
+				n.KnowledgePointID = uint(id)
+			}
+		}
+	}
+	if len(f.Icons) > 0 {
+		n.Icon = f.Icons[0].Builtin
+	}
+	for _, c := range f.Children {
+		n.Children = append(n.Children, nodeOfFreeMind(c))
+	}
+	return n
+}
+
+// htmlText converts the XHTML of a rich content element to plain text
+func htmlText(s string) string {
+	if i := strings.Index(strings.ToLower(s), "<body"); i >= 0 {
+		s = s[i:]
+	}
+	s = htmlBreakRe.ReplaceAllString(s, "\n")
+	s = htmlTagRe.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	lines := strings.Split(s, "\n")
+	out := lines[:0]
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,201 @@
+package mindmap
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Markdown outlines use a level-1 heading for the root and nested "- " list
+// items below it, the layout markmap and most outliners read. Node metadata
+// that Markdown cannot express is kept in a trailing HTML comment, e.g.
+// "- 行政法 <!-- color=#ff6600 kp=12 folded -->", which renderers hide.
+
+var (
+	mdHeadingRe = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+	mdItemRe    = regexp.MustCompile(`^([ \t]*)(?:[-*+]|\d+[.)])\s+(.*)$`)
+	mdLinkRe    = regexp.MustCompile(`^\[(.*)\]\(([^)\s]+)\)$`)
+	mdMetaRe    = regexp.MustCompile(`\s*<!--(.*?)-->\s*$`)
+)
+
+func encodeMarkdown(m *Map) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# %s\n", markdownItem(m.Root))
+	if m.Root.Note != "" {
+		buf.WriteString("\n")
+		writeMarkdownNote(&buf, m.Root.Note, "")
+	}
+	if len(m.Root.Children) > 0 {
+		buf.WriteString("\n")
+	}
+	for _, c := range m.Root.Children {
+		writeMarkdownItem(&buf, c, 0)
+	}
+	return buf.Bytes()
+}
+
+func writeMarkdownItem(buf *bytes.Buffer, n *Node, depth int) {
+	indent := strings.Repeat("  ", depth)
+	fmt.Fprintf(buf, "%s- %s\n", indent, markdownItem(n))
+	if n.Note != "" {
+		writeMarkdownNote(buf, n.Note, indent+"  ")
+	}
+	for _, c := range n.Children {
+		writeMarkdownItem(buf, c, depth+1)
+	}
+}
+
+func writeMarkdownNote(buf *bytes.Buffer, note, indent string) {
+	for _, line := range strings.Split(note, "\n") {
+		fmt.Fprintf(buf, "%s> %s\n", indent, line)
+	}
+}
+
+// markdownItem renders the text, link and metadata comment of a node
+func markdownItem(n *Node) string {
+	text := strings.Join(strings.Fields(n.Text), " ")
+	if n.Link != "" {
+		text = fmt.Sprintf("[%s](%s)", text, strings.ReplaceAll(n.Link, " ", "%20"))
+	}
+	var meta []string
+	if c := normalizeColor(n.Color); c != "" {
+		meta = append(meta, "color="+c)
+	}
+	if n.Icon != "" && !strings.ContainsAny(n.Icon, " =") {
+		meta = append(meta, "icon="+n.Icon)
+	}
+	if n.KnowledgePointID > 0 {
+		meta = append(meta, fmt.Sprintf("kp=%d", n.KnowledgePointID))
+	}
+	if !n.Expanded && len(n.Children) > 0 {
+		meta = append(meta, "folded")
+	}
+	if len(meta) > 0 {
+		text += " <!-- " + strings.Join(meta, " ") + " -->"
+	}
+	return text
+}
+
+type mdEntry struct {
+	node  *Node
+	level int
+}
+
+func decodeMarkdown(data []byte) (*Map, error) {
+	m := &Map{}
+	root := &Node{Expanded: true}
+	stack := []mdEntry{{root, 0}}
+	var last *Node
+	headingLevel := 0
+	inFence, inFrontMatter := false, false
+
+	scanner := bufio.NewScanner(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))))
+	scanner.Buffer(make([]byte, 64*1024), 4<<20)
+	for lineNo := 0; scanner.Scan(); lineNo++ {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		trimmed := strings.TrimSpace(line)
+
+		// markmap style front matter: only the title is used
+		if lineNo == 0 && trimmed == "---" {
+			inFrontMatter = true
+			continue
+		}
+		if inFrontMatter {
+			if trimmed == "---" {
+				inFrontMatter = false
+			} else if v, ok := strings.CutPrefix(trimmed, "title:"); ok {
+				m.Title = strings.Trim(strings.TrimSpace(v), `"'`)
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || trimmed == "" {
+			continue
+		}
+
+		var text string
+		level := -1
+		if h := mdHeadingRe.FindStringSubmatch(trimmed); h != nil && line[0] == '#' {
+			level, text = len(h[1]), h[2]
+			headingLevel = level
+		} else if item := mdItemRe.FindStringSubmatch(line); item != nil {
+			// list items sit below the last heading; deeper indentation nests
+			// further, whatever the indent width is
+			indent := len(strings.ReplaceAll(item[1], "\t", "    "))
+			level, text = headingLevel+1+indent/2, item[2]
+		}
+		if level < 0 {
+			// other text is the note of the preceding node
+			if last != nil {
+				note := strings.TrimSpace(strings.TrimPrefix(trimmed, ">"))
+				if last.Note != "" {
+					last.Note += "\n"
+				}
+				last.Note += note
+			}
+			continue
+		}
+
+		n := parseMarkdownItem(text)
+		for len(stack) > 1 && stack[len(stack)-1].level >= level {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1].node
+		parent.Children = append(parent.Children, n)
+		stack = append(stack, mdEntry{n, level})
+		last = n
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("mindmap: read markdown: %w", err)
+	}
+
+	if len(root.Children) == 0 {
+		return nil, ErrEmpty
+	}
+	if len(root.Children) == 1 {
+		m.Root = root.Children[0]
+	} else {
+		// several top-level topics hang below a root named after the document
+		root.Text = m.Title
+		m.Root = root
+	}
+	if m.Root.Text == "" {
+		m.Root.Text = m.Title
+	}
+	return m, nil
+}
+
+func parseMarkdownItem(text string) *Node {
+	n := &Node{Expanded: true}
+	if meta := mdMetaRe.FindStringSubmatch(text); meta != nil {
+		text = text[:len(text)-len(meta[0])]
+		for _, field := range strings.Fields(meta[1]) {
+			key, value, _ := strings.Cut(field, "=")
+			switch key {
+			case "color":
+				n.Color = normalizeColor(value)
+			case "icon":
+				n.Icon = value
+			case "kp":
+				if id, err := strconv.ParseUint(value, 10, 32); err == nil {
+					n.KnowledgePointID = uint(id)
+				}
+			case "folded":
+				n.Expanded = false
+			}
+		}
+	}
+	text = strings.TrimSpace(text)
+	if link := mdLinkRe.FindStringSubmatch(text); link != nil {
+		text = link[1]
+		setLink(n, strings.ReplaceAll(link[2], "%20", " "))
+	}
+	n.Text = text
+	return n
+}
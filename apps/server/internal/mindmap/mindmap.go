@@ -0,0 +1,228 @@
+// Package mindmap converts mind maps between the outline structure stored by
+// the server and common interchange formats: XMind (2020+ content.json with a
+// legacy content.xml fallback), FreeMind .mm, OPML 2.0 and indented Markdown.
+//
+// Every format keeps the node text, note, link, color, fold state and the
+// knowledge point a node refers to. Formats without a dedicated slot for the
+// knowledge point store it as a "knowledge-point:<id>" link when the node has
+// no other link.
+package mindmap
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Format identifies an interchange format
+type Format string
+
+const (
+	FormatXMind    Format = "xmind"
+	FormatFreeMind Format = "mm"
+	FormatOPML     Format = "opml"
+	FormatMarkdown Format = "md"
+)
+
+// ErrUnknownFormat is returned for formats other than the ones above
+var ErrUnknownFormat = errors.New("mindmap: unknown format")
+
+// ErrEmpty is returned when a document contains no topic
+var ErrEmpty = errors.New("mindmap: document contains no topic")
+
+// knowledgePointScheme prefixes links that refer to a knowledge point
+const knowledgePointScheme = "knowledge-point:"
+
+// Map is a mind map: a titled tree of nodes
+type Map struct {
+	Title  string
+	Root   *Node
+	Theme  string
+	Layout string
+}
+
+// Node is one topic of a mind map
+type Node struct {
+	ID               string
+	Text             string
+	Note             string
+	Link             string
+	Color            string // CSS hex color, e.g. #ff6600
+	Icon             string
+	Expanded         bool
+	KnowledgePointID uint // 0 when the node is not linked to a knowledge point
+	Children         []*Node
+}
+
+// ParseFormat normalizes a format name or file extension
+func ParseFormat(s string) (Format, error) {
+	switch strings.TrimPrefix(strings.ToLower(strings.TrimSpace(s)), ".") {
+	case "xmind":
+		return FormatXMind, nil
+	case "mm", "freemind":
+		return FormatFreeMind, nil
+	case "opml":
+		return FormatOPML, nil
+	case "md", "markdown":
+		return FormatMarkdown, nil
+	}
+	return "", ErrUnknownFormat
+}
+
+// FormatOf returns the format of a file name by its extension
+func FormatOf(name string) (Format, error) {
+	return ParseFormat(filepath.Ext(name))
+}
+
+// Ext returns the file extension of the format, including the dot
+func (f Format) Ext() string {
+	return "." + string(f)
+}
+
+// ContentType returns the MIME type used when serving the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatXMind:
+		return "application/vnd.xmind.workbook"
+	case FormatFreeMind:
+		return "application/x-freemind"
+	case FormatOPML:
+		return "text/x-opml; charset=utf-8"
+	case FormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	}
+	return "application/octet-stream"
+}
+
+// Encode writes m in format f
+func Encode(m *Map, f Format) ([]byte, error) {
+	if m == nil || m.Root == nil {
+		return nil, ErrEmpty
+	}
+	switch f {
+	case FormatXMind:
+		return encodeXMind(m)
+	case FormatFreeMind:
+		return encodeFreeMind(m)
+	case FormatOPML:
+		return encodeOPML(m)
+	case FormatMarkdown:
+		return encodeMarkdown(m), nil
+	}
+	return nil, ErrUnknownFormat
+}
+
+// Decode reads a document in format f. Nodes without an ID get a generated one.
+func Decode(data []byte, f Format) (*Map, error) {
+	var m *Map
+	var err error
+	switch f {
+	case FormatXMind:
+		m, err = decodeXMind(data)
+	case FormatFreeMind:
+		m, err = decodeFreeMind(data)
+	case FormatOPML:
+		m, err = decodeOPML(data)
+	case FormatMarkdown:
+		m, err = decodeMarkdown(data)
+	default:
+		return nil, ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if m.Root == nil || strings.TrimSpace(m.Root.Text) == "" && len(m.Root.Children) == 0 {
+		return nil, ErrEmpty
+	}
+	if m.Title == "" {
+		m.Title = m.Root.Text
+	}
+	assignIDs(m.Root)
+	return m, nil
+}
+
+// assignIDs gives every node without an ID a unique one and drops duplicates
+func assignIDs(root *Node) {
+	seen := make(map[string]bool)
+	next := 0
+	var walk func(n *Node, isRoot bool)
+	walk = func(n *Node, isRoot bool) {
+		if n.ID == "" || seen[n.ID] {
+			if isRoot && !seen["root"] {
+				n.ID = "root"
+			} else {
+				for {
+					next++
+					n.ID = "node_" + strconv.Itoa(next)
+					if !seen[n.ID] {
+						break
+					}
+				}
+			}
+		}
+		seen[n.ID] = true
+		for _, c := range n.Children {
+			walk(c, false)
+		}
+	}
+	walk(root, true)
+}
+
+// linkOf returns the link written for n in formats with a single link slot
+func linkOf(n *Node) string {
+	if n.Link == "" && n.KnowledgePointID > 0 {
+		return knowledgePointLink(n.KnowledgePointID)
+	}
+	return n.Link
+}
+
+// setLink stores a link read from a document, recognising knowledge point links
+func setLink(n *Node, link string) {
+	link = strings.TrimSpace(link)
+	if id, ok := parseKnowledgePointLink(link); ok {
+		n.KnowledgePointID = id
+		return
+	}
+	n.Link = link
+}
+
+func knowledgePointLink(id uint) string {
+	return fmt.Sprintf("%s%d", knowledgePointScheme, id)
+}
+
+func parseKnowledgePointLink(link string) (uint, bool) {
+	if !strings.HasPrefix(link, knowledgePointScheme) {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(strings.TrimPrefix(link, knowledgePointScheme), 10, 32)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// normalizeColor accepts #rgb, #rrggbb and #aarrggbb/#rrggbbaa style colors and
+// returns #rrggbb, or "" for anything else
+func normalizeColor(c string) string {
+	c = strings.ToLower(strings.TrimSpace(c))
+	if !strings.HasPrefix(c, "#") {
+		return ""
+	}
+	hex := c[1:]
+	for _, r := range hex {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return ""
+		}
+	}
+	switch len(hex) {
+	case 3:
+		return "#" + string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	case 6:
+		return c
+	case 8:
+		return "#" + hex[:6] // XMind writes #rrggbbaa
+	}
+	return ""
+}
@@ -0,0 +1,124 @@
+package mindmap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// opmlOutline is an OPML outline element. _note is the de facto note attribute
+// of OmniOutliner, Workflowy and most outliners; color, icon and
+// knowledgePointId are our own extensions.
+type opmlOutline struct {
+	Text             string         `xml:"text,attr"`
+	Title            string         `xml:"title,attr"`
+	Note             string         `xml:"_note,attr"`
+	URL              string         `xml:"url,attr"`
+	HTMLURL          string         `xml:"htmlUrl,attr"`
+	ID               string         `xml:"id,attr"`
+	Color            string         `xml:"color,attr"`
+	Icon             string         `xml:"icon,attr"`
+	Collapsed        string         `xml:"collapsed,attr"`
+	KnowledgePointID string         `xml:"knowledgePointId,attr"`
+	Children         []*opmlOutline `xml:"outline"`
+}
+
+type opmlDoc struct {
+	Title    string         `xml:"head>title"`
+	Outlines []*opmlOutline `xml:"body>outline"`
+}
+
+func encodeOPML(m *Map) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<opml version="2.0">` + "\n")
+	fmt.Fprintf(&buf, "  <head>\n    <title>%s</title>\n  </head>\n  <body>\n", xmlEscape(m.Title))
+	writeOPMLOutline(&buf, m.Root, 2)
+	buf.WriteString("  </body>\n</opml>\n")
+	return buf.Bytes(), nil
+}
+
+func writeOPMLOutline(buf *bytes.Buffer, n *Node, depth int) {
+	indent := strings.Repeat("  ", depth)
+	fmt.Fprintf(buf, `%s<outline text="%s" id="%s"`, indent, xmlEscape(n.Text), xmlEscape(n.ID))
+	if n.Note != "" {
+		fmt.Fprintf(buf, ` _note="%s"`, xmlEscape(n.Note))
+	}
+	if n.Link != "" {
+		fmt.Fprintf(buf, ` type="link" url="%s"`, xmlEscape(n.Link))
+	}
+	if c := normalizeColor(n.Color); c != "" {
+		fmt.Fprintf(buf, ` color="%s"`, c)
+	}
+	if n.Icon != "" {
+		fmt.Fprintf(buf, ` icon="%s"`, xmlEscape(n.Icon))
+	}
+	if n.KnowledgePointID > 0 {
+		fmt.Fprintf(buf, ` knowledgePointId="%d"`, n.KnowledgePointID)
+	}
+	if !n.Expanded && len(n.Children) > 0 {
+		buf.WriteString(` collapsed="true"`)
+	}
+	if len(n.Children) == 0 {
+		buf.WriteString("/>\n")
+		return
+	}
+	buf.WriteString(">\n")
+	for _, c := range n.Children {
+		writeOPMLOutline(buf, c, depth+1)
+	}
+	fmt.Fprintf(buf, "%s</outline>\n", indent)
+}
+
+func decodeOPML(data []byte) (*Map, error) {
+	var doc opmlDoc
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("mindmap: parse opml: %w", err)
+	}
+	if len(doc.Outlines) == 0 {
+		return nil, ErrEmpty
+	}
+
+	m := &Map{Title: strings.TrimSpace(doc.Title)}
+	if len(doc.Outlines) == 1 {
+		m.Root = nodeOfOPML(doc.Outlines[0])
+		return m, nil
+	}
+	// several top-level outlines hang below a root named after the document
+	m.Root = &Node{Text: m.Title, Expanded: true}
+	if m.Root.Text == "" {
+		m.Root.Text = "OPML"
+	}
+	for _, o := range doc.Outlines {
+		m.Root.Children = append(m.Root.Children, nodeOfOPML(o))
+	}
+	return m, nil
+}
+
+func nodeOfOPML(o *opmlOutline) *Node {
+	n := &Node{
+		ID:       o.ID,
+		Text:     o.Text,
+		Note:     o.Note,
+		Color:    normalizeColor(o.Color),
+		Icon:     o.Icon,
+		Expanded: o.Collapsed != "true",
+	}
+	if n.Text == "" {
+		n.Text = o.Title
+	}
+	link := o.URL
+	if link == "" {
+		link = o.HTMLURL
+	}
+	setLink(n, link)
+	if id, err := strconv.ParseUint(o.KnowledgePointID, 10, 32); err == nil && id > 0 {
+		n.KnowledgePointID = uint(id)
+	}
+	for _, c := range o.Children {
+		n.Children = append(n.Children, nodeOfOPML(c))
+	}
+	return n
+}
@@ -0,0 +1,347 @@
+package mindmap
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// maxXMindEntry bounds the size of a single entry read from an .xmind file
+const maxXMindEntry = 50 << 20
+
+// xmindMarkerRe matches XMind marker ids such as priority-1 or task-done,
+// the only icons that survive a round trip through XMind
+var xmindMarkerRe = regexp.MustCompile(`^[a-z]+-[a-z0-9-]+$`)
+
+// xmindTopic is a topic of the XMind 2020+ content.json
+type xmindTopic struct {
+	ID             string      `json:"id"`
+	Class          string      `json:"class,omitempty"`
+	Title          string      `json:"title"`
+	StructureClass string      `json:"structureClass,omitempty"`
+	Href           string      `json:"href,omitempty"`
+	Branch         string      `json:"branch,omitempty"` // "folded"
+	Notes          *xmindNotes `json:"notes,omitempty"`
+	Style          *xmindStyle `json:"style,omitempty"`
+	Markers        []struct {
+		MarkerID string `json:"markerId"`
+	} `json:"markers,omitempty"`
+	Children *struct {
+		Attached []*xmindTopic `json:"attached,omitempty"`
+	} `json:"children,omitempty"`
+}
+
+type xmindNotes struct {
+	Plain *struct {
+		Content string `json:"content"`
+	} `json:"plain,omitempty"`
+}
+
+type xmindStyle struct {
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type xmindSheet struct {
+	ID        string      `json:"id"`
+	Class     string      `json:"class"`
+	Title     string      `json:"title"`
+	RootTopic *xmindTopic `json:"rootTopic"`
+}
+
+func encodeXMind(m *Map) ([]byte, error) {
+	root := xmindTopicOf(m.Root)
+	root.StructureClass = "org.xmind.ui.map.unbalanced"
+	if m.Layout == "right" || m.Layout == "logic" {
+		root.StructureClass = "org.xmind.ui.logic.right"
+	}
+	content, err := json.Marshal([]xmindSheet{{ID: "sheet-" + m.Root.ID, Class: "sheet", Title: m.Title, RootTopic: root}})
+	if err != nil {
+		return nil, err
+	}
+	legacy, err := encodeXMindLegacy(m)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, entry := range []struct {
+		name string
+		data []byte
+	}{
+		{"content.json", content},
+		{"metadata.json", []byte(`{"creator":{"name":"what-cse","version":"1.0"}}`)},
+		{"manifest.json", []byte(`{"file-entries":{"content.json":{},"metadata.json":{}}}`)},
+		// XMind 8 and older only read content.xml
+		{"content.xml", legacy},
+		{"META-INF/manifest.xml", []byte(xml.Header + `<manifest xmlns="urn:xmind:xmap:xmlns:manifest:1.0">` +
+			`<file-entry full-path="content.xml" media-type="text/xml"/>` +
+			`<file-entry full-path="META-INF/" media-type=""/>` +
+			`<file-entry full-path="META-INF/manifest.xml" media-type="text/xml"/></manifest>`)},
+	} {
+		w, err := zw.Create(entry.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(entry.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func xmindTopicOf(n *Node) *xmindTopic {
+	t := &xmindTopic{ID: n.ID, Class: "topic", Title: n.Text, Href: linkOf(n)}
+	if !n.Expanded && len(n.Children) > 0 {
+		t.Branch = "folded"
+	}
+	if n.Note != "" {
+		t.Notes = &xmindNotes{Plain: &struct {
+			Content string `json:"content"`
+		}{n.Note}}
+	}
+	if c := normalizeColor(n.Color); c != "" {
+		t.Style = &xmindStyle{Properties: map[string]string{"svg:fill": c}}
+	}
+	if xmindMarkerRe.MatchString(n.Icon) {
+		t.Markers = append(t.Markers, struct {
+			MarkerID string `json:"markerId"`
+		}{n.Icon})
+	}
+	if len(n.Children) > 0 {
+		t.Children = &struct {
+			Attached []*xmindTopic `json:"attached,omitempty"`
+		}{}
+		for _, c := range n.Children {
+			t.Children.Attached = append(t.Children.Attached, xmindTopicOf(c))
+		}
+	}
+	return t
+}
+
+func decodeXMind(data []byte) (*Map, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("mindmap: open xmind: %w", err)
+	}
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	if f, ok := files["content.json"]; ok {
+		content, err := readXMindEntry(f)
+		if err != nil {
+			return nil, err
+		}
+		var sheets []xmindSheet
+		if err := json.Unmarshal(content, &sheets); err != nil {
+			return nil, fmt.Errorf("mindmap: parse xmind content.json: %w", err)
+		}
+		if len(sheets) == 0 || sheets[0].RootTopic == nil {
+			return nil, ErrEmpty
+		}
+		m := &Map{Title: sheets[0].Title, Root: nodeOfXMindTopic(sheets[0].RootTopic)}
+		if m.Title == "" || strings.HasPrefix(m.Title, "Sheet") || strings.HasPrefix(m.Title, "画布") {
+			m.Title = m.Root.Text
+		}
+		return m, nil
+	}
+
+	f, ok := files["content.xml"]
+	if !ok {
+		return nil, ErrEmpty
+	}
+	content, err := readXMindEntry(f)
+	if err != nil {
+		return nil, err
+	}
+	var styles map[string]string
+	if sf, ok := files["styles.xml"]; ok {
+		if data, err := readXMindEntry(sf); err == nil {
+			styles = parseXMindStyles(data)
+		}
+	}
+	return decodeXMindLegacy(content, styles)
+}
+
+func readXMindEntry(f *zip.File) ([]byte, error) {
+	if f.UncompressedSize64 > maxXMindEntry {
+		return nil, fmt.Errorf("mindmap: xmind entry %s too large", f.Name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(io.LimitReader(rc, maxXMindEntry))
+}
+
+func nodeOfXMindTopic(t *xmindTopic) *Node {
+	n := &Node{ID: t.ID, Text: t.Title, Expanded: t.Branch != "folded"}
+	setLink(n, t.Href)
+	if t.Notes != nil && t.Notes.Plain != nil {
+		n.Note = strings.TrimSpace(t.Notes.Plain.Content)
+	}
+	if t.Style != nil {
+		n.Color = normalizeColor(t.Style.Properties["svg:fill"])
+	}
+	if len(t.Markers) > 0 {
+		n.Icon = t.Markers[0].MarkerID
+	}
+	if t.Children != nil {
+		for _, c := range t.Children.Attached {
+			n.Children = append(n.Children, nodeOfXMindTopic(c))
+		}
+	}
+	return n
+}
+
+// =====================================================
+// XMind 8 content.xml
+// =====================================================
+
+type xmindXMLContent struct {
+	Sheets []xmindXMLSheet `xml:"sheet"`
+}
+
+type xmindXMLSheet struct {
+	ID    string         `xml:"id,attr"`
+	Topic *xmindXMLTopic `xml:"topic"`
+	Title string         `xml:"title"`
+}
+
+type xmindXMLTopic struct {
+	ID      string `xml:"id,attr"`
+	Href    string `xml:"href,attr"`
+	Branch  string `xml:"branch,attr"`
+	StyleID string `xml:"style-id,attr"`
+	Title   string `xml:"title"`
+	Notes   *struct {
+		Plain string `xml:"plain"`
+	} `xml:"notes"`
+	Markers *struct {
+		Refs []struct {
+			ID string `xml:"marker-id,attr"`
+		} `xml:"marker-ref"`
+	} `xml:"marker-refs"`
+	Children *struct {
+		Topics []struct {
+			Type   string           `xml:"type,attr"`
+			Topics []*xmindXMLTopic `xml:"topic"`
+		} `xml:"topics"`
+	} `xml:"children"`
+}
+
+// encodeXMindLegacy writes the tree for XMind 8. Colors need a styles.xml and
+// are only kept in content.json.
+func encodeXMindLegacy(m *Map) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<xmap-content xmlns="urn:xmind:xmap:xmlns:content:2.0" xmlns:xlink="http://www.w3.org/1999/xlink" version="2.0">`)
+	fmt.Fprintf(&buf, `<sheet id="sheet-%s">`, xmlEscape(m.Root.ID))
+	writeXMindXMLTopic(&buf, m.Root, true)
+	fmt.Fprintf(&buf, `<title>%s</title></sheet></xmap-content>`, xmlEscape(m.Title))
+	return buf.Bytes(), nil
+}
+
+func writeXMindXMLTopic(buf *bytes.Buffer, n *Node, isRoot bool) {
+	fmt.Fprintf(buf, `<topic id="%s"`, xmlEscape(n.ID))
+	if isRoot {
+		buf.WriteString(` structure-class="org.xmind.ui.map.unbalanced"`)
+	}
+	if link := linkOf(n); link != "" {
+		fmt.Fprintf(buf, ` xlink:href="%s"`, xmlEscape(link))
+	}
+	if !n.Expanded && len(n.Children) > 0 {
+		buf.WriteString(` branch="folded"`)
+	}
+	fmt.Fprintf(buf, `><title>%s</title>`, xmlEscape(n.Text))
+	if n.Note != "" {
+		fmt.Fprintf(buf, `<notes><plain>%s</plain></notes>`, xmlEscape(n.Note))
+	}
+	if xmindMarkerRe.MatchString(n.Icon) {
+		fmt.Fprintf(buf, `<marker-refs><marker-ref marker-id="%s"/></marker-refs>`, xmlEscape(n.Icon))
+	}
+	if len(n.Children) > 0 {
+		buf.WriteString(`<children><topics type="attached">`)
+		for _, c := range n.Children {
+			writeXMindXMLTopic(buf, c, false)
+		}
+		buf.WriteString(`</topics></children>`)
+	}
+	buf.WriteString(`</topic>`)
+}
+
+func decodeXMindLegacy(data []byte, styles map[string]string) (*Map, error) {
+	var content xmindXMLContent
+	if err := xml.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("mindmap: parse xmind content.xml: %w", err)
+	}
+	if len(content.Sheets) == 0 || content.Sheets[0].Topic == nil {
+		return nil, ErrEmpty
+	}
+	root := nodeOfXMindXMLTopic(content.Sheets[0].Topic, styles)
+	return &Map{Title: root.Text, Root: root}, nil
+}
+
+func nodeOfXMindXMLTopic(t *xmindXMLTopic, styles map[string]string) *Node {
+	n := &Node{ID: t.ID, Text: strings.TrimSpace(t.Title), Expanded: t.Branch != "folded"}
+	setLink(n, t.Href)
+	if t.Notes != nil {
+		n.Note = strings.TrimSpace(t.Notes.Plain)
+	}
+	if t.StyleID != "" {
+		n.Color = styles[t.StyleID]
+	}
+	if t.Markers != nil && len(t.Markers.Refs) > 0 {
+		n.Icon = t.Markers.Refs[0].ID
+	}
+	if t.Children != nil {
+		for _, group := range t.Children.Topics {
+			if group.Type != "" && group.Type != "attached" {
+				continue // detached and summary topics are not part of the tree
+			}
+			for _, c := range group.Topics {
+				n.Children = append(n.Children, nodeOfXMindXMLTopic(c, styles))
+			}
+		}
+	}
+	return n
+}
+
+// parseXMindStyles returns style id -> topic fill color from styles.xml
+func parseXMindStyles(data []byte) map[string]string {
+	var doc struct {
+		Styles []struct {
+			ID    string `xml:"id,attr"`
+			Props struct {
+				Fill string `xml:"fill,attr"`
+			} `xml:"topic-properties"`
+		} `xml:"styles>style"`
+	}
+	colors := make(map[string]string)
+	if xml.Unmarshal(data, &doc) != nil {
+		return colors
+	}
+	for _, s := range doc.Styles {
+		if c := normalizeColor(s.Props.Fill); c != "" {
+			colors[s.ID] = c
+		}
+	}
+	return colors
+}
+
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
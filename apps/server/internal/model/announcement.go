@@ -18,7 +18,10 @@ type Announcement struct {
 	Province         string          `gorm:"type:varchar(50);index" json:"province"`
 	City             string          `gorm:"type:varchar(50)" json:"city"`
 	AttachmentURLs   JSONStringArray `gorm:"type:json" json:"attachment_urls"`
-	Status           int             `gorm:"type:tinyint;default:1;index" json:"status"` // 0: draft, 1: published, 2: archived
+	Status           int             `gorm:"type:tinyint;default:1;index" json:"status"`    // 0: draft, 1: published, 2: archived
+	CampaignID       *uint           `gorm:"index" json:"campaign_id,omitempty"`            // 所属招录批次
+	LinkedAt         *time.Time      `json:"linked_at,omitempty"`                           // 批次关联处理时间，为空表示待关联
+	LinkError        string          `gorm:"type:varchar(500)" json:"link_error,omitempty"` // 最近一次批次关联失败原因
 	CreatedAt        time.Time       `json:"created_at"`
 	UpdatedAt        time.Time       `json:"updated_at"`
 	DeletedAt        gorm.DeletedAt  `gorm:"index" json:"-"`
@@ -41,6 +44,21 @@ const (
 	AnnouncementTypeHiring       AnnouncementType = "拟录用公示"
 )
 
+// Stage 返回公告类型对应的职位生命周期阶段
+func (t AnnouncementType) Stage() LifecycleStage {
+	switch t {
+	case AnnouncementTypeRegistration:
+		return StageRegistration
+	case AnnouncementTypeWrittenExam:
+		return StageWrittenExam
+	case AnnouncementTypeInterview, AnnouncementTypeResult:
+		return StageInterview
+	case AnnouncementTypeHiring:
+		return StageHiring
+	}
+	return StageRecruitment
+}
+
 type AnnouncementStatus int
 
 const (
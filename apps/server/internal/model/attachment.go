@@ -0,0 +1,54 @@
+package model
+
+import (
+	"time"
+)
+
+// Attachment 归档的公告附件，按内容 SHA-256 去重，不同站点的相同文件只存一份
+type Attachment struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	SHA256         string     `gorm:"type:char(64);uniqueIndex;not null" json:"sha256"`
+	Size           int64      `gorm:"not null" json:"size"`
+	MimeType       string     `gorm:"type:varchar(100)" json:"mime_type"`
+	FileType       string     `gorm:"type:varchar(20);index" json:"file_type"` // pdf/excel/word/html/other
+	Ext            string     `gorm:"type:varchar(20)" json:"ext"`             // 首次抓取时的扩展名，解析时按此还原文件名
+	StorageBackend string     `gorm:"type:varchar(20)" json:"storage_backend"` // local/s3
+	StorageKey     string     `gorm:"type:varchar(255)" json:"storage_key"`
+	ParserVersion  string     `gorm:"type:varchar(50);index" json:"parser_version,omitempty"` // 最近一次解析使用的解析器版本
+	ParsedAt       *time.Time `json:"parsed_at,omitempty"`
+	PositionCount  int        `gorm:"default:0" json:"position_count"`                // 最近一次解析得到的职位数
+	ParseError     string     `gorm:"type:varchar(500)" json:"parse_error,omitempty"` // 最近一次解析的错误
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	Sources []AttachmentSource `gorm:"foreignKey:AttachmentID" json:"sources,omitempty"`
+}
+
+func (Attachment) TableName() string {
+	return "what_attachments"
+}
+
+// AttachmentSource 附件的一次抓取来源：同一文件可能由多个站点、多个公告发布
+type AttachmentSource struct {
+	ID                  uint      `gorm:"primaryKey" json:"id"`
+	AttachmentID        uint      `gorm:"index;not null" json:"attachment_id"`
+	URL                 string    `gorm:"type:varchar(1000);not null" json:"url"`
+	Name                string    `gorm:"type:varchar(255)" json:"name"` // 链接文字或文件名
+	AnnouncementID      *uint     `gorm:"index" json:"announcement_id,omitempty"`
+	FenbiAnnouncementID *uint     `gorm:"index" json:"fenbi_announcement_id,omitempty"`
+	FetchedAt           time.Time `json:"fetched_at"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+func (AttachmentSource) TableName() string {
+	return "what_attachment_sources"
+}
+
+// 附件文件类型
+const (
+	AttachmentTypePDF   = "pdf"
+	AttachmentTypeExcel = "excel"
+	AttachmentTypeWord  = "word"
+	AttachmentTypeHTML  = "html"
+	AttachmentTypeOther = "other"
+)
@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// 向量化内容类型
+const (
+	EmbeddingEntityQuestion = "question" // 题目（题干 + 解析）
+	EmbeddingEntityMaterial = "material" // 素材（标题 + 正文 + 解析）
+)
+
+// ContentEmbedding 内容向量
+// 向量先做 L2 归一化再按最大绝对值量化为 int8，余弦相似度 ≈ Σ(a_i·b_i)·scale_a·scale_b
+type ContentEmbedding struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	EntityType  string    `gorm:"type:varchar(20);uniqueIndex:uk_content_embedding_entity;not null" json:"entity_type"`
+	EntityID    uint      `gorm:"uniqueIndex:uk_content_embedding_entity;not null" json:"entity_id"`
+	CategoryID  uint      `gorm:"default:0;index" json:"category_id"`
+	Model       string    `gorm:"type:varchar(100);index" json:"model"` // 嵌入模型，切换模型后旧向量作废
+	Dim         int       `json:"dim"`
+	ContentHash string    `gorm:"type:varchar(40)" json:"content_hash"` // 向量化文本的 SHA1，未变化时跳过
+	Vector      []byte    `gorm:"type:blob" json:"-"`                   // int8 量化向量
+	Scale       float32   `json:"scale"`                                // 反量化系数
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (ContentEmbedding) TableName() string {
+	return "what_content_embeddings"
+}
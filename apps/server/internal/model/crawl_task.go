@@ -103,3 +103,26 @@ const (
 	CrawlLogLevelWarning CrawlLogLevel = "warning"
 	CrawlLogLevelError   CrawlLogLevel = "error"
 )
+
+// CrawlURL 爬虫 URL 库：记录已发现/已抓取/失败的 URL，重启后不再重复发现，
+// 并保存 ETag/Last-Modified 用于条件请求
+type CrawlURL struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	URLHash      string     `gorm:"type:varchar(32);uniqueIndex" json:"url_hash"`
+	URL          string     `gorm:"type:varchar(2048)" json:"url"`
+	Domain       string     `gorm:"type:varchar(255);index" json:"domain"`
+	Status       string     `gorm:"type:varchar(20);index" json:"status"` // seen, fetched, failed
+	StatusCode   int        `gorm:"default:0" json:"status_code"`
+	ContentHash  string     `gorm:"type:varchar(64)" json:"content_hash,omitempty"`
+	ETag         string     `gorm:"column:etag;type:varchar(255)" json:"etag,omitempty"`
+	LastModified string     `gorm:"type:varchar(64)" json:"last_modified,omitempty"`
+	FailCount    int        `gorm:"default:0" json:"fail_count"`
+	LastError    string     `gorm:"type:text" json:"last_error,omitempty"`
+	FetchedAt    *time.Time `gorm:"type:datetime" json:"fetched_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (CrawlURL) TableName() string {
+	return "what_crawl_urls"
+}
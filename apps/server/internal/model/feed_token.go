@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// UserFeedToken 用户专属订阅源令牌，订阅源地址中携带令牌，无需登录即可在阅读器中订阅
+type UserFeedToken struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"uniqueIndex;not null" json:"user_id"`
+	Token      string     `gorm:"type:char(32);uniqueIndex;not null" json:"token"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"` // 阅读器最近一次拉取
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func (UserFeedToken) TableName() string {
+	return "what_user_feed_tokens"
+}
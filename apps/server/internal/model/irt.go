@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+// QuestionIRTParam 题目 IRT 两参数 (2PL) 估计
+// P(答对 | θ) = 1 / (1 + exp(-a(θ - b)))，由作答记录定期标定
+type QuestionIRTParam struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	QuestionID     uint      `gorm:"uniqueIndex;not null" json:"question_id"`
+	Discrimination float64   `gorm:"default:1" json:"discrimination"`   // 区分度 a
+	Difficulty     float64   `gorm:"default:0;index" json:"difficulty"` // 难度 b，与能力 θ 同量纲
+	DifficultySE   float64   `json:"difficulty_se"`                     // 难度估计标准误
+	ResponseCount  int       `json:"response_count"`                    // 参与标定的作答人数
+	CorrectRate    float64   `json:"correct_rate"`                      // 参与标定的首次作答正确率
+	CalibratedAt   time.Time `gorm:"type:datetime" json:"calibrated_at"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (QuestionIRTParam) TableName() string {
+	return "what_question_irt_params"
+}
+
+// UserAbility 用户能力估计 θ，CategoryID 为 0 表示总体能力
+// 每次作答在线更新，标定任务完成后按新的题目参数重新估计
+type UserAbility struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	UserID        uint      `gorm:"uniqueIndex:uk_user_ability_category,priority:1;not null" json:"user_id"`
+	CategoryID    uint      `gorm:"uniqueIndex:uk_user_ability_category,priority:2;not null;default:0" json:"category_id"`
+	Theta         float64   `gorm:"default:0" json:"theta"`
+	Information   float64   `gorm:"default:1" json:"information"` // 后验信息量，标准误 = 1/√信息量
+	ResponseCount int       `gorm:"default:0" json:"response_count"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func (UserAbility) TableName() string {
+	return "what_user_abilities"
+}
+
+// UserAbilityResponse 用户能力响应
+type UserAbilityResponse struct {
+	CategoryID    uint    `json:"category_id"`
+	CategoryName  string  `json:"category_name,omitempty"`
+	Theta         float64 `json:"theta"`
+	SE            float64 `json:"se"`
+	Percentile    float64 `json:"percentile"` // θ 在标准正态下的百分位
+	ResponseCount int     `json:"response_count"`
+	UpdatedAt     string  `json:"updated_at"`
+}
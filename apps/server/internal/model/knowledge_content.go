@@ -173,6 +173,11 @@ type KnowledgeFlashCard struct {
 	UpdatedAt        time.Time      `json:"updated_at"`
 	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
 
+	// 用户导入的私有卡片：OwnerID 为所属用户（为空表示公共卡片），
+	// ExternalID 为导入来源标识（Anki GUID 或内容哈希），用于重复导入去重
+	OwnerID    *uint  `gorm:"index:idx_flash_card_owner_external" json:"owner_id,omitempty"`
+	ExternalID string `gorm:"type:varchar(64);index:idx_flash_card_owner_external" json:"external_id,omitempty"`
+
 	// 关联
 	KnowledgePoint *KnowledgePoint `gorm:"foreignKey:KnowledgePointID" json:"knowledge_point,omitempty"`
 	Category       *CourseCategory `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
@@ -201,6 +206,7 @@ type KnowledgeFlashCardResponse struct {
 	ViewCount        int           `json:"view_count"`
 	CollectCount     int           `json:"collect_count"`
 	MasterCount      int           `json:"master_count"`
+	OwnerID          *uint         `json:"owner_id,omitempty"`
 	CreatedAt        time.Time     `json:"created_at"`
 }
 
@@ -223,6 +229,7 @@ func (c *KnowledgeFlashCard) ToResponse() *KnowledgeFlashCardResponse {
 		ViewCount:        c.ViewCount,
 		CollectCount:     c.CollectCount,
 		MasterCount:      c.MasterCount,
+		OwnerID:          c.OwnerID,
 		CreatedAt:        c.CreatedAt,
 	}
 }
@@ -283,6 +290,9 @@ type MindMapNode struct {
 	Icon     string         `json:"icon,omitempty"`
 	Expanded bool           `json:"expanded"`
 	Children []*MindMapNode `json:"children,omitempty"`
+
+	// 节点关联的知识点
+	KnowledgePointID uint `json:"knowledge_point_id,omitempty"`
 }
 
 // MindMapData 思维导图数据结构
@@ -365,6 +375,8 @@ type UserFlashCardRecord struct {
 	CreatedAt    time.Time           `json:"created_at"`
 	UpdatedAt    time.Time           `json:"updated_at"`
 
+	ReviewSchedule `gorm:"embedded"`
+
 	// 关联
 	Card *KnowledgeFlashCard `gorm:"foreignKey:CardID" json:"card,omitempty"`
 }
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// KnowledgeTracingParam 知识点的贝叶斯知识追踪 (BKT) 参数
+// 由作答序列用 EM 拟合，作答人数不足时使用默认参数
+type KnowledgeTracingParam struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	KnowledgePointID uint      `gorm:"uniqueIndex;not null" json:"knowledge_point_id"`
+	PInit            float64   `json:"p_init"`  // 初始掌握概率 P(L0)
+	PLearn           float64   `json:"p_learn"` // 每次练习后由未掌握转为掌握的概率 P(T)
+	PGuess           float64   `json:"p_guess"` // 未掌握时答对的概率 P(G)
+	PSlip            float64   `json:"p_slip"`  // 已掌握时答错的概率 P(S)
+	ResponseCount    int       `json:"response_count"`
+	SequenceCount    int       `json:"sequence_count"` // 参与拟合的用户序列数
+	LogLikelihood    float64   `json:"log_likelihood"`
+	FittedAt         time.Time `gorm:"type:datetime" json:"fitted_at"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+func (KnowledgeTracingParam) TableName() string {
+	return "what_knowledge_tracing_params"
+}
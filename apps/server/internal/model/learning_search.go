@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+// 学习内容搜索文档类型
+const (
+	LearningDocQuestion        = "question"         // 题目
+	LearningDocMaterial        = "material"         // 素材
+	LearningDocCourse          = "course"           // 课程
+	LearningDocChapter         = "chapter"          // 课程章节
+	LearningDocKnowledgePoint  = "knowledge_point"  // 知识点
+	LearningDocKnowledgeDetail = "knowledge_detail" // 知识点详情
+	LearningDocNote            = "note"             // 公开笔记
+)
+
+// LearningDocTypes 全部文档类型（用于分面统计顺序）
+var LearningDocTypes = []string{
+	LearningDocQuestion,
+	LearningDocMaterial,
+	LearningDocCourse,
+	LearningDocChapter,
+	LearningDocKnowledgePoint,
+	LearningDocKnowledgeDetail,
+	LearningDocNote,
+}
+
+// LearningSearchDoc 学习内容搜索文档
+// 题目、素材、课程、章节、知识点、公开笔记的统一投影，只保存对用户可见（已发布/已启用/公开）的内容，
+// title/content 上建有 ngram 全文索引
+type LearningSearchDoc struct {
+	ID         uint            `gorm:"primaryKey" json:"id"`
+	DocType    string          `gorm:"type:varchar(30);uniqueIndex:uk_learning_search_source;not null" json:"doc_type"`
+	SourceID   uint            `gorm:"uniqueIndex:uk_learning_search_source;not null" json:"source_id"`
+	ParentID   uint            `gorm:"default:0;index" json:"parent_id,omitempty"` // 章节所属课程 / 详情所属知识点
+	Title      string          `gorm:"type:varchar(300)" json:"title"`
+	Content    string          `gorm:"type:mediumtext" json:"content"` // 纯文本正文
+	Subject    string          `gorm:"type:varchar(50);index" json:"subject,omitempty"`
+	ExamType   string          `gorm:"type:varchar(50);index" json:"exam_type,omitempty"`
+	CategoryID uint            `gorm:"default:0" json:"category_id,omitempty"`
+	Tags       JSONStringArray `gorm:"type:json" json:"tags,omitempty"`
+	IsVIP      bool            `gorm:"default:false;index" json:"is_vip"`
+	Popularity int             `gorm:"default:0" json:"popularity"` // 浏览/作答等热度，相关度相同时排序用
+
+	SourceUpdatedAt time.Time `gorm:"type:datetime" json:"source_updated_at"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+func (LearningSearchDoc) TableName() string {
+	return "what_learning_search_docs"
+}
+
+// LearningSearchSyncState 各类型文档的同步水位
+type LearningSearchSyncState struct {
+	DocType   string    `gorm:"type:varchar(30);primaryKey" json:"doc_type"`
+	Watermark time.Time `gorm:"type:datetime" json:"watermark"` // 已同步到的源数据更新时间
+	LastRunAt time.Time `gorm:"type:datetime" json:"last_run_at"`
+	Upserted  int       `gorm:"default:0" json:"upserted"` // 最近一次写入数
+	Removed   int       `gorm:"default:0" json:"removed"`  // 最近一次删除数
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (LearningSearchSyncState) TableName() string {
+	return "what_learning_search_sync_states"
+}
@@ -16,6 +16,7 @@ type ListPage struct {
 	ArticleCount      int            `gorm:"default:0" json:"article_count"`
 	ArticleSelector   string         `gorm:"type:varchar(255)" json:"article_selector"`
 	PaginationPattern string         `gorm:"type:varchar(255)" json:"pagination_pattern"`
+	Adapter           string         `gorm:"type:text" json:"adapter,omitempty"`                    // site adapter definition (YAML/JSON)
 	Status            string         `gorm:"type:varchar(20);default:'active';index" json:"status"` // active, inactive, error
 	CreatedAt         time.Time      `json:"created_at"`
 	UpdatedAt         time.Time      `json:"updated_at"`
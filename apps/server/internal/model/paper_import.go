@@ -0,0 +1,91 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// 试卷导入状态
+const (
+	PaperImportStatusDraft     = "draft"     // 已生成草稿，待审核
+	PaperImportStatusPublished = "published" // 已发布
+	PaperImportStatusDiscarded = "discarded" // 已放弃，草稿已删除
+)
+
+// 试卷解析方式
+const (
+	PaperImportMethodStructural = "structural" // 规则切分
+	PaperImportMethodLLM        = "llm"        // LLM 结构化
+)
+
+// PaperImport 真题试卷导入记录
+// 试卷文件（及答案文件）解析后生成草稿试卷、草稿题目和材料，审核后发布
+type PaperImport struct {
+	ID                uint                  `gorm:"primaryKey" json:"id"`
+	PaperID           *uint                 `gorm:"index" json:"paper_id,omitempty"` // 生成的草稿试卷
+	Title             string                `gorm:"type:varchar(200)" json:"title"`
+	PaperFile         string                `gorm:"type:varchar(255)" json:"paper_file"`
+	AnswerFile        string                `gorm:"type:varchar(255)" json:"answer_file,omitempty"`
+	Method            string                `gorm:"type:varchar(20)" json:"method"` // structural/llm
+	Status            string                `gorm:"type:varchar(20);default:'draft';index" json:"status"`
+	SectionCount      int                   `json:"section_count"`
+	QuestionCount     int                   `json:"question_count"`
+	MaterialCount     int                   `json:"material_count"`
+	MaterialIDs       JSONIntArray          `gorm:"type:json" json:"material_ids,omitempty"`
+	MissingAnswers    JSONIntArray          `gorm:"type:json" json:"missing_answers,omitempty"` // 未匹配到答案的题号
+	Warnings          JSONStringArray       `gorm:"type:json" json:"warnings,omitempty"`
+	Duplicates        PaperImportDuplicates `gorm:"type:json" json:"duplicates,omitempty"`          // 查重命中的题目
+	ReusedQuestionIDs JSONIntArray          `gorm:"type:json" json:"reused_question_ids,omitempty"` // 与题库重复、直接引用的已有题目
+	CreatedBy         uint                  `json:"created_by"`
+	PublishedAt       *time.Time            `gorm:"type:datetime" json:"published_at,omitempty"`
+	CreatedAt         time.Time             `json:"created_at"`
+	UpdatedAt         time.Time             `json:"updated_at"`
+}
+
+func (PaperImport) TableName() string {
+	return "what_paper_imports"
+}
+
+// PaperImportDuplicate 导入题目的查重结论
+// rejected 的题目不再新建，试卷直接引用 DuplicateOfID；flagged 的题目照常创建并记录待审核题对
+type PaperImportDuplicate struct {
+	Section       string  `json:"section"`
+	Number        int     `json:"number"`                // 题号
+	QuestionID    uint    `json:"question_id,omitempty"` // 新建的草稿题目，rejected 时为空
+	Decision      string  `json:"decision"`              // flagged/rejected
+	DuplicateOfID uint    `json:"duplicate_of_id"`
+	Similarity    float64 `json:"similarity"`
+}
+
+// PaperImportDuplicates 查重结论列表
+type PaperImportDuplicates []PaperImportDuplicate
+
+// Value 实现 driver.Valuer 接口
+func (j PaperImportDuplicates) Value() (driver.Value, error) {
+	if j == nil {
+		return "[]", nil
+	}
+	return json.Marshal(j)
+}
+
+// Scan 实现 sql.Scanner 接口
+func (j *PaperImportDuplicates) Scan(value interface{}) error {
+	if value == nil {
+		*j = PaperImportDuplicates{}
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("invalid type for PaperImportDuplicates")
+	}
+
+	return json.Unmarshal(bytes, j)
+}
@@ -13,11 +13,11 @@ type Position struct {
 	FenbiAnnouncementID *uint         `gorm:"index" json:"fenbi_announcement_id"`                                                      // 粉笔公告ID
 	PositionID         string         `gorm:"type:varchar(50);uniqueIndex;not null" json:"position_id"`                                // 职位唯一标识
 	PositionCode       string         `gorm:"type:varchar(50);index" json:"position_code"`                                             // 职位代码
-	PositionName       string         `gorm:"type:varchar(200);not null;index" json:"position_name" es:"type:text,analyzer:ik_max_word"` // 岗位名称
+	PositionName       string         `gorm:"type:varchar(200);not null;index" json:"position_name" es:"type:text,analyzer:ik_max_word,search_analyzer:ik_smart,suggest"` // 岗位名称
 
 	// 招录单位
 	DepartmentCode  string `gorm:"type:varchar(50);index" json:"department_code"`                                     // 单位代码
-	DepartmentName  string `gorm:"type:varchar(200);index" json:"department_name" es:"type:text,analyzer:ik_max_word"` // 招录单位
+	DepartmentName  string `gorm:"type:varchar(200);index" json:"department_name" es:"type:text,analyzer:ik_max_word,search_analyzer:ik_smart"` // 招录单位
 	DepartmentLevel string `gorm:"type:varchar(50)" json:"department_level"`                                           // 单位层级
 
 	// 招录条件
@@ -25,12 +25,12 @@ type Position struct {
 	Education        string          `gorm:"type:varchar(50);index" json:"education"`         // 学历要求
 	Degree           string          `gorm:"type:varchar(50)" json:"degree"`                  // 学位要求
 	MajorCategory    string          `gorm:"type:varchar(100)" json:"major_category"`         // 专业大类
-	MajorRequirement string          `gorm:"type:text" json:"major_requirement"`              // 专业要求原文
+	MajorRequirement string          `gorm:"type:text" json:"major_requirement" es:"type:text,analyzer:ik_smart"`              // 专业要求原文
 	MajorList        JSONStringArray `gorm:"type:json" json:"major_list"`                     // 专业列表JSON
 	IsUnlimitedMajor bool            `gorm:"default:false;index" json:"is_unlimited_major"`   // 是否不限专业
 
 	// 工作地点
-	WorkLocation string `gorm:"type:varchar(200)" json:"work_location"` // 工作地点
+	WorkLocation string `gorm:"type:varchar(200)" json:"work_location" es:"type:text,analyzer:ik_smart"` // 工作地点
 	Province     string `gorm:"type:varchar(50);index" json:"province"` // 省份
 	City         string `gorm:"type:varchar(50);index" json:"city"`     // 城市
 	District     string `gorm:"type:varchar(50)" json:"district"`       // 区县
@@ -46,7 +46,7 @@ type Position struct {
 	Gender               string `gorm:"type:varchar(10)" json:"gender"`                    // 性别要求
 	HouseholdRequirement string `gorm:"type:varchar(200)" json:"household_requirement"`    // 户籍要求
 	ServicePeriod        string `gorm:"type:varchar(100)" json:"service_period"`           // 服务期限
-	OtherConditions      string `gorm:"type:text" json:"other_conditions"`                 // 其他条件
+	OtherConditions      string `gorm:"type:text" json:"other_conditions" es:"type:text,analyzer:ik_smart"`                 // 其他条件
 
 	// 考试信息
 	ExamType     string `gorm:"type:varchar(50);index" json:"exam_type"`  // 考试类型
@@ -60,17 +60,19 @@ type Position struct {
 
 	// 其他信息
 	SalaryRange string `gorm:"type:varchar(100)" json:"salary_range"` // 薪资范围
-	Remark      string `gorm:"type:text" json:"remark"`               // 备注
-	SourceURL   string `gorm:"type:varchar(500)" json:"source_url"`   // 来源链接
+	Remark      string `gorm:"type:text" json:"remark" es:"type:text,analyzer:ik_smart"`               // 备注
+	SourceURL   string `gorm:"type:varchar(500)" json:"source_url" es:"type:keyword,index:false"`   // 来源链接
 
 	// 报名统计(可选)
 	ApplicantCount   int     `gorm:"default:0" json:"applicant_count"`                       // 报名人数
 	PassCount        int     `gorm:"default:0" json:"pass_count"`                            // 过审人数
-	CompetitionRatio float64 `gorm:"type:decimal(10,2);default:0" json:"competition_ratio"`  // 竞争比
+	CompetitionRatio float64 `gorm:"type:decimal(10,2);default:0" json:"competition_ratio" es:"type:float"`  // 竞争比
 
 	// AI解析元数据
 	ParseConfidence int        `gorm:"default:0" json:"parse_confidence"` // 解析置信度(0-100)
 	ParsedAt        *time.Time `gorm:"type:datetime" json:"parsed_at"`    // 解析时间
+	AttachmentID    *uint      `gorm:"index" json:"attachment_id,omitempty"`                 // 来源附件（附件归档）
+	ParserVersion   string     `gorm:"type:varchar(50);index" json:"parser_version,omitempty"` // 产出该职位的解析器版本
 
 	// 状态
 	Status int `gorm:"type:tinyint;default:0;index" json:"status"` // 0: pending, 1: published, 2: offline
@@ -81,7 +83,7 @@ type Position struct {
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// 关联
-	Announcements []Announcement `gorm:"many2many:what_position_announcements;" json:"announcements,omitempty"`
+	Announcements []Announcement `gorm:"many2many:what_position_announcements;" json:"announcements,omitempty" es:"-"`
 }
 
 func (Position) TableName() string {
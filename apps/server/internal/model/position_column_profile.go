@@ -0,0 +1,51 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// JSONStringMap JSON 字符串映射类型
+type JSONStringMap map[string]string
+
+func (j JSONStringMap) Value() (driver.Value, error) {
+	if j == nil {
+		return nil, nil
+	}
+	return json.Marshal(j)
+}
+
+func (j *JSONStringMap) Scan(value interface{}) error {
+	if value == nil {
+		*j = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, j)
+}
+
+// PositionColumnProfile 职位表列映射方案
+// 各省职位表的列名、表头层数不尽相同，按来源站点或考试类型保存映射，解析时自动套用
+type PositionColumnProfile struct {
+	ID         uint          `gorm:"primaryKey" json:"id"`
+	Name       string        `gorm:"type:varchar(100);not null" json:"name"`
+	SourceSite string        `gorm:"type:varchar(200);index" json:"source_site"` // 来源站点域名，同时匹配子域名，为空匹配所有站点
+	ExamType   string        `gorm:"type:varchar(50);index" json:"exam_type"`    // 考试类型，为空匹配所有类型
+	Columns    JSONStringMap `gorm:"type:json" json:"columns"`                   // 列路径（如 招录条件/学历）或列名 → 字段，"-" 表示忽略该列
+	HeaderRow  int           `json:"header_row"`                                 // 表头起始行（从 1 开始），0 自动识别
+	HeaderRows int           `json:"header_rows"`                                // 表头行数，0 自动识别
+	Priority   int           `gorm:"default:0" json:"priority"`                  // 同等匹配程度时优先级高者生效
+	IsActive   bool          `gorm:"default:true" json:"is_active"`
+	Remark     string        `gorm:"type:varchar(500)" json:"remark,omitempty"`
+	CreatedBy  uint          `json:"created_by"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+func (PositionColumnProfile) TableName() string {
+	return "what_position_column_profiles"
+}
@@ -29,6 +29,7 @@ type PositionHistory struct {
 	Source           string    `gorm:"type:varchar(100)" json:"source"`                       // 数据来源
 	SourceURL        string    `gorm:"type:varchar(500)" json:"source_url"`                   // 来源链接
 	Remark           string    `gorm:"type:text" json:"remark"`                               // 备注
+	CampaignID       *uint     `gorm:"index" json:"campaign_id,omitempty"`                    // 由公告关联写入时的招录批次
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
 }
@@ -136,6 +137,12 @@ type ScoreLinePrediction struct {
 	HistoricalScores []float64 `json:"historical_scores"` // 历年分数
 	HistoricalYears  []int     `json:"historical_years"`  // 历年年份
 	Basis            string    `json:"basis"`             // 预测依据
+
+	IntervalLevel  float64 `json:"interval_level,omitempty"`  // 预测区间名义覆盖率
+	PoolLevel      string  `json:"pool_level,omitempty"`      // 使用的池化层级: position/group/province/global
+	SampleSize     int     `json:"sample_size,omitempty"`     // 建模样本量
+	TargetYear     int     `json:"target_year,omitempty"`     // 预测年份
+	YearAdjustment float64 `json:"year_adjustment,omitempty"` // 年度难度调整值
 }
 
 // AggregatedHistoryStats 聚合历史统计
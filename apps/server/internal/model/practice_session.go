@@ -21,6 +21,7 @@ const (
 	PracticeSessionTypeRandom      PracticeSessionType = "random"      // 随机练习
 	PracticeSessionTypeTimed       PracticeSessionType = "timed"       // 计时练习
 	PracticeSessionTypeWrongRedo   PracticeSessionType = "wrong_redo"  // 错题重做
+	PracticeSessionTypeAdaptive    PracticeSessionType = "adaptive"    // 自适应练习（按能力估计逐题选题）
 )
 
 // PracticeSessionStatus 练习会话状态
@@ -61,6 +62,9 @@ type PracticeSession struct {
 	InterruptReason string     `gorm:"type:varchar(50)" json:"interrupt_reason,omitempty"` // 中断原因
 	ElapsedAtSave   int        `gorm:"default:0" json:"elapsed_at_save"`                   // 保存时已用时间
 
+	// 自适应练习状态
+	Adaptive *AdaptiveSessionState `gorm:"type:json" json:"adaptive,omitempty"`
+
 	// 关联
 	User *User `gorm:"foreignKey:UserID" json:"-"`
 }
@@ -117,6 +121,42 @@ func (c *PracticeSessionConfig) Scan(value interface{}) error {
 	return json.Unmarshal(bytes, c)
 }
 
+// AdaptiveSessionState 自适应练习的能力估计轨迹
+type AdaptiveSessionState struct {
+	CategoryID  uint      `json:"category_id"`       // 估计能力所用分类，0 表示总体
+	Target      float64   `json:"target"`            // 目标答对概率
+	Theta       float64   `json:"theta"`             // 当前能力估计
+	Information float64   `json:"information"`       // 本次练习内的信息量
+	SE          float64   `json:"se"`                // 当前标准误
+	History     []float64 `json:"history,omitempty"` // 每题作答后的能力估计
+	Converged   bool      `json:"converged"`         // 是否已收敛
+}
+
+// Value 实现 driver.Valuer 接口
+func (a AdaptiveSessionState) Value() (driver.Value, error) {
+	return json.Marshal(a)
+}
+
+// Scan 实现 sql.Scanner 接口
+func (a *AdaptiveSessionState) Scan(value interface{}) error {
+	if value == nil {
+		*a = AdaptiveSessionState{}
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("invalid type for AdaptiveSessionState")
+	}
+
+	return json.Unmarshal(bytes, a)
+}
+
 // SessionQuestion 会话题目项
 type SessionQuestion struct {
 	QuestionID uint   `json:"question_id"`
@@ -292,6 +332,7 @@ type PracticeSessionDetailResponse struct {
 	PracticeSessionResponse
 	Config    PracticeSessionConfig       `json:"config"`
 	Questions []SessionQuestionWithDetail `json:"questions"`
+	Adaptive  *AdaptiveSessionState       `json:"adaptive,omitempty"`
 }
 
 // SessionQuestionWithDetail 带详情的会话题目
@@ -379,5 +420,16 @@ func GetQuickPracticeTemplates() []PracticeTemplate {
 			Icon:  "timer",
 			Color: "red",
 		},
+		{
+			ID:          "adaptive",
+			Name:        "自适应测评",
+			Description: "按你的水平逐题选题，估计稳定后自动结束",
+			SessionType: PracticeSessionTypeAdaptive,
+			Config: PracticeSessionConfig{
+				QuestionCount: 30, // 题量上限
+			},
+			Icon:  "gauge",
+			Color: "teal",
+		},
 	}
 }
@@ -0,0 +1,59 @@
+package model
+
+import "time"
+
+// 疑似重复题状态
+const (
+	QuestionDuplicatePending = "pending" // 待审核
+	QuestionDuplicateMerged  = "merged"  // 已合并
+	QuestionDuplicateIgnored = "ignored" // 已忽略（确认不是重复）
+)
+
+// 疑似重复题来源
+const (
+	QuestionDuplicateSourceImport = "import" // 人工/MCP 导入
+	QuestionDuplicateSourceLLM    = "llm"    // LLM 批量生成自动导入
+	QuestionDuplicateSourceAdmin  = "admin"  // 管理后台批量创建
+	QuestionDuplicateSourceScan   = "scan"   // 签名补齐时发现的存量重复
+)
+
+// QuestionSignature 题目 MinHash 签名（题干 + 选项的字符 n-gram）
+type QuestionSignature struct {
+	QuestionID   uint      `gorm:"primaryKey;autoIncrement:false" json:"question_id"`
+	Signature    []byte    `gorm:"type:blob" json:"-"` // num_hashes 个小端 uint32
+	ShingleCount int       `json:"shingle_count"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (QuestionSignature) TableName() string {
+	return "what_question_signatures"
+}
+
+// QuestionLSHBand 题目签名的 LSH 分段桶，同桶题目为候选重复
+type QuestionLSHBand struct {
+	ID         uint   `gorm:"primaryKey" json:"id"`
+	Band       int    `gorm:"index:idx_question_lsh_bucket,priority:1;not null" json:"band"`
+	Bucket     uint64 `gorm:"type:bigint unsigned;index:idx_question_lsh_bucket,priority:2;not null" json:"bucket"`
+	QuestionID uint   `gorm:"index;not null" json:"question_id"`
+}
+
+func (QuestionLSHBand) TableName() string {
+	return "what_question_lsh_bands"
+}
+
+// QuestionDuplicate 疑似重复题对
+type QuestionDuplicate struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	QuestionID    uint       `gorm:"uniqueIndex:uk_question_duplicate_pair;not null" json:"question_id"`           // 较新的题目
+	DuplicateOfID uint       `gorm:"uniqueIndex:uk_question_duplicate_pair;index;not null" json:"duplicate_of_id"` // 已存在的相似题目
+	Similarity    float64    `gorm:"type:decimal(5,4)" json:"similarity"`                                          // 估计 Jaccard 相似度
+	Source        string     `gorm:"type:varchar(20);index" json:"source"`                                         // 发现来源
+	Status        string     `gorm:"type:varchar(20);default:'pending';index" json:"status"`
+	ResolvedBy    *uint      `json:"resolved_by,omitempty"`
+	ResolvedAt    *time.Time `gorm:"type:datetime" json:"resolved_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func (QuestionDuplicate) TableName() string {
+	return "what_question_duplicates"
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+)
+
+// RecruitmentCampaign 招录批次：同一次招录的招录公告及后续的报名统计、笔试、面试、成绩、拟录用公告
+type RecruitmentCampaign struct {
+	ID                        uint       `gorm:"primaryKey" json:"id"`
+	Name                      string     `gorm:"type:varchar(255);index" json:"name"`            // 去掉公告类型词后的标题主干
+	Department                string     `gorm:"type:varchar(200);index" json:"department"`      // 发布单位
+	Year                      int        `gorm:"index" json:"year"`                              // 考试年度
+	ExamType                  string     `gorm:"type:varchar(20);index" json:"exam_type"`        // 考试类型
+	Province                  string     `gorm:"type:varchar(50);index" json:"province"`         // 省份
+	RecruitmentAnnouncementID *uint      `gorm:"index" json:"recruitment_announcement_id"`       // 招录公告ID
+	AnnouncementCount         int        `gorm:"default:0" json:"announcement_count"`            // 已关联公告数
+	LatestStage               string     `gorm:"type:varchar(50)" json:"latest_stage"`           // 最新进展阶段
+	LatestPublishDate         *time.Time `gorm:"type:date" json:"latest_publish_date,omitempty"` // 最新公告发布日期
+	CreatedAt                 time.Time  `json:"created_at"`
+	UpdatedAt                 time.Time  `json:"updated_at"`
+}
+
+func (RecruitmentCampaign) TableName() string {
+	return "what_recruitment_campaigns"
+}
+
+// PositionTimelineEvent 职位时间线中的一条公告
+type PositionTimelineEvent struct {
+	AnnouncementID   uint       `json:"announcement_id"`
+	Title            string     `json:"title"`
+	AnnouncementType string     `json:"announcement_type"`
+	Stage            string     `json:"stage"`
+	PublishDate      *time.Time `json:"publish_date,omitempty"`
+	SourceURL        string     `json:"source_url,omitempty"`
+	Mentioned        bool       `json:"mentioned"` // 公告正文中出现了该职位
+}
+
+// PositionTimeline 职位所在招录批次的完整时间线及已知结果
+type PositionTimeline struct {
+	PositionID     uint                     `json:"position_id"`
+	PositionCode   string                   `json:"position_code"`
+	PositionName   string                   `json:"position_name"`
+	DepartmentName string                   `json:"department_name"`
+	Campaign       *RecruitmentCampaign     `json:"campaign,omitempty"`
+	Events         []PositionTimelineEvent  `json:"events"`
+	Outcome        *PositionHistoryResponse `json:"outcome,omitempty"` // 进面分数线、最终成绩等
+}
@@ -79,3 +79,25 @@ type PositionRegistrationTrend struct {
 	CompetitionRatio float64 `json:"competition_ratio"`
 	DailyIncrement   int     `json:"daily_increment"`
 }
+
+// CompetitionForecast 职位最终竞争比预测
+type CompetitionForecast struct {
+	PositionID        string  `json:"position_id"`
+	PositionName      string  `json:"position_name"`
+	DepartmentName    string  `json:"department_name"`
+	RecruitCount      int     `json:"recruit_count"`
+	CountBasis        string  `json:"count_basis"`         // 计数口径: apply(报名) / pass(过审)
+	CurrentCount      int     `json:"current_count"`       // 当前人数
+	CurrentRatio      float64 `json:"current_ratio"`       // 当前竞争比
+	ForecastCount     int     `json:"forecast_count"`      // 预测最终人数
+	ForecastCountLow  int     `json:"forecast_count_low"`  // 预测区间下限
+	ForecastCountHigh int     `json:"forecast_count_high"` // 预测区间上限
+	ForecastRatio     float64 `json:"forecast_ratio"`      // 预测最终竞争比
+	ForecastRatioLow  float64 `json:"forecast_ratio_low"`  // 竞争比区间下限
+	ForecastRatioHigh float64 `json:"forecast_ratio_high"` // 竞争比区间上限
+	ConfidenceLevel   float64 `json:"confidence_level"`    // 置信度 (0-1)
+	Progress          float64 `json:"progress"`            // 报名窗口已过比例 (0-1)
+	DaysRemaining     int     `json:"days_remaining"`      // 距报名截止天数
+	ReferenceCurves   int     `json:"reference_curves"`    // 参考的往年报名曲线数量
+	Basis             string  `json:"basis"`               // 预测依据
+}
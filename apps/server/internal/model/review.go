@@ -0,0 +1,164 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ReviewItemType 复习条目类型
+type ReviewItemType string
+
+const (
+	ReviewItemFlashCard     ReviewItemType = "flash_card"     // 速记卡片
+	ReviewItemWrongQuestion ReviewItemType = "wrong_question" // 错题
+)
+
+// ReviewSchedule 间隔重复调度状态，嵌入速记卡片记录与错题
+// FSRS 使用稳定性与难度，SM-2 使用难度系数与间隔，两者都会保持最新
+type ReviewSchedule struct {
+	SRSState      string  `gorm:"column:srs_state;type:varchar(20);default:'new'" json:"srs_state"` // new/learning/review/relearning
+	Stability     float64 `gorm:"default:0" json:"stability"`                                       // 记忆稳定性（天）
+	SRSDifficulty float64 `gorm:"column:srs_difficulty;default:0" json:"srs_difficulty"`            // FSRS 难度 1-10
+	Lapses        int     `gorm:"default:0" json:"lapses"`                                          // 遗忘次数
+}
+
+// ReviewLog 复习日志，用于个人参数优化与统计
+// 表名: what_review_logs
+type ReviewLog struct {
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	UserID        uint           `gorm:"index:idx_review_log_user_time;not null" json:"user_id"`
+	ItemType      ReviewItemType `gorm:"type:varchar(20);index:idx_review_log_item;not null" json:"item_type"`
+	ItemID        uint           `gorm:"index:idx_review_log_item;not null" json:"item_id"`
+	Rating        int            `gorm:"type:tinyint;not null" json:"rating"` // 1=忘记 2=困难 3=良好 4=简单
+	State         string         `gorm:"type:varchar(20)" json:"state"`       // 复习前的状态
+	ElapsedDays   float64        `json:"elapsed_days"`                        // 距上次复习的天数
+	ScheduledDays int            `json:"scheduled_days"`                      // 本次安排的间隔（天）
+	Stability     float64        `json:"stability"`                           // 复习后的稳定性
+	Difficulty    float64        `json:"difficulty"`                          // 复习后的难度
+	Algorithm     string         `gorm:"type:varchar(20)" json:"algorithm"`   // fsrs / sm2
+	ReviewedAt    time.Time      `gorm:"type:datetime;index:idx_review_log_user_time" json:"reviewed_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+func (ReviewLog) TableName() string {
+	return "what_review_logs"
+}
+
+// JSONFloatArray JSON浮点数组类型
+type JSONFloatArray []float64
+
+// Value 实现 driver.Valuer 接口
+func (j JSONFloatArray) Value() (driver.Value, error) {
+	if j == nil {
+		return "[]", nil
+	}
+	return json.Marshal(j)
+}
+
+// Scan 实现 sql.Scanner 接口
+func (j *JSONFloatArray) Scan(value interface{}) error {
+	if value == nil {
+		*j = []float64{}
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("invalid type for JSONFloatArray")
+	}
+
+	return json.Unmarshal(bytes, j)
+}
+
+// SRSUserParams 用户的间隔重复设置与个人 FSRS 参数
+// 表名: what_srs_user_params
+type SRSUserParams struct {
+	ID               uint           `gorm:"primaryKey" json:"id"`
+	UserID           uint           `gorm:"uniqueIndex;not null" json:"user_id"`
+	Algorithm        string         `gorm:"type:varchar(20)" json:"algorithm"`  // 空表示使用系统默认
+	RequestRetention float64        `json:"request_retention"`                  // 0 表示使用系统默认
+	Weights          JSONFloatArray `gorm:"type:json" json:"weights,omitempty"` // 个人 FSRS 参数，为空时使用默认参数
+	ReviewCount      int            `json:"review_count"`                       // 参与优化的复习记录数
+	LogLoss          float64        `json:"log_loss"`                           // 个人参数的预测损失
+	DefaultLogLoss   float64        `json:"default_log_loss"`                   // 默认参数的预测损失
+	OptimizedAt      *time.Time     `gorm:"type:datetime" json:"optimized_at,omitempty"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+}
+
+func (SRSUserParams) TableName() string {
+	return "what_srs_user_params"
+}
+
+// =====================================================
+// 响应结构
+// =====================================================
+
+// ReviewQueueItem 今日复习队列中的条目
+type ReviewQueueItem struct {
+	ItemType       ReviewItemType              `json:"item_type"`
+	ItemID         uint                        `json:"item_id"` // 卡片ID或错题记录ID
+	State          string                      `json:"state"`
+	Due            *time.Time                  `json:"due,omitempty"`
+	Retrievability float64                     `json:"retrievability"` // 当前回忆概率估计
+	FlashCard      *KnowledgeFlashCardResponse `json:"flash_card,omitempty"`
+	WrongQuestion  *WrongQuestionResponse      `json:"wrong_question,omitempty"`
+}
+
+// ReviewQueueResponse 今日复习队列
+type ReviewQueueResponse struct {
+	Items         []ReviewQueueItem `json:"items"`
+	DueCount      int64             `json:"due_count"`      // 到期待复习总数
+	ReviewedToday int64             `json:"reviewed_today"` // 今日已复习
+	NewToday      int64             `json:"new_today"`      // 今日已学新卡片
+	ReviewLimit   int               `json:"review_limit"`
+	NewLimit      int               `json:"new_limit"`
+}
+
+// ReviewResult 一次复习后的调度结果
+type ReviewResult struct {
+	ItemType   ReviewItemType `json:"item_type"`
+	ItemID     uint           `json:"item_id"`
+	Rating     int            `json:"rating"`
+	State      string         `json:"state"`
+	Interval   int            `json:"interval"` // 天，0 表示当天再次学习
+	Due        time.Time      `json:"due"`
+	Stability  float64        `json:"stability"`
+	Difficulty float64        `json:"difficulty"`
+	Algorithm  string         `json:"algorithm"`
+}
+
+// ReviewSettingsResponse 用户复习设置
+type ReviewSettingsResponse struct {
+	Algorithm        string     `json:"algorithm"`
+	RequestRetention float64    `json:"request_retention"`
+	Personalized     bool       `json:"personalized"` // 是否已使用个人优化参数
+	ReviewCount      int        `json:"review_count"`
+	LogLoss          float64    `json:"log_loss,omitempty"`
+	DefaultLogLoss   float64    `json:"default_log_loss,omitempty"`
+	OptimizedAt      *time.Time `json:"optimized_at,omitempty"`
+	TotalReviews     int64      `json:"total_reviews"` // 复习日志总数
+	MinReviews       int        `json:"min_reviews"`   // 优化所需最少复习数
+}
+
+// ReviewForecastDay 某天到期的复习量
+type ReviewForecastDay struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+}
+
+// ReviewStatsResponse 复习统计
+type ReviewStatsResponse struct {
+	TodayReviewed int64               `json:"today_reviewed"`
+	TodayNew      int64               `json:"today_new"`
+	TotalReviews  int64               `json:"total_reviews"`
+	Retention     float64             `json:"retention"` // 近30天非当天重复复习的回忆率
+	Forecast      []ReviewForecastDay `json:"forecast"`  // 未来7天到期量
+}
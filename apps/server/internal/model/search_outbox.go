@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// 搜索同步实体类型
+const (
+	SearchEntityPosition = "position"
+)
+
+// SearchOutbox 搜索索引同步发件箱
+// 业务写入时在同一事务内追加一条记录，由同步任务异步刷入 Elasticsearch。
+// 同步时按实体的当前状态决定写入或删除，因此同一实体的多条记录可以合并处理。
+type SearchOutbox struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	EntityType  string     `gorm:"type:varchar(50);index:idx_search_outbox_entity;not null" json:"entity_type"` // 实体类型
+	EntityID    uint       `gorm:"index:idx_search_outbox_entity;not null" json:"entity_id"`                    // 实体主键ID
+	Attempts    int        `gorm:"default:0" json:"attempts"`                                                   // 同步尝试次数
+	LastError   string     `gorm:"type:text" json:"last_error,omitempty"`                                       // 最近一次错误
+	NextAttempt *time.Time `gorm:"column:next_attempt_at;type:datetime" json:"next_attempt_at,omitempty"`       // 失败后的下次重试时间，按尝试次数指数退避
+	ProcessedAt *time.Time `gorm:"type:datetime;index" json:"processed_at,omitempty"`                           // 同步完成时间
+	CreatedAt   time.Time  `gorm:"index" json:"created_at"`
+}
+
+func (SearchOutbox) TableName() string {
+	return "what_search_outbox"
+}
+
+// SearchOutboxStats 发件箱统计
+type SearchOutboxStats struct {
+	Pending       int64      `json:"pending"`                  // 待同步数
+	Failed        int64      `json:"failed"`                   // 有失败记录的待同步数
+	OldestPending *time.Time `json:"oldest_pending,omitempty"` // 最早待同步时间
+	LastProcessed *time.Time `json:"last_processed,omitempty"` // 最近同步时间
+}
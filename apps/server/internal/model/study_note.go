@@ -42,10 +42,14 @@ type WrongQuestion struct {
 	LastReviewAt *time.Time          `gorm:"type:datetime" json:"last_review_at,omitempty"`         // 最近复习时间
 	ReviewCount  int                 `gorm:"default:0" json:"review_count"`                         // 复习次数
 	NextReviewAt *time.Time          `gorm:"type:datetime;index" json:"next_review_at,omitempty"`   // 下次建议复习时间
+	EaseFactor   float64             `gorm:"default:2.5" json:"ease_factor"`                        // SM-2 难度系数
+	Interval     int                 `gorm:"default:0" json:"interval"`                             // 复习间隔（天）
 	CreatedAt    time.Time           `json:"created_at"`
 	UpdatedAt    time.Time           `json:"updated_at"`
 	DeletedAt    gorm.DeletedAt      `gorm:"index" json:"-"`
 
+	ReviewSchedule `gorm:"embedded"`
+
 	// 关联
 	User     *User     `gorm:"foreignKey:UserID" json:"-"`
 	Question *Question `gorm:"foreignKey:QuestionID" json:"question,omitempty"`
@@ -70,9 +74,14 @@ type WrongQuestionResponse struct {
 	LastReviewAt *time.Time             `json:"last_review_at,omitempty"`
 	ReviewCount  int                    `json:"review_count"`
 	NextReviewAt *time.Time             `json:"next_review_at,omitempty"`
+	Interval     int                    `json:"interval"`            // 复习间隔（天）
+	SRSState     string                 `json:"srs_state,omitempty"` // 记忆调度状态
 	CreatedAt    time.Time              `json:"created_at"`
 	Question     *QuestionBriefResponse `json:"question,omitempty"`
 	CategoryName string                 `json:"category_name,omitempty"`
+
+	// 语义相似题（仅详情接口返回）
+	SimilarQuestions []*QuestionBriefResponse `json:"similar_questions,omitempty"`
 }
 
 // ToResponse 转换为响应
@@ -91,6 +100,8 @@ func (w *WrongQuestion) ToResponse() *WrongQuestionResponse {
 		LastReviewAt: w.LastReviewAt,
 		ReviewCount:  w.ReviewCount,
 		NextReviewAt: w.NextReviewAt,
+		Interval:     w.Interval,
+		SRSState:     w.SRSState,
 		CreatedAt:    w.CreatedAt,
 	}
 	if w.Question != nil {
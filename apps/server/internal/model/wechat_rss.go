@@ -1,6 +1,7 @@
 package model
 
 import (
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
@@ -11,6 +12,7 @@ type WechatRSSSourceType string
 
 const (
 	WechatRSSSourceTypeWechatAPI WechatRSSSourceType = "wechat_api" // 微信公众平台API
+	WechatRSSSourceTypeFeed      WechatRSSSourceType = "feed"       // 通用 RSS/Atom/JSON Feed 地址
 )
 
 // WechatRSSSourceStatus represents the source status constants
@@ -36,8 +38,8 @@ type WechatRSSSource struct {
 	ID             uint                  `gorm:"primaryKey" json:"id"`
 	Name           string                `gorm:"type:varchar(255);not null" json:"name"`
 	WechatID       string                `gorm:"type:varchar(100);index:idx_wechat_rss_sources_wechat_id" json:"wechat_id,omitempty"` // biz参数
-	FakeID         string                `gorm:"type:varchar(100);uniqueIndex:uk_wechat_rss_fake_id" json:"fake_id,omitempty"`        // 微信公众号fakeid
-	RSSURL         string                `gorm:"type:varchar(500)" json:"rss_url,omitempty"`                                          // 保留字段兼容性，不再使用
+	FakeID         string                `gorm:"type:varchar(100);uniqueIndex:uk_wechat_rss_fake_id" json:"fake_id,omitempty"`        // 微信公众号fakeid；feed 类型为随机占位值，避免唯一索引冲突
+	RSSURL         string                `gorm:"type:varchar(500)" json:"rss_url,omitempty"`                                          // feed 类型的订阅地址，wechat_api 类型不使用
 	SourceType     WechatRSSSourceType   `gorm:"type:varchar(20);default:'wechat_api';index:idx_wechat_rss_sources_type" json:"source_type"`
 	CrawlFrequency int                   `gorm:"default:60" json:"crawl_frequency"` // 抓取频率（分钟）
 	LastCrawlAt    *time.Time            `gorm:"type:datetime" json:"last_crawl_at,omitempty"`
@@ -48,6 +50,9 @@ type WechatRSSSource struct {
 	ArticleCount   int                   `gorm:"default:0" json:"article_count"`
 	Description    string                `gorm:"type:text" json:"description,omitempty"`
 	IconURL        string                `gorm:"type:varchar(500)" json:"icon_url,omitempty"`
+	FetchContent   bool                  `gorm:"default:false" json:"fetch_content"`          // feed 类型：条目只有摘要时抓取原文全文
+	FeedETag       string                `gorm:"column:feed_etag;type:varchar(255)" json:"-"` // feed 类型：上次响应的 ETag，用于条件请求
+	FeedModified   string                `gorm:"type:varchar(100)" json:"-"`                  // feed 类型：上次响应的 Last-Modified
 	CreatedAt      time.Time             `json:"created_at"`
 	UpdatedAt      time.Time             `json:"updated_at"`
 	DeletedAt      gorm.DeletedAt        `gorm:"index:idx_wechat_rss_sources_deleted_at" json:"-"`
@@ -90,6 +95,16 @@ type UpdateWechatRSSSourceRequest struct {
 	CrawlFrequency *int                   `json:"crawl_frequency,omitempty" validate:"omitempty,min=5,max=1440"`
 	Status         *WechatRSSSourceStatus `json:"status,omitempty"`
 	Description    *string                `json:"description,omitempty"`
+	RSSURL         *string                `json:"rss_url,omitempty" validate:"omitempty,url,max=500"` // 仅 feed 类型
+	FetchContent   *bool                  `json:"fetch_content,omitempty"`                            // 仅 feed 类型
+}
+
+// CreateFeedSourceRequest is the request for subscribing to an RSS/Atom/JSON Feed URL
+type CreateFeedSourceRequest struct {
+	RSSURL         string `json:"rss_url" validate:"required,url,max=500"`
+	Name           string `json:"name,omitempty" validate:"omitempty,max=255"` // 留空时使用订阅源标题
+	CrawlFrequency int    `json:"crawl_frequency,omitempty" validate:"omitempty,min=5,max=1440"`
+	FetchContent   bool   `json:"fetch_content"`
 }
 
 // WechatRSSSourceResponse is the response for source API
@@ -111,12 +126,20 @@ type WechatRSSSourceResponse struct {
 	UnreadCount    int                   `json:"unread_count"`
 	Description    string                `json:"description,omitempty"`
 	IconURL        string                `json:"icon_url,omitempty"`
+	RSSURL         string                `json:"rss_url,omitempty"` // feed 类型的上游订阅地址
+	FetchContent   bool                  `json:"fetch_content"`
+	FeedURL        string                `json:"feed_url"` // 公开订阅源路径，末尾可追加 /atom 或 /json
 	CreatedAt      time.Time             `json:"created_at"`
 }
 
 // ToResponse converts WechatRSSSource to WechatRSSSourceResponse
 func (s *WechatRSSSource) ToResponse() *WechatRSSSourceResponse {
 	sourceTypeText := "微信API"
+	fakeID := s.FakeID
+	if s.SourceType == WechatRSSSourceTypeFeed {
+		sourceTypeText = "RSS/Atom"
+		fakeID = ""
+	}
 
 	statusText := "未知"
 	switch s.Status {
@@ -132,7 +155,7 @@ func (s *WechatRSSSource) ToResponse() *WechatRSSSourceResponse {
 		ID:             s.ID,
 		Name:           s.Name,
 		WechatID:       s.WechatID,
-		FakeID:         s.FakeID,
+		FakeID:         fakeID,
 		SourceType:     s.SourceType,
 		SourceTypeText: sourceTypeText,
 		CrawlFrequency: s.CrawlFrequency,
@@ -145,6 +168,9 @@ func (s *WechatRSSSource) ToResponse() *WechatRSSSourceResponse {
 		ArticleCount:   s.ArticleCount,
 		Description:    s.Description,
 		IconURL:        s.IconURL,
+		RSSURL:         s.RSSURL,
+		FetchContent:   s.FetchContent,
+		FeedURL:        fmt.Sprintf("/api/v1/feeds/wechat/%d", s.ID),
 		CreatedAt:      s.CreatedAt,
 	}
 }
@@ -70,7 +70,8 @@ func (p *ExcelPositionParser) extractTextFromHTML(filePath string) (string, erro
 
 // ExcelPositionParser parses Excel files to extract position data
 type ExcelPositionParser struct {
-	Logger *zap.Logger
+	Logger  *zap.Logger
+	Profile *ColumnProfile // optional column mapping of the source the file comes from
 }
 
 // NewExcelParser creates a new Excel parser
@@ -78,9 +79,24 @@ func NewExcelParser(logger *zap.Logger) *ExcelPositionParser {
 	return &ExcelPositionParser{Logger: logger}
 }
 
+// SetProfile sets the column mapping profile applied to every sheet
+func (p *ExcelPositionParser) SetProfile(profile *ColumnProfile) {
+	p.Profile = profile
+}
+
 // Parse parses an Excel file and extracts positions
 // Supports both .xlsx and .xls formats, also handles HTML files disguised as Excel
 func (p *ExcelPositionParser) Parse(filePath string) ([]ParsedPosition, error) {
+	tables, err := p.ParseDetailed(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return FlattenTables(tables), nil
+}
+
+// ParseDetailed parses an Excel file into its position tables, one per sheet
+// (or HTML table), together with the resolved header columns
+func (p *ExcelPositionParser) ParseDetailed(filePath string) ([]ParsedTable, error) {
 	// First, check if the file is actually HTML disguised as Excel
 	if isHTMLFile(filePath) {
 		if p.Logger != nil {
@@ -90,28 +106,28 @@ func (p *ExcelPositionParser) Parse(filePath string) ([]ParsedPosition, error) {
 		}
 		return p.parseHTML(filePath)
 	}
-	
+
 	ext := strings.ToLower(filepath.Ext(filePath))
-	
+
 	// Try appropriate parser based on extension
 	if ext == ".xls" {
-		positions, err := p.parseXLS(filePath)
-		if err == nil && len(positions) > 0 {
-			return positions, nil
+		tables, err := p.parseXLS(filePath)
+		if err == nil && len(FlattenTables(tables)) > 0 {
+			return tables, nil
 		}
 		// Fallback to HTML parser
-		htmlPositions, htmlErr := p.parseHTML(filePath)
-		if htmlErr == nil && len(htmlPositions) > 0 {
-			return htmlPositions, nil
+		htmlTables, htmlErr := p.parseHTML(filePath)
+		if htmlErr == nil && len(FlattenTables(htmlTables)) > 0 {
+			return htmlTables, nil
 		}
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse xls file: %w", err)
 		}
-		return positions, nil
+		return tables, nil
 	}
-	
+
 	// For .xlsx files, try excelize first
-	positions, err := p.parseXLSX(filePath)
+	tables, err := p.parseXLSX(filePath)
 	if err != nil {
 		// If xlsx parsing fails, the file might actually be an xls file
 		// (common on Chinese government websites that save HTML as .xls/.xlsx)
@@ -121,120 +137,77 @@ func (p *ExcelPositionParser) Parse(filePath string) ([]ParsedPosition, error) {
 				zap.Error(err),
 			)
 		}
-		
+
 		// Try xls parser as fallback
-		xlsPositions, xlsErr := p.parseXLS(filePath)
-		if xlsErr == nil && len(xlsPositions) > 0 {
-			return xlsPositions, nil
+		xlsTables, xlsErr := p.parseXLS(filePath)
+		if xlsErr == nil && len(FlattenTables(xlsTables)) > 0 {
+			return xlsTables, nil
 		}
-		
+
 		// Try HTML parser as last resort
-		htmlPositions, htmlErr := p.parseHTML(filePath)
-		if htmlErr == nil && len(htmlPositions) > 0 {
+		htmlTables, htmlErr := p.parseHTML(filePath)
+		if htmlErr == nil && len(FlattenTables(htmlTables)) > 0 {
 			if p.Logger != nil {
 				p.Logger.Debug("File is actually HTML, parsed using HTML parser",
 					zap.String("file", filePath),
 				)
 			}
-			return htmlPositions, nil
+			return htmlTables, nil
 		}
-		
+
 		// All parsers failed, return original error
 		return nil, fmt.Errorf("failed to open excel file: %w", err)
 	}
-	
-	return positions, nil
+
+	return tables, nil
 }
 
 // parseHTML parses HTML files disguised as Excel and extracts positions
-func (p *ExcelPositionParser) parseHTML(filePath string) ([]ParsedPosition, error) {
+func (p *ExcelPositionParser) parseHTML(filePath string) ([]ParsedTable, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, err
 	}
-	
+
 	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
 	if err != nil {
 		return nil, err
 	}
-	
-	var allPositions []ParsedPosition
-	
+
+	var tables []ParsedTable
+
 	// Process all tables
-	doc.Find("table").Each(func(_ int, table *goquery.Selection) {
-		positions := p.parseHTMLTable(table)
-		allPositions = append(allPositions, positions...)
+	doc.Find("table").Each(func(idx int, table *goquery.Selection) {
+		if t := p.parseHTMLTable(fmt.Sprintf("Table %d", idx+1), table); t != nil {
+			tables = append(tables, *t)
+		}
 	})
-	
-	return allPositions, nil
+
+	return tables, nil
 }
 
-// parseHTMLTable parses a single HTML table
-func (p *ExcelPositionParser) parseHTMLTable(table *goquery.Selection) []ParsedPosition {
-	var rows [][]string
-	
-	// Extract all rows
-	table.Find("tr").Each(func(_ int, row *goquery.Selection) {
-		var cells []string
-		row.Find("th, td").Each(func(_ int, cell *goquery.Selection) {
-			cells = append(cells, strings.TrimSpace(cell.Text()))
-		})
-		if len(cells) > 0 {
-			rows = append(rows, cells)
-		}
-	})
-	
-	if len(rows) < 2 {
-		return nil
-	}
-	
-	// Find header row
-	headerRowIdx := -1
-	for i, row := range rows {
-		if p.isHeaderRow(row) {
-			headerRowIdx = i
-			break
-		}
-	}
-	
-	if headerRowIdx < 0 {
+// parseHTMLTable parses a single HTML table, resolving rowspan/colspan
+func (p *ExcelPositionParser) parseHTMLTable(name string, table *goquery.Selection) *ParsedTable {
+	grid := htmlTableGrid(table)
+	if len(grid) < 2 {
 		return nil
 	}
-	
-	// Map headers
-	headers := rows[headerRowIdx]
-	mappedHeaders := p.mapHeaders(headers)
-	
-	// Parse data rows
-	var positions []ParsedPosition
-	for i := headerRowIdx + 1; i < len(rows); i++ {
-		row := rows[i]
-		if len(row) == 0 {
-			continue
-		}
-		
-		position := p.parseRow(row, mappedHeaders)
-		if position != nil && position.PositionName != "" {
-			positions = append(positions, *position)
-		}
-	}
-	
-	return positions
+	return parseGrid(name, grid, p.Profile, 90, p.setPositionField)
 }
 
 // parseXLSX parses .xlsx files using excelize
-func (p *ExcelPositionParser) parseXLSX(filePath string) ([]ParsedPosition, error) {
+func (p *ExcelPositionParser) parseXLSX(filePath string) ([]ParsedTable, error) {
 	f, err := excelize.OpenFile(filePath)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
 
-	var allPositions []ParsedPosition
+	var tables []ParsedTable
 
 	// Process all sheets
 	for _, sheetName := range f.GetSheetList() {
-		positions, err := p.parseSheet(f, sheetName)
+		table, err := p.parseSheet(f, sheetName)
 		if err != nil {
 			if p.Logger != nil {
 				p.Logger.Warn("Failed to parse sheet",
@@ -244,28 +217,30 @@ func (p *ExcelPositionParser) parseXLSX(filePath string) ([]ParsedPosition, erro
 			}
 			continue
 		}
-		allPositions = append(allPositions, positions...)
+		if table != nil {
+			tables = append(tables, *table)
+		}
 	}
 
 	if p.Logger != nil {
 		p.Logger.Debug("Excel parsing completed",
 			zap.String("file", filePath),
 			zap.Int("sheets", len(f.GetSheetList())),
-			zap.Int("positions", len(allPositions)),
+			zap.Int("positions", len(FlattenTables(tables))),
 		)
 	}
 
-	return allPositions, nil
+	return tables, nil
 }
 
 // parseXLS parses .xls files using extrame/xls library
-func (p *ExcelPositionParser) parseXLS(filePath string) ([]ParsedPosition, error) {
+func (p *ExcelPositionParser) parseXLS(filePath string) ([]ParsedTable, error) {
 	xlsFile, err := xls.Open(filePath, "utf-8")
 	if err != nil {
 		return nil, err
 	}
 
-	var allPositions []ParsedPosition
+	var tables []ParsedTable
 
 	// Process all sheets
 	for sheetIdx := 0; sheetIdx < xlsFile.NumSheets(); sheetIdx++ {
@@ -274,34 +249,27 @@ func (p *ExcelPositionParser) parseXLS(filePath string) ([]ParsedPosition, error
 			continue
 		}
 
-		positions, err := p.parseXLSSheet(sheet)
-		if err != nil {
-			if p.Logger != nil {
-				p.Logger.Warn("Failed to parse XLS sheet",
-					zap.String("sheet", sheet.Name),
-					zap.Error(err),
-				)
-			}
-			continue
+		if table := p.parseXLSSheet(sheet); table != nil {
+			tables = append(tables, *table)
 		}
-		allPositions = append(allPositions, positions...)
 	}
 
 	if p.Logger != nil {
 		p.Logger.Debug("XLS parsing completed",
 			zap.String("file", filePath),
-			zap.Int("positions", len(allPositions)),
+			zap.Int("positions", len(FlattenTables(tables))),
 		)
 	}
 
-	return allPositions, nil
+	return tables, nil
 }
 
-// parseXLSSheet parses a single sheet from an XLS file
-func (p *ExcelPositionParser) parseXLSSheet(sheet *xls.WorkSheet) ([]ParsedPosition, error) {
+// parseXLSSheet parses a single sheet from an XLS file. The xls reader does not
+// expose merged ranges, merged header cells are filled in by headerColumns.
+func (p *ExcelPositionParser) parseXLSSheet(sheet *xls.WorkSheet) *ParsedTable {
 	maxRow := int(sheet.MaxRow)
 	if maxRow < 2 {
-		return nil, nil // Not enough rows
+		return nil // Not enough rows
 	}
 
 	// Convert sheet to rows format
@@ -321,42 +289,11 @@ func (p *ExcelPositionParser) parseXLSSheet(sheet *xls.WorkSheet) ([]ParsedPosit
 		rows = append(rows, cells)
 	}
 
-	// Find header row
-	headerRowIdx := -1
-	for i, row := range rows {
-		if p.isHeaderRow(row) {
-			headerRowIdx = i
-			break
-		}
-	}
-
-	if headerRowIdx < 0 {
-		return nil, nil // No valid header found
-	}
-
-	// Map headers
-	headers := rows[headerRowIdx]
-	mappedHeaders := p.mapHeaders(headers)
-
-	// Parse data rows
-	var positions []ParsedPosition
-	for i := headerRowIdx + 1; i < len(rows); i++ {
-		row := rows[i]
-		if len(row) == 0 {
-			continue
-		}
-
-		position := p.parseRow(row, mappedHeaders)
-		if position != nil && position.PositionName != "" {
-			positions = append(positions, *position)
-		}
-	}
-
-	return positions, nil
+	return parseGrid(sheet.Name, padGrid(rows), p.Profile, 90, p.setPositionField)
 }
 
-// parseSheet parses a single sheet
-func (p *ExcelPositionParser) parseSheet(f *excelize.File, sheetName string) ([]ParsedPosition, error) {
+// parseSheet parses a single sheet, copying merged cell values across their range
+func (p *ExcelPositionParser) parseSheet(f *excelize.File, sheetName string) (*ParsedTable, error) {
 	rows, err := f.GetRows(sheetName)
 	if err != nil {
 		return nil, err
@@ -365,113 +302,21 @@ func (p *ExcelPositionParser) parseSheet(f *excelize.File, sheetName string) ([]
 	if len(rows) < 2 {
 		return nil, nil // Not enough rows
 	}
+	rows = padGrid(rows)
 
-	// Find header row (first row with position-related keywords)
-	headerRowIdx := -1
-	for i, row := range rows {
-		if p.isHeaderRow(row) {
-			headerRowIdx = i
-			break
-		}
-	}
-
-	if headerRowIdx < 0 {
-		return nil, nil // No valid header found
-	}
-
-	// Map headers
-	headers := rows[headerRowIdx]
-	mappedHeaders := p.mapHeaders(headers)
-
-	// Parse data rows
-	var positions []ParsedPosition
-	for i := headerRowIdx + 1; i < len(rows); i++ {
-		row := rows[i]
-		if len(row) == 0 {
-			continue
-		}
-
-		position := p.parseRow(row, mappedHeaders)
-		if position != nil && position.PositionName != "" {
-			positions = append(positions, *position)
-		}
-	}
-
-	return positions, nil
-}
-
-// isHeaderRow checks if a row is a header row
-func (p *ExcelPositionParser) isHeaderRow(row []string) bool {
-	rowText := strings.Join(row, " ")
-
-	matchCount := 0
-	for _, keyword := range PositionTableKeywords {
-		if strings.Contains(rowText, keyword) {
-			matchCount++
-		}
-	}
-
-	return matchCount >= 3
-}
-
-// mapHeaders maps Excel headers to standard field names
-func (p *ExcelPositionParser) mapHeaders(headers []string) []string {
-	mapped := make([]string, len(headers))
-
-	for i, header := range headers {
-		header = strings.TrimSpace(header)
-		if header == "" {
-			continue
-		}
-
-		// Try exact match
-		if fieldName, ok := FieldMapping[header]; ok {
-			mapped[i] = fieldName
-			continue
-		}
-
-		// Try partial match
-		for key, fieldName := range FieldMapping {
-			if strings.Contains(header, key) || strings.Contains(key, header) {
-				mapped[i] = fieldName
-				break
+	merges, err := f.GetMergeCells(sheetName)
+	if err == nil {
+		for _, m := range merges {
+			left, top, err1 := excelize.CellNameToCoordinates(m.GetStartAxis())
+			right, bottom, err2 := excelize.CellNameToCoordinates(m.GetEndAxis())
+			if err1 != nil || err2 != nil {
+				continue
 			}
+			fillMergedRange(rows, top-1, left-1, bottom-1, right-1)
 		}
-
-		if mapped[i] == "" {
-			mapped[i] = normalizeFieldName(header)
-		}
-	}
-
-	return mapped
-}
-
-// parseRow parses a single data row
-func (p *ExcelPositionParser) parseRow(row []string, mappedHeaders []string) *ParsedPosition {
-	position := &ParsedPosition{
-		ParseConfidence: 90,
-	}
-	hasData := false
-
-	for i, value := range row {
-		if i >= len(mappedHeaders) || mappedHeaders[i] == "" {
-			continue
-		}
-
-		value = strings.TrimSpace(value)
-		if value == "" {
-			continue
-		}
-
-		hasData = true
-		p.setPositionField(position, mappedHeaders[i], value)
-	}
-
-	if !hasData {
-		return nil
 	}
 
-	return position
+	return parseGrid(sheetName, rows, p.Profile, 90, p.setPositionField), nil
 }
 
 // setPositionField sets a field on the position based on field name
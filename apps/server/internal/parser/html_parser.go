@@ -2,6 +2,7 @@
 package parser
 
 import (
+	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
@@ -46,7 +47,8 @@ var PositionTableKeywords = []string{"职位", "岗位", "部门", "学历", "
 
 // HTMLTableParser parses HTML tables to extract position data
 type HTMLTableParser struct {
-	Logger *zap.Logger
+	Logger  *zap.Logger
+	Profile *ColumnProfile // optional column mapping of the source the page comes from
 }
 
 // NewHTMLTableParser creates a new HTML table parser
@@ -54,6 +56,11 @@ func NewHTMLTableParser(logger *zap.Logger) *HTMLTableParser {
 	return &HTMLTableParser{Logger: logger}
 }
 
+// SetProfile sets the column mapping profile applied to every table
+func (p *HTMLTableParser) SetProfile(profile *ColumnProfile) {
+	p.Profile = profile
+}
+
 // ParsedPosition represents a parsed position from HTML table
 type ParsedPosition struct {
 	PositionName      string   `json:"position_name"`
@@ -77,124 +84,42 @@ type ParsedPosition struct {
 
 // ParseTables parses HTML content and extracts positions from tables
 func (p *HTMLTableParser) ParseTables(html string) ([]ParsedPosition, error) {
+	tables, err := p.ParseTablesDetailed(html)
+	if err != nil {
+		return nil, err
+	}
+	return FlattenTables(tables), nil
+}
+
+// ParseTablesDetailed parses every position table of the HTML content together
+// with its resolved header columns; rowspan/colspan cells and headers spanning
+// several rows are resolved, title rows above the header are skipped
+func (p *HTMLTableParser) ParseTablesDetailed(html string) ([]ParsedTable, error) {
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
 	if err != nil {
 		return nil, err
 	}
 
-	var allPositions []ParsedPosition
+	var tables []ParsedTable
 
-	doc.Find("table").Each(func(_ int, table *goquery.Selection) {
-		if p.isPositionTable(table) {
-			positions := p.parseTable(table)
-			allPositions = append(allPositions, positions...)
+	doc.Find("table").Each(func(idx int, table *goquery.Selection) {
+		grid := htmlTableGrid(table)
+		if len(grid) < 2 {
+			return
+		}
+		if t := parseGrid(fmt.Sprintf("Table %d", idx+1), grid, p.Profile, 85, p.setPositionField); t != nil {
+			tables = append(tables, *t)
 		}
 	})
 
 	if p.Logger != nil {
 		p.Logger.Debug("HTML table parsing completed",
 			zap.Int("tables_found", doc.Find("table").Length()),
-			zap.Int("positions_extracted", len(allPositions)),
+			zap.Int("positions_extracted", len(FlattenTables(tables))),
 		)
 	}
 
-	return allPositions, nil
-}
-
-// isPositionTable checks if a table contains position data
-func (p *HTMLTableParser) isPositionTable(table *goquery.Selection) bool {
-	headerRow := table.Find("tr").First()
-	headerText := headerRow.Text()
-
-	matchCount := 0
-	for _, keyword := range PositionTableKeywords {
-		if strings.Contains(headerText, keyword) {
-			matchCount++
-		}
-	}
-
-	return matchCount >= 3
-}
-
-// parseTable parses a single table and extracts positions
-func (p *HTMLTableParser) parseTable(table *goquery.Selection) []ParsedPosition {
-	var positions []ParsedPosition
-
-	// Get headers from first row
-	var headers []string
-	table.Find("tr").First().Find("th, td").Each(func(_ int, cell *goquery.Selection) {
-		headers = append(headers, strings.TrimSpace(cell.Text()))
-	})
-
-	// Map headers to field names
-	mappedHeaders := p.mapHeaders(headers)
-
-	// Parse data rows
-	table.Find("tr").Each(func(rowIdx int, row *goquery.Selection) {
-		if rowIdx == 0 {
-			return // Skip header row
-		}
-
-		position := ParsedPosition{
-			ParseConfidence: 85,
-		}
-		hasData := false
-
-		row.Find("td").Each(func(colIdx int, cell *goquery.Selection) {
-			if colIdx >= len(mappedHeaders) || mappedHeaders[colIdx] == "" {
-				return
-			}
-
-			fieldName := mappedHeaders[colIdx]
-			value := strings.TrimSpace(cell.Text())
-
-			if value == "" {
-				return
-			}
-
-			hasData = true
-			p.setPositionField(&position, fieldName, value)
-		})
-
-		if hasData && position.PositionName != "" {
-			positions = append(positions, position)
-		}
-	})
-
-	return positions
-}
-
-// mapHeaders maps table headers to standard field names
-func (p *HTMLTableParser) mapHeaders(headers []string) []string {
-	mapped := make([]string, len(headers))
-
-	for i, header := range headers {
-		header = strings.TrimSpace(header)
-		if header == "" {
-			continue
-		}
-
-		// Try exact match
-		if fieldName, ok := FieldMapping[header]; ok {
-			mapped[i] = fieldName
-			continue
-		}
-
-		// Try partial match
-		for key, fieldName := range FieldMapping {
-			if strings.Contains(header, key) || strings.Contains(key, header) {
-				mapped[i] = fieldName
-				break
-			}
-		}
-
-		// If no match, use normalized header as field name
-		if mapped[i] == "" {
-			mapped[i] = normalizeFieldName(header)
-		}
-	}
-
-	return mapped
+	return tables, nil
 }
 
 // setPositionField sets a field on the position based on field name
@@ -239,14 +164,6 @@ func (p *HTMLTableParser) setPositionField(pos *ParsedPosition, fieldName, value
 	}
 }
 
-// normalizeFieldName converts a Chinese field name to snake_case
-func normalizeFieldName(name string) string {
-	// Simple conversion - replace spaces with underscores and lowercase
-	name = strings.ToLower(name)
-	name = strings.ReplaceAll(name, " ", "_")
-	return name
-}
-
 // extractNumber extracts a number from a string
 func extractNumber(s string) string {
 	re := regexp.MustCompile(`\d+`)
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"time"
 
@@ -22,22 +23,30 @@ type OCRConfig struct {
 	APIURL       string `mapstructure:"api_url"`       // For cloud OCR API
 	APIKey       string `mapstructure:"api_key"`
 	Timeout      time.Duration `mapstructure:"timeout"`
+	DPI           int     `mapstructure:"dpi"`            // Rendering resolution of PDF pages
+	PdftoppmCmd   string  `mapstructure:"pdftoppm_cmd"`   // Path to poppler pdftoppm binary
+	LowConfidence float64 `mapstructure:"low_confidence"` // Cells below this confidence (0-100) go to LLM cleanup
 }
 
 // DefaultOCRConfig returns default OCR configuration
 func DefaultOCRConfig() *OCRConfig {
 	return &OCRConfig{
-		Engine:       "tesseract",
-		TesseractCmd: "tesseract",
-		Language:     "chi_sim+eng",
-		Timeout:      60 * time.Second,
+		Engine:        "tesseract",
+		TesseractCmd:  "tesseract",
+		Language:      "chi_sim+eng",
+		Timeout:       60 * time.Second,
+		DPI:           300,
+		PdftoppmCmd:   "pdftoppm",
+		LowConfidence: 60,
 	}
 }
 
 // OCRPositionParser handles OCR-based text extraction
 type OCRPositionParser struct {
-	Config *OCRConfig
-	Logger *zap.Logger
+	Config  *OCRConfig
+	Logger  *zap.Logger
+	Profile *ColumnProfile // optional column mapping applied to reconstructed tables
+	Cleaner OCRCellCleaner // optional LLM cleanup of low-confidence cells
 }
 
 // NewOCRParser creates a new OCR parser
@@ -109,16 +118,33 @@ func (p *OCRPositionParser) parseWithTesseract(imagePath string) (string, error)
 	return text, nil
 }
 
+// apiOCRResponse is the response of the cloud OCR API; boxes are optional
+type apiOCRResponse struct {
+	Text       string           `json:"text"`
+	Confidence float64          `json:"confidence"`
+	Boxes      []OCRBoundingBox `json:"boxes"`
+	Error      string           `json:"error"`
+}
+
 // parseWithAPI uses a cloud OCR API
 func (p *OCRPositionParser) parseWithAPI(imagePath string) (string, error) {
+	result, err := p.callOCRAPI(imagePath)
+	if err != nil {
+		return "", err
+	}
+	return result.Text, nil
+}
+
+// callOCRAPI posts the image to the cloud OCR API
+func (p *OCRPositionParser) callOCRAPI(imagePath string) (*apiOCRResponse, error) {
 	if p.Config.APIURL == "" {
-		return "", fmt.Errorf("OCR API URL not configured")
+		return nil, fmt.Errorf("OCR API URL not configured")
 	}
 
 	// Read image file
 	imageData, err := os.ReadFile(imagePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read image: %w", err)
+		return nil, fmt.Errorf("failed to read image: %w", err)
 	}
 
 	// Create request
@@ -126,7 +152,7 @@ func (p *OCRPositionParser) parseWithAPI(imagePath string) (string, error) {
 
 	req, err := http.NewRequest("POST", p.Config.APIURL, bytes.NewReader(imageData))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/octet-stream")
@@ -136,37 +162,104 @@ func (p *OCRPositionParser) parseWithAPI(imagePath string) (string, error) {
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("OCR API request failed: %w", err)
+		return nil, fmt.Errorf("OCR API request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("OCR API error: %d - %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("OCR API error: %d - %s", resp.StatusCode, string(body))
 	}
 
 	// Parse response
-	var result struct {
-		Text  string `json:"text"`
-		Error string `json:"error"`
-	}
-
+	var result apiOCRResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", fmt.Errorf("failed to parse OCR response: %w", err)
+		return nil, fmt.Errorf("failed to parse OCR response: %w", err)
 	}
 
 	if result.Error != "" {
-		return "", fmt.Errorf("OCR API error: %s", result.Error)
+		return nil, fmt.Errorf("OCR API error: %s", result.Error)
 	}
 
-	return result.Text, nil
+	return &result, nil
 }
 
-// ParsePDFPages extracts text from PDF pages using OCR
+// ParsePDFPages extracts text from PDF pages using OCR, one entry per rendered page.
+// Page images are written to outputDir; a temporary directory is used when it is empty
 func (p *OCRPositionParser) ParsePDFPages(pdfPath string, outputDir string) ([]string, error) {
-	// This would convert PDF pages to images first, then OCR each
-	// For now, return an error indicating this needs image conversion
-	return nil, fmt.Errorf("PDF to image conversion not implemented - use external tool like poppler/pdftoppm")
+	images, cleanup, err := p.rasterize(pdfPath, outputDir)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	var pages []string
+	for _, img := range images {
+		text, err := p.ParseImage(img.Path)
+		if err != nil {
+			return nil, fmt.Errorf("OCR of page %d failed: %w", img.Page, err)
+		}
+		pages = append(pages, text)
+	}
+	return pages, nil
+}
+
+// ExtractPDFText OCRs a scanned PDF into text in the layout of ExtractText:
+// pages are marked "=== 第 N 页 ===" and the lines rebuilt from word boxes
+// are written with their cells tab separated so downstream extraction sees
+// the table columns
+func (p *OCRPositionParser) ExtractPDFText(pdfPath string) (string, error) {
+	images, cleanup, err := p.rasterize(pdfPath, "")
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	var builder strings.Builder
+	for _, img := range images {
+		result, err := p.ParseImageWithDetails(img.Path)
+		if err != nil {
+			return "", fmt.Errorf("OCR of page %d failed: %w", img.Page, err)
+		}
+		writeOCRPage(&builder, img.Page, result.Text, ReconstructTable(result.Boxes))
+	}
+	return builder.String(), nil
+}
+
+// writeOCRPage writes one page of ExtractPDFText output
+func writeOCRPage(builder *strings.Builder, page int, text string, table *OCRTable) {
+	builder.WriteString(fmt.Sprintf("=== 第 %d 页 ===\n", page))
+	// the API engine may return text without boxes
+	if table == nil {
+		builder.WriteString(text)
+		builder.WriteString("\n\n")
+		return
+	}
+	for _, row := range table.Grid() {
+		builder.WriteString(strings.Join(row, "\t"))
+		builder.WriteString("\n")
+	}
+	builder.WriteString("\n")
+}
+
+// rasterize renders the PDF pages, returning a cleanup for temporary output
+func (p *OCRPositionParser) rasterize(pdfPath, outputDir string) ([]PageImage, func(), error) {
+	cleanup := func() {}
+	if outputDir == "" {
+		dir, err := os.MkdirTemp("", "ocr-pages-*")
+		if err != nil {
+			return nil, nil, err
+		}
+		outputDir = dir
+		cleanup = func() { os.RemoveAll(dir) }
+	}
+
+	images, err := NewPDFRasterizer(p.Config, p.Logger).Rasterize(pdfPath, outputDir)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return images, cleanup, nil
 }
 
 // IsAvailable checks if OCR is available
@@ -226,33 +319,48 @@ type OCRBoundingBox struct {
 	Confidence float64 `json:"confidence"`
 }
 
-// ParseImageWithDetails extracts text with bounding box information
+// ParseImageWithDetails extracts text with word bounding boxes; when the boxes
+// form a table the reconstructed cells are returned in Tables
 func (p *OCRPositionParser) ParseImageWithDetails(imagePath string) (*OCRResult, error) {
 	startTime := time.Now()
-	
+
 	result := &OCRResult{}
 
-	// For tesseract, we can get detailed output with TSV format
-	if p.Config.Engine == "tesseract" {
-		text, err := p.parseWithTesseractTSV(imagePath)
+	// The API may return word boxes; tesseract reports them in TSV format
+	if p.Config.Engine == "api" {
+		resp, err := p.callOCRAPI(imagePath)
 		if err != nil {
 			return nil, err
 		}
-		result.Text = text
+		result.Text = resp.Text
+		result.Confidence = resp.Confidence
+		result.Boxes = resp.Boxes
 	} else {
-		text, err := p.ParseImage(imagePath)
+		text, boxes, err := p.parseWithTesseractTSV(imagePath)
 		if err != nil {
 			return nil, err
 		}
 		result.Text = text
+		result.Boxes = boxes
+	}
+
+	if result.Confidence == 0 && len(result.Boxes) > 0 {
+		var sum float64
+		for _, box := range result.Boxes {
+			sum += box.Confidence
+		}
+		result.Confidence = sum / float64(len(result.Boxes))
+	}
+	if table := ReconstructTable(result.Boxes); table != nil && table.Columns > 1 {
+		result.Tables = [][][]string{table.Grid()}
 	}
 
 	result.Duration = time.Since(startTime)
 	return result, nil
 }
 
-// parseWithTesseractTSV extracts text using Tesseract TSV output
-func (p *OCRPositionParser) parseWithTesseractTSV(imagePath string) (string, error) {
+// parseWithTesseractTSV extracts text and word boxes using Tesseract TSV output
+func (p *OCRPositionParser) parseWithTesseractTSV(imagePath string) (string, []OCRBoundingBox, error) {
 	args := []string{
 		imagePath,
 		"stdout",
@@ -262,32 +370,49 @@ func (p *OCRPositionParser) parseWithTesseractTSV(imagePath string) (string, err
 	}
 
 	cmd := exec.Command(p.Config.TesseractCmd, args...)
-	
+
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
 	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("tesseract failed: %w", err)
+		return "", nil, fmt.Errorf("tesseract failed: %w - %s", err, stderr.String())
 	}
 
-	// Parse TSV output
-	lines := strings.Split(stdout.String(), "\n")
+	text, boxes := parseTesseractTSV(stdout.String())
+	return text, boxes, nil
+}
+
+// parseTesseractTSV reads the word level rows of Tesseract TSV output:
+// level page_num block_num par_num line_num word_num left top width height conf text
+func parseTesseractTSV(tsv string) (string, []OCRBoundingBox) {
+	lines := strings.Split(tsv, "\n")
 	var textParts []string
+	var boxes []OCRBoundingBox
 
 	for i, line := range lines {
 		if i == 0 {
 			continue // Skip header
 		}
 
-		fields := strings.Split(line, "\t")
-		if len(fields) >= 12 {
-			text := fields[11]
-			if strings.TrimSpace(text) != "" {
-				textParts = append(textParts, text)
-			}
+		fields := strings.Split(strings.TrimRight(line, "\r"), "\t")
+		if len(fields) < 12 || fields[0] != "5" {
+			continue
+		}
+		text := strings.TrimSpace(fields[11])
+		if text == "" {
+			continue
 		}
+		textParts = append(textParts, text)
+
+		box := OCRBoundingBox{Text: text}
+		box.X, _ = strconv.Atoi(fields[6])
+		box.Y, _ = strconv.Atoi(fields[7])
+		box.Width, _ = strconv.Atoi(fields[8])
+		box.Height, _ = strconv.Atoi(fields[9])
+		box.Confidence, _ = strconv.ParseFloat(fields[10], 64)
+		boxes = append(boxes, box)
 	}
 
-	return strings.Join(textParts, " "), nil
+	return strings.Join(textParts, " "), boxes
 }
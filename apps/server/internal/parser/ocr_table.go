@@ -0,0 +1,563 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+// Table reconstruction from OCR word boxes.
+//
+// Words are grouped into text lines by their vertical centers. The columns
+// are the x ranges covered by text on most lines, separated by gaps almost no
+// line writes into. Every word goes to the column containing its center, and
+// lines filling only a few columns (text wrapped inside tall cells) are
+// merged into the nearest full row.
+
+// ocrCleanupBatch is the number of low-confidence cells sent per cleanup call
+const ocrCleanupBatch = 50
+
+// ocrMaxConfidence caps the parse confidence of positions read by OCR
+const ocrMaxConfidence = 80
+
+// OCRCell is a table cell reconstructed from OCR words
+type OCRCell struct {
+	Text       string  `json:"text"`
+	Confidence float64 `json:"confidence"` // lowest word confidence, 0-100
+}
+
+// OCRTable is a table reconstructed from the word boxes of one page
+type OCRTable struct {
+	Columns int
+	Rows    [][]OCRCell
+	bands   []ocrSpan
+}
+
+// Grid returns the cell texts row by row
+func (t *OCRTable) Grid() [][]string {
+	grid := make([][]string, len(t.Rows))
+	for i, row := range t.Rows {
+		grid[i] = make([]string, len(row))
+		for j, cell := range row {
+			grid[i][j] = cell.Text
+		}
+	}
+	return grid
+}
+
+// LowConfidenceCell is an OCR cell sent for cleanup together with its context
+type LowConfidenceCell struct {
+	ID     int    `json:"id"`
+	Column string `json:"column"` // header of the cell's column
+	Text   string `json:"text"`
+	Row    string `json:"row"` // whole row for context
+}
+
+// OCRCellCleaner corrects the text of low-confidence OCR cells, typically with
+// an LLM; cells it cannot improve may be left out of the result
+type OCRCellCleaner interface {
+	CleanCells(ctx context.Context, cells []LowConfidenceCell) (map[int]string, error)
+}
+
+// SetProfile sets the column mapping profile applied to reconstructed tables
+func (p *OCRPositionParser) SetProfile(profile *ColumnProfile) {
+	p.Profile = profile
+}
+
+// SetCellCleaner sets the cleanup of low-confidence cells
+func (p *OCRPositionParser) SetCellCleaner(cleaner OCRCellCleaner) {
+	p.Cleaner = cleaner
+}
+
+// ParsePDFTables OCRs a scanned PDF page by page and parses the position
+// tables rebuilt from the word boxes. Pages continuing a table without
+// repeating its header reuse the columns and header of the previous page.
+func (p *OCRPositionParser) ParsePDFTables(pdfPath string, outputDir string) ([]ParsedTable, error) {
+	return p.parsePDFTables(pdfPath, outputDir, nil)
+}
+
+// ExtractPDFTextAndTables OCRs a scanned PDF once and returns both the text of
+// ExtractPDFText and the position tables of ParsePDFTables
+func (p *OCRPositionParser) ExtractPDFTextAndTables(pdfPath string) (string, []ParsedTable, error) {
+	var text strings.Builder
+	tables, err := p.parsePDFTables(pdfPath, "", &text)
+	if err != nil {
+		return "", nil, err
+	}
+	return text.String(), tables, nil
+}
+
+// parsePDFTables parses the tables of every page, also writing the page text
+// to text when it is not nil
+func (p *OCRPositionParser) parsePDFTables(pdfPath string, outputDir string, text *strings.Builder) ([]ParsedTable, error) {
+	images, cleanup, err := p.rasterize(pdfPath, outputDir)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	fields := &HTMLTableParser{}
+	var tables []ParsedTable
+	var prev *OCRTable
+	var prevHeader [][]OCRCell
+
+	for _, img := range images {
+		result, err := p.ParseImageWithDetails(img.Path)
+		if err != nil {
+			return nil, fmt.Errorf("OCR of page %d failed: %w", img.Page, err)
+		}
+		table := ReconstructTable(result.Boxes)
+		if text != nil {
+			writeOCRPage(text, img.Page, result.Text, table)
+		}
+		if table == nil {
+			continue
+		}
+
+		start, depth := locateHeader(table.Grid(), p.Profile)
+		if prev != nil && (start < 0 || headerHits(table.Grid()[start:start+depth]) < 3) {
+			table = reconstructTable(result.Boxes, prev.bands)
+			table.Rows = append(append([][]OCRCell{}, prevHeader...), table.Rows...)
+			start, depth = 0, len(prevHeader)
+		}
+		if start < 0 {
+			continue
+		}
+
+		p.cleanCells(table, start, depth)
+
+		profile := &ColumnProfile{HeaderRow: start + 1, HeaderRows: depth}
+		if p.Profile != nil {
+			profile.Columns = p.Profile.Columns
+		}
+		parsed := parseGrid(fmt.Sprintf("第 %d 页", img.Page), table.Grid(), profile, table.confidence(start+depth), fields.setPositionField)
+		if parsed == nil {
+			continue
+		}
+		prev, prevHeader = table, table.Rows[start:start+depth]
+		tables = append(tables, *parsed)
+	}
+
+	if p.Logger != nil {
+		p.Logger.Info("OCR table parsing completed",
+			zap.String("file", pdfPath),
+			zap.Int("pages", len(images)),
+			zap.Int("tables", len(tables)),
+			zap.Int("positions", len(FlattenTables(tables))),
+		)
+	}
+
+	return tables, nil
+}
+
+// confidence is the mean cell confidence of the data rows, capped for OCR
+func (t *OCRTable) confidence(firstDataRow int) int {
+	var sum float64
+	var n int
+	for _, row := range t.Rows[min(firstDataRow, len(t.Rows)):] {
+		for _, cell := range row {
+			if cell.Text != "" {
+				sum += cell.Confidence
+				n++
+			}
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return min(ocrMaxConfidence, int(sum/float64(n)))
+}
+
+// cleanCells sends the cells below the confidence threshold to the cleaner;
+// the OCR text is kept when cleanup fails
+func (p *OCRPositionParser) cleanCells(table *OCRTable, start, depth int) {
+	if p.Cleaner == nil || p.Config.LowConfidence <= 0 {
+		return
+	}
+
+	columns := make([]string, table.Columns)
+	for c := range columns {
+		var parts []string
+		for _, row := range table.Rows[start : start+depth] {
+			if text := row[c].Text; text != "" && (len(parts) == 0 || parts[len(parts)-1] != text) {
+				parts = append(parts, text)
+			}
+		}
+		columns[c] = strings.Join(parts, "/")
+	}
+
+	type cellRef struct{ row, col int }
+	var cells []LowConfidenceCell
+	var refs []cellRef
+	for r := start; r < len(table.Rows); r++ {
+		row := table.Rows[r]
+		var rowText []string
+		for _, cell := range row {
+			if cell.Text != "" {
+				rowText = append(rowText, cell.Text)
+			}
+		}
+		for c, cell := range row {
+			if cell.Text == "" || cell.Confidence >= p.Config.LowConfidence {
+				continue
+			}
+			cells = append(cells, LowConfidenceCell{
+				ID:     len(cells),
+				Column: columns[c],
+				Text:   cell.Text,
+				Row:    strings.Join(rowText, " | "),
+			})
+			refs = append(refs, cellRef{r, c})
+		}
+	}
+
+	for i := 0; i < len(cells); i += ocrCleanupBatch {
+		batch := cells[i:min(i+ocrCleanupBatch, len(cells))]
+		fixes, err := p.Cleaner.CleanCells(context.Background(), batch)
+		if err != nil {
+			if p.Logger != nil {
+				p.Logger.Warn("OCR cell cleanup failed, keeping recognized text",
+					zap.Int("cells", len(cells)-i),
+					zap.Error(err),
+				)
+			}
+			return
+		}
+		for id, text := range fixes {
+			if id < 0 || id >= len(refs) || strings.TrimSpace(text) == "" {
+				continue
+			}
+			cell := &table.Rows[refs[id].row][refs[id].col]
+			cell.Text = strings.TrimSpace(text)
+			cell.Confidence = p.Config.LowConfidence
+		}
+	}
+}
+
+// ocrSpan is a horizontal pixel range
+type ocrSpan struct {
+	x0, x1 int
+}
+
+// ocrLine is a line of words sharing a vertical center
+type ocrLine struct {
+	y0, y1 int
+	center float64
+	words  []OCRBoundingBox
+	cells  []OCRCell
+	filled int
+}
+
+// ReconstructTable rebuilds table rows and cells from OCR word boxes
+func ReconstructTable(boxes []OCRBoundingBox) *OCRTable {
+	return reconstructTable(boxes, nil)
+}
+
+// reconstructTable rebuilds the table, deriving the columns from the words
+// unless bands of a previous page are given
+func reconstructTable(boxes []OCRBoundingBox, bands []ocrSpan) *OCRTable {
+	var words []OCRBoundingBox
+	var heights []int
+	for _, box := range boxes {
+		box.Text = strings.TrimSpace(box.Text)
+		if box.Text == "" || box.Width <= 0 || box.Height <= 0 {
+			continue
+		}
+		words = append(words, box)
+		heights = append(heights, box.Height)
+	}
+	if len(words) == 0 {
+		return nil
+	}
+	sort.Ints(heights)
+	lineHeight := heights[len(heights)/2]
+
+	lines := groupOCRLines(words, lineHeight)
+	if bands == nil {
+		bands = columnBands(lines)
+	}
+
+	for _, line := range lines {
+		line.cells = make([]OCRCell, len(bands))
+		ends := make([]int, len(bands))
+		for _, seg := range lineSegments(line) {
+			// a run inside one column goes there whole, a run crossing
+			// columns (title, merged header) is split word by word
+			hit := overlappingBands(bands, seg.ocrSpan)
+			for _, w := range seg.words {
+				c := nearestBand(bands, w.X+w.Width/2)
+				if len(hit) == 1 {
+					c = hit[0]
+				}
+				cell := &line.cells[c]
+				if cell.Text == "" || w.Confidence < cell.Confidence {
+					cell.Confidence = w.Confidence
+				}
+				// words recognized apart but printed together (digits of a code) join without space
+				if cell.Text != "" && w.X-ends[c] < lineHeight/4 {
+					cell.Text += w.Text
+				} else {
+					cell.Text = joinOCRText(cell.Text, w.Text)
+				}
+				ends[c] = w.X + w.Width
+			}
+		}
+		for _, cell := range line.cells {
+			if cell.Text != "" {
+				line.filled++
+			}
+		}
+	}
+
+	return &OCRTable{
+		Columns: len(bands),
+		Rows:    mergeWrappedLines(lines, bands, lineHeight),
+		bands:   bands,
+	}
+}
+
+// groupOCRLines groups words whose vertical centers lie within half a line
+// height, each line ordered left to right
+func groupOCRLines(words []OCRBoundingBox, lineHeight int) []*ocrLine {
+	sort.Slice(words, func(i, j int) bool {
+		return words[i].Y*2+words[i].Height < words[j].Y*2+words[j].Height
+	})
+
+	var lines []*ocrLine
+	var cur *ocrLine
+	for _, w := range words {
+		center := float64(w.Y) + float64(w.Height)/2
+		if cur == nil || center-cur.center > float64(lineHeight)/2 {
+			cur = &ocrLine{y0: w.Y, y1: w.Y + w.Height}
+			lines = append(lines, cur)
+		}
+		cur.words = append(cur.words, w)
+		cur.center += (center - cur.center) / float64(len(cur.words))
+		cur.y0 = min(cur.y0, w.Y)
+		cur.y1 = max(cur.y1, w.Y+w.Height)
+	}
+
+	for _, line := range lines {
+		sort.Slice(line.words, func(i, j int) bool { return line.words[i].X < line.words[j].X })
+	}
+	return lines
+}
+
+// columnBands finds the x ranges covered by text separated by gaps that at
+// most one line in twenty writes into. Lines of a single text run (titles,
+// wrapped cell text) and runs spanning most of the page are ignored.
+func columnBands(lines []*ocrLine) []ocrSpan {
+	left, right := -1, 0
+	for _, line := range lines {
+		for _, w := range line.words {
+			if left < 0 || w.X < left {
+				left = w.X
+			}
+			right = max(right, w.X+w.Width)
+		}
+	}
+	width := right - left
+	if width <= 0 {
+		return []ocrSpan{{left, right}}
+	}
+
+	coverage := make([]int, width+1)
+	counted := 0
+	for _, line := range lines {
+		segs := lineSegments(line)
+		if len(segs) < 2 {
+			continue
+		}
+		counted++
+		covered := make([]bool, width+1)
+		for _, seg := range segs {
+			if seg.x1-seg.x0 > width*2/5 {
+				continue
+			}
+			for x := seg.x0; x <= seg.x1; x++ {
+				covered[x-left] = true
+			}
+		}
+		for x, ok := range covered {
+			if ok {
+				coverage[x]++
+			}
+		}
+	}
+
+	var bands []ocrSpan
+	inBand := false
+	for x, n := range coverage {
+		open := n > max(1, counted/20) || (n > 0 && counted < 3)
+		switch {
+		case open && !inBand:
+			bands = append(bands, ocrSpan{x0: left + x})
+			inBand = true
+		case !open && inBand:
+			bands[len(bands)-1].x1 = left + x - 1
+			inBand = false
+		}
+	}
+	if inBand {
+		bands[len(bands)-1].x1 = right
+	}
+
+	// columns only the header writes into, such as an empty remarks column
+	for _, line := range lines {
+		segs := lineSegments(line)
+		if len(segs) < 2 {
+			continue
+		}
+		for _, seg := range segs {
+			if len(overlappingBands(bands, seg.ocrSpan)) == 0 {
+				bands = append(bands, seg.ocrSpan)
+				sort.Slice(bands, func(i, j int) bool { return bands[i].x0 < bands[j].x0 })
+			}
+		}
+	}
+
+	if len(bands) == 0 {
+		bands = []ocrSpan{{left, right}}
+	}
+	return bands
+}
+
+// overlappingBands returns the indexes of the bands a span overlaps
+func overlappingBands(bands []ocrSpan, span ocrSpan) []int {
+	var out []int
+	for i, b := range bands {
+		if span.x0 <= b.x1 && span.x1 >= b.x0 {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// ocrSegment is a run of neighbouring words on a line
+type ocrSegment struct {
+	ocrSpan
+	words []OCRBoundingBox
+}
+
+// lineSegments joins neighbouring words of a line into text runs; the gap
+// allowed inside a run is about the width of one character
+func lineSegments(line *ocrLine) []ocrSegment {
+	var segs []ocrSegment
+	for _, w := range line.words {
+		gap := max(w.Height, 1)
+		if n := len(segs); n > 0 && w.X-segs[n-1].x1 <= gap {
+			segs[n-1].x1 = max(segs[n-1].x1, w.X+w.Width)
+			segs[n-1].words = append(segs[n-1].words, w)
+			continue
+		}
+		segs = append(segs, ocrSegment{ocrSpan{w.X, w.X + w.Width}, []OCRBoundingBox{w}})
+	}
+	return segs
+}
+
+// nearestBand returns the band containing x, or the closest one
+func nearestBand(bands []ocrSpan, x int) int {
+	best, bestDist := 0, -1
+	for i, b := range bands {
+		dist := 0
+		if x < b.x0 {
+			dist = b.x0 - x
+		} else if x > b.x1 {
+			dist = x - b.x1
+		}
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+	return best
+}
+
+// mergeWrappedLines merges lines filling at most a third of the columns into
+// the closest full data line within a line height, keeping header lines,
+// distant lines and text running across columns (titles, section labels) as
+// rows of their own
+func mergeWrappedLines(lines []*ocrLine, bands []ocrSpan, lineHeight int) [][]OCRCell {
+	columns := len(bands)
+	sparse := func(l *ocrLine) bool { return columns > 1 && l.filled*3 <= columns }
+	header := func(l *ocrLine) bool {
+		row := make([]string, len(l.cells))
+		for i, c := range l.cells {
+			row[i] = c.Text
+		}
+		return headerHits([][]string{row}) >= 2
+	}
+
+	target := make([]int, len(lines))
+	for i, line := range lines {
+		target[i] = i
+		if !sparse(line) || spansBands(line, bands) {
+			continue
+		}
+		bestGap := lineHeight + 1
+		for j, other := range lines {
+			if sparse(other) || header(other) {
+				continue
+			}
+			gap := max(other.y0-line.y1, line.y0-other.y1, 0)
+			if gap < bestGap {
+				target[i], bestGap = j, gap
+			}
+		}
+	}
+
+	// cell text of merged lines is joined top to bottom
+	var rows [][]OCRCell
+	index := make(map[int]int)
+	for i := range lines {
+		if target[i] != i {
+			continue
+		}
+		index[i] = len(rows)
+		rows = append(rows, make([]OCRCell, columns))
+	}
+	for i, line := range lines {
+		row := rows[index[target[i]]]
+		for c, cell := range line.cells {
+			if cell.Text == "" {
+				continue
+			}
+			if row[c].Text == "" || cell.Confidence < row[c].Confidence {
+				row[c].Confidence = cell.Confidence
+			}
+			row[c].Text = joinOCRText(row[c].Text, cell.Text)
+		}
+	}
+	return rows
+}
+
+// spansBands reports whether a run of text of the line crosses a column gap
+func spansBands(line *ocrLine, bands []ocrSpan) bool {
+	for _, seg := range lineSegments(line) {
+		if len(overlappingBands(bands, seg.ocrSpan)) > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// joinOCRText joins recognized fragments, separating latin words and numbers
+// by a space and CJK text by nothing
+func joinOCRText(a, b string) string {
+	if a == "" {
+		return b
+	}
+	last, _ := utf8.DecodeLastRuneInString(a)
+	first, _ := utf8.DecodeRuneInString(b)
+	if last < utf8.RuneSelf && first < utf8.RuneSelf && isASCIIWord(last) && isASCIIWord(first) {
+		return a + " " + b
+	}
+	return a + b
+}
+
+func isASCIIWord(r rune) bool {
+	return r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
@@ -0,0 +1,478 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"go.uber.org/zap"
+)
+
+// ParsedPaper is an exam paper segmented into sections, shared materials and questions
+type ParsedPaper struct {
+	Title    string               `json:"title"`
+	Sections []ParsedPaperSection `json:"sections"`
+	Warnings []string             `json:"warnings,omitempty"`
+}
+
+// ParsedPaperSection is one part of a paper such as 常识判断 or 资料分析
+type ParsedPaperSection struct {
+	Name      string                `json:"name"`
+	Materials []ParsedPaperMaterial `json:"materials,omitempty"`
+	Questions []ParsedPaperQuestion `json:"questions"`
+}
+
+// ParsedPaperMaterial is a shared passage, table or chart description used by several questions
+type ParsedPaperMaterial struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+// ParsedPaperOption is a choice option
+type ParsedPaperOption struct {
+	Key     string `json:"key"`
+	Content string `json:"content"`
+}
+
+// ParsedPaperQuestion is a single numbered question
+type ParsedPaperQuestion struct {
+	Number        int                 `json:"number"`
+	Content       string              `json:"content"`
+	Options       []ParsedPaperOption `json:"options,omitempty"`
+	Answer        string              `json:"answer,omitempty"`
+	Analysis      string              `json:"analysis,omitempty"`
+	MaterialIndex *int                `json:"material_index,omitempty"` // index into the section's Materials
+}
+
+// ParsedAnswer is one entry of an answer key
+type ParsedAnswer struct {
+	Answer   string `json:"answer"`
+	Analysis string `json:"analysis,omitempty"`
+}
+
+// QuestionCount returns the total number of questions
+func (p *ParsedPaper) QuestionCount() int {
+	count := 0
+	for _, s := range p.Sections {
+		count += len(s.Questions)
+	}
+	return count
+}
+
+// Coverage returns found / expected questions, where expected is the span of question numbers
+func (p *ParsedPaper) Coverage() float64 {
+	minNum, maxNum, count := 0, 0, 0
+	for _, s := range p.Sections {
+		for _, q := range s.Questions {
+			if count == 0 || q.Number < minNum {
+				minNum = q.Number
+			}
+			if q.Number > maxNum {
+				maxNum = q.Number
+			}
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return float64(count) / float64(maxNum-minNum+1)
+}
+
+// ApplyAnswers fills missing answers and analyses from an answer key and returns the
+// numbers of questions that are still without an answer
+func (p *ParsedPaper) ApplyAnswers(answers map[int]ParsedAnswer) []int {
+	var missing []int
+	for si := range p.Sections {
+		for qi := range p.Sections[si].Questions {
+			q := &p.Sections[si].Questions[qi]
+			if a, ok := answers[q.Number]; ok {
+				if q.Answer == "" {
+					q.Answer = a.Answer
+				}
+				if q.Analysis == "" {
+					q.Analysis = a.Analysis
+				}
+			}
+			if q.Answer == "" {
+				missing = append(missing, q.Number)
+			}
+		}
+	}
+	return missing
+}
+
+var (
+	paperSectionPattern  = regexp.MustCompile(`^(?:第[一二三四五六七八九十]+部分|[一二三四五六七八九十]+\s*[、.．])\s*(.+)$`)
+	paperQuestionPattern = regexp.MustCompile(`^(\d{1,3})\s*[.．、]\s*(.*)$`)
+	paperOptionPattern   = regexp.MustCompile(`(?:^|\s)([A-H])\s*[.．、:：]`)
+	paperMaterialPattern = regexp.MustCompile(`^(?:[（(][一二三四五六七八九十]+[)）]\s*)?(?:根据|阅读|参考)(?:以下|下列|下面|所给|给定)?的?(?:资料|材料|图表|文字|表格)`)
+	paperSubheadPattern  = regexp.MustCompile(`^[（(][一二三四五六七八九十]+[)）]\s*$`)
+	paperRangePattern    = regexp.MustCompile(`(\d{1,3})\s*[~～\-—–至到]\s*(\d{1,3})\s*题`)
+	paperAnswerPattern   = regexp.MustCompile(`^【?(?:正确答案|参考答案|答案)】?\s*[:：]?\s*(.*)$`)
+	paperAnalysisPattern = regexp.MustCompile(`^【?(?:答案解析|解析|分析)】?\s*[:：]?\s*(.*)$`)
+	paperParenPattern    = regexp.MustCompile(`\s*[（(][^（()）]*[)）]\s*$`)
+
+	answerRangePattern  = regexp.MustCompile(`(\d{1,3})\s*[~～\-—–至到]\s*(\d{1,3})\s*[:：.．、]?\s*([A-HＡ-Ｈ]{2,})`)
+	answerSinglePattern = regexp.MustCompile(`(\d{1,3})\s*[.．、:：\-]?\s*([A-HＡ-Ｈ]{1,4})(?:[^A-Za-zＡ-Ｚａ-ｚ]|$)`)
+	answerLeadPattern   = regexp.MustCompile(`^\s*([A-HＡ-Ｈ]{1,4})(?:[^A-Za-zＡ-Ｚａ-ｚ]|$)`)
+	answerMarkerPattern = regexp.MustCompile(`【?(?:正确答案|参考答案|答案)】?\s*[:：]?\s*([A-HＡ-Ｈ]{1,4}|正确|错误|对|错|√|×)`)
+	analysisMarkPattern = regexp.MustCompile(`【?(?:答案解析|解析)】?\s*[:：]?`)
+)
+
+// PaperParser segments exam papers extracted from Word or PDF files
+type PaperParser struct {
+	Logger *zap.Logger
+}
+
+// NewPaperParser creates a new paper parser
+func NewPaperParser(logger *zap.Logger) *PaperParser {
+	return &PaperParser{Logger: logger}
+}
+
+// ExtractText extracts plain text from a .docx, .doc or .pdf file
+func (p *PaperParser) ExtractText(filePath string) (string, error) {
+	lowerPath := strings.ToLower(filePath)
+	switch {
+	case strings.HasSuffix(lowerPath, ".docx"), strings.HasSuffix(lowerPath, ".doc"):
+		return NewWordParser(p.Logger).ExtractText(filePath)
+	case strings.HasSuffix(lowerPath, ".pdf"):
+		return NewPDFParser(p.Logger).ExtractText(filePath)
+	default:
+		return "", fmt.Errorf("unsupported file format, please use .doc, .docx or .pdf")
+	}
+}
+
+// paperLineMode tells where continuation lines belong
+type paperLineMode int
+
+const (
+	modeNone paperLineMode = iota
+	modeMaterial
+	modeQuestion
+	modeOption
+	modeAnswer
+	modeAnalysis
+)
+
+// paperState tracks the segmentation progress
+type paperState struct {
+	paper      *ParsedPaper
+	section    *ParsedPaperSection
+	question   *ParsedPaperQuestion
+	material   int // index of the active material in the current section, -1 for none
+	materialTo int // last question number covered by the active material, 0 when unknown
+	lastNumber int
+	mode       paperLineMode
+}
+
+// Parse segments paper text into sections, materials and questions. Answers and
+// analyses embedded after each question (【答案】/【解析】) are picked up as well.
+func (p *PaperParser) Parse(text string) *ParsedPaper {
+	st := &paperState{paper: &ParsedPaper{}, material: -1}
+
+	for _, raw := range strings.Split(text, "\n") {
+		line := normalizePaperLine(raw)
+		if line == "" {
+			continue
+		}
+		st.consume(line)
+	}
+	st.flushQuestion()
+
+	// Drop sections that ended up without questions (cover pages, instructions)
+	sections := st.paper.Sections[:0]
+	for _, s := range st.paper.Sections {
+		if len(s.Questions) > 0 {
+			sections = append(sections, s)
+		}
+	}
+	st.paper.Sections = sections
+
+	if p.Logger != nil {
+		p.Logger.Debug("Paper segmentation completed",
+			zap.Int("sections", len(st.paper.Sections)),
+			zap.Int("questions", st.paper.QuestionCount()),
+		)
+	}
+	return st.paper
+}
+
+func normalizePaperLine(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r == '　' || r == ' ' || r == '\t':
+			return ' '
+		case r >= '０' && r <= '９':
+			return r - '０' + '0'
+		case r >= 'Ａ' && r <= 'Ｚ':
+			return r - 'Ａ' + 'A'
+		}
+		return r
+	}, s)
+	return strings.TrimSpace(s)
+}
+
+func (st *paperState) consume(line string) {
+	// Section heading
+	if m := paperSectionPattern.FindStringSubmatch(line); m != nil && !paperQuestionPattern.MatchString(line) {
+		name := strings.TrimSpace(paperParenPattern.ReplaceAllString(m[1], ""))
+		if name != "" && len([]rune(name)) <= 20 {
+			st.flushQuestion()
+			st.paper.Sections = append(st.paper.Sections, ParsedPaperSection{Name: name})
+			st.section = &st.paper.Sections[len(st.paper.Sections)-1]
+			st.material, st.materialTo = -1, 0
+			st.mode = modeNone
+			return
+		}
+	}
+
+	// Title: first meaningful line before any section or question
+	if st.paper.Title == "" && st.section == nil && st.question == nil && !paperQuestionPattern.MatchString(line) {
+		st.paper.Title = line
+		return
+	}
+
+	// Shared material
+	if paperMaterialPattern.MatchString(line) || paperSubheadPattern.MatchString(line) {
+		st.flushQuestion()
+		sec := st.currentSection()
+		sec.Materials = append(sec.Materials, ParsedPaperMaterial{Title: line})
+		st.material = len(sec.Materials) - 1
+		st.materialTo = 0
+		if m := paperRangePattern.FindStringSubmatch(line); m != nil {
+			st.materialTo, _ = strconv.Atoi(m[2])
+		}
+		st.mode = modeMaterial
+		return
+	}
+
+	// Numbered question, accepted only when it continues the numbering so that
+	// numbered lists inside materials are not mistaken for questions
+	if m := paperQuestionPattern.FindStringSubmatch(line); m != nil {
+		num, _ := strconv.Atoi(m[1])
+		if st.lastNumber == 0 || (num > st.lastNumber && num <= st.lastNumber+3) {
+			st.flushQuestion()
+			if st.lastNumber > 0 && num != st.lastNumber+1 {
+				st.paper.Warnings = append(st.paper.Warnings, fmt.Sprintf("第 %d-%d 题未识别", st.lastNumber+1, num-1))
+			}
+			st.lastNumber = num
+			if st.material >= 0 && st.materialTo > 0 && num > st.materialTo {
+				st.material, st.materialTo = -1, 0
+			}
+			st.question = &ParsedPaperQuestion{Number: num}
+			if st.material >= 0 {
+				idx := st.material
+				st.question.MaterialIndex = &idx
+			}
+			st.mode = modeQuestion
+			st.appendQuestionText(m[2])
+			return
+		}
+	}
+
+	if st.question != nil {
+		if m := paperAnswerPattern.FindStringSubmatch(line); m != nil {
+			st.question.Answer = normalizeAnswer(m[1])
+			st.mode = modeAnswer
+			return
+		}
+		if m := paperAnalysisPattern.FindStringSubmatch(line); m != nil {
+			st.question.Analysis = strings.TrimSpace(m[1])
+			st.mode = modeAnalysis
+			return
+		}
+		if st.mode != modeAnalysis && st.mode != modeAnswer && st.appendOptions(line) {
+			st.mode = modeOption
+			return
+		}
+	}
+
+	switch st.mode {
+	case modeMaterial:
+		sec := st.currentSection()
+		mat := &sec.Materials[st.material]
+		mat.Content = joinPaperLines(mat.Content, line)
+	case modeQuestion:
+		st.appendQuestionText(line)
+	case modeOption:
+		opts := st.question.Options
+		opts[len(opts)-1].Content = joinPaperLines(opts[len(opts)-1].Content, line)
+	case modeAnswer, modeAnalysis:
+		st.question.Analysis = joinPaperLines(st.question.Analysis, line)
+		st.mode = modeAnalysis
+	}
+}
+
+func (st *paperState) currentSection() *ParsedPaperSection {
+	if st.section == nil {
+		st.paper.Sections = append(st.paper.Sections, ParsedPaperSection{})
+		st.section = &st.paper.Sections[len(st.paper.Sections)-1]
+	}
+	return st.section
+}
+
+// appendQuestionText adds stem text, splitting off any options that share the line
+func (st *paperState) appendQuestionText(text string) {
+	if loc := paperOptionPattern.FindStringIndex(text); loc != nil && loc[0] > 0 {
+		stem := strings.TrimSpace(text[:loc[0]])
+		if st.startsOptions(text[loc[0]:]) {
+			st.question.Content = joinPaperLines(st.question.Content, stem)
+			st.appendOptions(strings.TrimSpace(text[loc[0]:]))
+			st.mode = modeOption
+			return
+		}
+	}
+	st.question.Content = joinPaperLines(st.question.Content, text)
+}
+
+// startsOptions reports whether text begins with the next expected option key
+func (st *paperState) startsOptions(text string) bool {
+	m := paperOptionPattern.FindStringSubmatch(strings.TrimSpace(text))
+	return m != nil && m[1] == string(rune('A'+len(st.question.Options)))
+}
+
+// appendOptions parses one or more consecutive options on a line
+// ("A.xxx", "A.xxx B.xxx C.xxx D.xxx"); returns false if the line does not start with the next key
+func (st *paperState) appendOptions(line string) bool {
+	locs := paperOptionPattern.FindAllStringSubmatchIndex(line, -1)
+	if len(locs) == 0 || strings.TrimSpace(line[:locs[0][0]]) != "" {
+		return false
+	}
+
+	expected := 'A' + rune(len(st.question.Options))
+	var starts []int
+	var keys []string
+	for _, loc := range locs {
+		key := line[loc[2]:loc[3]]
+		if rune(key[0]) != expected {
+			continue
+		}
+		starts = append(starts, loc[0], loc[1])
+		keys = append(keys, key)
+		expected++
+	}
+	if len(keys) == 0 {
+		return false
+	}
+	for i, key := range keys {
+		end := len(line)
+		if i+1 < len(keys) {
+			end = starts[2*(i+1)]
+		}
+		content := strings.TrimSpace(line[starts[2*i+1]:end])
+		st.question.Options = append(st.question.Options, ParsedPaperOption{Key: key, Content: content})
+	}
+	return true
+}
+
+func (st *paperState) flushQuestion() {
+	if st.question == nil {
+		return
+	}
+	sec := st.currentSection()
+	sec.Questions = append(sec.Questions, *st.question)
+	st.question = nil
+	st.mode = modeNone
+}
+
+func joinPaperLines(a, b string) string {
+	if a == "" {
+		return b
+	}
+	if b == "" {
+		return a
+	}
+	return a + "\n" + b
+}
+
+// normalizeAnswer keeps option letters ("A", "ACD") or a judge answer, dropping punctuation
+func normalizeAnswer(s string) string {
+	s = normalizePaperLine(s)
+	if m := answerLeadPattern.FindStringSubmatch(s); m != nil {
+		return m[1]
+	}
+	for _, judge := range []string{"正确", "错误", "对", "错", "√", "×"} {
+		if strings.HasPrefix(s, judge) {
+			return judge
+		}
+	}
+	return strings.TrimFunc(s, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsPunct(r) })
+}
+
+// ParseAnswerKey parses an answer key. It understands compact lists
+// ("1.A 2.C 3.D", "1-5 ABCDA") and per-question blocks with 【答案】/【解析】 markers.
+func (p *PaperParser) ParseAnswerKey(text string) map[int]ParsedAnswer {
+	answers := make(map[int]ParsedAnswer)
+
+	var blockNum int
+	var block strings.Builder
+	flush := func() {
+		if blockNum == 0 {
+			return
+		}
+		body := strings.TrimSpace(block.String())
+		var entry ParsedAnswer
+		if m := answerMarkerPattern.FindStringSubmatch(body); m != nil {
+			entry.Answer = normalizeAnswer(m[1])
+		} else if m := answerLeadPattern.FindStringSubmatch(body); m != nil {
+			entry.Answer = m[1]
+		}
+		if loc := analysisMarkPattern.FindStringIndex(body); loc != nil {
+			entry.Analysis = strings.TrimSpace(body[loc[1]:])
+		}
+		if entry.Answer != "" || entry.Analysis != "" {
+			if _, exists := answers[blockNum]; !exists || entry.Analysis != "" {
+				answers[blockNum] = entry
+			}
+		}
+		blockNum = 0
+		block.Reset()
+	}
+
+	for _, raw := range strings.Split(text, "\n") {
+		line := normalizePaperLine(raw)
+		if line == "" {
+			continue
+		}
+
+		// Compact ranges: 1-5 ABCDA
+		if ms := answerRangePattern.FindAllStringSubmatch(line, -1); len(ms) > 0 && !strings.Contains(line, "解析") {
+			flush()
+			for _, m := range ms {
+				from, _ := strconv.Atoi(m[1])
+				to, _ := strconv.Atoi(m[2])
+				letters := []rune(m[3])
+				for i := 0; i < len(letters) && from+i <= to; i++ {
+					answers[from+i] = ParsedAnswer{Answer: string(letters[i])}
+				}
+			}
+			continue
+		}
+		// Compact lists: 1.A 2.C 3.D
+		if ms := answerSinglePattern.FindAllStringSubmatch(line, -1); len(ms) >= 2 && !strings.Contains(line, "解析") {
+			flush()
+			for _, m := range ms {
+				num, _ := strconv.Atoi(m[1])
+				answers[num] = ParsedAnswer{Answer: m[2]}
+			}
+			continue
+		}
+
+		if m := paperQuestionPattern.FindStringSubmatch(line); m != nil {
+			flush()
+			blockNum, _ = strconv.Atoi(m[1])
+			block.WriteString(m[2])
+			continue
+		}
+		if blockNum > 0 {
+			block.WriteString("\n")
+			block.WriteString(line)
+		}
+	}
+	flush()
+
+	return answers
+}
@@ -23,6 +23,12 @@ type OCRParser interface {
 	ParseImage(imagePath string) (string, error)
 }
 
+// PDFTableOCR is implemented by OCR parsers able to rebuild position tables
+// from the pages of a scanned PDF
+type PDFTableOCR interface {
+	ParsePDFTables(pdfPath string, outputDir string) ([]ParsedTable, error)
+}
+
 // NewPDFParser creates a new PDF parser
 func NewPDFParser(logger *zap.Logger) *PDFPositionParser {
 	return &PDFPositionParser{Logger: logger}
@@ -49,8 +55,13 @@ func (p *PDFPositionParser) Parse(filePath string) ([]ParsedPosition, error) {
 				zap.Int("text_length", len(text)),
 			)
 		}
-		// Could call OCR here if OCRParser is set
-		// For now, return empty with a note
+		if tableOCR, ok := p.OCRParser.(PDFTableOCR); ok {
+			tables, err := tableOCR.ParsePDFTables(filePath, "")
+			if err != nil {
+				return nil, fmt.Errorf("failed to OCR scanned PDF: %w", err)
+			}
+			return FlattenTables(tables), nil
+		}
 		return nil, nil
 	}
 
@@ -0,0 +1,403 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ledongthuc/pdf"
+	"go.uber.org/zap"
+)
+
+// PageImage is a rendered PDF page
+type PageImage struct {
+	Page   int    `json:"page"`
+	Path   string `json:"path"`
+	Source string `json:"source"` // pdftoppm, embedded
+}
+
+// PDFRasterizer renders PDF pages to images for OCR.
+// A local poppler pdftoppm binary is used when present; otherwise the page
+// images embedded in the PDF are extracted directly, which covers scanner
+// output where every page is a single JPEG or Flate-compressed bitmap.
+type PDFRasterizer struct {
+	DPI         int
+	PdftoppmCmd string
+	Timeout     time.Duration
+	Logger      *zap.Logger
+}
+
+// NewPDFRasterizer creates a rasterizer from the OCR configuration
+func NewPDFRasterizer(config *OCRConfig, logger *zap.Logger) *PDFRasterizer {
+	if config == nil {
+		config = DefaultOCRConfig()
+	}
+	r := &PDFRasterizer{
+		DPI:         config.DPI,
+		PdftoppmCmd: config.PdftoppmCmd,
+		Timeout:     config.Timeout,
+		Logger:      logger,
+	}
+	if r.DPI <= 0 {
+		r.DPI = 300
+	}
+	if r.PdftoppmCmd == "" {
+		r.PdftoppmCmd = "pdftoppm"
+	}
+	return r
+}
+
+// HasPdftoppm checks if the poppler renderer is installed
+func (r *PDFRasterizer) HasPdftoppm() bool {
+	_, err := exec.LookPath(r.PdftoppmCmd)
+	return err == nil
+}
+
+// Rasterize renders every page of the PDF into outputDir, ordered by page number
+func (r *PDFRasterizer) Rasterize(pdfPath, outputDir string) ([]PageImage, error) {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output dir: %w", err)
+	}
+
+	if r.HasPdftoppm() {
+		images, err := r.rasterizeWithPdftoppm(pdfPath, outputDir)
+		if err == nil && len(images) > 0 {
+			return images, nil
+		}
+		if r.Logger != nil {
+			r.Logger.Warn("pdftoppm failed, falling back to embedded page images",
+				zap.String("file", pdfPath),
+				zap.Error(err),
+			)
+		}
+	}
+
+	return r.extractPageImages(pdfPath, outputDir)
+}
+
+// rasterizeWithPdftoppm renders pages with poppler, one grayscale PNG per page
+func (r *PDFRasterizer) rasterizeWithPdftoppm(pdfPath, outputDir string) ([]PageImage, error) {
+	ctx := context.Background()
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		// a scanned announcement is usually a few dozen pages
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout*10)
+		defer cancel()
+	}
+
+	prefix := filepath.Join(outputDir, "page")
+	cmd := exec.CommandContext(ctx, r.PdftoppmCmd, "-r", strconv.Itoa(r.DPI), "-gray", "-png", pdfPath, prefix)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("pdftoppm failed: %w - %s", err, stderr.String())
+	}
+
+	files, err := filepath.Glob(prefix + "-*.png")
+	if err != nil {
+		return nil, err
+	}
+
+	var images []PageImage
+	for _, file := range files {
+		// pdftoppm zero-pads the page number to the width of the page count
+		name := strings.TrimSuffix(filepath.Base(file), ".png")
+		page, err := strconv.Atoi(name[strings.LastIndex(name, "-")+1:])
+		if err != nil {
+			continue
+		}
+		images = append(images, PageImage{Page: page, Path: file, Source: "pdftoppm"})
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i].Page < images[j].Page })
+	return images, nil
+}
+
+// extractPageImages writes the largest image of every page without rendering
+func (r *PDFRasterizer) extractPageImages(pdfPath, outputDir string) (images []PageImage, err error) {
+	raw, err := os.ReadFile(pdfPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	f, reader, err := pdf.Open(pdfPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PDF: %w", err)
+	}
+	defer f.Close()
+
+	// the pdf library panics on filters it does not know
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("failed to read PDF page images: %v", rec)
+		}
+	}()
+
+	jpegs := embeddedJPEGs(raw)
+	used := make([]bool, len(jpegs))
+
+	for pageNum := 1; pageNum <= reader.NumPage(); pageNum++ {
+		page := reader.Page(pageNum)
+		if page.V.IsNull() {
+			continue
+		}
+		img := largestPageImage(page.Resources())
+		if img.IsNull() {
+			continue
+		}
+
+		width := int(img.Key("Width").Int64())
+		height := int(img.Key("Height").Int64())
+		base := filepath.Join(outputDir, fmt.Sprintf("page-%03d", pageNum))
+
+		switch filter := imageFilter(img); filter {
+		case "DCTDecode":
+			// the pdf library does not expose undecoded streams, so the JPEG is
+			// located in the file by its dimensions
+			for i, j := range jpegs {
+				if used[i] || j.width != width || j.height != height {
+					continue
+				}
+				used[i] = true
+				path := base + ".jpg"
+				if err := os.WriteFile(path, j.data, 0644); err != nil {
+					return nil, err
+				}
+				images = append(images, PageImage{Page: pageNum, Path: path, Source: "embedded"})
+				break
+			}
+		case "", "FlateDecode":
+			decoded, err := decodeRawImage(img, width, height)
+			if err != nil {
+				if r.Logger != nil {
+					r.Logger.Warn("Failed to decode page image",
+						zap.Int("page", pageNum),
+						zap.Error(err),
+					)
+				}
+				continue
+			}
+			path := base + ".png"
+			if err := writePNG(path, decoded); err != nil {
+				return nil, err
+			}
+			images = append(images, PageImage{Page: pageNum, Path: path, Source: "embedded"})
+		default:
+			if r.Logger != nil {
+				r.Logger.Warn("Unsupported page image filter, install poppler to render this PDF",
+					zap.Int("page", pageNum),
+					zap.String("filter", filter),
+				)
+			}
+		}
+	}
+
+	if len(images) == 0 {
+		return nil, fmt.Errorf("no page images found in PDF - install poppler/pdftoppm to render it")
+	}
+
+	if r.Logger != nil {
+		r.Logger.Debug("Extracted embedded page images",
+			zap.String("file", pdfPath),
+			zap.Int("pages", reader.NumPage()),
+			zap.Int("images", len(images)),
+		)
+	}
+
+	return images, nil
+}
+
+// largestPageImage returns the image XObject covering most of the page
+func largestPageImage(resources pdf.Value) pdf.Value {
+	xobjects := resources.Key("XObject")
+	var best pdf.Value
+	var bestArea int64
+	for _, name := range xobjects.Keys() {
+		obj := xobjects.Key(name)
+		if obj.Key("Subtype").Name() != "Image" {
+			continue
+		}
+		area := obj.Key("Width").Int64() * obj.Key("Height").Int64()
+		if area > bestArea {
+			best, bestArea = obj, area
+		}
+	}
+	return best
+}
+
+// imageFilter returns the single filter of an image stream, or "" when unfiltered
+func imageFilter(img pdf.Value) string {
+	filter := img.Key("Filter")
+	switch filter.Kind() {
+	case pdf.Name:
+		return filter.Name()
+	case pdf.Array:
+		if filter.Len() == 1 {
+			return filter.Index(0).Name()
+		}
+		if filter.Len() > 1 {
+			return "multiple"
+		}
+	}
+	return ""
+}
+
+// imageComponents returns the number of color components of an image
+func imageComponents(img pdf.Value) int {
+	cs := img.Key("ColorSpace")
+	name := cs.Name()
+	if cs.Kind() == pdf.Array {
+		name = cs.Index(0).Name()
+		if name == "ICCBased" {
+			return int(cs.Index(1).Key("N").Int64())
+		}
+	}
+	switch name {
+	case "DeviceGray", "CalGray":
+		return 1
+	case "DeviceRGB", "CalRGB":
+		return 3
+	case "DeviceCMYK":
+		return 4
+	}
+	return 0
+}
+
+// maxRawImagePixels bounds decoded page images, well above a full page
+// scanned at 600 dpi
+const maxRawImagePixels = 100 << 20
+
+// decodeRawImage turns an uncompressed or Flate-compressed image stream into an image
+func decodeRawImage(img pdf.Value, width, height int) (image.Image, error) {
+	if img.Key("ImageMask").Bool() {
+		return nil, fmt.Errorf("image masks are not supported")
+	}
+	comps := imageComponents(img)
+	bpc := int(img.Key("BitsPerComponent").Int64())
+	if comps == 0 || (bpc != 8 && !(bpc == 1 && comps == 1)) {
+		return nil, fmt.Errorf("unsupported image format: %d components, %d bits", comps, bpc)
+	}
+
+	if width <= 0 || height <= 0 || int64(width)*int64(height) > maxRawImagePixels {
+		return nil, fmt.Errorf("unsupported image size: %dx%d", width, height)
+	}
+
+	// read no more than the declared size, so a small stream inflating to
+	// gigabytes cannot exhaust memory
+	stride := (width*comps*bpc + 7) / 8
+	rd := img.Reader()
+	defer rd.Close()
+	data, err := io.ReadAll(io.LimitReader(rd, int64(stride)*int64(height)))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < stride*height {
+		return nil, fmt.Errorf("image data too short: %d < %d", len(data), stride*height)
+	}
+
+	bounds := image.Rect(0, 0, width, height)
+	switch {
+	case bpc == 1:
+		out := image.NewGray(bounds)
+		for y := 0; y < height; y++ {
+			row := data[y*stride:]
+			for x := 0; x < width; x++ {
+				if row[x/8]&(0x80>>(x%8)) != 0 {
+					out.Pix[y*out.Stride+x] = 0xff
+				}
+			}
+		}
+		return out, nil
+	case comps == 1:
+		out := image.NewGray(bounds)
+		for y := 0; y < height; y++ {
+			copy(out.Pix[y*out.Stride:], data[y*stride:y*stride+width])
+		}
+		return out, nil
+	case comps == 3:
+		out := image.NewRGBA(bounds)
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				p := data[y*stride+x*3:]
+				out.Set(x, y, color.RGBA{R: p[0], G: p[1], B: p[2], A: 0xff})
+			}
+		}
+		return out, nil
+	default:
+		out := image.NewCMYK(bounds)
+		for y := 0; y < height; y++ {
+			copy(out.Pix[y*out.Stride:], data[y*stride:y*stride+width*4])
+		}
+		return out, nil
+	}
+}
+
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// embeddedJPEG is a JPEG stream found in the raw PDF bytes
+type embeddedJPEG struct {
+	data          []byte
+	width, height int
+}
+
+// embeddedJPEGs finds every stream holding a JPEG file in document order
+func embeddedJPEGs(raw []byte) []embeddedJPEG {
+	var out []embeddedJPEG
+	keyword := []byte("stream")
+	for pos := 0; ; {
+		idx := bytes.Index(raw[pos:], keyword)
+		if idx < 0 {
+			break
+		}
+		at := pos + idx
+		start := at + len(keyword)
+		pos = start
+		// skip "endstream"
+		if at >= 3 && string(raw[at-3:at]) == "end" {
+			continue
+		}
+		if start < len(raw) && raw[start] == '\r' {
+			start++
+		}
+		if start < len(raw) && raw[start] == '\n' {
+			start++
+		}
+		if !bytes.HasPrefix(raw[start:], []byte{0xff, 0xd8, 0xff}) {
+			continue
+		}
+		end := bytes.Index(raw[start:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		data := bytes.TrimRight(raw[start:start+end], "\r\n")
+		pos = start + end
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			continue
+		}
+		out = append(out, embeddedJPEG{data: data, width: cfg.Width, height: cfg.Height})
+	}
+	return out
+}
@@ -0,0 +1,427 @@
+package parser
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// IgnoreColumn maps a column to nothing in a ColumnProfile
+const IgnoreColumn = "-"
+
+// Column mapping sources reported in HeaderColumn.Source
+const (
+	ColumnSourceProfile  = "profile"
+	ColumnSourceBuiltin  = "builtin"
+	ColumnSourceIgnored  = "ignored"
+	ColumnSourceUnmapped = "unmapped"
+)
+
+// maxHeaderRows is the deepest merged header resolved
+const maxHeaderRows = 3
+
+// headerSearchRows limits how far below the top title rows a header is searched
+const headerSearchRows = 30
+
+// PositionFields lists the fields a table column can be mapped to
+var PositionFields = []string{
+	"department_name", "department_code", "position_name", "position_code",
+	"recruit_count", "work_location", "education_min", "major_specific",
+	"political_status", "gender_required", "age_requirement", "work_exp_requirement",
+	"hukou_requirement", "other_requirements", "notes",
+}
+
+var (
+	digitsOnlyRe = regexp.MustCompile(`^[0-9]+$`)
+	spaceRe      = regexp.MustCompile(`\s+`)
+)
+
+// ColumnProfile overrides how the columns of a position table map to fields,
+// e.g. for a province whose 职位表 uses its own column names. Columns is keyed
+// by the resolved column path ("招录条件/学历") or by the last header cell
+// ("学历"); the value is one of PositionFields or IgnoreColumn.
+type ColumnProfile struct {
+	Columns    map[string]string `json:"columns,omitempty"`
+	HeaderRow  int               `json:"header_row,omitempty"`  // 1-based first header row, 0 detects it
+	HeaderRows int               `json:"header_rows,omitempty"` // header depth, 0 detects it
+}
+
+// HeaderColumn is a table column resolved from a possibly merged, multi-row header
+type HeaderColumn struct {
+	Index  int      `json:"index"`
+	Path   []string `json:"path"`
+	Name   string   `json:"name"` // Path joined with "/"
+	Field  string   `json:"field,omitempty"`
+	Source string   `json:"source"` // profile/builtin/ignored/unmapped
+}
+
+// ParsedTable is one sheet or table with its resolved header and positions
+type ParsedTable struct {
+	Name       string           `json:"name"`
+	HeaderRow  int              `json:"header_row"` // 1-based
+	HeaderRows int              `json:"header_rows"`
+	Columns    []HeaderColumn   `json:"columns"`
+	Positions  []ParsedPosition `json:"positions"`
+}
+
+// FlattenTables returns the positions of all tables in order
+func FlattenTables(tables []ParsedTable) []ParsedPosition {
+	var positions []ParsedPosition
+	for _, t := range tables {
+		positions = append(positions, t.Positions...)
+	}
+	return positions
+}
+
+// cellText normalizes a cell: header cells often wrap lines inside words
+func cellText(s string) string {
+	return strings.TrimSpace(spaceRe.ReplaceAllString(s, " "))
+}
+
+// headerKey removes all whitespace, "职位\n代码" is the header 职位代码
+func headerKey(s string) string {
+	return spaceRe.ReplaceAllString(s, "")
+}
+
+// padGrid makes all rows as wide as the widest row
+func padGrid(grid [][]string) [][]string {
+	width := 0
+	for _, row := range grid {
+		width = max(width, len(row))
+	}
+	for i, row := range grid {
+		for len(row) < width {
+			row = append(row, "")
+		}
+		grid[i] = row
+	}
+	return grid
+}
+
+// fillMergedRange copies the top-left value of a merged range into every cell
+// of the range (0-based, inclusive)
+func fillMergedRange(grid [][]string, top, left, bottom, right int) {
+	if top < 0 || left < 0 || top >= len(grid) || left >= len(grid[top]) {
+		return
+	}
+	value := grid[top][left]
+	for r := top; r <= bottom && r < len(grid); r++ {
+		// rows read by excelize stop at their last non-empty cell
+		for len(grid[r]) <= right {
+			grid[r] = append(grid[r], "")
+		}
+		for c := left; c <= right; c++ {
+			grid[r][c] = value
+		}
+	}
+}
+
+// htmlTableGrid expands an HTML table into a grid, copying the value of a
+// rowspan/colspan cell into every position it covers
+func htmlTableGrid(table *goquery.Selection) [][]string {
+	var grid [][]string
+	// pending[c] holds a value still spanning down column c and the rows left
+	type span struct {
+		value string
+		rows  int
+	}
+	pending := map[int]*span{}
+
+	table.Find("tr").Each(func(_ int, tr *goquery.Selection) {
+		// skip rows of nested tables
+		if tr.ParentsFiltered("table").First().Get(0) != table.Get(0) {
+			return
+		}
+		var row []string
+		col := 0
+		fillPending := func() {
+			for {
+				s, ok := pending[col]
+				if !ok || s.rows == 0 {
+					return
+				}
+				row = append(row, s.value)
+				if s.rows--; s.rows == 0 {
+					delete(pending, col)
+				}
+				col++
+			}
+		}
+		tr.ChildrenFiltered("th, td").Each(func(_ int, cell *goquery.Selection) {
+			fillPending()
+			value := cellText(cell.Text())
+			colspan := spanAttr(cell, "colspan")
+			rowspan := spanAttr(cell, "rowspan")
+			for i := 0; i < colspan; i++ {
+				row = append(row, value)
+				if rowspan > 1 {
+					pending[col] = &span{value: value, rows: rowspan - 1}
+				}
+				col++
+			}
+		})
+		fillPending()
+		if len(row) > 0 {
+			grid = append(grid, row)
+		}
+	})
+	return padGrid(grid)
+}
+
+func spanAttr(cell *goquery.Selection, name string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(cell.AttrOr(name, "1")))
+	if err != nil || n < 1 {
+		return 1
+	}
+	// guard against absurd spans in hand written pages
+	return min(n, 1000)
+}
+
+// isLabelRow reports whether a row looks like header labels: at least two
+// distinct short cells and no bare numbers, which data rows nearly always have
+func isLabelRow(row []string) bool {
+	distinct := map[string]bool{}
+	for _, c := range row {
+		c = headerKey(c)
+		if c == "" {
+			continue
+		}
+		if digitsOnlyRe.MatchString(c) || utf8.RuneCountInString(c) > 30 {
+			return false
+		}
+		distinct[c] = true
+	}
+	// a title merged across the whole table repeats one value
+	return len(distinct) >= 2
+}
+
+// continuesHeader reports whether row is a further header row below prev:
+// prev has cells merged across columns, cells merged down into row, or empty
+// cells above labels (merges lost by the .xls reader)
+func continuesHeader(prev, row []string) bool {
+	for c := range row {
+		if c >= len(prev) {
+			break
+		}
+		above, cur := headerKey(prev[c]), headerKey(row[c])
+		if above != "" && above == cur {
+			return true
+		}
+		if above == "" && cur != "" {
+			return true
+		}
+		if c > 0 && above != "" && above == headerKey(prev[c-1]) && cur != headerKey(row[c-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// headerHits counts position table keywords in the text of header rows
+func headerHits(rows [][]string) int {
+	var b strings.Builder
+	for _, row := range rows {
+		for _, c := range row {
+			b.WriteString(headerKey(c))
+			b.WriteString(" ")
+		}
+	}
+	text := b.String()
+	hits := 0
+	for _, keyword := range PositionTableKeywords {
+		if strings.Contains(text, keyword) {
+			hits++
+		}
+	}
+	return hits
+}
+
+// locateHeader finds the first header row (0-based) and the header depth,
+// skipping title rows above the table
+func locateHeader(grid [][]string, profile *ColumnProfile) (int, int) {
+	if profile != nil && profile.HeaderRow > 0 {
+		start := profile.HeaderRow - 1
+		if start >= len(grid) {
+			return -1, 0
+		}
+		depth := profile.HeaderRows
+		if depth <= 0 {
+			depth = headerDepth(grid, start)
+		}
+		return start, min(depth, len(grid)-start)
+	}
+
+	for i := 0; i < len(grid) && i < headerSearchRows; i++ {
+		if !isLabelRow(grid[i]) {
+			continue
+		}
+		depth := headerDepth(grid, i)
+		if profile != nil && profile.HeaderRows > 0 {
+			depth = min(profile.HeaderRows, len(grid)-i)
+		}
+		if headerHits(grid[i:i+depth]) >= 3 {
+			return i, depth
+		}
+	}
+	return -1, 0
+}
+
+// headerDepth counts the rows of the header starting at start
+func headerDepth(grid [][]string, start int) int {
+	depth := 1
+	for depth < maxHeaderRows && start+depth < len(grid) {
+		next := grid[start+depth]
+		if !isLabelRow(next) || !continuesHeader(grid[start+depth-1], next) {
+			break
+		}
+		depth++
+	}
+	return depth
+}
+
+// headerColumns resolves every column of a header block into a path of
+// distinct header cells from top to bottom and maps it to a field
+func headerColumns(header [][]string, profile *ColumnProfile) []HeaderColumn {
+	// fill gaps of merges the reader lost: an empty cell below a label
+	// continues the label above, an empty cell above sub labels continues the
+	// label on its left
+	block := make([][]string, len(header))
+	for r := range header {
+		block[r] = make([]string, len(header[r]))
+		for c := range header[r] {
+			block[r][c] = headerKey(header[r][c])
+		}
+	}
+	for r := range block {
+		for c := range block[r] {
+			if block[r][c] != "" {
+				continue
+			}
+			switch {
+			case r+1 < len(block) && c > 0 && block[r][c-1] != "" && c < len(block[r+1]) && block[r+1][c] != "":
+				block[r][c] = block[r][c-1]
+			case r > 0 && c < len(block[r-1]):
+				block[r][c] = block[r-1][c]
+			}
+		}
+	}
+
+	width := 0
+	for _, row := range block {
+		width = max(width, len(row))
+	}
+	columns := make([]HeaderColumn, 0, width)
+	for c := 0; c < width; c++ {
+		var path []string
+		for r := range block {
+			if c >= len(block[r]) || block[r][c] == "" {
+				continue
+			}
+			if len(path) == 0 || path[len(path)-1] != block[r][c] {
+				path = append(path, block[r][c])
+			}
+		}
+		col := HeaderColumn{Index: c, Path: path, Name: strings.Join(path, "/")}
+		col.Field, col.Source = mapColumn(path, profile)
+		columns = append(columns, col)
+	}
+	return columns
+}
+
+// builtinKeys are the FieldMapping keys, longest first so that 招录人数 wins
+// over 人数 in partial matches
+var builtinKeys = func() []string {
+	keys := make([]string, 0, len(FieldMapping))
+	for k := range FieldMapping {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if li, lj := utf8.RuneCountInString(keys[i]), utf8.RuneCountInString(keys[j]); li != lj {
+			return li > lj
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}()
+
+// mapColumn maps a column path to a position field: the profile by full path,
+// then by last cell; then FieldMapping exactly, then partially on the last
+// cell and finally on the whole path
+func mapColumn(path []string, profile *ColumnProfile) (string, string) {
+	if len(path) == 0 {
+		return "", ColumnSourceUnmapped
+	}
+	name := strings.Join(path, "/")
+	leaf := path[len(path)-1]
+
+	if profile != nil {
+		for _, key := range []string{name, leaf} {
+			if field, ok := profile.Columns[key]; ok {
+				if field == IgnoreColumn || field == "" {
+					return "", ColumnSourceIgnored
+				}
+				return field, ColumnSourceProfile
+			}
+		}
+	}
+
+	if field, ok := FieldMapping[leaf]; ok {
+		return field, ColumnSourceBuiltin
+	}
+	for _, key := range builtinKeys {
+		if strings.Contains(leaf, key) || strings.Contains(key, leaf) {
+			return FieldMapping[key], ColumnSourceBuiltin
+		}
+	}
+	joined := strings.Join(path, "")
+	for _, key := range builtinKeys {
+		if strings.Contains(joined, key) {
+			return FieldMapping[key], ColumnSourceBuiltin
+		}
+	}
+	return "", ColumnSourceUnmapped
+}
+
+// parseGrid resolves the header of a grid and parses the rows below it
+func parseGrid(name string, grid [][]string, profile *ColumnProfile, confidence int,
+	setField func(pos *ParsedPosition, fieldName, value string)) *ParsedTable {
+	start, depth := locateHeader(grid, profile)
+	if start < 0 {
+		return nil
+	}
+	table := &ParsedTable{
+		Name:       name,
+		HeaderRow:  start + 1,
+		HeaderRows: depth,
+		Columns:    headerColumns(grid[start:start+depth], profile),
+	}
+	headerLine := strings.Join(grid[start+depth-1], "")
+
+	for _, row := range grid[start+depth:] {
+		// headers repeated on each printed page
+		if strings.Join(row, "") == headerLine {
+			continue
+		}
+		position := ParsedPosition{ParseConfidence: confidence}
+		hasData := false
+		for _, col := range table.Columns {
+			if col.Field == "" || col.Index >= len(row) {
+				continue
+			}
+			value := strings.TrimSpace(row[col.Index])
+			if value == "" {
+				continue
+			}
+			hasData = true
+			setField(&position, col.Field, value)
+		}
+		if hasData && position.PositionName != "" {
+			table.Positions = append(table.Positions, position)
+		}
+	}
+	return table
+}
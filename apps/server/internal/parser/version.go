@@ -0,0 +1,7 @@
+package parser
+
+// Version identifies the extraction logic of this package. Positions record
+// the version that produced them, and archived attachments parsed with an
+// older version are picked up by `cli attachment-reparse`. Bump it whenever a
+// change alters the positions extracted from the same file.
+const Version = "2026.10.1"
@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"strings"
+	"time"
+
 	"github.com/what-cse/server/internal/model"
 	"gorm.io/gorm"
 )
@@ -123,3 +126,103 @@ func (r *AnnouncementRepository) GetLatest(limit int) ([]model.Announcement, err
 	}
 	return announcements, nil
 }
+
+// ListUnlinked 获取尚未做批次关联的公告，按发布日期升序，保证招录公告先于后续公告处理
+func (r *AnnouncementRepository) ListUnlinked(limit int) ([]model.Announcement, error) {
+	var announcements []model.Announcement
+	err := r.db.Where("linked_at IS NULL").
+		Order("publish_date ASC, id ASC").
+		Limit(limit).
+		Find(&announcements).Error
+	return announcements, err
+}
+
+// ListByCampaign 获取招录批次下的全部公告，按发布日期升序
+func (r *AnnouncementRepository) ListByCampaign(campaignID uint) ([]model.Announcement, error) {
+	var announcements []model.Announcement
+	err := r.db.Where("campaign_id = ?", campaignID).
+		Order("publish_date ASC, id ASC").
+		Find(&announcements).Error
+	return announcements, err
+}
+
+// SetCampaign 记录公告的批次关联结果，campaignID 为 nil 表示已处理但未归入任何批次
+func (r *AnnouncementRepository) SetCampaign(id uint, campaignID *uint, announcementType string, linkedAt time.Time) error {
+	updates := map[string]interface{}{
+		"campaign_id": campaignID,
+		"linked_at":   linkedAt,
+		"link_error":  "",
+	}
+	if announcementType != "" {
+		updates["announcement_type"] = announcementType
+	}
+	return r.db.Model(&model.Announcement{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// SetLinkError 记录批次关联失败原因，并标记为已处理
+func (r *AnnouncementRepository) SetLinkError(id uint, linkError string, linkedAt time.Time) error {
+	if len([]rune(linkError)) > 500 {
+		linkError = string([]rune(linkError)[:500])
+	}
+	return r.db.Model(&model.Announcement{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"link_error": linkError,
+		"linked_at":  linkedAt,
+	}).Error
+}
+
+// AnnouncementFeedFilter 订阅源筛选条件
+// 单值字段之间为“且”；Match* 为用户订阅条件，任一匹配即可（与订阅推送的匹配规则一致）
+type AnnouncementFeedFilter struct {
+	AnnouncementType string
+	ExamType         string
+	Province         string
+
+	MatchSubscriptions bool // 为 true 时按下列订阅条件过滤，没有任何条件时结果为空
+	MatchExamTypes     []string
+	MatchProvinces     []string
+	MatchCities        []string
+	MatchKeywords      []string // 标题包含
+}
+
+// ListForFeed 获取订阅源的最新已发布公告
+func (r *AnnouncementRepository) ListForFeed(filter *AnnouncementFeedFilter, limit int) ([]model.Announcement, error) {
+	query := r.db.Where("status = ?", model.AnnouncementStatusPublished)
+	if filter.AnnouncementType != "" {
+		query = query.Where("announcement_type = ?", filter.AnnouncementType)
+	}
+	if filter.ExamType != "" {
+		query = query.Where("exam_type = ?", filter.ExamType)
+	}
+	if filter.Province != "" {
+		query = query.Where("province = ?", filter.Province)
+	}
+
+	if filter.MatchSubscriptions {
+		var conds []string
+		var args []interface{}
+		if len(filter.MatchExamTypes) > 0 {
+			conds = append(conds, "exam_type IN ?")
+			args = append(args, filter.MatchExamTypes)
+		}
+		if len(filter.MatchProvinces) > 0 {
+			conds = append(conds, "province IN ?")
+			args = append(args, filter.MatchProvinces)
+		}
+		if len(filter.MatchCities) > 0 {
+			conds = append(conds, "city IN ?")
+			args = append(args, filter.MatchCities)
+		}
+		for _, keyword := range filter.MatchKeywords {
+			conds = append(conds, "title LIKE ?")
+			args = append(args, "%"+keyword+"%")
+		}
+		if len(conds) == 0 {
+			return nil, nil
+		}
+		query = query.Where("("+strings.Join(conds, " OR ")+")", args...)
+	}
+
+	var announcements []model.Announcement
+	err := query.Order("publish_date DESC, id DESC").Limit(limit).Find(&announcements).Error
+	return announcements, err
+}
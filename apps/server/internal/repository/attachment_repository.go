@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/what-cse/server/internal/model"
+	"gorm.io/gorm"
+)
+
+// AttachmentRepository 附件归档仓库
+type AttachmentRepository struct {
+	db *gorm.DB
+}
+
+// NewAttachmentRepository 创建附件归档仓库
+func NewAttachmentRepository(db *gorm.DB) *AttachmentRepository {
+	return &AttachmentRepository{db: db}
+}
+
+// Create 创建附件记录
+func (r *AttachmentRepository) Create(attachment *model.Attachment) error {
+	return r.db.Create(attachment).Error
+}
+
+// GetByID 获取附件，不存在时返回 nil
+func (r *AttachmentRepository) GetByID(id uint) (*model.Attachment, error) {
+	var attachment model.Attachment
+	err := r.db.First(&attachment, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// GetBySHA256 按内容哈希获取附件，不存在时返回 nil
+func (r *AttachmentRepository) GetBySHA256(sha string) (*model.Attachment, error) {
+	var attachment model.Attachment
+	err := r.db.Where("sha256 = ?", sha).First(&attachment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// ListForReparse 按 ID 游标分页获取某类附件；staleVersion 非空时只返回未用该版本解析过的附件
+func (r *AttachmentRepository) ListForReparse(fileType, staleVersion string, afterID uint, limit int) ([]model.Attachment, error) {
+	var attachments []model.Attachment
+	query := r.db.Where("id > ?", afterID)
+	if fileType != "" {
+		query = query.Where("file_type = ?", fileType)
+	}
+	if staleVersion != "" {
+		query = query.Where("parser_version IS NULL OR parser_version <> ?", staleVersion)
+	}
+	err := query.Order("id ASC").Limit(limit).Find(&attachments).Error
+	return attachments, err
+}
+
+// MarkParsed 记录一次解析结果
+func (r *AttachmentRepository) MarkParsed(id uint, version string, positions int, parseErr string, parsedAt time.Time) error {
+	if len(parseErr) > 500 {
+		parseErr = parseErr[:500]
+	}
+	return r.db.Model(&model.Attachment{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"parser_version": version,
+		"position_count": positions,
+		"parse_error":    parseErr,
+		"parsed_at":      parsedAt,
+	}).Error
+}
+
+// =====================================================
+// 抓取来源
+// =====================================================
+
+// AddSource 记录附件来源，同一附件同一 URL 只保留一条并刷新抓取时间和关联公告
+func (r *AttachmentRepository) AddSource(source *model.AttachmentSource) error {
+	var existing model.AttachmentSource
+	err := r.db.Where("attachment_id = ? AND url = ?", source.AttachmentID, source.URL).First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return r.db.Create(source).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	updates := map[string]interface{}{"fetched_at": source.FetchedAt}
+	if source.Name != "" {
+		updates["name"] = source.Name
+	}
+	if source.AnnouncementID != nil {
+		updates["announcement_id"] = source.AnnouncementID
+	}
+	if source.FenbiAnnouncementID != nil {
+		updates["fenbi_announcement_id"] = source.FenbiAnnouncementID
+	}
+	source.ID = existing.ID
+	source.CreatedAt = existing.CreatedAt
+	return r.db.Model(&existing).Updates(updates).Error
+}
+
+// GetSources 获取附件的全部来源，最近抓取的在前
+func (r *AttachmentRepository) GetSources(attachmentID uint) ([]model.AttachmentSource, error) {
+	var sources []model.AttachmentSource
+	err := r.db.Where("attachment_id = ?", attachmentID).Order("fetched_at DESC").Find(&sources).Error
+	return sources, err
+}
+
+// LinkFenbiAnnouncement 把附件来源关联到粉笔公告
+func (r *AttachmentRepository) LinkFenbiAnnouncement(attachmentID uint, url string, fenbiAnnouncementID uint) error {
+	return r.db.Model(&model.AttachmentSource{}).
+		Where("attachment_id = ? AND url = ?", attachmentID, url).
+		Update("fenbi_announcement_id", fenbiAnnouncementID).Error
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"github.com/what-cse/server/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ContentEmbeddingRepository 内容向量仓库
+type ContentEmbeddingRepository struct {
+	db *gorm.DB
+}
+
+// NewContentEmbeddingRepository 创建内容向量仓库
+func NewContentEmbeddingRepository(db *gorm.DB) *ContentEmbeddingRepository {
+	return &ContentEmbeddingRepository{db: db}
+}
+
+// ScanByModel 按主键游标读取指定模型的向量
+func (r *ContentEmbeddingRepository) ScanByModel(entityType, embeddingModel string, afterID uint, limit int) ([]model.ContentEmbedding, error) {
+	var rows []model.ContentEmbedding
+	err := r.db.Where("entity_type = ? AND model = ? AND id > ?", entityType, embeddingModel, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&rows).Error
+	return rows, err
+}
+
+// Upsert 批量写入向量，(entity_type, entity_id) 冲突时覆盖
+func (r *ContentEmbeddingRepository) Upsert(rows []model.ContentEmbedding) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "entity_type"}, {Name: "entity_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"category_id", "model", "dim", "content_hash", "vector", "scale", "updated_at",
+		}),
+	}).CreateInBatches(rows, 100).Error
+}
+
+// DeleteByEntities 删除指定内容的向量
+func (r *ContentEmbeddingRepository) DeleteByEntities(entityType string, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Where("entity_type = ? AND entity_id IN ?", entityType, ids).
+		Delete(&model.ContentEmbedding{}).Error
+}
+
+// DeleteOtherModels 删除非当前模型生成的向量
+func (r *ContentEmbeddingRepository) DeleteOtherModels(embeddingModel string) (int64, error) {
+	result := r.db.Where("model <> ?", embeddingModel).Delete(&model.ContentEmbedding{})
+	return result.RowsAffected, result.Error
+}
+
+// ScanPublishedQuestions 按主键游标读取已发布题目（仅向量化所需字段）
+func (r *ContentEmbeddingRepository) ScanPublishedQuestions(afterID uint, limit int) ([]model.Question, error) {
+	var questions []model.Question
+	err := r.db.Select("id", "category_id", "content", "analysis", "difficulty").
+		Where("status = ? AND id > ?", model.QuestionStatusPublished, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&questions).Error
+	return questions, err
+}
+
+// ScanPublishedMaterials 按主键游标读取已发布素材（仅向量化所需字段）
+func (r *ContentEmbeddingRepository) ScanPublishedMaterials(afterID uint, limit int) ([]model.LearningMaterial, error) {
+	var materials []model.LearningMaterial
+	err := r.db.Select("id", "category_id", "title", "content", "analysis").
+		Where("status = ? AND id > ?", model.MaterialStatusPublished, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&materials).Error
+	return materials, err
+}
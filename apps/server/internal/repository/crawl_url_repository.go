@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"github.com/what-cse/server/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CrawlURLRepository 爬虫 URL 库数据访问
+type CrawlURLRepository struct {
+	db *gorm.DB
+}
+
+// NewCrawlURLRepository 创建爬虫 URL 库仓储
+func NewCrawlURLRepository(db *gorm.DB) *CrawlURLRepository {
+	return &CrawlURLRepository{db: db}
+}
+
+// GetByHash 按 URL 哈希查询，不存在时返回 nil
+func (r *CrawlURLRepository) GetByHash(urlHash string) (*model.CrawlURL, error) {
+	var record model.CrawlURL
+	err := r.db.Where("url_hash = ?", urlHash).First(&record).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Save 按 URL 哈希写入或更新抓取状态
+func (r *CrawlURLRepository) Save(record *model.CrawlURL) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "url_hash"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"url", "domain", "status", "status_code", "content_hash", "etag", "last_modified",
+			"fail_count", "last_error", "fetched_at", "updated_at",
+		}),
+	}).Create(record).Error
+}
+
+// CountByStatus 按状态统计 URL 数量
+func (r *CrawlURLRepository) CountByStatus() (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err := r.db.Model(&model.CrawlURL{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		result[row.Status] = row.Count
+	}
+	return result, nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/what-cse/server/internal/model"
+	"gorm.io/gorm"
+)
+
+// FeedTokenRepository 用户订阅源令牌仓库
+type FeedTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewFeedTokenRepository 创建订阅源令牌仓库
+func NewFeedTokenRepository(db *gorm.DB) *FeedTokenRepository {
+	return &FeedTokenRepository{db: db}
+}
+
+// GetByUserID 获取用户的令牌，不存在时返回 nil
+func (r *FeedTokenRepository) GetByUserID(userID uint) (*model.UserFeedToken, error) {
+	var token model.UserFeedToken
+	err := r.db.Where("user_id = ?", userID).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// GetByToken 按令牌查找，不存在时返回 nil
+func (r *FeedTokenRepository) GetByToken(token string) (*model.UserFeedToken, error) {
+	var feedToken model.UserFeedToken
+	err := r.db.Where("token = ?", token).First(&feedToken).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &feedToken, nil
+}
+
+// Create 创建令牌
+func (r *FeedTokenRepository) Create(token *model.UserFeedToken) error {
+	return r.db.Create(token).Error
+}
+
+// UpdateToken 更换令牌，旧的订阅地址随即失效
+func (r *FeedTokenRepository) UpdateToken(id uint, token string) error {
+	return r.db.Model(&model.UserFeedToken{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"token": token, "last_used_at": nil}).Error
+}
+
+// Touch 记录阅读器拉取时间
+func (r *FeedTokenRepository) Touch(id uint, at time.Time) error {
+	return r.db.Model(&model.UserFeedToken{}).Where("id = ?", id).UpdateColumn("last_used_at", at).Error
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/what-cse/server/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IRTResponse 参与标定的一次首次作答
+type IRTResponse struct {
+	UserID     uint
+	QuestionID uint
+	CategoryID uint
+	Difficulty int // 人工难度 1-5，作为未标定题目的先验
+	IsCorrect  bool
+}
+
+// IRTCandidate 自适应选题候选，未标定题目的参数由人工难度换算
+type IRTCandidate struct {
+	QuestionID     uint
+	Discrimination float64
+	Difficulty     float64
+	Calibrated     bool
+}
+
+// IRTRepository 题目 IRT 参数与用户能力仓库
+type IRTRepository struct {
+	db *gorm.DB
+}
+
+// NewIRTRepository 创建 IRT 仓库
+func NewIRTRepository(db *gorm.DB) *IRTRepository {
+	return &IRTRepository{db: db}
+}
+
+// LoadResponses 加载时间范围内每个用户对每道题的首次作答，按时间倒序截取 limit 条
+func (r *IRTRepository) LoadResponses(since time.Time, limit int) ([]IRTResponse, error) {
+	var responses []IRTResponse
+	firsts := r.db.Model(&model.UserQuestionRecord{}).
+		Select("MIN(id) AS id").
+		Where("created_at >= ?", since).
+		Group("user_id, question_id")
+
+	err := r.db.Table("what_user_question_records AS r").
+		Select("r.user_id, r.question_id, q.category_id, q.difficulty, r.is_correct").
+		Joins("JOIN (?) AS f ON f.id = r.id", firsts).
+		Joins("JOIN what_questions AS q ON q.id = r.question_id AND q.deleted_at IS NULL").
+		Order("r.id DESC").
+		Limit(limit).
+		Scan(&responses).Error
+	return responses, err
+}
+
+// GetParams 获取题目 IRT 参数
+func (r *IRTRepository) GetParams(questionIDs []uint) (map[uint]model.QuestionIRTParam, error) {
+	result := make(map[uint]model.QuestionIRTParam, len(questionIDs))
+	if len(questionIDs) == 0 {
+		return result, nil
+	}
+	var params []model.QuestionIRTParam
+	if err := r.db.Where("question_id IN ?", questionIDs).Find(&params).Error; err != nil {
+		return nil, err
+	}
+	for _, p := range params {
+		result[p.QuestionID] = p
+	}
+	return result, nil
+}
+
+// SaveParams 批量写入标定结果
+func (r *IRTRepository) SaveParams(params []model.QuestionIRTParam) error {
+	if len(params) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "question_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"discrimination", "difficulty", "difficulty_se", "response_count", "correct_rate", "calibrated_at", "updated_at",
+		}),
+	}).CreateInBatches(params, 500).Error
+}
+
+// CountParams 已标定题目数
+func (r *IRTRepository) CountParams() (int64, error) {
+	var count int64
+	err := r.db.Model(&model.QuestionIRTParam{}).Count(&count).Error
+	return count, err
+}
+
+// GetAbility 获取用户在某分类（0 为总体）的能力估计，不存在时返回 nil
+func (r *IRTRepository) GetAbility(userID, categoryID uint) (*model.UserAbility, error) {
+	var ability model.UserAbility
+	err := r.db.Where("user_id = ? AND category_id = ?", userID, categoryID).First(&ability).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ability, nil
+}
+
+// GetUserAbilities 获取用户全部能力估计
+func (r *IRTRepository) GetUserAbilities(userID uint) ([]model.UserAbility, error) {
+	var abilities []model.UserAbility
+	err := r.db.Where("user_id = ?", userID).Order("category_id ASC").Find(&abilities).Error
+	return abilities, err
+}
+
+// SaveAbilities 批量写入能力估计
+func (r *IRTRepository) SaveAbilities(abilities []model.UserAbility) error {
+	if len(abilities) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "category_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"theta", "information", "response_count", "updated_at"}),
+	}).CreateInBatches(abilities, 500).Error
+}
+
+// FindCandidates 按目标难度查找最接近的已发布题目。
+// targetOffset 为 ln(p/(1-p))，理想难度 b* = θ - targetOffset/a；
+// 未标定题目按人工难度换算 b = (difficulty-3)*priorScale，a = 1；未设置难度（0）按 3 处理，与 irtPriorDifficulty 一致
+func (r *IRTRepository) FindCandidates(categoryIDs []uint, theta, targetOffset, priorScale float64, excludeIDs []uint, limit int) ([]IRTCandidate, error) {
+	var candidates []IRTCandidate
+	query := r.db.Table("what_questions AS q").
+		Select(`q.id AS question_id,
+			COALESCE(p.discrimination, 1) AS discrimination,
+			COALESCE(p.difficulty, (CASE WHEN q.difficulty > 0 THEN q.difficulty - 3 ELSE 0 END) * ?) AS difficulty,
+			p.id IS NOT NULL AS calibrated`, priorScale).
+		Joins("LEFT JOIN what_question_irt_params AS p ON p.question_id = q.id").
+		Where("q.deleted_at IS NULL AND q.status = ?", model.QuestionStatusPublished)
+	if len(categoryIDs) > 0 {
+		query = query.Where("q.category_id IN ?", categoryIDs)
+	}
+	if len(excludeIDs) > 0 {
+		query = query.Where("q.id NOT IN ?", excludeIDs)
+	}
+	err := query.
+		Order(clause.Expr{
+			SQL:  "ABS(COALESCE(p.difficulty, (CASE WHEN q.difficulty > 0 THEN q.difficulty - 3 ELSE 0 END) * ?) - (? - ? / COALESCE(p.discrimination, 1)))",
+			Vars: []interface{}{priorScale, theta, targetOffset},
+		}).
+		Limit(limit).
+		Scan(&candidates).Error
+	return candidates, err
+}
@@ -153,10 +153,20 @@ func (r *FlashCardRepository) GetByID(id uint) (*model.KnowledgeFlashCard, error
 	return &card, nil
 }
 
-// GetByKnowledgePoint 获取知识点的卡片
+// GetVisibleByID 获取用户可见的卡片：公共卡片或该用户自己导入的卡片，userID 为 0 时只返回公共卡片
+func (r *FlashCardRepository) GetVisibleByID(id, userID uint) (*model.KnowledgeFlashCard, error) {
+	var card model.KnowledgeFlashCard
+	err := r.db.Where("owner_id IS NULL OR owner_id = ?", userID).First(&card, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &card, nil
+}
+
+// GetByKnowledgePoint 获取知识点的公共卡片
 func (r *FlashCardRepository) GetByKnowledgePoint(knowledgePointID uint) ([]*model.KnowledgeFlashCard, error) {
 	var cards []*model.KnowledgeFlashCard
-	err := r.db.Where("knowledge_point_id = ? AND is_active = ?", knowledgePointID, true).
+	err := r.db.Where("knowledge_point_id = ? AND is_active = ? AND owner_id IS NULL", knowledgePointID, true).
 		Order("sort_order").
 		Find(&cards).Error
 	return cards, err
@@ -165,7 +175,7 @@ func (r *FlashCardRepository) GetByKnowledgePoint(knowledgePointID uint) ([]*mod
 // GetByCategory 获取分类下的卡片
 func (r *FlashCardRepository) GetByCategory(categoryID uint) ([]*model.KnowledgeFlashCard, error) {
 	var cards []*model.KnowledgeFlashCard
-	err := r.db.Where("category_id = ? AND is_active = ?", categoryID, true).
+	err := r.db.Where("category_id = ? AND is_active = ? AND owner_id IS NULL", categoryID, true).
 		Order("sort_order").
 		Find(&cards).Error
 	return cards, err
@@ -174,7 +184,7 @@ func (r *FlashCardRepository) GetByCategory(categoryID uint) ([]*model.Knowledge
 // GetByType 获取指定类型的卡片
 func (r *FlashCardRepository) GetByType(cardType model.FlashCardType, limit int) ([]*model.KnowledgeFlashCard, error) {
 	var cards []*model.KnowledgeFlashCard
-	query := r.db.Where("card_type = ? AND is_active = ?", cardType, true).
+	query := r.db.Where("card_type = ? AND is_active = ? AND owner_id IS NULL", cardType, true).
 		Order("importance DESC, sort_order")
 	if limit > 0 {
 		query = query.Limit(limit)
@@ -188,7 +198,8 @@ func (r *FlashCardRepository) List(params *FlashCardQueryParams) ([]*model.Knowl
 	var cards []*model.KnowledgeFlashCard
 	var total int64
 
-	query := r.db.Model(&model.KnowledgeFlashCard{})
+	// 用户导入的私有卡片不出现在公共卡片列表中
+	query := r.db.Model(&model.KnowledgeFlashCard{}).Where("owner_id IS NULL")
 
 	if params.KnowledgePointID > 0 {
 		query = query.Where("knowledge_point_id = ?", params.KnowledgePointID)
@@ -231,7 +242,7 @@ func (r *FlashCardRepository) List(params *FlashCardQueryParams) ([]*model.Knowl
 // GetRandomCards 随机获取卡片
 func (r *FlashCardRepository) GetRandomCards(cardType model.FlashCardType, count int) ([]*model.KnowledgeFlashCard, error) {
 	var cards []*model.KnowledgeFlashCard
-	query := r.db.Where("is_active = ?", true)
+	query := r.db.Where("is_active = ? AND owner_id IS NULL", true)
 	if cardType != "" {
 		query = query.Where("card_type = ?", cardType)
 	}
@@ -262,12 +273,42 @@ func (r *FlashCardRepository) GetTypeStats() ([]model.FlashCardTypeStats, error)
 	var stats []model.FlashCardTypeStats
 	err := r.db.Model(&model.KnowledgeFlashCard{}).
 		Select("card_type, COUNT(*) as count, SUM(view_count) as view_sum").
-		Where("is_active = ?", true).
+		Where("is_active = ? AND owner_id IS NULL", true).
 		Group("card_type").
 		Find(&stats).Error
 	return stats, err
 }
 
+// ListForUser 获取用户可见的卡片（公共卡片与自己导入的卡片），用于导出
+func (r *FlashCardRepository) ListForUser(userID uint, cardType model.FlashCardType, categoryID uint, limit int) ([]*model.KnowledgeFlashCard, error) {
+	var cards []*model.KnowledgeFlashCard
+	query := r.db.Where("is_active = ? AND (owner_id IS NULL OR owner_id = ?)", true, userID)
+	if cardType != "" {
+		query = query.Where("card_type = ?", cardType)
+	}
+	if categoryID > 0 {
+		query = query.Where("category_id = ?", categoryID)
+	}
+	err := query.Order("card_type, sort_order, id").Limit(limit).Find(&cards).Error
+	return cards, err
+}
+
+// GetOwnerExternalIDs 获取用户已导入卡片的来源标识
+func (r *FlashCardRepository) GetOwnerExternalIDs(ownerID uint) (map[string]bool, error) {
+	var ids []string
+	err := r.db.Model(&model.KnowledgeFlashCard{}).
+		Where("owner_id = ? AND external_id <> ''", ownerID).
+		Pluck("external_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		result[id] = true
+	}
+	return result, nil
+}
+
 // FlashCardQueryParams 查询参数
 type FlashCardQueryParams struct {
 	KnowledgePointID uint
@@ -464,6 +505,30 @@ func (r *UserFlashCardRecordRepository) GetByUserAndCard(userID, cardID uint) (*
 	return &record, nil
 }
 
+// GetByUserAndCards 批量获取用户的卡片记录，键为卡片ID
+func (r *UserFlashCardRecordRepository) GetByUserAndCards(userID uint, cardIDs []uint) (map[uint]*model.UserFlashCardRecord, error) {
+	result := make(map[uint]*model.UserFlashCardRecord, len(cardIDs))
+	if len(cardIDs) == 0 {
+		return result, nil
+	}
+	var records []*model.UserFlashCardRecord
+	if err := r.db.Where("user_id = ? AND card_id IN ?", userID, cardIDs).Find(&records).Error; err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		result[record.CardID] = record
+	}
+	return result, nil
+}
+
+// BatchCreate 批量创建记录
+func (r *UserFlashCardRecordRepository) BatchCreate(records []*model.UserFlashCardRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(records, 500).Error
+}
+
 // GetUserCards 获取用户的卡片学习记录
 func (r *UserFlashCardRecordRepository) GetUserCards(userID uint, status model.UserFlashCardStatus, limit int) ([]*model.UserFlashCardRecord, error) {
 	var records []*model.UserFlashCardRecord
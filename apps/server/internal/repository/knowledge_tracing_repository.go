@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/what-cse/server/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// KnowledgeObservation 一次带知识点的作答，用于拟合 BKT 参数和回放掌握度
+type KnowledgeObservation struct {
+	ID              uint
+	UserID          uint
+	QuestionID      uint
+	IsCorrect       bool
+	CreatedAt       time.Time
+	QuestionType    model.QuestionType
+	OptionCount     int
+	KnowledgePoints model.JSONIntArray
+}
+
+// KnowledgeTracingRepository 知识追踪参数仓库
+type KnowledgeTracingRepository struct {
+	db *gorm.DB
+}
+
+// NewKnowledgeTracingRepository 创建知识追踪参数仓库
+func NewKnowledgeTracingRepository(db *gorm.DB) *KnowledgeTracingRepository {
+	return &KnowledgeTracingRepository{db: db}
+}
+
+// LoadObservations 按记录ID顺序加载 afterID 之后关联了知识点的作答
+func (r *KnowledgeTracingRepository) LoadObservations(afterID uint, limit int) ([]KnowledgeObservation, error) {
+	var observations []KnowledgeObservation
+	err := r.db.Table("what_user_question_records AS r").
+		Select(`r.id, r.user_id, r.question_id, r.is_correct, r.created_at,
+			q.question_type, COALESCE(JSON_LENGTH(q.options), 0) AS option_count, q.knowledge_points`).
+		Joins("JOIN what_questions AS q ON q.id = r.question_id").
+		Where("r.id > ? AND q.knowledge_points IS NOT NULL AND JSON_LENGTH(q.knowledge_points) > 0", afterID).
+		Order("r.id ASC").
+		Limit(limit).
+		Scan(&observations).Error
+	return observations, err
+}
+
+// GetParams 获取知识点 BKT 参数
+func (r *KnowledgeTracingRepository) GetParams(knowledgePointIDs []uint) (map[uint]model.KnowledgeTracingParam, error) {
+	result := make(map[uint]model.KnowledgeTracingParam, len(knowledgePointIDs))
+	if len(knowledgePointIDs) == 0 {
+		return result, nil
+	}
+	var params []model.KnowledgeTracingParam
+	if err := r.db.Where("knowledge_point_id IN ?", knowledgePointIDs).Find(&params).Error; err != nil {
+		return nil, err
+	}
+	for _, p := range params {
+		result[p.KnowledgePointID] = p
+	}
+	return result, nil
+}
+
+// GetAllParams 获取全部已拟合的知识点参数
+func (r *KnowledgeTracingRepository) GetAllParams() (map[uint]model.KnowledgeTracingParam, error) {
+	var params []model.KnowledgeTracingParam
+	if err := r.db.Find(&params).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[uint]model.KnowledgeTracingParam, len(params))
+	for _, p := range params {
+		result[p.KnowledgePointID] = p
+	}
+	return result, nil
+}
+
+// SaveParams 批量写入拟合结果
+func (r *KnowledgeTracingRepository) SaveParams(params []model.KnowledgeTracingParam) error {
+	if len(params) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "knowledge_point_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"p_init", "p_learn", "p_guess", "p_slip", "response_count", "sequence_count", "log_likelihood", "fitted_at", "updated_at",
+		}),
+	}).CreateInBatches(params, 500).Error
+}
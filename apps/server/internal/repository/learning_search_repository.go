@@ -0,0 +1,259 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/what-cse/server/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LearningSearchRepository 学习内容搜索仓库
+type LearningSearchRepository struct {
+	db *gorm.DB
+}
+
+// NewLearningSearchRepository 创建学习内容搜索仓库
+func NewLearningSearchRepository(db *gorm.DB) *LearningSearchRepository {
+	return &LearningSearchRepository{db: db}
+}
+
+// LearningSearchParams 学习内容搜索参数
+type LearningSearchParams struct {
+	Keyword    string
+	Types      []string
+	Subject    string
+	ExamType   string
+	ExcludeVIP bool
+	Page       int
+	PageSize   int
+}
+
+// LearningSearchRow 搜索结果行
+type LearningSearchRow struct {
+	model.LearningSearchDoc
+	Relevance float64 `gorm:"column:relevance" json:"relevance"`
+}
+
+// =====================================================
+// 源数据扫描
+// =====================================================
+
+// ScanChanged 按主键游标扫描 [since, until) 内更新或删除的源数据（包含软删除记录）
+func ScanChanged[T any](r *LearningSearchRepository, since, until time.Time, afterID uint, limit int) ([]T, error) {
+	var rows []T
+	err := r.db.Unscoped().
+		Where("(updated_at >= ? AND updated_at < ?) OR (deleted_at >= ? AND deleted_at < ?)", since, until, since, until).
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&rows).Error
+	return rows, err
+}
+
+// GetAllCategories 获取全部课程分类（用于解析科目和考试类型）
+func (r *LearningSearchRepository) GetAllCategories() ([]model.CourseCategory, error) {
+	var categories []model.CourseCategory
+	err := r.db.Find(&categories).Error
+	return categories, err
+}
+
+// GetCoursesByIDs 按ID获取课程（包含软删除）
+func (r *LearningSearchRepository) GetCoursesByIDs(ids []uint) ([]model.Course, error) {
+	var courses []model.Course
+	if len(ids) == 0 {
+		return courses, nil
+	}
+	err := r.db.Unscoped().Where("id IN ?", ids).Find(&courses).Error
+	return courses, err
+}
+
+// GetChaptersByCourseIDs 获取课程下全部章节（包含软删除）
+func (r *LearningSearchRepository) GetChaptersByCourseIDs(courseIDs []uint) ([]model.CourseChapter, error) {
+	var chapters []model.CourseChapter
+	if len(courseIDs) == 0 {
+		return chapters, nil
+	}
+	err := r.db.Unscoped().Where("course_id IN ?", courseIDs).Find(&chapters).Error
+	return chapters, err
+}
+
+// GetKnowledgePointsByIDs 按ID获取知识点（包含软删除）
+func (r *LearningSearchRepository) GetKnowledgePointsByIDs(ids []uint) ([]model.KnowledgePoint, error) {
+	var points []model.KnowledgePoint
+	if len(ids) == 0 {
+		return points, nil
+	}
+	err := r.db.Unscoped().Where("id IN ?", ids).Find(&points).Error
+	return points, err
+}
+
+// GetDetailsByKnowledgePointIDs 获取知识点下全部详情（包含软删除）
+func (r *LearningSearchRepository) GetDetailsByKnowledgePointIDs(pointIDs []uint) ([]model.KnowledgeDetail, error) {
+	var details []model.KnowledgeDetail
+	if len(pointIDs) == 0 {
+		return details, nil
+	}
+	err := r.db.Unscoped().Where("knowledge_point_id IN ?", pointIDs).Find(&details).Error
+	return details, err
+}
+
+// =====================================================
+// 文档写入
+// =====================================================
+
+// UpsertDocs 批量写入文档，(doc_type, source_id) 冲突时覆盖
+func (r *LearningSearchRepository) UpsertDocs(docs []model.LearningSearchDoc) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "doc_type"}, {Name: "source_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"parent_id", "title", "content", "subject", "exam_type", "category_id",
+			"tags", "is_vip", "popularity", "source_updated_at", "updated_at",
+		}),
+	}).CreateInBatches(docs, 200).Error
+}
+
+// DeleteDocs 删除指定来源的文档
+func (r *LearningSearchRepository) DeleteDocs(docType string, sourceIDs []uint) error {
+	if len(sourceIDs) == 0 {
+		return nil
+	}
+	return r.db.Where("doc_type = ? AND source_id IN ?", docType, sourceIDs).
+		Delete(&model.LearningSearchDoc{}).Error
+}
+
+// DeleteAllDocs 清空全部文档和同步水位
+func (r *LearningSearchRepository) DeleteAllDocs() error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&model.LearningSearchDoc{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("1 = 1").Delete(&model.LearningSearchSyncState{}).Error
+	})
+}
+
+// =====================================================
+// 同步水位
+// =====================================================
+
+// GetState 获取文档类型的同步水位，不存在时返回零值
+func (r *LearningSearchRepository) GetState(docType string) (*model.LearningSearchSyncState, error) {
+	var state model.LearningSearchSyncState
+	err := r.db.Where("doc_type = ?", docType).First(&state).Error
+	if err == gorm.ErrRecordNotFound {
+		return &model.LearningSearchSyncState{DocType: docType}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// SaveState 保存同步水位
+func (r *LearningSearchRepository) SaveState(state *model.LearningSearchSyncState) error {
+	return r.db.Save(state).Error
+}
+
+// ListStates 获取全部同步水位
+func (r *LearningSearchRepository) ListStates() ([]model.LearningSearchSyncState, error) {
+	var states []model.LearningSearchSyncState
+	err := r.db.Order("doc_type ASC").Find(&states).Error
+	return states, err
+}
+
+// =====================================================
+// 检索
+// =====================================================
+
+// Search 在 ft_learning_search 全文索引上检索
+func (r *LearningSearchRepository) Search(params *LearningSearchParams) ([]LearningSearchRow, int64, error) {
+	var rows []LearningSearchRow
+	var total int64
+
+	query, against := r.searchQuery(params)
+	if len(params.Types) > 0 {
+		query = query.Where("doc_type IN ?", params.Types)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if against != "" {
+		// 标题命中加权
+		query = query.Select("what_learning_search_docs.*, MATCH(title, content) AGAINST (? IN BOOLEAN MODE) + MATCH(title) AGAINST (? IN BOOLEAN MODE) * 2 AS relevance",
+			against, against).
+			Order("relevance DESC")
+	} else {
+		query = query.Select("what_learning_search_docs.*, 0 AS relevance")
+	}
+	query = query.Order("popularity DESC").Order("id DESC")
+
+	page, pageSize := params.Page, params.PageSize
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	if err := query.Offset((page - 1) * pageSize).Limit(pageSize).Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return rows, total, nil
+}
+
+// CountByType 按文档类型统计命中数（不应用类型筛选，用于分面）
+func (r *LearningSearchRepository) CountByType(params *LearningSearchParams) (map[string]int64, error) {
+	var results []struct {
+		DocType string
+		Count   int64
+	}
+
+	query, _ := r.searchQuery(params)
+	err := query.Select("doc_type, COUNT(*) as count").Group("doc_type").Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(results))
+	for _, row := range results {
+		counts[row.DocType] = row.Count
+	}
+	return counts, nil
+}
+
+// CountDocs 按文档类型统计已索引文档数
+func (r *LearningSearchRepository) CountDocs() (map[string]int64, error) {
+	return r.CountByType(&LearningSearchParams{})
+}
+
+// searchQuery 构建除类型筛选外的查询条件
+func (r *LearningSearchRepository) searchQuery(params *LearningSearchParams) (*gorm.DB, string) {
+	query := r.db.Model(&model.LearningSearchDoc{})
+
+	against, shortTerms := buildFullTextAgainst(params.Keyword)
+	if against != "" {
+		query = query.Where("MATCH(title, content) AGAINST (? IN BOOLEAN MODE)", against)
+	}
+	for _, term := range shortTerms {
+		like := "%" + escapeLike(term) + "%"
+		query = query.Where("title LIKE ? OR content LIKE ?", like, like)
+	}
+
+	if params.Subject != "" {
+		query = query.Where("subject = ?", params.Subject)
+	}
+	if params.ExamType != "" {
+		query = query.Where("exam_type = ?", params.ExamType)
+	}
+	if params.ExcludeVIP {
+		query = query.Where("is_vip = ?", false)
+	}
+
+	return query, against
+}
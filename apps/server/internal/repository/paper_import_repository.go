@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"github.com/what-cse/server/internal/model"
+	"gorm.io/gorm"
+)
+
+// PaperImportRepository 试卷导入记录仓库
+type PaperImportRepository struct {
+	db *gorm.DB
+}
+
+// NewPaperImportRepository 创建试卷导入记录仓库
+func NewPaperImportRepository(db *gorm.DB) *PaperImportRepository {
+	return &PaperImportRepository{db: db}
+}
+
+// GetByID 获取导入记录
+func (r *PaperImportRepository) GetByID(id uint) (*model.PaperImport, error) {
+	var record model.PaperImport
+	if err := r.db.First(&record, id).Error; err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// List 分页获取导入记录
+func (r *PaperImportRepository) List(status string, page, pageSize int) ([]model.PaperImport, int64, error) {
+	var records []model.PaperImport
+	var total int64
+
+	query := r.db.Model(&model.PaperImport{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	err := query.Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&records).Error
+	return records, total, err
+}
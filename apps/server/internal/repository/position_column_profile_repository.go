@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/what-cse/server/internal/model"
+	"gorm.io/gorm"
+)
+
+// PositionColumnProfileRepository 职位表列映射方案仓库
+type PositionColumnProfileRepository struct {
+	db *gorm.DB
+}
+
+// NewPositionColumnProfileRepository 创建职位表列映射方案仓库
+func NewPositionColumnProfileRepository(db *gorm.DB) *PositionColumnProfileRepository {
+	return &PositionColumnProfileRepository{db: db}
+}
+
+// Create 创建方案
+func (r *PositionColumnProfileRepository) Create(profile *model.PositionColumnProfile) error {
+	return r.db.Create(profile).Error
+}
+
+// Update 保存方案
+func (r *PositionColumnProfileRepository) Update(profile *model.PositionColumnProfile) error {
+	return r.db.Save(profile).Error
+}
+
+// Delete 删除方案
+func (r *PositionColumnProfileRepository) Delete(id uint) error {
+	return r.db.Delete(&model.PositionColumnProfile{}, id).Error
+}
+
+// GetByID 获取方案，不存在时返回 nil
+func (r *PositionColumnProfileRepository) GetByID(id uint) (*model.PositionColumnProfile, error) {
+	var profile model.PositionColumnProfile
+	err := r.db.First(&profile, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// List 获取全部方案
+func (r *PositionColumnProfileRepository) List() ([]model.PositionColumnProfile, error) {
+	var profiles []model.PositionColumnProfile
+	err := r.db.Order("priority DESC, id DESC").Find(&profiles).Error
+	return profiles, err
+}
+
+// ListActive 获取启用的方案
+func (r *PositionColumnProfileRepository) ListActive() ([]model.PositionColumnProfile, error) {
+	var profiles []model.PositionColumnProfile
+	err := r.db.Where("is_active = ?", true).Order("priority DESC, id DESC").Find(&profiles).Error
+	return profiles, err
+}
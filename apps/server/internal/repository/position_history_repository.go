@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"errors"
 	"time"
 
 	"github.com/what-cse/server/internal/model"
@@ -322,6 +323,23 @@ func (r *PositionHistoryRepository) GetHistoricalScores(positionCode string, lim
 	return scores, years, nil
 }
 
+// GetScoredHistories 获取有进面分数线的历年数据（用于分数线预测建模）
+// examType 为空时返回全部考试类型
+func (r *PositionHistoryRepository) GetScoredHistories(examType string) ([]*model.PositionHistory, error) {
+	var histories []*model.PositionHistory
+
+	query := r.db.Model(&model.PositionHistory{}).
+		Select("id, position_code, department_code, department_level, year, recruit_count, interview_score, exam_type, exam_category, province").
+		Where("interview_score > 0")
+
+	if examType != "" {
+		query = query.Where("exam_type = ?", examType)
+	}
+
+	err := query.Order("year ASC").Find(&histories).Error
+	return histories, err
+}
+
 // GetAggregatedStats 获取聚合统计
 func (r *PositionHistoryRepository) GetAggregatedStats(examType, province string) (*model.AggregatedHistoryStats, error) {
 	var stats model.AggregatedHistoryStats
@@ -464,26 +482,39 @@ func (r *PositionHistoryRepository) GetStatsByExamType() ([]ExamTypeHistoryStats
 // 存在性检查
 // =====================================================
 
-// Exists 检查记录是否存在
-func (r *PositionHistoryRepository) Exists(positionCode string, year int) (bool, error) {
+// Exists 检查记录是否存在，同一职位代码在不同省份、考试类型下是不同职位
+func (r *PositionHistoryRepository) Exists(positionCode string, year int, province, examType string) (bool, error) {
 	var count int64
 	err := r.db.Model(&model.PositionHistory{}).
-		Where("position_code = ? AND year = ?", positionCode, year).
+		Where("position_code = ? AND year = ? AND province = ? AND exam_type = ?", positionCode, year, province, examType).
 		Count(&count).Error
 	return count > 0, err
 }
 
+// GetByCodeAndYear 按职位代码、年份、省份和考试类型获取记录，不存在时返回 nil
+func (r *PositionHistoryRepository) GetByCodeAndYear(positionCode string, year int, province, examType string) (*model.PositionHistory, error) {
+	var history model.PositionHistory
+	err := r.db.Where("position_code = ? AND year = ? AND province = ? AND exam_type = ?", positionCode, year, province, examType).First(&history).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
+
 // BatchUpsert 批量更新或插入
 func (r *PositionHistoryRepository) BatchUpsert(histories []*model.PositionHistory) error {
 	for _, h := range histories {
-		exists, err := r.Exists(h.PositionCode, h.Year)
+		exists, err := r.Exists(h.PositionCode, h.Year, h.Province, h.ExamType)
 		if err != nil {
 			return err
 		}
 		if exists {
 			// 更新现有记录
 			if err := r.db.Model(&model.PositionHistory{}).
-				Where("position_code = ? AND year = ?", h.PositionCode, h.Year).
+				Where("position_code = ? AND year = ? AND province = ? AND exam_type = ?", h.PositionCode, h.Year, h.Province, h.ExamType).
 				Updates(h).Error; err != nil {
 				return err
 			}
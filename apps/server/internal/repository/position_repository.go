@@ -1,7 +1,9 @@
 package repository
 
 import (
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/what-cse/server/internal/model"
 	"gorm.io/gorm"
@@ -122,7 +124,12 @@ type TrendItem struct {
 }
 
 func (r *PositionRepository) Create(position *model.Position) error {
-	return r.db.Create(position).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(position).Error; err != nil {
+			return err
+		}
+		return enqueueSearchSync(tx, model.SearchEntityPosition, position.ID)
+	})
 }
 
 func (r *PositionRepository) FindByID(id uint) (*model.Position, error) {
@@ -143,12 +150,55 @@ func (r *PositionRepository) FindByPositionID(positionID string) (*model.Positio
 	return &position, nil
 }
 
+// ExistingPositionIDs 返回已存在的职位唯一标识
+func (r *PositionRepository) ExistingPositionIDs(positionIDs []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	if len(positionIDs) == 0 {
+		return existing, nil
+	}
+	var ids []string
+	if err := r.db.Model(&model.Position{}).Where("position_id IN ?", positionIDs).Pluck("position_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		existing[id] = true
+	}
+	return existing, nil
+}
+
 func (r *PositionRepository) Update(position *model.Position) error {
-	return r.db.Save(position).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(position).Error; err != nil {
+			return err
+		}
+		return enqueueSearchSync(tx, model.SearchEntityPosition, position.ID)
+	})
+}
+
+// UpdateColumnsByPositionID 按职位唯一标识只更新指定列，未选中的列（如审核状态）保持不变
+func (r *PositionRepository) UpdateColumnsByPositionID(position *model.Position, columns []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+		if err := tx.Model(&model.Position{}).Where("position_id = ?", position.PositionID).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := tx.Model(&model.Position{}).Where("id IN ?", ids).Select(columns).Updates(position).Error; err != nil {
+			return err
+		}
+		return enqueueSearchSync(tx, model.SearchEntityPosition, ids...)
+	})
 }
 
 func (r *PositionRepository) Delete(id uint) error {
-	return r.db.Delete(&model.Position{}, id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&model.Position{}, id).Error; err != nil {
+			return err
+		}
+		return enqueueSearchSync(tx, model.SearchEntityPosition, id)
+	})
 }
 
 func (r *PositionRepository) List(filter *PositionFilter, sort *PositionSort, page, pageSize int) ([]model.Position, int64, error) {
@@ -234,11 +284,25 @@ func (r *PositionRepository) FindWithAnnouncements(id uint) (*model.Position, er
 }
 
 func (r *PositionRepository) UpdateStatus(id uint, status int) error {
-	return r.db.Model(&model.Position{}).Where("id = ?", id).Update("status", status).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Position{}).Where("id = ?", id).Update("status", status).Error; err != nil {
+			return err
+		}
+		return enqueueSearchSync(tx, model.SearchEntityPosition, id)
+	})
 }
 
 func (r *PositionRepository) BatchCreate(positions []model.Position) error {
-	return r.db.CreateInBatches(positions, 100).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(positions, 100).Error; err != nil {
+			return err
+		}
+		ids := make([]uint, 0, len(positions))
+		for _, p := range positions {
+			ids = append(ids, p.ID)
+		}
+		return enqueueSearchSync(tx, model.SearchEntityPosition, ids...)
+	})
 }
 
 func (r *PositionRepository) GetStatsByExamType() (map[string]int64, error) {
@@ -347,8 +411,31 @@ func (r *PositionRepository) ListWithParams(params *PositionQueryParams) ([]mode
 	var positions []model.Position
 	var total int64
 
-	query := r.db.Model(&model.Position{})
+	query := applyPositionQueryFilters(r.db.Model(&model.Position{}), params)
+
+	// 关键词搜索
+	if params.Keyword != "" {
+		keyword := "%" + params.Keyword + "%"
+		query = query.Where("position_name LIKE ? OR department_name LIKE ?", keyword, keyword)
+	}
+
+	// 计数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = query.Order(positionSortClause(params))
+
+	offset, limit := positionPage(params)
+	if err := query.Offset(offset).Limit(limit).Find(&positions).Error; err != nil {
+		return nil, 0, err
+	}
 
+	return positions, total, nil
+}
+
+// applyPositionQueryFilters 应用除关键词外的筛选条件
+func applyPositionQueryFilters(query *gorm.DB, params *PositionQueryParams) *gorm.DB {
 	// 地域筛选
 	if params.Province != "" {
 		query = query.Where("province = ?", params.Province)
@@ -441,11 +528,6 @@ func (r *PositionRepository) ListWithParams(params *PositionQueryParams) ([]mode
 		query = query.Where("created_at >= ?", todayStart)
 	}
 
-	// 关键词搜索
-	if params.Keyword != "" {
-		keyword := "%" + params.Keyword + "%"
-		query = query.Where("position_name LIKE ? OR department_name LIKE ?", keyword, keyword)
-	}
 	if params.DepartmentKeyword != "" {
 		query = query.Where("department_name LIKE ?", "%"+params.DepartmentKeyword+"%")
 	}
@@ -457,12 +539,11 @@ func (r *PositionRepository) ListWithParams(params *PositionQueryParams) ([]mode
 		query = query.Where("status = ?", model.PositionStatusPublished)
 	}
 
-	// 计数
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
+	return query
+}
 
-	// 排序
+// positionSortClause 生成排序子句
+func positionSortClause(params *PositionQueryParams) string {
 	sortField := "created_at"
 	sortOrder := "DESC"
 	if params.SortBy != "" {
@@ -474,9 +555,11 @@ func (r *PositionRepository) ListWithParams(params *PositionQueryParams) ([]mode
 	if params.SortOrder == "asc" {
 		sortOrder = "ASC"
 	}
-	query = query.Order(sortField + " " + sortOrder)
+	return sortField + " " + sortOrder
+}
 
-	// 分页
+// positionPage 计算分页偏移和条数
+func positionPage(params *PositionQueryParams) (offset, limit int) {
 	page := params.Page
 	pageSize := params.PageSize
 	if page <= 0 {
@@ -485,13 +568,7 @@ func (r *PositionRepository) ListWithParams(params *PositionQueryParams) ([]mode
 	if pageSize <= 0 || pageSize > 100 {
 		pageSize = 20
 	}
-	offset := (page - 1) * pageSize
-
-	if err := query.Offset(offset).Limit(pageSize).Find(&positions).Error; err != nil {
-		return nil, 0, err
-	}
-
-	return positions, total, nil
+	return (page - 1) * pageSize, pageSize
 }
 
 // GetByAnnouncementID 根据公告ID获取职位
@@ -517,17 +594,34 @@ func (r *PositionRepository) GetByPositionCode(code string) ([]model.Position, e
 
 // SoftDelete 软删除
 func (r *PositionRepository) SoftDelete(id uint) error {
-	return r.db.Delete(&model.Position{}, id).Error
+	return r.Delete(id)
 }
 
 // BatchUpdate 批量更新
 func (r *PositionRepository) BatchUpdate(ids []uint, updates map[string]interface{}) error {
-	return r.db.Model(&model.Position{}).Where("id IN ?", ids).Updates(updates).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Position{}).Where("id IN ?", ids).Updates(updates).Error; err != nil {
+			return err
+		}
+		return enqueueSearchSync(tx, model.SearchEntityPosition, ids...)
+	})
 }
 
 // DeleteByAnnouncementID 根据公告ID删除职位
 func (r *PositionRepository) DeleteByAnnouncementID(announcementID uint) error {
-	return r.db.Where("announcement_id = ?", announcementID).Delete(&model.Position{}).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+		if err := tx.Model(&model.Position{}).Where("announcement_id = ?", announcementID).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := tx.Where("id IN ?", ids).Delete(&model.Position{}).Error; err != nil {
+			return err
+		}
+		return enqueueSearchSync(tx, model.SearchEntityPosition, ids...)
+	})
 }
 
 // =====================================================
@@ -854,3 +948,165 @@ func (r *PositionRepository) GetSimilarPositions(position *model.Position, limit
 
 	return positions, err
 }
+
+// GetRegisteringPositions 获取正在报名中的已发布职位
+func (r *PositionRepository) GetRegisteringPositions() ([]model.Position, error) {
+	var positions []model.Position
+	now := time.Now()
+
+	err := r.db.Model(&model.Position{}).
+		Where("status = ?", model.PositionStatusPublished).
+		Where("registration_start IS NOT NULL AND registration_start <= ?", now).
+		Where("registration_end IS NOT NULL AND registration_end >= ?", now).
+		Find(&positions).Error
+
+	return positions, err
+}
+
+// GetPastWindowPositions 获取往年报名窗口已结束的同类职位（用于报名曲线参考）
+// departmentCode 非空时按单位筛选，否则按考试类型和省份筛选
+func (r *PositionRepository) GetPastWindowPositions(departmentCode, examType, province string, before time.Time, limit int) ([]model.Position, error) {
+	var positions []model.Position
+
+	query := r.db.Model(&model.Position{}).
+		Where("registration_start IS NOT NULL AND registration_end IS NOT NULL").
+		Where("registration_end < ?", before)
+
+	if departmentCode != "" {
+		query = query.Where("department_code = ?", departmentCode)
+	} else {
+		if examType != "" {
+			query = query.Where("exam_type = ?", examType)
+		}
+		if province != "" {
+			query = query.Where("province = ?", province)
+		}
+	}
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	err := query.Order("registration_end DESC").Find(&positions).Error
+	return positions, err
+}
+
+// FindForIndex 按主键批量获取职位（包含已软删除的记录），用于搜索索引同步
+func (r *PositionRepository) FindForIndex(ids []uint) ([]model.Position, error) {
+	var positions []model.Position
+	err := r.db.Unscoped().Where("id IN ?", ids).Find(&positions).Error
+	return positions, err
+}
+
+// ScanForIndex 按主键游标分批遍历未删除职位，用于全量重建索引
+func (r *PositionRepository) ScanForIndex(afterID uint, limit int) ([]model.Position, error) {
+	var positions []model.Position
+	err := r.db.Where("id > ?", afterID).Order("id ASC").Limit(limit).Find(&positions).Error
+	return positions, err
+}
+
+// GetChangedSince 获取指定时间之后变更过的职位主键（包含软删除）
+func (r *PositionRepository) GetChangedSince(since time.Time) ([]uint, error) {
+	var ids []uint
+	err := r.db.Unscoped().Model(&model.Position{}).
+		Where("updated_at >= ? OR deleted_at >= ?", since, since).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// =====================================================
+// 全文检索（FULLTEXT ngram）
+// =====================================================
+
+// ngramTokenSize 与 MySQL ngram_token_size 默认值一致，短于该长度的词无法命中全文索引
+const ngramTokenSize = 2
+
+// PositionSearchRow 全文检索结果行
+type PositionSearchRow struct {
+	model.Position
+	Relevance float64 `gorm:"column:relevance" json:"relevance"`
+}
+
+// FullTextSearch 使用 ft_position_search 全文索引检索职位，筛选条件与 ListWithParams 一致
+// SortBy 为空或 relevance 且有关键词时按相关度排序
+func (r *PositionRepository) FullTextSearch(params *PositionQueryParams) ([]PositionSearchRow, int64, error) {
+	var rows []PositionSearchRow
+	var total int64
+
+	query := applyPositionQueryFilters(r.db.Model(&model.Position{}), params)
+
+	against, shortTerms := buildFullTextAgainst(params.Keyword)
+	if against != "" {
+		query = query.Where("MATCH(position_name, department_name) AGAINST (? IN BOOLEAN MODE)", against)
+	}
+	for _, term := range shortTerms {
+		like := "%" + escapeLike(term) + "%"
+		query = query.Where("position_name LIKE ? OR department_name LIKE ?", like, like)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if against != "" {
+		query = query.Select("what_positions.*, MATCH(position_name, department_name) AGAINST (? IN BOOLEAN MODE) AS relevance", against)
+	} else {
+		query = query.Select("what_positions.*, 0 AS relevance")
+	}
+
+	if (params.SortBy == "" || params.SortBy == "relevance") && against != "" {
+		query = query.Order("relevance DESC").Order("created_at DESC")
+	} else {
+		query = query.Order(positionSortClause(params))
+	}
+
+	offset, limit := positionPage(params)
+	if err := query.Offset(offset).Limit(limit).Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return rows, total, nil
+}
+
+// SuggestPositionNames 按前缀联想职位名称，按出现次数排序
+func (r *PositionRepository) SuggestPositionNames(prefix string, limit int) ([]string, error) {
+	var names []string
+	err := r.db.Model(&model.Position{}).
+		Where("status = ? AND position_name LIKE ?", model.PositionStatusPublished, escapeLike(prefix)+"%").
+		Group("position_name").
+		Order("COUNT(*) DESC").
+		Limit(limit).
+		Pluck("position_name", &names).Error
+	return names, err
+}
+
+// buildFullTextAgainst 将关键词转换为 BOOLEAN MODE 查询串
+// 每个词作为必须命中的短语；短于 ngram 长度的词单独返回，由调用方使用 LIKE 匹配
+func buildFullTextAgainst(keyword string) (string, []string) {
+	var required []string
+	var short []string
+
+	for _, term := range strings.Fields(keyword) {
+		term = strings.Map(func(r rune) rune {
+			if strings.ContainsRune(`+-<>()~*"@`, r) {
+				return -1
+			}
+			return r
+		}, term)
+		if term == "" {
+			continue
+		}
+		if utf8.RuneCountInString(term) < ngramTokenSize {
+			short = append(short, term)
+			continue
+		}
+		required = append(required, `+"`+term+`"`)
+	}
+
+	return strings.Join(required, " "), short
+}
+
+// escapeLike 转义 LIKE 通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,311 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/what-cse/server/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// QuestionDedupRepository 题目查重仓库（MinHash 签名、LSH 分段桶、疑似重复题对）
+type QuestionDedupRepository struct {
+	db *gorm.DB
+}
+
+// NewQuestionDedupRepository 创建题目查重仓库
+func NewQuestionDedupRepository(db *gorm.DB) *QuestionDedupRepository {
+	return &QuestionDedupRepository{db: db}
+}
+
+// WithTx 返回在事务 tx 中读写的仓库
+func (r *QuestionDedupRepository) WithTx(tx *gorm.DB) *QuestionDedupRepository {
+	return &QuestionDedupRepository{db: tx}
+}
+
+// =====================================================
+// 签名与分段桶
+// =====================================================
+
+// SaveSignatures 写入签名并替换对应题目的分段桶
+func (r *QuestionDedupRepository) SaveSignatures(signatures []model.QuestionSignature, bands []model.QuestionLSHBand) error {
+	if len(signatures) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(signatures))
+	for i, sig := range signatures {
+		ids[i] = sig.QuestionID
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "question_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"signature", "shingle_count", "updated_at"}),
+		}).CreateInBatches(signatures, 200).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("question_id IN ?", ids).Delete(&model.QuestionLSHBand{}).Error; err != nil {
+			return err
+		}
+		if len(bands) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(bands, 500).Error
+	})
+}
+
+// DeleteSignatures 删除题目的签名和分段桶
+func (r *QuestionDedupRepository) DeleteSignatures(questionIDs []uint) error {
+	if len(questionIDs) == 0 {
+		return nil
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("question_id IN ?", questionIDs).Delete(&model.QuestionLSHBand{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("question_id IN ?", questionIDs).Delete(&model.QuestionSignature{}).Error
+	})
+}
+
+// FindCandidates 查找与任一 (band, bucket) 同桶的题目
+func (r *QuestionDedupRepository) FindCandidates(bands []model.QuestionLSHBand) ([]uint, error) {
+	var ids []uint
+	if len(bands) == 0 {
+		return ids, nil
+	}
+	keys := make([][]interface{}, len(bands))
+	for i, b := range bands {
+		keys[i] = []interface{}{b.Band, b.Bucket}
+	}
+	err := r.db.Model(&model.QuestionLSHBand{}).
+		Distinct("question_id").
+		Where("(band, bucket) IN ?", keys).
+		Pluck("question_id", &ids).Error
+	return ids, err
+}
+
+// GetSignatures 按题目ID获取签名
+func (r *QuestionDedupRepository) GetSignatures(questionIDs []uint) ([]model.QuestionSignature, error) {
+	var signatures []model.QuestionSignature
+	if len(questionIDs) == 0 {
+		return signatures, nil
+	}
+	err := r.db.Where("question_id IN ?", questionIDs).Find(&signatures).Error
+	return signatures, err
+}
+
+// ScanStaleQuestions 按主键游标读取缺少签名或签名早于题目更新时间的题目（不含归档题）
+func (r *QuestionDedupRepository) ScanStaleQuestions(afterID uint, limit int) ([]model.Question, error) {
+	var questions []model.Question
+	err := r.db.Model(&model.Question{}).
+		Select("what_questions.id", "what_questions.content", "what_questions.options").
+		Joins("LEFT JOIN what_question_signatures s ON s.question_id = what_questions.id").
+		Where("what_questions.status <> ?", model.QuestionStatusArchived).
+		Where("s.question_id IS NULL OR what_questions.updated_at > s.updated_at").
+		Where("what_questions.id > ?", afterID).
+		Order("what_questions.id ASC").
+		Limit(limit).
+		Find(&questions).Error
+	return questions, err
+}
+
+// GetRemovedSignatureIDs 获取题目已删除或归档但仍有签名的题目ID
+func (r *QuestionDedupRepository) GetRemovedSignatureIDs() ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&model.QuestionSignature{}).
+		Joins("LEFT JOIN what_questions q ON q.id = what_question_signatures.question_id").
+		Where("q.id IS NULL OR q.deleted_at IS NOT NULL OR q.status = ?", model.QuestionStatusArchived).
+		Pluck("what_question_signatures.question_id", &ids).Error
+	return ids, err
+}
+
+// CountSignatures 统计已签名题目数
+func (r *QuestionDedupRepository) CountSignatures() (int64, error) {
+	var count int64
+	err := r.db.Model(&model.QuestionSignature{}).Count(&count).Error
+	return count, err
+}
+
+// =====================================================
+// 疑似重复题对
+// =====================================================
+
+// CreateDuplicates 写入疑似重复题对，已存在的题对忽略
+func (r *QuestionDedupRepository) CreateDuplicates(pairs []model.QuestionDuplicate) error {
+	if len(pairs) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(pairs, 200).Error
+}
+
+// DeleteDuplicates 删除涉及这些题目的疑似重复题对
+func (r *QuestionDedupRepository) DeleteDuplicates(questionIDs []uint) error {
+	if len(questionIDs) == 0 {
+		return nil
+	}
+	return r.db.Where("question_id IN ? OR duplicate_of_id IN ?", questionIDs, questionIDs).
+		Delete(&model.QuestionDuplicate{}).Error
+}
+
+// ListDuplicates 按状态获取疑似重复题对
+func (r *QuestionDedupRepository) ListDuplicates(status string, limit int) ([]model.QuestionDuplicate, error) {
+	var pairs []model.QuestionDuplicate
+	query := r.db.Order("similarity DESC").Order("id ASC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&pairs).Error
+	return pairs, err
+}
+
+// GetDuplicateByID 获取疑似重复题对
+func (r *QuestionDedupRepository) GetDuplicateByID(id uint) (*model.QuestionDuplicate, error) {
+	var pair model.QuestionDuplicate
+	if err := r.db.First(&pair, id).Error; err != nil {
+		return nil, err
+	}
+	return &pair, nil
+}
+
+// ResolveDuplicate 更新单个题对的处理状态
+func (r *QuestionDedupRepository) ResolveDuplicate(id uint, status string, resolvedBy uint) error {
+	now := time.Now()
+	return r.db.Model(&model.QuestionDuplicate{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":      status,
+		"resolved_by": resolvedBy,
+		"resolved_at": now,
+	}).Error
+}
+
+// CountDuplicatesByStatus 按状态统计题对数
+func (r *QuestionDedupRepository) CountDuplicatesByStatus() (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err := r.db.Model(&model.QuestionDuplicate{}).
+		Select("status, COUNT(*) as count").
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// =====================================================
+// 合并
+// =====================================================
+
+// MergeQuestions 把 mergeIDs 合并到 keepID：
+// 做题记录、收藏、错题迁移到保留题（同一用户已有保留题时合并计数），
+// 作答统计累加，标签取并集，被合并题目归档并移出 LSH 索引，相关待审核题对标记为已合并
+func (r *QuestionDedupRepository) MergeQuestions(keepID uint, mergeIDs []uint, resolvedBy uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var keep model.Question
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&keep, keepID).Error; err != nil {
+			return err
+		}
+		var merged []model.Question
+		if err := tx.Where("id IN ?", mergeIDs).Find(&merged).Error; err != nil {
+			return err
+		}
+
+		tagSet := make(map[string]bool, len(keep.Tags))
+		for _, tag := range keep.Tags {
+			tagSet[tag] = true
+		}
+
+		for _, dup := range merged {
+			// 错题：同一用户两道题都有时累加到保留题
+			if err := tx.Exec(`
+				UPDATE what_wrong_questions k
+				JOIN what_wrong_questions d ON d.user_id = k.user_id AND d.question_id = ?
+				SET k.wrong_count = k.wrong_count + d.wrong_count,
+					k.correct_count = k.correct_count + d.correct_count,
+					k.first_wrong_at = LEAST(k.first_wrong_at, d.first_wrong_at),
+					k.last_wrong_at = GREATEST(k.last_wrong_at, d.last_wrong_at)
+				WHERE k.question_id = ?`, dup.ID, keepID).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(`
+				DELETE d FROM what_wrong_questions d
+				JOIN what_wrong_questions k ON k.user_id = d.user_id AND k.question_id = ?
+				WHERE d.question_id = ?`, keepID, dup.ID).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&model.WrongQuestion{}).Where("question_id = ?", dup.ID).
+				Update("question_id", keepID).Error; err != nil {
+				return err
+			}
+
+			// 收藏：已收藏保留题的直接删除
+			if err := tx.Exec(`
+				DELETE d FROM what_user_question_collects d
+				JOIN what_user_question_collects k ON k.user_id = d.user_id AND k.question_id = ?
+				WHERE d.question_id = ?`, keepID, dup.ID).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&model.UserQuestionCollect{}).Where("question_id = ?", dup.ID).
+				Update("question_id", keepID).Error; err != nil {
+				return err
+			}
+
+			// 做题记录
+			if err := tx.Model(&model.UserQuestionRecord{}).Where("question_id = ?", dup.ID).
+				Update("question_id", keepID).Error; err != nil {
+				return err
+			}
+
+			keep.AttemptCount += dup.AttemptCount
+			keep.CorrectCount += dup.CorrectCount
+			for _, tag := range dup.Tags {
+				if !tagSet[tag] {
+					tagSet[tag] = true
+					keep.Tags = append(keep.Tags, tag)
+				}
+			}
+		}
+
+		correctRate := 0.0
+		if keep.AttemptCount > 0 {
+			correctRate = float64(keep.CorrectCount*10000/keep.AttemptCount) / 100
+		}
+		if err := tx.Model(&model.Question{}).Where("id = ?", keepID).Updates(map[string]interface{}{
+			"attempt_count": keep.AttemptCount,
+			"correct_count": keep.CorrectCount,
+			"correct_rate":  correctRate,
+			"tags":          keep.Tags,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&model.Question{}).Where("id IN ?", mergeIDs).
+			Update("status", model.QuestionStatusArchived).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("question_id IN ?", mergeIDs).Delete(&model.QuestionLSHBand{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("question_id IN ?", mergeIDs).Delete(&model.QuestionSignature{}).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		return tx.Model(&model.QuestionDuplicate{}).
+			Where("status = ?", model.QuestionDuplicatePending).
+			Where("question_id IN ? OR duplicate_of_id IN ?", mergeIDs, mergeIDs).
+			Updates(map[string]interface{}{
+				"status":      model.QuestionDuplicateMerged,
+				"resolved_by": resolvedBy,
+				"resolved_at": now,
+			}).Error
+	})
+}
@@ -227,6 +227,16 @@ func (r *QuestionMaterialRepository) GetByID(id uint) (*model.QuestionMaterial,
 	return &material, nil
 }
 
+// GetByIDs gets materials by IDs
+func (r *QuestionMaterialRepository) GetByIDs(ids []uint) ([]model.QuestionMaterial, error) {
+	var materials []model.QuestionMaterial
+	if len(ids) == 0 {
+		return materials, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&materials).Error
+	return materials, err
+}
+
 // GetWithQuestions gets a material with its questions
 func (r *QuestionMaterialRepository) GetWithQuestions(id uint) (*model.QuestionMaterial, error) {
 	var material model.QuestionMaterial
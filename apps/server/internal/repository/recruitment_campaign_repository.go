@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/what-cse/server/internal/model"
+	"gorm.io/gorm"
+)
+
+type RecruitmentCampaignRepository struct {
+	db *gorm.DB
+}
+
+func NewRecruitmentCampaignRepository(db *gorm.DB) *RecruitmentCampaignRepository {
+	return &RecruitmentCampaignRepository{db: db}
+}
+
+func (r *RecruitmentCampaignRepository) Create(campaign *model.RecruitmentCampaign) error {
+	return r.db.Create(campaign).Error
+}
+
+func (r *RecruitmentCampaignRepository) Update(campaign *model.RecruitmentCampaign) error {
+	return r.db.Save(campaign).Error
+}
+
+// GetByID 获取招录批次，不存在时返回 nil
+func (r *RecruitmentCampaignRepository) GetByID(id uint) (*model.RecruitmentCampaign, error) {
+	var campaign model.RecruitmentCampaign
+	err := r.db.First(&campaign, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+// FindCandidates 查找可能与公告属于同一批次的招录批次（同年度，省份为空或一致）
+func (r *RecruitmentCampaignRepository) FindCandidates(year int, province string, limit int) ([]model.RecruitmentCampaign, error) {
+	var campaigns []model.RecruitmentCampaign
+	query := r.db.Model(&model.RecruitmentCampaign{}).Where("year = ?", year)
+	if province != "" {
+		query = query.Where("province = ? OR province = ''", province)
+	}
+	err := query.Order("updated_at DESC").Limit(limit).Find(&campaigns).Error
+	return campaigns, err
+}
+
+// GetPositionIDsByAnnouncement 获取招录公告下的职位ID
+func (r *RecruitmentCampaignRepository) GetPositionIDsByAnnouncement(announcementID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&model.Position{}).
+		Where("announcement_id = ?", announcementID).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// =====================================================
+// 职位-公告关联
+// =====================================================
+
+// LinkPosition 关联职位与公告，已存在时更新阶段
+func (r *RecruitmentCampaignRepository) LinkPosition(positionID, announcementID uint, stage model.LifecycleStage) error {
+	link := model.PositionAnnouncement{
+		PositionID:     strconv.FormatUint(uint64(positionID), 10),
+		AnnouncementID: announcementID,
+		Stage:          string(stage),
+	}
+	var existing model.PositionAnnouncement
+	err := r.db.Where("position_id = ? AND announcement_id = ?", link.PositionID, announcementID).
+		First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return r.db.Create(&link).Error
+	}
+	if err != nil {
+		return err
+	}
+	if existing.Stage == link.Stage {
+		return nil
+	}
+	return r.db.Model(&existing).Update("stage", link.Stage).Error
+}
+
+// GetPositionLinks 获取职位关联的所有公告记录
+func (r *RecruitmentCampaignRepository) GetPositionLinks(positionID uint) ([]model.PositionAnnouncement, error) {
+	var links []model.PositionAnnouncement
+	err := r.db.Where("position_id = ?", strconv.FormatUint(uint64(positionID), 10)).
+		Order("created_at ASC").
+		Find(&links).Error
+	return links, err
+}
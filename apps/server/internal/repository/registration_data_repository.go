@@ -274,3 +274,15 @@ type ExamTypeRegistrationStats struct {
 	TotalApplicants int64   `json:"total_applicants"`
 	AvgCompetition  float64 `json:"avg_competition"`
 }
+
+// GetByPositionIDs 批量获取多个职位的报名数据快照（按时间升序）
+func (r *RegistrationDataRepository) GetByPositionIDs(positionIDs []string) ([]model.PositionRegistrationData, error) {
+	var data []model.PositionRegistrationData
+	if len(positionIDs) == 0 {
+		return data, nil
+	}
+	err := r.db.Where("position_id IN ?", positionIDs).
+		Order("position_id ASC, snapshot_date ASC, snapshot_time ASC").
+		Find(&data).Error
+	return data, err
+}
@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/what-cse/server/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReviewRepository 间隔重复复习仓库（复习日志、用户参数、跨卡片与错题的到期查询）
+type ReviewRepository struct {
+	db *gorm.DB
+}
+
+// NewReviewRepository 创建复习仓库
+func NewReviewRepository(db *gorm.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+// CreateLog 写入复习日志
+func (r *ReviewRepository) CreateLog(log *model.ReviewLog) error {
+	return r.db.Create(log).Error
+}
+
+// CreateLogs 批量写入复习日志
+func (r *ReviewRepository) CreateLogs(logs []model.ReviewLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(logs, 500).Error
+}
+
+// GetItemLogs 获取用户若干条目的复习日志，按时间顺序分组，键为条目ID
+func (r *ReviewRepository) GetItemLogs(userID uint, itemType model.ReviewItemType, itemIDs []uint) (map[uint][]model.ReviewLog, error) {
+	result := make(map[uint][]model.ReviewLog)
+	if len(itemIDs) == 0 {
+		return result, nil
+	}
+	var logs []model.ReviewLog
+	err := r.db.Where("user_id = ? AND item_type = ? AND item_id IN ?", userID, itemType, itemIDs).
+		Order("reviewed_at, id").
+		Find(&logs).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, log := range logs {
+		result[log.ItemID] = append(result[log.ItemID], log)
+	}
+	return result, nil
+}
+
+// GetOptimizeLogs 按条目和时间顺序加载用户的复习日志
+func (r *ReviewRepository) GetOptimizeLogs(userID uint, limit int) ([]model.ReviewLog, error) {
+	var logs []model.ReviewLog
+	err := r.db.Select("item_type, item_id, rating, state, elapsed_days, reviewed_at").
+		Where("user_id = ?", userID).
+		Order("item_type, item_id, reviewed_at, id").
+		Limit(limit).
+		Find(&logs).Error
+	return logs, err
+}
+
+// CountLogs 统计用户复习日志总数
+func (r *ReviewRepository) CountLogs(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.ReviewLog{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// CountSince 统计某时间之后的复习次数与新卡片学习次数
+func (r *ReviewRepository) CountSince(userID uint, since time.Time) (reviewed, learned int64, err error) {
+	var result struct {
+		Reviewed int64
+		Learned  int64
+	}
+	err = r.db.Model(&model.ReviewLog{}).
+		Select("COALESCE(SUM(CASE WHEN state <> 'new' THEN 1 ELSE 0 END), 0) AS reviewed, "+
+			"COALESCE(SUM(CASE WHEN state = 'new' AND item_type = ? THEN 1 ELSE 0 END), 0) AS learned",
+			model.ReviewItemFlashCard).
+		Where("user_id = ? AND reviewed_at >= ?", userID, since).
+		Scan(&result).Error
+	return result.Reviewed, result.Learned, err
+}
+
+// GetRetention 统计某时间之后隔天复习的回忆次数与总次数
+func (r *ReviewRepository) GetRetention(userID uint, since time.Time) (recalled, total int64, err error) {
+	var result struct {
+		Recalled int64
+		Total    int64
+	}
+	err = r.db.Model(&model.ReviewLog{}).
+		Select("COALESCE(SUM(CASE WHEN rating > 1 THEN 1 ELSE 0 END), 0) AS recalled, COUNT(*) AS total").
+		Where("user_id = ? AND reviewed_at >= ? AND elapsed_days >= 1", userID, since).
+		Scan(&result).Error
+	return result.Recalled, result.Total, err
+}
+
+// UsersToOptimize 获取复习日志足够且自上次优化以来新增了至少 step 条日志的用户
+func (r *ReviewRepository) UsersToOptimize(minReviews, step int) ([]uint, error) {
+	var userIDs []uint
+	err := r.db.Table("what_review_logs AS l").
+		Select("l.user_id").
+		Joins("LEFT JOIN what_srs_user_params AS p ON p.user_id = l.user_id").
+		Group("l.user_id, p.review_count").
+		Having("COUNT(*) >= ? AND COUNT(*) >= COALESCE(p.review_count, 0) + ?", minReviews, step).
+		Pluck("l.user_id", &userIDs).Error
+	return userIDs, err
+}
+
+// GetParams 获取用户复习参数，不存在时返回 nil
+func (r *ReviewRepository) GetParams(userID uint) (*model.SRSUserParams, error) {
+	var params model.SRSUserParams
+	err := r.db.Where("user_id = ?", userID).First(&params).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &params, nil
+}
+
+// SaveParams 写入用户复习参数
+func (r *ReviewRepository) SaveParams(params *model.SRSUserParams) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"algorithm", "request_retention", "weights", "review_count",
+			"log_loss", "default_log_loss", "optimized_at", "updated_at",
+		}),
+	}).Create(params).Error
+}
+
+// DueLoad 统计 [from, to) 内每天到期的卡片与错题数量，键为 2006-01-02
+func (r *ReviewRepository) DueLoad(userID uint, from, to time.Time) (map[string]int, error) {
+	type dayCount struct {
+		Day   string
+		Count int
+	}
+	load := make(map[string]int)
+
+	var cards []dayCount
+	err := r.db.Model(&model.UserFlashCardRecord{}).
+		Select("DATE_FORMAT(next_review_at, '%Y-%m-%d') AS day, COUNT(*) AS count").
+		Where("user_id = ? AND next_review_at >= ? AND next_review_at < ?", userID, from, to).
+		Group("day").
+		Scan(&cards).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var wrongs []dayCount
+	err = r.db.Model(&model.WrongQuestion{}).
+		Select("DATE_FORMAT(next_review_at, '%Y-%m-%d') AS day, COUNT(*) AS count").
+		Where("user_id = ? AND status = ? AND next_review_at >= ? AND next_review_at < ?",
+			userID, model.WrongQuestionStatusActive, from, to).
+		Group("day").
+		Scan(&wrongs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range append(cards, wrongs...) {
+		load[c.Day] += c.Count
+	}
+	return load, nil
+}
+
+// CountDue 统计截止时间前到期的卡片与错题总数
+func (r *ReviewRepository) CountDue(userID uint, cutoff time.Time) (int64, error) {
+	var cards, wrongs int64
+	if err := r.db.Model(&model.UserFlashCardRecord{}).
+		Where("user_id = ? AND next_review_at <= ?", userID, cutoff).
+		Count(&cards).Error; err != nil {
+		return 0, err
+	}
+	if err := r.db.Model(&model.WrongQuestion{}).
+		Where("user_id = ? AND status = ? AND next_review_at <= ?", userID, model.WrongQuestionStatusActive, cutoff).
+		Count(&wrongs).Error; err != nil {
+		return 0, err
+	}
+	return cards + wrongs, nil
+}
+
+// DueFlashCards 获取截止时间前到期的卡片记录
+func (r *ReviewRepository) DueFlashCards(userID uint, cutoff time.Time, limit int) ([]*model.UserFlashCardRecord, error) {
+	var records []*model.UserFlashCardRecord
+	err := r.db.Where("user_id = ? AND next_review_at <= ?", userID, cutoff).
+		Preload("Card").
+		Order("next_review_at").
+		Limit(limit).
+		Find(&records).Error
+	return records, err
+}
+
+// DueWrongQuestions 获取截止时间前到期的错题
+func (r *ReviewRepository) DueWrongQuestions(userID uint, cutoff time.Time, limit int) ([]*model.WrongQuestion, error) {
+	var wrongs []*model.WrongQuestion
+	err := r.db.Where("user_id = ? AND status = ? AND next_review_at <= ?", userID, model.WrongQuestionStatusActive, cutoff).
+		Preload("Question").Preload("Question.Category").
+		Order("next_review_at").
+		Limit(limit).
+		Find(&wrongs).Error
+	return wrongs, err
+}
+
+// NewFlashCards 获取用户尚未学习过的启用卡片，按重要度优先
+func (r *ReviewRepository) NewFlashCards(userID uint, limit int) ([]*model.KnowledgeFlashCard, error) {
+	var cards []*model.KnowledgeFlashCard
+	err := r.db.Where("is_active = ? AND (owner_id IS NULL OR owner_id = ?)", true, userID).
+		Where("NOT EXISTS (SELECT 1 FROM what_user_flash_card_records AS r WHERE r.card_id = what_knowledge_flash_cards.id AND r.user_id = ?)", userID).
+		Order("importance DESC, sort_order, id").
+		Limit(limit).
+		Find(&cards).Error
+	return cards, err
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/what-cse/server/internal/model"
+	"gorm.io/gorm"
+)
+
+// SearchOutboxRepository 搜索同步发件箱仓库
+type SearchOutboxRepository struct {
+	db *gorm.DB
+}
+
+// NewSearchOutboxRepository 创建搜索同步发件箱仓库
+func NewSearchOutboxRepository(db *gorm.DB) *SearchOutboxRepository {
+	return &SearchOutboxRepository{db: db}
+}
+
+// enqueueSearchSync 在给定事务内追加同步记录
+func enqueueSearchSync(tx *gorm.DB, entityType string, ids ...uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	entries := make([]model.SearchOutbox, 0, len(ids))
+	for _, id := range ids {
+		if id == 0 {
+			continue
+		}
+		entries = append(entries, model.SearchOutbox{EntityType: entityType, EntityID: id})
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(entries, 500).Error
+}
+
+// Enqueue 追加同步记录
+func (r *SearchOutboxRepository) Enqueue(entityType string, ids ...uint) error {
+	return enqueueSearchSync(r.db, entityType, ids...)
+}
+
+// GetPending 获取待同步且已到重试时间的记录（按创建时间升序）
+func (r *SearchOutboxRepository) GetPending(entityType string, maxAttempts, limit int) ([]model.SearchOutbox, error) {
+	var entries []model.SearchOutbox
+	query := r.db.Where("entity_type = ? AND processed_at IS NULL", entityType).
+		Where("next_attempt_at IS NULL OR next_attempt_at <= ?", time.Now())
+	if maxAttempts > 0 {
+		query = query.Where("attempts < ?", maxAttempts)
+	}
+	err := query.Order("id ASC").Limit(limit).Find(&entries).Error
+	return entries, err
+}
+
+// MarkProcessed 标记记录已同步
+func (r *SearchOutboxRepository) MarkProcessed(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Model(&model.SearchOutbox{}).
+		Where("id IN ?", ids).
+		Update("processed_at", time.Now()).Error
+}
+
+// MarkFailed 记录同步失败，nextAttempt 之前不再重试
+func (r *SearchOutboxRepository) MarkFailed(ids []uint, errMsg string, nextAttempt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Model(&model.SearchOutbox{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"last_error":      errMsg,
+			"next_attempt_at": nextAttempt,
+		}).Error
+}
+
+// PurgeProcessed 清理早于指定时间的已同步记录
+func (r *SearchOutboxRepository) PurgeProcessed(before time.Time) (int64, error) {
+	result := r.db.Where("processed_at IS NOT NULL AND processed_at < ?", before).
+		Delete(&model.SearchOutbox{})
+	return result.RowsAffected, result.Error
+}
+
+// ResetFailed 重置失败记录的尝试次数，使其重新进入同步队列
+func (r *SearchOutboxRepository) ResetFailed(entityType string) (int64, error) {
+	result := r.db.Model(&model.SearchOutbox{}).
+		Where("entity_type = ? AND processed_at IS NULL AND attempts > 0", entityType).
+		Updates(map[string]interface{}{"attempts": 0, "last_error": "", "next_attempt_at": nil})
+	return result.RowsAffected, result.Error
+}
+
+// GetStats 获取发件箱统计
+func (r *SearchOutboxRepository) GetStats(entityType string) (*model.SearchOutboxStats, error) {
+	stats := &model.SearchOutboxStats{}
+	base := func() *gorm.DB {
+		return r.db.Model(&model.SearchOutbox{}).Where("entity_type = ?", entityType)
+	}
+
+	if err := base().Where("processed_at IS NULL").Count(&stats.Pending).Error; err != nil {
+		return nil, err
+	}
+	base().Where("processed_at IS NULL AND attempts > 0").Count(&stats.Failed)
+
+	var oldest model.SearchOutbox
+	if err := base().Where("processed_at IS NULL").Order("id ASC").First(&oldest).Error; err == nil {
+		stats.OldestPending = &oldest.CreatedAt
+	}
+	var last model.SearchOutbox
+	if err := base().Where("processed_at IS NOT NULL").Order("processed_at DESC").First(&last).Error; err == nil {
+		stats.LastProcessed = last.ProcessedAt
+	}
+
+	return stats, nil
+}
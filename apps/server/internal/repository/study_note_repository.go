@@ -293,6 +293,22 @@ func (r *WrongQuestionRepository) UpdateReview(id uint, nextReviewAt *time.Time)
 		}).Error
 }
 
+// UpdateSchedule saves the spaced-repetition state after a review
+func (r *WrongQuestionRepository) UpdateSchedule(wrong *model.WrongQuestion) error {
+	return r.db.Model(&model.WrongQuestion{}).Where("id = ?", wrong.ID).
+		Updates(map[string]interface{}{
+			"last_review_at": wrong.LastReviewAt,
+			"review_count":   wrong.ReviewCount,
+			"next_review_at": wrong.NextReviewAt,
+			"ease_factor":    wrong.EaseFactor,
+			"interval":       wrong.Interval,
+			"srs_state":      wrong.SRSState,
+			"stability":      wrong.Stability,
+			"srs_difficulty": wrong.SRSDifficulty,
+			"lapses":         wrong.Lapses,
+		}).Error
+}
+
 // GetNeedReviewQuestions gets questions that need review
 func (r *WrongQuestionRepository) GetNeedReviewQuestions(userID uint, limit int) ([]model.WrongQuestion, error) {
 	var wrongs []model.WrongQuestion
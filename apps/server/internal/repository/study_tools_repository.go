@@ -5,6 +5,7 @@ import (
 
 	"github.com/what-cse/server/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // =====================================================
@@ -631,54 +632,31 @@ func NewKnowledgeMasteryRepository(db *gorm.DB) *KnowledgeMasteryRepository {
 	return &KnowledgeMasteryRepository{db: db}
 }
 
-// Upsert 更新或创建掌握度记录
-func (r *KnowledgeMasteryRepository) Upsert(userID, knowledgePointID uint, isCorrect bool) error {
+// Get 获取单条掌握度记录，不存在时返回 nil
+func (r *KnowledgeMasteryRepository) Get(userID, knowledgePointID uint) (*model.UserKnowledgeMastery, error) {
 	var mastery model.UserKnowledgeMastery
 	err := r.db.Where("user_id = ? AND knowledge_point_id = ?", userID, knowledgePointID).
 		First(&mastery).Error
-
-	now := time.Now()
-
 	if err == gorm.ErrRecordNotFound {
-		// 创建新记录
-		mastery = model.UserKnowledgeMastery{
-			UserID:           userID,
-			KnowledgePointID: knowledgePointID,
-			TotalQuestions:   1,
-			LastPracticeAt:   &now,
-		}
-		if isCorrect {
-			mastery.CorrectQuestions = 1
-			mastery.MasteryLevel = 100
-			mastery.StreakCount = 1
-			mastery.LastCorrectAt = &now
-		}
-		return r.db.Create(&mastery).Error
+		return nil, nil
 	}
-
-	// 更新现有记录
-	mastery.TotalQuestions++
-	mastery.LastPracticeAt = &now
-	if isCorrect {
-		mastery.CorrectQuestions++
-		mastery.StreakCount++
-		mastery.LastCorrectAt = &now
-	} else {
-		mastery.StreakCount = 0
+	if err != nil {
+		return nil, err
 	}
+	return &mastery, nil
+}
 
-	// 计算掌握度：正确率 * 0.6 + 连续正确奖励 * 0.4
-	correctRate := float64(mastery.CorrectQuestions) / float64(mastery.TotalQuestions) * 100
-	streakBonus := float64(mastery.StreakCount) * 5 // 每连续正确一次加5分
-	if streakBonus > 20 {
-		streakBonus = 20 // 最多加20分
-	}
-	mastery.MasteryLevel = correctRate*0.8 + streakBonus
-	if mastery.MasteryLevel > 100 {
-		mastery.MasteryLevel = 100
+// SaveBatch 批量写入掌握度记录（按用户与知识点覆盖）
+func (r *KnowledgeMasteryRepository) SaveBatch(masteries []model.UserKnowledgeMastery) error {
+	if len(masteries) == 0 {
+		return nil
 	}
-
-	return r.db.Save(&mastery).Error
+	return r.db.Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "knowledge_point_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"mastery_level", "total_questions", "correct_questions", "last_practice_at", "last_correct_at", "streak_count", "updated_at",
+		}),
+	}).CreateInBatches(masteries, 500).Error
 }
 
 // GetByUser 获取用户的知识点掌握情况
@@ -695,48 +673,3 @@ func (r *KnowledgeMasteryRepository) GetByUser(userID uint, categoryID *uint) ([
 	err := query.Find(&masteries).Error
 	return masteries, err
 }
-
-// GetWeakPoints 获取薄弱知识点
-func (r *KnowledgeMasteryRepository) GetWeakPoints(userID uint, threshold float64, limit int) ([]model.UserKnowledgeMastery, error) {
-	var masteries []model.UserKnowledgeMastery
-	err := r.db.Where("user_id = ? AND mastery_level < ?", userID, threshold).
-		Preload("KnowledgePoint").
-		Order("mastery_level ASC").
-		Limit(limit).
-		Find(&masteries).Error
-	return masteries, err
-}
-
-// GetMasteryStats 获取掌握度统计
-func (r *KnowledgeMasteryRepository) GetMasteryStats(userID uint) (map[string]int64, error) {
-	type stat struct {
-		Level string
-		Count int64
-	}
-
-	// 按掌握度分段统计
-	var stats []stat
-	err := r.db.Model(&model.UserKnowledgeMastery{}).
-		Select(`
-			CASE 
-				WHEN mastery_level >= 80 THEN 'mastered'
-				WHEN mastery_level >= 60 THEN 'familiar'
-				WHEN mastery_level >= 40 THEN 'learning'
-				ELSE 'weak'
-			END as level, 
-			COUNT(*) as count
-		`).
-		Where("user_id = ?", userID).
-		Group("level").
-		Scan(&stats).Error
-	if err != nil {
-		return nil, err
-	}
-
-	result := make(map[string]int64)
-	for _, s := range stats {
-		result[s.Level] = s.Count
-	}
-
-	return result, nil
-}
@@ -85,6 +85,16 @@ func (r *WechatRSSSourceRepository) ListDueToCrawl() ([]model.WechatRSSSource, e
 	return sources, err
 }
 
+// ListDueToCrawlByType 列出指定类型中到期需要抓取的正常状态订阅源
+func (r *WechatRSSSourceRepository) ListDueToCrawlByType(sourceType model.WechatRSSSourceType) ([]model.WechatRSSSource, error) {
+	var sources []model.WechatRSSSource
+	err := r.db.Where("source_type = ? AND status = ? AND (next_crawl_at IS NULL OR next_crawl_at <= ?)",
+		sourceType, model.WechatRSSSourceStatusActive, time.Now()).
+		Order("next_crawl_at ASC").
+		Find(&sources).Error
+	return sources, err
+}
+
 func (r *WechatRSSSourceRepository) Update(source *model.WechatRSSSource) error {
 	return r.db.Save(source).Error
 }
@@ -110,6 +120,27 @@ func (r *WechatRSSSourceRepository) UpdateCrawlTime(id uint, lastCrawl, nextCraw
 	}).Error
 }
 
+// RecordCrawlError 记录一次抓取失败：错误次数加一并推迟下次抓取，status 为空时不改变状态
+func (r *WechatRSSSourceRepository) RecordCrawlError(id uint, errorMsg string, nextCrawl time.Time, status model.WechatRSSSourceStatus) error {
+	updates := map[string]interface{}{
+		"error_message": errorMsg,
+		"error_count":   gorm.Expr("error_count + 1"),
+		"next_crawl_at": nextCrawl,
+	}
+	if status != "" {
+		updates["status"] = status
+	}
+	return r.db.Model(&model.WechatRSSSource{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// UpdateFeedValidators 保存订阅源响应的 ETag 和 Last-Modified，供下次条件请求使用
+func (r *WechatRSSSourceRepository) UpdateFeedValidators(id uint, etag, modified string) error {
+	return r.db.Model(&model.WechatRSSSource{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"feed_etag":     etag,
+		"feed_modified": modified,
+	}).Error
+}
+
 func (r *WechatRSSSourceRepository) IncrementArticleCount(id uint, count int) error {
 	return r.db.Model(&model.WechatRSSSource{}).Where("id = ?", id).
 		Update("article_count", gorm.Expr("article_count + ?", count)).Error
@@ -18,6 +18,7 @@ type TaskHandlers struct {
 	ListPageRepo    ListPageRepository
 	AnnouncementRepo AnnouncementRepository
 	CrawlTaskRepo   CrawlTaskRepository
+	URLStore        crawler.URLStore
 }
 
 // ListPageRepository interface for list page operations
@@ -73,6 +74,11 @@ func NewTaskHandlers(
 	}
 }
 
+// SetURLStore sets the persistent URL store shared by the spiders
+func (h *TaskHandlers) SetURLStore(store crawler.URLStore) {
+	h.URLStore = store
+}
+
 // HandleListMonitor handles the list monitor task
 func (h *TaskHandlers) HandleListMonitor(ctx context.Context, task *asynq.Task) error {
 	payload, err := ParseListMonitorPayload(task)
@@ -126,6 +132,9 @@ func (h *TaskHandlers) HandleListMonitor(ctx context.Context, task *asynq.Task)
 
 	// Create and run spider
 	spider := crawler.NewListMonitorSpider(h.SpiderConfig, h.Logger)
+	if h.URLStore != nil {
+		spider.SetURLStore(h.URLStore)
+	}
 	spider.SetListPages(listPages)
 
 	result, err := spider.Run()
@@ -183,6 +192,12 @@ func (h *TaskHandlers) HandleAnnouncementCrawl(ctx context.Context, task *asynq.
 
 	// Create and run spider
 	spider := crawler.NewAnnouncementSpider(h.SpiderConfig, h.Logger)
+	if h.URLStore != nil {
+		spider.SetURLStore(h.URLStore)
+	}
+	if listPage, _ := h.ListPageRepo.GetByID(payload.SourceListID); listPage != nil && listPage.Adapter != nil {
+		spider.SetAdapters(map[uint]*crawler.SiteAdapter{listPage.ID: listPage.Adapter})
+	}
 	spider.SetArticles([]crawler.Article{article})
 
 	result, err := spider.Run()
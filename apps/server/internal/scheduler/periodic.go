@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+)
+
+// Periodic maintenance task types
+const (
+	TypeSearchSync          = "maintenance:search_sync"           // 搜索发件箱同步
+	TypeSearchOutboxPurge   = "maintenance:search_outbox_purge"   // 清理已同步的发件箱记录
+	TypeEmbeddingRefresh    = "maintenance:embedding_refresh"     // 增量向量化
+	TypeQuestionDedupSync   = "maintenance:question_dedup_sync"   // 题目签名补齐
+	TypeIRTCalibrate        = "maintenance:irt_calibrate"         // IRT 题目标定
+	TypeKnowledgeTracingFit = "maintenance:knowledge_tracing_fit" // BKT 参数拟合
+	TypeReviewOptimize      = "maintenance:review_optimize"       // FSRS 个人参数优化
+	TypeAnnouncementLink    = "maintenance:announcement_link"     // 公告批次关联
+	TypeLearningSearch      = "maintenance:learning_search"       // 学习内容搜索投影刷新
+	TypeFeedPoll            = "maintenance:feed_poll"             // 订阅源抓取
+)
+
+// PeriodicTask is a maintenance job run on a fixed interval
+type PeriodicTask struct {
+	Type       string
+	Interval   time.Duration // zero or negative disables the task
+	RunOnStart bool          // also enqueue once at registration instead of waiting a full interval
+	Run        func(ctx context.Context) error
+}
+
+// SchedulePeriodic registers the handler of a periodic task and schedules it.
+// Every API instance registers the same schedule; the unique option keeps a
+// single copy of the task queued or running across the cluster, so the job
+// runs once per interval no matter how many instances are up.
+func (s *Scheduler) SchedulePeriodic(task PeriodicTask) error {
+	if task.Interval <= 0 {
+		s.Logger.Info("Periodic task disabled", zap.String("type", task.Type))
+		return nil
+	}
+
+	s.RegisterHandler(task.Type, asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+		if err := task.Run(ctx); err != nil {
+			s.Logger.Warn("Periodic task failed", zap.String("type", t.Type()), zap.Error(err))
+			return err
+		}
+		return nil
+	}))
+
+	// A run may take longer than the interval (daily calibrations), but never
+	// less than the asynq default
+	timeout := max(task.Interval, 30*time.Minute)
+	opts := []asynq.Option{
+		asynq.Queue("low"),
+		asynq.MaxRetry(0), // the next tick is the retry
+		asynq.Timeout(timeout),
+		asynq.Unique(max(task.Interval, time.Second)),
+	}
+	if _, err := s.ScheduleTask(fmt.Sprintf("@every %s", task.Interval), asynq.NewTask(task.Type, nil), opts...); err != nil {
+		return err
+	}
+	if task.RunOnStart {
+		_, err := s.Client.Enqueue(asynq.NewTask(task.Type, nil), opts...)
+		if err != nil && !errors.Is(err, asynq.ErrDuplicateTask) {
+			s.Logger.Warn("Failed to enqueue periodic task", zap.String("type", task.Type), zap.Error(err))
+		}
+	}
+	return nil
+}
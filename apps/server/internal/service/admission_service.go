@@ -0,0 +1,360 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/what-cse/server/internal/model"
+	"github.com/what-cse/server/internal/repository"
+)
+
+var (
+	ErrNoScorePrediction = errors.New("做题量不足，暂无法预测分数")
+)
+
+const (
+	// 进面比例（招录人数 : 进面人数），未知时按 1:3
+	admissionInterviewRatio = 3.0
+	// 用户预测分数区间按 90% 区间处理
+	admissionIntervalZ = 1.645
+	// 竞争比每翻一倍，进面分数线约上浮的分数（按 200 分制）
+	admissionLinePerDoubling = 3.0
+	// 竞争调整的上限（按 200 分制）
+	admissionMaxLineShift = 8.0
+	// 单次估算的职位上限
+	admissionMaxPositions = 50
+)
+
+// AdmissionSource 上岸概率的职位来源
+const (
+	AdmissionSourceFavorites = "favorites"
+	AdmissionSourceMatched   = "matched"
+)
+
+// AdmissionEstimate 单个职位的上岸概率估算
+type AdmissionEstimate struct {
+	PositionID         uint    `json:"position_id"`
+	PositionName       string  `json:"position_name"`
+	DepartmentName     string  `json:"department_name"`
+	RecruitCount       int     `json:"recruit_count"`
+	UserScore          float64 `json:"user_score"`           // 用户预测笔试分（已换算到分数线分制）
+	UserScoreStd       float64 `json:"user_score_std"`       // 用户分数标准差
+	InterviewLine      float64 `json:"interview_line"`       // 预测进面分数线（含竞争调整）
+	InterviewLineStd   float64 `json:"interview_line_std"`   // 分数线标准差
+	LineShift          float64 `json:"line_shift"`           // 竞争调整值
+	ForecastRatio      float64 `json:"forecast_ratio"`       // 预测最终竞争比
+	InterviewProb      float64 `json:"interview_prob"`       // 进面概率
+	AdmissionProb      float64 `json:"admission_prob"`       // 上岸概率（进面概率 × 面试通过率）
+	ExpectedValue      float64 `json:"expected_value"`       // 期望值（上岸概率 × 招录名额权重）
+	ScorePoolLevel     string  `json:"score_pool_level"`     // 分数线预测池化层级
+	Level              string  `json:"level"`                // 稳/冲/保 等级
+	Explanation        string  `json:"explanation"`          // 说明
+	HasForecastOutcome bool    `json:"has_forecast_outcome"` // 是否使用了报名竞争预测
+}
+
+// AdmissionReport 上岸概率报告
+type AdmissionReport struct {
+	Source         string                `json:"source"`
+	UserScore      *model.PredictedScore `json:"user_score"`
+	Estimates      []*AdmissionEstimate  `json:"estimates"`
+	SkippedCount   int                   `json:"skipped_count"` // 无法预测分数线而跳过的职位数
+	BestPositionID uint                  `json:"best_position_id,omitempty"`
+}
+
+// AdmissionService 上岸概率服务
+// 结合用户预测分数分布、职位预测进面分数线、招录人数和竞争预测，估算进入面试名单的概率
+type AdmissionService struct {
+	positionRepo    *repository.PositionRepository
+	favoriteRepo    *repository.FavoriteRepository
+	historyRepo     *repository.PositionHistoryRepository
+	historyService  *PositionHistoryService
+	statsService    *LearningStatsService
+	forecastService *RegistrationForecastService
+	matchService    *MatchService
+}
+
+// NewAdmissionService 创建上岸概率服务
+func NewAdmissionService(
+	positionRepo *repository.PositionRepository,
+	favoriteRepo *repository.FavoriteRepository,
+	historyRepo *repository.PositionHistoryRepository,
+	historyService *PositionHistoryService,
+	statsService *LearningStatsService,
+	forecastService *RegistrationForecastService,
+) *AdmissionService {
+	return &AdmissionService{
+		positionRepo:    positionRepo,
+		favoriteRepo:    favoriteRepo,
+		historyRepo:     historyRepo,
+		historyService:  historyService,
+		statsService:    statsService,
+		forecastService: forecastService,
+	}
+}
+
+// SetMatchService 设置匹配服务（用于匹配职位来源）
+func (s *AdmissionService) SetMatchService(matchService *MatchService) {
+	s.matchService = matchService
+}
+
+// =====================================================
+// 公开方法
+// =====================================================
+
+// EstimatePosition 估算用户报考单个职位的上岸概率
+func (s *AdmissionService) EstimatePosition(userID, positionID uint) (*AdmissionEstimate, error) {
+	userScore, err := s.userScore(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	position, err := s.positionRepo.FindByID(positionID)
+	if err != nil {
+		return nil, ErrPositionNotFound
+	}
+
+	return s.estimate(userScore, position, 1)
+}
+
+// GetReport 按来源（收藏/匹配）估算并按期望值排序
+func (s *AdmissionService) GetReport(userID uint, source string, limit int) (*AdmissionReport, error) {
+	if limit <= 0 || limit > admissionMaxPositions {
+		limit = 20
+	}
+
+	userScore, err := s.userScore(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var positions []model.Position
+	weights := make(map[uint]float64)
+
+	switch source {
+	case AdmissionSourceMatched:
+		if s.matchService == nil {
+			return nil, fmt.Errorf("匹配服务不可用")
+		}
+		resp, err := s.matchService.MatchPositions(userID, &MatchRequest{
+			Strategy:     "smart",
+			Page:         1,
+			PageSize:     limit,
+			OnlyEligible: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range resp.Results {
+			positions = append(positions, r.Position)
+			weights[r.Position.ID] = float64(r.MatchScore) / 100
+		}
+	default:
+		source = AdmissionSourceFavorites
+		positions, _, err = s.favoriteRepo.GetUserFavorites(userID, 1, limit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.buildReport(source, userScore, positions, weights), nil
+}
+
+// EstimateForMatches 对匹配结果估算上岸概率（供匹配报告使用）
+func (s *AdmissionService) EstimateForMatches(userID uint, results []MatchResult) (*AdmissionReport, error) {
+	userScore, err := s.userScore(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make([]model.Position, 0, len(results))
+	weights := make(map[uint]float64, len(results))
+	for _, r := range results {
+		if len(positions) >= admissionMaxPositions {
+			break
+		}
+		positions = append(positions, r.Position)
+		weights[r.Position.ID] = float64(r.MatchScore) / 100
+	}
+
+	return s.buildReport(AdmissionSourceMatched, userScore, positions, weights), nil
+}
+
+// =====================================================
+// 估算核心
+// =====================================================
+
+// buildReport 批量估算并按期望值排序
+func (s *AdmissionService) buildReport(source string, userScore *model.PredictedScore, positions []model.Position, weights map[uint]float64) *AdmissionReport {
+	report := &AdmissionReport{
+		Source:    source,
+		UserScore: userScore,
+		Estimates: make([]*AdmissionEstimate, 0, len(positions)),
+	}
+
+	for i := range positions {
+		weight := 1.0
+		if w, ok := weights[positions[i].ID]; ok && w > 0 {
+			weight = w
+		}
+		est, err := s.estimate(userScore, &positions[i], weight)
+		if err != nil {
+			report.SkippedCount++
+			continue
+		}
+		report.Estimates = append(report.Estimates, est)
+	}
+
+	sort.Slice(report.Estimates, func(i, j int) bool {
+		return report.Estimates[i].ExpectedValue > report.Estimates[j].ExpectedValue
+	})
+	if len(report.Estimates) > 0 {
+		report.BestPositionID = report.Estimates[0].PositionID
+	}
+
+	return report
+}
+
+// estimate 估算单个职位
+func (s *AdmissionService) estimate(userScore *model.PredictedScore, position *model.Position, weight float64) (*AdmissionEstimate, error) {
+	line, err := s.historyService.PredictScoreLineByPosition(position)
+	if err != nil {
+		return nil, err
+	}
+
+	// 分数线为两科合计（>100）时，把用户单科百分制分数换算到同一分制
+	scale := 1.0
+	if line.PredictedScore > 100 {
+		scale = 2.0
+	}
+
+	userMean := userScore.MostLikely * scale
+	userStd := (userScore.MaxScore - userScore.MinScore) / 2 / admissionIntervalZ * scale
+
+	lineStd := (line.ConfidenceHigh - line.ConfidenceLow) / 2 / admissionIntervalZ
+	if line.IntervalLevel == 0 {
+		// 旧口径的 95% 区间
+		lineStd = (line.ConfidenceHigh - line.ConfidenceLow) / 2 / 1.96
+	}
+
+	est := &AdmissionEstimate{
+		PositionID:     position.ID,
+		PositionName:   position.PositionName,
+		DepartmentName: position.DepartmentName,
+		RecruitCount:   position.RecruitCount,
+		UserScore:      round2(userMean),
+		UserScoreStd:   round2(userStd),
+		ScorePoolLevel: line.PoolLevel,
+	}
+
+	// 竞争调整：今年预测竞争比高于往年时，进面线相应上浮
+	lineMean := line.PredictedScore
+	if s.forecastService != nil {
+		if forecast, err := s.forecastService.ForecastPosition(position); err == nil && forecast.ForecastRatio > 0 {
+			est.ForecastRatio = forecast.ForecastRatio
+			est.HasForecastOutcome = true
+			if histRatio := s.historicalRatio(position); histRatio > 0 {
+				shift := admissionLinePerDoubling * scale / 2 * math.Log2(forecast.ForecastRatio/histRatio)
+				limit := admissionMaxLineShift * scale / 2
+				shift = math.Max(-limit, math.Min(limit, shift))
+				est.LineShift = round2(shift)
+				lineMean += shift
+			}
+		}
+	}
+
+	est.InterviewLine = round2(lineMean)
+	est.InterviewLineStd = round2(lineStd)
+
+	// P(用户分数 >= 进面线)，两者独立正态
+	diffStd := math.Sqrt(userStd*userStd + lineStd*lineStd)
+	if diffStd <= 0 {
+		diffStd = 1
+	}
+	interviewProb := normalCDF((userMean - lineMean) / diffStd)
+
+	// 进面后按 1:3 面试竞争估计录用概率，招录人数多的职位面试容错更高
+	admissionProb := interviewProb / admissionInterviewRatio
+	if position.RecruitCount > 1 {
+		admissionProb = interviewProb * math.Min(1, (1+0.1*float64(position.RecruitCount-1))/admissionInterviewRatio)
+	}
+
+	est.InterviewProb = round4(interviewProb)
+	est.AdmissionProb = round4(admissionProb)
+	est.ExpectedValue = round4(admissionProb * weight)
+	est.Level = admissionLevel(interviewProb)
+	est.Explanation = admissionExplanation(est)
+
+	return est, nil
+}
+
+// userScore 获取用户预测分数分布
+func (s *AdmissionService) userScore(userID uint) (*model.PredictedScore, error) {
+	score, err := s.statsService.PredictScore(userID)
+	if err != nil {
+		return nil, err
+	}
+	if score == nil {
+		return nil, ErrNoScorePrediction
+	}
+	return score, nil
+}
+
+// historicalRatio 往年同职位最近一年的竞争比
+func (s *AdmissionService) historicalRatio(position *model.Position) float64 {
+	if position.PositionCode == "" || s.historyRepo == nil {
+		return 0
+	}
+	histories, err := s.historyRepo.GetByPositionCode(position.PositionCode)
+	if err != nil {
+		return 0
+	}
+	for _, h := range histories {
+		if h.CompetitionRatio > 0 {
+			return h.CompetitionRatio
+		}
+	}
+	return 0
+}
+
+// normalCDF 标准正态分布函数
+func normalCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+// round4 保留四位小数
+func round4(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
+
+// admissionLevel 按进面概率分档
+func admissionLevel(p float64) string {
+	switch {
+	case p >= 0.7:
+		return "稳"
+	case p >= 0.4:
+		return "冲"
+	case p >= 0.15:
+		return "搏"
+	default:
+		return "难"
+	}
+}
+
+// admissionExplanation 生成说明文字
+func admissionExplanation(est *AdmissionEstimate) string {
+	gap := est.UserScore - est.InterviewLine
+	text := fmt.Sprintf("预测笔试%.1f分，预测进面线%.1f分", est.UserScore, est.InterviewLine)
+	if gap >= 0 {
+		text += fmt.Sprintf("，高出%.1f分", gap)
+	} else {
+		text += fmt.Sprintf("，还差%.1f分", -gap)
+	}
+	if est.LineShift > 0 {
+		text += fmt.Sprintf("；今年竞争预计更激烈，分数线上调%.1f分", est.LineShift)
+	} else if est.LineShift < 0 {
+		text += fmt.Sprintf("；今年竞争预计缓和，分数线下调%.1f分", -est.LineShift)
+	}
+	return text
+}
@@ -28,6 +28,8 @@ type AILearningPathService struct {
 	questionRepo  *repository.QuestionRepository
 	courseRepo    *repository.CourseRepository
 	knowledgeRepo *repository.KnowledgePointRepository
+
+	masteryService *KnowledgeMasteryService
 }
 
 // NewAILearningPathService 创建AI学习路径服务实例
@@ -47,6 +49,11 @@ func NewAILearningPathService(
 	}
 }
 
+// SetKnowledgeMasteryService 设置知识点掌握度服务，设置后以掌握度最低的知识点作为薄弱项
+func (s *AILearningPathService) SetKnowledgeMasteryService(masteryService *KnowledgeMasteryService) {
+	s.masteryService = masteryService
+}
+
 // =====================================================
 // 类型定义
 // =====================================================
@@ -137,6 +144,17 @@ func (s *AILearningPathService) AnalyzeUserProfile(ctx context.Context, userID u
 		TotalMinutes:      3600,
 	}
 
+	if s.masteryService != nil {
+		if weak, err := s.masteryService.GetWeakPoints(userID, 5); err == nil && len(weak) > 0 {
+			profile.WeakSubjects = make([]string, 0, len(weak))
+			for _, w := range weak {
+				if w.KnowledgeName != "" {
+					profile.WeakSubjects = append(profile.WeakSubjects, w.KnowledgeName)
+				}
+			}
+		}
+	}
+
 	// TODO: 从实际数据分析用户画像
 	// 1. 获取用户做题记录，计算各科目正确率
 	// 2. 分析学习时间分布
@@ -8,6 +8,7 @@ import (
 	"sort"
 	"time"
 
+	"github.com/what-cse/server/internal/model"
 	"github.com/what-cse/server/internal/repository"
 )
 
@@ -25,6 +26,9 @@ type AIWeaknessAnalyzerService struct {
 	practiceRepo *repository.PracticeSessionRepository
 	questionRepo *repository.QuestionRepository
 	learningRepo *repository.UserDailyLearningStatsRepository
+
+	embeddingService *EmbeddingService
+	masteryService   *KnowledgeMasteryService
 }
 
 // NewAIWeaknessAnalyzerService 创建AI薄弱点分析服务实例
@@ -40,6 +44,16 @@ func NewAIWeaknessAnalyzerService(
 	}
 }
 
+// SetEmbeddingService 设置向量化服务，设置后专项练习按语义相似度选题
+func (s *AIWeaknessAnalyzerService) SetEmbeddingService(embeddingService *EmbeddingService) {
+	s.embeddingService = embeddingService
+}
+
+// SetKnowledgeMasteryService 设置知识点掌握度服务，设置后薄弱知识点以知识追踪的掌握度为准
+func (s *AIWeaknessAnalyzerService) SetKnowledgeMasteryService(masteryService *KnowledgeMasteryService) {
+	s.masteryService = masteryService
+}
+
 // =====================================================
 // 类型定义
 // =====================================================
@@ -65,6 +79,7 @@ type WeakKnowledgePoint struct {
 	Subject             string    `json:"subject"`
 	Category            string    `json:"category"`
 	CorrectRate         float64   `json:"correct_rate"`         // 正确率
+	Mastery             float64   `json:"mastery,omitempty"`    // 知识追踪掌握度 0-100
 	TotalCount          int       `json:"total_count"`          // 总题数
 	CorrectCount        int       `json:"correct_count"`        // 正确数
 	AvgTime             float64   `json:"avg_time"`             // 平均用时(秒)
@@ -212,6 +227,11 @@ func (s *AIWeaknessAnalyzerService) AnalyzeWeakness(ctx context.Context, req Ana
 
 	// 分析知识点薄弱情况
 	weakKnowledgePoints := s.analyzeKnowledgePoints(data)
+	if s.masteryService != nil {
+		if points := s.masteryWeakPoints(req.UserID); len(points) > 0 {
+			weakKnowledgePoints = points
+		}
+	}
 
 	// 分析题型薄弱情况
 	weakQuestionTypes := s.analyzeQuestionTypes(data)
@@ -250,6 +270,37 @@ func (s *AIWeaknessAnalyzerService) AnalyzeWeakness(ctx context.Context, req Ana
 	}, nil
 }
 
+// masteryWeakPoints 由知识追踪掌握度得到薄弱知识点，掌握度已考虑猜测、失误和遗忘
+func (s *AIWeaknessAnalyzerService) masteryWeakPoints(userID uint) []WeakKnowledgePoint {
+	weak, err := s.masteryService.GetWeakPoints(userID, 10)
+	if err != nil {
+		return nil
+	}
+
+	threshold := s.masteryService.WeakThreshold()
+	points := make([]WeakKnowledgePoint, 0, len(weak))
+	for _, w := range weak {
+		severityLevel := "medium"
+		if w.MasteryLevel < threshold*0.5 {
+			severityLevel = "critical"
+		} else if w.MasteryLevel < threshold*0.75 {
+			severityLevel = "high"
+		}
+		points = append(points, WeakKnowledgePoint{
+			ID:                  w.KnowledgePointID,
+			Name:                w.KnowledgeName,
+			CorrectRate:         math.Round(w.CorrectRate*10) / 10,
+			Mastery:             w.MasteryLevel,
+			TotalCount:          w.TotalQuestions,
+			CorrectCount:        int(math.Round(w.CorrectRate * float64(w.TotalQuestions) / 100)),
+			SeverityLevel:       severityLevel,
+			Trend:               "stable",
+			RecommendedPriority: 4 - map[string]int{"critical": 3, "high": 2, "medium": 1}[severityLevel],
+		})
+	}
+	return points
+}
+
 // analyzeKnowledgePoints 分析知识点薄弱情况
 func (s *AIWeaknessAnalyzerService) analyzeKnowledgePoints(data []PracticeData) []WeakKnowledgePoint {
 	// 按知识点分组统计
@@ -659,6 +710,12 @@ type SpecializedPractice struct {
 
 // GenerateSpecializedPractice 生成专项练习
 func (s *AIWeaknessAnalyzerService) GenerateSpecializedPractice(ctx context.Context, userID uint, knowledgePoint string, count int) (*SpecializedPractice, error) {
+	if s.embeddingService != nil && s.embeddingService.Ready(model.EmbeddingEntityQuestion) {
+		if practice, err := s.generateSemanticPractice(ctx, userID, knowledgePoint, count); err == nil && practice.QuestionCount > 0 {
+			return practice, nil
+		}
+	}
+
 	// TODO: 从题库中筛选相关题目
 	// 这里返回模拟数据
 
@@ -681,6 +738,71 @@ func (s *AIWeaknessAnalyzerService) GenerateSpecializedPractice(ctx context.Cont
 	return practice, nil
 }
 
+// generateSemanticPractice 以知识点名称为查询，从向量索引中选取语义最相关的已发布题目
+func (s *AIWeaknessAnalyzerService) generateSemanticPractice(ctx context.Context, userID uint, knowledgePoint string, count int) (*SpecializedPractice, error) {
+	if count <= 0 {
+		count = 10
+	}
+
+	// 多取一些候选，过滤掉下线题目后仍能凑够数量
+	scored, err := s.embeddingService.SimilarToText(ctx, model.EmbeddingEntityQuestion, knowledgePoint, count*2, nil)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, len(scored))
+	for i, sc := range scored {
+		ids[i] = sc.ID
+	}
+	questions, err := s.questionRepo.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*model.Question, len(questions))
+	for i := range questions {
+		byID[questions[i].ID] = &questions[i]
+	}
+
+	questionIDs := make([]uint, 0, count)
+	totalDifficulty, totalSeconds := 0, 0
+	for _, id := range ids {
+		q, ok := byID[id]
+		if !ok || q.Status != model.QuestionStatusPublished {
+			continue
+		}
+		questionIDs = append(questionIDs, id)
+		totalDifficulty += q.Difficulty
+		if q.AvgTime > 0 {
+			totalSeconds += q.AvgTime
+		} else {
+			totalSeconds += 120
+		}
+		if len(questionIDs) == count {
+			break
+		}
+	}
+
+	difficulty := "medium"
+	if n := len(questionIDs); n > 0 {
+		avg := float64(totalDifficulty) / float64(n)
+		if avg <= 2 {
+			difficulty = "easy"
+		} else if avg >= 4 {
+			difficulty = "hard"
+		}
+	}
+
+	return &SpecializedPractice{
+		ID:             fmt.Sprintf("practice_%d_%s", userID, time.Now().Format("20060102150405")),
+		Title:          fmt.Sprintf("%s专项突破练习", knowledgePoint),
+		Description:    fmt.Sprintf("根据您的薄弱点分析，为您精选的%s相关练习题", knowledgePoint),
+		KnowledgePoint: knowledgePoint,
+		QuestionCount:  len(questionIDs),
+		EstimatedTime:  int(math.Ceil(float64(totalSeconds) / 60)),
+		Difficulty:     difficulty,
+		QuestionIDs:    questionIDs,
+	}, nil
+}
+
 // =====================================================
 // 获取典型错题
 // =====================================================
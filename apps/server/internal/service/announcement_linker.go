@@ -0,0 +1,933 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/what-cse/server/internal/ai"
+	"github.com/what-cse/server/internal/config"
+	"github.com/what-cse/server/internal/model"
+	"github.com/what-cse/server/internal/repository"
+)
+
+var ErrAnnouncementLinking = errors.New("公告批次关联正在进行中")
+
+// 写入历年数据时的来源标记
+const announcementLinkSource = "公告关联"
+
+// AnnouncementLinkResult 单条公告的关联结果
+type AnnouncementLinkResult struct {
+	AnnouncementID   uint    `json:"announcement_id"`
+	AnnouncementType string  `json:"announcement_type"`
+	CampaignID       *uint   `json:"campaign_id,omitempty"`
+	NewCampaign      bool    `json:"new_campaign"`
+	Score            float64 `json:"score"`            // 与所归入批次的相似度，新建批次为 0
+	LinkedPositions  int     `json:"linked_positions"` // 写入职位-公告关联的职位数
+	Outcomes         int     `json:"outcomes"`         // 写入历年数据的职位数
+}
+
+// AnnouncementLinkBatchResult 一轮待关联公告的处理结果
+type AnnouncementLinkBatchResult struct {
+	Processed   int       `json:"processed"`
+	Linked      int       `json:"linked"`
+	Campaigns   int       `json:"campaigns"` // 新建的招录批次
+	Outcomes    int       `json:"outcomes"`
+	Failed      int       `json:"failed"`
+	DurationMs  int64     `json:"duration_ms"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// AnnouncementLinkerService 把同一次招录的招录公告与后续公告归为一个招录批次，
+// 并从面试名单、拟录用公示、分数线公告中提取各职位结果写入历年数据
+type AnnouncementLinkerService struct {
+	db               *gorm.DB
+	announcementRepo *repository.AnnouncementRepository
+	campaignRepo     *repository.RecruitmentCampaignRepository
+	positionRepo     *repository.PositionRepository
+	historyRepo      *repository.PositionHistoryRepository
+	llmConfigService *LLMConfigService
+	cfg              config.AnnouncementLinkConfig
+	logger           *zap.Logger
+
+	syncMu sync.Mutex
+}
+
+// NewAnnouncementLinkerService 创建公告批次关联服务
+func NewAnnouncementLinkerService(
+	db *gorm.DB,
+	announcementRepo *repository.AnnouncementRepository,
+	campaignRepo *repository.RecruitmentCampaignRepository,
+	positionRepo *repository.PositionRepository,
+	historyRepo *repository.PositionHistoryRepository,
+	cfg config.AnnouncementLinkConfig,
+	logger *zap.Logger,
+) *AnnouncementLinkerService {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 200
+	}
+	if cfg.MinScore <= 0 || cfg.MinScore > 1 {
+		cfg.MinScore = 0.55
+	}
+	return &AnnouncementLinkerService{
+		db:               db,
+		announcementRepo: announcementRepo,
+		campaignRepo:     campaignRepo,
+		positionRepo:     positionRepo,
+		historyRepo:      historyRepo,
+		cfg:              cfg,
+		logger:           logger,
+	}
+}
+
+// SetLLMConfigService 设置 LLM 配置服务，用于识别公告类型和提取分数线
+func (s *AnnouncementLinkerService) SetLLMConfigService(llmConfigService *LLMConfigService) {
+	s.llmConfigService = llmConfigService
+}
+
+// =====================================================
+// 定时任务
+// =====================================================
+
+// RunScheduled 定时关联入口，上一轮仍在执行时跳过
+func (s *AnnouncementLinkerService) RunScheduled(ctx context.Context) error {
+	if _, err := s.LinkPending(ctx); err != nil && !errors.Is(err, ErrAnnouncementLinking) {
+		return err
+	}
+	return nil
+}
+
+// =====================================================
+// 关联入口
+// =====================================================
+
+// LinkPending 处理一批尚未关联的公告，按发布日期升序保证招录公告先建立批次
+func (s *AnnouncementLinkerService) LinkPending(ctx context.Context) (*AnnouncementLinkBatchResult, error) {
+	if !s.syncMu.TryLock() {
+		return nil, ErrAnnouncementLinking
+	}
+	defer s.syncMu.Unlock()
+
+	start := time.Now()
+	announcements, err := s.announcementRepo.ListUnlinked(s.cfg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	extractor := s.extractor()
+	result := &AnnouncementLinkBatchResult{}
+	for i := range announcements {
+		if ctx.Err() != nil {
+			break
+		}
+		result.Processed++
+		linkResult, err := s.link(ctx, &announcements[i], extractor)
+		if err != nil {
+			result.Failed++
+			s.logger.Warn("Failed to link announcement",
+				zap.Uint("announcement_id", announcements[i].ID),
+				zap.Error(err),
+			)
+			if ctx.Err() != nil {
+				break
+			}
+			// 记录失败并标记已处理，避免每批重复调用 LLM、阻塞后续公告；可通过单条关联接口重试
+			if err := s.announcementRepo.SetLinkError(announcements[i].ID, err.Error(), time.Now()); err != nil {
+				s.logger.Warn("Failed to record announcement link error", zap.Error(err))
+			}
+			continue
+		}
+		if linkResult.CampaignID != nil {
+			result.Linked++
+		}
+		if linkResult.NewCampaign {
+			result.Campaigns++
+		}
+		result.Outcomes += linkResult.Outcomes
+	}
+
+	result.DurationMs = time.Since(start).Milliseconds()
+	result.CompletedAt = time.Now()
+	if result.Processed > 0 {
+		s.logger.Info("Announcement linking completed",
+			zap.Int("processed", result.Processed),
+			zap.Int("linked", result.Linked),
+			zap.Int("campaigns", result.Campaigns),
+			zap.Int("outcomes", result.Outcomes),
+			zap.Int("failed", result.Failed),
+		)
+	}
+	return result, nil
+}
+
+// LinkAnnouncement 立即关联（或重新关联）单条公告
+func (s *AnnouncementLinkerService) LinkAnnouncement(ctx context.Context, id uint) (*AnnouncementLinkResult, error) {
+	if !s.syncMu.TryLock() {
+		return nil, ErrAnnouncementLinking
+	}
+	defer s.syncMu.Unlock()
+
+	announcement, err := s.announcementRepo.FindByID(id)
+	if err != nil {
+		return nil, ErrAnnouncementNotFound
+	}
+	return s.link(ctx, announcement, s.extractor())
+}
+
+// extractor 获取当前默认 LLM 配置的提取器，未配置时返回 nil，仅使用规则
+func (s *AnnouncementLinkerService) extractor() *ai.AIExtractor {
+	if s.llmConfigService == nil {
+		return nil
+	}
+	extractor, err := s.llmConfigService.GetActiveConfigForExtractor()
+	if err != nil {
+		return nil
+	}
+	return extractor
+}
+
+// link 归类公告、选择或新建招录批次、写入职位关联和职位结果
+func (s *AnnouncementLinkerService) link(ctx context.Context, a *model.Announcement, extractor *ai.AIExtractor) (*AnnouncementLinkResult, error) {
+	result := &AnnouncementLinkResult{AnnouncementID: a.ID}
+	now := time.Now()
+
+	annType := s.classify(ctx, a, extractor)
+	result.AnnouncementType = string(annType)
+	if annType == "" {
+		// 无法识别为招录流程中的公告，标记已处理
+		return result, s.announcementRepo.SetCampaign(a.ID, nil, "", now)
+	}
+
+	features := newCampaignFeatures(a, annType)
+	outcomes := s.extractOutcomes(ctx, a, annType, extractor)
+	for _, o := range outcomes {
+		if o.Code != "" {
+			features.codes[o.Code] = true
+		}
+	}
+
+	campaign, score, err := s.matchCampaign(features)
+	if err != nil {
+		return nil, err
+	}
+	if campaign == nil {
+		campaign = &model.RecruitmentCampaign{
+			Name:       features.name,
+			Department: features.department,
+			Year:       features.year,
+			ExamType:   a.ExamType,
+			Province:   a.Province,
+		}
+		result.NewCampaign = true
+	} else {
+		result.Score = round4(score)
+	}
+
+	// 批次、职位关联、职位结果和公告的处理标记在同一事务中写入，失败时整体回滚
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		txs := s.withTx(tx)
+		if result.NewCampaign {
+			if err := txs.campaignRepo.Create(campaign); err != nil {
+				return err
+			}
+		}
+		result.CampaignID = &campaign.ID
+
+		if a.CampaignID == nil || *a.CampaignID != campaign.ID {
+			campaign.AnnouncementCount++
+		}
+		txs.mergeCampaign(campaign, a, annType, features)
+		if err := txs.campaignRepo.Update(campaign); err != nil {
+			return err
+		}
+
+		positions, err := txs.campaignPositions(campaign)
+		if err != nil {
+			return err
+		}
+		if result.LinkedPositions, err = txs.linkPositions(a, annType, features, positions); err != nil {
+			return err
+		}
+		if result.Outcomes, err = txs.saveOutcomes(a, campaign, outcomes, positions); err != nil {
+			return err
+		}
+		return txs.announcementRepo.SetCampaign(a.ID, &campaign.ID, string(annType), now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// withTx 返回在事务 tx 中读写的服务副本
+func (s *AnnouncementLinkerService) withTx(tx *gorm.DB) *AnnouncementLinkerService {
+	return &AnnouncementLinkerService{
+		db:               tx,
+		announcementRepo: repository.NewAnnouncementRepository(tx),
+		campaignRepo:     repository.NewRecruitmentCampaignRepository(tx),
+		positionRepo:     repository.NewPositionRepository(tx),
+		historyRepo:      repository.NewPositionHistoryRepository(tx),
+		llmConfigService: s.llmConfigService,
+		cfg:              s.cfg,
+		logger:           s.logger,
+	}
+}
+
+// =====================================================
+// 公告类型识别
+// =====================================================
+
+// 标题关键词，按顺序匹配，越靠后的阶段越先判断
+var announcementTypeKeywords = []struct {
+	annType  model.AnnouncementType
+	keywords []string
+}{
+	{model.AnnouncementTypeHiring, []string{"拟录用", "拟聘用", "拟录取", "录用公示", "聘用公示", "拟任职"}},
+	{model.AnnouncementTypeRegistration, []string{"报名人数", "报名情况", "报名统计", "缴费人数", "报考人数"}},
+	{model.AnnouncementTypeResult, []string{"总成绩", "综合成绩", "最终成绩"}},
+	{model.AnnouncementTypeInterview, []string{"面试", "资格复审", "资格复核", "资格审查", "体检", "考察"}},
+	{model.AnnouncementTypeResult, []string{"成绩", "分数线", "合格线"}},
+	{model.AnnouncementTypeWrittenExam, []string{"笔试", "准考证", "考试安排", "考点"}},
+	{model.AnnouncementTypeRecruitment, []string{"招录", "招考", "招聘", "录用", "遴选", "选调", "选聘"}},
+}
+
+// AI 公告类型代码与公告类型的对应
+var aiAnnouncementTypes = map[string]model.AnnouncementType{
+	"recruitment":          model.AnnouncementTypeRecruitment,
+	"registration_stats":   model.AnnouncementTypeRegistration,
+	"written_exam":         model.AnnouncementTypeWrittenExam,
+	"score_release":        model.AnnouncementTypeResult,
+	"qualification_review": model.AnnouncementTypeInterview,
+	"interview":            model.AnnouncementTypeInterview,
+	"physical_exam":        model.AnnouncementTypeInterview,
+	"political_review":     model.AnnouncementTypeInterview,
+	"publicity":            model.AnnouncementTypeHiring,
+}
+
+// classifyAnnouncementTitle 按标题关键词识别公告类型，无法识别返回空
+func classifyAnnouncementTitle(title string) model.AnnouncementType {
+	for _, item := range announcementTypeKeywords {
+		for _, keyword := range item.keywords {
+			if strings.Contains(title, keyword) {
+				return item.annType
+			}
+		}
+	}
+	return ""
+}
+
+// classify 识别公告类型：已有有效类型 > 标题关键词 > AI
+func (s *AnnouncementLinkerService) classify(ctx context.Context, a *model.Announcement, extractor *ai.AIExtractor) model.AnnouncementType {
+	switch t := model.AnnouncementType(a.AnnouncementType); t {
+	case model.AnnouncementTypeRecruitment, model.AnnouncementTypeRegistration, model.AnnouncementTypeWrittenExam,
+		model.AnnouncementTypeInterview, model.AnnouncementTypeResult, model.AnnouncementTypeHiring:
+		return t
+	}
+	if t := classifyAnnouncementTitle(a.Title); t != "" {
+		return t
+	}
+	if extractor == nil {
+		return ""
+	}
+	code, confidence, err := extractor.IdentifyAnnouncementType(ctx, a.Title, announcementText(a.Content))
+	if err != nil || confidence < 60 {
+		return ""
+	}
+	return aiAnnouncementTypes[code]
+}
+
+// =====================================================
+// 批次匹配
+// =====================================================
+
+var (
+	campaignYearPattern = regexp.MustCompile(`(20\d{2})\s*年`)
+	// 标题主干中去掉的年份、公告类型与虚词
+	campaignNoisePattern = regexp.MustCompile(`20\d{2}\s*年度?|（[^）]*）|\([^)]*\)|【[^】]*】|第[一二三四五六七八九十\d]+批|` +
+		`关于|公布|公告|公示|通知|拟录用|拟聘用|拟录取|人员|名单|进入|面试|笔试|资格复审|资格审查|体检|考察|` +
+		`报名人数|报名情况|报名统计|统计|成绩|总成绩|综合成绩|分数线|合格线|准考证|打印|有关|事项|情况|及|和|的`)
+	campaignDepartmentPattern = regexp.MustCompile(`^(.{2,30}?(?:人民政府|委员会|办公室|管理局|人力资源和社会保障局|人社局|组织部|厅|局|委|办|院|中心))`)
+	positionCodePattern       = regexp.MustCompile(`(?:职位|岗位)(?:代码|编码|编号)\s*[：:为]?\s*([A-Za-z0-9\-]{4,20})`)
+)
+
+// campaignFeatures 用于判断公告是否属于同一招录批次的特征
+type campaignFeatures struct {
+	annType    model.AnnouncementType
+	year       int
+	name       string // 标题主干
+	department string
+	examType   string
+	province   string
+	codes      map[string]bool // 公告中引用的职位代码
+}
+
+func newCampaignFeatures(a *model.Announcement, annType model.AnnouncementType) *campaignFeatures {
+	f := &campaignFeatures{
+		annType:  annType,
+		name:     campaignTitleCore(a.Title),
+		examType: a.ExamType,
+		province: a.Province,
+		codes:    make(map[string]bool),
+	}
+	if m := campaignYearPattern.FindStringSubmatch(a.Title); m != nil {
+		f.year, _ = strconv.Atoi(m[1])
+	} else if a.PublishDate != nil {
+		f.year = a.PublishDate.Year()
+	}
+	f.department = strings.TrimSpace(a.SourceName)
+	if f.department == "" {
+		title := campaignYearPattern.ReplaceAllString(a.Title, "")
+		if m := campaignDepartmentPattern.FindStringSubmatch(title); m != nil {
+			f.department = m[1]
+		}
+	}
+	for _, m := range positionCodePattern.FindAllStringSubmatch(announcementText(a.Content), -1) {
+		f.codes[m[1]] = true
+	}
+	return f
+}
+
+// campaignTitleCore 去掉年份、公告类型词和标点后的标题主干
+func campaignTitleCore(title string) string {
+	core := campaignNoisePattern.ReplaceAllString(title, "")
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			return -1
+		}
+		return r
+	}, core)
+}
+
+// bigramSimilarity 两个字符串的字二元组 Jaccard 相似度
+func bigramSimilarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	grams := func(s string) map[string]bool {
+		runes := []rune(s)
+		set := make(map[string]bool)
+		if len(runes) == 1 {
+			set[s] = true
+		}
+		for i := 0; i+1 < len(runes); i++ {
+			set[string(runes[i:i+2])] = true
+		}
+		return set
+	}
+	ga, gb := grams(a), grams(b)
+	inter := 0
+	for g := range ga {
+		if gb[g] {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(ga)+len(gb)-inter)
+}
+
+// matchCampaign 在同年度的已有批次中找相似度最高且达到阈值的批次，找不到返回 nil
+func (s *AnnouncementLinkerService) matchCampaign(f *campaignFeatures) (*model.RecruitmentCampaign, float64, error) {
+	if f.year == 0 {
+		return nil, 0, nil
+	}
+	candidates, err := s.campaignRepo.FindCandidates(f.year, f.province, 100)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var best *model.RecruitmentCampaign
+	bestScore := 0.0
+	for i := range candidates {
+		c := &candidates[i]
+		if f.examType != "" && c.ExamType != "" && f.examType != c.ExamType {
+			continue
+		}
+		// 已有招录公告的批次不再接收另一篇招录公告（如第二批招录）
+		if f.annType == model.AnnouncementTypeRecruitment && c.RecruitmentAnnouncementID != nil {
+			continue
+		}
+		score, err := s.campaignScore(f, c)
+		if err != nil {
+			return nil, 0, err
+		}
+		if score > bestScore {
+			best, bestScore = c, score
+		}
+	}
+	if best == nil || bestScore < s.cfg.MinScore {
+		return nil, bestScore, nil
+	}
+	return best, bestScore, nil
+}
+
+// campaignScore 标题主干、发布单位、职位代码三项加权，缺失的信号不参与计算
+func (s *AnnouncementLinkerService) campaignScore(f *campaignFeatures, c *model.RecruitmentCampaign) (float64, error) {
+	score, weight := 0.6*bigramSimilarity(f.name, c.Name), 0.6
+
+	if f.department != "" && c.Department != "" {
+		deptScore := bigramSimilarity(f.department, c.Department)
+		if strings.Contains(f.department, c.Department) || strings.Contains(c.Department, f.department) {
+			deptScore = 1
+		}
+		score += 0.2 * deptScore
+		weight += 0.2
+	}
+
+	if len(f.codes) > 0 && c.RecruitmentAnnouncementID != nil {
+		positions, err := s.positionRepo.GetByAnnouncementID(*c.RecruitmentAnnouncementID)
+		if err != nil {
+			return 0, err
+		}
+		if len(positions) > 0 {
+			matched := 0
+			for _, p := range positions {
+				if p.PositionCode != "" && f.codes[p.PositionCode] {
+					matched++
+				}
+			}
+			score += 0.2 * float64(matched) / float64(len(f.codes))
+			weight += 0.2
+		}
+	}
+	return score / weight, nil
+}
+
+// mergeCampaign 用新关联的公告补全批次信息
+func (s *AnnouncementLinkerService) mergeCampaign(c *model.RecruitmentCampaign, a *model.Announcement, annType model.AnnouncementType, f *campaignFeatures) {
+	if annType == model.AnnouncementTypeRecruitment {
+		c.RecruitmentAnnouncementID = &a.ID
+		if f.name != "" {
+			c.Name = f.name
+		}
+	}
+	if c.Name == "" {
+		c.Name = f.name
+	}
+	if c.Department == "" {
+		c.Department = f.department
+	}
+	if c.Year == 0 {
+		c.Year = f.year
+	}
+	if c.ExamType == "" {
+		c.ExamType = a.ExamType
+	}
+	if c.Province == "" {
+		c.Province = a.Province
+	}
+	if c.LatestPublishDate == nil || (a.PublishDate != nil && !a.PublishDate.Before(*c.LatestPublishDate)) {
+		c.LatestPublishDate = a.PublishDate
+		c.LatestStage = string(annType.Stage())
+	}
+}
+
+// =====================================================
+// 职位关联
+// =====================================================
+
+// campaignPositions 招录批次的职位，来自批次的招录公告
+func (s *AnnouncementLinkerService) campaignPositions(c *model.RecruitmentCampaign) ([]model.Position, error) {
+	if c.RecruitmentAnnouncementID == nil {
+		return nil, nil
+	}
+	return s.positionRepo.GetByAnnouncementID(*c.RecruitmentAnnouncementID)
+}
+
+// linkPositions 写入职位-公告关联：公告引用了职位代码时只关联这些职位，否则视为面向整个批次
+func (s *AnnouncementLinkerService) linkPositions(a *model.Announcement, annType model.AnnouncementType, f *campaignFeatures, positions []model.Position) (int, error) {
+	linked := 0
+	for _, p := range positions {
+		if len(f.codes) > 0 && annType != model.AnnouncementTypeRecruitment && !f.codes[p.PositionCode] {
+			continue
+		}
+		if err := s.campaignRepo.LinkPosition(p.ID, a.ID, annType.Stage()); err != nil {
+			return linked, err
+		}
+		linked++
+	}
+	return linked, nil
+}
+
+// =====================================================
+// 职位结果提取
+// =====================================================
+
+// positionOutcome 从后续公告中得到的单个职位结果
+type positionOutcome struct {
+	Code           string
+	Name           string
+	Department     string
+	WrittenScore   float64
+	InterviewScore float64 // 进面分数线（最低进面分数）
+	FinalScore     float64 // 最终录取分数线（拟录用人员最低总成绩）
+}
+
+// 结果表格表头关键词，按顺序判断，避免“笔试总成绩”被当作总成绩
+var outcomeColumnKeywords = []struct {
+	column   string
+	keywords []string
+}{
+	{"min_interview", []string{"最低进面", "进面最低", "最低面试分数", "进入面试最低", "最低分数线"}},
+	{"written", []string{"笔试成绩", "笔试总成绩", "笔试合成成绩", "笔试分数"}},
+	{"total", []string{"总成绩", "综合成绩", "最终成绩"}},
+	{"code", []string{"职位代码", "岗位代码", "职位编码", "岗位编码", "职位编号", "岗位编号"}},
+	{"name", []string{"职位名称", "岗位名称", "报考职位", "招考职位", "报考岗位"}},
+	{"department", []string{"招录机关", "招录单位", "用人单位", "招聘单位", "报考单位", "单位名称", "部门名称"}},
+}
+
+// extractOutcomes 从面试、成绩、拟录用公告中提取各职位结果：优先解析表格，表格无结果时交给 AI 提取分数线
+func (s *AnnouncementLinkerService) extractOutcomes(ctx context.Context, a *model.Announcement, annType model.AnnouncementType, extractor *ai.AIExtractor) []*positionOutcome {
+	switch annType {
+	case model.AnnouncementTypeInterview, model.AnnouncementTypeResult, model.AnnouncementTypeHiring:
+	default:
+		return nil
+	}
+
+	outcomes := parseOutcomeTables(a.Content, annType)
+	if len(outcomes) > 0 || extractor == nil {
+		return outcomes
+	}
+
+	result, err := extractor.ExtractScoreLines(ctx, announcementText(a.Content))
+	if err != nil {
+		s.logger.Debug("Score line extraction failed",
+			zap.Uint("announcement_id", a.ID),
+			zap.Error(err),
+		)
+		return nil
+	}
+	return scoreLineOutcomes(result)
+}
+
+// parseOutcomeTables 解析公告正文中的名单表格，按职位汇总进面最低分或拟录用最低总成绩
+func parseOutcomeTables(content string, annType model.AnnouncementType) []*positionOutcome {
+	if !strings.Contains(content, "<table") {
+		return nil
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		return nil
+	}
+
+	outcomes := make(map[string]*positionOutcome)
+	var order []string
+	doc.Find("table").Each(func(_ int, table *goquery.Selection) {
+		columns := map[string]int{}
+		table.Find("tr").Each(func(_ int, row *goquery.Selection) {
+			cells := row.Find("td, th").Map(func(_ int, cell *goquery.Selection) string {
+				return strings.TrimSpace(cell.Text())
+			})
+			if len(columns) == 0 {
+				columns = outcomeHeaderColumns(cells)
+				return
+			}
+			cell := func(column string) string {
+				if i, ok := columns[column]; ok && i < len(cells) {
+					return cells[i]
+				}
+				return ""
+			}
+
+			o := &positionOutcome{Code: cell("code"), Name: cell("name"), Department: cell("department")}
+			key := o.Code
+			if key == "" {
+				key = o.Department + "|" + o.Name
+			}
+			if key == "|" {
+				return
+			}
+			switch annType {
+			case model.AnnouncementTypeInterview:
+				if v := parseScore(cell("min_interview")); v > 0 {
+					o.InterviewScore = v
+				} else {
+					o.InterviewScore = parseScore(cell("written"))
+				}
+			case model.AnnouncementTypeResult:
+				o.InterviewScore = parseScore(cell("min_interview"))
+			case model.AnnouncementTypeHiring:
+				o.FinalScore = parseScore(cell("total"))
+			}
+			if o.InterviewScore == 0 && o.FinalScore == 0 {
+				return
+			}
+
+			existing, ok := outcomes[key]
+			if !ok {
+				outcomes[key] = o
+				order = append(order, key)
+				return
+			}
+			existing.InterviewScore = minPositive(existing.InterviewScore, o.InterviewScore)
+			existing.FinalScore = minPositive(existing.FinalScore, o.FinalScore)
+		})
+	})
+
+	result := make([]*positionOutcome, 0, len(order))
+	for _, key := range order {
+		result = append(result, outcomes[key])
+	}
+	return result
+}
+
+// outcomeHeaderColumns 识别表头行，至少包含职位代码或职位名称才视为名单表格
+func outcomeHeaderColumns(cells []string) map[string]int {
+	columns := map[string]int{}
+	for i, text := range cells {
+		text = strings.Join(strings.Fields(text), "")
+	match:
+		for _, item := range outcomeColumnKeywords {
+			if _, ok := columns[item.column]; ok {
+				continue
+			}
+			for _, keyword := range item.keywords {
+				if strings.Contains(text, keyword) {
+					columns[item.column] = i
+					break match
+				}
+			}
+		}
+	}
+	_, hasCode := columns["code"]
+	_, hasName := columns["name"]
+	if !hasCode && !hasName {
+		return map[string]int{}
+	}
+	return columns
+}
+
+// scoreLineOutcomes 把 AI 提取的按职位分数线转换为职位结果
+func scoreLineOutcomes(result *ai.ScoreLineExtractionResult) []*positionOutcome {
+	var outcomes []*positionOutcome
+	for _, line := range result.ScoreLines {
+		if line == nil || line.TotalScore == nil || *line.TotalScore <= 0 {
+			continue
+		}
+		if line.PositionCode == "" && line.PositionName == "" {
+			continue
+		}
+		o := &positionOutcome{Code: line.PositionCode, Name: line.PositionName, Department: line.DepartmentName}
+		switch line.LineType {
+		case "interview":
+			o.InterviewScore = *line.TotalScore
+		case "admission":
+			o.FinalScore = *line.TotalScore
+		case "written_qualify":
+			o.WrittenScore = *line.TotalScore
+		default:
+			continue
+		}
+		outcomes = append(outcomes, o)
+	}
+	return outcomes
+}
+
+// saveOutcomes 把职位结果写入历年数据，按职位代码+年份更新或新建
+func (s *AnnouncementLinkerService) saveOutcomes(a *model.Announcement, c *model.RecruitmentCampaign, outcomes []*positionOutcome, positions []model.Position) (int, error) {
+	if len(outcomes) == 0 || c.Year == 0 {
+		return 0, nil
+	}
+
+	byCode := make(map[string]*model.Position)
+	byName := make(map[string]*model.Position)
+	for i := range positions {
+		p := &positions[i]
+		if p.PositionCode != "" {
+			byCode[p.PositionCode] = p
+		}
+		byName[p.DepartmentName+"|"+p.PositionName] = p
+	}
+
+	histories := make([]*model.PositionHistory, 0, len(outcomes))
+	for _, o := range outcomes {
+		position := byCode[o.Code]
+		if position == nil {
+			position = byName[o.Department+"|"+o.Name]
+		}
+		h := &model.PositionHistory{
+			PositionCode:   o.Code,
+			PositionName:   o.Name,
+			DepartmentName: o.Department,
+			Year:           c.Year,
+			WrittenScore:   o.WrittenScore,
+			InterviewScore: o.InterviewScore,
+			FinalScore:     o.FinalScore,
+			ExamType:       c.ExamType,
+			Province:       c.Province,
+			Source:         announcementLinkSource,
+			SourceURL:      a.SourceURL,
+			CampaignID:     &c.ID,
+		}
+		if position != nil {
+			h.PositionCode = position.PositionCode
+			h.PositionName = position.PositionName
+			h.DepartmentCode = position.DepartmentCode
+			h.DepartmentName = position.DepartmentName
+			h.DepartmentLevel = position.DepartmentLevel
+			h.RecruitCount = position.RecruitCount
+			h.ApplyCount = position.ApplicantCount
+			h.PassCount = position.PassCount
+			h.CompetitionRatio = position.CompetitionRatio
+			h.ExamCategory = position.ExamCategory
+			h.City = position.City
+			h.District = position.District
+			h.Education = position.Education
+		}
+		// 历年数据以职位代码+年份+省份+考试类型为键，没有代码的结果无法可靠归属
+		if h.PositionCode == "" {
+			continue
+		}
+		histories = append(histories, h)
+	}
+	if err := s.historyRepo.BatchUpsert(histories); err != nil {
+		return 0, err
+	}
+	return len(histories), nil
+}
+
+// =====================================================
+// 职位时间线
+// =====================================================
+
+// GetPositionTimeline 获取职位所在招录批次的公告时间线和已知结果
+func (s *AnnouncementLinkerService) GetPositionTimeline(positionID uint) (*model.PositionTimeline, error) {
+	position, err := s.positionRepo.FindByID(positionID)
+	if err != nil {
+		return nil, ErrPositionNotFound
+	}
+
+	timeline := &model.PositionTimeline{
+		PositionID:     position.ID,
+		PositionCode:   position.PositionCode,
+		PositionName:   position.PositionName,
+		DepartmentName: position.DepartmentName,
+		Events:         []model.PositionTimelineEvent{},
+	}
+
+	links, err := s.campaignRepo.GetPositionLinks(position.ID)
+	if err != nil {
+		return nil, err
+	}
+	mentioned := make(map[uint]bool)
+	for _, l := range links {
+		mentioned[l.AnnouncementID] = true
+	}
+	if position.AnnouncementID != nil {
+		mentioned[*position.AnnouncementID] = true
+	}
+
+	// 职位所属批次：优先取招录公告所在批次，其次取任一关联公告的批次
+	var campaignID *uint
+	candidates := make([]uint, 0, len(links)+1)
+	if position.AnnouncementID != nil {
+		candidates = append(candidates, *position.AnnouncementID)
+	}
+	for _, l := range links {
+		candidates = append(candidates, l.AnnouncementID)
+	}
+	announcements := make([]model.Announcement, 0, len(candidates))
+	seen := make(map[uint]bool)
+	for _, id := range candidates {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		a, err := s.announcementRepo.FindByID(id)
+		if err != nil {
+			continue
+		}
+		if campaignID == nil && a.CampaignID != nil {
+			campaignID = a.CampaignID
+		}
+		announcements = append(announcements, *a)
+	}
+
+	if campaignID != nil {
+		campaign, err := s.campaignRepo.GetByID(*campaignID)
+		if err != nil {
+			return nil, err
+		}
+		timeline.Campaign = campaign
+		if announcements, err = s.announcementRepo.ListByCampaign(*campaignID); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, a := range announcements {
+		annType := model.AnnouncementType(a.AnnouncementType)
+		timeline.Events = append(timeline.Events, model.PositionTimelineEvent{
+			AnnouncementID:   a.ID,
+			Title:            a.Title,
+			AnnouncementType: a.AnnouncementType,
+			Stage:            string(annType.Stage()),
+			PublishDate:      a.PublishDate,
+			SourceURL:        a.SourceURL,
+			Mentioned:        mentioned[a.ID],
+		})
+	}
+
+	if timeline.Campaign != nil && position.PositionCode != "" {
+		history, err := s.historyRepo.GetByCodeAndYear(position.PositionCode, timeline.Campaign.Year, timeline.Campaign.Province, timeline.Campaign.ExamType)
+		if err != nil {
+			return nil, err
+		}
+		if history != nil {
+			timeline.Outcome = history.ToResponse()
+		}
+	}
+	return timeline, nil
+}
+
+// =====================================================
+// 工具函数
+// =====================================================
+
+// announcementText 公告正文的纯文本，正文为 HTML 时去掉标签
+func announcementText(content string) string {
+	if !strings.Contains(content, "<") {
+		return content
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		return content
+	}
+	return strings.Join(strings.Fields(doc.Text()), " ")
+}
+
+var scoreValuePattern = regexp.MustCompile(`\d+(?:\.\d+)?`)
+
+// parseScore 从单元格中解析分数，无法解析返回 0
+func parseScore(text string) float64 {
+	m := scoreValuePattern.FindString(text)
+	if m == "" {
+		return 0
+	}
+	v, err := strconv.ParseFloat(m, 64)
+	if err != nil || v <= 0 || v > 1000 {
+		return 0
+	}
+	return math.Round(v*100) / 100
+}
+
+// minPositive 两个分数中较小的正数
+func minPositive(a, b float64) float64 {
+	if a <= 0 {
+		return b
+	}
+	if b <= 0 {
+		return a
+	}
+	return math.Min(a, b)
+}
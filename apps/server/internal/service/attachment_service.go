@@ -0,0 +1,365 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/what-cse/server/internal/model"
+	"github.com/what-cse/server/internal/parser"
+	"github.com/what-cse/server/internal/repository"
+	"github.com/what-cse/server/internal/storage"
+)
+
+var (
+	ErrAttachmentReparsing = errors.New("附件重新解析正在进行中")
+	ErrAttachmentEmpty     = errors.New("附件内容为空")
+)
+
+// attachmentReparseBatch 重新解析时每批读取的附件数
+const attachmentReparseBatch = 50
+
+// AttachmentFetch 一次附件抓取
+type AttachmentFetch struct {
+	URL                 string
+	Name                string // 链接文字或文件名
+	TypeHint            string // 页面上识别的类型 pdf/excel/word，扩展名缺失时使用
+	ContentType         string // 响应头 Content-Type
+	Data                []byte
+	AnnouncementID      *uint
+	FenbiAnnouncementID *uint
+	FetchedAt           time.Time
+}
+
+// AttachmentReparseOptions 重新解析参数
+type AttachmentReparseOptions struct {
+	FileType string // 附件类型，空表示全部可解析类型
+	All      bool   // 包括已用当前解析器版本解析过的附件
+	Limit    int    // 最多处理的附件数，0 不限
+	DryRun   bool   // 只解析不写入职位
+}
+
+// AttachmentReparseResult 重新解析结果
+type AttachmentReparseResult struct {
+	ParserVersion string   `json:"parser_version"`
+	Attachments   int      `json:"attachments"`
+	Parsed        int      `json:"parsed"`
+	Failed        int      `json:"failed"`
+	Positions     int      `json:"positions"`
+	Created       int      `json:"created"`
+	Updated       int      `json:"updated"`
+	Errors        []string `json:"errors,omitempty"`
+	DurationMs    int64    `json:"duration_ms"`
+}
+
+// AttachmentService 附件归档
+// 下载的公告附件按内容 SHA-256 存入本地磁盘或 S3 兼容存储，相同文件只存一份，
+// 每次抓取记录一条来源（URL、时间、关联公告），解析器升级后可整批重新解析
+type AttachmentService struct {
+	repo          *repository.AttachmentRepository
+	store         storage.BlobStore
+	importService *PositionImportService
+	logger        *zap.Logger
+
+	reparseMu sync.Mutex
+}
+
+// NewAttachmentService 创建附件归档服务
+func NewAttachmentService(repo *repository.AttachmentRepository, store storage.BlobStore, logger *zap.Logger) *AttachmentService {
+	return &AttachmentService{
+		repo:   repo,
+		store:  store,
+		logger: logger,
+	}
+}
+
+// SetImportService 设置职位表导入服务（重新解析需要）
+func (s *AttachmentService) SetImportService(importService *PositionImportService) {
+	s.importService = importService
+}
+
+// Archive 归档一次抓取的附件，内容相同的文件复用已有记录，只追加来源
+func (s *AttachmentService) Archive(ctx context.Context, fetch *AttachmentFetch) (*model.Attachment, error) {
+	if len(fetch.Data) == 0 {
+		return nil, ErrAttachmentEmpty
+	}
+	if fetch.FetchedAt.IsZero() {
+		fetch.FetchedAt = time.Now()
+	}
+	sha := storage.SHA256(fetch.Data)
+	key := storage.Key(sha)
+
+	attachment, err := s.repo.GetBySHA256(sha)
+	if err != nil {
+		return nil, err
+	}
+	if attachment == nil {
+		mimeType := attachmentMimeType(fetch.ContentType, fetch.Data)
+		fileType, ext := attachmentFileType(fetch.URL, fetch.Name, fetch.TypeHint, mimeType)
+		if err := s.store.Put(ctx, key, fetch.Data, mimeType); err != nil {
+			return nil, fmt.Errorf("store attachment: %w", err)
+		}
+		attachment = &model.Attachment{
+			SHA256:         sha,
+			Size:           int64(len(fetch.Data)),
+			MimeType:       mimeType,
+			FileType:       fileType,
+			Ext:            ext,
+			StorageBackend: s.store.Backend(),
+			StorageKey:     key,
+		}
+		if err := s.repo.Create(attachment); err != nil {
+			// 并发抓取到同一文件时以先写入的记录为准
+			existing, getErr := s.repo.GetBySHA256(sha)
+			if getErr != nil || existing == nil {
+				return nil, err
+			}
+			attachment = existing
+		}
+	} else if attachment.StorageBackend != s.store.Backend() || attachment.StorageKey != key {
+		s.logger.Warn("Attachment stored in another backend",
+			zap.Uint("attachment_id", attachment.ID),
+			zap.String("backend", attachment.StorageBackend),
+		)
+	} else if ok, err := s.store.Exists(ctx, key); err == nil && !ok {
+		// 存储被清理过，用这次下载的内容补回
+		if err := s.store.Put(ctx, key, fetch.Data, attachment.MimeType); err != nil {
+			return nil, fmt.Errorf("store attachment: %w", err)
+		}
+	}
+
+	source := &model.AttachmentSource{
+		AttachmentID:        attachment.ID,
+		URL:                 fetch.URL,
+		Name:                truncateRunes(fetch.Name, 255),
+		AnnouncementID:      fetch.AnnouncementID,
+		FenbiAnnouncementID: fetch.FenbiAnnouncementID,
+		FetchedAt:           fetch.FetchedAt,
+	}
+	if err := s.repo.AddSource(source); err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// Load 读取附件内容
+func (s *AttachmentService) Load(ctx context.Context, attachment *model.Attachment) ([]byte, error) {
+	if attachment.StorageBackend != "" && attachment.StorageBackend != s.store.Backend() {
+		return nil, fmt.Errorf("附件保存在 %s 存储，当前配置为 %s", attachment.StorageBackend, s.store.Backend())
+	}
+	return s.store.Get(ctx, attachment.StorageKey)
+}
+
+// LinkFenbiAnnouncement 公告保存后把附件来源关联到粉笔公告
+func (s *AttachmentService) LinkFenbiAnnouncement(attachmentID uint, url string, fenbiAnnouncementID uint) error {
+	return s.repo.LinkFenbiAnnouncement(attachmentID, url, fenbiAnnouncementID)
+}
+
+// Reparse 用当前解析器版本重新解析某类附件，解析出的职位覆盖已有的同一职位
+func (s *AttachmentService) Reparse(ctx context.Context, opts AttachmentReparseOptions) (*AttachmentReparseResult, error) {
+	if s.importService == nil {
+		return nil, errors.New("职位表导入服务未配置")
+	}
+	if !s.reparseMu.TryLock() {
+		return nil, ErrAttachmentReparsing
+	}
+	defer s.reparseMu.Unlock()
+
+	start := time.Now()
+	result := &AttachmentReparseResult{ParserVersion: parser.Version}
+	staleVersion := parser.Version
+	if opts.All {
+		staleVersion = ""
+	}
+
+	tmpDir, err := os.MkdirTemp("", "attachment_reparse_*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	var afterID uint
+	for opts.Limit <= 0 || result.Attachments < opts.Limit {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		batch := attachmentReparseBatch
+		if opts.Limit > 0 && opts.Limit-result.Attachments < batch {
+			batch = opts.Limit - result.Attachments
+		}
+		attachments, err := s.repo.ListForReparse(opts.FileType, staleVersion, afterID, batch)
+		if err != nil {
+			return result, err
+		}
+		if len(attachments) == 0 {
+			break
+		}
+		for i := range attachments {
+			attachment := &attachments[i]
+			afterID = attachment.ID
+			if !attachmentParsable(attachment.FileType) {
+				continue
+			}
+			result.Attachments++
+
+			imported, err := s.reparseOne(ctx, attachment, tmpDir, opts.DryRun)
+			if err != nil {
+				result.Failed++
+				result.Errors = append(result.Errors, fmt.Sprintf("#%d: %v", attachment.ID, err))
+				s.logger.Warn("Failed to reparse attachment", zap.Uint("attachment_id", attachment.ID), zap.Error(err))
+			} else {
+				result.Parsed++
+				result.Positions += imported.Total
+				result.Created += imported.Created
+				result.Updated += imported.Updated
+			}
+			if opts.DryRun {
+				continue
+			}
+			positions, parseErr := 0, ""
+			if err != nil {
+				parseErr = err.Error()
+			} else {
+				positions = imported.Total
+			}
+			if err := s.repo.MarkParsed(attachment.ID, parser.Version, positions, parseErr, time.Now()); err != nil {
+				s.logger.Warn("Failed to record attachment parse", zap.Uint("attachment_id", attachment.ID), zap.Error(err))
+			}
+		}
+	}
+
+	result.DurationMs = time.Since(start).Milliseconds()
+	s.logger.Info("Attachment reparse finished",
+		zap.String("file_type", opts.FileType),
+		zap.String("parser_version", parser.Version),
+		zap.Int("attachments", result.Attachments),
+		zap.Int("parsed", result.Parsed),
+		zap.Int("failed", result.Failed),
+		zap.Int("created", result.Created),
+		zap.Int("updated", result.Updated),
+	)
+	return result, nil
+}
+
+// reparseOne 还原单个附件文件并按职位表导入
+func (s *AttachmentService) reparseOne(ctx context.Context, attachment *model.Attachment, tmpDir string, dryRun bool) (*PositionImportResult, error) {
+	data, err := s.Load(ctx, attachment)
+	if err != nil {
+		return nil, err
+	}
+	fileName := attachment.SHA256 + attachment.Ext
+	filePath := filepath.Join(tmpDir, fileName)
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return nil, err
+	}
+	defer os.Remove(filePath)
+
+	sources, err := s.repo.GetSources(attachment.ID)
+	if err != nil {
+		return nil, err
+	}
+	req := &PositionFileRequest{
+		FilePath:     filePath,
+		FileName:     fileName,
+		AttachmentID: &attachment.ID,
+		Overwrite:    true,
+	}
+	// 优先使用关联了公告的来源，职位写回对应公告
+	for i, source := range sources {
+		if i == 0 || source.AnnouncementID != nil || source.FenbiAnnouncementID != nil {
+			req.SourceURL = source.URL
+			req.AnnouncementID = source.AnnouncementID
+			req.FenbiAnnouncementID = source.FenbiAnnouncementID
+		}
+		if req.AnnouncementID != nil || req.FenbiAnnouncementID != nil {
+			break
+		}
+	}
+
+	if dryRun {
+		preview, err := s.importService.Preview(req)
+		if err != nil {
+			return nil, err
+		}
+		return &PositionImportResult{PositionFilePreview: *preview}, nil
+	}
+	return s.importService.Import(req)
+}
+
+// attachmentParsable 可按职位表解析的附件类型
+func attachmentParsable(fileType string) bool {
+	switch fileType {
+	case model.AttachmentTypePDF, model.AttachmentTypeExcel, model.AttachmentTypeWord, model.AttachmentTypeHTML:
+		return true
+	}
+	return false
+}
+
+// attachmentMimeType 优先使用响应头，缺失或为通用二进制类型时按内容识别
+func attachmentMimeType(contentType string, data []byte) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType != "application/octet-stream" {
+		return mediaType
+	}
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	return mediaType
+}
+
+// attachmentFileType 根据 URL、文件名、页面类型和 MIME 判断附件类型和扩展名
+func attachmentFileType(rawURL, name, typeHint, mimeType string) (string, string) {
+	var candidates []string
+	if u, err := url.Parse(rawURL); err == nil {
+		candidates = append(candidates, path.Ext(u.Path))
+	}
+	candidates = append(candidates, filepath.Ext(name))
+	for _, ext := range candidates {
+		ext = strings.ToLower(ext)
+		if fileType := attachmentTypeByExt(ext); fileType != model.AttachmentTypeOther {
+			return fileType, ext
+		}
+	}
+
+	switch {
+	case mimeType == "application/pdf":
+		return model.AttachmentTypePDF, ".pdf"
+	case strings.Contains(mimeType, "spreadsheet") || strings.Contains(mimeType, "ms-excel"):
+		return model.AttachmentTypeExcel, ".xlsx"
+	case strings.Contains(mimeType, "wordprocessing") || mimeType == "application/msword":
+		return model.AttachmentTypeWord, ".docx"
+	case mimeType == "text/html":
+		return model.AttachmentTypeHTML, ".html"
+	}
+	switch typeHint {
+	case model.AttachmentTypePDF:
+		return model.AttachmentTypePDF, ".pdf"
+	case model.AttachmentTypeExcel:
+		return model.AttachmentTypeExcel, ".xlsx"
+	case model.AttachmentTypeWord:
+		return model.AttachmentTypeWord, ".docx"
+	}
+	return model.AttachmentTypeOther, strings.ToLower(candidates[0])
+}
+
+func attachmentTypeByExt(ext string) string {
+	switch ext {
+	case ".pdf":
+		return model.AttachmentTypePDF
+	case ".xlsx", ".xls", ".xlsm", ".et":
+		return model.AttachmentTypeExcel
+	case ".docx", ".doc":
+		return model.AttachmentTypeWord
+	case ".html", ".htm":
+		return model.AttachmentTypeHTML
+	}
+	return model.AttachmentTypeOther
+}
@@ -12,7 +12,8 @@ import (
 
 // CompareService 职位对比服务
 type CompareService struct {
-	positionRepo *repository.PositionRepository
+	positionRepo    *repository.PositionRepository
+	forecastService *RegistrationForecastService
 }
 
 // NewCompareService 创建对比服务
@@ -22,6 +23,11 @@ func NewCompareService(positionRepo *repository.PositionRepository) *CompareServ
 	}
 }
 
+// SetForecastService 设置报名预测服务（用于对比预测竞争比）
+func (s *CompareService) SetForecastService(forecastService *RegistrationForecastService) {
+	s.forecastService = forecastService
+}
+
 // =====================================================
 // 数据结构定义
 // =====================================================
@@ -32,6 +38,8 @@ type CompareItem struct {
 	DaysUntilEnd    *int            `json:"days_until_end,omitempty"`    // 距离报名截止天数
 	IsRegistering   bool            `json:"is_registering"`              // 是否正在报名中
 	FormattedRegEnd string          `json:"formatted_reg_end,omitempty"` // 格式化的报名截止日期
+
+	Forecast *model.CompetitionForecast `json:"forecast,omitempty"` // 最终竞争比预测（报名期内）
 }
 
 // CompareResponse 对比响应
@@ -49,6 +57,7 @@ type Recommendation struct {
 	LowestRequirement *PositionRecommend `json:"lowest_requirement,omitempty"`  // 条件最宽松
 	SoonestDeadline   *PositionRecommend `json:"soonest_deadline,omitempty"`    // 报名即将截止
 	LowestCompetition *PositionRecommend `json:"lowest_competition,omitempty"`  // 竞争最小
+	LowestForecast    *PositionRecommend `json:"lowest_forecast,omitempty"`     // 预测最终竞争最小
 }
 
 // PositionRecommend 职位推荐
@@ -153,6 +162,12 @@ func (s *CompareService) GetCompareItems(ids []uint) ([]*CompareItem, error) {
 			}
 		}
 
+		if s.forecastService != nil && item.IsRegistering {
+			if forecast, err := s.forecastService.ForecastPosition(pos); err == nil {
+				item.Forecast = forecast
+			}
+		}
+
 		items = append(items, item)
 	}
 
@@ -279,6 +294,25 @@ func (s *CompareService) CalculateRecommendation(items []*CompareItem) *Recommen
 		}
 	}
 
+	// 6. 预测最终竞争最小（报名期内的当前竞争比会误导）
+	var lowestForecast *CompareItem
+	for _, item := range items {
+		if item.Forecast != nil && item.Forecast.ForecastRatio > 0 {
+			if lowestForecast == nil || item.Forecast.ForecastRatio < lowestForecast.Forecast.ForecastRatio {
+				lowestForecast = item
+			}
+		}
+	}
+	if lowestForecast != nil {
+		rec.LowestForecast = &PositionRecommend{
+			PositionID:   lowestForecast.Position.ID,
+			PositionName: lowestForecast.Position.PositionName,
+			Reason:       "预测最终竞争比最低",
+			Value: fmt.Sprintf("%.1f:1（%.1f-%.1f）", lowestForecast.Forecast.ForecastRatio,
+				lowestForecast.Forecast.ForecastRatioLow, lowestForecast.Forecast.ForecastRatioHigh),
+		}
+	}
+
 	return rec
 }
 
@@ -309,6 +343,7 @@ func (s *CompareService) buildCompareDimensions(items []*CompareItem) []*Compare
 		{"registration_end", "报名截止", "date"},
 		{"exam_date", "笔试时间", "date"},
 		{"competition_ratio", "竞争比", "number"},
+		{"forecast_ratio", "预测最终竞争比", "number"},
 	}
 
 	for _, def := range dimensionDefs {
@@ -323,6 +358,9 @@ func (s *CompareService) buildCompareDimensions(items []*CompareItem) []*Compare
 
 		for _, item := range items {
 			val, display := s.getPositionFieldValue(item.Position, def.Key, def.Type)
+			if def.Key == "forecast_ratio" {
+				val, display = s.getForecastValue(item)
+			}
 			dimVal := &DimensionValue{
 				PositionID: item.Position.ID,
 				Value:      val,
@@ -398,6 +436,15 @@ func (s *CompareService) getPositionFieldValue(p *model.Position, key string, fi
 	}
 }
 
+// getForecastValue 获取预测竞争比值
+func (s *CompareService) getForecastValue(item *CompareItem) (interface{}, string) {
+	if item.Forecast == nil || item.Forecast.RecruitCount <= 0 {
+		return nil, "暂无"
+	}
+	f := item.Forecast
+	return f.ForecastRatio, fmt.Sprintf("%.1f:1（%.1f-%.1f）", f.ForecastRatio, f.ForecastRatioLow, f.ForecastRatioHigh)
+}
+
 // formatStringValue 格式化字符串值
 func (s *CompareService) formatStringValue(val string) string {
 	if val == "" {
@@ -437,7 +484,7 @@ func (s *CompareService) markBestValue(dim *CompareDimension, items []*CompareIt
 			dim.Values[maxIdx].IsBest = true
 			dim.BestValueID = &dim.Values[maxIdx].PositionID
 		}
-	case "competition_ratio":
+	case "competition_ratio", "forecast_ratio":
 		// 竞争比越低越好
 		var minIdx int
 		var minVal float64 = 999999
@@ -533,6 +580,13 @@ func (s *CompareService) generateCompareSummary(items []*CompareItem, rec *Recom
 		))
 	}
 
+	if rec.LowestForecast != nil {
+		summary.Suggestions = append(summary.Suggestions, fmt.Sprintf(
+			"「%s」预测最终竞争比最低（%s），当前竞争比仅供参考",
+			rec.LowestForecast.PositionName, rec.LowestForecast.Value,
+		))
+	}
+
 	if rec.LowestRequirement != nil {
 		summary.Suggestions = append(summary.Suggestions, fmt.Sprintf(
 			"「%s」报考条件较宽松，适合更多考生报考",
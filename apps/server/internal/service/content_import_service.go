@@ -29,6 +29,7 @@ type ContentImportService struct {
 	questionRepo  *repository.QuestionRepository
 	materialRepo  *repository.MaterialRepository
 	aiContentRepo *repository.AIContentRepository
+	dedupService  *QuestionDedupService
 	logger        *zap.Logger
 }
 
@@ -55,6 +56,11 @@ func NewContentImportService(
 	}
 }
 
+// SetDedupService 设置题目查重服务
+func (s *ContentImportService) SetDedupService(dedupService *QuestionDedupService) {
+	s.dedupService = dedupService
+}
+
 // =====================================================
 // 课程内容导入
 // =====================================================
@@ -449,10 +455,12 @@ func (s *ContentImportService) buildCourseModules(chapterID uint, content *model
 
 // ImportQuestionBatchResult 导入题目批次结果
 type ImportQuestionBatchResult struct {
-	CategoryID       uint   `json:"category_id"`
-	QuestionsCreated int    `json:"questions_created"`
-	Success          bool   `json:"success"`
-	Error            string `json:"error,omitempty"`
+	CategoryID        uint   `json:"category_id"`
+	QuestionsCreated  int    `json:"questions_created"`
+	QuestionsRejected int    `json:"questions_rejected"` // 与已有题目高度重复被拒绝
+	QuestionsFlagged  int    `json:"questions_flagged"`  // 疑似重复，已导入待审核
+	Success           bool   `json:"success"`
+	Error             string `json:"error,omitempty"`
 }
 
 // ImportQuestionBatch 导入生成的题目批次到数据库
@@ -511,11 +519,16 @@ func (s *ContentImportService) ImportQuestionBatch(ctx context.Context, category
 	}
 
 	if len(questions) > 0 {
-		if err := s.db.Create(&questions).Error; err != nil {
+		report, err := s.dedupService.ImportQuestions(questions, model.QuestionDuplicateSourceLLM, func(kept []model.Question) error {
+			return s.db.Create(&kept).Error
+		})
+		if err != nil {
 			result.Error = fmt.Sprintf("创建题目失败: %v", err)
 			return result, fmt.Errorf("%w: %v", ErrContentImportFailed, err)
 		}
-		result.QuestionsCreated = len(questions)
+		result.QuestionsCreated = report.Created
+		result.QuestionsRejected = report.Rejected
+		result.QuestionsFlagged = report.Flagged
 	}
 
 	result.Success = true
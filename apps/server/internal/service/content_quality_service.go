@@ -17,6 +17,9 @@ type ContentQualityService struct {
 	db           *gorm.DB
 	questionRepo *repository.QuestionRepository
 	courseRepo   *repository.CourseRepository
+
+	embeddingService *EmbeddingService
+	dedupService     *QuestionDedupService
 }
 
 // NewContentQualityService 创建内容质量检查服务
@@ -32,6 +35,16 @@ func NewContentQualityService(
 	}
 }
 
+// SetEmbeddingService 设置向量化服务，设置后重复检测使用语义相似度
+func (s *ContentQualityService) SetEmbeddingService(embeddingService *EmbeddingService) {
+	s.embeddingService = embeddingService
+}
+
+// SetDedupService 设置题目 MinHash 查重服务，向量索引未就绪时用于题目重复检测
+func (s *ContentQualityService) SetDedupService(dedupService *QuestionDedupService) {
+	s.dedupService = dedupService
+}
+
 // =====================================================
 // 质量检查结果模型
 // =====================================================
@@ -295,6 +308,19 @@ func (s *ContentQualityService) runFormatCheck(req *QualityCheckRequest) Quality
 // =====================================================
 
 func (s *ContentQualityService) runDuplicateCheck(req *QualityCheckRequest) QualityCheckTaskResult {
+	if s.embeddingService != nil {
+		targetType := model.EmbeddingEntityQuestion
+		if req.TargetType == model.EmbeddingEntityMaterial {
+			targetType = model.EmbeddingEntityMaterial
+		}
+		if s.embeddingService.Ready(targetType) {
+			return s.runSemanticDuplicateCheck(targetType, req.Limit)
+		}
+	}
+	if s.dedupService != nil && req.TargetType != model.EmbeddingEntityMaterial {
+		return s.runMinHashDuplicateCheck(req.Limit)
+	}
+
 	result := QualityCheckTaskResult{}
 
 	limit := 500
@@ -329,6 +355,70 @@ func (s *ContentQualityService) runDuplicateCheck(req *QualityCheckRequest) Qual
 	return result
 }
 
+// runSemanticDuplicateCheck 基于向量余弦相似度的全量重复检测，可识别改写、换序的重复内容
+func (s *ContentQualityService) runSemanticDuplicateCheck(targetType string, limit int) QualityCheckTaskResult {
+	result := QualityCheckTaskResult{}
+
+	pairs, total, err := s.embeddingService.FindDuplicates(targetType, 0, limit)
+	if err != nil {
+		return result
+	}
+	result.TotalChecked = total
+
+	targetName := "题目"
+	if targetType == model.EmbeddingEntityMaterial {
+		targetName = "素材"
+	}
+	for _, pair := range pairs {
+		severity := "warning"
+		if pair.Score >= 0.99 {
+			severity = "error"
+		}
+		s.saveQualityResult(&QualityCheckResult{
+			CheckType:  "duplicate",
+			TargetType: targetType,
+			TargetID:   pair.ID,
+			Severity:   severity,
+			Message:    fmt.Sprintf("与%s #%d 语义高度相似 (%.1f%%)", targetName, pair.OtherID, pair.Score*100),
+			Suggestion: "请检查是否为重复或改写内容",
+		})
+		result.IssuesFound++
+		if severity == "error" {
+			result.ErrorCount++
+		} else {
+			result.WarningCount++
+		}
+	}
+
+	return result
+}
+
+// runMinHashDuplicateCheck 汇总 MinHash 查重发现的待审核题对
+func (s *ContentQualityService) runMinHashDuplicateCheck(limit int) QualityCheckTaskResult {
+	result := QualityCheckTaskResult{}
+
+	pairs, signed, err := s.dedupService.PendingPairs(limit)
+	if err != nil {
+		return result
+	}
+	result.TotalChecked = int(signed)
+
+	for _, pair := range pairs {
+		s.saveQualityResult(&QualityCheckResult{
+			CheckType:  "duplicate",
+			TargetType: "question",
+			TargetID:   pair.QuestionID,
+			Severity:   "warning",
+			Message:    fmt.Sprintf("与题目 #%d 高度相似 (%.1f%%)", pair.DuplicateOfID, pair.Similarity*100),
+			Suggestion: "请在疑似重复题列表中合并或忽略",
+		})
+		result.IssuesFound++
+		result.WarningCount++
+	}
+
+	return result
+}
+
 // =====================================================
 // 知识点覆盖度分析
 // =====================================================
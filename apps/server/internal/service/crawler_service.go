@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/hibiken/asynq"
@@ -18,6 +19,7 @@ var (
 	ErrListPageNotFound = errors.New("list page not found")
 	ErrListPageExists   = errors.New("list page with this URL already exists")
 	ErrTaskNotFound     = errors.New("task not found")
+	ErrInvalidAdapter   = errors.New("invalid site adapter")
 )
 
 type CrawlerService struct {
@@ -26,6 +28,8 @@ type CrawlerService struct {
 	crawlLogRepo  *repository.CrawlLogRepository
 	scheduler     *scheduler.Scheduler
 	spiderConfig  *crawler.SpiderConfig
+	crawlURLRepo  *repository.CrawlURLRepository
+	urlStore      crawler.URLStore
 	logger        *zap.Logger
 }
 
@@ -54,6 +58,64 @@ func NewCrawlerServiceSimple(listPageRepo *repository.ListPageRepository) *Crawl
 	}
 }
 
+// SetSpider 设置爬虫配置与 URL 库；监控任务通过 URL 库跨重启记录已发现的文章并发送条件请求，测试抓取不写入
+func (s *CrawlerService) SetSpider(spiderConfig *crawler.SpiderConfig, crawlURLRepo *repository.CrawlURLRepository, logger *zap.Logger) {
+	s.spiderConfig = spiderConfig
+	s.crawlURLRepo = crawlURLRepo
+	s.logger = logger
+	if crawlURLRepo != nil {
+		s.urlStore = NewCrawlURLStore(crawlURLRepo)
+	}
+}
+
+// CrawlURLStore 将爬虫 URL 库适配为 crawler.URLStore
+type CrawlURLStore struct {
+	repo *repository.CrawlURLRepository
+}
+
+// NewCrawlURLStore 创建爬虫 URL 库适配器
+func NewCrawlURLStore(repo *repository.CrawlURLRepository) *CrawlURLStore {
+	return &CrawlURLStore{repo: repo}
+}
+
+// GetURL implements crawler.URLStore
+func (a *CrawlURLStore) GetURL(urlHash string) (*crawler.URLRecord, error) {
+	record, err := a.repo.GetByHash(urlHash)
+	if err != nil || record == nil {
+		return nil, err
+	}
+	return &crawler.URLRecord{
+		URLHash:      record.URLHash,
+		URL:          record.URL,
+		Domain:       record.Domain,
+		Status:       record.Status,
+		StatusCode:   record.StatusCode,
+		ContentHash:  record.ContentHash,
+		ETag:         record.ETag,
+		LastModified: record.LastModified,
+		FailCount:    record.FailCount,
+		LastError:    record.LastError,
+		FetchedAt:    record.FetchedAt,
+	}, nil
+}
+
+// SaveURL implements crawler.URLStore
+func (a *CrawlURLStore) SaveURL(record *crawler.URLRecord) error {
+	return a.repo.Save(&model.CrawlURL{
+		URLHash:      record.URLHash,
+		URL:          record.URL,
+		Domain:       record.Domain,
+		Status:       record.Status,
+		StatusCode:   record.StatusCode,
+		ContentHash:  record.ContentHash,
+		ETag:         record.ETag,
+		LastModified: record.LastModified,
+		FailCount:    record.FailCount,
+		LastError:    record.LastError,
+		FetchedAt:    record.FetchedAt,
+	})
+}
+
 type CrawlerTaskStatus struct {
 	TaskID    string     `json:"task_id"`
 	Status    string     `json:"status"` // pending, running, completed, failed
@@ -76,6 +138,7 @@ type CreateListPageRequest struct {
 	CrawlFrequency    string `json:"crawl_frequency"`
 	ArticleSelector   string `json:"article_selector"`
 	PaginationPattern string `json:"pagination_pattern"`
+	Adapter           string `json:"adapter"`
 }
 
 type UpdateListPageRequest struct {
@@ -84,9 +147,20 @@ type UpdateListPageRequest struct {
 	CrawlFrequency    *string `json:"crawl_frequency,omitempty"`
 	ArticleSelector   *string `json:"article_selector,omitempty"`
 	PaginationPattern *string `json:"pagination_pattern,omitempty"`
+	Adapter           *string `json:"adapter,omitempty"`
 	Status            *string `json:"status,omitempty"`
 }
 
+// AdapterDryRunRequest runs a site adapter without saving anything. With a
+// list page ID, its URL and stored adapter are used unless given here.
+type AdapterDryRunRequest struct {
+	ListPageID    *uint  `json:"list_page_id,omitempty"`
+	URL           string `json:"url"`
+	Adapter       string `json:"adapter"`
+	MaxPages      int    `json:"max_pages"`
+	DetailSamples int    `json:"detail_samples"`
+}
+
 type TriggerCrawlerRequest struct {
 	ListPageID *uint  `json:"list_page_id,omitempty"`
 	SpiderType string `json:"spider_type"` // list_monitor, announcement, position
@@ -105,6 +179,9 @@ func (s *CrawlerService) CreateListPage(req *CreateListPageRequest) (*model.List
 	if existing != nil {
 		return nil, ErrListPageExists
 	}
+	if _, err := parseAdapter(req.Adapter); err != nil {
+		return nil, err
+	}
 
 	listPage := &model.ListPage{
 		URL:               req.URL,
@@ -113,6 +190,7 @@ func (s *CrawlerService) CreateListPage(req *CreateListPageRequest) (*model.List
 		CrawlFrequency:    req.CrawlFrequency,
 		ArticleSelector:   req.ArticleSelector,
 		PaginationPattern: req.PaginationPattern,
+		Adapter:           req.Adapter,
 		Status:            string(model.ListPageStatusActive),
 	}
 
@@ -148,6 +226,12 @@ func (s *CrawlerService) UpdateListPage(id uint, req *UpdateListPageRequest) (*m
 	if req.PaginationPattern != nil {
 		listPage.PaginationPattern = *req.PaginationPattern
 	}
+	if req.Adapter != nil {
+		if _, err := parseAdapter(*req.Adapter); err != nil {
+			return nil, err
+		}
+		listPage.Adapter = *req.Adapter
+	}
 	if req.Status != nil {
 		listPage.Status = *req.Status
 	}
@@ -208,6 +292,18 @@ func (s *CrawlerService) TriggerCrawler(req *TriggerCrawlerRequest) (*CrawlerTas
 		}
 	}
 
+	// 未启用任务队列时列表页监控在进程内执行
+	if s.scheduler == nil && req.SpiderType == "list_monitor" {
+		var ids []uint
+		if req.ListPageID != nil {
+			ids = []uint{*req.ListPageID}
+		}
+		taskStatus.Status = string(model.CrawlTaskStatusRunning)
+		taskStatus.Message = "Crawler task started"
+		go s.runListMonitor(taskID, ids)
+		return taskStatus, nil
+	}
+
 	// Enqueue task if scheduler is available
 	if s.scheduler != nil {
 		var asynqTask *asynq.Task
@@ -243,6 +339,84 @@ func (s *CrawlerService) TriggerCrawler(req *TriggerCrawlerRequest) (*CrawlerTas
 	return taskStatus, nil
 }
 
+// maxMonitorListPages 进程内监控单次最多处理的列表页数
+const maxMonitorListPages = 500
+
+// runListMonitor 在进程内运行列表页监控，爬虫挂载持久化 URL 库
+func (s *CrawlerService) runListMonitor(taskID string, ids []uint) {
+	var listPages []model.ListPage
+	if len(ids) > 0 {
+		for _, id := range ids {
+			if listPage, err := s.listPageRepo.FindByID(id); err == nil {
+				listPages = append(listPages, *listPage)
+			}
+		}
+	} else {
+		var err error
+		listPages, _, err = s.listPageRepo.List(string(model.ListPageStatusActive), 1, maxMonitorListPages)
+		if err != nil {
+			s.finishListMonitor(taskID, nil, err)
+			return
+		}
+	}
+
+	pages := make([]crawler.ListPage, 0, len(listPages))
+	for i := range listPages {
+		page, err := toCrawlerListPage(&listPages[i])
+		if err != nil {
+			s.logger.Warn("Skipping list page with invalid adapter", zap.Uint("id", listPages[i].ID), zap.Error(err))
+			continue
+		}
+		pages = append(pages, page)
+	}
+	if len(pages) == 0 {
+		s.finishListMonitor(taskID, &crawler.CrawlResult{}, nil)
+		return
+	}
+
+	spider := crawler.NewListMonitorSpider(s.spiderConfig, s.logger)
+	if s.urlStore != nil {
+		spider.SetURLStore(s.urlStore)
+	}
+	spider.SetListPages(pages)
+
+	result, err := spider.Run()
+	if err == nil {
+		now := time.Now()
+		for i := range listPages {
+			listPages[i].LastCrawlTime = &now
+			if updateErr := s.listPageRepo.Update(&listPages[i]); updateErr != nil {
+				s.logger.Warn("Failed to update list page crawl time", zap.Uint("id", listPages[i].ID), zap.Error(updateErr))
+			}
+		}
+	}
+	s.finishListMonitor(taskID, result, err)
+}
+
+// finishListMonitor 记录进程内监控任务的结果
+func (s *CrawlerService) finishListMonitor(taskID string, result *crawler.CrawlResult, err error) {
+	if err != nil {
+		s.logger.Error("List monitor failed", zap.String("task_id", taskID), zap.Error(err))
+		if s.crawlTaskRepo != nil {
+			s.crawlTaskRepo.UpdateStatus(taskID, string(model.CrawlTaskStatusFailed), nil, err.Error())
+		}
+		return
+	}
+
+	s.logger.Info("List monitor completed",
+		zap.String("task_id", taskID),
+		zap.Int("total_found", result.TotalFound),
+		zap.Int("new_articles", result.NewArticles),
+	)
+	if s.crawlTaskRepo != nil {
+		s.crawlTaskRepo.UpdateStatus(taskID, string(model.CrawlTaskStatusCompleted), model.JSON{
+			"total_found":   result.TotalFound,
+			"new_articles":  result.NewArticles,
+			"request_count": result.RequestCount,
+		}, "")
+	}
+}
+
 func (s *CrawlerService) GetCrawlerTaskStatus(taskID string) (*CrawlerTaskStatus, error) {
 	if s.crawlTaskRepo != nil {
 		task, err := s.crawlTaskRepo.FindByTaskID(taskID)
@@ -318,19 +492,14 @@ func (s *CrawlerService) TestListPageCrawl(listPageID uint) (map[string]interfac
 		return nil, ErrListPageNotFound
 	}
 
+	page, err := toCrawlerListPage(listPage)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create spider and do a test crawl
 	spider := crawler.NewListMonitorSpider(s.spiderConfig, s.logger)
-	spider.SetListPages([]crawler.ListPage{
-		{
-			ID:              listPage.ID,
-			URL:             listPage.URL,
-			SourceName:      listPage.SourceName,
-			Category:        listPage.Category,
-			ArticleSelector: listPage.ArticleSelector,
-			PaginationPattern: listPage.PaginationPattern,
-			Status:          listPage.Status,
-		},
-	})
+	spider.SetListPages([]crawler.ListPage{page})
 
 	result, err := spider.Run()
 	if err != nil {
@@ -359,6 +528,70 @@ func (s *CrawlerService) TestListPageCrawl(listPageID uint) (map[string]interfac
 	}, nil
 }
 
+// DryRunAdapter runs a site adapter against its list page and a few detail
+// pages and returns the extracted items
+func (s *CrawlerService) DryRunAdapter(req *AdapterDryRunRequest) (*crawler.AdapterDryRunResult, error) {
+	listURL, definition := req.URL, req.Adapter
+	if req.ListPageID != nil {
+		listPage, err := s.listPageRepo.FindByID(*req.ListPageID)
+		if err != nil {
+			return nil, ErrListPageNotFound
+		}
+		if listURL == "" {
+			listURL = listPage.URL
+		}
+		if definition == "" {
+			definition = listPage.Adapter
+		}
+	}
+	if listURL == "" {
+		return nil, fmt.Errorf("%w: url is required", ErrInvalidAdapter)
+	}
+	if definition == "" {
+		return nil, fmt.Errorf("%w: adapter is required", ErrInvalidAdapter)
+	}
+	adapter, err := parseAdapter(definition)
+	if err != nil {
+		return nil, err
+	}
+
+	detailSamples := req.DetailSamples
+	if detailSamples <= 0 {
+		detailSamples = 3
+	}
+	return crawler.DryRunAdapter(s.spiderConfig, s.logger, adapter, listURL, req.MaxPages, detailSamples)
+}
+
+// parseAdapter parses a site adapter definition; empty means none
+func parseAdapter(definition string) (*crawler.SiteAdapter, error) {
+	if definition == "" {
+		return nil, nil
+	}
+	adapter, err := crawler.ParseSiteAdapter([]byte(definition))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAdapter, err)
+	}
+	return adapter, nil
+}
+
+// toCrawlerListPage converts a list page record for the spider
+func toCrawlerListPage(listPage *model.ListPage) (crawler.ListPage, error) {
+	adapter, err := parseAdapter(listPage.Adapter)
+	if err != nil {
+		return crawler.ListPage{}, err
+	}
+	return crawler.ListPage{
+		ID:                listPage.ID,
+		URL:               listPage.URL,
+		SourceName:        listPage.SourceName,
+		Category:          listPage.Category,
+		ArticleSelector:   listPage.ArticleSelector,
+		PaginationPattern: listPage.PaginationPattern,
+		Status:            listPage.Status,
+		Adapter:           adapter,
+	}, nil
+}
+
 // GetCrawlerStatistics returns comprehensive crawler statistics
 func (s *CrawlerService) GetCrawlerStatistics() (map[string]interface{}, error) {
 	stats := make(map[string]interface{})
@@ -387,6 +620,14 @@ func (s *CrawlerService) GetCrawlerStatistics() (map[string]interface{}, error)
 		}
 	}
 
+	// URL store stats
+	if s.crawlURLRepo != nil {
+		urlByStatus, _ := s.crawlURLRepo.CountByStatus()
+		stats["urls"] = map[string]interface{}{
+			"by_status": urlByStatus,
+		}
+	}
+
 	return stats, nil
 }
 
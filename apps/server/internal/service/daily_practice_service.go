@@ -26,6 +26,8 @@ type DailyPracticeService struct {
 	weakCategoryRepo *repository.UserWeakCategoryRepository
 	questionRepo     *repository.QuestionRepository
 	recordRepo       *repository.UserQuestionRecordRepository
+	irtService       *IRTService
+	masteryService   *KnowledgeMasteryService
 }
 
 func NewDailyPracticeService(
@@ -46,6 +48,16 @@ func NewDailyPracticeService(
 	}
 }
 
+// SetIRTService 设置 IRT 服务（按能力选题）
+func (s *DailyPracticeService) SetIRTService(irtService *IRTService) {
+	s.irtService = irtService
+}
+
+// SetKnowledgeMasteryService 设置知识点掌握度服务（作答后更新知识追踪）
+func (s *DailyPracticeService) SetKnowledgeMasteryService(masteryService *KnowledgeMasteryService) {
+	s.masteryService = masteryService
+}
+
 // =====================================================
 // 每日一练核心功能
 // =====================================================
@@ -137,14 +149,14 @@ func (s *DailyPracticeService) generateDailyQuestions(userID uint, config model.
 		// Get remaining random questions
 		if randomCount > 0 || len(allQuestions) < config.QuestionCount {
 			neededCount := config.QuestionCount - len(allQuestions)
-			randomQuestions, err := s.getRandomQuestions(neededCount, config.DifficultyLevel, recentQuestionIDs)
+			randomQuestions, err := s.getRandomQuestions(userID, neededCount, config.DifficultyLevel, recentQuestionIDs)
 			if err == nil && len(randomQuestions) > 0 {
 				allQuestions = append(allQuestions, randomQuestions...)
 			}
 		}
 	} else {
 		// All random questions
-		randomQuestions, err := s.getRandomQuestions(config.QuestionCount, config.DifficultyLevel, recentQuestionIDs)
+		randomQuestions, err := s.getRandomQuestions(userID, config.QuestionCount, config.DifficultyLevel, recentQuestionIDs)
 		if err != nil {
 			return nil, err
 		}
@@ -208,8 +220,13 @@ func (s *DailyPracticeService) getWeakCategoryQuestions(userID uint, count, diff
 	return questions, nil
 }
 
-// getRandomQuestions 获取随机题目
-func (s *DailyPracticeService) getRandomQuestions(count, difficulty int, excludeIDs []uint) ([]model.Question, error) {
+// getRandomQuestions 获取随机题目，有能力估计时优先选答对概率接近目标的题目
+func (s *DailyPracticeService) getRandomQuestions(userID uint, count, difficulty int, excludeIDs []uint) ([]model.Question, error) {
+	if s.irtService != nil {
+		if questions, err := s.irtService.SelectQuestions(userID, 0, count, excludeIDs); err == nil && len(questions) > 0 {
+			return questions, nil
+		}
+	}
 	return s.questionRepo.GetRandomQuestions(0, count, excludeIDs)
 }
 
@@ -361,6 +378,14 @@ func (s *DailyPracticeService) SubmitAnswer(userID uint, req *model.SubmitDailyA
 		_ = s.weakCategoryRepo.UpdateFromQuestionRecord(userID, question.CategoryID, isCorrect)
 	}
 
+	// Update ability estimate and knowledge mastery
+	if s.irtService != nil {
+		s.irtService.RecordAnswer(userID, question, isCorrect)
+	}
+	if s.masteryService != nil {
+		s.masteryService.RecordAnswer(userID, question, isCorrect)
+	}
+
 	// Return response
 	resp := question.ToDetailResponse()
 	resp.UserAnswer = req.UserAnswer
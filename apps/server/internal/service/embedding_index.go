@@ -0,0 +1,175 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// quantizedVector int8 量化向量，原向量已做 L2 归一化，values[i]·scale ≈ v[i]
+type quantizedVector struct {
+	values []int8
+	scale  float32
+}
+
+// quantizeVector 归一化后按最大绝对值线性量化到 [-127, 127]
+func quantizeVector(v []float32) quantizedVector {
+	var norm float64
+	for _, x := range v {
+		norm += float64(x) * float64(x)
+	}
+	if norm == 0 {
+		return quantizedVector{values: make([]int8, len(v))}
+	}
+	inv := 1 / math.Sqrt(norm)
+
+	var maxAbs float64
+	for _, x := range v {
+		if a := math.Abs(float64(x) * inv); a > maxAbs {
+			maxAbs = a
+		}
+	}
+
+	q := quantizedVector{values: make([]int8, len(v)), scale: float32(maxAbs / 127)}
+	for i, x := range v {
+		q.values[i] = int8(math.Round(float64(x) * inv / maxAbs * 127))
+	}
+	return q
+}
+
+// bytes 序列化为数据库存储格式
+func (q quantizedVector) bytes() []byte {
+	b := make([]byte, len(q.values))
+	for i, v := range q.values {
+		b[i] = byte(v)
+	}
+	return b
+}
+
+func quantizedFromBytes(b []byte, scale float32) quantizedVector {
+	values := make([]int8, len(b))
+	for i, v := range b {
+		values[i] = int8(v)
+	}
+	return quantizedVector{values: values, scale: scale}
+}
+
+// cosine 近似余弦相似度
+func (q quantizedVector) cosine(other quantizedVector) float64 {
+	if len(q.values) != len(other.values) {
+		return 0
+	}
+	var dot int64
+	for i, v := range q.values {
+		dot += int64(v) * int64(other.values[i])
+	}
+	// 量化误差可能使结果略超出 [-1, 1]
+	return math.Max(-1, math.Min(1, float64(dot)*float64(q.scale)*float64(other.scale)))
+}
+
+// vectorEntry 索引条目
+type vectorEntry struct {
+	id         uint
+	categoryID uint
+	hash       string
+	vec        quantizedVector
+}
+
+// ScoredContent 相似内容
+type ScoredContent struct {
+	ID         uint    `json:"id"`
+	CategoryID uint    `json:"category_id"`
+	Score      float64 `json:"score"`
+}
+
+// vectorIndex 进程内暴力检索索引，int8 点积足以支撑十万级条目的毫秒级查询
+type vectorIndex struct {
+	mu      sync.RWMutex
+	entries map[uint]*vectorEntry
+}
+
+func newVectorIndex() *vectorIndex {
+	return &vectorIndex{entries: make(map[uint]*vectorEntry)}
+}
+
+func (x *vectorIndex) put(e *vectorEntry) {
+	x.mu.Lock()
+	x.entries[e.id] = e
+	x.mu.Unlock()
+}
+
+func (x *vectorIndex) remove(ids []uint) {
+	x.mu.Lock()
+	for _, id := range ids {
+		delete(x.entries, id)
+	}
+	x.mu.Unlock()
+}
+
+func (x *vectorIndex) get(id uint) (*vectorEntry, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	e, ok := x.entries[id]
+	return e, ok
+}
+
+func (x *vectorIndex) len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.entries)
+}
+
+// hashes 返回 id -> 内容哈希
+func (x *vectorIndex) hashes() map[uint]string {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	result := make(map[uint]string, len(x.entries))
+	for id, e := range x.entries {
+		result[id] = e.hash
+	}
+	return result
+}
+
+// search 返回与 query 最相似的 k 个条目，filter 返回 false 的条目跳过
+func (x *vectorIndex) search(query quantizedVector, k int, minScore float64, filter func(*vectorEntry) bool) []ScoredContent {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	results := make([]ScoredContent, 0, k+1)
+	for _, e := range x.entries {
+		if filter != nil && !filter(e) {
+			continue
+		}
+		score := query.cosine(e.vec)
+		if score < minScore {
+			continue
+		}
+		if len(results) == k && score <= results[k-1].Score {
+			continue
+		}
+		results = append(results, ScoredContent{ID: e.id, CategoryID: e.categoryID, Score: score})
+		sort.Slice(results, func(i, j int) bool {
+			if results[i].Score != results[j].Score {
+				return results[i].Score > results[j].Score
+			}
+			return results[i].ID < results[j].ID
+		})
+		if len(results) > k {
+			results = results[:k]
+		}
+	}
+	return results
+}
+
+// snapshot 按 id 升序返回全部条目，用于两两比较
+func (x *vectorIndex) snapshot() []*vectorEntry {
+	x.mu.RLock()
+	entries := make([]*vectorEntry, 0, len(x.entries))
+	for _, e := range x.entries {
+		entries = append(entries, e)
+	}
+	x.mu.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].id < entries[j].id })
+	return entries
+}
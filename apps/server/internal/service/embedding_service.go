@@ -0,0 +1,468 @@
+package service
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/what-cse/server/internal/ai"
+	"github.com/what-cse/server/internal/config"
+	"github.com/what-cse/server/internal/model"
+	"github.com/what-cse/server/internal/repository"
+)
+
+var (
+	ErrEmbeddingNotReady   = errors.New("向量索引尚未就绪")
+	ErrEmbeddingRefreshing = errors.New("向量化任务正在运行")
+)
+
+const (
+	embeddingScanBatch     = 500
+	embeddingMaxTextRunes  = 2000
+	embeddingProviderLocal = "local"
+)
+
+// EmbeddingService 内容向量化与相似检索
+// 题目（题干 + 解析）和素材文本经嵌入模型向量化后 int8 量化存库，
+// 启动时载入进程内索引，供相似题推荐、重复检测和专项练习选题使用
+type EmbeddingService struct {
+	repo             *repository.ContentEmbeddingRepository
+	llmConfigService *LLMConfigService
+	cfg              config.EmbeddingConfig
+	logger           *zap.Logger
+
+	embedderMu sync.Mutex
+	embedder   ai.Embedder
+
+	indexes   map[string]*vectorIndex
+	loadedFor string // 已载入索引对应的模型
+
+	refreshMu   sync.Mutex
+	lastRefresh *EmbeddingRefreshResult
+
+	mu sync.Mutex
+}
+
+// EmbeddingRefreshResult 增量向量化结果
+type EmbeddingRefreshResult struct {
+	Model       string         `json:"model"`
+	Embedded    map[string]int `json:"embedded"`
+	Removed     map[string]int `json:"removed"`
+	Unchanged   map[string]int `json:"unchanged"`
+	StaleModels int64          `json:"stale_models"` // 清理的旧模型向量数
+	FinishedAt  time.Time      `json:"finished_at"`
+	DurationSec float64        `json:"duration_sec"`
+}
+
+// EmbeddingStatus 向量索引状态
+type EmbeddingStatus struct {
+	Provider    string                  `json:"provider"`
+	Model       string                  `json:"model,omitempty"`
+	Ready       bool                    `json:"ready"`
+	Indexed     map[string]int          `json:"indexed"`
+	LastRefresh *EmbeddingRefreshResult `json:"last_refresh,omitempty"`
+	Error       string                  `json:"error,omitempty"`
+}
+
+// DuplicatePair 疑似重复内容
+type DuplicatePair struct {
+	ID      uint    `json:"id"`
+	OtherID uint    `json:"other_id"`
+	Score   float64 `json:"score"`
+}
+
+// NewEmbeddingService 创建向量化服务
+func NewEmbeddingService(repo *repository.ContentEmbeddingRepository, llmConfigService *LLMConfigService, cfg config.EmbeddingConfig, logger *zap.Logger) *EmbeddingService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 64
+	}
+	if cfg.DuplicateThreshold <= 0 {
+		cfg.DuplicateThreshold = 0.95
+	}
+	return &EmbeddingService{
+		repo:             repo,
+		llmConfigService: llmConfigService,
+		cfg:              cfg,
+		logger:           logger,
+		indexes: map[string]*vectorIndex{
+			model.EmbeddingEntityQuestion: newVectorIndex(),
+			model.EmbeddingEntityMaterial: newVectorIndex(),
+		},
+	}
+}
+
+// getEmbedder 获取嵌入模型客户端，LLM 配置不可用时下次调用重试
+func (s *EmbeddingService) getEmbedder() (ai.Embedder, error) {
+	s.embedderMu.Lock()
+	defer s.embedderMu.Unlock()
+
+	if s.embedder != nil {
+		return s.embedder, nil
+	}
+	if s.cfg.Provider == embeddingProviderLocal {
+		s.embedder = ai.NewHashEmbedder(s.cfg.Dimensions)
+		return s.embedder, nil
+	}
+	if s.llmConfigService == nil {
+		return nil, ErrNoDefaultLLMConfig
+	}
+	embedder, err := s.llmConfigService.GetEmbedder(s.cfg.LLMConfigID, s.cfg.Model)
+	if err != nil {
+		return nil, err
+	}
+	s.embedder = embedder
+	return embedder, nil
+}
+
+// ResetEmbedder 丢弃缓存的客户端（LLM 配置变更后调用）
+func (s *EmbeddingService) ResetEmbedder() {
+	s.embedderMu.Lock()
+	s.embedder = nil
+	s.embedderMu.Unlock()
+}
+
+// Ready 索引中是否已有向量
+func (s *EmbeddingService) Ready(entityType string) bool {
+	idx, ok := s.indexes[entityType]
+	return ok && idx.len() > 0
+}
+
+// =====================================================
+// 载入与增量向量化
+// =====================================================
+
+// loadIndex 从数据库载入当前模型的向量
+func (s *EmbeddingService) loadIndex(embeddingModel string) error {
+	for entityType, idx := range s.indexes {
+		fresh := newVectorIndex()
+		var afterID uint
+		for {
+			rows, err := s.repo.ScanByModel(entityType, embeddingModel, afterID, embeddingScanBatch)
+			if err != nil {
+				return err
+			}
+			for _, row := range rows {
+				fresh.entries[row.EntityID] = &vectorEntry{
+					id:         row.EntityID,
+					categoryID: row.CategoryID,
+					hash:       row.ContentHash,
+					vec:        quantizedFromBytes(row.Vector, row.Scale),
+				}
+			}
+			if len(rows) < embeddingScanBatch {
+				break
+			}
+			afterID = rows[len(rows)-1].ID
+		}
+
+		idx.mu.Lock()
+		idx.entries = fresh.entries
+		idx.mu.Unlock()
+	}
+	s.loadedFor = embeddingModel
+	return nil
+}
+
+// embeddingSource 待向量化的内容
+type embeddingSource struct {
+	id         uint
+	categoryID uint
+	text       string
+	hash       string
+}
+
+// Refresh 扫描已发布的题目和素材，对新增或内容变化的条目向量化，移除已下线条目
+func (s *EmbeddingService) Refresh(ctx context.Context) (*EmbeddingRefreshResult, error) {
+	if !s.refreshMu.TryLock() {
+		return nil, ErrEmbeddingRefreshing
+	}
+	defer s.refreshMu.Unlock()
+
+	start := time.Now()
+	embedder, err := s.getEmbedder()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &EmbeddingRefreshResult{
+		Model:     embedder.Model(),
+		Embedded:  make(map[string]int),
+		Removed:   make(map[string]int),
+		Unchanged: make(map[string]int),
+	}
+
+	if s.loadedFor != embedder.Model() {
+		stale, err := s.repo.DeleteOtherModels(embedder.Model())
+		if err != nil {
+			return nil, err
+		}
+		result.StaleModels = stale
+		if err := s.loadIndex(embedder.Model()); err != nil {
+			return nil, err
+		}
+	}
+
+	scanners := map[string]func(afterID uint) ([]embeddingSource, uint, error){
+		model.EmbeddingEntityQuestion: s.scanQuestions,
+		model.EmbeddingEntityMaterial: s.scanMaterials,
+	}
+	for _, entityType := range []string{model.EmbeddingEntityQuestion, model.EmbeddingEntityMaterial} {
+		if err := s.refreshEntity(ctx, embedder, entityType, scanners[entityType], result); err != nil {
+			return nil, err
+		}
+	}
+
+	result.FinishedAt = time.Now()
+	result.DurationSec = round2(time.Since(start).Seconds())
+	s.mu.Lock()
+	s.lastRefresh = result
+	s.mu.Unlock()
+	return result, nil
+}
+
+func (s *EmbeddingService) refreshEntity(ctx context.Context, embedder ai.Embedder, entityType string,
+	scan func(afterID uint) ([]embeddingSource, uint, error), result *EmbeddingRefreshResult) error {
+	idx := s.indexes[entityType]
+	existing := idx.hashes()
+	seen := make(map[uint]bool, len(existing))
+
+	var pending []embeddingSource
+	flush := func() error {
+		if len(pending) == 0 {
+			return nil
+		}
+		if err := s.embedSources(ctx, embedder, entityType, pending); err != nil {
+			return err
+		}
+		result.Embedded[entityType] += len(pending)
+		pending = pending[:0]
+		return nil
+	}
+
+	var afterID uint
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		sources, next, err := scan(afterID)
+		if err != nil {
+			return err
+		}
+		for _, src := range sources {
+			seen[src.id] = true
+			if existing[src.id] == src.hash {
+				result.Unchanged[entityType]++
+				continue
+			}
+			pending = append(pending, src)
+			if len(pending) >= s.cfg.BatchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		if len(sources) == 0 || next == afterID {
+			break
+		}
+		afterID = next
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	var removed []uint
+	for id := range existing {
+		if !seen[id] {
+			removed = append(removed, id)
+		}
+	}
+	if err := s.repo.DeleteByEntities(entityType, removed); err != nil {
+		return err
+	}
+	idx.remove(removed)
+	result.Removed[entityType] = len(removed)
+	return nil
+}
+
+func (s *EmbeddingService) embedSources(ctx context.Context, embedder ai.Embedder, entityType string, sources []embeddingSource) error {
+	texts := make([]string, len(sources))
+	for i, src := range sources {
+		texts[i] = src.text
+	}
+
+	vectors, err := embedder.Embed(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("向量化失败: %w", err)
+	}
+
+	rows := make([]model.ContentEmbedding, 0, len(sources))
+	entries := make([]*vectorEntry, 0, len(sources))
+	for i, src := range sources {
+		q := quantizeVector(vectors[i])
+		rows = append(rows, model.ContentEmbedding{
+			EntityType:  entityType,
+			EntityID:    src.id,
+			CategoryID:  src.categoryID,
+			Model:       embedder.Model(),
+			Dim:         len(q.values),
+			ContentHash: src.hash,
+			Vector:      q.bytes(),
+			Scale:       q.scale,
+		})
+		entries = append(entries, &vectorEntry{id: src.id, categoryID: src.categoryID, hash: src.hash, vec: q})
+	}
+	if err := s.repo.Upsert(rows); err != nil {
+		return err
+	}
+
+	idx := s.indexes[entityType]
+	for _, e := range entries {
+		idx.put(e)
+	}
+	return nil
+}
+
+func (s *EmbeddingService) scanQuestions(afterID uint) ([]embeddingSource, uint, error) {
+	questions, err := s.repo.ScanPublishedQuestions(afterID, embeddingScanBatch)
+	if err != nil || len(questions) == 0 {
+		return nil, afterID, err
+	}
+	sources := make([]embeddingSource, 0, len(questions))
+	for _, q := range questions {
+		sources = append(sources, newEmbeddingSource(q.ID, q.CategoryID, questionEmbeddingText(&q)))
+	}
+	return sources, questions[len(questions)-1].ID, nil
+}
+
+func (s *EmbeddingService) scanMaterials(afterID uint) ([]embeddingSource, uint, error) {
+	materials, err := s.repo.ScanPublishedMaterials(afterID, embeddingScanBatch)
+	if err != nil || len(materials) == 0 {
+		return nil, afterID, err
+	}
+	sources := make([]embeddingSource, 0, len(materials))
+	for _, m := range materials {
+		text := joinNonEmpty(m.Title, plainText(m.Content), plainText(m.Analysis))
+		sources = append(sources, newEmbeddingSource(m.ID, m.CategoryID, text))
+	}
+	return sources, materials[len(materials)-1].ID, nil
+}
+
+// questionEmbeddingText 题干 + 解析
+func questionEmbeddingText(q *model.Question) string {
+	return joinNonEmpty(plainText(q.Content), plainText(q.Analysis))
+}
+
+func newEmbeddingSource(id, categoryID uint, text string) embeddingSource {
+	text = truncateRunes(strings.TrimSpace(text), embeddingMaxTextRunes)
+	sum := sha1.Sum([]byte(text))
+	return embeddingSource{id: id, categoryID: categoryID, text: text, hash: hex.EncodeToString(sum[:])}
+}
+
+// RunScheduled 定时增量向量化入口，上一轮仍在执行时跳过
+func (s *EmbeddingService) RunScheduled(ctx context.Context) error {
+	if _, err := s.Refresh(ctx); err != nil && !errors.Is(err, ErrEmbeddingRefreshing) {
+		s.ResetEmbedder()
+		return err
+	}
+	return nil
+}
+
+// GetStatus 获取索引状态
+func (s *EmbeddingService) GetStatus() *EmbeddingStatus {
+	s.mu.Lock()
+	status := &EmbeddingStatus{
+		Provider:    s.cfg.Provider,
+		Indexed:     make(map[string]int, len(s.indexes)),
+		LastRefresh: s.lastRefresh,
+	}
+	s.mu.Unlock()
+	for entityType, idx := range s.indexes {
+		status.Indexed[entityType] = idx.len()
+		if idx.len() > 0 {
+			status.Ready = true
+		}
+	}
+	if embedder, err := s.getEmbedder(); err != nil {
+		status.Error = err.Error()
+	} else {
+		status.Model = embedder.Model()
+	}
+	return status
+}
+
+// =====================================================
+// 相似检索
+// =====================================================
+
+// SimilarByID 查找与指定内容最相似的 k 条同类内容（不含自身）
+func (s *EmbeddingService) SimilarByID(entityType string, id uint, k int) ([]ScoredContent, error) {
+	idx, ok := s.indexes[entityType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported entity type: %s", entityType)
+	}
+	entry, ok := idx.get(id)
+	if !ok {
+		return nil, ErrEmbeddingNotReady
+	}
+	return idx.search(entry.vec, k, 0, func(e *vectorEntry) bool { return e.id != id }), nil
+}
+
+// SimilarToText 查找与任意文本最相似的 k 条内容，exclude 中的 id 跳过
+func (s *EmbeddingService) SimilarToText(ctx context.Context, entityType, text string, k int, exclude map[uint]bool) ([]ScoredContent, error) {
+	idx, ok := s.indexes[entityType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported entity type: %s", entityType)
+	}
+	if idx.len() == 0 {
+		return nil, ErrEmbeddingNotReady
+	}
+
+	embedder, err := s.getEmbedder()
+	if err != nil {
+		return nil, err
+	}
+	vectors, err := embedder.Embed(ctx, []string{truncateRunes(text, embeddingMaxTextRunes)})
+	if err != nil {
+		return nil, fmt.Errorf("向量化失败: %w", err)
+	}
+
+	query := quantizeVector(vectors[0])
+	return idx.search(query, k, 0, func(e *vectorEntry) bool { return !exclude[e.id] }), nil
+}
+
+// FindDuplicates 两两比较找出相似度不低于 threshold 的内容对，threshold<=0 时使用配置值
+func (s *EmbeddingService) FindDuplicates(entityType string, threshold float64, limit int) ([]DuplicatePair, int, error) {
+	idx, ok := s.indexes[entityType]
+	if !ok {
+		return nil, 0, fmt.Errorf("unsupported entity type: %s", entityType)
+	}
+	if threshold <= 0 {
+		threshold = s.cfg.DuplicateThreshold
+	}
+
+	entries := idx.snapshot()
+	var pairs []DuplicatePair
+	for i := 0; i < len(entries); i++ {
+		for j := i + 1; j < len(entries); j++ {
+			score := entries[i].vec.cosine(entries[j].vec)
+			if score < threshold {
+				continue
+			}
+			pairs = append(pairs, DuplicatePair{ID: entries[i].id, OtherID: entries[j].id, Score: score})
+			if limit > 0 && len(pairs) >= limit {
+				return pairs, len(entries), nil
+			}
+		}
+	}
+	return pairs, len(entries), nil
+}
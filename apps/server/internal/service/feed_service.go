@@ -0,0 +1,345 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/what-cse/server/internal/config"
+	"github.com/what-cse/server/internal/feed"
+	"github.com/what-cse/server/internal/model"
+	"github.com/what-cse/server/internal/repository"
+)
+
+var (
+	ErrFeedSourceNotFound = errors.New("订阅源不存在")
+	ErrFeedTokenInvalid   = errors.New("订阅链接无效或已重置")
+)
+
+// FeedQuery 订阅源请求参数
+type FeedQuery struct {
+	AnnouncementType string
+	ExamType         string
+	Province         string
+	Summary          bool   // 摘要模式：只输出摘要，不输出全文
+	FeedURL          string // 订阅源自身地址
+	Path             string // 订阅源路径，不含查询参数
+}
+
+// FeedTokenInfo 用户专属订阅源令牌
+type FeedTokenInfo struct {
+	Token      string            `json:"token"`
+	Path       string            `json:"path"`           // 订阅源路径，末尾追加 /rss、/atom 或 /json
+	URLs       map[string]string `json:"urls,omitempty"` // 各格式的完整地址
+	LastUsedAt *time.Time        `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+// FeedService 公告与公众号文章的 RSS/Atom/JSON Feed 订阅源
+// 公告订阅源可按省份、考试类型、公告类型过滤；用户专属订阅源按用户启用的订阅条件过滤
+type FeedService struct {
+	announcementRepo  *repository.AnnouncementRepository
+	wechatSourceRepo  *repository.WechatRSSSourceRepository
+	wechatArticleRepo *repository.WechatRSSArticleRepository
+	subscriptionRepo  *repository.SubscriptionRepository
+	tokenRepo         *repository.FeedTokenRepository
+	cfg               config.FeedConfig
+	logger            *zap.Logger
+}
+
+// NewFeedService 创建订阅源服务
+func NewFeedService(
+	announcementRepo *repository.AnnouncementRepository,
+	wechatSourceRepo *repository.WechatRSSSourceRepository,
+	wechatArticleRepo *repository.WechatRSSArticleRepository,
+	subscriptionRepo *repository.SubscriptionRepository,
+	tokenRepo *repository.FeedTokenRepository,
+	cfg config.FeedConfig,
+	logger *zap.Logger,
+) *FeedService {
+	if cfg.Title == "" {
+		cfg.Title = "公考公告"
+	}
+	if cfg.ItemLimit <= 0 {
+		cfg.ItemLimit = 50
+	}
+	if cfg.SummaryLength <= 0 {
+		cfg.SummaryLength = 200
+	}
+	cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+	return &FeedService{
+		announcementRepo:  announcementRepo,
+		wechatSourceRepo:  wechatSourceRepo,
+		wechatArticleRepo: wechatArticleRepo,
+		subscriptionRepo:  subscriptionRepo,
+		tokenRepo:         tokenRepo,
+		cfg:               cfg,
+		logger:            logger,
+	}
+}
+
+// CacheSeconds 订阅源的缓存时间
+func (s *FeedService) CacheSeconds() int {
+	return s.cfg.CacheSeconds
+}
+
+// AnnouncementFeed 公告订阅源
+func (s *FeedService) AnnouncementFeed(q *FeedQuery) (*feed.Feed, error) {
+	announcements, err := s.announcementRepo.ListForFeed(&repository.AnnouncementFeedFilter{
+		AnnouncementType: q.AnnouncementType,
+		ExamType:         q.ExamType,
+		Province:         q.Province,
+	}, s.cfg.ItemLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	title := s.cfg.Title
+	var scope []string
+	for _, v := range []string{q.Province, q.ExamType, q.AnnouncementType} {
+		if v != "" {
+			scope = append(scope, v)
+		}
+	}
+	if len(scope) > 0 {
+		title += " - " + strings.Join(scope, " · ")
+	}
+	return s.announcementFeed(title, "最新公务员、事业单位招考公告", announcements, q), nil
+}
+
+// UserFeed 用户专属订阅源，按用户启用的订阅条件筛选公告
+func (s *FeedService) UserFeed(token string, q *FeedQuery) (*feed.Feed, error) {
+	feedToken, err := s.tokenRepo.GetByToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if feedToken == nil {
+		return nil, ErrFeedTokenInvalid
+	}
+	subscriptions, err := s.subscriptionRepo.GetEnabledSubscriptions(feedToken.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &repository.AnnouncementFeedFilter{
+		AnnouncementType:   q.AnnouncementType,
+		ExamType:           q.ExamType,
+		Province:           q.Province,
+		MatchSubscriptions: true,
+	}
+	for _, sub := range subscriptions {
+		value := strings.TrimSpace(sub.SubscribeValue)
+		if value == "" {
+			continue
+		}
+		switch sub.SubscribeType {
+		case model.SubscribeTypeExamType:
+			filter.MatchExamTypes = append(filter.MatchExamTypes, value)
+		case model.SubscribeTypeProvince:
+			filter.MatchProvinces = append(filter.MatchProvinces, value)
+		case model.SubscribeTypeCity:
+			filter.MatchCities = append(filter.MatchCities, value)
+		case model.SubscribeTypeKeyword, model.SubscribeTypeDepartment:
+			filter.MatchKeywords = append(filter.MatchKeywords, value)
+		}
+		// 学历、专业订阅针对职位，公告层面无法判断，不参与筛选
+	}
+
+	announcements, err := s.announcementRepo.ListForFeed(filter, s.cfg.ItemLimit)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tokenRepo.Touch(feedToken.ID, time.Now()); err != nil {
+		s.logger.Warn("Failed to record feed token use", zap.Uint("user_id", feedToken.UserID), zap.Error(err))
+	}
+	return s.announcementFeed(s.cfg.Title+" - 我的订阅", "按我的订阅条件筛选的招考公告", announcements, q), nil
+}
+
+// WechatSourceFeed 公众号文章订阅源
+func (s *FeedService) WechatSourceFeed(sourceID uint, q *FeedQuery) (*feed.Feed, error) {
+	source, err := s.wechatSourceRepo.FindByID(sourceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrFeedSourceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	articles, err := s.wechatArticleRepo.ListBySourceID(source.ID, s.cfg.ItemLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &feed.Feed{
+		Title:       source.Name,
+		Description: source.Description,
+		ID:          s.feedID(q),
+		Link:        s.cfg.BaseURL,
+		FeedURL:     q.FeedURL,
+		Language:    "zh-CN",
+		IconURL:     source.IconURL,
+		Items:       make([]feed.Item, 0, len(articles)),
+	}
+	if f.Description == "" {
+		f.Description = source.Name + " 公众号文章"
+	}
+	for _, article := range articles {
+		item := feed.Item{
+			ID:        article.GUID,
+			Title:     article.Title,
+			Link:      article.Link,
+			Author:    article.Author,
+			ImageURL:  article.ImageURL,
+			Published: article.CreatedAt,
+			Updated:   article.UpdatedAt,
+		}
+		if article.PubDate != nil {
+			item.Published = *article.PubDate
+		}
+		item.Summary = article.Description
+		if item.Summary == "" {
+			item.Summary = feed.Summarize(article.Content, s.cfg.SummaryLength)
+		}
+		if !q.Summary {
+			item.Content = article.Content
+		}
+		f.Items = append(f.Items, item)
+	}
+	return f, nil
+}
+
+// feedID 订阅源的固定标识：站点地址 + 订阅源路径，
+// 访问所用的域名和摘要模式等查询参数不影响标识，阅读器不会因此把同一订阅源当成新的
+func (s *FeedService) feedID(q *FeedQuery) string {
+	if s.cfg.BaseURL == "" || q.Path == "" {
+		id, _, _ := strings.Cut(q.FeedURL, "?")
+		return id
+	}
+	return strings.TrimRight(s.cfg.BaseURL, "/") + q.Path
+}
+
+func (s *FeedService) announcementFeed(title, description string, announcements []model.Announcement, q *FeedQuery) *feed.Feed {
+	f := &feed.Feed{
+		Title:       title,
+		Description: description,
+		ID:          s.feedID(q),
+		Link:        s.cfg.BaseURL,
+		FeedURL:     q.FeedURL,
+		Language:    "zh-CN",
+		Items:       make([]feed.Item, 0, len(announcements)),
+	}
+	for i := range announcements {
+		f.Items = append(f.Items, s.announcementItem(&announcements[i], q.Summary))
+	}
+	return f
+}
+
+func (s *FeedService) announcementItem(a *model.Announcement, summary bool) feed.Item {
+	item := feed.Item{
+		// 不随站点地址变化的固定标识，阅读器据此去重
+		ID:        fmt.Sprintf("urn:what-cse:announcement:%d", a.ID),
+		Title:     a.Title,
+		Link:      a.SourceURL,
+		Summary:   feed.Summarize(a.Content, s.cfg.SummaryLength),
+		Author:    a.SourceName,
+		Published: a.CreatedAt,
+		Updated:   a.UpdatedAt,
+	}
+	if s.cfg.BaseURL != "" {
+		item.Link = fmt.Sprintf("%s/announcements/%d", s.cfg.BaseURL, a.ID)
+	}
+	if a.PublishDate != nil {
+		item.Published = *a.PublishDate
+	}
+	for _, category := range []string{a.AnnouncementType, a.ExamType, a.Province, a.City} {
+		if category != "" {
+			item.Categories = append(item.Categories, category)
+		}
+	}
+	if summary {
+		return item
+	}
+
+	var content strings.Builder
+	content.WriteString(feed.TextToHTML(a.Content))
+	if len(a.AttachmentURLs) > 0 {
+		content.WriteString("<p>附件：</p>\n<ul>\n")
+		for _, u := range a.AttachmentURLs {
+			escaped := html.EscapeString(u)
+			content.WriteString(`<li><a href="` + escaped + `">` + escaped + "</a></li>\n")
+		}
+		content.WriteString("</ul>\n")
+	}
+	if a.SourceURL != "" && item.Link != a.SourceURL {
+		content.WriteString(`<p><a href="` + html.EscapeString(a.SourceURL) + `">查看原文</a></p>` + "\n")
+	}
+	item.Content = content.String()
+	return item
+}
+
+// =====================================================
+// 用户专属订阅源令牌
+// =====================================================
+
+// GetToken 获取用户的订阅源令牌，首次获取时生成
+func (s *FeedService) GetToken(userID uint) (*FeedTokenInfo, error) {
+	feedToken, err := s.tokenRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if feedToken == nil {
+		token, err := newFeedToken()
+		if err != nil {
+			return nil, err
+		}
+		feedToken = &model.UserFeedToken{UserID: userID, Token: token}
+		if err := s.tokenRepo.Create(feedToken); err != nil {
+			return nil, err
+		}
+	}
+	return feedTokenInfo(feedToken), nil
+}
+
+// ResetToken 重新生成令牌，旧的订阅地址失效
+func (s *FeedService) ResetToken(userID uint) (*FeedTokenInfo, error) {
+	feedToken, err := s.tokenRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if feedToken == nil {
+		return s.GetToken(userID)
+	}
+	token, err := newFeedToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tokenRepo.UpdateToken(feedToken.ID, token); err != nil {
+		return nil, err
+	}
+	feedToken.Token = token
+	feedToken.LastUsedAt = nil
+	return feedTokenInfo(feedToken), nil
+}
+
+func feedTokenInfo(t *model.UserFeedToken) *FeedTokenInfo {
+	return &FeedTokenInfo{
+		Token:      t.Token,
+		Path:       "/api/v1/feeds/user/" + t.Token,
+		LastUsedAt: t.LastUsedAt,
+		CreatedAt:  t.CreatedAt,
+	}
+}
+
+func newFeedToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
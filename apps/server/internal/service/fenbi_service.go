@@ -53,14 +53,17 @@ type CrawlPosition struct {
 }
 
 type FenbiService struct {
-	credRepo         *repository.FenbiCredentialRepository
-	categoryRepo     *repository.FenbiCategoryRepository
-	announcementRepo *repository.FenbiAnnouncementRepository
-	parseTaskRepo    *repository.FenbiParseTaskRepository
-	positionRepo     *repository.PositionRepository
-	spiderConfig     *crawler.SpiderConfig
-	llmConfigService *LLMConfigService
-	logger           *zap.Logger
+	credRepo          *repository.FenbiCredentialRepository
+	categoryRepo      *repository.FenbiCategoryRepository
+	announcementRepo  *repository.FenbiAnnouncementRepository
+	parseTaskRepo     *repository.FenbiParseTaskRepository
+	positionRepo      *repository.PositionRepository
+	spiderConfig      *crawler.SpiderConfig
+	llmConfigService  *LLMConfigService
+	ocrParser         *parser.OCRPositionParser // 扫描版 PDF 附件识别（可选）
+	attachmentService *AttachmentService        // 附件归档（可选）
+	importService     *PositionImportService    // 职位表列映射方案匹配（可选）
+	logger            *zap.Logger
 
 	// Crawl control
 	crawlMutex    sync.Mutex
@@ -93,6 +96,42 @@ func NewFenbiService(
 	}
 }
 
+// SetOCRParser 设置扫描版 PDF 附件的 OCR 识别
+func (s *FenbiService) SetOCRParser(ocrParser *parser.OCRPositionParser) {
+	s.ocrParser = ocrParser
+}
+
+// SetAttachmentService 设置附件归档，下载的附件按内容哈希保存以便重新解析
+func (s *FenbiService) SetAttachmentService(attachmentService *AttachmentService) {
+	s.attachmentService = attachmentService
+}
+
+// SetImportService 设置职位表导入服务，解析附件时按来源站点和考试类型匹配列映射方案
+func (s *FenbiService) SetImportService(importService *PositionImportService) {
+	s.importService = importService
+}
+
+// matchColumnProfile 匹配附件职位表的列映射方案，未配置或没有匹配方案时返回 nil（自动识别表头）
+func (s *FenbiService) matchColumnProfile(sourceURL, examType string) *parser.ColumnProfile {
+	if s.importService == nil {
+		return nil
+	}
+	profile, err := s.importService.MatchProfile(sourceURL, examType)
+	if err != nil {
+		s.logger.Warn("Failed to match column profile", zap.String("url", sourceURL), zap.Error(err))
+		return nil
+	}
+	if profile == nil {
+		return nil
+	}
+	s.logger.Info("Matched column profile for attachments",
+		zap.String("url", sourceURL),
+		zap.String("exam_type", examType),
+		zap.String("profile", profile.Name),
+	)
+	return toColumnProfile(profile)
+}
+
 // === Credential Management ===
 
 type SaveCredentialRequest struct {
@@ -1082,12 +1121,14 @@ type PageContentResult struct {
 
 // AttachmentResult holds information about a downloaded and parsed attachment
 type AttachmentResult struct {
-	Name      string `json:"name"`
-	URL       string `json:"url"`
-	Type      string `json:"type"`
-	LocalPath string `json:"local_path,omitempty"`
-	Content   string `json:"content,omitempty"`
-	Error     string `json:"error,omitempty"`
+	Name         string `json:"name"`
+	URL          string `json:"url"`
+	Type         string `json:"type"`
+	LocalPath    string `json:"local_path,omitempty"`
+	Content      string `json:"content,omitempty"`
+	Error        string `json:"error,omitempty"`
+	AttachmentID uint   `json:"attachment_id,omitempty"` // 归档附件 ID
+	Rows         int    `json:"rows,omitempty"`          // 结构化解析识别到的职位行数
 }
 
 // LLMAnalysisResult holds the LLM analysis results
@@ -1096,6 +1137,7 @@ type LLMAnalysisResult struct {
 	Positions   []ExtractedPositionInfo `json:"positions,omitempty"`
 	ExamInfo    *ExtractedExamInfo      `json:"exam_info,omitempty"`
 	Confidence  int                     `json:"confidence"`
+	Warnings    []string                `json:"warnings,omitempty"`
 	RawResponse string                  `json:"raw_response,omitempty"`
 	Error       string                  `json:"error,omitempty"`
 }
@@ -1390,12 +1432,13 @@ func (s *FenbiService) TestCrawl() (*TestCrawlResult, error) {
 			}
 
 			// Step 2: Download and parse attachments
+			profile := s.matchColumnProfile(pageContent.URL, "")
 			if len(pageContent.Attachments) > 0 {
-				testResult.Attachments = s.processAttachments(spider, pageContent.Attachments)
+				testResult.Attachments = s.processAttachments(spider, pageContent.Attachments, profile)
 			}
 
 			// Step 3: LLM Analysis (use default LLM config)
-			testResult.LLMAnalysis = s.performLLMAnalysis(pageContent, testResult.Attachments, 0)
+			testResult.LLMAnalysis = s.performLLMAnalysis(pageContent, testResult.Attachments, profile, 0)
 		}
 	}
 
@@ -1584,9 +1627,17 @@ func (s *FenbiService) ParseURL(inputURL string, llmConfigID uint) (*ParseURLRes
 	result.Data.PageContent = contentPreview
 
 	// Step 3: Process attachments
+	// 粉笔公告已同步过考试类型时一并用于匹配列映射方案
+	examType := ""
+	if fenbiID != "" {
+		if existing, err := s.announcementRepo.FindByFenbiID(fenbiID); err == nil && existing != nil {
+			examType = existing.ExamTypeName
+		}
+	}
+	profile := s.matchColumnProfile(pageContent.URL, examType)
 	if len(pageContent.Attachments) > 0 {
 		stepStart = time.Now()
-		attachmentResults := s.processAttachments(spider, pageContent.Attachments)
+		attachmentResults := s.processAttachments(spider, pageContent.Attachments, profile)
 		result.Data.Attachments = attachmentResults
 
 		successCount := 0
@@ -1634,7 +1685,7 @@ func (s *FenbiService) ParseURL(inputURL string, llmConfigID uint) (*ParseURLRes
 
 	// Step 4: LLM Analysis
 	stepStart = time.Now()
-	llmResult := s.performLLMAnalysis(pageContent, result.Data.Attachments, llmConfigID)
+	llmResult := s.performLLMAnalysis(pageContent, result.Data.Attachments, profile, llmConfigID)
 	result.Data.LLMAnalysis = llmResult
 
 	if llmResult.Error != "" {
@@ -1664,6 +1715,9 @@ func (s *FenbiService) ParseURL(inputURL string, llmConfigID uint) (*ParseURLRes
 				llmDetails.WriteString(fmt.Sprintf("报名截止: %s\n", llmResult.ExamInfo.RegistrationEnd))
 			}
 		}
+		for _, w := range llmResult.Warnings {
+			llmDetails.WriteString(fmt.Sprintf("\n警告: %s", w))
+		}
 
 		result.Steps = append(result.Steps, ParseStep{
 			Name:     "LLM智能分析",
@@ -1707,6 +1761,7 @@ func (s *FenbiService) ParseURL(inputURL string, llmConfigID uint) (*ParseURLRes
 			savedAnn, _ := s.announcementRepo.FindByFenbiID(fenbiID)
 			if savedAnn != nil {
 				savedAnnouncementID = savedAnn.ID
+				s.linkArchivedAttachments(savedAnnouncementID, result.Data.Attachments)
 			}
 		}
 	}
@@ -1848,6 +1903,24 @@ func (s *FenbiService) saveOrUpdateFenbiAnnouncement(fenbiID string, detail *cra
 	return s.announcementRepo.Create(announcement)
 }
 
+// linkArchivedAttachments 把本次归档的附件来源关联到保存后的粉笔公告
+func (s *FenbiService) linkArchivedAttachments(fenbiAnnouncementID uint, attachments []AttachmentResult) {
+	if s.attachmentService == nil {
+		return
+	}
+	for _, att := range attachments {
+		if att.AttachmentID == 0 {
+			continue
+		}
+		if err := s.attachmentService.LinkFenbiAnnouncement(att.AttachmentID, att.URL, fenbiAnnouncementID); err != nil {
+			s.logger.Warn("Failed to link attachment to announcement",
+				zap.Uint("attachment_id", att.AttachmentID),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
 // SavePositionsFromParseResult 从解析结果保存职位信息到数据库
 func (s *FenbiService) SavePositionsFromParseResult(fenbiAnnouncementID uint, llmResult *LLMAnalysisResult) (int, error) {
 	if s.positionRepo == nil {
@@ -1924,6 +1997,7 @@ func (s *FenbiService) SavePositionsFromParseResult(fenbiAnnouncementID uint, ll
 			ExamDate:             parsedPos.ExamDate,
 			ParseConfidence:      llmResult.Confidence,
 			ParsedAt:             &now,
+			ParserVersion:        "llm-" + parser.Version,          // 附件文本由当前解析器提取后交给 LLM
 			Status:               int(model.PositionStatusPending), // 待审核
 		}
 
@@ -2032,8 +2106,9 @@ func (s *FenbiService) updateAnnouncementListPage(fenbiID string, listPageURL st
 	}
 }
 
-// processAttachments downloads and parses attachments
-func (s *FenbiService) processAttachments(spider *crawler.FenbiSpider, attachments []crawler.PageAttachment) []AttachmentResult {
+// processAttachments downloads and parses attachments. profile is the column
+// mapping of the position tables, nil to detect the header
+func (s *FenbiService) processAttachments(spider *crawler.FenbiSpider, attachments []crawler.PageAttachment, profile *parser.ColumnProfile) []AttachmentResult {
 	results := make([]AttachmentResult, 0, len(attachments))
 
 	// Create temp directory for attachments
@@ -2103,7 +2178,11 @@ func (s *FenbiService) processAttachments(spider *crawler.FenbiSpider, attachmen
 		)
 
 		// Download file
-		if err := spider.DownloadFile(att.URL, localPath); err != nil {
+		file, err := spider.FetchFile(att.URL)
+		if err == nil {
+			err = os.WriteFile(localPath, file.Data, 0644)
+		}
+		if err != nil {
 			s.logger.Warn("Failed to download attachment",
 				zap.String("name", att.Name),
 				zap.Error(err),
@@ -2115,8 +2194,27 @@ func (s *FenbiService) processAttachments(spider *crawler.FenbiSpider, attachmen
 
 		result.LocalPath = localPath
 
+		// Archive by content hash so the file can be re-parsed later
+		if s.attachmentService != nil {
+			archived, archiveErr := s.attachmentService.Archive(context.Background(), &AttachmentFetch{
+				URL:         att.URL,
+				Name:        att.Name,
+				TypeHint:    att.Type,
+				ContentType: file.ContentType,
+				Data:        file.Data,
+			})
+			if archiveErr != nil {
+				s.logger.Warn("Failed to archive attachment",
+					zap.String("name", att.Name),
+					zap.Error(archiveErr),
+				)
+			} else {
+				result.AttachmentID = archived.ID
+			}
+		}
+
 		// Parse content based on type
-		content, parseErr := s.parseAttachmentContent(localPath, att.Type)
+		content, rows, parseErr := s.parseAttachmentContent(localPath, att.Type, profile)
 		if parseErr != nil {
 			s.logger.Warn("Failed to parse attachment",
 				zap.String("name", att.Name),
@@ -2126,9 +2224,11 @@ func (s *FenbiService) processAttachments(spider *crawler.FenbiSpider, attachmen
 		} else {
 			// Store full content - no truncation for LLM analysis
 			result.Content = content
+			result.Rows = rows
 			s.logger.Info("Parsed attachment content",
 				zap.String("name", att.Name),
 				zap.Int("content_length", len(content)),
+				zap.Int("rows", rows),
 			)
 		}
 
@@ -2138,26 +2238,52 @@ func (s *FenbiService) processAttachments(spider *crawler.FenbiSpider, attachmen
 	return results
 }
 
-// parseAttachmentContent parses attachment content based on file type
-func (s *FenbiService) parseAttachmentContent(filePath, fileType string) (string, error) {
+// parseAttachmentContent parses attachment content based on file type.
+// rows is the number of position rows found by the structural parsers, used
+// as the completeness target of the LLM extraction (0 = unknown)
+func (s *FenbiService) parseAttachmentContent(filePath, fileType string, profile *parser.ColumnProfile) (string, int, error) {
 	switch fileType {
 	case "pdf":
 		pdfParser := parser.NewPDFParser(s.logger)
-		return pdfParser.ExtractText(filePath)
+		text, err := pdfParser.ExtractText(filePath)
+		if err != nil || len(strings.TrimSpace(text)) >= 100 || s.ocrParser == nil || !s.ocrParser.IsAvailable() {
+			return text, 0, err
+		}
+		// 扫描件没有文字层，逐页 OCR 并按坐标还原表格行
+		s.logger.Info("PDF attachment looks scanned, running OCR", zap.String("file", filePath))
+		ocrParser := *s.ocrParser
+		ocrParser.SetProfile(profile)
+		text, tables, err := ocrParser.ExtractPDFTextAndTables(filePath)
+		return text, len(parser.FlattenTables(tables)), err
 	case "word":
 		wordParser := parser.NewWordParser(s.logger)
-		return wordParser.ExtractText(filePath)
+		text, err := wordParser.ExtractText(filePath)
+		return text, 0, err
 	case "excel":
 		excelParser := parser.NewExcelParser(s.logger)
-		return excelParser.ExtractText(filePath)
+		text, err := excelParser.ExtractText(filePath)
+		if err != nil {
+			return "", 0, err
+		}
+		excelParser.SetProfile(profile)
+		tables, err := excelParser.ParseDetailed(filePath)
+		if err != nil {
+			s.logger.Debug("Failed to parse attachment tables", zap.String("file", filePath), zap.Error(err))
+			return text, 0, nil
+		}
+		return text, len(parser.FlattenTables(tables)), nil
 	default:
-		return "", fmt.Errorf("unsupported file type: %s", fileType)
+		return "", 0, fmt.Errorf("unsupported file type: %s", fileType)
 	}
 }
 
-// performLLMAnalysis uses LLM to analyze page content and attachments
+// performLLMAnalysis uses LLM to analyze page content and attachments.
+// The summary and exam info come from one short call on the announcement
+// text; positions are extracted chunk by chunk by ai.AIExtractor so long
+// position tables are not truncated, checked against the row counts of the
+// structural parsers.
 // llmConfigID: optional LLM config ID to use (0 = use default)
-func (s *FenbiService) performLLMAnalysis(pageContent *crawler.PageContent, attachments []AttachmentResult, llmConfigID uint) *LLMAnalysisResult {
+func (s *FenbiService) performLLMAnalysis(pageContent *crawler.PageContent, attachments []AttachmentResult, profile *parser.ColumnProfile, llmConfigID uint) *LLMAnalysisResult {
 	result := &LLMAnalysisResult{}
 
 	// Check if LLM service is available
@@ -2166,119 +2292,130 @@ func (s *FenbiService) performLLMAnalysis(pageContent *crawler.PageContent, atta
 		return result
 	}
 
-	// Build prompt for LLM analysis
-	var promptBuilder strings.Builder
-	promptBuilder.WriteString(`你是一个专业的公务员/事业单位招聘公告分析助手。请仔细分析以下招聘公告内容，**特别注意从附件（如岗位表、职位表）中提取详细的职位信息**。
-
-## 分析任务
-1. 提取公告摘要（包含招录总人数、招录单位数量、报名时间等关键信息）
-2. **重点任务**：从附件的职位表/岗位表中提取每个具体职位的详细信息
-3. 提取考试相关时间信息
-
-`)
-	promptBuilder.WriteString("## 公告标题\n")
-	promptBuilder.WriteString(pageContent.Title)
-	promptBuilder.WriteString("\n\n## 公告正文\n")
-
-	// Include full content - Gemini supports 1M tokens
-	promptBuilder.WriteString(pageContent.Text)
+	if err := s.analyzeAnnouncementSummary(pageContent, llmConfigID, result); err != nil {
+		s.logger.Warn("LLM analysis failed", zap.Error(err), zap.Uint("llm_config_id", llmConfigID))
+		result.Error = fmt.Sprintf("LLM调用失败: %v", err)
+		return result
+	}
 
-	// Add attachment content - NO truncation for attachments as they contain the position table
-	// Gemini 1.5 Pro supports 1M tokens, so we can include very large attachment content
-	hasAttachments := false
-	totalAttachmentChars := 0
-	const maxTotalAttachmentChars = 2000000 // 2M chars total for all attachments (~500k tokens)
+	// Document for position extraction: announcement body followed by the attachments
+	var doc strings.Builder
+	doc.WriteString("## 公告标题\n")
+	doc.WriteString(pageContent.Title)
+	doc.WriteString("\n\n## 公告正文\n")
+	doc.WriteString(pageContent.Text)
 
+	expectedRows := 0
+	if pageContent.HTML != "" {
+		htmlParser := parser.NewHTMLTableParser(s.logger)
+		htmlParser.SetProfile(profile)
+		if tables, err := htmlParser.ParseTablesDetailed(pageContent.HTML); err == nil {
+			expectedRows += len(parser.FlattenTables(tables))
+		}
+	}
 	for _, att := range attachments {
-		if att.Content != "" && att.Error == "" {
-			hasAttachments = true
-			promptBuilder.WriteString(fmt.Sprintf("\n\n## 附件: %s\n", att.Name))
-			promptBuilder.WriteString("**请从此附件中提取所有职位信息**\n")
-
-			attContent := att.Content
-			remainingChars := maxTotalAttachmentChars - totalAttachmentChars
-
-			// Only truncate if we're approaching the total limit
-			if len(attContent) > remainingChars && remainingChars > 0 {
-				attContent = attContent[:remainingChars] + fmt.Sprintf("...(附件内容已截断，原始长度: %d 字符)", len(att.Content))
-				s.logger.Warn("Attachment content truncated due to total limit",
-					zap.String("attachment", att.Name),
-					zap.Int("original_length", len(att.Content)),
-					zap.Int("truncated_to", remainingChars),
-				)
-			}
+		if att.Content == "" || att.Error != "" {
+			continue
+		}
+		doc.WriteString(fmt.Sprintf("\n\n## 附件: %s\n", att.Name))
+		doc.WriteString(att.Content)
+		expectedRows += att.Rows
+	}
 
-			promptBuilder.WriteString(attContent)
-			totalAttachmentChars += len(attContent)
+	s.logger.Info("Calling LLM for position extraction",
+		zap.Int("content_length", doc.Len()),
+		zap.Int("expected_rows", expectedRows),
+		zap.Uint("llm_config_id", llmConfigID),
+	)
 
-			s.logger.Info("Added attachment content to LLM prompt",
-				zap.String("attachment", att.Name),
-				zap.Int("content_length", len(attContent)),
-				zap.Int("total_attachment_chars", totalAttachmentChars),
-			)
+	extractor := ai.NewAIExtractor(nil, s.logger)
+	extracted, err := extractor.ExtractPositionsWithOptions(context.Background(), doc.String(), &ai.PositionExtractionOptions{
+		ExpectedRows: expectedRows,
+		Complete: func(ctx context.Context, prompt string) (string, error) {
+			return s.llmConfigService.CallWithConfigID(llmConfigID, prompt, 300, extractor.Config.MaxOutputTokens)
+		},
+	})
+	if err != nil {
+		s.logger.Warn("LLM position extraction failed", zap.Error(err), zap.Uint("llm_config_id", llmConfigID))
+		result.Error = fmt.Sprintf("LLM调用失败: %v", err)
+		return result
+	}
+
+	for _, pos := range extracted.Positions {
+		result.Positions = append(result.Positions, ExtractedPositionInfo{
+			PositionName:    pos.PositionName,
+			DepartmentName:  pos.DepartmentName,
+			RecruitCount:    pos.RecruitCount,
+			Education:       pos.EducationMin,
+			Major:           pos.MajorSpecific,
+			WorkLocation:    pos.WorkLocation,
+			PoliticalStatus: pos.PoliticalStatus,
+		})
+	}
+	if result.ExamInfo == nil && extracted.ExamInfo != nil {
+		result.ExamInfo = &ExtractedExamInfo{
+			ExamType:          extracted.ExamInfo.ExamType,
+			RegistrationStart: extracted.ExamInfo.RegistrationStart,
+			RegistrationEnd:   extracted.ExamInfo.RegistrationEnd,
+			ExamDate:          extracted.ExamInfo.ExamDateWritten,
 		}
 	}
+	if len(extracted.Positions) > 0 || expectedRows > 0 {
+		result.Confidence = extracted.Confidence
+	}
+	result.Warnings = extracted.Warnings
+
+	s.logger.Info("LLM analysis completed",
+		zap.String("summary", result.Summary),
+		zap.Int("positions_count", len(result.Positions)),
+		zap.Int("expected_rows", extracted.ExpectedRows),
+		zap.Int("chunks", extracted.Chunks),
+		zap.Int("failed_chunks", extracted.FailedChunks),
+	)
 
-	if hasAttachments {
-		promptBuilder.WriteString("\n\n**重要提示**：请务必从上述附件中提取所有职位信息，每个职位单独列出。\n")
+	return result
+}
+
+// analyzeAnnouncementSummary asks the LLM for the summary and exam info of
+// the announcement. Attachments are left out: their positions are extracted
+// separately.
+func (s *FenbiService) analyzeAnnouncementSummary(pageContent *crawler.PageContent, llmConfigID uint, result *LLMAnalysisResult) error {
+	const maxSummaryChars = 20000
+
+	text := pageContent.Text
+	if runes := []rune(text); len(runes) > maxSummaryChars {
+		text = string(runes[:maxSummaryChars])
 	}
 
+	var promptBuilder strings.Builder
+	promptBuilder.WriteString(`你是一个专业的公务员/事业单位招聘公告分析助手。请仔细分析以下招聘公告内容，提取公告摘要（包含招录总人数、招录单位数量、报名时间等关键信息）和考试相关时间信息。
+
+`)
+	promptBuilder.WriteString("## 公告标题\n")
+	promptBuilder.WriteString(pageContent.Title)
+	promptBuilder.WriteString("\n\n## 公告正文\n")
+	promptBuilder.WriteString(text)
+
 	promptBuilder.WriteString("\n\n请严格按照以下JSON格式返回分析结果（直接返回JSON，不要添加markdown代码块）：\n")
 	promptBuilder.WriteString(`{
   "summary": "公告摘要，需包含：招录总人数、涉及单位数量、报名方式、考试方式等(150-300字)",
-  "positions": [
-    {
-      "position_name": "具体职位名称（必填）",
-      "department_name": "招录单位/部门名称",
-      "recruit_count": 招录人数(数字，必填),
-      "education": "学历要求（如：本科及以上、研究生等）",
-      "major": ["专业要求列表，多个专业用数组"],
-      "work_location": "工作地点",
-      "political_status": "政治面貌要求（如：中共党员、共青团员、不限等，仅在明确要求时填写）"
-    }
-  ],
   "exam_info": {
     "exam_type": "考试类型（如：事业单位公开招聘、省考、选调等）",
     "registration_start": "报名开始时间",
     "registration_end": "报名截止时间",
     "exam_date": "笔试/考试时间"
   },
-  "confidence": 分析置信度(0-100的整数，如果能完整提取职位信息则为80以上)
-}
-
-注意事项：
-1. positions数组必须包含从附件职位表中提取的所有职位，不要遗漏
-2. 如果职位表内容很多，至少提取前30个职位
-3. recruit_count必须是数字类型
-4. 如果某个字段信息不明确，可以省略该字段，但position_name和recruit_count是必填项
-5. political_status字段：如果公告明确要求党员或有政治面貌限制，请提取；如无明确要求可省略或填"不限"`)
-
-	s.logger.Info("Calling LLM for analysis",
-		zap.Int("prompt_length", promptBuilder.Len()),
-		zap.Uint("llm_config_id", llmConfigID),
-	)
+  "confidence": 分析置信度(0-100的整数)
+}`)
 
-	// Call LLM with extended timeout (5 minutes) and large max_tokens (32k) for complete position extraction
-	// Use specified config ID or default if 0
-	response, err := s.llmConfigService.CallWithConfigID(llmConfigID, promptBuilder.String(), 300, 32768)
+	response, err := s.llmConfigService.CallWithConfigID(llmConfigID, promptBuilder.String(), 120, 2048)
 	if err != nil {
-		s.logger.Warn("LLM analysis failed", zap.Error(err), zap.Uint("llm_config_id", llmConfigID))
-		result.Error = fmt.Sprintf("LLM调用失败: %v", err)
-		return result
+		return err
 	}
 
 	result.RawResponse = response
-
-	// Parse LLM response as JSON
 	s.parseLLMAnalysisResponse(response, result)
-
-	s.logger.Info("LLM analysis completed",
-		zap.Int("response_length", len(response)),
-		zap.String("summary", result.Summary),
-		zap.Int("positions_count", len(result.Positions)),
-	)
-
-	return result
+	return nil
 }
 
 // LLMAnalysisJSON is the expected JSON structure from LLM
@@ -0,0 +1,630 @@
+package service
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/what-cse/server/internal/anki"
+	"github.com/what-cse/server/internal/model"
+	"github.com/what-cse/server/internal/repository"
+	"github.com/what-cse/server/internal/srs"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrFlashCardDeckFormat    = errors.New("不支持的格式，请使用 apkg 或 csv")
+	ErrFlashCardDeckEmpty     = errors.New("没有可导出的卡片")
+	ErrFlashCardDeckTooMany   = errors.New("卡片数量超过导出上限，请按类型或分类筛选")
+	ErrFlashCardImportFormat  = errors.New("不支持的文件格式，请上传 .apkg/.csv/.txt/.tsv")
+	ErrFlashCardImportEmpty   = errors.New("文件中没有可导入的卡片")
+	ErrFlashCardImportTooMany = errors.New("卡片数量超过单次导入上限")
+)
+
+const (
+	// maxDeckCards 单次导出/导入的最多卡片数
+	maxDeckCards = 5000
+	// maxDeckImageSize 导入时内嵌为 data URI 的单张图片大小上限
+	maxDeckImageSize = 200 << 10
+	// maxDeckImportErrors 导入结果中最多返回的错误条数
+	maxDeckImportErrors = 20
+	// deckGUIDPrefix 导出卡片 GUID 的前缀，同一张卡片多次导出到 Anki 时更新而非重复
+	deckGUIDPrefix = "what-cse:flash-card:"
+)
+
+// deckFields 导出牌组的字段顺序，第一个字段为 Anki 的排序与去重字段
+var deckFields = []string{"Front", "Back", "Title", "Example", "Mnemonic", "Type"}
+
+var (
+	dataURIImageRe = regexp.MustCompile(`!\[[^\]]*\]\((data:(image/[a-z0-9.+-]+);base64,([A-Za-z0-9+/=]+))\)`)
+	urlImageRe     = regexp.MustCompile(`!\[[^\]]*\]\((https?://[^)\s]+)\)`)
+)
+
+// FlashCardDeckService 速记卡片牌组导入导出服务（Anki .apkg 与 CSV）
+type FlashCardDeckService struct {
+	cardRepo        *repository.FlashCardRepository
+	recordRepo      *repository.UserFlashCardRecordRepository
+	reviewRepo      *repository.ReviewRepository
+	reviewScheduler *ReviewSchedulerService
+	logger          *zap.Logger
+}
+
+// NewFlashCardDeckService 创建牌组导入导出服务
+func NewFlashCardDeckService(
+	cardRepo *repository.FlashCardRepository,
+	recordRepo *repository.UserFlashCardRecordRepository,
+	reviewRepo *repository.ReviewRepository,
+	logger *zap.Logger,
+) *FlashCardDeckService {
+	return &FlashCardDeckService{
+		cardRepo:   cardRepo,
+		recordRepo: recordRepo,
+		reviewRepo: reviewRepo,
+		logger:     logger,
+	}
+}
+
+// SetReviewScheduler 注入统一复习调度服务，导入复习历史时按用户的算法重放
+func (s *FlashCardDeckService) SetReviewScheduler(reviewScheduler *ReviewSchedulerService) {
+	s.reviewScheduler = reviewScheduler
+}
+
+// =====================================================
+// 导出
+// =====================================================
+
+// FlashCardDeckExportRequest 牌组导出请求
+type FlashCardDeckExportRequest struct {
+	Format     string              `json:"format"`                // apkg / csv，默认 apkg
+	CardType   model.FlashCardType `json:"card_type,omitempty"`   // 按卡片类型筛选
+	CategoryID uint                `json:"category_id,omitempty"` // 按分类筛选
+	Due        bool                `json:"due"`                   // 仅导出今日到期的卡片
+	DeckName   string              `json:"deck_name,omitempty"`   // 牌组名称
+}
+
+// Export 导出用户可见的卡片（公共卡片与自己导入的卡片）或今日到期卡片
+// apkg 会带上用户的复习状态与复习日志，Anki 导入后可直接继续复习
+func (s *FlashCardDeckService) Export(userID uint, req *FlashCardDeckExportRequest) (*PrintFile, error) {
+	format := strings.ToLower(req.Format)
+	if format == "" {
+		format = "apkg"
+	}
+	if format != "apkg" && format != "csv" {
+		return nil, ErrFlashCardDeckFormat
+	}
+
+	cards, err := s.exportCards(userID, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(cards) == 0 {
+		return nil, ErrFlashCardDeckEmpty
+	}
+	if len(cards) > maxDeckCards {
+		return nil, ErrFlashCardDeckTooMany
+	}
+
+	deckName := strings.TrimSpace(req.DeckName)
+	if deckName == "" {
+		deckName = "速记卡片"
+		if req.Due {
+			deckName += "::今日复习"
+		}
+	}
+
+	if format == "csv" {
+		data, err := writeDeckCSV(cards)
+		if err != nil {
+			return nil, err
+		}
+		return &PrintFile{Filename: deckFileName(deckName) + ".csv", ContentType: "text/csv; charset=utf-8", Data: data}, nil
+	}
+
+	ids := make([]uint, len(cards))
+	for i, card := range cards {
+		ids[i] = card.ID
+	}
+	records, err := s.recordRepo.GetByUserAndCards(userID, ids)
+	if err != nil {
+		return nil, err
+	}
+	logs, err := s.reviewRepo.GetItemLogs(userID, model.ReviewItemFlashCard, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	deck := &anki.Deck{
+		Name: deckName,
+		Model: anki.Model{
+			Name:           "速记卡片",
+			Fields:         deckFields,
+			QuestionFormat: `{{#Title}}<div class="title">{{Title}}</div>{{/Title}}{{Front}}`,
+			AnswerFormat: `{{FrontSide}}<hr id=answer>{{Back}}` +
+				`{{#Example}}<div class="example">{{Example}}</div>{{/Example}}` +
+				`{{#Mnemonic}}<div class="mnemonic">{{Mnemonic}}</div>{{/Mnemonic}}`,
+			CSS: ".card { font-family: sans-serif; font-size: 20px; text-align: left; }\n" +
+				".title { color: #888; font-size: 14px; margin-bottom: 8px; }\n" +
+				".example, .mnemonic { margin-top: 12px; font-size: 16px; color: #555; }",
+		},
+		Media: make(map[string][]byte),
+	}
+	for _, card := range cards {
+		note := anki.Note{
+			GUID: anki.GUID(fmt.Sprintf("%s%d", deckGUIDPrefix, card.ID)),
+			Fields: []string{
+				deckFieldHTML(card.FrontContent, deck.Media),
+				deckFieldHTML(card.BackContent, deck.Media),
+				deckFieldHTML(card.Title, deck.Media),
+				deckFieldHTML(card.Example, deck.Media),
+				deckFieldHTML(card.Mnemonic, deck.Media),
+				string(card.CardType),
+			},
+			Tags: append([]string{string(card.CardType)}, card.Tags...),
+		}
+		if record := records[card.ID]; record != nil {
+			note.Card = ankiCardState(record)
+			note.Reviews = ankiReviews(logs[card.ID], record.EaseFactor)
+		}
+		deck.Notes = append(deck.Notes, note)
+	}
+
+	var buf bytes.Buffer
+	if err := anki.WritePackage(&buf, deck); err != nil {
+		return nil, fmt.Errorf("生成 apkg 失败: %w", err)
+	}
+	return &PrintFile{Filename: deckFileName(deckName) + ".apkg", ContentType: "application/octet-stream", Data: buf.Bytes()}, nil
+}
+
+// exportCards 按请求选出要导出的卡片，多取一张用于判断是否超出上限
+func (s *FlashCardDeckService) exportCards(userID uint, req *FlashCardDeckExportRequest) ([]*model.KnowledgeFlashCard, error) {
+	if !req.Due {
+		return s.cardRepo.ListForUser(userID, req.CardType, req.CategoryID, maxDeckCards+1)
+	}
+	records, err := s.reviewRepo.DueFlashCards(userID, startOfDay(time.Now()).AddDate(0, 0, 1), maxDeckCards+1)
+	if err != nil {
+		return nil, err
+	}
+	cards := make([]*model.KnowledgeFlashCard, 0, len(records))
+	for _, record := range records {
+		if record.Card == nil {
+			continue
+		}
+		if req.CardType != "" && record.Card.CardType != req.CardType {
+			continue
+		}
+		if req.CategoryID > 0 && (record.Card.CategoryID == nil || *record.Card.CategoryID != req.CategoryID) {
+			continue
+		}
+		cards = append(cards, record.Card)
+	}
+	return cards, nil
+}
+
+// deckFieldHTML 把卡片文本转为 Anki 字段 HTML，内嵌的 data URI 图片拆为媒体文件
+func deckFieldHTML(text string, media map[string][]byte) string {
+	var out strings.Builder
+	last := 0
+	for _, m := range dataURIImageRe.FindAllStringSubmatchIndex(text, -1) {
+		out.WriteString(anki.TextToHTML(text[last:m[0]]))
+		last = m[1]
+		data, err := base64.StdEncoding.DecodeString(text[m[6]:m[7]])
+		if err != nil {
+			continue
+		}
+		ext := strings.TrimPrefix(text[m[4]:m[5]], "image/")
+		if ext == "svg+xml" {
+			ext = "svg"
+		}
+		name := fmt.Sprintf("%s.%s", anki.GUID(string(data)), ext)
+		media[name] = data
+		out.WriteString(fmt.Sprintf(`<img src="%s">`, name))
+	}
+	out.WriteString(anki.TextToHTML(text[last:]))
+	return out.String()
+}
+
+// ankiCardState 把用户卡片记录转为 Anki 卡片状态，未学习过的记录按新卡片导出
+func ankiCardState(record *model.UserFlashCardRecord) *anki.CardState {
+	card := flashCardRecordCard(record)
+	if card.State == srs.StateNew || record.NextReviewAt == nil {
+		return nil
+	}
+	state := &anki.CardState{
+		Type:     anki.CardReview,
+		Due:      record.NextReviewAt,
+		Interval: record.Interval,
+		Ease:     record.EaseFactor,
+		Reps:     record.ReviewCount,
+		Lapses:   record.Lapses,
+	}
+	switch card.State {
+	case srs.StateLearning:
+		state.Type = anki.CardLearning
+	case srs.StateRelearning:
+		state.Type = anki.CardRelearning
+	}
+	return state
+}
+
+// ankiReviews 把复习日志转为 Anki revlog
+func ankiReviews(logs []model.ReviewLog, ease float64) []anki.Review {
+	reviews := make([]anki.Review, 0, len(logs))
+	lastInterval := 0
+	for _, log := range logs {
+		review := anki.Review{
+			Time:         log.ReviewedAt,
+			Ease:         log.Rating,
+			Interval:     log.ScheduledDays,
+			LastInterval: lastInterval,
+			Factor:       ease,
+			Type:         anki.ReviewReview,
+		}
+		switch srs.State(log.State) {
+		case srs.StateNew, srs.StateLearning:
+			review.Type = anki.ReviewLearn
+		case srs.StateRelearning:
+			review.Type = anki.ReviewRelearn
+		}
+		reviews = append(reviews, review)
+		lastInterval = log.ScheduledDays
+	}
+	return reviews
+}
+
+// writeDeckCSV 写出带 Anki 文件头的 CSV，Anki 与本系统均可直接导入
+func writeDeckCSV(cards []*model.KnowledgeFlashCard) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\xEF\xBB\xBF")
+	buf.WriteString("#separator:Comma\n#html:false\n#columns:Front,Back,Title,Example,Mnemonic,Type,Tags\n#tags column:7\n")
+	w := csv.NewWriter(&buf)
+	for _, card := range cards {
+		tags := make([]string, 0, len(card.Tags)+1)
+		for _, tag := range append([]string{string(card.CardType)}, card.Tags...) {
+			if tag = strings.Join(strings.Fields(tag), "_"); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		if err := w.Write([]string{
+			card.FrontContent, card.BackContent, card.Title, card.Example, card.Mnemonic,
+			string(card.CardType), strings.Join(tags, " "),
+		}); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func deckFileName(deckName string) string {
+	return strings.NewReplacer("::", "-", "/", "-", "\\", "-").Replace(deckName)
+}
+
+// =====================================================
+// 导入
+// =====================================================
+
+// FlashCardDeckMapping 字段映射，值为 apkg 字段名或 CSV 列名/从 1 开始的列号
+type FlashCardDeckMapping struct {
+	Front    string `json:"front"`    // 默认 Front/正面 字段或第 1 列
+	Back     string `json:"back"`     // 默认 Back/背面 字段或第 2 列
+	Title    string `json:"title"`    // 为空时取正面首行
+	Example  string `json:"example"`  // 默认 Example/例句 字段
+	Mnemonic string `json:"mnemonic"` // 默认 Mnemonic/记忆技巧 字段
+	Tags     string `json:"tags"`     // 以空格分隔的标签，与笔记自带标签合并
+}
+
+// FlashCardDeckImportRequest 牌组导入请求
+type FlashCardDeckImportRequest struct {
+	Path          string
+	FileName      string
+	CardType      model.FlashCardType
+	CategoryID    *uint
+	Mapping       FlashCardDeckMapping
+	Delimiter     rune // CSV 分隔符，0 表示自动识别
+	HasHeader     bool // CSV 首行为列名（文件带 #columns: 头时忽略）
+	ImportHistory bool // 带入 apkg 中的复习状态与复习历史
+}
+
+// FlashCardDeckImportResult 牌组导入结果
+type FlashCardDeckImportResult struct {
+	Total       int      `json:"total"`
+	Created     int      `json:"created"`
+	Skipped     int      `json:"skipped"`      // 重复或缺少正反面的条目
+	WithHistory int      `json:"with_history"` // 带入复习历史的卡片数
+	Errors      []string `json:"errors,omitempty"`
+}
+
+// deckEntry 从文件中解析出的一条卡片
+type deckEntry struct {
+	externalID string
+	front      string
+	back       string
+	title      string
+	example    string
+	mnemonic   string
+	tags       []string
+	note       *anki.Note // apkg 来源的笔记，用于带入复习历史
+}
+
+// Import 从 Anki .apkg 或 CSV/TSV 文件创建用户私有卡片，重复导入按来源标识跳过
+func (s *FlashCardDeckService) Import(userID uint, req *FlashCardDeckImportRequest) (*FlashCardDeckImportResult, error) {
+	var entries []deckEntry
+	var err error
+	switch strings.ToLower(filepath.Ext(req.FileName)) {
+	case ".apkg", ".colpkg":
+		entries, err = readDeckPackage(req)
+	case ".csv", ".txt", ".tsv":
+		entries, err = readDeckText(req)
+	default:
+		return nil, ErrFlashCardImportFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrFlashCardImportEmpty
+	}
+	if len(entries) > maxDeckCards {
+		return nil, ErrFlashCardImportTooMany
+	}
+
+	existing, err := s.cardRepo.GetOwnerExternalIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	cardType := req.CardType
+	if cardType == "" {
+		cardType = model.FlashCardTypeOther
+	}
+	result := &FlashCardDeckImportResult{Total: len(entries)}
+	var cards []*model.KnowledgeFlashCard
+	var sources []deckEntry
+	for i, entry := range entries {
+		if entry.front == "" || entry.back == "" {
+			result.Skipped++
+			if len(result.Errors) < maxDeckImportErrors {
+				result.Errors = append(result.Errors, fmt.Sprintf("第 %d 条缺少正面或背面内容", i+1))
+			}
+			continue
+		}
+		if len(entry.externalID) > 64 {
+			entry.externalID = entry.externalID[:64]
+		}
+		if existing[entry.externalID] {
+			result.Skipped++
+			continue
+		}
+		existing[entry.externalID] = true
+
+		ownerID := userID
+		cards = append(cards, &model.KnowledgeFlashCard{
+			CategoryID:   req.CategoryID,
+			CardType:     cardType,
+			Title:        entry.title,
+			FrontContent: entry.front,
+			BackContent:  entry.back,
+			Example:      entry.example,
+			Mnemonic:     entry.mnemonic,
+			Tags:         model.JSONStrArray(entry.tags),
+			Difficulty:   3,
+			Importance:   3,
+			IsActive:     true,
+			OwnerID:      &ownerID,
+			ExternalID:   entry.externalID,
+		})
+		sources = append(sources, entry)
+	}
+	if err := s.cardRepo.BatchCreate(cards); err != nil {
+		return nil, err
+	}
+	result.Created = len(cards)
+
+	if !req.ImportHistory {
+		return result, nil
+	}
+	var records []*model.UserFlashCardRecord
+	for i, card := range cards {
+		note := sources[i].note
+		if note == nil || note.Card == nil || note.Card.Type == anki.CardNew || note.Card.Due == nil {
+			continue
+		}
+		record, err := s.importedRecord(userID, card.ID, note)
+		if err != nil {
+			s.logger.Warn("Failed to import flash card review history",
+				zap.Uint("user_id", userID), zap.Uint("card_id", card.ID), zap.Error(err))
+			continue
+		}
+		records = append(records, record)
+	}
+	if err := s.recordRepo.BatchCreate(records); err != nil {
+		return nil, err
+	}
+	result.WithHistory = len(records)
+	return result, nil
+}
+
+// importedRecord 重放 Anki 复习历史得到记忆状态，再以 Anki 的到期时间与间隔为准生成学习记录
+func (s *FlashCardDeckService) importedRecord(userID, cardID uint, note *anki.Note) (*model.UserFlashCardRecord, error) {
+	var reviews []ImportedReview
+	correct, wrong := 0, 0
+	for _, r := range note.Reviews {
+		if r.Type == anki.ReviewManual || r.Ease < 1 || r.Ease > 4 {
+			continue
+		}
+		reviews = append(reviews, ImportedReview{Rating: srs.Rating(r.Ease), ReviewedAt: r.Time})
+		if r.Ease == int(srs.Again) {
+			wrong++
+		} else {
+			correct++
+		}
+	}
+	card, err := s.reviewScheduler.ImportHistory(userID, model.ReviewItemFlashCard, cardID, reviews)
+	if err != nil {
+		return nil, err
+	}
+
+	state := note.Card
+	record := &model.UserFlashCardRecord{UserID: userID, CardID: cardID, EaseFactor: 2.5}
+	if len(reviews) > 0 {
+		applyFlashCardRecord(record, card)
+	} else {
+		// 没有复习日志（如 Anki 中重置过历史）时按旧版调度的方式由间隔推断记忆状态
+		correct, wrong = max(state.Reps-state.Lapses, 0), state.Lapses
+	}
+	record.NextReviewAt = state.Due
+	record.Interval = state.Interval
+	record.ReviewCount = max(state.Reps, len(reviews))
+	record.Lapses = max(state.Lapses, card.Lapses)
+	record.CorrectCount, record.WrongCount = correct, wrong
+	if state.Ease > 0 {
+		record.EaseFactor = state.Ease
+	}
+	if record.SRSState == "" && state.Type != anki.CardReview {
+		record.SRSState = string(srs.StateLearning)
+	}
+	record.Status = flashCardStatus(record, flashCardRecordCard(record).State)
+	return record, nil
+}
+
+// readDeckPackage 解析 .apkg 中的笔记
+func readDeckPackage(req *FlashCardDeckImportRequest) ([]deckEntry, error) {
+	f, err := os.Open(req.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	pkg, err := anki.ReadPackage(f, info.Size())
+	if errors.Is(err, anki.ErrNoCollection) {
+		return nil, ErrFlashCardImportEmpty
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析 apkg 失败: %w", err)
+	}
+
+	image := func(src string) string {
+		data, err := pkg.Media(src)
+		if err != nil || len(data) > maxDeckImageSize {
+			return ""
+		}
+		return fmt.Sprintf("![](data:%s;base64,%s)", http.DetectContentType(data), base64.StdEncoding.EncodeToString(data))
+	}
+	entries := make([]deckEntry, 0, len(pkg.Notes))
+	for i := range pkg.Notes {
+		note := &pkg.Notes[i]
+		table := &anki.Table{Header: note.FieldNames}
+		entry := mapDeckEntry(table, note.Fields, note.Tags, req.Mapping, func(field string) string {
+			return anki.HTMLToText(field, image)
+		})
+		entry.externalID = note.GUID
+		entry.note = note
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// readDeckText 解析 CSV/TSV 文件，支持 Anki 文本导出的文件头
+func readDeckText(req *FlashCardDeckImportRequest) ([]deckEntry, error) {
+	f, err := os.Open(req.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	delimiter := req.Delimiter
+	if delimiter == 0 && strings.EqualFold(filepath.Ext(req.FileName), ".tsv") {
+		delimiter = '\t'
+	}
+	table, err := anki.ReadText(f, delimiter, req.HasHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	convert := strings.TrimSpace
+	if table.HTML {
+		convert = func(field string) string {
+			return anki.HTMLToText(field, func(src string) string {
+				if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+					return fmt.Sprintf("![](%s)", src)
+				}
+				return ""
+			})
+		}
+	}
+	entries := make([]deckEntry, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		var tags []string
+		if c := table.TagsColumn - 1; c >= 0 && c < len(row) {
+			tags = strings.Fields(row[c])
+		}
+		entry := mapDeckEntry(table, row, tags, req.Mapping, convert)
+		if c := table.GUIDColumn - 1; c >= 0 && c < len(row) && strings.TrimSpace(row[c]) != "" {
+			entry.externalID = strings.TrimSpace(row[c])
+		} else {
+			entry.externalID = anki.GUID(entry.front + "\x1f" + entry.back)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// mapDeckEntry 按字段映射取出卡片内容，未指定映射时按常见字段名或列序推断
+func mapDeckEntry(table *anki.Table, fields, tags []string, mapping FlashCardDeckMapping, convert func(string) string) deckEntry {
+	field := func(ref string, defaults ...string) string {
+		col := -1
+		if ref != "" {
+			col = table.Column(ref)
+		} else {
+			for _, name := range defaults {
+				if col = table.Column(name); col >= 0 {
+					break
+				}
+			}
+		}
+		if col < 0 || col >= len(fields) {
+			return ""
+		}
+		return convert(fields[col])
+	}
+
+	entry := deckEntry{
+		front:    field(mapping.Front, "Front", "正面", "1"),
+		back:     field(mapping.Back, "Back", "背面", "2"),
+		title:    field(mapping.Title, "Title", "标题"),
+		example:  field(mapping.Example, "Example", "例句"),
+		mnemonic: field(mapping.Mnemonic, "Mnemonic", "记忆技巧"),
+	}
+	if entry.title == "" {
+		entry.title = deckTitle(entry.front)
+	}
+	entry.tags = append(entry.tags, tags...)
+	if mapping.Tags != "" {
+		entry.tags = append(entry.tags, strings.Fields(field(mapping.Tags))...)
+	}
+	return entry
+}
+
+// deckTitle 取正面首行（去掉图片）作为卡片标题
+func deckTitle(front string) string {
+	line, _, _ := strings.Cut(front, "\n")
+	line = strings.TrimSpace(urlImageRe.ReplaceAllString(dataURIImageRe.ReplaceAllString(line, ""), ""))
+	if utf8.RuneCountInString(line) > 50 {
+		line = string([]rune(line)[:50]) + "…"
+	}
+	if line == "" {
+		line = "导入卡片"
+	}
+	return line
+}
@@ -0,0 +1,677 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/what-cse/server/internal/config"
+	"github.com/what-cse/server/internal/model"
+	"github.com/what-cse/server/internal/repository"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrIRTCalibrating      = errors.New("IRT 标定正在进行中")
+	ErrAdaptiveUnavailable = errors.New("自适应练习暂不可用")
+)
+
+const (
+	irtPriorScale          = 0.8  // 人工难度每差一级对应的 b 间隔，难度 3 对应 b = 0
+	irtDiscriminationSD    = 0.5  // 区分度先验 N(1, 0.5²)
+	irtDifficultySD        = 1.0  // 难度先验 N(人工难度换算值, 1)
+	irtThetaBound          = 4.0  // θ 与 b 的取值范围
+	irtMaxIterations       = 30   // 联合估计最大迭代轮数
+	irtTolerance           = 1e-3 // 参数最大变化低于该值视为收敛
+	irtMaxInformation      = 50.0 // 在线更新的信息量上限，避免长期用户的能力估计不再变化
+	irtCandidatePool       = 5    // 自适应选题在最接近目标的若干题中随机选，控制曝光
+	irtCategoryNewtonSteps = 10
+)
+
+// IRTCalibrationResult 一次标定结果
+type IRTCalibrationResult struct {
+	Responses   int       `json:"responses"`
+	Users       int       `json:"users"`
+	Questions   int       `json:"questions"`
+	Calibrated  int       `json:"calibrated"` // 作答人数达到下限、参数被估计的题目数
+	Abilities   int       `json:"abilities"`  // 重新估计的用户能力条数
+	Iterations  int       `json:"iterations"`
+	Converged   bool      `json:"converged"`
+	DurationMs  int64     `json:"duration_ms"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// IRTService 基于作答记录的 IRT (2PL) 题目标定、在线能力估计与自适应选题
+type IRTService struct {
+	repo         *repository.IRTRepository
+	questionRepo *repository.QuestionRepository
+	categoryRepo *repository.CourseCategoryRepository
+	cfg          config.IRTConfig
+	logger       *zap.Logger
+
+	abilityLocks userLocks // 按用户串行化能力的读改写
+	syncMu       sync.Mutex
+	resultMu     sync.RWMutex
+	lastResult   *IRTCalibrationResult
+}
+
+// NewIRTService 创建 IRT 服务
+func NewIRTService(
+	repo *repository.IRTRepository,
+	questionRepo *repository.QuestionRepository,
+	categoryRepo *repository.CourseCategoryRepository,
+	cfg config.IRTConfig,
+	logger *zap.Logger,
+) *IRTService {
+	if cfg.TargetProbability <= 0 || cfg.TargetProbability >= 1 {
+		cfg.TargetProbability = 0.7
+	}
+	if cfg.MinResponses <= 0 {
+		cfg.MinResponses = 30
+	}
+	if cfg.MaxResponses <= 0 {
+		cfg.MaxResponses = 500000
+	}
+	if cfg.LookbackDays <= 0 {
+		cfg.LookbackDays = 365
+	}
+	if cfg.MinAdaptive <= 0 {
+		cfg.MinAdaptive = 5
+	}
+	return &IRTService{
+		repo:         repo,
+		questionRepo: questionRepo,
+		categoryRepo: categoryRepo,
+		cfg:          cfg,
+		logger:       logger,
+	}
+}
+
+// irtProbability 2PL 答对概率
+func irtProbability(theta, a, b float64) float64 {
+	return 1 / (1 + math.Exp(-a*(theta-b)))
+}
+
+// irtPriorDifficulty 把人工难度 1-5 换算为 b
+func irtPriorDifficulty(difficulty int) float64 {
+	if difficulty <= 0 {
+		difficulty = 3
+	}
+	return float64(difficulty-3) * irtPriorScale
+}
+
+func clampFloat(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
+
+// updateTheta 用一道题的作答对 θ 的高斯后验做一步 Newton 更新，返回新的 θ 与信息量
+func updateTheta(theta, information, a, b float64, correct bool) (float64, float64) {
+	p := irtProbability(theta, a, b)
+	y := 0.0
+	if correct {
+		y = 1
+	}
+	information = math.Min(information+a*a*p*(1-p), irtMaxInformation)
+	theta = clampFloat(theta+a*(y-p)/information, -irtThetaBound, irtThetaBound)
+	return theta, information
+}
+
+// =====================================================
+// 标定
+// =====================================================
+
+// irtItem 标定过程中的题目状态
+type irtItem struct {
+	questionID uint
+	prior      float64
+	a, b, seB  float64
+	users      []int
+	answers    []bool
+	correct    int
+}
+
+// irtLearner 标定过程中的用户状态
+type irtLearner struct {
+	userID  uint
+	theta   float64
+	items   []int
+	answers []bool
+}
+
+// Calibrate 用首次作答记录联合估计题目参数 (a, b) 与用户能力 θ。
+// 采用带先验的交替 Newton 迭代（JML-MAP）：θ ~ N(0,1)，a ~ N(1,0.5²)，b ~ N(人工难度换算值,1)；
+// 作答人数不足 min_responses 的题目保持先验参数，只参与能力估计
+func (s *IRTService) Calibrate(ctx context.Context) (*IRTCalibrationResult, error) {
+	if !s.syncMu.TryLock() {
+		return nil, ErrIRTCalibrating
+	}
+	defer s.syncMu.Unlock()
+
+	started := time.Now()
+	responses, err := s.repo.LoadResponses(started.AddDate(0, 0, -s.cfg.LookbackDays), s.cfg.MaxResponses)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &IRTCalibrationResult{Responses: len(responses)}
+	if len(responses) == 0 {
+		result.CompletedAt = time.Now()
+		s.setLastResult(result)
+		return result, nil
+	}
+
+	items, learners := irtIndex(responses)
+	result.Users = len(learners)
+	result.Questions = len(items)
+
+	iterations, converged, err := jmlCalibrate(ctx, items, learners, s.cfg.MinResponses)
+	if err != nil {
+		return nil, err
+	}
+	result.Iterations = iterations
+	result.Converged = converged
+
+	// 保存题目参数
+	now := time.Now()
+	var params []model.QuestionIRTParam
+	for _, it := range items {
+		if len(it.users) < s.cfg.MinResponses {
+			continue
+		}
+		params = append(params, model.QuestionIRTParam{
+			QuestionID:     it.questionID,
+			Discrimination: round3(it.a),
+			Difficulty:     round3(it.b),
+			DifficultySE:   round3(it.seB),
+			ResponseCount:  len(it.users),
+			CorrectRate:    round3(float64(it.correct) / float64(len(it.users))),
+			CalibratedAt:   now,
+		})
+	}
+	if err := s.repo.SaveParams(params); err != nil {
+		return nil, err
+	}
+	result.Calibrated = len(params)
+
+	// 按新参数重新估计总体与分类能力
+	categoryOf := make(map[uint]uint, len(responses))
+	for _, r := range responses {
+		categoryOf[r.QuestionID] = r.CategoryID
+	}
+	abilities := s.estimateAbilities(learners, items, categoryOf)
+	if err := s.repo.SaveAbilities(abilities); err != nil {
+		return nil, err
+	}
+	result.Abilities = len(abilities)
+
+	result.DurationMs = time.Since(started).Milliseconds()
+	result.CompletedAt = time.Now()
+	s.setLastResult(result)
+
+	s.logger.Info("IRT calibration finished",
+		zap.Int("responses", result.Responses),
+		zap.Int("users", result.Users),
+		zap.Int("calibrated", result.Calibrated),
+		zap.Int("iterations", result.Iterations),
+		zap.Bool("converged", result.Converged),
+		zap.Int64("duration_ms", result.DurationMs))
+	return result, nil
+}
+
+// irtIndex 按首次作答记录建立题目与用户的作答索引，题目参数从先验出发
+func irtIndex(responses []repository.IRTResponse) ([]*irtItem, []*irtLearner) {
+	var items []*irtItem
+	var learners []*irtLearner
+	itemIndex := make(map[uint]int)
+	userIndex := make(map[uint]int)
+	for _, r := range responses {
+		ii, ok := itemIndex[r.QuestionID]
+		if !ok {
+			ii = len(items)
+			itemIndex[r.QuestionID] = ii
+			prior := irtPriorDifficulty(r.Difficulty)
+			items = append(items, &irtItem{questionID: r.QuestionID, prior: prior, a: 1, b: prior, seB: irtDifficultySD})
+		}
+		ui, ok := userIndex[r.UserID]
+		if !ok {
+			ui = len(learners)
+			userIndex[r.UserID] = ui
+			learners = append(learners, &irtLearner{userID: r.UserID})
+		}
+		items[ii].users = append(items[ii].users, ui)
+		items[ii].answers = append(items[ii].answers, r.IsCorrect)
+		if r.IsCorrect {
+			items[ii].correct++
+		}
+		learners[ui].items = append(learners[ui].items, ii)
+		learners[ui].answers = append(learners[ui].answers, r.IsCorrect)
+	}
+	return items, learners
+}
+
+// jmlCalibrate 交替估计 θ 与题目参数直到收敛，返回迭代轮数与是否收敛
+func jmlCalibrate(ctx context.Context, items []*irtItem, learners []*irtLearner, minResponses int) (int, bool, error) {
+	previous := make([]float64, len(learners))
+	for iter := 1; iter <= irtMaxIterations; iter++ {
+		if err := ctx.Err(); err != nil {
+			return iter - 1, false, err
+		}
+		maxDelta := 0.0
+
+		// 固定题目参数估计 θ
+		for i, l := range learners {
+			g, h := -l.theta, 1.0
+			for k, ii := range l.items {
+				it := items[ii]
+				p := irtProbability(l.theta, it.a, it.b)
+				y := 0.0
+				if l.answers[k] {
+					y = 1
+				}
+				g += it.a * (y - p)
+				h += it.a * it.a * p * (1 - p)
+			}
+			previous[i] = l.theta
+			l.theta = clampFloat(l.theta+clampFloat(g/h, -1, 1), -irtThetaBound, irtThetaBound)
+		}
+		// MAP 估计把 θ 向 0 收缩，题目参数随之放大区分度、压缩难度，
+		// 每轮把 θ 的离散程度还原为先验的标准差 1，固定量尺
+		standardizeThetaSpread(learners)
+		for i, l := range learners {
+			maxDelta = math.Max(maxDelta, math.Abs(l.theta-previous[i]))
+		}
+
+		// 固定 θ 估计题目参数（Fisher scoring）
+		for _, it := range items {
+			if len(it.users) < minResponses {
+				continue
+			}
+			ga := -(it.a - 1) / (irtDiscriminationSD * irtDiscriminationSD)
+			gb := -(it.b - it.prior) / (irtDifficultySD * irtDifficultySD)
+			iaa := 1 / (irtDiscriminationSD * irtDiscriminationSD)
+			ibb := 1 / (irtDifficultySD * irtDifficultySD)
+			iab := 0.0
+			for k, ui := range it.users {
+				theta := learners[ui].theta
+				p := irtProbability(theta, it.a, it.b)
+				y := 0.0
+				if it.answers[k] {
+					y = 1
+				}
+				r := y - p
+				d := theta - it.b
+				w := p * (1 - p)
+				ga += r * d
+				gb -= it.a * r
+				iaa += w * d * d
+				ibb += w * it.a * it.a
+				iab -= w * d * it.a
+			}
+			det := iaa*ibb - iab*iab
+			if det <= 1e-9 {
+				continue
+			}
+			da := clampFloat((ibb*ga-iab*gb)/det, -0.5, 0.5)
+			db := clampFloat((iaa*gb-iab*ga)/det, -1, 1)
+			nextA := clampFloat(it.a+da, 0.2, 4)
+			nextB := clampFloat(it.b+db, -irtThetaBound, irtThetaBound)
+			maxDelta = math.Max(maxDelta, math.Max(math.Abs(nextA-it.a), math.Abs(nextB-it.b)))
+			it.a, it.b = nextA, nextB
+			it.seB = math.Sqrt(iaa / det)
+		}
+
+		if maxDelta < irtTolerance {
+			return iter, true, nil
+		}
+	}
+	return irtMaxIterations, false, nil
+
+}
+
+// standardizeThetaSpread 保持 θ 均值不变，把标准差缩放为 1
+func standardizeThetaSpread(learners []*irtLearner) {
+	if len(learners) < 2 {
+		return
+	}
+	var mean float64
+	for _, l := range learners {
+		mean += l.theta
+	}
+	mean /= float64(len(learners))
+	var variance float64
+	for _, l := range learners {
+		variance += (l.theta - mean) * (l.theta - mean)
+	}
+	sd := math.Sqrt(variance / float64(len(learners)))
+	if sd < 1e-6 {
+		return
+	}
+	for _, l := range learners {
+		l.theta = clampFloat(mean+(l.theta-mean)/sd, -irtThetaBound, irtThetaBound)
+	}
+}
+
+// estimateAbilities 对每个用户计算总体 θ 的信息量，并按分类做 MAP 估计
+func (s *IRTService) estimateAbilities(learners []*irtLearner, items []*irtItem, categoryOf map[uint]uint) []model.UserAbility {
+	var abilities []model.UserAbility
+	for _, l := range learners {
+		info := 1.0
+		byCategory := make(map[uint][]int)
+		for k, ii := range l.items {
+			it := items[ii]
+			p := irtProbability(l.theta, it.a, it.b)
+			info += it.a * it.a * p * (1 - p)
+			if category := categoryOf[it.questionID]; category > 0 {
+				byCategory[category] = append(byCategory[category], k)
+			}
+		}
+		abilities = append(abilities, model.UserAbility{
+			UserID:        l.userID,
+			Theta:         round3(l.theta),
+			Information:   math.Min(info, irtMaxInformation),
+			ResponseCount: len(l.items),
+		})
+
+		for category, ks := range byCategory {
+			theta := l.theta
+			var h float64
+			for step := 0; step < irtCategoryNewtonSteps; step++ {
+				g := -theta
+				h = 1.0
+				for _, k := range ks {
+					it := items[l.items[k]]
+					p := irtProbability(theta, it.a, it.b)
+					y := 0.0
+					if l.answers[k] {
+						y = 1
+					}
+					g += it.a * (y - p)
+					h += it.a * it.a * p * (1 - p)
+				}
+				delta := clampFloat(g/h, -1, 1)
+				theta = clampFloat(theta+delta, -irtThetaBound, irtThetaBound)
+				if math.Abs(delta) < irtTolerance {
+					break
+				}
+			}
+			abilities = append(abilities, model.UserAbility{
+				UserID:        l.userID,
+				CategoryID:    category,
+				Theta:         round3(theta),
+				Information:   math.Min(h, irtMaxInformation),
+				ResponseCount: len(ks),
+			})
+		}
+	}
+	return abilities
+}
+
+// RunScheduled 定时标定入口，上一轮仍在执行时跳过
+func (s *IRTService) RunScheduled(ctx context.Context) error {
+	if _, err := s.Calibrate(ctx); err != nil && !errors.Is(err, ErrIRTCalibrating) {
+		return err
+	}
+	return nil
+}
+
+// setLastResult 记录最近一次标定结果，Status 会并发读取
+func (s *IRTService) setLastResult(result *IRTCalibrationResult) {
+	s.resultMu.Lock()
+	s.lastResult = result
+	s.resultMu.Unlock()
+}
+
+// IRTStatus 标定状态
+type IRTStatus struct {
+	CalibratedQuestions int64                 `json:"calibrated_questions"`
+	Calibrating         bool                  `json:"calibrating"`
+	LastResult          *IRTCalibrationResult `json:"last_result,omitempty"`
+}
+
+// Status 获取标定状态
+func (s *IRTService) Status() (*IRTStatus, error) {
+	count, err := s.repo.CountParams()
+	if err != nil {
+		return nil, err
+	}
+	s.resultMu.RLock()
+	status := &IRTStatus{CalibratedQuestions: count, LastResult: s.lastResult}
+	s.resultMu.RUnlock()
+	if s.syncMu.TryLock() {
+		s.syncMu.Unlock()
+	} else {
+		status.Calibrating = true
+	}
+	return status, nil
+}
+
+// GetQuestionParam 获取题目当前使用的参数，未标定时返回按人工难度换算的先验
+func (s *IRTService) GetQuestionParam(questionID uint) (*model.QuestionIRTParam, error) {
+	params, err := s.repo.GetParams([]uint{questionID})
+	if err != nil {
+		return nil, err
+	}
+	if p, ok := params[questionID]; ok {
+		return &p, nil
+	}
+	question, err := s.questionRepo.GetByID(questionID)
+	if err != nil {
+		return nil, ErrQuestionNotFound
+	}
+	return &model.QuestionIRTParam{
+		QuestionID:     questionID,
+		Discrimination: 1,
+		Difficulty:     irtPriorDifficulty(question.Difficulty),
+		DifficultySE:   irtDifficultySD,
+	}, nil
+}
+
+// =====================================================
+// 在线能力估计
+// =====================================================
+
+// itemParams 题目当前参数 (a, b)
+func (s *IRTService) itemParams(question *model.Question) (float64, float64) {
+	params, err := s.repo.GetParams([]uint{question.ID})
+	if err == nil {
+		if p, ok := params[question.ID]; ok {
+			return p.Discrimination, p.Difficulty
+		}
+	}
+	return 1, irtPriorDifficulty(question.Difficulty)
+}
+
+// RecordAnswer 作答后在线更新用户的总体能力和题目所属分类的能力
+func (s *IRTService) RecordAnswer(userID uint, question *model.Question, isCorrect bool) {
+	if s == nil || userID == 0 || question == nil {
+		return
+	}
+	a, b := s.itemParams(question)
+
+	defer s.abilityLocks.lock(userID)()
+
+	categories := []uint{0}
+	if question.CategoryID > 0 {
+		categories = append(categories, question.CategoryID)
+	}
+	abilities := make([]model.UserAbility, 0, len(categories))
+	for _, categoryID := range categories {
+		ability, err := s.repo.GetAbility(userID, categoryID)
+		if err != nil {
+			s.logger.Warn("Failed to load user ability", zap.Uint("user_id", userID), zap.Error(err))
+			return
+		}
+		if ability == nil {
+			ability = &model.UserAbility{UserID: userID, CategoryID: categoryID, Information: 1}
+		}
+		ability.Theta, ability.Information = updateTheta(ability.Theta, ability.Information, a, b, isCorrect)
+		ability.Theta = round3(ability.Theta)
+		ability.ResponseCount++
+		abilities = append(abilities, *ability)
+	}
+	if err := s.repo.SaveAbilities(abilities); err != nil {
+		s.logger.Warn("Failed to save user ability", zap.Uint("user_id", userID), zap.Error(err))
+	}
+}
+
+// currentTheta 获取用户在分类上的能力，分类尚无估计时退回总体能力
+func (s *IRTService) currentTheta(userID, categoryID uint) float64 {
+	if categoryID > 0 {
+		if ability, err := s.repo.GetAbility(userID, categoryID); err == nil && ability != nil {
+			return ability.Theta
+		}
+	}
+	if ability, err := s.repo.GetAbility(userID, 0); err == nil && ability != nil {
+		return ability.Theta
+	}
+	return 0
+}
+
+// GetAbilities 获取用户的总体与分类能力
+func (s *IRTService) GetAbilities(userID uint) ([]model.UserAbilityResponse, error) {
+	abilities, err := s.repo.GetUserAbilities(userID)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[uint]string)
+	if categories, err := s.categoryRepo.GetAll(); err == nil {
+		for _, c := range categories {
+			names[c.ID] = c.Name
+		}
+	}
+
+	responses := make([]model.UserAbilityResponse, 0, len(abilities))
+	for _, a := range abilities {
+		resp := model.UserAbilityResponse{
+			CategoryID:    a.CategoryID,
+			CategoryName:  names[a.CategoryID],
+			Theta:         a.Theta,
+			Percentile:    round2(50 * (1 + math.Erf(a.Theta/math.Sqrt2))),
+			ResponseCount: a.ResponseCount,
+			UpdatedAt:     a.UpdatedAt.Format("2006-01-02 15:04:05"),
+		}
+		if a.Information > 0 {
+			resp.SE = round3(1 / math.Sqrt(a.Information))
+		}
+		if a.CategoryID == 0 {
+			resp.CategoryName = "总体"
+		}
+		responses = append(responses, resp)
+	}
+	return responses, nil
+}
+
+// =====================================================
+// 自适应选题
+// =====================================================
+
+// targetOffset 目标概率对应的 a(θ-b)
+func (s *IRTService) targetOffset() float64 {
+	p := s.cfg.TargetProbability
+	return math.Log(p / (1 - p))
+}
+
+// pickCandidates 在最接近目标难度的候选中随机挑选 count 道题
+func (s *IRTService) pickCandidates(categoryIDs []uint, theta float64, excludeIDs []uint, count int) ([]uint, error) {
+	pool := count * 3
+	if pool < irtCandidatePool {
+		pool = irtCandidatePool
+	}
+	candidates, err := s.repo.FindCandidates(categoryIDs, theta, s.targetOffset(), irtPriorScale, excludeIDs, pool)
+	if err != nil {
+		return nil, err
+	}
+	rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	if len(candidates) > count {
+		candidates = candidates[:count]
+	}
+	ids := make([]uint, len(candidates))
+	for i, c := range candidates {
+		ids[i] = c.QuestionID
+	}
+	return ids, nil
+}
+
+// SelectQuestions 按用户能力挑选答对概率接近目标的题目，categoryID 为 0 时不限分类
+func (s *IRTService) SelectQuestions(userID, categoryID uint, count int, excludeIDs []uint) ([]model.Question, error) {
+	if count <= 0 {
+		return nil, nil
+	}
+	var categoryIDs []uint
+	if categoryID > 0 {
+		categoryIDs = []uint{categoryID}
+	}
+	ids, err := s.pickCandidates(categoryIDs, s.currentTheta(userID, categoryID), excludeIDs, count)
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	return s.questionRepo.GetByIDs(ids)
+}
+
+// StartAdaptive 初始化自适应练习状态：以已有能力估计为起点、单位信息量为先验重新测量
+func (s *IRTService) StartAdaptive(userID uint, categoryIDs []uint) *model.AdaptiveSessionState {
+	var categoryID uint
+	if len(categoryIDs) == 1 {
+		categoryID = categoryIDs[0]
+	}
+	theta := s.currentTheta(userID, categoryID)
+	return &model.AdaptiveSessionState{
+		CategoryID:  categoryID,
+		Target:      s.cfg.TargetProbability,
+		Theta:       theta,
+		Information: 1,
+		SE:          1,
+		History:     []float64{theta},
+	}
+}
+
+// NextAdaptiveQuestion 挑选下一道答对概率最接近目标的题目
+func (s *IRTService) NextAdaptiveQuestion(state *model.AdaptiveSessionState, categoryIDs []uint, excludeIDs []uint) (*model.Question, error) {
+	ids, err := s.pickCandidates(categoryIDs, state.Theta, excludeIDs, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, ErrNoQuestionsForSession
+	}
+	return s.questionRepo.GetByID(ids[0])
+}
+
+// AdvanceAdaptive 用本题作答更新练习内的能力估计并判断是否收敛：
+// 至少作答 min_adaptive 题后，标准误低于 converge_se，或最近三题的能力变化都小于 converge_delta
+func (s *IRTService) AdvanceAdaptive(state *model.AdaptiveSessionState, question *model.Question, isCorrect bool) {
+	a, b := s.itemParams(question)
+	theta, info := updateTheta(state.Theta, state.Information, a, b, isCorrect)
+	state.Theta = round3(theta)
+	state.Information = info
+	state.SE = round3(1 / math.Sqrt(info))
+	state.History = append(state.History, state.Theta)
+
+	answered := len(state.History) - 1
+	if answered < s.cfg.MinAdaptive {
+		return
+	}
+	if s.cfg.ConvergeSE > 0 && state.SE <= s.cfg.ConvergeSE {
+		state.Converged = true
+		return
+	}
+	if s.cfg.ConvergeDelta > 0 && len(state.History) >= 4 {
+		stable := true
+		for i := len(state.History) - 3; i < len(state.History); i++ {
+			if math.Abs(state.History[i]-state.History[i-1]) >= s.cfg.ConvergeDelta {
+				stable = false
+				break
+			}
+		}
+		state.Converged = stable
+	}
+}
+
+func round3(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
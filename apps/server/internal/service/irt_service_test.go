@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/what-cse/server/internal/repository"
+)
+
+func TestUpdateTheta(t *testing.T) {
+	// θ=0, 信息量 1，a=1, b=0：p=0.5，信息量 1.25，θ 移动 ±0.5/1.25
+	theta, info := updateTheta(0, 1, 1, 0, true)
+	if math.Abs(theta-0.4) > 1e-9 || math.Abs(info-1.25) > 1e-9 {
+		t.Errorf("correct: got (%.4f, %.4f), want (0.4, 1.25)", theta, info)
+	}
+	theta, info = updateTheta(0, 1, 1, 0, false)
+	if math.Abs(theta+0.4) > 1e-9 || math.Abs(info-1.25) > 1e-9 {
+		t.Errorf("wrong: got (%.4f, %.4f), want (-0.4, 1.25)", theta, info)
+	}
+
+	// 答对难题比答对易题提升更多
+	hard, _ := updateTheta(0, 1, 1, 2, true)
+	easy, _ := updateTheta(0, 1, 1, -2, true)
+	if hard <= easy {
+		t.Errorf("correct on hard item moved θ by %.3f, easy by %.3f", hard, easy)
+	}
+
+	// 信息量有上限，θ 不越界
+	theta, info = 0, 1
+	for i := 0; i < 1000; i++ {
+		theta, info = updateTheta(theta, info, 2, 0, true)
+	}
+	if info != irtMaxInformation {
+		t.Errorf("information = %.2f, want cap %.0f", info, irtMaxInformation)
+	}
+	if theta > irtThetaBound {
+		t.Errorf("theta = %.2f beyond bound", theta)
+	}
+}
+
+func TestJMLCalibrateRecoversParameters(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	const users, questions = 500, 20
+
+	trueA := make([]float64, questions)
+	trueB := make([]float64, questions)
+	for q := range trueB {
+		trueA[q] = 0.7 + 1.1*rng.Float64()
+		trueB[q] = -1.5 + 3*float64(q)/float64(questions-1)
+	}
+	trueTheta := make([]float64, users)
+	var responses []repository.IRTResponse
+	for u := range trueTheta {
+		trueTheta[u] = rng.NormFloat64()
+		for q := range trueB {
+			responses = append(responses, repository.IRTResponse{
+				UserID:     uint(u + 1),
+				QuestionID: uint(q + 1),
+				Difficulty: 3,
+				IsCorrect:  rng.Float64() < irtProbability(trueTheta[u], trueA[q], trueB[q]),
+			})
+		}
+	}
+	// 作答人数不足下限的题目保持先验参数
+	responses = append(responses, repository.IRTResponse{UserID: 1, QuestionID: 99, Difficulty: 5, IsCorrect: true})
+
+	items, learners := irtIndex(responses)
+	iterations, converged, err := jmlCalibrate(context.Background(), items, learners, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !converged {
+		t.Fatalf("did not converge in %d iterations", iterations)
+	}
+
+	var estA, estB []float64
+	var errB float64
+	for _, it := range items {
+		if it.questionID == 99 {
+			if it.a != 1 || it.b != irtPriorDifficulty(5) {
+				t.Errorf("sparse item moved from prior: a=%.3f b=%.3f", it.a, it.b)
+			}
+			continue
+		}
+		estA = append(estA, it.a)
+		estB = append(estB, it.b)
+		errB += math.Abs(it.b-trueB[it.questionID-1]) / questions
+	}
+	if errB > 0.25 {
+		t.Errorf("difficulty mean absolute error = %.3f", errB)
+	}
+	estTheta := make([]float64, users)
+	for _, l := range learners {
+		estTheta[l.userID-1] = l.theta
+	}
+
+	if r := correlation(estB, trueB); r < 0.98 {
+		t.Errorf("difficulty correlation = %.3f", r)
+	}
+	if r := correlation(estA, trueA); r < 0.7 {
+		t.Errorf("discrimination correlation = %.3f", r)
+	}
+	if r := correlation(estTheta, trueTheta); r < 0.85 {
+		t.Errorf("ability correlation = %.3f", r)
+	}
+}
+
+func TestJMLCalibrateStopsOnCancel(t *testing.T) {
+	items, learners := irtIndex([]repository.IRTResponse{{UserID: 1, QuestionID: 1, Difficulty: 3, IsCorrect: true}})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := jmlCalibrate(ctx, items, learners, 1); err == nil {
+		t.Error("expected context error")
+	}
+}
+
+func correlation(x, y []float64) float64 {
+	var mx, my float64
+	for i := range x {
+		mx += x[i]
+		my += y[i]
+	}
+	mx /= float64(len(x))
+	my /= float64(len(y))
+	var sxy, sxx, syy float64
+	for i := range x {
+		sxy += (x[i] - mx) * (y[i] - my)
+		sxx += (x[i] - mx) * (x[i] - mx)
+		syy += (y[i] - my) * (y[i] - my)
+	}
+	return sxy / math.Sqrt(sxx*syy)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/what-cse/server/internal/model"
 	"github.com/what-cse/server/internal/repository"
+	"github.com/what-cse/server/internal/srs"
 )
 
 // =====================================================
@@ -152,14 +153,20 @@ type UpdateKnowledgeDetailRequest struct {
 
 // FlashCardService 速记卡片服务
 type FlashCardService struct {
-	repo       *repository.FlashCardRepository
-	recordRepo *repository.UserFlashCardRecordRepository
+	repo            *repository.FlashCardRepository
+	recordRepo      *repository.UserFlashCardRecordRepository
+	reviewScheduler *ReviewSchedulerService
 }
 
 func NewFlashCardService(repo *repository.FlashCardRepository, recordRepo *repository.UserFlashCardRecordRepository) *FlashCardService {
 	return &FlashCardService{repo: repo, recordRepo: recordRepo}
 }
 
+// SetReviewScheduler 注入统一复习调度服务
+func (s *FlashCardService) SetReviewScheduler(reviewScheduler *ReviewSchedulerService) {
+	s.reviewScheduler = reviewScheduler
+}
+
 // CreateCard 创建卡片
 func (s *FlashCardService) CreateCard(req *CreateFlashCardRequest) (*model.KnowledgeFlashCard, error) {
 	card := &model.KnowledgeFlashCard{
@@ -249,9 +256,9 @@ func (s *FlashCardService) DeleteCard(id uint) error {
 	return s.repo.Delete(id)
 }
 
-// GetCard 获取卡片
-func (s *FlashCardService) GetCard(id uint) (*model.KnowledgeFlashCardResponse, error) {
-	card, err := s.repo.GetByID(id)
+// GetCard 获取卡片，用户导入的私有卡片只对其所有者可见
+func (s *FlashCardService) GetCard(id, userID uint) (*model.KnowledgeFlashCardResponse, error) {
+	card, err := s.repo.GetVisibleByID(id, userID)
 	if err != nil {
 		return nil, ErrFlashCardNotFound
 	}
@@ -312,64 +319,61 @@ func (s *FlashCardService) GetTypeStats() ([]model.FlashCardTypeStats, error) {
 	return s.repo.GetTypeStats()
 }
 
-// ReviewCard 复习卡片（间隔重复算法）
-func (s *FlashCardService) ReviewCard(userID, cardID uint, quality int) error {
-	// quality: 1-5, 1=完全忘记, 5=非常熟悉
+// ReviewCard 复习卡片，按用户的间隔重复算法安排下次复习
+func (s *FlashCardService) ReviewCard(userID, cardID uint, rating srs.Rating) (*model.ReviewResult, error) {
 	record, err := s.recordRepo.GetByUserAndCard(userID, cardID)
 	if err != nil {
-		// 新记录
+		// 新记录；其他用户导入的私有卡片不可见
+		card, err := s.repo.GetByID(cardID)
+		if err != nil || (card.OwnerID != nil && *card.OwnerID != userID) {
+			return nil, ErrFlashCardNotFound
+		}
 		record = &model.UserFlashCardRecord{
 			UserID:     userID,
 			CardID:     cardID,
-			Status:     model.UserFlashCardStatusLearning,
+			Status:     model.UserFlashCardStatusNew,
 			EaseFactor: 2.5,
 		}
 	}
 
-	record.ReviewCount++
 	now := time.Now()
-	record.LastReviewAt = &now
-
-	if quality >= 3 {
-		record.CorrectCount++
-	} else {
-		record.WrongCount++
+	next, result, err := s.reviewScheduler.Schedule(userID, model.ReviewItemFlashCard, cardID, flashCardRecordCard(record), rating, now)
+	if err != nil {
+		return nil, err
 	}
+	applyFlashCardRecord(record, next)
 
-	// SM-2 算法计算下次复习时间
-	if quality < 3 {
-		// 忘记了，重新学习
-		record.Interval = 1
-		record.Status = model.UserFlashCardStatusLearning
+	if rating == srs.Again {
+		record.WrongCount++
 	} else {
-		// 记住了
-		if record.Interval == 0 {
-			record.Interval = 1
-		} else if record.Interval == 1 {
-			record.Interval = 6
-		} else {
-			record.Interval = int(float64(record.Interval) * record.EaseFactor)
-		}
-
-		// 更新难度系数
-		record.EaseFactor = record.EaseFactor + (0.1 - float64(5-quality)*(0.08+float64(5-quality)*0.02))
-		if record.EaseFactor < 1.3 {
-			record.EaseFactor = 1.3
-		}
+		record.CorrectCount++
+	}
 
-		// 更新状态
-		if record.CorrectCount >= 5 && float64(record.CorrectCount)/float64(record.ReviewCount) >= 0.8 {
-			record.Status = model.UserFlashCardStatusMastered
-			s.repo.IncrMasterCount(cardID)
-		} else {
-			record.Status = model.UserFlashCardStatusReview
-		}
+	// 更新状态
+	wasMastered := record.Status == model.UserFlashCardStatusMastered
+	record.Status = flashCardStatus(record, next.State)
+	if record.Status == model.UserFlashCardStatusMastered && !wasMastered {
+		s.repo.IncrMasterCount(cardID)
 	}
 
-	nextReview := now.AddDate(0, 0, record.Interval)
-	record.NextReviewAt = &nextReview
+	if err := s.recordRepo.Upsert(record); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
 
-	return s.recordRepo.Upsert(record)
+// flashCardStatus 根据调度状态与答题情况确定卡片学习状态
+func flashCardStatus(record *model.UserFlashCardRecord, state srs.State) model.UserFlashCardStatus {
+	switch {
+	case state == srs.StateNew:
+		return model.UserFlashCardStatusNew
+	case state != srs.StateReview:
+		return model.UserFlashCardStatusLearning
+	case record.CorrectCount >= 5 && float64(record.CorrectCount)/float64(record.ReviewCount) >= 0.8:
+		return model.UserFlashCardStatusMastered
+	default:
+		return model.UserFlashCardStatusReview
+	}
 }
 
 // GetDueCards 获取需要复习的卡片
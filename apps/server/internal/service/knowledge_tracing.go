@@ -0,0 +1,553 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/what-cse/server/internal/model"
+	"github.com/what-cse/server/internal/repository"
+	"go.uber.org/zap"
+)
+
+var ErrKnowledgeTracingBusy = errors.New("知识追踪参数拟合正在进行中")
+
+const (
+	bktDefaultInit   = 0.2
+	bktDefaultLearn  = 0.1
+	bktDefaultGuess  = 0.2
+	bktDefaultSlip   = 0.1
+	bktMaxGuess      = 0.4 // 猜测率、失误率上限，避免 EM 退化为“已掌握却总答错”的解
+	bktMaxSlip       = 0.3
+	bktEMIterations  = 30
+	bktEMTolerance   = 1e-4
+	bktLoadBatchSize = 5000
+)
+
+// bktParams 一个知识点的 BKT 参数
+type bktParams struct {
+	init, learn, guess, slip float64
+}
+
+func defaultBKTParams() bktParams {
+	return bktParams{init: bktDefaultInit, learn: bktDefaultLearn, guess: bktDefaultGuess, slip: bktDefaultSlip}
+}
+
+func bktParamsFrom(p model.KnowledgeTracingParam) bktParams {
+	return bktParams{init: p.PInit, learn: p.PLearn, guess: p.PGuess, slip: p.PSlip}
+}
+
+// chanceLevel 题目的随机猜中概率：单选为 1/选项数，判断为 1/2，多选为 1/(2^n-1)，主观题视为不可猜
+func chanceLevel(questionType model.QuestionType, optionCount int) float64 {
+	switch questionType {
+	case model.QuestionTypeSingleChoice:
+		if optionCount > 1 {
+			return 1 / float64(optionCount)
+		}
+		return 0.25
+	case model.QuestionTypeJudge:
+		return 0.5
+	case model.QuestionTypeMultiChoice:
+		if optionCount > 1 && optionCount < 16 {
+			return 1 / float64(int(1)<<optionCount-1)
+		}
+		return 1.0 / 15
+	default:
+		return 0
+	}
+}
+
+// bktUpdate 用一次作答更新掌握概率：先按猜测/失误求后验，再叠加本次练习的学习转移。
+// 实际猜测率取知识点参数与题目随机猜中概率的较大者，四选一的题答对最多只能说明这么多
+func bktUpdate(p float64, params bktParams, chance float64, correct bool) float64 {
+	guess := math.Max(params.guess, chance)
+	var posterior float64
+	if correct {
+		known := p * (1 - params.slip)
+		posterior = known / (known + (1-p)*guess)
+	} else {
+		known := p * params.slip
+		posterior = known / (known + (1-p)*(1-guess))
+	}
+	if math.IsNaN(posterior) {
+		posterior = p
+	}
+	return posterior + (1-posterior)*params.learn
+}
+
+// forget 按遗忘半衰期把掌握概率向初始掌握概率回落
+func (s *KnowledgeMasteryService) forget(p, pInit float64, elapsed time.Duration) float64 {
+	if s.cfg.ForgetHalfLifeDays <= 0 || elapsed <= 0 || p <= pInit {
+		return p
+	}
+	days := elapsed.Hours() / 24
+	return pInit + (p-pInit)*math.Pow(0.5, days/s.cfg.ForgetHalfLifeDays)
+}
+
+// observe 把一次作答计入掌握度记录
+func (s *KnowledgeMasteryService) observe(m *model.UserKnowledgeMastery, params bktParams, chance float64, correct bool, at time.Time) {
+	p := params.init
+	if m.LastPracticeAt != nil && m.TotalQuestions > 0 {
+		p = s.forget(m.MasteryLevel/100, params.init, at.Sub(*m.LastPracticeAt))
+	}
+	p = bktUpdate(p, params, chance, correct)
+	m.MasteryLevel = math.Round(p*10000) / 100
+
+	m.TotalQuestions++
+	m.LastPracticeAt = &at
+	if correct {
+		m.CorrectQuestions++
+		m.StreakCount++
+		m.LastCorrectAt = &at
+	} else {
+		m.StreakCount = 0
+	}
+}
+
+// applyForgetting 把掌握度衰减到 now，用于展示和薄弱点判断
+func (s *KnowledgeMasteryService) applyForgetting(masteries []model.UserKnowledgeMastery, now time.Time) {
+	if len(masteries) == 0 || s.cfg.ForgetHalfLifeDays <= 0 {
+		return
+	}
+	ids := make([]uint, len(masteries))
+	for i, m := range masteries {
+		ids[i] = m.KnowledgePointID
+	}
+	params, _ := s.tracingRepo.GetParams(ids)
+
+	for i := range masteries {
+		m := &masteries[i]
+		if m.LastPracticeAt == nil {
+			continue
+		}
+		pInit := bktDefaultInit
+		if p, ok := params[m.KnowledgePointID]; ok {
+			pInit = p.PInit
+		}
+		level := s.forget(m.MasteryLevel/100, pInit, now.Sub(*m.LastPracticeAt))
+		m.MasteryLevel = math.Round(level*10000) / 100
+	}
+}
+
+// params 获取知识点当前使用的参数
+func (s *KnowledgeMasteryService) params(knowledgePointID uint) bktParams {
+	params, err := s.tracingRepo.GetParams([]uint{knowledgePointID})
+	if err == nil {
+		if p, ok := params[knowledgePointID]; ok {
+			return bktParamsFrom(p)
+		}
+	}
+	return defaultBKTParams()
+}
+
+// update 更新单个知识点的掌握度
+func (s *KnowledgeMasteryService) update(userID, knowledgePointID uint, chance float64, isCorrect bool) error {
+	params := s.params(knowledgePointID)
+
+	defer s.userLocks.lock(userID)()
+
+	mastery, err := s.masteryRepo.Get(userID, knowledgePointID)
+	if err != nil {
+		return err
+	}
+	if mastery == nil {
+		mastery = &model.UserKnowledgeMastery{UserID: userID, KnowledgePointID: knowledgePointID}
+	}
+	s.observe(mastery, params, chance, isCorrect, time.Now())
+	return s.masteryRepo.SaveBatch([]model.UserKnowledgeMastery{*mastery})
+}
+
+// saveLocked 持有批次内全部用户的锁写入掌握度，避免与在线更新交错
+func (s *KnowledgeMasteryService) saveLocked(batch []model.UserKnowledgeMastery) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	seen := make(map[uint]bool)
+	var userIDs []uint
+	for _, m := range batch {
+		if !seen[m.UserID] {
+			seen[m.UserID] = true
+			userIDs = append(userIDs, m.UserID)
+		}
+	}
+	// 按用户ID升序加锁，多把锁之间不会死锁
+	sort.Slice(userIDs, func(i, j int) bool { return userIDs[i] < userIDs[j] })
+	for _, id := range userIDs {
+		defer s.userLocks.lock(id)()
+	}
+	return s.masteryRepo.SaveBatch(batch)
+}
+
+// userLocks 按用户串行化掌握度的读改写，不同用户的作答互不阻塞
+type userLocks struct {
+	mu    sync.Mutex
+	locks map[uint]*userLock
+}
+
+type userLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock 锁定用户并返回解锁函数，无人等待的锁在解锁时释放
+func (l *userLocks) lock(userID uint) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[uint]*userLock)
+	}
+	ul, ok := l.locks[userID]
+	if !ok {
+		ul = &userLock{}
+		l.locks[userID] = ul
+	}
+	ul.refs++
+	l.mu.Unlock()
+
+	ul.Lock()
+	return func() {
+		ul.Unlock()
+		l.mu.Lock()
+		ul.refs--
+		if ul.refs == 0 {
+			delete(l.locks, userID)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// RecordAnswer 作答后更新题目关联的全部知识点掌握度
+func (s *KnowledgeMasteryService) RecordAnswer(userID uint, question *model.Question, isCorrect bool) {
+	if s == nil || userID == 0 || question == nil {
+		return
+	}
+	chance := chanceLevel(question.QuestionType, len(question.Options))
+	for _, kp := range question.KnowledgePoints {
+		if kp <= 0 {
+			continue
+		}
+		if err := s.update(userID, uint(kp), chance, isCorrect); err != nil {
+			s.logger.Warn("Failed to update knowledge mastery",
+				zap.Uint("user_id", userID), zap.Int("knowledge_point_id", kp), zap.Error(err))
+		}
+	}
+}
+
+// =====================================================
+// 参数拟合与回放
+// =====================================================
+
+// KnowledgeTracingFitResult 一次参数拟合结果
+type KnowledgeTracingFitResult struct {
+	Responses       int       `json:"responses"`        // 参与的作答记录数
+	KnowledgePoints int       `json:"knowledge_points"` // 出现过的知识点数
+	Fitted          int       `json:"fitted"`           // 作答数达到下限、参数被拟合的知识点数
+	Masteries       int       `json:"masteries"`        // 回放写入的掌握度记录数（仅回放时）
+	DurationMs      int64     `json:"duration_ms"`
+	CompletedAt     time.Time `json:"completed_at"`
+}
+
+// forEachObservation 按记录ID顺序分批遍历全部带知识点的作答记录
+func (s *KnowledgeMasteryService) forEachObservation(ctx context.Context, fn func([]repository.KnowledgeObservation)) error {
+	var afterID uint
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		batch, err := s.tracingRepo.LoadObservations(afterID, bktLoadBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(batch) > 0 {
+			fn(batch)
+			afterID = batch[len(batch)-1].ID
+		}
+		if len(batch) < bktLoadBatchSize {
+			return nil
+		}
+	}
+}
+
+// loadObservations 按时间顺序加载最新的 MaxResponses 条带知识点的作答记录，用于拟合参数
+func (s *KnowledgeMasteryService) loadObservations(ctx context.Context) ([]repository.KnowledgeObservation, error) {
+	var all []repository.KnowledgeObservation
+	err := s.forEachObservation(ctx, func(batch []repository.KnowledgeObservation) {
+		all = append(all, batch...)
+		// 超出上限一倍时丢弃最旧的记录，内存占用保持在上限的两倍以内
+		if len(all) > 2*s.cfg.MaxResponses {
+			all = append(all[:0:0], all[len(all)-s.cfg.MaxResponses:]...)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(all) > s.cfg.MaxResponses {
+		all = all[len(all)-s.cfg.MaxResponses:]
+	}
+	return all, nil
+}
+
+// Fit 用历史作答序列按知识点拟合 BKT 参数
+func (s *KnowledgeMasteryService) Fit(ctx context.Context) (*KnowledgeTracingFitResult, error) {
+	if !s.syncMu.TryLock() {
+		return nil, ErrKnowledgeTracingBusy
+	}
+	defer s.syncMu.Unlock()
+
+	started := time.Now()
+	observations, err := s.loadObservations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.fit(observations)
+	if err != nil {
+		return nil, err
+	}
+	result.DurationMs = time.Since(started).Milliseconds()
+	result.CompletedAt = time.Now()
+	return result, nil
+}
+
+// Backfill 先拟合参数，再按时间顺序回放全部历史作答重新计算每个用户的掌握度
+func (s *KnowledgeMasteryService) Backfill(ctx context.Context) (*KnowledgeTracingFitResult, error) {
+	if !s.syncMu.TryLock() {
+		return nil, ErrKnowledgeTracingBusy
+	}
+	defer s.syncMu.Unlock()
+
+	started := time.Now()
+	observations, err := s.loadObservations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.fit(observations)
+	if err != nil {
+		return nil, err
+	}
+
+	fitted, err := s.tracingRepo.GetAllParams()
+	if err != nil {
+		return nil, err
+	}
+	// 拟合只用最新的部分作答，回放必须覆盖全部历史，否则掌握度会退回旧值
+	type masteryKey struct{ userID, knowledgePointID uint }
+	states := make(map[masteryKey]*model.UserKnowledgeMastery)
+	var order []masteryKey
+	err = s.forEachObservation(ctx, func(batch []repository.KnowledgeObservation) {
+		for _, o := range batch {
+			chance := chanceLevel(o.QuestionType, o.OptionCount)
+			for _, kp := range o.KnowledgePoints {
+				if kp <= 0 {
+					continue
+				}
+				key := masteryKey{o.UserID, uint(kp)}
+				m, ok := states[key]
+				if !ok {
+					m = &model.UserKnowledgeMastery{UserID: o.UserID, KnowledgePointID: uint(kp)}
+					states[key] = m
+					order = append(order, key)
+				}
+				params := defaultBKTParams()
+				if p, ok := fitted[uint(kp)]; ok {
+					params = bktParamsFrom(p)
+				}
+				s.observe(m, params, chance, o.IsCorrect, o.CreatedAt)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	batch := make([]model.UserKnowledgeMastery, 0, 500)
+	for _, key := range order {
+		batch = append(batch, *states[key])
+		if len(batch) == cap(batch) {
+			if err := s.saveLocked(batch); err != nil {
+				return nil, err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := s.saveLocked(batch); err != nil {
+		return nil, err
+	}
+
+	result.Masteries = len(order)
+	result.DurationMs = time.Since(started).Milliseconds()
+	result.CompletedAt = time.Now()
+	return result, nil
+}
+
+// fit 按知识点分组拟合并保存参数
+func (s *KnowledgeMasteryService) fit(observations []repository.KnowledgeObservation) (*KnowledgeTracingFitResult, error) {
+	type kpData struct {
+		sequences map[uint][]bool
+		chance    float64
+		responses int
+	}
+	byKP := make(map[uint]*kpData)
+	for _, o := range observations {
+		chance := chanceLevel(o.QuestionType, o.OptionCount)
+		for _, kp := range o.KnowledgePoints {
+			if kp <= 0 {
+				continue
+			}
+			d, ok := byKP[uint(kp)]
+			if !ok {
+				d = &kpData{sequences: make(map[uint][]bool)}
+				byKP[uint(kp)] = d
+			}
+			d.sequences[o.UserID] = append(d.sequences[o.UserID], o.IsCorrect)
+			d.chance += chance
+			d.responses++
+		}
+	}
+
+	result := &KnowledgeTracingFitResult{Responses: len(observations), KnowledgePoints: len(byKP)}
+	now := time.Now()
+	var params []model.KnowledgeTracingParam
+	for kp, d := range byKP {
+		if d.responses < s.cfg.MinResponses {
+			continue
+		}
+		init := defaultBKTParams()
+		init.guess = clampFloat(d.chance/float64(d.responses), 0.05, bktMaxGuess)
+
+		sequences := make([][]bool, 0, len(d.sequences))
+		for _, seq := range d.sequences {
+			sequences = append(sequences, seq)
+		}
+		fitted, ll := fitBKT(sequences, init)
+		params = append(params, model.KnowledgeTracingParam{
+			KnowledgePointID: kp,
+			PInit:            round3(fitted.init),
+			PLearn:           round3(fitted.learn),
+			PGuess:           round3(fitted.guess),
+			PSlip:            round3(fitted.slip),
+			ResponseCount:    d.responses,
+			SequenceCount:    len(sequences),
+			LogLikelihood:    math.Round(ll*100) / 100,
+			FittedAt:         now,
+		})
+	}
+	if err := s.tracingRepo.SaveParams(params); err != nil {
+		return nil, err
+	}
+	result.Fitted = len(params)
+
+	s.logger.Info("Knowledge tracing parameters fitted",
+		zap.Int("responses", result.Responses),
+		zap.Int("knowledge_points", result.KnowledgePoints),
+		zap.Int("fitted", result.Fitted))
+	return result, nil
+}
+
+// fitBKT 用 Baum-Welch (EM) 拟合两状态隐马尔可夫模型，返回参数和对数似然。
+// 状态 0 未掌握、1 已掌握；只允许 0→1 转移，遗忘在在线更新时按时间间隔单独处理
+func fitBKT(sequences [][]bool, params bktParams) (bktParams, float64) {
+	emit := func(p bktParams, known bool, correct bool) float64 {
+		switch {
+		case known && correct:
+			return 1 - p.slip
+		case known:
+			return p.slip
+		case correct:
+			return p.guess
+		default:
+			return 1 - p.guess
+		}
+	}
+
+	prevLL := math.Inf(-1)
+	var ll float64
+	for iter := 0; iter < bktEMIterations; iter++ {
+		ll = 0
+		var initSum, learnNum, learnDen, guessNum, guessDen, slipNum, slipDen float64
+
+		for _, seq := range sequences {
+			n := len(seq)
+			if n == 0 {
+				continue
+			}
+			alpha := make([][2]float64, n)
+			beta := make([][2]float64, n)
+			scale := make([]float64, n)
+
+			alpha[0][0] = (1 - params.init) * emit(params, false, seq[0])
+			alpha[0][1] = params.init * emit(params, true, seq[0])
+			for t := 0; t < n; t++ {
+				if t > 0 {
+					alpha[t][0] = alpha[t-1][0] * (1 - params.learn) * emit(params, false, seq[t])
+					alpha[t][1] = (alpha[t-1][0]*params.learn + alpha[t-1][1]) * emit(params, true, seq[t])
+				}
+				scale[t] = alpha[t][0] + alpha[t][1]
+				if scale[t] <= 0 {
+					scale[t] = 1e-300
+				}
+				alpha[t][0] /= scale[t]
+				alpha[t][1] /= scale[t]
+				ll += math.Log(scale[t])
+			}
+
+			beta[n-1] = [2]float64{1, 1}
+			for t := n - 2; t >= 0; t-- {
+				e0 := emit(params, false, seq[t+1])
+				e1 := emit(params, true, seq[t+1])
+				beta[t][0] = ((1-params.learn)*e0*beta[t+1][0] + params.learn*e1*beta[t+1][1]) / scale[t+1]
+				beta[t][1] = e1 * beta[t+1][1] / scale[t+1]
+			}
+
+			for t := 0; t < n; t++ {
+				g0 := alpha[t][0] * beta[t][0]
+				g1 := alpha[t][1] * beta[t][1]
+				if sum := g0 + g1; sum > 0 {
+					g0, g1 = g0/sum, g1/sum
+				}
+				if t == 0 {
+					initSum += g1
+				}
+				if t < n-1 {
+					learnNum += alpha[t][0] * params.learn * emit(params, true, seq[t+1]) * beta[t+1][1] / scale[t+1]
+					learnDen += g0
+				}
+				guessDen += g0
+				slipDen += g1
+				if seq[t] {
+					guessNum += g0
+				} else {
+					slipNum += g1
+				}
+			}
+		}
+
+		if len(sequences) > 0 {
+			params.init = clampFloat(initSum/float64(len(sequences)), 0.01, 0.95)
+		}
+		if learnDen > 0 {
+			params.learn = clampFloat(learnNum/learnDen, 0.01, 0.5)
+		}
+		if guessDen > 0 {
+			params.guess = clampFloat(guessNum/guessDen, 0.01, bktMaxGuess)
+		}
+		if slipDen > 0 {
+			params.slip = clampFloat(slipNum/slipDen, 0.01, bktMaxSlip)
+		}
+
+		if math.Abs(ll-prevLL) < bktEMTolerance*math.Max(1, math.Abs(ll)) {
+			break
+		}
+		prevLL = ll
+	}
+	return params, ll
+}
+
+// RunScheduled 定时参数拟合入口，上一轮仍在执行时跳过
+func (s *KnowledgeMasteryService) RunScheduled(ctx context.Context) error {
+	if _, err := s.Fit(ctx); err != nil && !errors.Is(err, ErrKnowledgeTracingBusy) {
+		return err
+	}
+	return nil
+}
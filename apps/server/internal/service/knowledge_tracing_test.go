@@ -0,0 +1,140 @@
+package service
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/what-cse/server/internal/config"
+	"github.com/what-cse/server/internal/model"
+)
+
+func TestBKTUpdate(t *testing.T) {
+	params := defaultBKTParams()
+
+	// 答对：0.18 / (0.18 + 0.8×0.2) = 0.5294，再叠加学习转移 0.1
+	if got, want := bktUpdate(0.2, params, 0, true), 0.5294118+0.4705882*0.1; math.Abs(got-want) > 1e-6 {
+		t.Errorf("correct: got %.6f, want %.6f", got, want)
+	}
+	// 答错：0.02 / (0.02 + 0.8×0.8) = 0.0303，再叠加学习转移 0.1
+	if got, want := bktUpdate(0.2, params, 0, false), 0.0303030+0.9696970*0.1; math.Abs(got-want) > 1e-6 {
+		t.Errorf("wrong: got %.6f, want %.6f", got, want)
+	}
+
+	// 判断题答对能说明的比主观题少
+	if judge, essay := bktUpdate(0.2, params, 0.5, true), bktUpdate(0.2, params, 0, true); judge >= essay {
+		t.Errorf("judge %.4f should be below essay %.4f", judge, essay)
+	}
+
+	// 连续答对收敛到接近 1，连续答错回落但不低于学习转移
+	p := 0.2
+	for i := 0; i < 20; i++ {
+		p = bktUpdate(p, params, 0.25, true)
+	}
+	if p < 0.99 {
+		t.Errorf("after 20 correct answers p = %.4f", p)
+	}
+	for i := 0; i < 20; i++ {
+		p = bktUpdate(p, params, 0.25, false)
+	}
+	if p > 0.2 || p < params.learn {
+		t.Errorf("after 20 wrong answers p = %.4f", p)
+	}
+}
+
+func TestChanceLevel(t *testing.T) {
+	cases := []struct {
+		questionType model.QuestionType
+		options      int
+		want         float64
+	}{
+		{model.QuestionTypeSingleChoice, 4, 0.25},
+		{model.QuestionTypeSingleChoice, 5, 0.2},
+		{model.QuestionTypeSingleChoice, 0, 0.25},
+		{model.QuestionTypeJudge, 2, 0.5},
+		{model.QuestionTypeMultiChoice, 4, 1.0 / 15},
+		{model.QuestionTypeMultiChoice, 5, 1.0 / 31},
+	}
+	for _, c := range cases {
+		if got := chanceLevel(c.questionType, c.options); math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("chanceLevel(%s, %d) = %.4f, want %.4f", c.questionType, c.options, got, c.want)
+		}
+	}
+}
+
+func TestForgetting(t *testing.T) {
+	s := &KnowledgeMasteryService{cfg: config.KnowledgeConfig{ForgetHalfLifeDays: 30}}
+	day := 24 * time.Hour
+
+	// 一个半衰期后，高出初始值的部分减半
+	if got := s.forget(0.9, 0.2, 30*day); math.Abs(got-0.55) > 1e-9 {
+		t.Errorf("one half-life: got %.4f, want 0.55", got)
+	}
+	if got := s.forget(0.9, 0.2, 60*day); math.Abs(got-0.375) > 1e-9 {
+		t.Errorf("two half-lives: got %.4f, want 0.375", got)
+	}
+	if got := s.forget(0.1, 0.2, 60*day); got != 0.1 {
+		t.Errorf("below initial mastery: got %.4f, want unchanged", got)
+	}
+	if got := (&KnowledgeMasteryService{}).forget(0.9, 0.2, 60*day); got != 0.9 {
+		t.Errorf("forgetting disabled: got %.4f, want unchanged", got)
+	}
+
+	// 间隔较久的作答先衰减再更新
+	params := defaultBKTParams()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fresh := model.UserKnowledgeMastery{}
+	s.observe(&fresh, params, 0, true, start)
+	if want := math.Round(bktUpdate(params.init, params, 0, true)*10000) / 100; fresh.MasteryLevel != want {
+		t.Errorf("first answer: mastery %.2f, want %.2f", fresh.MasteryLevel, want)
+	}
+	soon, later := fresh, fresh
+	s.observe(&soon, params, 0, true, start.Add(day))
+	s.observe(&later, params, 0, true, start.Add(90*day))
+	if later.MasteryLevel >= soon.MasteryLevel {
+		t.Errorf("answer after 90 days %.2f should count less than after 1 day %.2f", later.MasteryLevel, soon.MasteryLevel)
+	}
+	if soon.TotalQuestions != 2 || soon.CorrectQuestions != 2 || soon.StreakCount != 2 {
+		t.Errorf("counters = %d/%d streak %d", soon.CorrectQuestions, soon.TotalQuestions, soon.StreakCount)
+	}
+
+}
+
+func TestFitBKTRecoversParameters(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	truth := bktParams{init: 0.3, learn: 0.15, guess: 0.2, slip: 0.08}
+
+	sequences := make([][]bool, 2000)
+	for i := range sequences {
+		known := rng.Float64() < truth.init
+		seq := make([]bool, 10)
+		for k := range seq {
+			if known {
+				seq[k] = rng.Float64() >= truth.slip
+			} else {
+				seq[k] = rng.Float64() < truth.guess
+				known = rng.Float64() < truth.learn
+			}
+		}
+		sequences[i] = seq
+	}
+
+	fitted, ll := fitBKT(sequences, defaultBKTParams())
+	if math.IsNaN(ll) || ll >= 0 {
+		t.Fatalf("log-likelihood = %f", ll)
+	}
+	for _, c := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"init", fitted.init, truth.init},
+		{"learn", fitted.learn, truth.learn},
+		{"guess", fitted.guess, truth.guess},
+		{"slip", fitted.slip, truth.slip},
+	} {
+		if math.Abs(c.got-c.want) > 0.03 {
+			t.Errorf("%s = %.3f, want %.3f", c.name, c.got, c.want)
+		}
+	}
+}
@@ -0,0 +1,761 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"html"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	"github.com/what-cse/server/internal/model"
+	"github.com/what-cse/server/internal/repository"
+)
+
+var (
+	ErrLearningSearchRefreshing = errors.New("学习内容索引正在刷新")
+)
+
+const (
+	learningSearchScanBatch   = 500
+	learningSearchTitleRunes  = 60
+	learningSearchSnippetSize = 120
+)
+
+// LearningSearchService 学习内容统一搜索
+// 把题目、素材、课程、章节、知识点、知识点详情和公开笔记投影到 what_learning_search_docs，
+// 按各源表 updated_at / deleted_at 水位增量刷新，检索使用 ngram 全文索引
+type LearningSearchService struct {
+	repo              *repository.LearningSearchRepository
+	membershipService *MembershipService
+	logger            *zap.Logger
+
+	refreshMu sync.Mutex
+}
+
+// LearningSearchQuery 搜索请求
+type LearningSearchQuery struct {
+	Keyword    string   `json:"keyword"`
+	Types      []string `json:"types,omitempty"`
+	Subject    string   `json:"subject,omitempty"`
+	ExamType   string   `json:"exam_type,omitempty"`
+	ExcludeVIP bool     `json:"exclude_vip,omitempty"`
+	Page       int      `json:"page"`
+	PageSize   int      `json:"page_size"`
+}
+
+// LearningSearchItem 搜索结果条目
+type LearningSearchItem struct {
+	Type     string   `json:"type"`
+	ID       uint     `json:"id"`
+	ParentID uint     `json:"parent_id,omitempty"`
+	Title    string   `json:"title"`
+	Snippet  string   `json:"snippet,omitempty"` // 带 <em> 高亮的正文片段，VIP 内容对非会员不返回
+	Subject  string   `json:"subject,omitempty"`
+	ExamType string   `json:"exam_type,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	IsVIP    bool     `json:"is_vip"`
+	Locked   bool     `json:"locked"` // VIP 内容且当前用户不是会员
+	Score    float64  `json:"score"`
+}
+
+// LearningSearchFacet 类型分面
+type LearningSearchFacet struct {
+	Type  string `json:"type"`
+	Count int64  `json:"count"`
+}
+
+// LearningSearchResponse 搜索响应
+type LearningSearchResponse struct {
+	Items    []LearningSearchItem  `json:"items"`
+	Total    int64                 `json:"total"`
+	Facets   []LearningSearchFacet `json:"facets"`
+	Page     int                   `json:"page"`
+	PageSize int                   `json:"page_size"`
+}
+
+// LearningSearchRefreshResult 刷新结果
+type LearningSearchRefreshResult struct {
+	DocType  string `json:"doc_type"`
+	Upserted int    `json:"upserted"`
+	Removed  int    `json:"removed"`
+}
+
+// LearningSearchStatus 索引状态
+type LearningSearchStatus struct {
+	Docs       map[string]int64                `json:"docs"`
+	States     []model.LearningSearchSyncState `json:"states"`
+	Refreshing bool                            `json:"refreshing"`
+}
+
+// NewLearningSearchService 创建学习内容搜索服务
+func NewLearningSearchService(repo *repository.LearningSearchRepository, membershipService *MembershipService, logger *zap.Logger) *LearningSearchService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &LearningSearchService{
+		repo:              repo,
+		membershipService: membershipService,
+		logger:            logger,
+	}
+}
+
+// =====================================================
+// 检索
+// =====================================================
+
+// Search 搜索学习内容，userID 为 0 表示未登录
+func (s *LearningSearchService) Search(userID uint, query LearningSearchQuery) (*LearningSearchResponse, error) {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 || query.PageSize > 100 {
+		query.PageSize = 20
+	}
+
+	params := &repository.LearningSearchParams{
+		Keyword:    strings.TrimSpace(query.Keyword),
+		Types:      query.Types,
+		Subject:    query.Subject,
+		ExamType:   query.ExamType,
+		ExcludeVIP: query.ExcludeVIP,
+		Page:       query.Page,
+		PageSize:   query.PageSize,
+	}
+
+	rows, total, err := s.repo.Search(params)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.repo.CountByType(params)
+	if err != nil {
+		return nil, err
+	}
+
+	isVIP := false
+	if userID > 0 && s.membershipService != nil {
+		isVIP, _ = s.membershipService.CheckVIP(userID)
+	}
+
+	terms := strings.Fields(params.Keyword)
+	items := make([]LearningSearchItem, 0, len(rows))
+	for _, row := range rows {
+		item := LearningSearchItem{
+			Type:     row.DocType,
+			ID:       row.SourceID,
+			ParentID: row.ParentID,
+			Title:    html.EscapeString(row.Title),
+			Subject:  row.Subject,
+			ExamType: row.ExamType,
+			Tags:     row.Tags,
+			IsVIP:    row.IsVIP,
+			Locked:   row.IsVIP && !isVIP,
+			Score:    round2(row.Relevance),
+		}
+		if highlighted, ok := highlightTerms(row.Title, terms); ok {
+			item.Title = highlighted
+		}
+		if !item.Locked {
+			item.Snippet = buildSnippet(row.Content, terms, learningSearchSnippetSize)
+		}
+		items = append(items, item)
+	}
+
+	facets := make([]LearningSearchFacet, 0, len(model.LearningDocTypes))
+	for _, docType := range model.LearningDocTypes {
+		facets = append(facets, LearningSearchFacet{Type: docType, Count: counts[docType]})
+	}
+
+	return &LearningSearchResponse{
+		Items:    items,
+		Total:    total,
+		Facets:   facets,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	}, nil
+}
+
+// buildSnippet 截取首个命中词附近的正文并高亮，无命中时返回开头部分
+func buildSnippet(content string, terms []string, size int) string {
+	if content == "" {
+		return ""
+	}
+	runes := []rune(content)
+
+	start := 0
+	lower := strings.ToLower(content)
+	for _, term := range terms {
+		if idx := strings.Index(lower, strings.ToLower(term)); idx >= 0 {
+			start = utf8.RuneCountInString(lower[:idx]) - size/4
+			break
+		}
+	}
+	if start < 0 {
+		start = 0
+	}
+	end := start + size
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	fragment := string(runes[start:end])
+	snippet, ok := highlightTerms(fragment, terms)
+	if !ok {
+		snippet = html.EscapeString(fragment)
+	}
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+// =====================================================
+// 增量刷新
+// =====================================================
+
+// RunScheduled 定时增量刷新入口，上一轮仍在执行时跳过
+func (s *LearningSearchService) RunScheduled(ctx context.Context) error {
+	if _, err := s.Refresh(ctx); err != nil && !errors.Is(err, ErrLearningSearchRefreshing) {
+		return err
+	}
+	return nil
+}
+
+// Rebuild 清空后全量重建
+func (s *LearningSearchService) Rebuild(ctx context.Context) ([]LearningSearchRefreshResult, error) {
+	if !s.refreshMu.TryLock() {
+		return nil, ErrLearningSearchRefreshing
+	}
+	if err := s.repo.DeleteAllDocs(); err != nil {
+		s.refreshMu.Unlock()
+		return nil, err
+	}
+	s.refreshMu.Unlock()
+
+	return s.Refresh(ctx)
+}
+
+// Refresh 按水位增量刷新全部类型
+func (s *LearningSearchService) Refresh(ctx context.Context) ([]LearningSearchRefreshResult, error) {
+	if !s.refreshMu.TryLock() {
+		return nil, ErrLearningSearchRefreshing
+	}
+	defer s.refreshMu.Unlock()
+
+	categories, err := s.loadCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	refreshers := []struct {
+		docType string
+		run     func(since, until time.Time) (int, int, error)
+	}{
+		{model.LearningDocQuestion, func(since, until time.Time) (int, int, error) {
+			return s.refreshQuestions(categories, since, until)
+		}},
+		{model.LearningDocMaterial, s.refreshMaterials},
+		{model.LearningDocCourse, func(since, until time.Time) (int, int, error) {
+			return s.refreshCourses(categories, since, until)
+		}},
+		{model.LearningDocChapter, func(since, until time.Time) (int, int, error) {
+			return s.refreshChapters(categories, since, until)
+		}},
+		{model.LearningDocKnowledgePoint, func(since, until time.Time) (int, int, error) {
+			return s.refreshKnowledgePoints(categories, since, until)
+		}},
+		{model.LearningDocKnowledgeDetail, func(since, until time.Time) (int, int, error) {
+			return s.refreshKnowledgeDetails(categories, since, until)
+		}},
+		{model.LearningDocNote, s.refreshNotes},
+	}
+
+	// 上界留出一秒，避免同一秒内尚未提交的写入被水位跳过
+	until := time.Now().Add(-time.Second)
+	results := make([]LearningSearchRefreshResult, 0, len(refreshers))
+	for _, r := range refreshers {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		state, err := s.repo.GetState(r.docType)
+		if err != nil {
+			return results, err
+		}
+		if !state.Watermark.Before(until) {
+			continue
+		}
+
+		upserted, removed, err := r.run(state.Watermark, until)
+		if err != nil {
+			return results, err
+		}
+
+		state.Watermark = until
+		state.LastRunAt = time.Now()
+		state.Upserted = upserted
+		state.Removed = removed
+		if err := s.repo.SaveState(state); err != nil {
+			return results, err
+		}
+		results = append(results, LearningSearchRefreshResult{DocType: r.docType, Upserted: upserted, Removed: removed})
+	}
+
+	return results, nil
+}
+
+// GetStatus 获取索引状态
+func (s *LearningSearchService) GetStatus() (*LearningSearchStatus, error) {
+	docs, err := s.repo.CountDocs()
+	if err != nil {
+		return nil, err
+	}
+	states, err := s.repo.ListStates()
+	if err != nil {
+		return nil, err
+	}
+
+	refreshing := !s.refreshMu.TryLock()
+	if !refreshing {
+		s.refreshMu.Unlock()
+	}
+
+	return &LearningSearchStatus{Docs: docs, States: states, Refreshing: refreshing}, nil
+}
+
+// scanAll 分批扫描变更的源数据并交给 handle 处理
+func scanAll[T any](s *LearningSearchService, since, until time.Time, id func(*T) uint, handle func([]T) error) error {
+	var afterID uint
+	for {
+		rows, err := repository.ScanChanged[T](s.repo, since, until, afterID, learningSearchScanBatch)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		if err := handle(rows); err != nil {
+			return err
+		}
+		afterID = id(&rows[len(rows)-1])
+	}
+}
+
+// applyDocs 写入可见文档、删除不可见文档
+func (s *LearningSearchService) applyDocs(docType string, docs []model.LearningSearchDoc, removed []uint) (int, int, error) {
+	if err := s.repo.UpsertDocs(docs); err != nil {
+		return 0, 0, err
+	}
+	if err := s.repo.DeleteDocs(docType, removed); err != nil {
+		return 0, 0, err
+	}
+	return len(docs), len(removed), nil
+}
+
+// ---------- 题目 ----------
+
+func (s *LearningSearchService) refreshQuestions(categories map[uint]categoryInfo, since, until time.Time) (int, int, error) {
+	upserted, removed := 0, 0
+	err := scanAll(s, since, until, func(q *model.Question) uint { return q.ID }, func(rows []model.Question) error {
+		var docs []model.LearningSearchDoc
+		var gone []uint
+		for _, q := range rows {
+			if q.DeletedAt.Valid || q.Status != model.QuestionStatusPublished {
+				gone = append(gone, q.ID)
+				continue
+			}
+			parts := []string{plainText(q.Content)}
+			for _, opt := range q.Options {
+				parts = append(parts, opt.Key+". "+plainText(opt.Content))
+			}
+			parts = append(parts, plainText(q.Analysis))
+
+			cat := categories[q.CategoryID]
+			docs = append(docs, model.LearningSearchDoc{
+				DocType:         model.LearningDocQuestion,
+				SourceID:        q.ID,
+				Title:           truncateRunes(plainText(q.Content), learningSearchTitleRunes),
+				Content:         strings.Join(parts, "\n"),
+				Subject:         cat.Subject,
+				ExamType:        cat.ExamType,
+				CategoryID:      q.CategoryID,
+				Tags:            q.Tags,
+				IsVIP:           q.IsVIP,
+				Popularity:      q.AttemptCount,
+				SourceUpdatedAt: q.UpdatedAt,
+			})
+		}
+		u, r, err := s.applyDocs(model.LearningDocQuestion, docs, gone)
+		upserted, removed = upserted+u, removed+r
+		return err
+	})
+	return upserted, removed, err
+}
+
+// ---------- 素材 ----------
+
+func (s *LearningSearchService) refreshMaterials(since, until time.Time) (int, int, error) {
+	upserted, removed := 0, 0
+	err := scanAll(s, since, until, func(m *model.LearningMaterial) uint { return m.ID }, func(rows []model.LearningMaterial) error {
+		var docs []model.LearningSearchDoc
+		var gone []uint
+		for _, m := range rows {
+			if m.DeletedAt.Valid || m.Status != model.MaterialStatusPublished {
+				gone = append(gone, m.ID)
+				continue
+			}
+			content := joinNonEmpty(plainText(m.Content), plainText(m.Analysis), plainText(m.Usage),
+				plainText(m.Example), plainText(m.Translation), plainText(m.Background), plainText(m.Significance))
+			docs = append(docs, model.LearningSearchDoc{
+				DocType:         model.LearningDocMaterial,
+				SourceID:        m.ID,
+				Title:           m.Title,
+				Content:         content,
+				Subject:         m.Subject,
+				CategoryID:      m.CategoryID,
+				Tags:            append(append(model.JSONStringArray{}, m.Tags...), m.Keywords...),
+				IsVIP:           m.VIPOnly,
+				Popularity:      m.ViewCount,
+				SourceUpdatedAt: m.UpdatedAt,
+			})
+		}
+		u, r, err := s.applyDocs(model.LearningDocMaterial, docs, gone)
+		upserted, removed = upserted+u, removed+r
+		return err
+	})
+	return upserted, removed, err
+}
+
+// ---------- 课程 ----------
+
+func (s *LearningSearchService) refreshCourses(categories map[uint]categoryInfo, since, until time.Time) (int, int, error) {
+	upserted, removed := 0, 0
+	err := scanAll(s, since, until, func(c *model.Course) uint { return c.ID }, func(rows []model.Course) error {
+		var docs []model.LearningSearchDoc
+		var gone []uint
+		for _, c := range rows {
+			if c.DeletedAt.Valid || c.Status != model.CourseStatusPublished {
+				gone = append(gone, c.ID)
+				continue
+			}
+			cat := categories[c.CategoryID]
+			docs = append(docs, model.LearningSearchDoc{
+				DocType:         model.LearningDocCourse,
+				SourceID:        c.ID,
+				Title:           c.Title,
+				Content:         joinNonEmpty(c.Subtitle, plainText(c.Description), c.TeacherName),
+				Subject:         cat.Subject,
+				ExamType:        cat.ExamType,
+				CategoryID:      c.CategoryID,
+				Tags:            c.Tags,
+				IsVIP:           c.VIPOnly,
+				Popularity:      c.StudyCount,
+				SourceUpdatedAt: c.UpdatedAt,
+			})
+		}
+		u, r, err := s.applyDocs(model.LearningDocCourse, docs, gone)
+		upserted, removed = upserted+u, removed+r
+		if err != nil {
+			return err
+		}
+
+		// 课程发布状态和 VIP 设置决定章节可见性，课程变更时重算其全部章节
+		courseIDs := make([]uint, 0, len(rows))
+		for _, c := range rows {
+			courseIDs = append(courseIDs, c.ID)
+		}
+		chapters, err := s.repo.GetChaptersByCourseIDs(courseIDs)
+		if err != nil {
+			return err
+		}
+		_, _, err = s.projectChapters(categories, chapters)
+		return err
+	})
+	return upserted, removed, err
+}
+
+// ---------- 章节 ----------
+
+func (s *LearningSearchService) refreshChapters(categories map[uint]categoryInfo, since, until time.Time) (int, int, error) {
+	upserted, removed := 0, 0
+	err := scanAll(s, since, until, func(c *model.CourseChapter) uint { return c.ID }, func(rows []model.CourseChapter) error {
+		u, r, err := s.projectChapters(categories, rows)
+		upserted, removed = upserted+u, removed+r
+		return err
+	})
+	return upserted, removed, err
+}
+
+func (s *LearningSearchService) projectChapters(categories map[uint]categoryInfo, chapters []model.CourseChapter) (int, int, error) {
+	if len(chapters) == 0 {
+		return 0, 0, nil
+	}
+
+	courseIDs := make([]uint, 0, len(chapters))
+	seen := make(map[uint]bool)
+	for _, ch := range chapters {
+		if !seen[ch.CourseID] {
+			seen[ch.CourseID] = true
+			courseIDs = append(courseIDs, ch.CourseID)
+		}
+	}
+	courses, err := s.repo.GetCoursesByIDs(courseIDs)
+	if err != nil {
+		return 0, 0, err
+	}
+	courseByID := make(map[uint]model.Course, len(courses))
+	for _, c := range courses {
+		courseByID[c.ID] = c
+	}
+
+	var docs []model.LearningSearchDoc
+	var gone []uint
+	for _, ch := range chapters {
+		course, ok := courseByID[ch.CourseID]
+		if ch.DeletedAt.Valid || !ok || course.DeletedAt.Valid || course.Status != model.CourseStatusPublished {
+			gone = append(gone, ch.ID)
+			continue
+		}
+		cat := categories[course.CategoryID]
+		docs = append(docs, model.LearningSearchDoc{
+			DocType:         model.LearningDocChapter,
+			SourceID:        ch.ID,
+			ParentID:        ch.CourseID,
+			Title:           ch.Title,
+			Content:         joinNonEmpty(plainText(ch.ContentText), plainText(ch.ExamAnalysis)),
+			Subject:         cat.Subject,
+			ExamType:        cat.ExamType,
+			CategoryID:      course.CategoryID,
+			IsVIP:           course.VIPOnly && !ch.IsFreePreview,
+			SourceUpdatedAt: ch.UpdatedAt,
+		})
+	}
+	return s.applyDocs(model.LearningDocChapter, docs, gone)
+}
+
+// ---------- 知识点 ----------
+
+func (s *LearningSearchService) refreshKnowledgePoints(categories map[uint]categoryInfo, since, until time.Time) (int, int, error) {
+	upserted, removed := 0, 0
+	err := scanAll(s, since, until, func(p *model.KnowledgePoint) uint { return p.ID }, func(rows []model.KnowledgePoint) error {
+		var docs []model.LearningSearchDoc
+		var gone []uint
+		for _, p := range rows {
+			cat := categories[p.CategoryID]
+			if p.DeletedAt.Valid || !cat.Active {
+				gone = append(gone, p.ID)
+				continue
+			}
+			docs = append(docs, model.LearningSearchDoc{
+				DocType:         model.LearningDocKnowledgePoint,
+				SourceID:        p.ID,
+				Title:           p.Name,
+				Content:         joinNonEmpty(plainText(p.Description), plainText(p.Tips)),
+				Subject:         cat.Subject,
+				ExamType:        cat.ExamType,
+				CategoryID:      p.CategoryID,
+				Popularity:      p.QuestionCount,
+				SourceUpdatedAt: p.UpdatedAt,
+			})
+		}
+		u, r, err := s.applyDocs(model.LearningDocKnowledgePoint, docs, gone)
+		upserted, removed = upserted+u, removed+r
+		if err != nil {
+			return err
+		}
+
+		// 知识点删除或改名会影响详情，重算其全部详情
+		pointIDs := make([]uint, 0, len(rows))
+		for _, p := range rows {
+			pointIDs = append(pointIDs, p.ID)
+		}
+		details, err := s.repo.GetDetailsByKnowledgePointIDs(pointIDs)
+		if err != nil {
+			return err
+		}
+		_, _, err = s.projectKnowledgeDetails(categories, details)
+		return err
+	})
+	return upserted, removed, err
+}
+
+// ---------- 知识点详情 ----------
+
+func (s *LearningSearchService) refreshKnowledgeDetails(categories map[uint]categoryInfo, since, until time.Time) (int, int, error) {
+	upserted, removed := 0, 0
+	err := scanAll(s, since, until, func(d *model.KnowledgeDetail) uint { return d.ID }, func(rows []model.KnowledgeDetail) error {
+		u, r, err := s.projectKnowledgeDetails(categories, rows)
+		upserted, removed = upserted+u, removed+r
+		return err
+	})
+	return upserted, removed, err
+}
+
+func (s *LearningSearchService) projectKnowledgeDetails(categories map[uint]categoryInfo, details []model.KnowledgeDetail) (int, int, error) {
+	if len(details) == 0 {
+		return 0, 0, nil
+	}
+
+	pointIDs := make([]uint, 0, len(details))
+	seen := make(map[uint]bool)
+	for _, d := range details {
+		if !seen[d.KnowledgePointID] {
+			seen[d.KnowledgePointID] = true
+			pointIDs = append(pointIDs, d.KnowledgePointID)
+		}
+	}
+	points, err := s.repo.GetKnowledgePointsByIDs(pointIDs)
+	if err != nil {
+		return 0, 0, err
+	}
+	pointByID := make(map[uint]model.KnowledgePoint, len(points))
+	for _, p := range points {
+		pointByID[p.ID] = p
+	}
+
+	var docs []model.LearningSearchDoc
+	var gone []uint
+	for _, d := range details {
+		point, ok := pointByID[d.KnowledgePointID]
+		if d.DeletedAt.Valid || !d.IsActive || !ok || point.DeletedAt.Valid {
+			gone = append(gone, d.ID)
+			continue
+		}
+		title := d.Title
+		if title == "" {
+			title = point.Name
+		}
+		cat := categories[point.CategoryID]
+		docs = append(docs, model.LearningSearchDoc{
+			DocType:         model.LearningDocKnowledgeDetail,
+			SourceID:        d.ID,
+			ParentID:        d.KnowledgePointID,
+			Title:           title,
+			Content:         plainText(d.Content),
+			Subject:         cat.Subject,
+			ExamType:        cat.ExamType,
+			CategoryID:      point.CategoryID,
+			Tags:            model.JSONStringArray{string(d.ContentType)},
+			Popularity:      d.ViewCount,
+			SourceUpdatedAt: d.UpdatedAt,
+		})
+	}
+	return s.applyDocs(model.LearningDocKnowledgeDetail, docs, gone)
+}
+
+// ---------- 笔记 ----------
+
+func (s *LearningSearchService) refreshNotes(since, until time.Time) (int, int, error) {
+	upserted, removed := 0, 0
+	err := scanAll(s, since, until, func(n *model.StudyNote) uint { return n.ID }, func(rows []model.StudyNote) error {
+		var docs []model.LearningSearchDoc
+		var gone []uint
+		for _, n := range rows {
+			if n.DeletedAt.Valid || !n.IsPublic {
+				gone = append(gone, n.ID)
+				continue
+			}
+			docs = append(docs, model.LearningSearchDoc{
+				DocType:         model.LearningDocNote,
+				SourceID:        n.ID,
+				Title:           n.Title,
+				Content:         joinNonEmpty(n.Summary, plainText(n.Content)),
+				Tags:            n.Tags,
+				Popularity:      n.LikeCount,
+				SourceUpdatedAt: n.UpdatedAt,
+			})
+		}
+		u, r, err := s.applyDocs(model.LearningDocNote, docs, gone)
+		upserted, removed = upserted+u, removed+r
+		return err
+	})
+	return upserted, removed, err
+}
+
+// =====================================================
+// 辅助
+// =====================================================
+
+// categoryInfo 分类解析结果（科目、考试类型沿父分类向上继承）
+type categoryInfo struct {
+	Subject  string
+	ExamType string
+	Active   bool
+}
+
+func (s *LearningSearchService) loadCategories() (map[uint]categoryInfo, error) {
+	categories, err := s.repo.GetAllCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]model.CourseCategory, len(categories))
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+
+	infos := make(map[uint]categoryInfo, len(categories))
+	for _, c := range categories {
+		info := categoryInfo{Subject: c.Subject, ExamType: c.ExamType, Active: c.IsActive}
+		parentID := c.ParentID
+		for depth := 0; parentID != nil && depth < 10 && (info.Subject == "" || info.ExamType == ""); depth++ {
+			parent, ok := byID[*parentID]
+			if !ok {
+				break
+			}
+			if info.Subject == "" {
+				info.Subject = parent.Subject
+			}
+			if info.ExamType == "" {
+				info.ExamType = parent.ExamType
+			}
+			parentID = parent.ParentID
+		}
+		infos[c.ID] = info
+	}
+	return infos, nil
+}
+
+var (
+	htmlTagPattern    = regexp.MustCompile(`<[^>]*>`)
+	markdownPattern   = regexp.MustCompile("(?m)^#{1,6}\\s+|[*_`>]{1,3}")
+	whitespacePattern = regexp.MustCompile(`\s+`)
+)
+
+// plainText 去除 HTML 标签和常见 Markdown 标记，折叠空白
+func plainText(s string) string {
+	if s == "" {
+		return ""
+	}
+	s = htmlTagPattern.ReplaceAllString(s, " ")
+	s = html.UnescapeString(s)
+	s = markdownPattern.ReplaceAllString(s, "")
+	return strings.TrimSpace(whitespacePattern.ReplaceAllString(s, " "))
+}
+
+// truncateRunes 按字符截断
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "…"
+}
+
+// joinNonEmpty 以换行连接非空片段
+func joinNonEmpty(parts ...string) string {
+	kept := parts[:0]
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
@@ -347,6 +347,15 @@ func (s *LearningStatsService) GetAbilityReport(userID uint) (*model.AbilityRepo
 	}, nil
 }
 
+// PredictScore 预测用户当前的考试分数（做题量不足时返回 nil）
+func (s *LearningStatsService) PredictScore(userID uint) (*model.PredictedScore, error) {
+	totalStats, err := s.dailyStatsRepo.GetUserTotalStats(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.calculatePredictedScore(s.calculateOverallScore(totalStats), totalStats), nil
+}
+
 // calculateOverallScore 计算综合能力分
 func (s *LearningStatsService) calculateOverallScore(stats *repository.UserTotalStats) float64 {
 	if stats == nil {
@@ -23,11 +23,12 @@ import (
 )
 
 var (
-	ErrLLMConfigNotFound   = errors.New("LLM config not found")
-	ErrLLMConfigNameExists = errors.New("LLM config name already exists")
-	ErrLLMConfigTestFailed = errors.New("LLM config test failed")
-	ErrNoDefaultLLMConfig  = errors.New("no default LLM config")
-	ErrLLMConfigDisabled   = errors.New("LLM config is disabled")
+	ErrLLMConfigNotFound    = errors.New("LLM config not found")
+	ErrLLMConfigNameExists  = errors.New("LLM config name already exists")
+	ErrLLMConfigTestFailed  = errors.New("LLM config test failed")
+	ErrNoDefaultLLMConfig   = errors.New("no default LLM config")
+	ErrLLMConfigDisabled    = errors.New("LLM config is disabled")
+	ErrEmbeddingUnsupported = errors.New("LLM provider does not support OpenAI-compatible embeddings")
 )
 
 // LLM encryption key (should be from config in production)
@@ -664,12 +665,79 @@ func (s *LLMConfigService) GetActiveConfigForExtractor() (*ai.AIExtractor, error
 		Temperature:         float32(config.Temperature),
 		ConfidenceThreshold: 70,
 		Timeout:             time.Duration(config.Timeout) * time.Second,
+		ChunkConcurrency:    4,
 	}
 
 	// Create and return the extractor
 	return ai.NewAIExtractor(aiConfig, s.logger), nil
 }
 
+// GetEmbedder returns an OpenAI-compatible embedder for the given LLM config (0 = default config).
+// The embedding model comes from extra_params.embedding_model, falling back to defaultModel.
+func (s *LLMConfigService) GetEmbedder(configID uint, defaultModel string) (ai.Embedder, error) {
+	var config *model.LLMConfig
+	var err error
+	if configID > 0 {
+		config, err = s.repo.GetByID(configID)
+		if err != nil {
+			return nil, ErrLLMConfigNotFound
+		}
+	} else {
+		config, err = s.repo.GetDefault()
+		if err != nil {
+			return nil, ErrNoDefaultLLMConfig
+		}
+	}
+
+	if !config.IsEnabled {
+		return nil, ErrLLMConfigDisabled
+	}
+
+	switch model.LLMProvider(config.Provider) {
+	case model.LLMProviderAnthropic, model.LLMProviderGemini:
+		return nil, ErrEmbeddingUnsupported
+	}
+
+	apiKey, err := decryptAPIKey(config.APIKeyEncrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt API key: %w", err)
+	}
+
+	embeddingModel := defaultModel
+	if m, ok := config.ExtraParams["embedding_model"].(string); ok && m != "" {
+		embeddingModel = m
+	}
+
+	return ai.NewOpenAIEmbedder(embeddingBaseURL(config), apiKey, embeddingModel,
+		time.Duration(config.Timeout)*time.Second), nil
+}
+
+// embeddingBaseURL derives the API root from a chat endpoint URL
+// e.g. https://api.openai.com/v1/chat/completions -> https://api.openai.com/v1,
+// http://localhost:11434/api/generate -> http://localhost:11434/v1
+func embeddingBaseURL(config *model.LLMConfig) string {
+	if base, ok := config.ExtraParams["embedding_base_url"].(string); ok && base != "" {
+		return base
+	}
+
+	apiURL := config.APIURL
+	if i := strings.Index(apiURL, "?"); i >= 0 {
+		apiURL = apiURL[:i]
+	}
+	apiURL = strings.TrimRight(apiURL, "/")
+	for _, suffix := range []string{"/chat/completions", "/completions", "/embeddings"} {
+		if strings.HasSuffix(apiURL, suffix) {
+			return strings.TrimSuffix(apiURL, suffix)
+		}
+	}
+	if model.LLMProvider(config.Provider) == model.LLMProviderOllama {
+		if i := strings.Index(apiURL, "/api/"); i >= 0 {
+			return apiURL[:i] + "/v1"
+		}
+	}
+	return apiURL
+}
+
 // Encryption helpers
 
 func encryptAPIKey(apiKey string) (string, error) {
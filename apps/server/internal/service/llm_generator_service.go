@@ -2096,7 +2096,9 @@ func (s *LLMGeneratorService) autoImportQuestionBatch(ctx context.Context, taskI
 
 	s.logger.Info("题目内容导入成功",
 		zap.Uint("task_id", taskID),
-		zap.Int("questions_created", result.QuestionsCreated))
+		zap.Int("questions_created", result.QuestionsCreated),
+		zap.Int("questions_rejected", result.QuestionsRejected),
+		zap.Int("questions_flagged", result.QuestionsFlagged))
 }
 
 // GenerateMaterialBatchV2 生成素材批次（V2增强版）
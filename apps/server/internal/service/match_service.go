@@ -30,6 +30,8 @@ type MatchService struct {
 	matchCacheRepo *repository.MatchCacheRepository
 	weights        config.MatchWeightConfig
 	cacheEnabled   bool
+
+	admissionService *AdmissionService
 }
 
 func NewMatchService(
@@ -55,6 +57,11 @@ func (s *MatchService) SetMatchCacheRepo(repo *repository.MatchCacheRepository)
 	s.cacheEnabled = repo != nil
 }
 
+// SetAdmissionService 设置上岸概率服务（匹配报告中附带上岸概率）
+func (s *MatchService) SetAdmissionService(admissionService *AdmissionService) {
+	s.admissionService = admissionService
+}
+
 // IsCacheEnabled 检查缓存是否启用
 func (s *MatchService) IsCacheEnabled() bool {
 	return s.cacheEnabled && s.matchCacheRepo != nil
@@ -1021,6 +1028,7 @@ type MatchReport struct {
 	DimensionStats    *MatchDimensionStats     `json:"dimension_stats,omitempty"`
 	MatchDistribution MatchDistribution        `json:"match_distribution"`
 	TopMatches        []MatchResult            `json:"top_matches,omitempty"`
+	Admission         *AdmissionReport         `json:"admission,omitempty"` // 符合条件职位的上岸概率排名
 }
 
 type MatchDistribution struct {
@@ -1100,6 +1108,26 @@ func (s *MatchService) GenerateMatchReport(userID uint) (*MatchReport, error) {
 	// Generate recommendations
 	report.Recommendations = s.generateRecommendations(profile, matchResp.Stats, dimStats)
 
+	// Admission probability for eligible positions (skipped when the user has too few practice records)
+	if s.admissionService != nil {
+		var eligible []MatchResult
+		for _, result := range matchResp.Results {
+			if result.IsEligible {
+				eligible = append(eligible, result)
+			}
+		}
+		if admission, err := s.admissionService.EstimateForMatches(userID, eligible); err == nil {
+			report.Admission = admission
+			if len(admission.Estimates) > 0 {
+				best := admission.Estimates[0]
+				report.Recommendations = append(report.Recommendations, fmt.Sprintf(
+					"综合上岸概率与匹配度，「%s」期望值最高（进面概率%.0f%%）",
+					best.PositionName, best.InterviewProb*100,
+				))
+			}
+		}
+	}
+
 	return report, nil
 }
 
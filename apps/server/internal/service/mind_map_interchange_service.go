@@ -0,0 +1,258 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/what-cse/server/internal/mindmap"
+	"github.com/what-cse/server/internal/model"
+)
+
+// =====================================================
+// 思维导图导入导出（XMind / FreeMind / OPML / Markdown）
+// =====================================================
+
+var (
+	ErrMindMapFormat      = errors.New("不支持的导图格式，请使用 xmind/mm/opml/md")
+	ErrMindMapInvalidData = errors.New("导图数据格式错误")
+	ErrMindMapEmpty       = errors.New("导图中没有任何节点")
+)
+
+// maxMindMapImportFile 单个导图文件大小上限
+const maxMindMapImportFile = 20 << 20
+
+// ImportMindMapRequest 导图导入选项，批量导入时作用于每个文件
+type ImportMindMapRequest struct {
+	KnowledgePointID *uint             `json:"knowledge_point_id,omitempty"`
+	CategoryID       *uint             `json:"category_id,omitempty"`
+	MapType          model.MindMapType `json:"map_type"`        // 默认 knowledge
+	Title            string            `json:"title,omitempty"` // 仅单文件导入时使用，默认取导图标题
+	Tags             []string          `json:"tags,omitempty"`
+	IsPublic         bool              `json:"is_public"`
+}
+
+// MindMapImportResult 批量导入结果
+type MindMapImportResult struct {
+	Total    int                               `json:"total"`
+	Created  int                               `json:"created"`
+	MindMaps []*model.KnowledgeMindMapResponse `json:"mind_maps"`
+	Failures []MindMapImportFailure            `json:"failures,omitempty"`
+}
+
+// MindMapImportFailure 导入失败的文件
+type MindMapImportFailure struct {
+	File  string `json:"file"`
+	Error string `json:"error"`
+}
+
+// ExportMindMap 把导图转换为指定格式的文件（管理端，不检查公开状态）
+func (s *MindMapService) ExportMindMap(id uint, format string) (*PrintFile, error) {
+	mindMap, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, ErrMindMapNotFound
+	}
+	return s.exportMindMap(mindMap, format)
+}
+
+// ExportUserMindMap 用户导出导图：仅限公开启用的导图或用户自己创建的导图
+func (s *MindMapService) ExportUserMindMap(userID, id uint, format string) (*PrintFile, error) {
+	mindMap, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, ErrMindMapNotFound
+	}
+	if mindMap.CreatedBy != userID && !(mindMap.IsPublic && mindMap.IsActive) {
+		return nil, ErrMindMapNotFound
+	}
+	return s.exportMindMap(mindMap, format)
+}
+
+func (s *MindMapService) exportMindMap(mindMap *model.KnowledgeMindMap, format string) (*PrintFile, error) {
+	f, err := mindmap.ParseFormat(format)
+	if err != nil {
+		return nil, ErrMindMapFormat
+	}
+	m, err := interchangeMindMap(mindMap)
+	if err != nil {
+		return nil, err
+	}
+	data, err := mindmap.Encode(m, f)
+	if errors.Is(err, mindmap.ErrEmpty) {
+		return nil, ErrMindMapEmpty
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.repo.IncrDownloadCount(mindMap.ID)
+	return &PrintFile{Filename: mindMap.Title + f.Ext(), ContentType: f.ContentType(), Data: data}, nil
+}
+
+// ImportMindMap 从 XMind/FreeMind/OPML/Markdown 文件创建导图，格式按文件扩展名识别
+func (s *MindMapService) ImportMindMap(userID uint, fileName string, data []byte, req *ImportMindMapRequest) (*model.KnowledgeMindMap, error) {
+	f, err := mindmap.FormatOf(fileName)
+	if err != nil {
+		return nil, ErrMindMapFormat
+	}
+	m, err := mindmap.Decode(data, f)
+	if errors.Is(err, mindmap.ErrEmpty) {
+		return nil, ErrMindMapEmpty
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMindMapInvalidData, err)
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = strings.TrimSpace(m.Title)
+	}
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+	}
+	if m.Root.Text == "" {
+		m.Root.Text = title
+	}
+	if r := []rune(title); len(r) > 200 {
+		title = string(r[:200])
+	}
+	mapData, err := json.Marshal(mindMapData(m))
+	if err != nil {
+		return nil, err
+	}
+
+	mapType := req.MapType
+	if mapType == "" {
+		mapType = model.MindMapTypeKnowledge
+	}
+	mindMap := &model.KnowledgeMindMap{
+		KnowledgePointID: req.KnowledgePointID,
+		CategoryID:       req.CategoryID,
+		MapType:          mapType,
+		Title:            title,
+		Description:      m.Root.Note,
+		MapData:          string(mapData),
+		Tags:             req.Tags,
+		IsActive:         true,
+		IsPublic:         req.IsPublic,
+		CreatedBy:        userID,
+	}
+	if err := s.repo.Create(mindMap); err != nil {
+		return nil, err
+	}
+	return mindMap, nil
+}
+
+// ImportMindMapDir 批量导入目录（含子目录）下所有支持格式的导图文件，单个文件失败不影响其他文件
+func (s *MindMapService) ImportMindMapDir(userID uint, dir string, req *ImportMindMapRequest) (*MindMapImportResult, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == "__MACOSX" {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+		if _, err := mindmap.FormatOf(path); err == nil {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 批量导入时标题取自各文件
+	fileReq := *req
+	fileReq.Title = ""
+	result := &MindMapImportResult{Total: len(files), MindMaps: []*model.KnowledgeMindMapResponse{}}
+	for _, path := range files {
+		name, _ := filepath.Rel(dir, path)
+		mindMap, err := s.importMindMapFile(userID, path, &fileReq)
+		if err != nil {
+			result.Failures = append(result.Failures, MindMapImportFailure{File: name, Error: err.Error()})
+			continue
+		}
+		result.Created++
+		result.MindMaps = append(result.MindMaps, mindMap.ToResponse())
+	}
+	return result, nil
+}
+
+func (s *MindMapService) importMindMapFile(userID uint, path string, req *ImportMindMapRequest) (*model.KnowledgeMindMap, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > maxMindMapImportFile {
+		return nil, fmt.Errorf("文件超过 %dMB", maxMindMapImportFile>>20)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return s.ImportMindMap(userID, filepath.Base(path), data, req)
+}
+
+// interchangeMindMap 解析导图存储的 JSON 数据
+func interchangeMindMap(mindMap *model.KnowledgeMindMap) (*mindmap.Map, error) {
+	var data model.MindMapData
+	if err := json.Unmarshal([]byte(mindMap.MapData), &data); err != nil {
+		return nil, ErrMindMapInvalidData
+	}
+	if data.Root == nil {
+		return nil, ErrMindMapEmpty
+	}
+	return &mindmap.Map{
+		Title:  mindMap.Title,
+		Root:   interchangeNode(data.Root),
+		Theme:  data.Theme,
+		Layout: data.Layout,
+	}, nil
+}
+
+func interchangeNode(n *model.MindMapNode) *mindmap.Node {
+	node := &mindmap.Node{
+		ID:               n.ID,
+		Text:             n.Text,
+		Note:             n.Note,
+		Link:             n.Link,
+		Color:            n.Color,
+		Icon:             n.Icon,
+		Expanded:         n.Expanded,
+		KnowledgePointID: n.KnowledgePointID,
+	}
+	for _, c := range n.Children {
+		if c != nil {
+			node.Children = append(node.Children, interchangeNode(c))
+		}
+	}
+	return node
+}
+
+// mindMapData 转换为导图存储的数据结构
+func mindMapData(m *mindmap.Map) *model.MindMapData {
+	return &model.MindMapData{Root: mindMapNode(m.Root), Theme: m.Theme, Layout: m.Layout}
+}
+
+func mindMapNode(n *mindmap.Node) *model.MindMapNode {
+	node := &model.MindMapNode{
+		ID:               n.ID,
+		Text:             n.Text,
+		Note:             n.Note,
+		Link:             n.Link,
+		Color:            n.Color,
+		Icon:             n.Icon,
+		Expanded:         n.Expanded,
+		KnowledgePointID: n.KnowledgePointID,
+	}
+	for _, c := range n.Children {
+		node.Children = append(node.Children, mindMapNode(c))
+	}
+	return node
+}
@@ -0,0 +1,642 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/what-cse/server/internal/model"
+	"github.com/what-cse/server/internal/parser"
+	"github.com/what-cse/server/internal/repository"
+)
+
+var (
+	ErrPaperImportEmpty      = errors.New("未能从文件中提取到文本")
+	ErrPaperImportNoQuestion = errors.New("未能识别出题目")
+	ErrPaperImportCategory   = errors.New("无法确定题目分类，请指定默认分类")
+	ErrPaperImportNotFound   = errors.New("导入记录不存在")
+	ErrPaperImportNotDraft   = errors.New("只有草稿状态的导入可以发布或放弃")
+)
+
+// 解析模式
+const (
+	PaperImportModeAuto       = "auto"       // 先规则切分，识别效果差时改用 LLM
+	PaperImportModeStructural = "structural" // 只用规则切分
+	PaperImportModeLLM        = "llm"        // 直接用 LLM
+)
+
+const (
+	paperImportMinQuestions  = 5
+	paperImportMinCoverage   = 0.9
+	paperImportLLMTextRunes  = 60000
+	paperImportLLMTimeout    = 300
+	paperImportLLMMaxTokens  = 16000
+	paperImportDefaultScore  = 100
+	paperImportDefaultMinute = 120
+)
+
+// PaperImportService 真题试卷导入
+// Word/PDF 试卷及答案文件 → 规则切分（失败时 LLM 兜底）→ 草稿试卷、题目和材料 → 审核后发布
+type PaperImportService struct {
+	db               *gorm.DB
+	repo             *repository.PaperImportRepository
+	paperRepo        *repository.ExamPaperRepository
+	questionRepo     *repository.QuestionRepository
+	llmConfigService *LLMConfigService
+	dedupService     *QuestionDedupService
+	parser           *parser.PaperParser
+	logger           *zap.Logger
+}
+
+// NewPaperImportService 创建试卷导入服务
+func NewPaperImportService(
+	db *gorm.DB,
+	repo *repository.PaperImportRepository,
+	paperRepo *repository.ExamPaperRepository,
+	questionRepo *repository.QuestionRepository,
+	llmConfigService *LLMConfigService,
+	logger *zap.Logger,
+) *PaperImportService {
+	return &PaperImportService{
+		db:               db,
+		repo:             repo,
+		paperRepo:        paperRepo,
+		questionRepo:     questionRepo,
+		llmConfigService: llmConfigService,
+		parser:           parser.NewPaperParser(logger),
+		logger:           logger,
+	}
+}
+
+// SetDedupService 设置题目查重服务，生成草稿题目前做近似重复检测
+func (s *PaperImportService) SetDedupService(dedupService *QuestionDedupService) {
+	s.dedupService = dedupService
+}
+
+// PaperImportRequest 试卷导入参数
+type PaperImportRequest struct {
+	PaperPath      string          // 试卷文件本地路径（.docx/.doc/.pdf）
+	PaperFileName  string          // 原始文件名
+	AnswerPath     string          // 答案文件本地路径，可选
+	AnswerFileName string          // 原始文件名
+	Title          string          // 为空时取试卷首行
+	PaperType      model.PaperType // 默认真题卷
+	ExamType       string
+	Subject        string
+	Region         string
+	Year           int
+	CategoryID     uint    // 默认分类，分区名无法匹配到分类时使用
+	TotalScore     float64 // 默认 100，按题平分
+	TimeLimit      int     // 分钟，默认 120
+	Mode           string  // auto/structural/llm
+	AdminID        uint
+}
+
+// PaperImportDetail 导入详情，供审核
+type PaperImportDetail struct {
+	Import    *model.PaperImport         `json:"import"`
+	Paper     *model.ExamPaper           `json:"paper,omitempty"`
+	Sections  []PaperImportSectionDetail `json:"sections,omitempty"`
+	Materials []model.QuestionMaterial   `json:"materials,omitempty"`
+}
+
+// PaperImportSectionDetail 分区及其题目
+type PaperImportSectionDetail struct {
+	Name      string           `json:"name"`
+	Questions []model.Question `json:"questions"`
+}
+
+// Import 解析试卷文件并生成草稿试卷
+func (s *PaperImportService) Import(req PaperImportRequest) (*PaperImportDetail, error) {
+	text, err := s.parser.ExtractText(req.PaperPath)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, ErrPaperImportEmpty
+	}
+
+	var answers map[int]parser.ParsedAnswer
+	if req.AnswerPath != "" {
+		answerText, err := s.parser.ExtractText(req.AnswerPath)
+		if err != nil {
+			return nil, fmt.Errorf("答案文件解析失败: %w", err)
+		}
+		answers = s.parser.ParseAnswerKey(answerText)
+	}
+
+	mode := req.Mode
+	if mode == "" {
+		mode = PaperImportModeAuto
+	}
+
+	method := model.PaperImportMethodStructural
+	var parsed *parser.ParsedPaper
+	if mode != PaperImportModeLLM {
+		parsed = s.parser.Parse(text)
+	}
+
+	if mode == PaperImportModeLLM || (mode == PaperImportModeAuto && !structuralParseUsable(parsed)) {
+		llmParsed, llmErr := s.parseWithLLM(text)
+		switch {
+		case llmErr == nil:
+			parsed = llmParsed
+			method = model.PaperImportMethodLLM
+		case parsed != nil && parsed.QuestionCount() > 0:
+			parsed.Warnings = append(parsed.Warnings, "LLM 结构化失败，保留规则切分结果: "+llmErr.Error())
+		default:
+			return nil, llmErr
+		}
+	}
+	if parsed.QuestionCount() == 0 {
+		return nil, ErrPaperImportNoQuestion
+	}
+
+	missing := parsed.ApplyAnswers(answers)
+	if parsed.Title == "" {
+		parsed.Title = strings.TrimSuffix(req.PaperFileName, fileExt(req.PaperFileName))
+	}
+
+	record, err := s.createDraft(req, parsed, method, missing)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Paper imported as draft",
+		zap.Uint("import_id", record.ID),
+		zap.String("method", method),
+		zap.Int("questions", record.QuestionCount),
+		zap.Int("missing_answers", len(missing)),
+	)
+	return s.GetDetail(record.ID)
+}
+
+// structuralParseUsable 规则切分结果是否可用：题量足够、题号基本连续、
+// 选择题部分的选项识别完整
+func structuralParseUsable(paper *parser.ParsedPaper) bool {
+	if paper == nil || paper.QuestionCount() < paperImportMinQuestions {
+		return false
+	}
+	if paper.Coverage() < paperImportMinCoverage {
+		return false
+	}
+	for _, sec := range paper.Sections {
+		withOptions := 0
+		for _, q := range sec.Questions {
+			if len(q.Options) >= 2 {
+				withOptions++
+			}
+		}
+		// 选择题分区中超过两成题目缺选项，多半是切分错位
+		if withOptions > 0 && float64(len(sec.Questions)-withOptions) > 0.2*float64(len(sec.Questions)) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseWithLLM 由 LLM 把试卷文本结构化为与规则切分相同的格式
+func (s *PaperImportService) parseWithLLM(text string) (*parser.ParsedPaper, error) {
+	if s.llmConfigService == nil {
+		return nil, errors.New("LLM服务未配置")
+	}
+
+	var warnings []string
+	if runes := []rune(text); len(runes) > paperImportLLMTextRunes {
+		text = string(runes[:paperImportLLMTextRunes])
+		warnings = append(warnings, fmt.Sprintf("试卷文本超过 %d 字，LLM 只处理了前半部分", paperImportLLMTextRunes))
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString(`你是公务员考试真题录入助手。请把下面的试卷文本切分为结构化 JSON，只输出 JSON，不要输出其他内容。
+
+要求：
+1. sections 为试卷分区（如“常识判断”“言语理解与表达”“资料分析”），name 不含题量说明
+2. 资料分析等多题共用的资料放入该分区的 materials，题目用 material_index（materials 下标，从 0 开始）引用
+3. number 为原卷题号；content 为题干，不含题号和选项；options 的 key 为 A/B/C/D…
+4. 文本中带有答案或解析时填入 answer（只填选项字母，多选如 "ACD"）和 analysis，没有则留空
+5. 保持原文，不要改写、补全或翻译
+
+输出格式：
+{"title":"试卷标题","sections":[{"name":"分区名","materials":[{"title":"资料标题","content":"资料正文"}],"questions":[{"number":1,"content":"题干","options":[{"key":"A","content":"选项"}],"answer":"","analysis":"","material_index":null}]}]}
+
+## 试卷文本
+`)
+	prompt.WriteString(text)
+
+	response, err := s.llmConfigService.CallWithOptions(prompt.String(), paperImportLLMTimeout, paperImportLLMMaxTokens)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call LLM: %w", err)
+	}
+
+	var parsed parser.ParsedPaper
+	if err := parseJSONResponse(response, &parsed); err != nil {
+		return nil, fmt.Errorf("LLM 返回格式错误: %w", err)
+	}
+	if parsed.QuestionCount() == 0 {
+		return nil, ErrPaperImportNoQuestion
+	}
+
+	// 越界的材料引用视为无材料
+	for si := range parsed.Sections {
+		sec := &parsed.Sections[si]
+		for qi := range sec.Questions {
+			if idx := sec.Questions[qi].MaterialIndex; idx != nil && (*idx < 0 || *idx >= len(sec.Materials)) {
+				sec.Questions[qi].MaterialIndex = nil
+			}
+		}
+	}
+	parsed.Warnings = append(warnings, parsed.Warnings...)
+	return &parsed, nil
+}
+
+// createDraft 在一个事务中写入材料、草稿题目、草稿试卷和导入记录
+func (s *PaperImportService) createDraft(req PaperImportRequest, parsed *parser.ParsedPaper, method string, missing []int) (*model.PaperImport, error) {
+	title := req.Title
+	if title == "" {
+		title = parsed.Title
+	}
+	paperType := req.PaperType
+	if paperType == "" {
+		paperType = model.PaperTypeRealExam
+	}
+	totalScore := req.TotalScore
+	if totalScore <= 0 {
+		totalScore = paperImportDefaultScore
+	}
+	timeLimit := req.TimeLimit
+	if timeLimit <= 0 {
+		timeLimit = paperImportDefaultMinute
+	}
+	var year *int
+	if req.Year > 0 {
+		y := req.Year
+		year = &y
+	}
+	sourceType := model.QuestionSourceMock
+	if paperType == model.PaperTypeRealExam {
+		sourceType = model.QuestionSourceRealExam
+	}
+
+	total := parsed.QuestionCount()
+	score := math.Round(totalScore/float64(total)*10) / 10
+
+	record := &model.PaperImport{
+		Title:          title,
+		PaperFile:      req.PaperFileName,
+		AnswerFile:     req.AnswerFileName,
+		Method:         method,
+		Status:         model.PaperImportStatusDraft,
+		SectionCount:   len(parsed.Sections),
+		QuestionCount:  total,
+		MissingAnswers: model.JSONIntArray(missing),
+		Warnings:       model.JSONStringArray(parsed.Warnings),
+		CreatedBy:      req.AdminID,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		paper := &model.ExamPaper{
+			Title:          title,
+			PaperType:      paperType,
+			ExamType:       req.ExamType,
+			Subject:        req.Subject,
+			Year:           year,
+			Region:         req.Region,
+			TotalQuestions: total,
+			TotalScore:     totalScore,
+			TimeLimit:      timeLimit,
+			Status:         model.PaperStatusDraft,
+			Description:    fmt.Sprintf("由 %s 导入", req.PaperFileName),
+		}
+
+		// 先写入各分区材料并生成题目，整卷一起查重入库
+		categories := make(map[string]uint)
+		var questions []model.Question
+		var sectionOf, numberOf []int
+		for si, sec := range parsed.Sections {
+			categoryID, err := s.sectionCategory(tx, categories, sec.Name, req.CategoryID)
+			if err != nil {
+				return err
+			}
+
+			materialIDs := make([]uint, len(sec.Materials))
+			for i, m := range sec.Materials {
+				material := &model.QuestionMaterial{
+					Title:       truncateRunes(m.Title, 190),
+					Content:     m.Content,
+					ContentType: model.MaterialContentText,
+					SourceYear:  year,
+					SourceExam:  title,
+				}
+				if material.Title == "" {
+					material.Title = fmt.Sprintf("%s 资料%d", sec.Name, i+1)
+				}
+				if err := tx.Create(material).Error; err != nil {
+					return err
+				}
+				materialIDs[i] = material.ID
+				record.MaterialIDs = append(record.MaterialIDs, int(material.ID))
+			}
+
+			for _, q := range sec.Questions {
+				options := make(model.QuestionOptions, len(q.Options))
+				for j, opt := range q.Options {
+					options[j] = model.QuestionOption{Key: opt.Key, Content: opt.Content}
+				}
+				question := model.Question{
+					CategoryID:   categoryID,
+					QuestionType: importedQuestionType(q),
+					SourceType:   sourceType,
+					SourceYear:   year,
+					SourceRegion: req.Region,
+					SourceExam:   title,
+					Content:      q.Content,
+					Options:      options,
+					Answer:       q.Answer,
+					Analysis:     q.Analysis,
+					Difficulty:   3,
+					Status:       model.QuestionStatusDraft,
+					SortOrder:    q.Number,
+				}
+				if q.MaterialIndex != nil {
+					id := materialIDs[*q.MaterialIndex]
+					question.MaterialID = &id
+				}
+				questions = append(questions, question)
+				sectionOf = append(sectionOf, si)
+				numberOf = append(numberOf, q.Number)
+			}
+		}
+
+		questionIDs, err := s.createQuestions(tx, questions, record, parsed, sectionOf, numberOf)
+		if err != nil {
+			return err
+		}
+
+		order := 0
+		for si, sec := range parsed.Sections {
+			section := model.PaperSection{Name: sec.Name}
+			for i, id := range questionIDs {
+				if sectionOf[i] != si {
+					continue
+				}
+				order++
+				section.QuestionIDs = append(section.QuestionIDs, id)
+				paper.Questions = append(paper.Questions, model.PaperQuestion{QuestionID: id, Score: score, Order: order})
+			}
+			paper.Sections = append(paper.Sections, section)
+		}
+
+		if err := tx.Create(paper).Error; err != nil {
+			return err
+		}
+		record.PaperID = &paper.ID
+		record.MaterialCount = len(record.MaterialIDs)
+		return tx.Create(record).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// createQuestions 查重后写入草稿题目，返回每道题在试卷中引用的题目ID
+// 与题库已有题目高度相似的题目不再新建，试卷直接引用已有题目；查重结论记录在导入记录中供审核
+func (s *PaperImportService) createQuestions(tx *gorm.DB, questions []model.Question, record *model.PaperImport, parsed *parser.ParsedPaper, sectionOf, numberOf []int) ([]uint, error) {
+	var created []model.Question
+	// 签名和题对与草稿在同一事务中写入，导入失败时不会残留指向不存在题目的签名
+	report, err := s.dedupService.WithTx(tx).ImportQuestions(questions, model.QuestionDuplicateSourceImport, func(kept []model.Question) error {
+		if err := tx.Create(&kept).Error; err != nil {
+			return err
+		}
+		created = kept
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make(map[int]QuestionDedupItem, len(report.Items))
+	for _, item := range report.Items {
+		items[item.Index] = item
+	}
+	ids := make([]uint, len(questions))
+	k := 0
+	for i := range questions {
+		if item, ok := items[i]; ok && item.Decision == QuestionDedupDecisionRejected {
+			continue
+		}
+		ids[i] = created[k].ID
+		k++
+	}
+
+	for _, item := range report.Items {
+		dup := model.PaperImportDuplicate{
+			Section:       parsed.Sections[sectionOf[item.Index]].Name,
+			Number:        numberOf[item.Index],
+			QuestionID:    item.QuestionID,
+			Decision:      item.Decision,
+			DuplicateOfID: item.DuplicateOfID,
+			Similarity:    item.Similarity,
+		}
+		if item.Decision == QuestionDedupDecisionRejected {
+			if item.DuplicateOf != nil {
+				// 与本卷前面的题目重复
+				dup.DuplicateOfID = ids[*item.DuplicateOf]
+			} else {
+				record.ReusedQuestionIDs = append(record.ReusedQuestionIDs, int(item.DuplicateOfID))
+			}
+			ids[item.Index] = dup.DuplicateOfID
+		}
+		record.Duplicates = append(record.Duplicates, dup)
+	}
+	if report.Rejected > 0 || report.Flagged > 0 {
+		record.Warnings = append(record.Warnings, fmt.Sprintf("查重：%d 道题与已有题目重复，已引用已有题目；%d 道题疑似重复，待审核", report.Rejected, report.Flagged))
+	}
+	return ids, nil
+}
+
+// sectionCategory 分区名匹配到的分类（如“资料分析”），匹配不到时用默认分类
+func (s *PaperImportService) sectionCategory(tx *gorm.DB, cache map[string]uint, name string, fallback uint) (uint, error) {
+	if id, ok := cache[name]; ok {
+		return id, nil
+	}
+	id := fallback
+	if name != "" {
+		var category model.CourseCategory
+		if err := tx.Where("name = ?", name).Order("level ASC, id ASC").First(&category).Error; err == nil {
+			id = category.ID
+		}
+	}
+	if id == 0 {
+		return 0, ErrPaperImportCategory
+	}
+	cache[name] = id
+	return id, nil
+}
+
+// importedQuestionType 按选项和答案推断题型
+func importedQuestionType(q parser.ParsedPaperQuestion) model.QuestionType {
+	switch {
+	case len(q.Options) == 0:
+		switch q.Answer {
+		case "正确", "错误", "对", "错", "√", "×":
+			return model.QuestionTypeJudge
+		}
+		return model.QuestionTypeEssay
+	case len(q.Answer) > 1 && strings.Trim(q.Answer, "ABCDEFGH") == "":
+		return model.QuestionTypeMultiChoice
+	default:
+		return model.QuestionTypeSingleChoice
+	}
+}
+
+func fileExt(name string) string {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[i:]
+	}
+	return ""
+}
+
+// GetDetail 获取导入详情（草稿试卷按分区列出题目）
+func (s *PaperImportService) GetDetail(id uint) (*PaperImportDetail, error) {
+	record, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, ErrPaperImportNotFound
+	}
+	detail := &PaperImportDetail{Import: record}
+	if record.PaperID == nil || record.Status == model.PaperImportStatusDiscarded {
+		return detail, nil
+	}
+
+	paper, err := s.paperRepo.GetByID(*record.PaperID)
+	if err != nil {
+		return detail, nil
+	}
+	detail.Paper = paper
+
+	ids := make([]uint, 0, len(paper.Questions))
+	for _, pq := range paper.Questions {
+		ids = append(ids, pq.QuestionID)
+	}
+	questions, err := s.questionRepo.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]model.Question, len(questions))
+	for _, q := range questions {
+		q.Material = nil
+		byID[q.ID] = q
+	}
+	for _, sec := range paper.Sections {
+		section := PaperImportSectionDetail{Name: sec.Name}
+		for _, qid := range sec.QuestionIDs {
+			if q, ok := byID[qid]; ok {
+				section.Questions = append(section.Questions, q)
+			}
+		}
+		detail.Sections = append(detail.Sections, section)
+	}
+
+	if len(record.MaterialIDs) > 0 {
+		if err := s.db.Where("id IN ?", []int(record.MaterialIDs)).Order("id ASC").Find(&detail.Materials).Error; err != nil {
+			return nil, err
+		}
+	}
+	return detail, nil
+}
+
+// List 获取导入记录
+func (s *PaperImportService) List(status string, page, pageSize int) ([]model.PaperImport, int64, error) {
+	return s.repo.List(status, page, pageSize)
+}
+
+// lockDraft 在事务中锁定草稿状态的导入记录，返回记录、试卷及本次导入新建的题目ID
+// 状态在锁内检查，并发的发布和放弃只有一个能成功
+func (s *PaperImportService) lockDraft(tx *gorm.DB, id uint) (*model.PaperImport, *model.ExamPaper, []uint, error) {
+	var record model.PaperImport
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&record, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, nil, ErrPaperImportNotFound
+		}
+		return nil, nil, nil, err
+	}
+	if record.Status != model.PaperImportStatusDraft || record.PaperID == nil {
+		return nil, nil, nil, ErrPaperImportNotDraft
+	}
+	var paper model.ExamPaper
+	if err := tx.First(&paper, *record.PaperID).Error; err != nil {
+		return nil, nil, nil, ErrPaperNotFound
+	}
+
+	// 引用的已有题目不随草稿发布或删除
+	reused := make(map[uint]bool, len(record.ReusedQuestionIDs))
+	for _, id := range record.ReusedQuestionIDs {
+		reused[uint(id)] = true
+	}
+	ids := make([]uint, 0, len(paper.Questions))
+	for _, pq := range paper.Questions {
+		if !reused[pq.QuestionID] {
+			ids = append(ids, pq.QuestionID)
+		}
+	}
+	return &record, &paper, ids, nil
+}
+
+// Publish 发布草稿试卷及其题目
+func (s *PaperImportService) Publish(id uint) error {
+	now := time.Now()
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		record, paper, questionIDs, err := s.lockDraft(tx, id)
+		if err != nil {
+			return err
+		}
+		if len(questionIDs) > 0 {
+			if err := tx.Model(&model.Question{}).Where("id IN ?", questionIDs).
+				Update("status", model.QuestionStatusPublished).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&model.ExamPaper{}).Where("id = ?", paper.ID).
+			Update("status", model.PaperStatusPublished).Error; err != nil {
+			return err
+		}
+		return tx.Model(record).Updates(map[string]interface{}{
+			"status":       model.PaperImportStatusPublished,
+			"published_at": now,
+		}).Error
+	})
+}
+
+// Discard 放弃导入，删除草稿试卷、题目和材料
+func (s *PaperImportService) Discard(id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		record, paper, questionIDs, err := s.lockDraft(tx, id)
+		if err != nil {
+			return err
+		}
+		if len(questionIDs) > 0 {
+			if err := tx.Where("id IN ?", questionIDs).Delete(&model.Question{}).Error; err != nil {
+				return err
+			}
+			if err := s.dedupService.WithTx(tx).ForgetQuestions(questionIDs); err != nil {
+				return err
+			}
+		}
+		if len(record.MaterialIDs) > 0 {
+			if err := tx.Where("id IN ?", []int(record.MaterialIDs)).Delete(&model.QuestionMaterial{}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Delete(&model.ExamPaper{}, paper.ID).Error; err != nil {
+			return err
+		}
+		return tx.Model(record).Update("status", model.PaperImportStatusDiscarded).Error
+	})
+}
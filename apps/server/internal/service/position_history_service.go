@@ -2,7 +2,6 @@ package service
 
 import (
 	"errors"
-	"math"
 	"sort"
 
 	"github.com/what-cse/server/internal/model"
@@ -19,12 +18,14 @@ var (
 type PositionHistoryService struct {
 	historyRepo  *repository.PositionHistoryRepository
 	positionRepo *repository.PositionRepository
+	predictor    *ScoreLinePredictor
 }
 
 func NewPositionHistoryService(historyRepo *repository.PositionHistoryRepository, positionRepo *repository.PositionRepository) *PositionHistoryService {
 	return &PositionHistoryService{
 		historyRepo:  historyRepo,
 		positionRepo: positionRepo,
+		predictor:    NewScoreLinePredictor(historyRepo),
 	}
 }
 
@@ -246,117 +247,29 @@ func (s *PositionHistoryService) GetAverageScoreLine(examType, province string,
 }
 
 // PredictScoreLine 预测分数线
-// 使用简单线性回归预测
+// 使用分层收缩模型：以该职位历年分数为主，向同类职位收缩，并做年度难度调整
 func (s *PositionHistoryService) PredictScoreLine(positionCode string) (*model.ScoreLinePrediction, error) {
-	scores, years, err := s.historyRepo.GetHistoricalScores(positionCode, 10)
+	histories, err := s.historyRepo.GetByPositionCode(positionCode)
 	if err != nil {
 		return nil, err
 	}
-
-	if len(scores) < 2 {
+	if len(histories) == 0 {
 		return nil, ErrInsufficientData
 	}
 
-	// 反转数据（因为数据库返回是降序）
-	for i, j := 0, len(scores)-1; i < j; i, j = i+1, j-1 {
-		scores[i], scores[j] = scores[j], scores[i]
-		years[i], years[j] = years[j], years[i]
-	}
-
-	// 计算线性回归
-	n := float64(len(scores))
-	var sumX, sumY, sumXY, sumX2 float64
-
-	for i := 0; i < len(scores); i++ {
-		x := float64(years[i])
-		y := scores[i]
-		sumX += x
-		sumY += y
-		sumXY += x * y
-		sumX2 += x * x
-	}
-
-	// 斜率和截距
-	slope := (n*sumXY - sumX*sumY) / (n*sumX2 - sumX*sumX)
-	intercept := (sumY - slope*sumX) / n
-
-	// 预测下一年
-	nextYear := years[len(years)-1] + 1
-	predictedScore := slope*float64(nextYear) + intercept
-
-	// 计算标准差用于置信区间
-	var sumSquaredDiff float64
-	for i := 0; i < len(scores); i++ {
-		predicted := slope*float64(years[i]) + intercept
-		diff := scores[i] - predicted
-		sumSquaredDiff += diff * diff
-	}
-	stdDev := math.Sqrt(sumSquaredDiff / n)
-
-	// 95%置信区间
-	confidenceMargin := 1.96 * stdDev
-
-	// 计算置信度（基于R²）
-	var ssTotal, ssResidual float64
-	avgScore := sumY / n
-	for i := 0; i < len(scores); i++ {
-		ssTotal += (scores[i] - avgScore) * (scores[i] - avgScore)
-		predicted := slope*float64(years[i]) + intercept
-		ssResidual += (scores[i] - predicted) * (scores[i] - predicted)
-	}
-	rSquared := 1 - ssResidual/ssTotal
-	if rSquared < 0 {
-		rSquared = 0
-	}
-
-	// 生成预测依据
-	basis := "基于历年数据的线性回归分析"
-	if len(scores) >= 5 {
-		basis = "基于近" + string(rune('0'+len(scores))) + "年数据的线性回归分析，数据较为充足"
-	} else {
-		basis = "基于近" + string(rune('0'+len(scores))) + "年数据的线性回归分析，数据量较少，预测可能存在偏差"
-	}
-
-	return &model.ScoreLinePrediction{
-		PredictedScore:   math.Round(predictedScore*100) / 100,
-		ConfidenceLow:    math.Round((predictedScore-confidenceMargin)*100) / 100,
-		ConfidenceHigh:   math.Round((predictedScore+confidenceMargin)*100) / 100,
-		ConfidenceLevel:  math.Round(rSquared*100) / 100,
-		HistoricalScores: scores,
-		HistoricalYears:  years,
-		Basis:            basis,
-	}, nil
+	// 数据库按年份降序返回，取最近一年的职位特征
+	return s.predictor.Predict(scoreLineQueryFromHistory(histories[0]))
 }
 
 // PredictScoreLineByPosition 根据当前职位信息预测分数线
+// 新职位没有历年数据时，使用同省份、同单位层级、同考试分类职位的池化估计
 func (s *PositionHistoryService) PredictScoreLineByPosition(position *model.Position) (*model.ScoreLinePrediction, error) {
-	// 首先尝试根据职位代码预测
-	if position.PositionCode != "" {
-		prediction, err := s.PredictScoreLine(position.PositionCode)
-		if err == nil {
-			return prediction, nil
-		}
-	}
-
-	// 如果没有历史数据，使用同类型职位的平均值
-	avgInterview, avgWritten, err := s.historyRepo.GetAverageScoreLine(position.ExamType, position.Province, 3)
-	if err != nil {
-		return nil, err
-	}
-
-	if avgInterview == 0 && avgWritten == 0 {
-		return nil, ErrInsufficientData
-	}
+	return s.predictor.Predict(ScoreLineQueryFromPosition(position))
+}
 
-	return &model.ScoreLinePrediction{
-		PredictedScore:   avgInterview,
-		ConfidenceLow:    avgInterview * 0.9,
-		ConfidenceHigh:   avgInterview * 1.1,
-		ConfidenceLevel:  0.5, // 低置信度
-		HistoricalScores: nil,
-		HistoricalYears:  nil,
-		Basis:            "基于同地区同类型职位的历史平均分数线预测",
-	}, nil
+// BacktestScoreLine 分数线预测回测
+func (s *PositionHistoryService) BacktestScoreLine(examType string, years []int) (*ScoreLineBacktestReport, error) {
+	return s.predictor.Backtest(examType, years)
 }
 
 // =====================================================
@@ -455,6 +368,7 @@ func (s *PositionHistoryService) CreateHistory(req *CreateHistoryRequest) (*mode
 	if err := s.historyRepo.Create(history); err != nil {
 		return nil, err
 	}
+	s.predictor.Invalidate()
 
 	return history.ToResponse(), nil
 }
@@ -498,13 +412,18 @@ func (s *PositionHistoryService) UpdateHistory(id uint, req *CreateHistoryReques
 	if err := s.historyRepo.Update(history); err != nil {
 		return nil, err
 	}
+	s.predictor.Invalidate()
 
 	return history.ToResponse(), nil
 }
 
 // DeleteHistory 删除历史记录
 func (s *PositionHistoryService) DeleteHistory(id uint) error {
-	return s.historyRepo.Delete(id)
+	if err := s.historyRepo.Delete(id); err != nil {
+		return err
+	}
+	s.predictor.Invalidate()
+	return nil
 }
 
 // ImportHistoryData 导入历史数据
@@ -516,7 +435,11 @@ func (s *PositionHistoryService) ImportHistoryData(data []*model.PositionHistory
 		}
 	}
 
-	return s.historyRepo.BatchUpsert(data)
+	if err := s.historyRepo.BatchUpsert(data); err != nil {
+		return err
+	}
+	s.predictor.Invalidate()
+	return nil
 }
 
 // BatchDeleteHistories 批量删除历史记录
@@ -526,5 +449,6 @@ func (s *PositionHistoryService) BatchDeleteHistories(ids []uint) error {
 			return err
 		}
 	}
+	s.predictor.Invalidate()
 	return nil
 }
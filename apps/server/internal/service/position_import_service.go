@@ -0,0 +1,673 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.uber.org/zap"
+
+	"github.com/what-cse/server/internal/ai"
+	"github.com/what-cse/server/internal/model"
+	"github.com/what-cse/server/internal/parser"
+	"github.com/what-cse/server/internal/repository"
+)
+
+var (
+	ErrColumnProfileNotFound = errors.New("列映射方案不存在")
+	ErrColumnProfileName     = errors.New("请填写方案名称")
+	ErrColumnProfileField    = errors.New("列映射包含未知字段")
+	ErrPositionFileFormat    = errors.New("不支持的文件格式，请上传 .xlsx/.xls/.html/.pdf/.doc/.docx")
+	ErrPositionOCRDisabled   = errors.New("未配置 OCR，无法识别扫描版 PDF")
+	ErrPositionFileNoTable   = errors.New("未识别到职位表")
+	ErrPositionFileColumns   = errors.New("职位表缺少必需的列，请调整列映射")
+)
+
+// positionImportSamples 预览时每张表展示的职位数
+const positionImportSamples = 5
+
+// positionRequiredFields 导入必须映射到的字段
+var positionRequiredFields = []string{"position_name", "department_name"}
+
+// positionReparseColumns 重新解析附件时覆盖的列，审核状态、考试类型等人工维护的字段保持不变
+var positionReparseColumns = []string{
+	"position_name", "position_code", "department_code", "department_name", "recruit_count",
+	"education", "major_requirement", "major_list", "is_unlimited_major",
+	"work_location", "province", "city", "district", "political_status",
+	"age", "age_min", "age_max", "work_experience", "work_experience_years", "is_for_fresh_graduate",
+	"gender", "household_requirement", "other_conditions", "remark",
+	"parse_confidence", "parsed_at", "attachment_id", "parser_version",
+}
+
+// PositionImportService 职位表导入
+// Excel/HTML 职位表 → 解析合并表头 → 套用按来源站点或考试类型保存的列映射方案 → 预览 → 导入为待审核职位
+// 扫描版 PDF 先逐页渲染并 OCR，按文字坐标还原表格行列后走同样的列映射
+type PositionImportService struct {
+	profileRepo  *repository.PositionColumnProfileRepository
+	positionRepo *repository.PositionRepository
+	ocrConfig    *parser.OCRConfig
+	ocrCleaner   parser.OCRCellCleaner
+	logger       *zap.Logger
+}
+
+// NewPositionImportService 创建职位表导入服务
+func NewPositionImportService(
+	profileRepo *repository.PositionColumnProfileRepository,
+	positionRepo *repository.PositionRepository,
+	logger *zap.Logger,
+) *PositionImportService {
+	return &PositionImportService{
+		profileRepo:  profileRepo,
+		positionRepo: positionRepo,
+		logger:       logger,
+	}
+}
+
+// SetOCR 设置扫描版 PDF 的 OCR 配置和低置信度单元格校对（可选）
+func (s *PositionImportService) SetOCR(config *parser.OCRConfig, cleaner parser.OCRCellCleaner) {
+	s.ocrConfig = config
+	s.ocrCleaner = cleaner
+}
+
+// =====================================================
+// 列映射方案
+// =====================================================
+
+// PositionColumnProfileRequest 创建/更新列映射方案请求
+type PositionColumnProfileRequest struct {
+	Name       string            `json:"name"`
+	SourceSite string            `json:"source_site"` // 域名或公告链接
+	ExamType   string            `json:"exam_type"`
+	Columns    map[string]string `json:"columns"`
+	HeaderRow  int               `json:"header_row"`
+	HeaderRows int               `json:"header_rows"`
+	Priority   int               `json:"priority"`
+	IsActive   *bool             `json:"is_active"`
+	Remark     string            `json:"remark"`
+}
+
+// ListProfiles 获取全部列映射方案
+func (s *PositionImportService) ListProfiles() ([]model.PositionColumnProfile, error) {
+	return s.profileRepo.List()
+}
+
+// CreateProfile 创建列映射方案
+func (s *PositionImportService) CreateProfile(adminID uint, req *PositionColumnProfileRequest) (*model.PositionColumnProfile, error) {
+	profile := &model.PositionColumnProfile{IsActive: true, CreatedBy: adminID}
+	if err := applyColumnProfileRequest(profile, req); err != nil {
+		return nil, err
+	}
+	if err := s.profileRepo.Create(profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// UpdateProfile 更新列映射方案
+func (s *PositionImportService) UpdateProfile(id uint, req *PositionColumnProfileRequest) (*model.PositionColumnProfile, error) {
+	profile, err := s.profileRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, ErrColumnProfileNotFound
+	}
+	if err := applyColumnProfileRequest(profile, req); err != nil {
+		return nil, err
+	}
+	if err := s.profileRepo.Update(profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// DeleteProfile 删除列映射方案
+func (s *PositionImportService) DeleteProfile(id uint) error {
+	profile, err := s.profileRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if profile == nil {
+		return ErrColumnProfileNotFound
+	}
+	return s.profileRepo.Delete(id)
+}
+
+func applyColumnProfileRequest(profile *model.PositionColumnProfile, req *PositionColumnProfileRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return ErrColumnProfileName
+	}
+	columns, err := normalizeProfileColumns(req.Columns)
+	if err != nil {
+		return err
+	}
+	profile.Name = name
+	profile.SourceSite = normalizeSourceSite(req.SourceSite)
+	profile.ExamType = strings.TrimSpace(req.ExamType)
+	profile.Columns = columns
+	profile.HeaderRow = max(req.HeaderRow, 0)
+	profile.HeaderRows = max(req.HeaderRows, 0)
+	profile.Priority = req.Priority
+	profile.Remark = strings.TrimSpace(req.Remark)
+	if req.IsActive != nil {
+		profile.IsActive = *req.IsActive
+	}
+	return nil
+}
+
+// normalizeProfileColumns 去掉列名中的空白，并校验目标字段
+func normalizeProfileColumns(columns map[string]string) (model.JSONStringMap, error) {
+	known := make(map[string]bool, len(parser.PositionFields)+1)
+	for _, f := range parser.PositionFields {
+		known[f] = true
+	}
+	known[parser.IgnoreColumn] = true
+
+	result := make(model.JSONStringMap, len(columns))
+	for column, field := range columns {
+		column = strings.Join(strings.Fields(column), "")
+		field = strings.TrimSpace(field)
+		if column == "" {
+			continue
+		}
+		if !known[field] {
+			return nil, fmt.Errorf("%w: %s → %s", ErrColumnProfileField, column, field)
+		}
+		result[column] = field
+	}
+	return result, nil
+}
+
+// normalizeSourceSite 把域名或链接规范为小写主机名
+func normalizeSourceSite(site string) string {
+	site = strings.ToLower(strings.TrimSpace(site))
+	if site == "" {
+		return ""
+	}
+	if !strings.Contains(site, "://") {
+		site = "http://" + site
+	}
+	u, err := url.Parse(site)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// MatchProfile 按来源链接和考试类型匹配启用的方案
+// 站点和考试类型都匹配的优先，其次只匹配站点、只匹配考试类型，最后是通用方案；同等匹配时按优先级
+func (s *PositionImportService) MatchProfile(sourceURL, examType string) (*model.PositionColumnProfile, error) {
+	profiles, err := s.profileRepo.ListActive()
+	if err != nil {
+		return nil, err
+	}
+	host := normalizeSourceSite(sourceURL)
+	examType = strings.TrimSpace(examType)
+
+	var best *model.PositionColumnProfile
+	bestScore := -1
+	for i := range profiles {
+		p := &profiles[i]
+		score := 0
+		if p.SourceSite != "" {
+			if host != p.SourceSite && !strings.HasSuffix(host, "."+p.SourceSite) {
+				continue
+			}
+			score += 2
+		}
+		if p.ExamType != "" {
+			if p.ExamType != examType {
+				continue
+			}
+			score++
+		}
+		// 列表已按优先级排序，同分时保留先出现的
+		if score > bestScore {
+			best, bestScore = p, score
+		}
+	}
+	return best, nil
+}
+
+// =====================================================
+// 解析、预览与导入
+// =====================================================
+
+// PositionFileRequest 职位表解析参数
+type PositionFileRequest struct {
+	FilePath       string            // 上传文件本地路径
+	FileName       string            // 原始文件名
+	SourceURL      string            // 公告链接，用于匹配方案和记录来源
+	ExamType       string            // 考试类型，用于匹配方案并写入职位
+	ProfileID      uint              // 指定方案，0 时自动匹配
+	Columns        map[string]string // 预览时临时调整的列映射，覆盖方案中的同名列
+	HeaderRow      int               // 临时指定表头起始行
+	HeaderRows     int               // 临时指定表头行数
+	AnnouncementID *uint
+	AdminID        uint
+
+	FenbiAnnouncementID *uint // 粉笔公告附件，职位标识与 LLM 解析结果一致以便覆盖
+	AttachmentID        *uint // 归档附件，记录职位由哪个文件解析而来
+	Overwrite           bool  // 已存在的职位用本次解析结果覆盖，而不是跳过
+}
+
+// PositionFilePreview 职位表解析预览
+type PositionFilePreview struct {
+	Profile  *model.PositionColumnProfile `json:"profile,omitempty"` // 套用的方案
+	Tables   []PositionTablePreview       `json:"tables"`
+	Total    int                          `json:"total"`              // 识别出的职位数
+	Unmapped []string                     `json:"unmapped,omitempty"` // 未映射的列
+	Missing  []string                     `json:"missing,omitempty"`  // 未映射到的必填字段
+}
+
+// PositionTablePreview 单个工作表/表格的解析预览
+type PositionTablePreview struct {
+	Name       string                  `json:"name"`
+	HeaderRow  int                     `json:"header_row"`
+	HeaderRows int                     `json:"header_rows"`
+	Columns    []parser.HeaderColumn   `json:"columns"`
+	RowCount   int                     `json:"row_count"`
+	Samples    []parser.ParsedPosition `json:"samples"`
+}
+
+// PositionImportResult 职位表导入结果
+type PositionImportResult struct {
+	PositionFilePreview
+	Created int `json:"created"`
+	Updated int `json:"updated"` // Overwrite 时覆盖的已存在职位
+	Skipped int `json:"skipped"` // 已存在的职位
+}
+
+// Preview 解析职位表并返回列映射和样例，不写入数据
+func (s *PositionImportService) Preview(req *PositionFileRequest) (*PositionFilePreview, error) {
+	profile, tables, err := s.parseFile(req)
+	if err != nil {
+		return nil, err
+	}
+	return positionFilePreview(profile, tables), nil
+}
+
+// Import 解析职位表并导入为待审核职位，已存在的职位跳过（Overwrite 时覆盖解析字段）
+func (s *PositionImportService) Import(req *PositionFileRequest) (*PositionImportResult, error) {
+	profile, tables, err := s.parseFile(req)
+	if err != nil {
+		return nil, err
+	}
+	result := &PositionImportResult{PositionFilePreview: *positionFilePreview(profile, tables)}
+	if len(result.Missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrPositionFileColumns, strings.Join(result.Missing, "、"))
+	}
+
+	// 粉笔公告的职位此前可能已由 LLM 解析入库，其职位名称与职位表不完全一致，按职位代码或标准化名称对应
+	var fenbiIDs map[string]string
+	if req.FenbiAnnouncementID != nil {
+		existingPositions, err := s.positionRepo.GetByFenbiAnnouncementID(*req.FenbiAnnouncementID)
+		if err != nil {
+			return nil, err
+		}
+		fenbiIDs = fenbiPositionIDs(existingPositions)
+	}
+
+	now := time.Now()
+	seen := make(map[string]bool)
+	var positions []model.Position
+	var ids []string
+	for _, table := range tables {
+		for i := range table.Positions {
+			position := importedPosition(&table.Positions[i], req, now)
+			if id, ok := matchFenbiPosition(fenbiIDs, position); ok {
+				position.PositionID = id
+			}
+			if seen[position.PositionID] {
+				result.Skipped++
+				continue
+			}
+			seen[position.PositionID] = true
+			positions = append(positions, *position)
+			ids = append(ids, position.PositionID)
+		}
+	}
+
+	existing, err := s.positionRepo.ExistingPositionIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	fresh := positions[:0]
+	for _, p := range positions {
+		if !existing[p.PositionID] {
+			fresh = append(fresh, p)
+			continue
+		}
+		if !req.Overwrite {
+			result.Skipped++
+			continue
+		}
+		if err := s.positionRepo.UpdateColumnsByPositionID(&p, positionReparseColumns); err != nil {
+			return nil, err
+		}
+		result.Updated++
+	}
+	if len(fresh) > 0 {
+		if err := s.positionRepo.BatchCreate(fresh); err != nil {
+			return nil, err
+		}
+	}
+	result.Created = len(fresh)
+
+	s.logger.Info("Imported position table",
+		zap.String("file", req.FileName),
+		zap.Int("total", result.Total),
+		zap.Int("created", result.Created),
+		zap.Int("updated", result.Updated),
+		zap.Int("skipped", result.Skipped),
+	)
+	return result, nil
+}
+
+// parseFile 选择方案并按文件类型解析
+func (s *PositionImportService) parseFile(req *PositionFileRequest) (*model.PositionColumnProfile, []parser.ParsedTable, error) {
+	var profile *model.PositionColumnProfile
+	var err error
+	if req.ProfileID > 0 {
+		profile, err = s.profileRepo.GetByID(req.ProfileID)
+		if err == nil && profile == nil {
+			err = ErrColumnProfileNotFound
+		}
+	} else {
+		profile, err = s.MatchProfile(req.SourceURL, req.ExamType)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columnProfile := toColumnProfile(profile)
+	overrides, err := normalizeProfileColumns(req.Columns)
+	if err != nil {
+		return nil, nil, err
+	}
+	for k, v := range overrides {
+		columnProfile.Columns[k] = v
+	}
+	if req.HeaderRow > 0 {
+		columnProfile.HeaderRow = req.HeaderRow
+	}
+	if req.HeaderRows > 0 {
+		columnProfile.HeaderRows = req.HeaderRows
+	}
+
+	var tables []parser.ParsedTable
+	switch strings.ToLower(filepath.Ext(req.FileName)) {
+	case ".xlsx", ".xls", ".xlsm", ".et":
+		excelParser := parser.NewExcelParser(s.logger)
+		excelParser.SetProfile(columnProfile)
+		tables, err = excelParser.ParseDetailed(req.FilePath)
+	case ".html", ".htm":
+		var data []byte
+		if data, err = os.ReadFile(req.FilePath); err == nil {
+			htmlParser := parser.NewHTMLTableParser(s.logger)
+			htmlParser.SetProfile(columnProfile)
+			tables, err = htmlParser.ParseTablesDetailed(string(data))
+		}
+	case ".pdf":
+		var ocrParser *parser.OCRPositionParser
+		if s.ocrConfig != nil {
+			ocrParser = parser.NewOCRParser(s.ocrConfig, s.logger)
+		}
+		if ocrParser == nil || !ocrParser.IsAvailable() {
+			// 重新解析归档附件时没有 OCR 也尽量从文本层提取
+			if !req.Overwrite {
+				return nil, nil, ErrPositionOCRDisabled
+			}
+			tables, err = textTables(parser.NewPDFParser(s.logger).Parse(req.FilePath))
+			break
+		}
+		ocrParser.SetProfile(columnProfile)
+		if s.ocrCleaner != nil {
+			ocrParser.SetCellCleaner(s.ocrCleaner)
+		}
+		tables, err = ocrParser.ParsePDFTables(req.FilePath, "")
+	case ".doc", ".docx":
+		tables, err = textTables(parser.NewWordParser(s.logger).Parse(req.FilePath))
+	default:
+		return nil, nil, ErrPositionFileFormat
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(tables) == 0 {
+		return nil, nil, ErrPositionFileNoTable
+	}
+	return profile, tables, nil
+}
+
+// toColumnProfile 把方案转换为解析器的列映射，方案为空时返回空映射（自动识别表头）
+func toColumnProfile(profile *model.PositionColumnProfile) *parser.ColumnProfile {
+	columnProfile := &parser.ColumnProfile{Columns: map[string]string{}}
+	if profile != nil {
+		for k, v := range profile.Columns {
+			columnProfile.Columns[k] = v
+		}
+		columnProfile.HeaderRow = profile.HeaderRow
+		columnProfile.HeaderRows = profile.HeaderRows
+	}
+	return columnProfile
+}
+
+// textTables 把按文本行识别的职位包装为一张没有列信息的表
+func textTables(positions []parser.ParsedPosition, err error) ([]parser.ParsedTable, error) {
+	if err != nil || len(positions) == 0 {
+		return nil, err
+	}
+	return []parser.ParsedTable{{Name: "text", Positions: positions}}, nil
+}
+
+func positionFilePreview(profile *model.PositionColumnProfile, tables []parser.ParsedTable) *PositionFilePreview {
+	preview := &PositionFilePreview{Profile: profile, Tables: make([]PositionTablePreview, 0, len(tables))}
+	mapped := make(map[string]bool)
+	unmapped := make(map[string]bool)
+	textOnly := true
+	for _, t := range tables {
+		samples := t.Positions
+		if len(samples) > positionImportSamples {
+			samples = samples[:positionImportSamples]
+		}
+		preview.Tables = append(preview.Tables, PositionTablePreview{
+			Name:       t.Name,
+			HeaderRow:  t.HeaderRow,
+			HeaderRows: t.HeaderRows,
+			Columns:    t.Columns,
+			RowCount:   len(t.Positions),
+			Samples:    samples,
+		})
+		preview.Total += len(t.Positions)
+		if len(t.Columns) > 0 {
+			textOnly = false
+		}
+		for _, c := range t.Columns {
+			if c.Field != "" {
+				mapped[c.Field] = true
+			} else if c.Source == parser.ColumnSourceUnmapped && c.Name != "" {
+				unmapped[c.Name] = true
+			}
+		}
+	}
+	for name := range unmapped {
+		preview.Unmapped = append(preview.Unmapped, name)
+	}
+	sort.Strings(preview.Unmapped)
+	if textOnly {
+		// 文本解析没有表头，无从检查列映射
+		return preview
+	}
+	for _, f := range positionRequiredFields {
+		if !mapped[f] {
+			preview.Missing = append(preview.Missing, f)
+		}
+	}
+	return preview
+}
+
+// importedPosition 把职位表中的一行转换为待审核职位
+func importedPosition(p *parser.ParsedPosition, req *PositionFileRequest, now time.Time) *model.Position {
+	parsed := &ParsedPosition{
+		PositionName:         p.PositionName,
+		PositionCode:         p.PositionCode,
+		DepartmentName:       p.DepartmentName,
+		RecruitCount:         p.RecruitCount,
+		Education:            p.EducationMin,
+		MajorRequirement:     strings.Join(p.MajorSpecific, "、"),
+		MajorList:            p.MajorSpecific,
+		IsUnlimitedMajor:     p.MajorUnlimited,
+		WorkLocation:         p.WorkLocation,
+		PoliticalStatus:      p.PoliticalStatus,
+		Age:                  p.AgeRequirement,
+		WorkExperience:       p.WorkExpRequirement,
+		Gender:               p.GenderRequired,
+		HouseholdRequirement: p.HukouRequirement,
+		OtherConditions:      p.OtherRequirements,
+		ExamType:             req.ExamType,
+		ParseConfidence:      p.ParseConfidence,
+	}
+	if parsed.IsUnlimitedMajor {
+		parsed.MajorRequirement = "不限"
+	}
+	NormalizePosition(parsed)
+
+	// 同一考试、单位和职位代码（无代码时用职位名称）视为同一职位
+	key := parsed.PositionCode
+	if key == "" {
+		key = parsed.PositionName
+	}
+	positionID := fmt.Sprintf("import_%s", generatePositionHash(key+"_"+p.DepartmentCode, parsed.DepartmentName+"_"+req.ExamType))
+	if req.AnnouncementID != nil {
+		positionID = fmt.Sprintf("import_%d_%s", *req.AnnouncementID, generatePositionHash(key+"_"+p.DepartmentCode, parsed.DepartmentName))
+	}
+	if req.FenbiAnnouncementID != nil {
+		// 公告下没有可对应的已有职位时使用的标识，格式与 SavePositionsFromParseResult 相同
+		positionID = fmt.Sprintf("fenbi_%d_%s", *req.FenbiAnnouncementID, generatePositionHash(parsed.PositionName, parsed.DepartmentName))
+	}
+
+	return &model.Position{
+		AnnouncementID:       req.AnnouncementID,
+		FenbiAnnouncementID:  req.FenbiAnnouncementID,
+		AttachmentID:         req.AttachmentID,
+		PositionID:           positionID,
+		PositionName:         parsed.PositionName,
+		PositionCode:         parsed.PositionCode,
+		DepartmentCode:       p.DepartmentCode,
+		DepartmentName:       parsed.DepartmentName,
+		RecruitCount:         parsed.RecruitCount,
+		Education:            parsed.Education,
+		MajorRequirement:     parsed.MajorRequirement,
+		MajorList:            model.JSONStringArray(parsed.MajorList),
+		IsUnlimitedMajor:     parsed.IsUnlimitedMajor,
+		WorkLocation:         parsed.WorkLocation,
+		Province:             parsed.Province,
+		City:                 parsed.City,
+		District:             parsed.District,
+		PoliticalStatus:      parsed.PoliticalStatus,
+		Age:                  parsed.Age,
+		AgeMin:               parsed.AgeMin,
+		AgeMax:               parsed.AgeMax,
+		WorkExperience:       parsed.WorkExperience,
+		WorkExperienceYears:  parsed.WorkExperienceYears,
+		IsForFreshGraduate:   parsed.IsForFreshGraduate,
+		Gender:               parsed.Gender,
+		HouseholdRequirement: parsed.HouseholdRequirement,
+		OtherConditions:      parsed.OtherConditions,
+		ExamType:             parsed.ExamType,
+		Remark:               p.Notes,
+		SourceURL:            req.SourceURL,
+		ParseConfidence:      parsed.ParseConfidence,
+		ParsedAt:             &now,
+		ParserVersion:        parser.Version,
+		Status:               int(model.PositionStatusPending),
+	}
+}
+
+// fenbiPositionIDs 按职位代码和标准化后的职位名称+单位名称索引公告下已有职位的标识
+func fenbiPositionIDs(positions []model.Position) map[string]string {
+	ids := make(map[string]string, len(positions)*2)
+	for _, p := range positions {
+		if p.PositionCode != "" {
+			ids["code:"+p.PositionCode] = p.PositionID
+		}
+		if key := positionNameKey(p.PositionName, p.DepartmentName); key != "" {
+			if _, ok := ids[key]; !ok {
+				ids[key] = p.PositionID
+			}
+		}
+	}
+	return ids
+}
+
+// matchFenbiPosition 先按职位代码、再按标准化名称查找公告下已有的同一职位
+func matchFenbiPosition(ids map[string]string, position *model.Position) (string, bool) {
+	if len(ids) == 0 {
+		return "", false
+	}
+	if position.PositionCode != "" {
+		if id, ok := ids["code:"+position.PositionCode]; ok {
+			return id, true
+		}
+	}
+	if key := positionNameKey(position.PositionName, position.DepartmentName); key != "" {
+		if id, ok := ids[key]; ok {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// positionNameKey 去掉空白和括号差异后的职位名称+单位名称
+func positionNameKey(positionName, departmentName string) string {
+	normalize := func(s string) string {
+		return strings.Map(func(r rune) rune {
+			switch {
+			case unicode.IsSpace(r):
+				return -1
+			case r == '（':
+				return '('
+			case r == '）':
+				return ')'
+			}
+			return r
+		}, s)
+	}
+	name := normalize(positionName)
+	if name == "" {
+		return ""
+	}
+	return "name:" + name + "|" + normalize(departmentName)
+}
+
+// LLMOCRCellCleaner 使用默认 LLM 配置校对低置信度的 OCR 单元格
+type LLMOCRCellCleaner struct {
+	llmConfigService *LLMConfigService
+}
+
+// NewLLMOCRCellCleaner 创建 OCR 单元格校对
+func NewLLMOCRCellCleaner(llmConfigService *LLMConfigService) *LLMOCRCellCleaner {
+	return &LLMOCRCellCleaner{llmConfigService: llmConfigService}
+}
+
+// CleanCells 实现 parser.OCRCellCleaner
+func (c *LLMOCRCellCleaner) CleanCells(ctx context.Context, cells []parser.LowConfidenceCell) (map[int]string, error) {
+	extractor, err := c.llmConfigService.GetActiveConfigForExtractor()
+	if err != nil {
+		return nil, err
+	}
+	inputs := make([]ai.OCRCellInput, len(cells))
+	for i, cell := range cells {
+		inputs[i] = ai.OCRCellInput{ID: cell.ID, Column: cell.Column, Text: cell.Text, Row: cell.Row}
+	}
+	return extractor.CleanOCRCells(ctx, inputs)
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 
 	"github.com/what-cse/server/internal/model"
@@ -14,6 +15,7 @@ var (
 type PositionService struct {
 	positionRepo *repository.PositionRepository
 	favoriteRepo *repository.FavoriteRepository
+	searchRouter *SearchRouter
 }
 
 func NewPositionService(positionRepo *repository.PositionRepository, favoriteRepo *repository.FavoriteRepository) *PositionService {
@@ -23,6 +25,11 @@ func NewPositionService(positionRepo *repository.PositionRepository, favoriteRep
 	}
 }
 
+// SetSearchRouter 设置搜索路由，设置后关键词搜索走 Elasticsearch / MySQL 全文索引
+func (s *PositionService) SetSearchRouter(router *SearchRouter) {
+	s.searchRouter = router
+}
+
 // =====================================================
 // 请求和响应结构体
 // =====================================================
@@ -60,6 +67,10 @@ type PositionBriefListResponse struct {
 	Total     int64                          `json:"total"`
 	Page      int                            `json:"page"`
 	PageSize  int                            `json:"page_size"`
+
+	// 关键词搜索时返回
+	Highlights map[string]map[string][]string `json:"highlights,omitempty"`
+	Backend    string                         `json:"backend,omitempty"`
 }
 
 // PositionStatsResponse 统计响应
@@ -260,9 +271,74 @@ func (s *PositionService) ListPositionsWithParams(params *repository.PositionQue
 }
 
 // SearchPositions 搜索职位
+// 筛选条件都能由搜索 DSL 表达时走搜索路由（全文索引、相关度排序），否则退回 LIKE 查询
 func (s *PositionService) SearchPositions(keyword string, params *repository.PositionQueryParams) (*PositionBriefListResponse, error) {
 	params.Keyword = keyword
-	return s.ListPositionsWithParams(params)
+	if s.searchRouter == nil || !searchQueryCovers(params) {
+		return s.ListPositionsWithParams(params)
+	}
+
+	result, err := s.searchRouter.Search(context.Background(), SearchQuery{
+		Keyword:            params.Keyword,
+		Province:           params.Province,
+		City:               params.City,
+		Department:         params.DepartmentKeyword,
+		Education:          params.Education,
+		ExamType:           params.ExamType,
+		Provinces:          params.Provinces,
+		Educations:         params.Educations,
+		ExamTypes:          params.ExamTypes,
+		DepartmentLevel:    params.DepartmentLevel,
+		MajorCategory:      params.MajorCategory,
+		RegistrationStatus: params.RegistrationStatus,
+		IsUnlimitedMajor:   params.IsUnlimitedMajor,
+		IsForFreshGrad:     params.IsForFreshGrad,
+		MinRecruitCount:    params.MinRecruitCount,
+		SortBy:             params.SortBy,
+		SortOrder:          params.SortOrder,
+		Highlight:          true,
+		Page:               params.Page,
+		PageSize:           params.PageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	briefPositions := make([]*model.PositionBriefResponse, len(result.Positions))
+	for i := range result.Positions {
+		briefPositions[i] = result.Positions[i].ToBriefResponse()
+	}
+
+	page, pageSize := params.Page, params.PageSize
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	return &PositionBriefListResponse{
+		Positions:  briefPositions,
+		Total:      result.Total,
+		Page:       page,
+		PageSize:   pageSize,
+		Highlights: result.Highlights,
+		Backend:    result.Backend,
+	}, nil
+}
+
+// searchQueryCovers 判断查询参数是否都能由搜索 DSL 表达
+func searchQueryCovers(params *repository.PositionQueryParams) bool {
+	return params.District == "" &&
+		params.Major == "" &&
+		params.PoliticalStatus == "" &&
+		params.Gender == "" &&
+		params.HasNoExperience == nil &&
+		params.AgeMax == 0 &&
+		params.WorkExperienceYearsMin == 0 &&
+		params.ExpiringInDays == 0 &&
+		!params.UpdatedToday &&
+		params.Status == nil
 }
 
 // GetPositionDetailWithFavorite 获取职位详情（含收藏状态）
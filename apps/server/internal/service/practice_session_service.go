@@ -27,6 +27,8 @@ type PracticeSessionService struct {
 	questionRepo     *repository.QuestionRepository
 	recordRepo       *repository.UserQuestionRecordRepository
 	weakCategoryRepo *repository.UserWeakCategoryRepository
+	irtService       *IRTService
+	masteryService   *KnowledgeMasteryService
 }
 
 func NewPracticeSessionService(
@@ -45,6 +47,16 @@ func NewPracticeSessionService(
 	}
 }
 
+// SetIRTService 设置 IRT 服务（自适应练习与按能力选题）
+func (s *PracticeSessionService) SetIRTService(irtService *IRTService) {
+	s.irtService = irtService
+}
+
+// SetKnowledgeMasteryService 设置知识点掌握度服务（作答后更新知识追踪）
+func (s *PracticeSessionService) SetKnowledgeMasteryService(masteryService *KnowledgeMasteryService) {
+	s.masteryService = masteryService
+}
+
 // =====================================================
 // 创建练习会话
 // =====================================================
@@ -67,9 +79,12 @@ func (s *PracticeSessionService) CreateSession(userID uint, req *model.CreatePra
 
 	// Generate questions based on session type
 	var questions []model.Question
+	var adaptive *model.AdaptiveSessionState
 	var err error
 
 	switch req.SessionType {
+	case model.PracticeSessionTypeAdaptive:
+		questions, adaptive, err = s.generateAdaptiveQuestions(userID, config)
 	case model.PracticeSessionTypeSpecialized:
 		questions, err = s.generateSpecializedQuestions(userID, config)
 	case model.PracticeSessionTypeRandom:
@@ -119,6 +134,7 @@ func (s *PracticeSessionService) CreateSession(userID uint, req *model.CreatePra
 		TotalQuestions: len(questions),
 		TimeLimit:      timeLimit,
 		Status:         model.PracticeSessionStatusPending,
+		Adaptive:       adaptive,
 	}
 
 	if err := s.sessionRepo.Create(session); err != nil {
@@ -227,7 +243,7 @@ func (s *PracticeSessionService) generateSmartRandomQuestions(userID uint, count
 		// Get questions from weak categories
 		questionsPerCategory := (weakCount + len(weakCategoryIDs) - 1) / len(weakCategoryIDs)
 		for _, categoryID := range weakCategoryIDs {
-			questions, err := s.questionRepo.GetRandomQuestions(categoryID, questionsPerCategory, excludeIDs)
+			questions, err := s.pickCategoryQuestions(userID, categoryID, questionsPerCategory, excludeIDs)
 			if err == nil && len(questions) > 0 {
 				allQuestions = append(allQuestions, questions...)
 				for _, q := range questions {
@@ -261,6 +277,61 @@ func (s *PracticeSessionService) generateSmartRandomQuestions(userID uint, count
 	return allQuestions, nil
 }
 
+// generateAdaptiveQuestions 生成自适应练习的首题，后续题目在每次作答后按能力估计追加，
+// QuestionCount 作为题量上限
+func (s *PracticeSessionService) generateAdaptiveQuestions(userID uint, config model.PracticeSessionConfig) ([]model.Question, *model.AdaptiveSessionState, error) {
+	if s.irtService == nil {
+		return nil, nil, ErrAdaptiveUnavailable
+	}
+	recentIDs, _ := s.getRecentQuestionIDs(userID, 7)
+
+	state := s.irtService.StartAdaptive(userID, config.CategoryIDs)
+	question, err := s.irtService.NextAdaptiveQuestion(state, config.CategoryIDs, recentIDs)
+	if errors.Is(err, ErrNoQuestionsForSession) && len(recentIDs) > 0 {
+		// 近期题目已做完时允许重复
+		question, err = s.irtService.NextAdaptiveQuestion(state, config.CategoryIDs, nil)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return []model.Question{*question}, state, nil
+}
+
+// nextAdaptiveQuestion 自适应练习作答后追加下一题，估计已收敛或已达题量上限时不再追加
+func (s *PracticeSessionService) nextAdaptiveQuestion(session *model.PracticeSession, question *model.Question, isCorrect bool) {
+	if s.irtService == nil || session.Adaptive == nil {
+		return
+	}
+	s.irtService.AdvanceAdaptive(session.Adaptive, question, isCorrect)
+	if session.Adaptive.Converged || len(session.Questions) >= session.Config.QuestionCount {
+		return
+	}
+
+	excludeIDs, _ := s.getRecentQuestionIDs(session.UserID, 7)
+	for _, q := range session.Questions {
+		excludeIDs = append(excludeIDs, q.QuestionID)
+	}
+	next, err := s.irtService.NextAdaptiveQuestion(session.Adaptive, session.Config.CategoryIDs, excludeIDs)
+	if err != nil {
+		return
+	}
+	session.Questions = append(session.Questions, model.SessionQuestion{
+		QuestionID: next.ID,
+		Order:      len(session.Questions) + 1,
+	})
+	session.TotalQuestions = len(session.Questions)
+}
+
+// pickCategoryQuestions 从分类中挑题，有能力估计时优先选难度贴合用户水平的题目
+func (s *PracticeSessionService) pickCategoryQuestions(userID, categoryID uint, count int, excludeIDs []uint) ([]model.Question, error) {
+	if s.irtService != nil {
+		if questions, err := s.irtService.SelectQuestions(userID, categoryID, count, excludeIDs); err == nil && len(questions) > 0 {
+			return questions, nil
+		}
+	}
+	return s.questionRepo.GetRandomQuestions(categoryID, count, excludeIDs)
+}
+
 // generateTimedQuestions 生成计时练习题目
 func (s *PracticeSessionService) generateTimedQuestions(userID uint, config model.PracticeSessionConfig) ([]model.Question, error) {
 	// Same as random for now, can be customized
@@ -447,6 +518,11 @@ func (s *PracticeSessionService) SubmitAnswer(sessionID, userID uint, req *model
 		session.WrongCount++
 	}
 
+	// Adaptive: update ability estimate and append the next question
+	if session.SessionType == model.PracticeSessionTypeAdaptive {
+		s.nextAdaptiveQuestion(session, question, isCorrect)
+	}
+
 	// Check if completed
 	if session.CompletedCount >= session.TotalQuestions {
 		now := time.Now()
@@ -489,6 +565,14 @@ func (s *PracticeSessionService) SubmitAnswer(sessionID, userID uint, req *model
 		_ = s.weakCategoryRepo.UpdateFromQuestionRecord(userID, question.CategoryID, isCorrect)
 	}
 
+	// Update ability estimate and knowledge mastery
+	if s.irtService != nil {
+		s.irtService.RecordAnswer(userID, question, isCorrect)
+	}
+	if s.masteryService != nil {
+		s.masteryService.RecordAnswer(userID, question, isCorrect)
+	}
+
 	// Return response
 	resp := question.ToDetailResponse()
 	resp.UserAnswer = req.UserAnswer
@@ -766,6 +850,7 @@ func (s *PracticeSessionService) buildDetailResponse(session *model.PracticeSess
 		PracticeSessionResponse: *session.ToResponse(),
 		Config:                  session.Config,
 		Questions:               questionsWithDetail,
+		Adaptive:                session.Adaptive,
 	}, nil
 }
 
@@ -0,0 +1,393 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/what-cse/server/internal/config"
+	"github.com/what-cse/server/internal/export"
+	"github.com/what-cse/server/internal/model"
+	"github.com/what-cse/server/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPrintExportFormat      = errors.New("不支持的导出格式，可选 pdf/docx/html")
+	ErrPrintExportEmpty       = errors.New("没有可导出的题目")
+	ErrPrintExportTooMany     = errors.New("导出题目数量超过上限")
+	ErrPrintExportUnavailable = errors.New("PDF 渲染服务不可用，请稍后重试或导出 DOCX")
+)
+
+// 打印导出格式
+const (
+	PrintFormatPDF  = "pdf"
+	PrintFormatDOCX = "docx"
+	PrintFormatHTML = "html"
+)
+
+// PrintExportOptions 打印导出选项
+type PrintExportOptions struct {
+	Format      string `json:"format"`       // pdf/docx/html
+	AnswerMode  string `json:"answer_mode"`  // inline: 题后附答案; appendix: 文末附答案; hidden: 不含答案
+	AnswerSheet bool   `json:"answer_sheet"` // 是否附答题卡
+	Watermark   bool   `json:"watermark"`    // 是否加用户ID水印
+}
+
+// PrintFile 导出的文件
+type PrintFile struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// PrintExportService 试卷与错题本打印导出服务（PDF/DOCX）
+type PrintExportService struct {
+	paperRepo        *repository.ExamPaperRepository
+	questionRepo     *repository.QuestionRepository
+	materialRepo     *repository.QuestionMaterialRepository
+	studyNoteService *StudyNoteService
+	pdf              *export.PDFRenderer
+	font             *export.Font
+	maxQuestions     int
+	logger           *zap.Logger
+}
+
+// NewPrintExportService 创建打印导出服务，字体加载失败时退回系统字体
+func NewPrintExportService(
+	paperRepo *repository.ExamPaperRepository,
+	questionRepo *repository.QuestionRepository,
+	materialRepo *repository.QuestionMaterialRepository,
+	studyNoteService *StudyNoteService,
+	cfg config.ExportConfig,
+	logger *zap.Logger,
+) *PrintExportService {
+	s := &PrintExportService{
+		paperRepo:        paperRepo,
+		questionRepo:     questionRepo,
+		materialRepo:     materialRepo,
+		studyNoteService: studyNoteService,
+		pdf:              export.NewPDFRenderer(cfg.ChromePath, time.Duration(cfg.TimeoutSeconds)*time.Second, cfg.MaxConcurrent),
+		maxQuestions:     cfg.MaxQuestions,
+		logger:           logger,
+	}
+	if s.maxQuestions <= 0 {
+		s.maxQuestions = 500
+	}
+
+	if cfg.FontPath != "" {
+		font, err := export.LoadFont(cfg.FontPath, cfg.FontFamily)
+		if err != nil {
+			logger.Warn("Failed to load export font, falling back to system fonts",
+				zap.String("path", cfg.FontPath), zap.Error(err))
+		} else {
+			s.font = font
+		}
+	}
+	return s
+}
+
+// ExportPaper 导出已发布试卷
+func (s *PrintExportService) ExportPaper(ctx context.Context, paperID, userID uint, opts PrintExportOptions) (*PrintFile, error) {
+	paper, err := s.paperRepo.GetByID(paperID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPaperNotFound
+		}
+		return nil, err
+	}
+	if paper.Status != model.PaperStatusPublished {
+		return nil, ErrPaperNotPublished
+	}
+	if len(paper.Questions) == 0 {
+		return nil, ErrPrintExportEmpty
+	}
+	if len(paper.Questions) > s.maxQuestions {
+		return nil, ErrPrintExportTooMany
+	}
+
+	ids := make([]uint, 0, len(paper.Questions))
+	scores := make(map[uint]float64, len(paper.Questions))
+	for _, pq := range paper.Questions {
+		ids = append(ids, pq.QuestionID)
+		scores[pq.QuestionID] = pq.Score
+	}
+	questions, err := s.questionRepo.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*model.Question, len(questions))
+	for i := range questions {
+		byID[questions[i].ID] = &questions[i]
+	}
+
+	doc := &export.Document{
+		Title:    paper.Title,
+		Subtitle: joinWithSpace(paper.ExamType, paper.Subject, paper.Region, yearLabel(paper.Year)),
+	}
+	if paper.TimeLimit > 0 {
+		doc.Meta = append(doc.Meta, fmt.Sprintf("限时 %d 分钟", paper.TimeLimit))
+	}
+	if paper.TotalScore > 0 {
+		doc.Meta = append(doc.Meta, fmt.Sprintf("满分 %s 分", strings.TrimSuffix(fmt.Sprintf("%.1f", paper.TotalScore), ".0")))
+	}
+	doc.Meta = append(doc.Meta, fmt.Sprintf("共 %d 题", len(questions)))
+
+	// 按分区组织题目，未归入分区的题目按试卷顺序放在最后
+	placed := make(map[uint]bool, len(ids))
+	number := 0
+	for _, section := range paper.Sections {
+		var list []*model.Question
+		for _, id := range section.QuestionIDs {
+			if q, ok := byID[id]; ok && !placed[id] {
+				list = append(list, q)
+				placed[id] = true
+			}
+		}
+		if len(list) > 0 {
+			doc.Sections = append(doc.Sections, export.Section{
+				Name:   section.Name,
+				Groups: buildPrintGroups(list, scores, nil, &number),
+			})
+		}
+	}
+	var rest []*model.Question
+	for _, pq := range paper.Questions {
+		if q, ok := byID[pq.QuestionID]; ok && !placed[pq.QuestionID] {
+			rest = append(rest, q)
+			placed[pq.QuestionID] = true
+		}
+	}
+	if len(rest) > 0 {
+		doc.Sections = append(doc.Sections, export.Section{Groups: buildPrintGroups(rest, scores, nil, &number)})
+	}
+	if number == 0 {
+		return nil, ErrPrintExportEmpty
+	}
+
+	return s.render(ctx, doc, userID, opts, paper.Title)
+}
+
+// ExportWrongQuestions 导出错题本，按分类分区，同一材料的错题归为一组
+func (s *PrintExportService) ExportWrongQuestions(ctx context.Context, userID uint, req *WrongQuestionExportRequest, opts PrintExportOptions) (*PrintFile, error) {
+	wrongs, err := s.studyNoteService.GetWrongQuestionsForExport(userID, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrongs) > s.maxQuestions {
+		return nil, ErrPrintExportTooMany
+	}
+
+	var materialIDs []uint
+	seen := make(map[uint]bool)
+	for _, w := range wrongs {
+		if w.Question != nil && w.Question.MaterialID != nil && !seen[*w.Question.MaterialID] {
+			seen[*w.Question.MaterialID] = true
+			materialIDs = append(materialIDs, *w.Question.MaterialID)
+		}
+	}
+	materials, err := s.materialRepo.GetByIDs(materialIDs)
+	if err != nil {
+		return nil, err
+	}
+	materialByID := make(map[uint]*model.QuestionMaterial, len(materials))
+	for i := range materials {
+		materialByID[materials[i].ID] = &materials[i]
+	}
+
+	// 分类顺序按首次出现排列，材料题按材料聚拢
+	var order []string
+	byCategory := make(map[string][]*model.Question)
+	notes := make(map[uint][]export.Note)
+	for i := range wrongs {
+		w := &wrongs[i]
+		q := w.Question
+		if q == nil {
+			continue
+		}
+		if q.MaterialID != nil {
+			q.Material = materialByID[*q.MaterialID]
+		}
+		name := "未分类"
+		if q.Category != nil && q.Category.Name != "" {
+			name = q.Category.Name
+		}
+		if _, ok := byCategory[name]; !ok {
+			order = append(order, name)
+		}
+		byCategory[name] = append(byCategory[name], q)
+
+		list := []export.Note{{Label: "错误次数", Value: fmt.Sprintf("%d 次（重做做对 %d 次），最近 %s",
+			w.WrongCount, w.CorrectCount, w.LastWrongAt.Format("2006-01-02"))}}
+		if req.IncludeNote {
+			if w.ErrorReason != "" {
+				list = append(list, export.Note{Label: "错误原因", Value: w.ErrorReason})
+			}
+			if w.UserNote != "" {
+				list = append(list, export.Note{Label: "我的笔记", Value: w.UserNote})
+			}
+		}
+		notes[q.ID] = list
+	}
+
+	doc := &export.Document{
+		Title: "错题本",
+		Meta:  []string{"导出时间 " + time.Now().Format("2006-01-02 15:04")},
+	}
+	number := 0
+	for _, name := range order {
+		doc.Sections = append(doc.Sections, export.Section{
+			Name:   name,
+			Groups: buildPrintGroups(clusterByMaterial(byCategory[name]), nil, notes, &number),
+		})
+	}
+	if number == 0 {
+		return nil, ErrPrintExportEmpty
+	}
+	doc.Meta = append([]string{fmt.Sprintf("共 %d 题", number)}, doc.Meta...)
+
+	return s.render(ctx, doc, userID, opts, "错题本_"+time.Now().Format("20060102"))
+}
+
+// render 应用通用选项并按格式渲染
+func (s *PrintExportService) render(ctx context.Context, doc *export.Document, userID uint, opts PrintExportOptions, name string) (*PrintFile, error) {
+	doc.AnswerMode = export.ParseAnswerMode(opts.AnswerMode)
+	doc.AnswerSheet = opts.AnswerSheet
+	if opts.Watermark && userID > 0 {
+		doc.Watermark = fmt.Sprintf("用户ID %d · %s", userID, time.Now().Format("2006-01-02"))
+	}
+	filename := printFilename(name)
+
+	switch strings.ToLower(opts.Format) {
+	case PrintFormatPDF, "":
+		data, err := s.pdf.Render(ctx, doc, s.font)
+		if err != nil {
+			s.logger.Error("Failed to render PDF", zap.String("title", doc.Title), zap.Error(err))
+			if errors.Is(err, export.ErrPDFUnavailable) {
+				return nil, ErrPrintExportUnavailable
+			}
+			return nil, err
+		}
+		return &PrintFile{Filename: filename + ".pdf", ContentType: "application/pdf", Data: data}, nil
+
+	case PrintFormatDOCX:
+		data, err := export.RenderDOCX(doc, s.font)
+		if err != nil {
+			return nil, err
+		}
+		return &PrintFile{
+			Filename:    filename + ".docx",
+			ContentType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+			Data:        data,
+		}, nil
+
+	case PrintFormatHTML:
+		return &PrintFile{
+			Filename:    filename + ".html",
+			ContentType: "text/html; charset=utf-8",
+			Data:        []byte(export.RenderHTML(doc, s.font)),
+		}, nil
+
+	default:
+		return nil, ErrPrintExportFormat
+	}
+}
+
+// buildPrintGroups 按顺序把共享同一材料的相邻题目归为一组，并连续编号
+func buildPrintGroups(questions []*model.Question, scores map[uint]float64, notes map[uint][]export.Note, number *int) []export.Group {
+	var groups []export.Group
+	var current *export.Group
+	var currentMaterial uint
+
+	for _, q := range questions {
+		var materialID uint
+		if q.MaterialID != nil {
+			materialID = *q.MaterialID
+		}
+		if current == nil || materialID != currentMaterial {
+			groups = append(groups, export.Group{})
+			current = &groups[len(groups)-1]
+			currentMaterial = materialID
+			if materialID != 0 && q.Material != nil {
+				current.Material = &export.Material{Title: q.Material.Title, Content: q.Material.Content}
+			}
+		}
+
+		*number++
+		item := export.Question{
+			Number:   *number,
+			Content:  q.Content,
+			Multi:    q.QuestionType == model.QuestionTypeMultiChoice,
+			Score:    scores[q.ID],
+			Answer:   q.Answer,
+			Analysis: q.Analysis,
+			Notes:    notes[q.ID],
+		}
+		for _, opt := range q.Options {
+			item.Options = append(item.Options, export.Option{Key: opt.Key, Content: opt.Content})
+		}
+		current.Questions = append(current.Questions, item)
+	}
+	return groups
+}
+
+// clusterByMaterial 把同一材料的题目移到该材料首次出现的位置，其余题目保持原顺序
+func clusterByMaterial(questions []*model.Question) []*model.Question {
+	out := make([]*model.Question, 0, len(questions))
+	byMaterial := make(map[uint][]*model.Question)
+	var order []uint
+	for _, q := range questions {
+		if q.MaterialID == nil {
+			continue
+		}
+		if _, ok := byMaterial[*q.MaterialID]; !ok {
+			order = append(order, *q.MaterialID)
+		}
+		byMaterial[*q.MaterialID] = append(byMaterial[*q.MaterialID], q)
+	}
+
+	emitted := make(map[uint]bool, len(order))
+	for _, q := range questions {
+		if q.MaterialID == nil {
+			out = append(out, q)
+			continue
+		}
+		if !emitted[*q.MaterialID] {
+			emitted[*q.MaterialID] = true
+			out = append(out, byMaterial[*q.MaterialID]...)
+		}
+	}
+	return out
+}
+
+var unsafeFilenamePattern = regexp.MustCompile(`[\\/:*?"<>|\s]+`)
+
+// printFilename 去除文件名中的非法字符
+func printFilename(name string) string {
+	name = strings.Trim(unsafeFilenamePattern.ReplaceAllString(name, "_"), "_")
+	if name == "" {
+		return "export"
+	}
+	return truncateRunes(name, 80)
+}
+
+func yearLabel(year *int) string {
+	if year == nil || *year == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d年", *year)
+}
+
+func joinWithSpace(parts ...string) string {
+	kept := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, " ")
+}
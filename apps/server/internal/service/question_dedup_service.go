@@ -0,0 +1,790 @@
+package service
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/what-cse/server/internal/config"
+	"github.com/what-cse/server/internal/model"
+	"github.com/what-cse/server/internal/repository"
+)
+
+var (
+	ErrQuestionDedupSyncing      = errors.New("题目签名任务正在运行")
+	ErrQuestionDedupInvalidMerge = errors.New("合并参数无效")
+	ErrQuestionDuplicateResolved = errors.New("该题对已处理")
+)
+
+const (
+	questionDedupScanBatch   = 500
+	questionDedupActionFlag  = "flag"
+	questionDedupMaxClusters = 200
+)
+
+// 导入时单题的查重结论
+const (
+	QuestionDedupDecisionFlagged  = "flagged"
+	QuestionDedupDecisionRejected = "rejected"
+)
+
+// =====================================================
+// MinHash / LSH
+// =====================================================
+
+// minHasher 字符 n-gram MinHash 签名，签名按 bands 段切分后哈希成 LSH 桶
+type minHasher struct {
+	shingleSize int
+	bands       int
+	rows        int
+	seeds       []uint64
+}
+
+func newMinHasher(cfg config.QuestionDedupConfig) *minHasher {
+	shingleSize := cfg.ShingleSize
+	if shingleSize <= 0 {
+		shingleSize = 3
+	}
+	bands := cfg.Bands
+	if bands <= 0 {
+		bands = 32
+	}
+	numHashes := cfg.NumHashes
+	if numHashes < bands {
+		numHashes = bands * 4
+	}
+	rows := numHashes / bands
+
+	h := &minHasher{shingleSize: shingleSize, bands: bands, rows: rows, seeds: make([]uint64, bands*rows)}
+	seed := uint64(0x9e3779b97f4a7c15)
+	for i := range h.seeds {
+		seed = splitmix64(seed)
+		h.seeds[i] = seed
+	}
+	return h
+}
+
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// questionDedupText 参与查重的文本：题干 + 选项
+func questionDedupText(content string, options []model.QuestionOption) string {
+	parts := make([]string, 0, len(options)+1)
+	parts = append(parts, plainText(content))
+	for _, opt := range options {
+		parts = append(parts, plainText(opt.Content))
+	}
+	return strings.Join(parts, " ")
+}
+
+// shingles 归一化（小写、只保留字母和数字）后的字符 n-gram 哈希集合
+func (h *minHasher) shingles(text string) []uint64 {
+	runes := make([]rune, 0, len(text))
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			runes = append(runes, r)
+		}
+	}
+	if len(runes) == 0 {
+		return nil
+	}
+
+	n := h.shingleSize
+	if len(runes) < n {
+		n = len(runes)
+	}
+	seen := make(map[uint64]bool, len(runes))
+	result := make([]uint64, 0, len(runes))
+	for i := 0; i+n <= len(runes); i++ {
+		f := fnv.New64a()
+		f.Write([]byte(string(runes[i : i+n])))
+		sum := f.Sum64()
+		if !seen[sum] {
+			seen[sum] = true
+			result = append(result, sum)
+		}
+	}
+	return result
+}
+
+// signature 计算 MinHash 签名，文本为空时返回 nil
+func (h *minHasher) signature(text string) ([]uint32, int) {
+	shingles := h.shingles(text)
+	if len(shingles) == 0 {
+		return nil, 0
+	}
+	sig := make([]uint32, len(h.seeds))
+	for i := range sig {
+		sig[i] = math.MaxUint32
+	}
+	for _, sh := range shingles {
+		for i, seed := range h.seeds {
+			if v := uint32(splitmix64(sh^seed) >> 32); v < sig[i] {
+				sig[i] = v
+			}
+		}
+	}
+	return sig, len(shingles)
+}
+
+// buckets 签名的 LSH 分段桶，最高位清零以适配 MySQL 驱动
+func (h *minHasher) buckets(sig []uint32) []uint64 {
+	buckets := make([]uint64, h.bands)
+	buf := make([]byte, 4)
+	for b := 0; b < h.bands; b++ {
+		f := fnv.New64a()
+		for _, v := range sig[b*h.rows : (b+1)*h.rows] {
+			binary.LittleEndian.PutUint32(buf, v)
+			f.Write(buf)
+		}
+		buckets[b] = f.Sum64() &^ (1 << 63)
+	}
+	return buckets
+}
+
+// minHashSimilarity 签名相同位置的比例，即 Jaccard 相似度的估计
+func minHashSimilarity(a, b []uint32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	same := 0
+	for i := range a {
+		if a[i] == b[i] {
+			same++
+		}
+	}
+	return float64(same) / float64(len(a))
+}
+
+func encodeSignature(sig []uint32) []byte {
+	b := make([]byte, 4*len(sig))
+	for i, v := range sig {
+		binary.LittleEndian.PutUint32(b[4*i:], v)
+	}
+	return b
+}
+
+func decodeSignature(b []byte) []uint32 {
+	sig := make([]uint32, len(b)/4)
+	for i := range sig {
+		sig[i] = binary.LittleEndian.Uint32(b[4*i:])
+	}
+	return sig
+}
+
+// =====================================================
+// Service
+// =====================================================
+
+// QuestionDedupService 题库近似重复检测
+// 题干 + 选项的字符 n-gram MinHash 签名与 LSH 分段桶存库，
+// 每次导入前按桶召回候选并估算相似度，高相似题拒绝或标记待审核，管理后台按簇合并
+type QuestionDedupService struct {
+	repo         *repository.QuestionDedupRepository
+	questionRepo *repository.QuestionRepository
+	cfg          config.QuestionDedupConfig
+	hasher       *minHasher
+	logger       *zap.Logger
+
+	syncMu   sync.Mutex
+	lastSync *QuestionDedupSyncResult
+
+	mu sync.Mutex
+}
+
+// NewQuestionDedupService 创建题库查重服务
+func NewQuestionDedupService(
+	repo *repository.QuestionDedupRepository,
+	questionRepo *repository.QuestionRepository,
+	cfg config.QuestionDedupConfig,
+	logger *zap.Logger,
+) *QuestionDedupService {
+	return &QuestionDedupService{
+		repo:         repo,
+		questionRepo: questionRepo,
+		cfg:          cfg,
+		hasher:       newMinHasher(cfg),
+		logger:       logger,
+	}
+}
+
+// WithTx 返回在事务 tx 中写入签名和题对的查重服务，事务回滚时一并回滚
+// s 为 nil 时返回 nil（不查重）
+func (s *QuestionDedupService) WithTx(tx *gorm.DB) *QuestionDedupService {
+	if s == nil {
+		return nil
+	}
+	return &QuestionDedupService{
+		repo:         s.repo.WithTx(tx),
+		questionRepo: s.questionRepo,
+		cfg:          s.cfg,
+		hasher:       s.hasher,
+		logger:       s.logger,
+	}
+}
+
+// ForgetQuestions 删除题目的签名、分段桶和疑似重复题对，用于草稿题目被删除时，
+// 避免后续导入被判为与不存在的题目重复
+func (s *QuestionDedupService) ForgetQuestions(questionIDs []uint) error {
+	if s == nil || len(questionIDs) == 0 {
+		return nil
+	}
+	if err := s.repo.DeleteSignatures(questionIDs); err != nil {
+		return err
+	}
+	return s.repo.DeleteDuplicates(questionIDs)
+}
+
+// QuestionDedupMatch 相似题
+type QuestionDedupMatch struct {
+	QuestionID uint                         `json:"question_id"`
+	Similarity float64                      `json:"similarity"`
+	Question   *model.QuestionBriefResponse `json:"question,omitempty"`
+}
+
+// QuestionDedupItem 导入批次中单题的查重结论
+type QuestionDedupItem struct {
+	Index         int     `json:"index"`                        // 在导入批次中的位置
+	QuestionID    uint    `json:"question_id,omitempty"`        // 创建后的题目ID
+	Decision      string  `json:"decision"`                     // flagged/rejected
+	DuplicateOfID uint    `json:"duplicate_of_id,omitempty"`    // 相似的已有题目
+	DuplicateOf   *int    `json:"duplicate_of_index,omitempty"` // 相似的同批次题目
+	Similarity    float64 `json:"similarity,omitempty"`
+}
+
+// QuestionDedupReport 导入查重报告
+type QuestionDedupReport struct {
+	Checked  int                 `json:"checked"`
+	Created  int                 `json:"created"`
+	Rejected int                 `json:"rejected"`
+	Flagged  int                 `json:"flagged"`
+	Items    []QuestionDedupItem `json:"items,omitempty"` // 仅包含被拒绝或标记的题目
+}
+
+// signedQuestion 已计算签名的题目
+type signedQuestion struct {
+	sig          []uint32
+	shingleCount int
+	buckets      []uint64
+}
+
+func (s *QuestionDedupService) sign(q *model.Question) signedQuestion {
+	sig, count := s.hasher.signature(questionDedupText(q.Content, q.Options))
+	if sig == nil {
+		return signedQuestion{}
+	}
+	return signedQuestion{sig: sig, shingleCount: count, buckets: s.hasher.buckets(sig)}
+}
+
+func bandRows(questionID uint, buckets []uint64) []model.QuestionLSHBand {
+	rows := make([]model.QuestionLSHBand, len(buckets))
+	for i, bucket := range buckets {
+		rows[i] = model.QuestionLSHBand{Band: i, Bucket: bucket, QuestionID: questionID}
+	}
+	return rows
+}
+
+// findMatches 按 LSH 桶召回候选并返回相似度不低于 minScore 的题目，按相似度降序
+func (s *QuestionDedupService) findMatches(signed signedQuestion, minScore float64, exclude uint) ([]QuestionDedupMatch, error) {
+	if signed.sig == nil {
+		return nil, nil
+	}
+	candidateIDs, err := s.repo.FindCandidates(bandRows(0, signed.buckets))
+	if err != nil {
+		return nil, err
+	}
+	if len(candidateIDs) == 0 {
+		return nil, nil
+	}
+	signatures, err := s.repo.GetSignatures(candidateIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []QuestionDedupMatch
+	for _, other := range signatures {
+		if other.QuestionID == exclude {
+			continue
+		}
+		score := minHashSimilarity(signed.sig, decodeSignature(other.Signature))
+		if score >= minScore {
+			matches = append(matches, QuestionDedupMatch{QuestionID: other.QuestionID, Similarity: score})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Similarity != matches[j].Similarity {
+			return matches[i].Similarity > matches[j].Similarity
+		}
+		return matches[i].QuestionID < matches[j].QuestionID
+	})
+	return matches, nil
+}
+
+func (s *QuestionDedupService) rejects(similarity float64) bool {
+	return s.cfg.Action != questionDedupActionFlag && similarity >= s.cfg.RejectThreshold
+}
+
+// ImportQuestions 查重后导入题目
+// 与库中已有题目或同批次前面的题目高度相似的题目被拒绝（action=flag 时只标记），
+// 达到标记阈值的题目照常创建并记录为待审核题对。create 负责实际入库并回填ID。
+// s 为 nil 时不做查重，直接创建
+func (s *QuestionDedupService) ImportQuestions(questions []model.Question, source string, create func([]model.Question) error) (*QuestionDedupReport, error) {
+	report := &QuestionDedupReport{Checked: len(questions)}
+	if len(questions) == 0 {
+		return report, nil
+	}
+	if s == nil {
+		if err := create(questions); err != nil {
+			return report, err
+		}
+		report.Created = len(questions)
+		return report, nil
+	}
+
+	type pending struct {
+		index    int
+		signed   signedQuestion
+		dupOfID  uint
+		dupOfIdx int
+		score    float64
+	}
+
+	var kept []model.Question
+	var keptInfo []pending
+	for i := range questions {
+		signed := s.sign(&questions[i])
+		info := pending{index: i, signed: signed, dupOfIdx: -1}
+
+		matches, err := s.findMatches(signed, s.cfg.FlagThreshold, 0)
+		if err != nil {
+			return report, err
+		}
+		if len(matches) > 0 {
+			info.dupOfID, info.score = matches[0].QuestionID, matches[0].Similarity
+		}
+		// 同批次内的重复
+		for k, prev := range keptInfo {
+			if score := minHashSimilarity(signed.sig, prev.signed.sig); score >= s.cfg.FlagThreshold && score > info.score {
+				info.dupOfID, info.dupOfIdx, info.score = 0, k, score
+			}
+		}
+
+		if info.score > 0 && s.rejects(info.score) {
+			item := QuestionDedupItem{Index: i, Decision: QuestionDedupDecisionRejected, DuplicateOfID: info.dupOfID, Similarity: info.score}
+			if info.dupOfIdx >= 0 {
+				idx := keptInfo[info.dupOfIdx].index
+				item.DuplicateOf = &idx
+			}
+			report.Items = append(report.Items, item)
+			report.Rejected++
+			continue
+		}
+		kept = append(kept, questions[i])
+		keptInfo = append(keptInfo, info)
+	}
+
+	if len(kept) == 0 {
+		return report, nil
+	}
+	if err := create(kept); err != nil {
+		return report, err
+	}
+	report.Created = len(kept)
+
+	var signatures []model.QuestionSignature
+	var bands []model.QuestionLSHBand
+	var pairs []model.QuestionDuplicate
+	now := time.Now()
+	for k, info := range keptInfo {
+		id := kept[k].ID
+		if info.signed.sig != nil {
+			signatures = append(signatures, model.QuestionSignature{
+				QuestionID: id, Signature: encodeSignature(info.signed.sig), ShingleCount: info.signed.shingleCount, UpdatedAt: now,
+			})
+			bands = append(bands, bandRows(id, info.signed.buckets)...)
+		}
+		if info.score == 0 {
+			continue
+		}
+
+		item := QuestionDedupItem{Index: info.index, QuestionID: id, Decision: QuestionDedupDecisionFlagged, DuplicateOfID: info.dupOfID, Similarity: info.score}
+		if info.dupOfIdx >= 0 {
+			item.DuplicateOfID = kept[info.dupOfIdx].ID
+			idx := keptInfo[info.dupOfIdx].index
+			item.DuplicateOf = &idx
+		}
+		report.Items = append(report.Items, item)
+		report.Flagged++
+		pairs = append(pairs, model.QuestionDuplicate{
+			QuestionID:    id,
+			DuplicateOfID: item.DuplicateOfID,
+			Similarity:    math.Round(info.score*10000) / 10000,
+			Source:        source,
+			Status:        model.QuestionDuplicatePending,
+		})
+	}
+
+	// 题目已入库，索引失败不影响导入，缺失的签名由定时任务补齐
+	if err := s.repo.SaveSignatures(signatures, bands); err != nil {
+		s.logger.Warn("Failed to index question signatures", zap.Error(err))
+	}
+	if err := s.repo.CreateDuplicates(pairs); err != nil {
+		s.logger.Warn("Failed to record duplicate questions", zap.Error(err))
+	}
+	sort.Slice(report.Items, func(i, j int) bool { return report.Items[i].Index < report.Items[j].Index })
+	return report, nil
+}
+
+// CheckText 预检一道题与库中题目的相似度（不入库）
+func (s *QuestionDedupService) CheckText(content string, options []model.QuestionOption, limit int) ([]QuestionDedupMatch, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	q := model.Question{Content: content, Options: options}
+	matches, err := s.findMatches(s.sign(&q), s.cfg.FlagThreshold, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return s.attachQuestions(matches)
+}
+
+func (s *QuestionDedupService) attachQuestions(matches []QuestionDedupMatch) ([]QuestionDedupMatch, error) {
+	if len(matches) == 0 {
+		return matches, nil
+	}
+	ids := make([]uint, len(matches))
+	for i, m := range matches {
+		ids[i] = m.QuestionID
+	}
+	questions, err := s.questionRepo.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*model.Question, len(questions))
+	for i := range questions {
+		byID[questions[i].ID] = &questions[i]
+	}
+	for i := range matches {
+		if q, ok := byID[matches[i].QuestionID]; ok {
+			matches[i].Question = q.ToBriefResponse()
+		}
+	}
+	return matches, nil
+}
+
+// =====================================================
+// 签名补齐
+// =====================================================
+
+// QuestionDedupSyncResult 签名补齐结果
+type QuestionDedupSyncResult struct {
+	Signed      int       `json:"signed"`  // 新签名或重新签名的题目数
+	Flagged     int       `json:"flagged"` // 发现的存量疑似重复题对
+	Purged      int       `json:"purged"`  // 清理的已删除/归档题目签名
+	FinishedAt  time.Time `json:"finished_at"`
+	DurationSec float64   `json:"duration_sec"`
+}
+
+// Sync 为缺少签名或签名过期的题目补齐签名，并把与存量题目的相似题对记为待审核
+func (s *QuestionDedupService) Sync(ctx context.Context) (*QuestionDedupSyncResult, error) {
+	if !s.syncMu.TryLock() {
+		return nil, ErrQuestionDedupSyncing
+	}
+	defer s.syncMu.Unlock()
+
+	start := time.Now()
+	result := &QuestionDedupSyncResult{}
+
+	removed, err := s.repo.GetRemovedSignatureIDs()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.DeleteSignatures(removed); err != nil {
+		return nil, err
+	}
+	result.Purged = len(removed)
+
+	var afterID uint
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		questions, err := s.repo.ScanStaleQuestions(afterID, questionDedupScanBatch)
+		if err != nil {
+			return nil, err
+		}
+		if len(questions) == 0 {
+			break
+		}
+		afterID = questions[len(questions)-1].ID
+
+		signedByID := make(map[uint]signedQuestion, len(questions))
+		var signatures []model.QuestionSignature
+		var bands []model.QuestionLSHBand
+		now := time.Now()
+		for i := range questions {
+			q := &questions[i]
+			signed := s.sign(q)
+			// 空文本也写入签名记录，避免每轮重复扫描
+			signatures = append(signatures, model.QuestionSignature{
+				QuestionID: q.ID, Signature: encodeSignature(signed.sig), ShingleCount: signed.shingleCount, UpdatedAt: now,
+			})
+			if signed.sig != nil {
+				bands = append(bands, bandRows(q.ID, signed.buckets)...)
+				signedByID[q.ID] = signed
+			}
+		}
+		// 先写入整批签名，同批次题目之间也能互相召回
+		if err := s.repo.SaveSignatures(signatures, bands); err != nil {
+			return nil, err
+		}
+		result.Signed += len(questions)
+
+		var pairs []model.QuestionDuplicate
+		for id, signed := range signedByID {
+			matches, err := s.findMatches(signed, s.cfg.FlagThreshold, id)
+			if err != nil {
+				return nil, err
+			}
+			for _, m := range matches {
+				// 题对统一为（较新题目, 较早题目），由唯一索引去重
+				newer, older := id, m.QuestionID
+				if newer < older {
+					newer, older = older, newer
+				}
+				pairs = append(pairs, model.QuestionDuplicate{
+					QuestionID:    newer,
+					DuplicateOfID: older,
+					Similarity:    math.Round(m.Similarity*10000) / 10000,
+					Source:        model.QuestionDuplicateSourceScan,
+					Status:        model.QuestionDuplicatePending,
+				})
+			}
+		}
+		if err := s.repo.CreateDuplicates(pairs); err != nil {
+			return nil, err
+		}
+		result.Flagged += len(pairs)
+	}
+
+	result.FinishedAt = time.Now()
+	result.DurationSec = time.Since(start).Seconds()
+	s.mu.Lock()
+	s.lastSync = result
+	s.mu.Unlock()
+	return result, nil
+}
+
+// RunScheduled 定时签名补齐入口，上一轮仍在执行时跳过
+func (s *QuestionDedupService) RunScheduled(ctx context.Context) error {
+	if _, err := s.Sync(ctx); err != nil && !errors.Is(err, ErrQuestionDedupSyncing) {
+		return err
+	}
+	return nil
+}
+
+// =====================================================
+// 疑似重复簇
+// =====================================================
+
+// QuestionDuplicateCluster 疑似重复簇：待审核题对连通的一组题目
+type QuestionDuplicateCluster struct {
+	Questions     []*model.QuestionBriefResponse `json:"questions"`
+	Pairs         []model.QuestionDuplicate      `json:"pairs"`
+	MaxSimilarity float64                        `json:"max_similarity"`
+	SuggestKeepID uint                           `json:"suggest_keep_id"` // 建议保留：作答人次最多，其次最早创建
+}
+
+// Clusters 把待审核题对按连通分量聚成簇，按簇大小和最高相似度排序
+func (s *QuestionDedupService) Clusters(limit int) ([]QuestionDuplicateCluster, error) {
+	if limit <= 0 || limit > questionDedupMaxClusters {
+		limit = questionDedupMaxClusters
+	}
+	pairs, err := s.repo.ListDuplicates(model.QuestionDuplicatePending, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	parent := make(map[uint]uint)
+	var find func(uint) uint
+	find = func(x uint) uint {
+		if p, ok := parent[x]; ok && p != x {
+			root := find(p)
+			parent[x] = root
+			return root
+		}
+		parent[x] = x
+		return x
+	}
+	for _, p := range pairs {
+		a, b := find(p.QuestionID), find(p.DuplicateOfID)
+		if a != b {
+			if a < b {
+				parent[b] = a
+			} else {
+				parent[a] = b
+			}
+		}
+	}
+
+	groups := make(map[uint]*QuestionDuplicateCluster)
+	members := make(map[uint][]uint)
+	for _, p := range pairs {
+		root := find(p.QuestionID)
+		cluster, ok := groups[root]
+		if !ok {
+			cluster = &QuestionDuplicateCluster{}
+			groups[root] = cluster
+		}
+		cluster.Pairs = append(cluster.Pairs, p)
+		if p.Similarity > cluster.MaxSimilarity {
+			cluster.MaxSimilarity = p.Similarity
+		}
+	}
+	for id := range parent {
+		root := find(id)
+		members[root] = append(members[root], id)
+	}
+
+	clusters := make([]*QuestionDuplicateCluster, 0, len(groups))
+	for _, cluster := range groups {
+		clusters = append(clusters, cluster)
+	}
+	roots := make(map[*QuestionDuplicateCluster]uint, len(groups))
+	for root, cluster := range groups {
+		roots[cluster] = root
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		ni, nj := len(members[roots[clusters[i]]]), len(members[roots[clusters[j]]])
+		if ni != nj {
+			return ni > nj
+		}
+		if clusters[i].MaxSimilarity != clusters[j].MaxSimilarity {
+			return clusters[i].MaxSimilarity > clusters[j].MaxSimilarity
+		}
+		return roots[clusters[i]] < roots[clusters[j]]
+	})
+	if len(clusters) > limit {
+		clusters = clusters[:limit]
+	}
+
+	var ids []uint
+	for _, cluster := range clusters {
+		ids = append(ids, members[roots[cluster]]...)
+	}
+	questions, err := s.questionRepo.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*model.Question, len(questions))
+	for i := range questions {
+		byID[questions[i].ID] = &questions[i]
+	}
+
+	result := make([]QuestionDuplicateCluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		ids := members[roots[cluster]]
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		var keep *model.Question
+		for _, id := range ids {
+			q, ok := byID[id]
+			if !ok {
+				continue
+			}
+			cluster.Questions = append(cluster.Questions, q.ToBriefResponse())
+			if keep == nil || q.AttemptCount > keep.AttemptCount {
+				keep = q
+			}
+		}
+		if keep != nil {
+			cluster.SuggestKeepID = keep.ID
+		}
+		result = append(result, *cluster)
+	}
+	return result, nil
+}
+
+// PendingPairs 获取待审核题对（按相似度降序）及已签名题目数
+func (s *QuestionDedupService) PendingPairs(limit int) ([]model.QuestionDuplicate, int64, error) {
+	signed, err := s.repo.CountSignatures()
+	if err != nil {
+		return nil, 0, err
+	}
+	pairs, err := s.repo.ListDuplicates(model.QuestionDuplicatePending, limit)
+	return pairs, signed, err
+}
+
+// Merge 把 mergeIDs 合并到 keepID，被合并题目归档
+func (s *QuestionDedupService) Merge(keepID uint, mergeIDs []uint, adminID uint) error {
+	if keepID == 0 || len(mergeIDs) == 0 {
+		return ErrQuestionDedupInvalidMerge
+	}
+	seen := make(map[uint]bool, len(mergeIDs))
+	ids := make([]uint, 0, len(mergeIDs))
+	for _, id := range mergeIDs {
+		if id == keepID || id == 0 {
+			return ErrQuestionDedupInvalidMerge
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if _, err := s.questionRepo.GetByID(keepID); err != nil {
+		return ErrQuestionNotFound
+	}
+	return s.repo.MergeQuestions(keepID, ids, adminID)
+}
+
+// Ignore 确认题对不是重复
+func (s *QuestionDedupService) Ignore(pairID uint, adminID uint) error {
+	pair, err := s.repo.GetDuplicateByID(pairID)
+	if err != nil {
+		return err
+	}
+	if pair.Status != model.QuestionDuplicatePending {
+		return ErrQuestionDuplicateResolved
+	}
+	return s.repo.ResolveDuplicate(pairID, model.QuestionDuplicateIgnored, adminID)
+}
+
+// QuestionDedupStats 查重统计
+type QuestionDedupStats struct {
+	Signed   int64                      `json:"signed"`
+	Pairs    map[string]int64           `json:"pairs"`
+	Config   config.QuestionDedupConfig `json:"config"`
+	LastSync *QuestionDedupSyncResult   `json:"last_sync,omitempty"`
+}
+
+// Stats 获取查重统计
+func (s *QuestionDedupService) Stats() (*QuestionDedupStats, error) {
+	signed, err := s.repo.CountSignatures()
+	if err != nil {
+		return nil, err
+	}
+	pairs, err := s.repo.CountDuplicatesByStatus()
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	lastSync := s.lastSync
+	s.mu.Unlock()
+	return &QuestionDedupStats{Signed: signed, Pairs: pairs, Config: s.cfg, LastSync: lastSync}, nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"github.com/what-cse/server/internal/config"
+	"github.com/what-cse/server/internal/model"
+)
+
+// 默认配置：3-gram，128 个哈希分 32 段
+func testMinHasher() *minHasher {
+	return newMinHasher(config.QuestionDedupConfig{ShingleSize: 3, NumHashes: 128, Bands: 32})
+}
+
+func exactJaccard(h *minHasher, a, b string) float64 {
+	set := make(map[uint64]int)
+	for _, s := range h.shingles(a) {
+		set[s] |= 1
+	}
+	for _, s := range h.shingles(b) {
+		set[s] |= 2
+	}
+	both := 0
+	for _, v := range set {
+		if v == 3 {
+			both++
+		}
+	}
+	return float64(both) / float64(len(set))
+}
+
+func sharedBands(h *minHasher, a, b []uint32) int {
+	ba, bb := h.buckets(a), h.buckets(b)
+	n := 0
+	for i := range ba {
+		if ba[i] == bb[i] {
+			n++
+		}
+	}
+	return n
+}
+
+func TestMinHashNearDuplicates(t *testing.T) {
+	h := testMinHasher()
+	const flagThreshold = 0.6
+
+	base := questionDedupText("某市2023年第一季度全市生产总值为1250亿元，同比增长6.2%，其中第三产业增加值占比约为多少？",
+		[]model.QuestionOption{{Content: "45.3%"}, {Content: "52.8%"}, {Content: "58.1%"}, {Content: "63.4%"}})
+	tests := []struct {
+		name      string
+		text      string
+		duplicate bool
+	}{
+		{"标点与空格不同", questionDedupText("某市 2023 年第一季度，全市生产总值为 1250 亿元；同比增长 6.2%，其中第三产业增加值占比约为多少?",
+			[]model.QuestionOption{{Content: "45.3%"}, {Content: "52.8%"}, {Content: "58.1%"}, {Content: "63.4%"}}), true},
+		{"改动个别字词", questionDedupText("某市2023年第一季度全市地区生产总值为1250亿元，同比增长6.2%，其中第三产业增加值所占比重约为多少？",
+			[]model.QuestionOption{{Content: "45.3%"}, {Content: "52.8%"}, {Content: "58.1%"}, {Content: "63.4%"}}), true},
+		{"同题型不同题目", questionDedupText("某省2022年全年粮食总产量为680万吨，比上年下降1.5%，其中小麦产量约占多少？",
+			[]model.QuestionOption{{Content: "21.6%"}, {Content: "34.2%"}, {Content: "40.7%"}, {Content: "47.9%"}}), false},
+		{"无关题目", questionDedupText("下列关于我国古代科技成就的表述，正确的是：",
+			[]model.QuestionOption{{Content: "《齐民要术》是现存最早的农书"}, {Content: "祖冲之编订了《大明历》"}}), false},
+	}
+
+	baseSig, _ := h.signature(base)
+	for _, tt := range tests {
+		sig, _ := h.signature(tt.text)
+		est, exact := minHashSimilarity(baseSig, sig), exactJaccard(h, base, tt.text)
+		// 128 个哈希的估计标准差约 0.044
+		if math.Abs(est-exact) > 0.15 {
+			t.Errorf("%s: estimate %.3f, exact Jaccard %.3f", tt.name, est, exact)
+		}
+		if got := est >= flagThreshold; got != tt.duplicate {
+			t.Errorf("%s: similarity %.3f, duplicate = %v, want %v", tt.name, est, got, tt.duplicate)
+		}
+		// 近似重复必须在至少一个 LSH 段上碰撞才能被召回
+		if bands := sharedBands(h, baseSig, sig); tt.duplicate && bands == 0 {
+			t.Errorf("%s: no shared LSH band, candidate would be missed", tt.name)
+		} else if !tt.duplicate && bands > 2 {
+			t.Errorf("%s: %d shared LSH bands for a non-duplicate", tt.name, bands)
+		}
+	}
+
+	// 只有标点、大小写和空白不同的文本签名完全一致
+	a, _ := h.signature("Which of the following is TRUE?")
+	b, _ := h.signature("which of the following, is true")
+	if minHashSimilarity(a, b) != 1 || sharedBands(h, a, b) != 32 {
+		t.Errorf("normalized text differs: similarity %.3f", minHashSimilarity(a, b))
+	}
+}
+
+// LSH 召回概率 1-(1-s^r)^b：32 段 × 4 行时相似度 0.8 的题对几乎必被召回，0.2 的很少
+func TestLSHCandidateRate(t *testing.T) {
+	h := testMinHasher()
+	if h.bands != 32 || h.rows != 4 {
+		t.Fatalf("bands %d rows %d", h.bands, h.rows)
+	}
+	for _, tt := range []struct {
+		s        float64
+		min, max float64
+	}{
+		{0.2, 0, 0.06},
+		{0.6, 0.98, 1},
+		{0.8, 0.999, 1},
+	} {
+		p := 1 - math.Pow(1-math.Pow(tt.s, float64(h.rows)), float64(h.bands))
+		if p < tt.min || p > tt.max {
+			t.Errorf("s=%.1f: candidate probability %.4f outside [%v, %v]", tt.s, p, tt.min, tt.max)
+		}
+	}
+}
+
+func TestMinHashSignatureEdgeCases(t *testing.T) {
+	h := testMinHasher()
+	if sig, n := h.signature("，。！？ "); sig != nil || n != 0 {
+		t.Errorf("punctuation only: got %d shingles", n)
+	}
+	// 短于 n-gram 的文本整体作为一个 shingle
+	if _, n := h.signature("A1"); n != 1 {
+		t.Errorf("short text: got %d shingles, want 1", n)
+	}
+
+	sig, _ := h.signature("行政职业能力测验")
+	decoded := decodeSignature(encodeSignature(sig))
+	if minHashSimilarity(sig, decoded) != 1 {
+		t.Error("signature changed after encode/decode")
+	}
+	if minHashSimilarity(sig, sig[:64]) != 0 {
+		t.Error("signatures of different length compared")
+	}
+}
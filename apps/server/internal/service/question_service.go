@@ -33,6 +33,9 @@ type QuestionService struct {
 	recordRepo      *repository.UserQuestionRecordRepository
 	paperRecordRepo *repository.UserPaperRecordRepository
 	collectRepo     *repository.UserQuestionCollectRepository
+	dedupService    *QuestionDedupService
+	irtService      *IRTService
+	masteryService  *KnowledgeMasteryService
 }
 
 func NewQuestionService(
@@ -124,6 +127,14 @@ func (s *QuestionService) SubmitAnswer(userID, questionID uint, userAnswer strin
 	// Update question stats
 	_ = s.questionRepo.UpdateStats(questionID, isCorrect, timeSpent)
 
+	// Update ability estimate
+	if s.irtService != nil {
+		s.irtService.RecordAnswer(userID, question, isCorrect)
+	}
+	if s.masteryService != nil {
+		s.masteryService.RecordAnswer(userID, question, isCorrect)
+	}
+
 	// Return response with answer and analysis
 	resp := question.ToDetailResponse()
 	resp.UserAnswer = userAnswer
@@ -354,6 +365,12 @@ func (s *QuestionService) SubmitPaper(userID, paperID uint, answers []model.Pape
 
 		// Update question stats
 		_ = s.questionRepo.UpdateStats(a.QuestionID, isCorrect, a.TimeSpent)
+		if s.irtService != nil {
+			s.irtService.RecordAnswer(userID, q, isCorrect)
+		}
+		if s.masteryService != nil {
+			s.masteryService.RecordAnswer(userID, q, isCorrect)
+		}
 
 		// Save individual question record
 		qRecord := &model.UserQuestionRecord{
@@ -534,11 +551,31 @@ func (s *QuestionService) DeleteQuestion(id uint) error {
 	return s.questionRepo.Delete(id)
 }
 
+// SetDedupService sets the near-duplicate checker used by batch imports
+func (s *QuestionService) SetDedupService(dedupService *QuestionDedupService) {
+	s.dedupService = dedupService
+}
+
+// SetIRTService sets the ability tracker updated on every answer
+func (s *QuestionService) SetIRTService(irtService *IRTService) {
+	s.irtService = irtService
+}
+
+// SetKnowledgeMasteryService sets the knowledge tracer updated on every answer
+func (s *QuestionService) SetKnowledgeMasteryService(masteryService *KnowledgeMasteryService) {
+	s.masteryService = masteryService
+}
+
 // BatchCreateQuestions creates multiple questions (admin)
 func (s *QuestionService) BatchCreateQuestions(questions []model.Question) error {
 	return s.questionRepo.BatchCreate(questions)
 }
 
+// ImportQuestions creates multiple questions after near-duplicate screening (admin)
+func (s *QuestionService) ImportQuestions(questions []model.Question) (*QuestionDedupReport, error) {
+	return s.dedupService.ImportQuestions(questions, model.QuestionDuplicateSourceAdmin, s.questionRepo.BatchCreate)
+}
+
 // CreateMaterial creates a new material (admin)
 func (s *QuestionService) CreateMaterial(material *model.QuestionMaterial) error {
 	return s.materialRepo.Create(material)
@@ -0,0 +1,599 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/what-cse/server/internal/model"
+	"github.com/what-cse/server/internal/repository"
+)
+
+var (
+	ErrForecastNoWindow = errors.New("position has no registration window")
+)
+
+const (
+	// 至少需要这么多条往年曲线才认为单位级参考可用，否则放宽到同考试类型同省份
+	forecastMinDepartmentCurves = 3
+	// 单次查询的往年参考职位上限
+	forecastReferenceLimit = 200
+	// 完成比例下限，避免早期极小比例导致预测爆炸
+	forecastMinFraction = 0.02
+	// 默认冷门竞争比阈值
+	forecastColdRatio = 10.0
+	// 单个职位预测的缓存有效期，职位详情页反复打开时不重复计算
+	forecastCacheTTL = 10 * time.Minute
+	// 缓存条目超过该数量时清理过期条目
+	forecastCacheSweepSize = 5000
+)
+
+// defaultCompletionCurve 默认报名完成曲线（报名窗口进度 -> 已报人数占最终人数比例）
+// 公考报名普遍"前松后紧"，最后两天集中涌入
+var defaultCompletionCurve = []curvePoint{
+	{0.0, 0.0},
+	{0.1, 0.08},
+	{0.2, 0.16},
+	{0.3, 0.24},
+	{0.4, 0.32},
+	{0.5, 0.40},
+	{0.6, 0.49},
+	{0.7, 0.59},
+	{0.8, 0.70},
+	{0.9, 0.84},
+	{1.0, 1.0},
+}
+
+// curvePoint 报名曲线上的一个点
+type curvePoint struct {
+	Progress float64 // 报名窗口进度 (0-1)
+	Fraction float64 // 已报人数 / 最终人数
+}
+
+// cachedForecast 缓存的单个职位预测结果，无报名窗口的结论同样缓存
+type cachedForecast struct {
+	forecast *model.CompetitionForecast
+	err      error
+	cachedAt time.Time
+}
+
+// RegistrationForecastService 报名竞争比预测服务
+// 基于报名期内的快照曲线、同单位往年报名曲线和剩余天数，预测职位最终报名人数和竞争比
+type RegistrationForecastService struct {
+	regDataRepo  *repository.RegistrationDataRepository
+	positionRepo *repository.PositionRepository
+	historyRepo  *repository.PositionHistoryRepository
+
+	mu    sync.Mutex
+	cache map[uint]cachedForecast
+}
+
+// NewRegistrationForecastService 创建报名预测服务
+func NewRegistrationForecastService(
+	regDataRepo *repository.RegistrationDataRepository,
+	positionRepo *repository.PositionRepository,
+	historyRepo *repository.PositionHistoryRepository,
+) *RegistrationForecastService {
+	return &RegistrationForecastService{
+		regDataRepo:  regDataRepo,
+		positionRepo: positionRepo,
+		historyRepo:  historyRepo,
+		cache:        make(map[uint]cachedForecast),
+	}
+}
+
+// LikelyColdPositionsResponse 预测冷门职位响应
+type LikelyColdPositionsResponse struct {
+	Positions []*model.CompetitionForecast `json:"positions"`
+	Total     int                          `json:"total"`
+	Page      int                          `json:"page"`
+	PageSize  int                          `json:"page_size"`
+	MaxRatio  float64                      `json:"max_ratio"`
+}
+
+// =====================================================
+// 公开方法
+// =====================================================
+
+// ForecastByID 根据职位主键预测，结果缓存 forecastCacheTTL
+func (s *RegistrationForecastService) ForecastByID(id uint) (*model.CompetitionForecast, error) {
+	s.mu.Lock()
+	cached, ok := s.cache[id]
+	s.mu.Unlock()
+	if ok && time.Since(cached.cachedAt) < forecastCacheTTL {
+		return cached.forecast, cached.err
+	}
+
+	position, err := s.positionRepo.FindByID(id)
+	if err != nil {
+		return nil, ErrPositionNotFound
+	}
+	forecast, err := s.ForecastPosition(position)
+	if err != nil && !errors.Is(err, ErrForecastNoWindow) {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if len(s.cache) >= forecastCacheSweepSize {
+		for key, c := range s.cache {
+			if time.Since(c.cachedAt) >= forecastCacheTTL {
+				delete(s.cache, key)
+			}
+		}
+	}
+	s.cache[id] = cachedForecast{forecast: forecast, err: err, cachedAt: time.Now()}
+	s.mu.Unlock()
+	return forecast, err
+}
+
+// ForecastByPositionID 根据职位业务标识预测
+func (s *RegistrationForecastService) ForecastByPositionID(positionID string) (*model.CompetitionForecast, error) {
+	position, err := s.positionRepo.FindByPositionID(positionID)
+	if err != nil {
+		return nil, ErrPositionNotFound
+	}
+	return s.ForecastPosition(position)
+}
+
+// ForecastPosition 预测单个职位的最终报名人数和竞争比
+func (s *RegistrationForecastService) ForecastPosition(position *model.Position) (*model.CompetitionForecast, error) {
+	if position.RegistrationStart == nil || position.RegistrationEnd == nil {
+		return nil, ErrForecastNoWindow
+	}
+
+	snapshots, err := s.regDataRepo.GetByPositionIDs([]string{position.PositionID})
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := s.loadReferenceCurves(position)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.forecast(position, snapshots, refs, time.Now()), nil
+}
+
+// ForecastPositions 批量预测（同单位共用参考曲线）
+func (s *RegistrationForecastService) ForecastPositions(positions []model.Position) ([]*model.CompetitionForecast, error) {
+	ids := make([]string, 0, len(positions))
+	for _, p := range positions {
+		ids = append(ids, p.PositionID)
+	}
+
+	allSnapshots, err := s.regDataRepo.GetByPositionIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	snapshotsByPosition := make(map[string][]model.PositionRegistrationData)
+	for _, snap := range allSnapshots {
+		snapshotsByPosition[snap.PositionID] = append(snapshotsByPosition[snap.PositionID], snap)
+	}
+
+	refCache := make(map[string][][]curvePoint)
+	now := time.Now()
+	results := make([]*model.CompetitionForecast, 0, len(positions))
+
+	for i := range positions {
+		pos := &positions[i]
+		if pos.RegistrationStart == nil || pos.RegistrationEnd == nil {
+			continue
+		}
+
+		cacheKey := pos.DepartmentCode + "|" + pos.ExamType + "|" + pos.Province
+		refs, ok := refCache[cacheKey]
+		if !ok {
+			refs, err = s.loadReferenceCurves(pos)
+			if err != nil {
+				return nil, err
+			}
+			refCache[cacheKey] = refs
+		}
+
+		results = append(results, s.forecast(pos, snapshotsByPosition[pos.PositionID], refs, now))
+	}
+
+	return results, nil
+}
+
+// GetLikelyColdPositions 获取预测最终竞争比较低的报名中职位
+func (s *RegistrationForecastService) GetLikelyColdPositions(maxRatio float64, page, pageSize int) (*LikelyColdPositionsResponse, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+	if maxRatio <= 0 {
+		maxRatio = forecastColdRatio
+	}
+
+	positions, err := s.positionRepo.GetRegisteringPositions()
+	if err != nil {
+		return nil, err
+	}
+
+	forecasts, err := s.ForecastPositions(positions)
+	if err != nil {
+		return nil, err
+	}
+
+	cold := make([]*model.CompetitionForecast, 0)
+	for _, f := range forecasts {
+		// 用区间上限判断，避免把"目前冷但最后会爆"的职位推给用户
+		if f.RecruitCount > 0 && f.ForecastRatioHigh <= maxRatio {
+			cold = append(cold, f)
+		}
+	}
+
+	sort.Slice(cold, func(i, j int) bool {
+		if cold[i].ForecastRatio != cold[j].ForecastRatio {
+			return cold[i].ForecastRatio < cold[j].ForecastRatio
+		}
+		return cold[i].RecruitCount > cold[j].RecruitCount
+	})
+
+	total := len(cold)
+	start := (page - 1) * pageSize
+	if start > total {
+		start = total
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+
+	return &LikelyColdPositionsResponse{
+		Positions: cold[start:end],
+		Total:     total,
+		Page:      page,
+		PageSize:  pageSize,
+		MaxRatio:  maxRatio,
+	}, nil
+}
+
+// =====================================================
+// 预测核心
+// =====================================================
+
+// forecast 根据快照和参考曲线计算预测结果
+func (s *RegistrationForecastService) forecast(position *model.Position, snapshots []model.PositionRegistrationData, refs [][]curvePoint, now time.Time) *model.CompetitionForecast {
+	usePass := position.PassCount > 0
+	for _, snap := range snapshots {
+		if snap.PassCount > 0 {
+			usePass = true
+			break
+		}
+	}
+
+	current := countOf(position.ApplicantCount, position.PassCount, usePass)
+	if len(snapshots) > 0 {
+		last := snapshots[len(snapshots)-1]
+		if c := countOf(last.ApplyCount, last.PassCount, usePass); c > current {
+			current = c
+		}
+	}
+
+	start, end := *position.RegistrationStart, *position.RegistrationEnd
+	progress := windowProgress(start, end, now)
+	daysRemaining := 0
+	if now.Before(end) {
+		daysRemaining = int(math.Ceil(end.Sub(now).Hours() / 24))
+	}
+
+	result := &model.CompetitionForecast{
+		PositionID:      position.PositionID,
+		PositionName:    position.PositionName,
+		DepartmentName:  position.DepartmentName,
+		RecruitCount:    position.RecruitCount,
+		CountBasis:      "apply",
+		CurrentCount:    current,
+		Progress:        math.Round(progress*100) / 100,
+		DaysRemaining:   daysRemaining,
+		ReferenceCurves: len(refs),
+	}
+	if usePass {
+		result.CountBasis = "pass"
+	}
+
+	// 报名已结束，当前人数即最终人数
+	if progress >= 1 {
+		result.ForecastCount = current
+		result.ForecastCountLow = current
+		result.ForecastCountHigh = current
+		result.ConfidenceLevel = 1
+		result.Basis = "报名已截止，使用最终报名数据"
+		fillRatios(result)
+		return result
+	}
+
+	// 各参考曲线在当前进度上的完成比例
+	var fractions []float64
+	for _, curve := range refs {
+		fractions = append(fractions, interpolateCurve(curve, progress))
+	}
+	if len(fractions) == 0 {
+		fractions = []float64{interpolateCurve(defaultCompletionCurve, progress)}
+	}
+	sort.Float64s(fractions)
+
+	mid := math.Max(quantile(fractions, 0.5), forecastMinFraction)
+	lo := math.Max(quantile(fractions, 0.1), forecastMinFraction)
+	hi := math.Max(quantile(fractions, 0.9), forecastMinFraction)
+
+	curveEst := float64(current) / mid
+	curveHigh := float64(current) / lo
+	curveLow := float64(current) / hi
+
+	// 默认曲线没有离散度，按剩余进度给一个经验区间
+	if len(refs) == 0 {
+		spread := 0.5 * (1 - progress)
+		curveLow = curveEst * (1 - spread)
+		curveHigh = curveEst * (1 + spread)
+	}
+
+	// 往年同职位/同单位最终人数作为先验，报名早期权重更高
+	prior := s.priorFinalCount(position, usePass)
+	est, low, high := curveEst, curveLow, curveHigh
+	if prior > 0 {
+		w := math.Min(math.Max(mid, 0.1), 0.95)
+		est = w*curveEst + (1-w)*prior
+		low = w*curveLow + (1-w)*math.Min(prior, curveLow)
+		high = w*curveHigh + (1-w)*math.Max(prior, curveHigh)
+	}
+
+	// 最终人数不会少于当前人数
+	floor := float64(current)
+	est = math.Max(est, floor)
+	low = math.Max(low, floor)
+	high = math.Max(high, est)
+
+	result.ForecastCount = int(math.Round(est))
+	result.ForecastCountLow = int(math.Round(low))
+	result.ForecastCountHigh = int(math.Round(high))
+	result.ConfidenceLevel = forecastConfidence(progress, len(refs), prior > 0)
+	result.Basis = forecastBasis(len(refs), prior > 0, len(snapshots))
+	fillRatios(result)
+
+	return result
+}
+
+// loadReferenceCurves 加载往年同单位（不足时放宽到同考试类型同省份）职位的报名曲线
+func (s *RegistrationForecastService) loadReferenceCurves(position *model.Position) ([][]curvePoint, error) {
+	before := *position.RegistrationStart
+
+	var refs [][]curvePoint
+	if position.DepartmentCode != "" {
+		positions, err := s.positionRepo.GetPastWindowPositions(position.DepartmentCode, "", "", before, forecastReferenceLimit)
+		if err != nil {
+			return nil, err
+		}
+		refs, err = s.buildCurves(positions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(refs) < forecastMinDepartmentCurves {
+		positions, err := s.positionRepo.GetPastWindowPositions("", position.ExamType, position.Province, before, forecastReferenceLimit)
+		if err != nil {
+			return nil, err
+		}
+		more, err := s.buildCurves(positions)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, more...)
+	}
+
+	return refs, nil
+}
+
+// buildCurves 把往年职位的快照转换为归一化的完成曲线
+func (s *RegistrationForecastService) buildCurves(positions []model.Position) ([][]curvePoint, error) {
+	if len(positions) == 0 {
+		return nil, nil
+	}
+
+	byID := make(map[string]*model.Position, len(positions))
+	ids := make([]string, 0, len(positions))
+	for i := range positions {
+		byID[positions[i].PositionID] = &positions[i]
+		ids = append(ids, positions[i].PositionID)
+	}
+
+	snapshots, err := s.regDataRepo.GetByPositionIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	grouped := make(map[string][]model.PositionRegistrationData)
+	for _, snap := range snapshots {
+		grouped[snap.PositionID] = append(grouped[snap.PositionID], snap)
+	}
+
+	var curves [][]curvePoint
+	for id, snaps := range grouped {
+		pos := byID[id]
+		if pos == nil || len(snaps) < 2 {
+			continue
+		}
+
+		usePass := false
+		for _, snap := range snaps {
+			if snap.PassCount > 0 {
+				usePass = true
+				break
+			}
+		}
+
+		final := countOf(snaps[len(snaps)-1].ApplyCount, snaps[len(snaps)-1].PassCount, usePass)
+		if f := countOf(pos.ApplicantCount, pos.PassCount, usePass); f > final {
+			final = f
+		}
+		if final <= 0 {
+			continue
+		}
+
+		curve := []curvePoint{{0, 0}}
+		for _, snap := range snaps {
+			at := snapshotTime(snap)
+			p := windowProgress(*pos.RegistrationStart, *pos.RegistrationEnd, at)
+			frac := math.Min(float64(countOf(snap.ApplyCount, snap.PassCount, usePass))/float64(final), 1)
+			curve = append(curve, curvePoint{p, frac})
+		}
+		curve = append(curve, curvePoint{1, 1})
+		curves = append(curves, curve)
+	}
+
+	return curves, nil
+}
+
+// priorFinalCount 往年最终人数先验：优先同职位代码最近一年，其次同单位平均每个招录名额的人数
+func (s *RegistrationForecastService) priorFinalCount(position *model.Position, usePass bool) float64 {
+	if s.historyRepo == nil {
+		return 0
+	}
+
+	if position.PositionCode != "" {
+		histories, err := s.historyRepo.GetByPositionCode(position.PositionCode)
+		if err == nil {
+			for _, h := range histories {
+				if c := countOf(h.ApplyCount, h.PassCount, usePass); c > 0 {
+					// 按招录人数变化缩放
+					if h.RecruitCount > 0 && position.RecruitCount > 0 {
+						return float64(c) * float64(position.RecruitCount) / float64(h.RecruitCount)
+					}
+					return float64(c)
+				}
+			}
+		}
+	}
+
+	if position.DepartmentCode != "" && position.RecruitCount > 0 {
+		histories, err := s.historyRepo.GetByDepartmentCode(position.DepartmentCode)
+		if err == nil {
+			var perSeat []float64
+			for _, h := range histories {
+				c := countOf(h.ApplyCount, h.PassCount, usePass)
+				if c > 0 && h.RecruitCount > 0 {
+					perSeat = append(perSeat, float64(c)/float64(h.RecruitCount))
+				}
+			}
+			if len(perSeat) > 0 {
+				sort.Float64s(perSeat)
+				return quantile(perSeat, 0.5) * float64(position.RecruitCount)
+			}
+		}
+	}
+
+	return 0
+}
+
+// =====================================================
+// 辅助函数
+// =====================================================
+
+// countOf 按计数口径取人数
+func countOf(apply, pass int, usePass bool) int {
+	if usePass {
+		return pass
+	}
+	return apply
+}
+
+// snapshotTime 合并快照日期和时间
+func snapshotTime(snap model.PositionRegistrationData) time.Time {
+	if t, err := time.ParseInLocation("15:04:05", snap.SnapshotTime, snap.SnapshotDate.Location()); err == nil {
+		return snap.SnapshotDate.Add(time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second)
+	}
+	// 没有时间时视为当日结束
+	return snap.SnapshotDate.Add(24*time.Hour - time.Second)
+}
+
+// windowProgress 计算某时刻在报名窗口中的进度
+func windowProgress(start, end, at time.Time) float64 {
+	total := end.Sub(start).Seconds()
+	if total <= 0 {
+		return 1
+	}
+	p := at.Sub(start).Seconds() / total
+	return math.Min(math.Max(p, 0), 1)
+}
+
+// interpolateCurve 在曲线上线性插值（曲线点按进度升序）
+func interpolateCurve(curve []curvePoint, progress float64) float64 {
+	if len(curve) == 0 {
+		return progress
+	}
+	if progress <= curve[0].Progress {
+		return curve[0].Fraction
+	}
+	for i := 1; i < len(curve); i++ {
+		if progress <= curve[i].Progress {
+			prev, next := curve[i-1], curve[i]
+			if next.Progress == prev.Progress {
+				return next.Fraction
+			}
+			t := (progress - prev.Progress) / (next.Progress - prev.Progress)
+			return prev.Fraction + t*(next.Fraction-prev.Fraction)
+		}
+	}
+	return curve[len(curve)-1].Fraction
+}
+
+// quantile 计算已排序切片的分位数
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := q * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	if lower == upper {
+		return sorted[lower]
+	}
+	return sorted[lower] + (pos-float64(lower))*(sorted[upper]-sorted[lower])
+}
+
+// fillRatios 根据预测人数计算竞争比
+func fillRatios(f *model.CompetitionForecast) {
+	if f.RecruitCount <= 0 {
+		return
+	}
+	recruit := float64(f.RecruitCount)
+	f.CurrentRatio = math.Round(float64(f.CurrentCount)/recruit*100) / 100
+	f.ForecastRatio = math.Round(float64(f.ForecastCount)/recruit*100) / 100
+	f.ForecastRatioLow = math.Round(float64(f.ForecastCountLow)/recruit*100) / 100
+	f.ForecastRatioHigh = math.Round(float64(f.ForecastCountHigh)/recruit*100) / 100
+}
+
+// forecastConfidence 置信度随报名进度和参考数据量增加
+func forecastConfidence(progress float64, refCount int, hasPrior bool) float64 {
+	confidence := 0.3 + 0.5*progress
+	if refCount >= forecastMinDepartmentCurves {
+		confidence += 0.1
+	}
+	if hasPrior {
+		confidence += 0.05
+	}
+	return math.Round(math.Min(confidence, 0.95)*100) / 100
+}
+
+// forecastBasis 生成预测依据说明
+func forecastBasis(refCount int, hasPrior bool, snapshotCount int) string {
+	basis := "基于默认报名曲线推算"
+	if refCount > 0 {
+		basis = fmt.Sprintf("基于%d条往年同类职位报名曲线推算", refCount)
+	}
+	if hasPrior {
+		basis += "，结合往年最终报名人数"
+	}
+	if snapshotCount == 0 {
+		basis += "，暂无本职位报名快照"
+	}
+	return basis
+}
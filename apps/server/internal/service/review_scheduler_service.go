@@ -0,0 +1,607 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/what-cse/server/internal/config"
+	"github.com/what-cse/server/internal/model"
+	"github.com/what-cse/server/internal/repository"
+	"github.com/what-cse/server/internal/srs"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidReviewRating     = errors.New("无效的复习评分")
+	ErrInvalidReviewAlgorithm  = errors.New("不支持的复习算法")
+	ErrInvalidRequestRetention = errors.New("目标记忆保持率需在 0.7-0.97 之间")
+	ErrNotEnoughReviews        = errors.New("复习记录不足，暂无法优化个人参数")
+	ErrReviewOptimizing        = errors.New("复习参数优化正在进行中")
+)
+
+const (
+	// maxOptimizeLogs 单个用户参与优化的最多复习日志数
+	maxOptimizeLogs = 100000
+	// wrongQuestionRelearnDelay 错题答错后至少隔天再复习，避免刚看完解析就重做
+	wrongQuestionRelearnDelay = 24 * time.Hour
+)
+
+// ReviewSchedulerService 统一的间隔重复调度服务
+// 速记卡片与错题共用同一套 FSRS/SM-2 调度、复习日志、每日队列与个人参数优化
+type ReviewSchedulerService struct {
+	repo   *repository.ReviewRepository
+	cfg    config.ReviewConfig
+	logger *zap.Logger
+	syncMu sync.Mutex
+}
+
+// NewReviewSchedulerService 创建复习调度服务
+func NewReviewSchedulerService(repo *repository.ReviewRepository, cfg config.ReviewConfig, logger *zap.Logger) *ReviewSchedulerService {
+	return &ReviewSchedulerService{
+		repo:   repo,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// UpdateReviewSettingsRequest 更新复习设置请求
+type UpdateReviewSettingsRequest struct {
+	Algorithm        string  `json:"algorithm"`         // fsrs / sm2，空表示使用系统默认
+	RequestRetention float64 `json:"request_retention"` // 0 表示使用系统默认
+}
+
+// =====================================================
+// 调度
+// =====================================================
+
+// Schedule 按用户的算法与参数计算复习后的状态，在模糊区间内做负载均衡并写入复习日志
+// 未注入调度服务时退回默认 SM-2 且不记录日志
+func (s *ReviewSchedulerService) Schedule(userID uint, itemType model.ReviewItemType, itemID uint, card srs.Card, rating srs.Rating, now time.Time) (srs.Card, *model.ReviewResult, error) {
+	if !rating.Valid() {
+		return card, nil, ErrInvalidReviewRating
+	}
+	if card.State == "" {
+		card.State = srs.StateNew
+	}
+	if s == nil {
+		next := srs.SM2{}.Next(card, rating, now)
+		return next, reviewResult(itemType, itemID, rating, next, srs.AlgorithmSM2), nil
+	}
+
+	params, err := s.repo.GetParams(userID)
+	if err != nil {
+		return card, nil, err
+	}
+	scheduler := s.scheduler(params, itemType)
+	next := scheduler.Next(card, rating, now)
+	if s.cfg.LoadBalance && next.Interval >= 3 {
+		next = s.balance(userID, next, now)
+	}
+
+	log := &model.ReviewLog{
+		UserID:        userID,
+		ItemType:      itemType,
+		ItemID:        itemID,
+		Rating:        int(rating),
+		State:         string(card.State),
+		ElapsedDays:   round3(card.ElapsedDays(now)),
+		ScheduledDays: next.Interval,
+		Stability:     round4(next.Stability),
+		Difficulty:    round4(next.Difficulty),
+		Algorithm:     scheduler.Name(),
+		ReviewedAt:    now,
+	}
+	if err := s.repo.CreateLog(log); err != nil {
+		s.logger.Warn("Failed to write review log", zap.Uint("user_id", userID), zap.Error(err))
+	}
+	return next, reviewResult(itemType, itemID, rating, next, scheduler.Name()), nil
+}
+
+// scheduler 按用户设置（未设置时按系统默认）创建调度器
+func (s *ReviewSchedulerService) scheduler(params *model.SRSUserParams, itemType model.ReviewItemType) srs.Scheduler {
+	algorithm, retention := s.cfg.Algorithm, s.cfg.RequestRetention
+	var weights []float64
+	if params != nil {
+		if params.Algorithm != "" {
+			algorithm = params.Algorithm
+		}
+		if params.RequestRetention > 0 {
+			retention = params.RequestRetention
+		}
+		weights = params.Weights
+	}
+	if algorithm == srs.AlgorithmSM2 {
+		return srs.SM2{MaximumInterval: s.cfg.MaximumInterval}
+	}
+	f := srs.NewFSRS(weights, retention, s.cfg.MaximumInterval)
+	if itemType == model.ReviewItemWrongQuestion {
+		f.LearningStep = wrongQuestionRelearnDelay
+	}
+	return f
+}
+
+// balance 在间隔的模糊区间内选择已到期条目最少的一天
+func (s *ReviewSchedulerService) balance(userID uint, card srs.Card, now time.Time) srs.Card {
+	lo, hi := srs.FuzzRange(card.Interval, s.cfg.MaximumInterval)
+	today := startOfDay(now)
+	load, err := s.repo.DueLoad(userID, today.AddDate(0, 0, lo), today.AddDate(0, 0, hi+1))
+	if err != nil {
+		s.logger.Warn("Failed to load review due counts", zap.Uint("user_id", userID), zap.Error(err))
+		return card
+	}
+	card.Interval = srs.Balance(card.Interval, s.cfg.MaximumInterval, func(day int) int {
+		return load[today.AddDate(0, 0, day).Format("2006-01-02")]
+	})
+	card.Due = now.AddDate(0, 0, card.Interval)
+	return card
+}
+
+// ImportedReview 从外部（如 Anki）导入的一次复习
+type ImportedReview struct {
+	Rating     srs.Rating
+	ReviewedAt time.Time
+}
+
+// ImportHistory 按时间顺序用用户的调度器重放导入的复习历史，批量写入复习日志并返回最终状态
+// 重放不做负载均衡；未注入调度服务时退回默认 SM-2 且不记录日志
+func (s *ReviewSchedulerService) ImportHistory(userID uint, itemType model.ReviewItemType, itemID uint, reviews []ImportedReview) (srs.Card, error) {
+	card := srs.Card{State: srs.StateNew}
+	var scheduler srs.Scheduler = srs.SM2{}
+	if s != nil {
+		params, err := s.repo.GetParams(userID)
+		if err != nil {
+			return card, err
+		}
+		scheduler = s.scheduler(params, itemType)
+	}
+
+	logs := make([]model.ReviewLog, 0, len(reviews))
+	for _, review := range reviews {
+		if !review.Rating.Valid() {
+			continue
+		}
+		next := scheduler.Next(card, review.Rating, review.ReviewedAt)
+		logs = append(logs, model.ReviewLog{
+			UserID:        userID,
+			ItemType:      itemType,
+			ItemID:        itemID,
+			Rating:        int(review.Rating),
+			State:         string(card.State),
+			ElapsedDays:   round3(card.ElapsedDays(review.ReviewedAt)),
+			ScheduledDays: next.Interval,
+			Stability:     round4(next.Stability),
+			Difficulty:    round4(next.Difficulty),
+			Algorithm:     scheduler.Name(),
+			ReviewedAt:    review.ReviewedAt,
+		})
+		card = next
+	}
+	if s == nil {
+		return card, nil
+	}
+	return card, s.repo.CreateLogs(logs)
+}
+
+func reviewResult(itemType model.ReviewItemType, itemID uint, rating srs.Rating, card srs.Card, algorithm string) *model.ReviewResult {
+	return &model.ReviewResult{
+		ItemType:   itemType,
+		ItemID:     itemID,
+		Rating:     int(rating),
+		State:      string(card.State),
+		Interval:   card.Interval,
+		Due:        card.Due,
+		Stability:  round4(card.Stability),
+		Difficulty: round4(card.Difficulty),
+		Algorithm:  algorithm,
+	}
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// =====================================================
+// 卡片记录/错题 与 调度状态的转换
+// =====================================================
+
+// legacyCard 将旧版调度（SM-2 卡片、固定阶梯错题）复习过的条目视为复习状态，以间隔作为初始稳定性
+func legacyCard(card srs.Card) srs.Card {
+	if (card.State == "" || card.State == srs.StateNew) && card.Reps > 0 {
+		card.State = srs.StateReview
+		card.Stability = float64(max(card.Interval, 1))
+		card.Difficulty = 5
+	}
+	if card.State == "" {
+		card.State = srs.StateNew
+	}
+	return card
+}
+
+func flashCardRecordCard(record *model.UserFlashCardRecord) srs.Card {
+	card := srs.Card{
+		State:      srs.State(record.SRSState),
+		Stability:  record.Stability,
+		Difficulty: record.SRSDifficulty,
+		Ease:       record.EaseFactor,
+		Interval:   record.Interval,
+		Reps:       record.ReviewCount,
+		Lapses:     record.Lapses,
+		LastReview: record.LastReviewAt,
+	}
+	if record.NextReviewAt != nil {
+		card.Due = *record.NextReviewAt
+	}
+	return legacyCard(card)
+}
+
+func applyFlashCardRecord(record *model.UserFlashCardRecord, card srs.Card) {
+	due := card.Due
+	record.SRSState = string(card.State)
+	record.Stability = round4(card.Stability)
+	record.SRSDifficulty = round4(card.Difficulty)
+	record.EaseFactor = card.Ease
+	record.Interval = card.Interval
+	record.ReviewCount = card.Reps
+	record.Lapses = card.Lapses
+	record.LastReviewAt = card.LastReview
+	record.NextReviewAt = &due
+}
+
+func wrongQuestionCard(wrong *model.WrongQuestion) srs.Card {
+	card := srs.Card{
+		State:      srs.State(wrong.SRSState),
+		Stability:  wrong.Stability,
+		Difficulty: wrong.SRSDifficulty,
+		Ease:       wrong.EaseFactor,
+		Interval:   wrong.Interval,
+		Reps:       wrong.ReviewCount,
+		Lapses:     wrong.Lapses,
+		LastReview: wrong.LastReviewAt,
+	}
+	if wrong.NextReviewAt != nil {
+		card.Due = *wrong.NextReviewAt
+	}
+	return legacyCard(card)
+}
+
+// applyWrongQuestion 写回调度状态；复习次数只统计用户主动复习，由调用方维护
+func applyWrongQuestion(wrong *model.WrongQuestion, card srs.Card) {
+	due := card.Due
+	wrong.SRSState = string(card.State)
+	wrong.Stability = round4(card.Stability)
+	wrong.SRSDifficulty = round4(card.Difficulty)
+	wrong.EaseFactor = card.Ease
+	wrong.Interval = card.Interval
+	wrong.Lapses = card.Lapses
+	wrong.LastReviewAt = card.LastReview
+	wrong.NextReviewAt = &due
+}
+
+// =====================================================
+// 今日复习队列
+// =====================================================
+
+// TodayQueue 获取今日复习队列：到期卡片与错题合并后按回忆概率从低到高排列，再补充新卡片
+func (s *ReviewSchedulerService) TodayQueue(userID uint) (*model.ReviewQueueResponse, error) {
+	now := time.Now()
+	today := startOfDay(now)
+	tomorrow := today.AddDate(0, 0, 1)
+
+	reviewed, learned, err := s.repo.CountSince(userID, today)
+	if err != nil {
+		return nil, err
+	}
+	due, err := s.repo.CountDue(userID, tomorrow)
+	if err != nil {
+		return nil, err
+	}
+	params, err := s.repo.GetParams(userID)
+	if err != nil {
+		return nil, err
+	}
+	scheduler := s.scheduler(params, model.ReviewItemFlashCard)
+
+	resp := &model.ReviewQueueResponse{
+		Items:         []model.ReviewQueueItem{},
+		DueCount:      due,
+		ReviewedToday: reviewed,
+		NewToday:      learned,
+		ReviewLimit:   s.cfg.DailyReviewLimit,
+		NewLimit:      s.cfg.DailyNewLimit,
+	}
+
+	if reviewLeft := s.cfg.DailyReviewLimit - int(reviewed); reviewLeft > 0 {
+		records, err := s.repo.DueFlashCards(userID, tomorrow, reviewLeft)
+		if err != nil {
+			return nil, err
+		}
+		wrongs, err := s.repo.DueWrongQuestions(userID, tomorrow, reviewLeft)
+		if err != nil {
+			return nil, err
+		}
+
+		items := make([]model.ReviewQueueItem, 0, len(records)+len(wrongs))
+		for _, record := range records {
+			card := flashCardRecordCard(record)
+			item := model.ReviewQueueItem{
+				ItemType:       model.ReviewItemFlashCard,
+				ItemID:         record.CardID,
+				State:          string(card.State),
+				Due:            record.NextReviewAt,
+				Retrievability: round3(scheduler.Retrievability(card, now)),
+			}
+			if record.Card != nil {
+				item.FlashCard = record.Card.ToResponse()
+			}
+			items = append(items, item)
+		}
+		for _, wrong := range wrongs {
+			card := wrongQuestionCard(wrong)
+			items = append(items, model.ReviewQueueItem{
+				ItemType:       model.ReviewItemWrongQuestion,
+				ItemID:         wrong.ID,
+				State:          string(card.State),
+				Due:            wrong.NextReviewAt,
+				Retrievability: round3(scheduler.Retrievability(card, now)),
+				WrongQuestion:  wrong.ToResponse(),
+			})
+		}
+		resp.Items = append(resp.Items, orderReviewQueue(items, reviewLeft)...)
+	}
+
+	if newLeft := s.cfg.DailyNewLimit - int(learned); newLeft > 0 {
+		cards, err := s.repo.NewFlashCards(userID, newLeft)
+		if err != nil {
+			return nil, err
+		}
+		for _, card := range cards {
+			resp.Items = append(resp.Items, model.ReviewQueueItem{
+				ItemType:  model.ReviewItemFlashCard,
+				ItemID:    card.ID,
+				State:     string(srs.StateNew),
+				FlashCard: card.ToResponse(),
+			})
+		}
+	}
+	return resp, nil
+}
+
+// orderReviewQueue 按回忆概率从低到高排列到期项目，概率相同时先到期的在前，最多保留 limit 个
+func orderReviewQueue(items []model.ReviewQueueItem, limit int) []model.ReviewQueueItem {
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Retrievability != items[j].Retrievability {
+			return items[i].Retrievability < items[j].Retrievability
+		}
+		return items[i].Due.Before(*items[j].Due)
+	})
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
+
+// =====================================================
+// 设置与统计
+// =====================================================
+
+// GetSettings 获取用户复习设置
+func (s *ReviewSchedulerService) GetSettings(userID uint) (*model.ReviewSettingsResponse, error) {
+	params, err := s.repo.GetParams(userID)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.repo.CountLogs(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.settingsResponse(params, total), nil
+}
+
+// UpdateSettings 更新用户的调度算法与目标记忆保持率
+func (s *ReviewSchedulerService) UpdateSettings(userID uint, req *UpdateReviewSettingsRequest) (*model.ReviewSettingsResponse, error) {
+	if req.Algorithm != "" && req.Algorithm != srs.AlgorithmFSRS && req.Algorithm != srs.AlgorithmSM2 {
+		return nil, ErrInvalidReviewAlgorithm
+	}
+	if req.RequestRetention != 0 && (req.RequestRetention < 0.7 || req.RequestRetention > 0.97) {
+		return nil, ErrInvalidRequestRetention
+	}
+
+	params, err := s.repo.GetParams(userID)
+	if err != nil {
+		return nil, err
+	}
+	if params == nil {
+		params = &model.SRSUserParams{UserID: userID}
+	}
+	params.Algorithm = req.Algorithm
+	params.RequestRetention = req.RequestRetention
+	if err := s.repo.SaveParams(params); err != nil {
+		return nil, err
+	}
+	return s.GetSettings(userID)
+}
+
+func (s *ReviewSchedulerService) settingsResponse(params *model.SRSUserParams, total int64) *model.ReviewSettingsResponse {
+	resp := &model.ReviewSettingsResponse{
+		Algorithm:        s.cfg.Algorithm,
+		RequestRetention: s.cfg.RequestRetention,
+		TotalReviews:     total,
+		MinReviews:       s.cfg.MinReviewsToOptimize,
+	}
+	if params == nil {
+		return resp
+	}
+	if params.Algorithm != "" {
+		resp.Algorithm = params.Algorithm
+	}
+	if params.RequestRetention > 0 {
+		resp.RequestRetention = params.RequestRetention
+	}
+	resp.Personalized = len(params.Weights) == len(srs.DefaultWeights)
+	resp.ReviewCount = params.ReviewCount
+	resp.LogLoss = params.LogLoss
+	resp.DefaultLogLoss = params.DefaultLogLoss
+	resp.OptimizedAt = params.OptimizedAt
+	return resp
+}
+
+// Stats 获取复习统计：今日复习量、近30天回忆率与未来7天到期量
+func (s *ReviewSchedulerService) Stats(userID uint) (*model.ReviewStatsResponse, error) {
+	now := time.Now()
+	today := startOfDay(now)
+	tomorrow := today.AddDate(0, 0, 1)
+
+	reviewed, learned, err := s.repo.CountSince(userID, today)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.repo.CountLogs(userID)
+	if err != nil {
+		return nil, err
+	}
+	recalled, scored, err := s.repo.GetRetention(userID, today.AddDate(0, 0, -30))
+	if err != nil {
+		return nil, err
+	}
+	dueToday, err := s.repo.CountDue(userID, tomorrow)
+	if err != nil {
+		return nil, err
+	}
+	load, err := s.repo.DueLoad(userID, tomorrow, today.AddDate(0, 0, 7))
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &model.ReviewStatsResponse{
+		TodayReviewed: reviewed,
+		TodayNew:      learned,
+		TotalReviews:  total,
+		Forecast:      make([]model.ReviewForecastDay, 0, 7),
+	}
+	if scored > 0 {
+		stats.Retention = round3(float64(recalled) / float64(scored))
+	}
+	stats.Forecast = append(stats.Forecast, model.ReviewForecastDay{Date: today.Format("2006-01-02"), Count: int(dueToday)})
+	for day := 1; day < 7; day++ {
+		date := today.AddDate(0, 0, day).Format("2006-01-02")
+		stats.Forecast = append(stats.Forecast, model.ReviewForecastDay{Date: date, Count: load[date]})
+	}
+	return stats, nil
+}
+
+// =====================================================
+// 个人参数优化
+// =====================================================
+
+// Optimize 用用户的复习日志拟合个人 FSRS 参数，仅在优于默认参数时启用
+func (s *ReviewSchedulerService) Optimize(ctx context.Context, userID uint) (*model.ReviewSettingsResponse, error) {
+	total, err := s.repo.CountLogs(userID)
+	if err != nil {
+		return nil, err
+	}
+	if int(total) < s.cfg.MinReviewsToOptimize {
+		return nil, ErrNotEnoughReviews
+	}
+	logs, err := s.repo.GetOptimizeLogs(userID, maxOptimizeLogs)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	params, err := s.repo.GetParams(userID)
+	if err != nil {
+		return nil, err
+	}
+	if params == nil {
+		params = &model.SRSUserParams{UserID: userID}
+	}
+
+	result := srs.Optimize(reviewHistories(logs), params.Weights)
+	now := time.Now()
+	params.ReviewCount = int(total)
+	params.LogLoss = round4(result.Loss)
+	params.DefaultLogLoss = round4(result.DefaultLoss)
+	params.OptimizedAt = &now
+	params.Weights = nil
+	if result.Samples > 0 && result.Loss < result.DefaultLoss {
+		params.Weights = make(model.JSONFloatArray, len(result.Weights))
+		for i, w := range result.Weights {
+			params.Weights[i] = round4(w)
+		}
+	}
+	if err := s.repo.SaveParams(params); err != nil {
+		return nil, err
+	}
+	return s.settingsResponse(params, total), nil
+}
+
+// reviewHistories 将按条目和时间排序的日志拆分为每个条目的复习序列
+// 从旧版调度迁移而来、没有首次学习记录的条目无法回放，予以跳过
+func reviewHistories(logs []model.ReviewLog) [][]srs.Review {
+	var histories [][]srs.Review
+	var current []srs.Review
+	var lastType model.ReviewItemType
+	var lastID uint
+	for i, log := range logs {
+		if i == 0 || log.ItemType != lastType || log.ItemID != lastID {
+			if len(current) > 1 {
+				histories = append(histories, current)
+			}
+			current = nil
+			lastType, lastID = log.ItemType, log.ItemID
+			if log.State != string(srs.StateNew) {
+				continue
+			}
+		} else if current == nil {
+			continue
+		}
+		current = append(current, srs.Review{Rating: srs.Rating(log.Rating), ElapsedDays: log.ElapsedDays})
+	}
+	if len(current) > 1 {
+		histories = append(histories, current)
+	}
+	return histories
+}
+
+// OptimizeAll 为复习日志足够且有新增记录的用户重新优化参数，返回成功优化的用户数
+func (s *ReviewSchedulerService) OptimizeAll(ctx context.Context) (int, error) {
+	if !s.syncMu.TryLock() {
+		return 0, ErrReviewOptimizing
+	}
+	defer s.syncMu.Unlock()
+
+	step := max(s.cfg.MinReviewsToOptimize/4, 1)
+	userIDs, err := s.repo.UsersToOptimize(s.cfg.MinReviewsToOptimize, step)
+	if err != nil {
+		return 0, err
+	}
+	optimized := 0
+	for _, userID := range userIDs {
+		if err := ctx.Err(); err != nil {
+			return optimized, err
+		}
+		if _, err := s.Optimize(ctx, userID); err != nil {
+			s.logger.Warn("Review parameter optimization failed", zap.Uint("user_id", userID), zap.Error(err))
+			continue
+		}
+		optimized++
+	}
+	if optimized > 0 {
+		s.logger.Info("Review parameters optimized", zap.Int("users", optimized))
+	}
+	return optimized, nil
+}
+
+// RunScheduled 定时参数优化入口，上一轮仍在执行时跳过
+func (s *ReviewSchedulerService) RunScheduled(ctx context.Context) error {
+	if _, err := s.OptimizeAll(ctx); err != nil && !errors.Is(err, ErrReviewOptimizing) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/what-cse/server/internal/model"
+	"github.com/what-cse/server/internal/srs"
+)
+
+func TestOrderReviewQueue(t *testing.T) {
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	due := func(hours int) *time.Time {
+		d := day.Add(time.Duration(hours) * time.Hour)
+		return &d
+	}
+	items := []model.ReviewQueueItem{
+		{ItemID: 1, Retrievability: 0.85, Due: due(1)},
+		{ItemID: 2, Retrievability: 0.42, Due: due(5)},
+		{ItemID: 3, Retrievability: 0.85, Due: due(0)},
+		{ItemID: 4, Retrievability: 0.9, Due: due(2)},
+		{ItemID: 5, Retrievability: 0.42, Due: due(3)},
+	}
+
+	// 回忆概率低的优先，概率相同时先到期的优先，超出每日上限的截断
+	got := orderReviewQueue(items, 4)
+	want := []uint{5, 2, 3, 1}
+	if len(got) != len(want) {
+		t.Fatalf("got %d items, want %d", len(got), len(want))
+	}
+	for i, id := range want {
+		if got[i].ItemID != id {
+			t.Errorf("position %d: item %d, want %d", i, got[i].ItemID, id)
+		}
+	}
+}
+
+func TestLegacyCard(t *testing.T) {
+	tests := []struct {
+		name string
+		card srs.Card
+		want srs.Card
+	}{
+		// 旧版 SM-2 复习过的卡片以间隔作为稳定性进入 FSRS
+		{"legacy reviewed", srs.Card{Reps: 3, Interval: 6}, srs.Card{State: srs.StateReview, Stability: 6, Difficulty: 5, Reps: 3, Interval: 6}},
+		{"legacy zero interval", srs.Card{Reps: 1}, srs.Card{State: srs.StateReview, Stability: 1, Difficulty: 5, Reps: 1}},
+		{"never reviewed", srs.Card{}, srs.Card{State: srs.StateNew}},
+		{"fsrs card kept", srs.Card{State: srs.StateReview, Stability: 14.8, Difficulty: 5.2, Reps: 2}, srs.Card{State: srs.StateReview, Stability: 14.8, Difficulty: 5.2, Reps: 2}},
+	}
+	for _, tt := range tests {
+		got := legacyCard(tt.card)
+		if got.State != tt.want.State || got.Stability != tt.want.Stability || got.Difficulty != tt.want.Difficulty {
+			t.Errorf("%s: got %s S=%v D=%v, want %s S=%v D=%v", tt.name, got.State, got.Stability, got.Difficulty, tt.want.State, tt.want.Stability, tt.want.Difficulty)
+		}
+	}
+}
@@ -0,0 +1,696 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/what-cse/server/internal/model"
+	"github.com/what-cse/server/internal/repository"
+)
+
+const (
+	// 预测区间的名义覆盖率及对应的正态分位数
+	scoreLineIntervalLevel = 0.9
+	scoreLineIntervalZ     = 1.645
+	// 年份难度效应与分组均值交替估计的迭代次数
+	scoreLineEffectIterations = 8
+	// 模型缓存有效期
+	scoreLineModelTTL = time.Hour
+	// 方差下限，避免单点分组导致除零
+	scoreLineMinVariance = 0.25
+)
+
+// 分层池化的层级
+const (
+	PoolLevelPosition = "position" // 同职位代码
+	PoolLevelGroup    = "group"    // 同省份+单位层级+考试分类
+	PoolLevelProvince = "province" // 同省份
+	PoolLevelGlobal   = "global"   // 同考试类型
+)
+
+// ScoreLineQuery 分数线预测的职位特征
+type ScoreLineQuery struct {
+	PositionCode    string
+	ExamType        string
+	ExamCategory    string
+	Province        string
+	DepartmentLevel string
+}
+
+// ScoreLineQueryFromPosition 从当前职位构造预测特征
+func ScoreLineQueryFromPosition(p *model.Position) ScoreLineQuery {
+	return ScoreLineQuery{
+		PositionCode:    p.PositionCode,
+		ExamType:        p.ExamType,
+		ExamCategory:    p.ExamCategory,
+		Province:        p.Province,
+		DepartmentLevel: p.DepartmentLevel,
+	}
+}
+
+// scoreLineQueryFromHistory 从历年记录构造预测特征
+func scoreLineQueryFromHistory(h *model.PositionHistory) ScoreLineQuery {
+	return ScoreLineQuery{
+		PositionCode:    h.PositionCode,
+		ExamType:        h.ExamType,
+		ExamCategory:    h.ExamCategory,
+		Province:        h.Province,
+		DepartmentLevel: h.DepartmentLevel,
+	}
+}
+
+func (q ScoreLineQuery) provinceKey() string { return q.Province }
+func (q ScoreLineQuery) groupKey() string {
+	return q.Province + "|" + q.DepartmentLevel + "|" + q.ExamCategory
+}
+
+// poolStat 某一层级中一个分组的后验估计
+type poolStat struct {
+	Estimate float64 // 收缩后的均值
+	PostVar  float64 // 后验方差
+	N        int     // 样本量
+}
+
+// poolLevel 一个层级的所有分组及层级方差参数
+type poolLevel struct {
+	Groups   map[string]*poolStat
+	Within   float64 // 组内方差
+	Between  float64 // 组间方差
+	keyOf    func(ScoreLineQuery) string
+	levelTag string
+}
+
+// scoreLineModel 某考试类型、某目标年份的分层收缩模型
+type scoreLineModel struct {
+	TargetYear   int
+	GlobalMean   float64
+	GlobalVar    float64
+	Levels       []*poolLevel // province -> group -> position
+	YearEffects  map[int]float64
+	TargetEffect float64 // 目标年份的难度效应外推值
+	EffectVar    float64 // 年份效应的不确定性
+	Calibration  float64 // 区间校准系数
+	PositionData map[string][]scorePoint
+	GroupMeans   map[string]float64 // 同组原始均值（回测基线用）
+	SampleSize   int
+	fittedAt     time.Time
+}
+
+// scorePoint 一条带年份的分数
+type scorePoint struct {
+	Year  int
+	Score float64
+}
+
+// ScoreLinePredictor 分数线预测引擎
+// 对同单位层级/省份/考试分类的职位做分层收缩，新职位借用同类职位的信息；
+// 同时估计年份难度效应，并用留出年份的残差校准预测区间
+type ScoreLinePredictor struct {
+	historyRepo *repository.PositionHistoryRepository
+
+	mu     sync.Mutex
+	models map[string]*scoreLineModel
+}
+
+// NewScoreLinePredictor 创建分数线预测引擎
+func NewScoreLinePredictor(historyRepo *repository.PositionHistoryRepository) *ScoreLinePredictor {
+	return &ScoreLinePredictor{
+		historyRepo: historyRepo,
+		models:      make(map[string]*scoreLineModel),
+	}
+}
+
+// ScoreLineBacktestYear 单个留出年份的回测结果
+type ScoreLineBacktestYear struct {
+	Year          int            `json:"year"`
+	Count         int            `json:"count"`
+	MAE           float64        `json:"mae"`
+	RMSE          float64        `json:"rmse"`
+	Bias          float64        `json:"bias"`
+	Coverage      float64        `json:"coverage"`       // 真实值落入预测区间的比例
+	AvgWidth      float64        `json:"avg_width"`      // 平均区间宽度
+	BaselineMAE   float64        `json:"baseline_mae"`   // 基线（同职位上一年分数/同组均值）的 MAE
+	CountByLevel  map[string]int `json:"count_by_level"` // 各池化层级的预测数量
+	BaselineCount int            `json:"baseline_count"`
+}
+
+// ScoreLineBacktestReport 回测报告
+type ScoreLineBacktestReport struct {
+	ExamType      string                   `json:"exam_type"`
+	IntervalLevel float64                  `json:"interval_level"`
+	Years         []*ScoreLineBacktestYear `json:"years"`
+	Overall       *ScoreLineBacktestYear   `json:"overall"`
+}
+
+// =====================================================
+// 预测
+// =====================================================
+
+// Predict 预测某职位下一届的进面分数线
+func (p *ScoreLinePredictor) Predict(q ScoreLineQuery) (*model.ScoreLinePrediction, error) {
+	m, err := p.modelFor(q.ExamType)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, ErrInsufficientData
+	}
+
+	est, variance, level, reliability := m.predict(q)
+	halfWidth := scoreLineIntervalZ * m.Calibration * math.Sqrt(variance)
+
+	prediction := &model.ScoreLinePrediction{
+		PredictedScore:  round2(est),
+		ConfidenceLow:   round2(est - halfWidth),
+		ConfidenceHigh:  round2(est + halfWidth),
+		ConfidenceLevel: round2(reliability),
+		IntervalLevel:   scoreLineIntervalLevel,
+		PoolLevel:       level,
+		SampleSize:      m.SampleSize,
+		TargetYear:      m.TargetYear,
+		YearAdjustment:  round2(m.TargetEffect),
+	}
+
+	own := m.PositionData[q.PositionCode]
+	if q.PositionCode != "" && len(own) > 0 {
+		for _, pt := range own {
+			prediction.HistoricalScores = append(prediction.HistoricalScores, pt.Score)
+			prediction.HistoricalYears = append(prediction.HistoricalYears, pt.Year)
+		}
+	}
+	prediction.Basis = scoreLineBasis(level, len(own), m.SampleSize)
+
+	return prediction, nil
+}
+
+// Invalidate 清空模型缓存（历年数据变更后调用）
+func (p *ScoreLinePredictor) Invalidate() {
+	p.mu.Lock()
+	p.models = make(map[string]*scoreLineModel)
+	p.mu.Unlock()
+}
+
+// modelFor 获取（必要时拟合）某考试类型的模型
+func (p *ScoreLinePredictor) modelFor(examType string) (*scoreLineModel, error) {
+	p.mu.Lock()
+	m, ok := p.models[examType]
+	p.mu.Unlock()
+	if ok && time.Since(m.fittedAt) < scoreLineModelTTL {
+		return m, nil
+	}
+
+	histories, err := p.historyRepo.GetScoredHistories(examType)
+	if err != nil {
+		return nil, err
+	}
+	if len(histories) == 0 {
+		return nil, nil
+	}
+
+	maxYear := 0
+	for _, h := range histories {
+		if h.Year > maxYear {
+			maxYear = h.Year
+		}
+	}
+
+	m = fitScoreLineModel(histories, maxYear+1, true)
+
+	p.mu.Lock()
+	p.models[examType] = m
+	p.mu.Unlock()
+
+	return m, nil
+}
+
+// =====================================================
+// 回测
+// =====================================================
+
+// Backtest 按年份滚动回测：每个留出年份只用更早的数据拟合，再预测该年份的分数线
+func (p *ScoreLinePredictor) Backtest(examType string, years []int) (*ScoreLineBacktestReport, error) {
+	histories, err := p.historyRepo.GetScoredHistories(examType)
+	if err != nil {
+		return nil, err
+	}
+	if len(histories) == 0 {
+		return nil, ErrInsufficientData
+	}
+
+	if len(years) == 0 {
+		years = defaultBacktestYears(histories, 3)
+	}
+
+	report := &ScoreLineBacktestReport{
+		ExamType:      examType,
+		IntervalLevel: scoreLineIntervalLevel,
+	}
+	overall := newBacktestAccumulator(0)
+
+	for _, year := range years {
+		var train, test []*model.PositionHistory
+		for _, h := range histories {
+			if h.Year < year {
+				train = append(train, h)
+			} else if h.Year == year {
+				test = append(test, h)
+			}
+		}
+		if len(train) == 0 || len(test) == 0 {
+			continue
+		}
+
+		m := fitScoreLineModel(train, year, true)
+		acc := newBacktestAccumulator(year)
+
+		for _, h := range test {
+			q := scoreLineQueryFromHistory(h)
+			est, variance, level, _ := m.predict(q)
+			halfWidth := scoreLineIntervalZ * m.Calibration * math.Sqrt(variance)
+			baseline, hasBaseline := m.baseline(q)
+			acc.add(h.InterviewScore, est, halfWidth, level, baseline, hasBaseline)
+			overall.add(h.InterviewScore, est, halfWidth, level, baseline, hasBaseline)
+		}
+
+		report.Years = append(report.Years, acc.result())
+	}
+
+	if len(report.Years) == 0 {
+		return nil, ErrInsufficientData
+	}
+	report.Overall = overall.result()
+
+	return report, nil
+}
+
+// defaultBacktestYears 默认回测最近 n 个有数据的年份（至少保留一年训练数据）
+func defaultBacktestYears(histories []*model.PositionHistory, n int) []int {
+	seen := make(map[int]bool)
+	var all []int
+	for _, h := range histories {
+		if !seen[h.Year] {
+			seen[h.Year] = true
+			all = append(all, h.Year)
+		}
+	}
+	sort.Ints(all)
+	if len(all) <= 1 {
+		return nil
+	}
+	all = all[1:]
+	if len(all) > n {
+		all = all[len(all)-n:]
+	}
+	return all
+}
+
+// backtestAccumulator 回测误差累加器
+type backtestAccumulator struct {
+	year                      int
+	n, covered, baselineN     int
+	absErr, sqErr, err, width float64
+	baselineAbsErr            float64
+	byLevel                   map[string]int
+}
+
+func newBacktestAccumulator(year int) *backtestAccumulator {
+	return &backtestAccumulator{year: year, byLevel: make(map[string]int)}
+}
+
+func (a *backtestAccumulator) add(actual, est, halfWidth float64, level string, baseline float64, hasBaseline bool) {
+	diff := est - actual
+	a.n++
+	a.absErr += math.Abs(diff)
+	a.sqErr += diff * diff
+	a.err += diff
+	a.width += 2 * halfWidth
+	if math.Abs(diff) <= halfWidth {
+		a.covered++
+	}
+	a.byLevel[level]++
+	if hasBaseline {
+		a.baselineN++
+		a.baselineAbsErr += math.Abs(baseline - actual)
+	}
+}
+
+func (a *backtestAccumulator) result() *ScoreLineBacktestYear {
+	r := &ScoreLineBacktestYear{
+		Year:          a.year,
+		Count:         a.n,
+		CountByLevel:  a.byLevel,
+		BaselineCount: a.baselineN,
+	}
+	if a.n > 0 {
+		n := float64(a.n)
+		r.MAE = round2(a.absErr / n)
+		r.RMSE = round2(math.Sqrt(a.sqErr / n))
+		r.Bias = round2(a.err / n)
+		r.Coverage = round2(float64(a.covered) / n)
+		r.AvgWidth = round2(a.width / n)
+	}
+	if a.baselineN > 0 {
+		r.BaselineMAE = round2(a.baselineAbsErr / float64(a.baselineN))
+	}
+	return r
+}
+
+// =====================================================
+// 模型拟合
+// =====================================================
+
+// fitScoreLineModel 拟合分层收缩模型
+// calibrate 为 true 时用最后一个训练年份做留出，校准区间宽度
+func fitScoreLineModel(histories []*model.PositionHistory, targetYear int, calibrate bool) *scoreLineModel {
+	m := &scoreLineModel{
+		TargetYear:   targetYear,
+		YearEffects:  make(map[int]float64),
+		PositionData: make(map[string][]scorePoint),
+		GroupMeans:   make(map[string]float64),
+		Calibration:  1,
+		SampleSize:   len(histories),
+		fittedAt:     time.Now(),
+	}
+
+	queries := make([]ScoreLineQuery, len(histories))
+	for i, h := range histories {
+		queries[i] = scoreLineQueryFromHistory(h)
+	}
+
+	// 1. 年份难度效应：与分组均值交替估计，去除各年份职位构成差异的影响
+	groupMeans := make(map[string]float64)
+	for iter := 0; iter < scoreLineEffectIterations; iter++ {
+		sums, counts := make(map[string]float64), make(map[string]int)
+		for i, h := range histories {
+			k := queries[i].groupKey()
+			sums[k] += h.InterviewScore - m.YearEffects[h.Year]
+			counts[k]++
+		}
+		for k := range sums {
+			groupMeans[k] = sums[k] / float64(counts[k])
+		}
+
+		ySums, yCounts := make(map[int]float64), make(map[int]int)
+		for i, h := range histories {
+			ySums[h.Year] += h.InterviewScore - groupMeans[queries[i].groupKey()]
+			yCounts[h.Year]++
+		}
+		var center float64
+		for y := range ySums {
+			m.YearEffects[y] = ySums[y] / float64(yCounts[y])
+			center += m.YearEffects[y]
+		}
+		center /= float64(len(ySums))
+		for y := range m.YearEffects {
+			m.YearEffects[y] -= center
+		}
+	}
+	m.TargetEffect, m.EffectVar = extrapolateYearEffect(m.YearEffects, targetYear)
+
+	// 2. 去除年份效应后的分数
+	adjusted := make([]float64, len(histories))
+	var sum float64
+	for i, h := range histories {
+		adjusted[i] = h.InterviewScore - m.YearEffects[h.Year]
+		sum += adjusted[i]
+	}
+	m.GlobalMean = sum / float64(len(adjusted))
+	for _, a := range adjusted {
+		m.GlobalVar += (a - m.GlobalMean) * (a - m.GlobalMean)
+	}
+	m.GlobalVar = math.Max(m.GlobalVar/float64(len(adjusted)), scoreLineMinVariance)
+
+	rawSums, rawCounts := make(map[string]float64), make(map[string]int)
+	for i, h := range histories {
+		rawSums[queries[i].groupKey()] += h.InterviewScore
+		rawCounts[queries[i].groupKey()]++
+	}
+	for k := range rawSums {
+		m.GroupMeans[k] = rawSums[k] / float64(rawCounts[k])
+	}
+
+	for _, h := range histories {
+		if h.PositionCode != "" {
+			m.PositionData[h.PositionCode] = append(m.PositionData[h.PositionCode], scorePoint{Year: h.Year, Score: h.InterviewScore})
+		}
+	}
+
+	// 3. 自上而下逐层收缩：省份 -> 省份+层级+分类 -> 职位代码
+	levels := []*poolLevel{
+		{keyOf: ScoreLineQuery.provinceKey, levelTag: PoolLevelProvince},
+		{keyOf: ScoreLineQuery.groupKey, levelTag: PoolLevelGroup},
+		{keyOf: func(q ScoreLineQuery) string { return q.PositionCode }, levelTag: PoolLevelPosition},
+	}
+	for li, level := range levels {
+		parentOf := func(q ScoreLineQuery) (float64, float64) {
+			if li == 0 {
+				return m.GlobalMean, 0
+			}
+			return m.levelEstimate(levels[:li], q)
+		}
+		fitPoolLevel(level, queries, adjusted, parentOf)
+		m.Levels = append(m.Levels, level)
+	}
+
+	// 4. 用最后一个训练年份做留出，按标准化残差校准区间
+	if calibrate {
+		m.Calibration = calibrateIntervals(histories, targetYear)
+	}
+
+	return m
+}
+
+// fitPoolLevel 对某一层级做经验贝叶斯收缩
+func fitPoolLevel(level *poolLevel, queries []ScoreLineQuery, adjusted []float64, parentOf func(ScoreLineQuery) (float64, float64)) {
+	type acc struct {
+		sum, sq float64
+		n       int
+		query   ScoreLineQuery
+	}
+	groups := make(map[string]*acc)
+	for i, q := range queries {
+		k := level.keyOf(q)
+		if k == "" {
+			continue
+		}
+		g, ok := groups[k]
+		if !ok {
+			g = &acc{query: q}
+			groups[k] = g
+		}
+		g.sum += adjusted[i]
+		g.sq += adjusted[i] * adjusted[i]
+		g.n++
+	}
+
+	level.Groups = make(map[string]*poolStat, len(groups))
+	if len(groups) == 0 {
+		level.Within, level.Between = scoreLineMinVariance, scoreLineMinVariance
+		return
+	}
+
+	// 组内方差（合并估计）
+	var withinSS float64
+	var withinDF int
+	for _, g := range groups {
+		mean := g.sum / float64(g.n)
+		withinSS += g.sq - float64(g.n)*mean*mean
+		withinDF += g.n - 1
+	}
+	within := scoreLineMinVariance
+	if withinDF > 0 {
+		within = math.Max(withinSS/float64(withinDF), scoreLineMinVariance)
+	}
+
+	// 组间方差（矩估计：组均值相对父级估计的离散减去抽样噪声）
+	var betweenSum, noiseSum float64
+	for _, g := range groups {
+		parent, _ := parentOf(g.query)
+		d := g.sum/float64(g.n) - parent
+		betweenSum += d * d
+		noiseSum += within / float64(g.n)
+	}
+	between := math.Max((betweenSum-noiseSum)/float64(len(groups)), scoreLineMinVariance)
+
+	level.Within, level.Between = within, between
+
+	for k, g := range groups {
+		parent, parentVar := parentOf(g.query)
+		precision := float64(g.n)/within + 1/(between+parentVar)
+		estimate := (g.sum/within + parent/(between+parentVar)) / precision
+		level.Groups[k] = &poolStat{Estimate: estimate, PostVar: 1 / precision, N: g.n}
+	}
+}
+
+// levelEstimate 沿给定层级向下取最细一层已有的估计；缺失的层级继承父级并叠加组间方差
+func (m *scoreLineModel) levelEstimate(levels []*poolLevel, q ScoreLineQuery) (float64, float64) {
+	est, variance := m.GlobalMean, 0.0
+	for _, level := range levels {
+		k := level.keyOf(q)
+		if stat, ok := level.Groups[k]; ok && k != "" {
+			est, variance = stat.Estimate, stat.PostVar
+		} else {
+			variance += level.Between
+		}
+	}
+	return est, variance
+}
+
+// predict 返回预测值、预测方差、使用的池化层级和可靠度
+func (m *scoreLineModel) predict(q ScoreLineQuery) (float64, float64, string, float64) {
+	est, variance := m.levelEstimate(m.Levels, q)
+
+	level := PoolLevelGlobal
+	for i := len(m.Levels) - 1; i >= 0; i-- {
+		k := m.Levels[i].keyOf(q)
+		if _, ok := m.Levels[i].Groups[k]; ok && k != "" {
+			level = m.Levels[i].levelTag
+			break
+		}
+	}
+
+	// 观测噪声取最细层级的组内方差
+	noise := m.Levels[len(m.Levels)-1].Within
+	predVar := variance + noise + m.EffectVar
+
+	// 可靠度：后验方差相对先验总方差的缩减比例
+	priorVar := m.GlobalVar + m.EffectVar
+	reliability := 1 - variance/(variance+priorVar)
+	if level == PoolLevelGlobal {
+		reliability = math.Min(reliability, 0.3)
+	}
+
+	return est + m.TargetEffect, predVar, level, reliability
+}
+
+// baseline 朴素基线：同职位最近一年分数，否则同组原始均值
+func (m *scoreLineModel) baseline(q ScoreLineQuery) (float64, bool) {
+	if pts := m.PositionData[q.PositionCode]; q.PositionCode != "" && len(pts) > 0 {
+		latest := pts[0]
+		for _, pt := range pts {
+			if pt.Year > latest.Year {
+				latest = pt
+			}
+		}
+		return latest.Score, true
+	}
+	if mean, ok := m.GroupMeans[q.groupKey()]; ok {
+		return mean, true
+	}
+	return 0, false
+}
+
+// extrapolateYearEffect 对近年的难度效应做阻尼线性外推
+func extrapolateYearEffect(effects map[int]float64, targetYear int) (float64, float64) {
+	if len(effects) == 0 {
+		return 0, 0
+	}
+
+	years := make([]int, 0, len(effects))
+	for y := range effects {
+		years = append(years, y)
+	}
+	sort.Ints(years)
+	if len(years) > 5 {
+		years = years[len(years)-5:]
+	}
+
+	var mean, variance float64
+	for _, y := range years {
+		mean += effects[y]
+	}
+	mean /= float64(len(years))
+	for _, y := range years {
+		variance += (effects[y] - mean) * (effects[y] - mean)
+	}
+	if len(years) > 1 {
+		variance /= float64(len(years) - 1)
+	}
+
+	if len(years) < 3 {
+		return mean * 0.5, variance
+	}
+
+	var sx, sy, sxy, sxx float64
+	for _, y := range years {
+		x := float64(y)
+		sx += x
+		sy += effects[y]
+		sxy += x * effects[y]
+		sxx += x * x
+	}
+	n := float64(len(years))
+	denom := n*sxx - sx*sx
+	if denom == 0 {
+		return mean, variance
+	}
+	slope := (n*sxy - sx*sy) / denom
+	intercept := (sy - slope*sx) / n
+	trend := slope*float64(targetYear) + intercept
+
+	// 趋势外推打五折，向近年均值回归
+	return 0.5*trend + 0.5*mean, variance
+}
+
+// calibrateIntervals 留出最后一个训练年份，以标准化残差的分位数校准区间
+func calibrateIntervals(histories []*model.PositionHistory, targetYear int) float64 {
+	holdoutYear := 0
+	for _, h := range histories {
+		if h.Year < targetYear && h.Year > holdoutYear {
+			holdoutYear = h.Year
+		}
+	}
+
+	var train, test []*model.PositionHistory
+	for _, h := range histories {
+		if h.Year < holdoutYear {
+			train = append(train, h)
+		} else if h.Year == holdoutYear {
+			test = append(test, h)
+		}
+	}
+	if len(train) == 0 || len(test) < 10 {
+		return 1
+	}
+
+	inner := fitScoreLineModel(train, holdoutYear, false)
+	z := make([]float64, 0, len(test))
+	for _, h := range test {
+		est, variance, _, _ := inner.predict(scoreLineQueryFromHistory(h))
+		if variance > 0 {
+			z = append(z, math.Abs(h.InterviewScore-est)/math.Sqrt(variance))
+		}
+	}
+	if len(z) == 0 {
+		return 1
+	}
+	sort.Float64s(z)
+
+	scale := quantile(z, scoreLineIntervalLevel) / scoreLineIntervalZ
+	return math.Min(math.Max(scale, 0.7), 2.5)
+}
+
+// scoreLineBasis 生成预测依据说明
+func scoreLineBasis(level string, ownYears, sampleSize int) string {
+	switch level {
+	case PoolLevelPosition:
+		if ownYears >= 5 {
+			return fmt.Sprintf("基于该职位近%d年分数线，并结合同类职位分层收缩和年度难度调整，数据较为充足", ownYears)
+		}
+		return fmt.Sprintf("基于该职位近%d年分数线，向同地区同层级职位收缩并做年度难度调整，数据量较少，预测可能存在偏差", ownYears)
+	case PoolLevelGroup:
+		return "该职位暂无历年分数线，基于同省份、同单位层级、同考试分类职位的分数线预测"
+	case PoolLevelProvince:
+		return "该职位暂无历年分数线，基于同省份职位的分数线预测"
+	default:
+		return fmt.Sprintf("基于同考试类型%d条历年分数线的整体水平预测，参考价值有限", sampleSize)
+	}
+}
+
+// round2 保留两位小数
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
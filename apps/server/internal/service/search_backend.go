@@ -0,0 +1,366 @@
+package service
+
+import (
+	"context"
+	"html"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/what-cse/server/internal/model"
+	"github.com/what-cse/server/internal/repository"
+)
+
+// 搜索后端名称
+const (
+	SearchBackendElasticsearch = "elasticsearch"
+	SearchBackendMySQL         = "mysql"
+)
+
+// SearchBackend 职位搜索后端，Elasticsearch 与 MySQL 全文索引共用同一套 SearchQuery
+type SearchBackend interface {
+	Name() string
+	Search(ctx context.Context, query SearchQuery) (*SearchResult, error)
+	Suggest(ctx context.Context, prefix string, size int) ([]string, error)
+	Ping(ctx context.Context) error
+}
+
+// =====================================================
+// MySQL FULLTEXT 后端
+// =====================================================
+
+// MySQLSearchBackend 基于 ngram 全文索引的搜索后端，作为 Elasticsearch 不可用时的兜底
+type MySQLSearchBackend struct {
+	positionRepo *repository.PositionRepository
+}
+
+// NewMySQLSearchBackend 创建 MySQL 搜索后端
+func NewMySQLSearchBackend(positionRepo *repository.PositionRepository) *MySQLSearchBackend {
+	return &MySQLSearchBackend{positionRepo: positionRepo}
+}
+
+// Name 后端名称
+func (b *MySQLSearchBackend) Name() string {
+	return SearchBackendMySQL
+}
+
+// Ping 数据库可用即视为可用
+func (b *MySQLSearchBackend) Ping(ctx context.Context) error {
+	_, err := b.positionRepo.SuggestPositionNames("", 1)
+	return err
+}
+
+// Search 全文检索职位
+func (b *MySQLSearchBackend) Search(ctx context.Context, query SearchQuery) (*SearchResult, error) {
+	start := time.Now()
+	normalizeSearchQuery(&query)
+
+	params := &repository.PositionQueryParams{
+		City:               query.City,
+		Provinces:          query.Provinces,
+		Educations:         query.Educations,
+		ExamTypes:          query.ExamTypes,
+		DepartmentLevel:    query.DepartmentLevel,
+		MajorCategory:      query.MajorCategory,
+		RegistrationStatus: query.RegistrationStatus,
+		IsUnlimitedMajor:   query.IsUnlimitedMajor,
+		IsForFreshGrad:     query.IsForFreshGrad,
+		MinRecruitCount:    query.MinRecruitCount,
+		Keyword:            query.Keyword,
+		DepartmentKeyword:  query.Department,
+		Page:               query.Page,
+		PageSize:           query.PageSize,
+		SortBy:             query.SortBy,
+		SortOrder:          query.SortOrder,
+	}
+
+	rows, total, err := b.positionRepo.FullTextSearch(params)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SearchResult{
+		Total:     total,
+		Positions: make([]model.Position, 0, len(rows)),
+		Backend:   b.Name(),
+	}
+	terms := strings.Fields(query.Keyword)
+	for _, row := range rows {
+		result.Positions = append(result.Positions, row.Position)
+		if !query.Highlight || len(terms) == 0 {
+			continue
+		}
+		fields := map[string]string{
+			"position_name":   row.PositionName,
+			"department_name": row.DepartmentName,
+		}
+		for _, field := range searchHighlightFields {
+			if fragment, ok := highlightTerms(fields[field], terms); ok {
+				if result.Highlights == nil {
+					result.Highlights = make(map[string]map[string][]string)
+				}
+				if result.Highlights[row.PositionID] == nil {
+					result.Highlights[row.PositionID] = make(map[string][]string)
+				}
+				result.Highlights[row.PositionID][field] = []string{fragment}
+			}
+		}
+	}
+	result.Took = time.Since(start).Milliseconds()
+
+	return result, nil
+}
+
+// Suggest 按前缀联想职位名称
+func (b *MySQLSearchBackend) Suggest(ctx context.Context, prefix string, size int) ([]string, error) {
+	if size <= 0 {
+		size = 10
+	}
+	return b.positionRepo.SuggestPositionNames(prefix, size)
+}
+
+// highlightTerms 对文本做 HTML 转义并用 <em></em> 包裹命中的词，与 Elasticsearch 高亮格式一致
+func highlightTerms(text string, terms []string) (string, bool) {
+	if text == "" {
+		return "", false
+	}
+
+	// 在原文上标记命中区间，避免转义后位置错乱
+	runes := []rune(text)
+	marked := make([]bool, len(runes))
+	hit := false
+	lower := []rune(strings.ToLower(text))
+	for _, term := range terms {
+		t := []rune(strings.ToLower(term))
+		if len(t) == 0 || len(t) > len(lower) {
+			continue
+		}
+		for i := 0; i+len(t) <= len(lower); i++ {
+			if string(lower[i:i+len(t)]) == string(t) {
+				for j := i; j < i+len(t); j++ {
+					marked[j] = true
+				}
+				hit = true
+			}
+		}
+	}
+	if !hit {
+		return "", false
+	}
+
+	var sb strings.Builder
+	for i, r := range runes {
+		if marked[i] && (i == 0 || !marked[i-1]) {
+			sb.WriteString("<em>")
+		}
+		sb.WriteString(html.EscapeString(string(r)))
+		if marked[i] && (i == len(runes)-1 || !marked[i+1]) {
+			sb.WriteString("</em>")
+		}
+	}
+	return sb.String(), true
+}
+
+// =====================================================
+// 按健康状态切换的搜索路由
+// =====================================================
+
+// SearchRouter 在 Elasticsearch 与 MySQL 后端之间按健康状态路由
+// Elasticsearch 健康时优先使用；不可用或请求失败时切换到 MySQL，
+// 后台健康检查发现 Elasticsearch 恢复后自动切回。
+type SearchRouter struct {
+	fallback SearchBackend
+	logger   *zap.Logger
+
+	mu        sync.RWMutex
+	primary   SearchBackend
+	healthy   bool
+	lastCheck time.Time
+	lastError string
+
+	// 启动时 Elasticsearch 不可用，由健康检查负责延迟连接
+	connect   func() (*SearchService, error)
+	onConnect func(*SearchService)
+
+	stopChan chan struct{}
+}
+
+// SearchRouterStatus 路由状态
+type SearchRouterStatus struct {
+	Active         string     `json:"active"`
+	PrimaryHealthy bool       `json:"primary_healthy"`
+	LastCheck      *time.Time `json:"last_check,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+}
+
+// NewSearchRouter 创建搜索路由
+func NewSearchRouter(primary *SearchService, fallback SearchBackend, logger *zap.Logger) *SearchRouter {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	r := &SearchRouter{fallback: fallback, logger: logger}
+	if primary != nil {
+		r.primary = primary
+	}
+	return r
+}
+
+// SetConnector 设置延迟连接 Elasticsearch 的方法，连接成功后回调 onConnect
+func (r *SearchRouter) SetConnector(connect func() (*SearchService, error), onConnect func(*SearchService)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.connect = connect
+	r.onConnect = onConnect
+}
+
+// Active 返回当前使用的后端
+func (r *SearchRouter) Active() SearchBackend {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.primary != nil && r.healthy {
+		return r.primary
+	}
+	return r.fallback
+}
+
+// Search 使用当前后端搜索，Elasticsearch 出错时降级到 MySQL 重试
+func (r *SearchRouter) Search(ctx context.Context, query SearchQuery) (*SearchResult, error) {
+	backend := r.Active()
+	result, err := backend.Search(ctx, query)
+	if err == nil || backend == r.fallback {
+		return result, err
+	}
+
+	r.markUnhealthy(err)
+	return r.fallback.Search(ctx, query)
+}
+
+// Suggest 使用当前后端获取联想词，Elasticsearch 出错时降级到 MySQL 重试
+func (r *SearchRouter) Suggest(ctx context.Context, prefix string, size int) ([]string, error) {
+	backend := r.Active()
+	suggestions, err := backend.Suggest(ctx, prefix, size)
+	if err == nil || backend == r.fallback {
+		return suggestions, err
+	}
+
+	r.markUnhealthy(err)
+	return r.fallback.Suggest(ctx, prefix, size)
+}
+
+func (r *SearchRouter) markUnhealthy(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.healthy {
+		r.logger.Warn("Elasticsearch request failed, falling back to MySQL search", zap.Error(err))
+	}
+	r.healthy = false
+	r.lastError = err.Error()
+}
+
+// CheckHealth 检查 Elasticsearch 健康状态并更新路由，必要时尝试建立连接
+func (r *SearchRouter) CheckHealth(ctx context.Context) {
+	r.mu.RLock()
+	primary, connect, onConnect := r.primary, r.connect, r.onConnect
+	r.mu.RUnlock()
+
+	if primary == nil && connect != nil {
+		search, err := connect()
+		if err != nil {
+			r.setHealth(false, err)
+			return
+		}
+		if onConnect != nil {
+			onConnect(search)
+		}
+		r.mu.Lock()
+		r.primary = search
+		r.mu.Unlock()
+		primary = search
+	}
+	if primary == nil {
+		r.setHealth(false, nil)
+		return
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	err := primary.Ping(pingCtx)
+	r.setHealth(err == nil, err)
+}
+
+func (r *SearchRouter) setHealth(healthy bool, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.primary != nil && healthy != r.healthy {
+		if healthy {
+			r.logger.Info("Elasticsearch healthy, search switched to elasticsearch")
+		} else {
+			r.logger.Warn("Elasticsearch unhealthy, search switched to mysql", zap.Error(err))
+		}
+	}
+	r.healthy = healthy
+	r.lastCheck = time.Now()
+	r.lastError = ""
+	if err != nil {
+		r.lastError = err.Error()
+	}
+}
+
+// StartHealthCheck 启动后台健康检查
+func (r *SearchRouter) StartHealthCheck(interval time.Duration) {
+	r.mu.Lock()
+	if r.stopChan != nil {
+		r.mu.Unlock()
+		return
+	}
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	stop := make(chan struct{})
+	r.stopChan = stop
+	r.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				r.CheckHealth(context.Background())
+			}
+		}
+	}()
+}
+
+// StopHealthCheck 停止后台健康检查
+func (r *SearchRouter) StopHealthCheck() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stopChan != nil {
+		close(r.stopChan)
+		r.stopChan = nil
+	}
+}
+
+// Status 返回路由状态
+func (r *SearchRouter) Status() *SearchRouterStatus {
+	active := r.Active().Name()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	status := &SearchRouterStatus{
+		Active:         active,
+		PrimaryHealthy: r.primary != nil && r.healthy,
+		LastError:      r.lastError,
+	}
+	if !r.lastCheck.IsZero() {
+		t := r.lastCheck
+		status.LastCheck = &t
+	}
+	return status
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// es 结构体标签说明
+//
+//	es:"-"                                          不写入索引
+//	es:"type:keyword"                               指定字段类型
+//	es:"type:text,analyzer:ik_max_word"             指定分词器
+//	es:"type:text,search_analyzer:ik_smart,suggest" 追加 completion 子字段 <name>.suggest
+//	es:"type:keyword,index:false"                   只存储不建索引
+//
+// 未标注的字段按 Go 类型推断：string 为 keyword，整数为 integer/long，
+// 浮点为 double，bool 为 boolean，time.Time 为 date，字符串切片为 keyword。
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+)
+
+// BuildIndexMapping 根据结构体的 json / es 标签生成 Elasticsearch 索引定义（settings + mappings）
+func BuildIndexMapping(doc interface{}, shards, replicas int) map[string]interface{} {
+	t := reflect.TypeOf(doc)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return map[string]interface{}{
+		"settings": map[string]interface{}{
+			"number_of_shards":   shards,
+			"number_of_replicas": replicas,
+		},
+		"mappings": map[string]interface{}{
+			"dynamic":    false,
+			"properties": buildIndexProperties(t),
+		},
+	}
+}
+
+// buildIndexProperties 遍历结构体字段生成 properties
+func buildIndexProperties(t reflect.Type) map[string]interface{} {
+	props := make(map[string]interface{})
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		// 匿名嵌入结构体的字段平铺到同一层
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for name, prop := range buildIndexProperties(ft) {
+					props[name] = prop
+				}
+				continue
+			}
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		tag := field.Tag.Get("es")
+		if tag == "-" {
+			continue
+		}
+
+		prop := parseESTag(tag)
+		if _, ok := prop["type"]; !ok {
+			esType := inferESType(field.Type)
+			if esType == "" {
+				continue
+			}
+			prop["type"] = esType
+		}
+		props[name] = prop
+	}
+
+	return props
+}
+
+// parseESTag 解析 es 标签为字段映射
+func parseESTag(tag string) map[string]interface{} {
+	prop := make(map[string]interface{})
+	if tag == "" {
+		return prop
+	}
+
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, hasValue := strings.Cut(part, ":")
+		if !hasValue {
+			switch key {
+			case "suggest":
+				prop["fields"] = map[string]interface{}{
+					"suggest": map[string]interface{}{
+						"type":     "completion",
+						"analyzer": "keyword",
+					},
+				}
+			}
+			continue
+		}
+
+		switch value {
+		case "true":
+			prop[key] = true
+		case "false":
+			prop[key] = false
+		default:
+			prop[key] = value
+		}
+	}
+
+	return prop
+}
+
+// inferESType 根据 Go 类型推断字段类型，无法映射时返回空字符串
+func inferESType(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType, deletedAtType:
+		return "date"
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "keyword"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int, reflect.Uint8, reflect.Uint16:
+		return "integer"
+	case reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return "long"
+	case reflect.Float32:
+		return "float"
+	case reflect.Float64:
+		return "double"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.String {
+			return "keyword"
+		}
+	}
+
+	return ""
+}
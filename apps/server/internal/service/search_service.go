@@ -4,7 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/elastic/go-elasticsearch/v8"
 	"github.com/elastic/go-elasticsearch/v8/esapi"
@@ -13,11 +19,17 @@ import (
 	"github.com/what-cse/server/internal/model"
 )
 
+// SearchService 职位搜索服务
+// 对外读写统一使用别名 indexName，别名指向 <indexName>_v<时间戳> 的版本化索引，
+// 全量重建时创建新版本索引并原子切换别名，实现不停机重建。
 type SearchService struct {
 	client    *elasticsearch.Client
 	indexName string
+	shards    int
+	replicas  int
 }
 
+// SearchQuery 职位搜索 DSL，各搜索后端共用
 type SearchQuery struct {
 	Keyword    string `json:"keyword"`
 	Province   string `json:"province"`
@@ -25,14 +37,55 @@ type SearchQuery struct {
 	Department string `json:"department"`
 	Education  string `json:"education"`
 	ExamType   string `json:"exam_type"`
-	Page       int    `json:"page"`
-	PageSize   int    `json:"page_size"`
+
+	Provinces          []string `json:"provinces,omitempty"`
+	Educations         []string `json:"educations,omitempty"`
+	ExamTypes          []string `json:"exam_types,omitempty"`
+	DepartmentLevel    string   `json:"department_level,omitempty"`
+	MajorCategory      string   `json:"major_category,omitempty"`
+	RegistrationStatus string   `json:"reg_status,omitempty"` // registering/upcoming/ended
+	IsUnlimitedMajor   *bool    `json:"unlimited_major,omitempty"`
+	IsForFreshGrad     *bool    `json:"fresh_graduate,omitempty"`
+	MinRecruitCount    int      `json:"min_recruit,omitempty"`
+
+	SortBy    string `json:"sort_by"`    // relevance/created_at/registration_end/recruit_count/competition_ratio
+	SortOrder string `json:"sort_order"` // asc/desc
+	Highlight bool   `json:"highlight"`  // 是否返回高亮片段
+
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
 }
 
 type SearchResult struct {
 	Total     int64            `json:"total"`
 	Positions []model.Position `json:"positions"`
 	Took      int64            `json:"took"`
+	Backend   string           `json:"backend"`
+
+	// Highlights position_id -> 字段 -> 高亮片段（命中处以 <em></em> 包裹）
+	Highlights map[string]map[string][]string `json:"highlights,omitempty"`
+}
+
+// ErrSearchUnavailable 集群无法访问或暂时不可用（连接失败、5xx、429），不属于单条数据的错误
+var ErrSearchUnavailable = errors.New("elasticsearch unavailable")
+
+// searchSortFields 允许的排序字段及默认顺序
+var searchSortFields = map[string]string{
+	"created_at":        "desc",
+	"registration_end":  "asc",
+	"recruit_count":     "desc",
+	"competition_ratio": "asc",
+}
+
+// searchHighlightFields 返回高亮的字段
+var searchHighlightFields = []string{"position_name", "department_name"}
+
+// SearchIndexInfo 索引状态
+type SearchIndexInfo struct {
+	Alias     string   `json:"alias"`
+	Indices   []string `json:"indices"`    // 别名当前指向的索引
+	Versions  []string `json:"versions"`   // 所有版本化索引
+	DocsCount int64    `json:"docs_count"` // 别名下文档数
 }
 
 func NewSearchService(cfg *config.ElasticsearchConfig) (*SearchService, error) {
@@ -55,79 +108,314 @@ func NewSearchService(cfg *config.ElasticsearchConfig) (*SearchService, error) {
 		return nil, fmt.Errorf("failed to connect to elasticsearch: %w", err)
 	}
 
+	shards := cfg.Shards
+	if shards <= 0 {
+		shards = 1
+	}
+
 	return &SearchService{
 		client:    client,
 		indexName: cfg.IndexName,
+		shards:    shards,
+		replicas:  cfg.Replicas,
 	}, nil
 }
 
+// Alias 返回对外使用的索引别名
+func (s *SearchService) Alias() string {
+	return s.indexName
+}
+
+// IndexMapping 返回由 model.Position 的 es 标签生成的索引定义
+func (s *SearchService) IndexMapping() map[string]interface{} {
+	return BuildIndexMapping(model.Position{}, s.shards, s.replicas)
+}
+
+// Name 后端名称
+func (s *SearchService) Name() string {
+	return SearchBackendElasticsearch
+}
+
+// Ping 检查集群可用且别名已就绪
+func (s *SearchService) Ping(ctx context.Context) error {
+	res, err := s.client.Indices.Exists([]string{s.indexName}, s.client.Indices.Exists.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("index %s not ready: %s", s.indexName, res.Status())
+	}
+	return nil
+}
+
+// CreateIndex 确保别名可用：别名或同名索引已存在时不做处理，否则创建首个版本索引并挂上别名
 func (s *SearchService) CreateIndex(ctx context.Context) error {
-	mapping := `{
-		"settings": {
-			"number_of_shards": 1,
-			"number_of_replicas": 0,
-			"analysis": {
-				"analyzer": {
-					"ik_smart_analyzer": {
-						"type": "custom",
-						"tokenizer": "ik_smart"
-					},
-					"ik_max_analyzer": {
-						"type": "custom",
-						"tokenizer": "ik_max_word"
-					}
-				}
-			}
-		},
-		"mappings": {
-			"properties": {
-				"position_id": { "type": "keyword" },
-				"position_name": { "type": "text", "analyzer": "ik_max_analyzer", "search_analyzer": "ik_smart_analyzer" },
-				"department_name": { "type": "text", "analyzer": "ik_max_analyzer", "search_analyzer": "ik_smart_analyzer" },
-				"department_code": { "type": "keyword" },
-				"department_level": { "type": "keyword" },
-				"work_location_province": { "type": "keyword" },
-				"work_location_city": { "type": "keyword" },
-				"work_location_district": { "type": "keyword" },
-				"recruit_count": { "type": "integer" },
-				"exam_type": { "type": "keyword" },
-				"education_min": { "type": "keyword" },
-				"major_category": { "type": "keyword" },
-				"major_specific": { "type": "text", "analyzer": "ik_smart_analyzer" },
-				"major_unlimited": { "type": "boolean" },
-				"political_status": { "type": "keyword" },
-				"work_exp_years_min": { "type": "integer" },
-				"age_min": { "type": "integer" },
-				"age_max": { "type": "integer" },
-				"hukou_required": { "type": "boolean" },
-				"applicant_count": { "type": "integer" },
-				"competition_ratio": { "type": "float" },
-				"status": { "type": "keyword" },
-				"created_at": { "type": "date" },
-				"updated_at": { "type": "date" }
-			}
-		}
-	}`
+	_, err := s.EnsureIndex(ctx)
+	return err
+}
+
+// EnsureIndex 同 CreateIndex，返回是否新建了（空的）索引，新建时调用方应触发全量重建
+func (s *SearchService) EnsureIndex(ctx context.Context) (bool, error) {
+	res, err := s.client.Indices.Exists([]string{s.indexName}, s.client.Indices.Exists.WithContext(ctx))
+	if err != nil {
+		return false, err
+	}
+	res.Body.Close()
+	if res.StatusCode == http.StatusOK {
+		return false, nil
+	}
+
+	index, err := s.CreateVersionedIndex(ctx, false)
+	if err != nil {
+		return false, err
+	}
+	if _, err = s.SwapAlias(ctx, index); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CreateVersionedIndex 创建新的版本化索引并返回索引名
+// bulkLoad 为 true 时关闭刷新和副本以加快全量写入，写入完成后需调用 FinishBulkLoad
+func (s *SearchService) CreateVersionedIndex(ctx context.Context, bulkLoad bool) (string, error) {
+	index := fmt.Sprintf("%s_v%s", s.indexName, time.Now().Format("20060102150405"))
+
+	mapping := s.IndexMapping()
+	if bulkLoad {
+		settings := mapping["settings"].(map[string]interface{})
+		settings["refresh_interval"] = "-1"
+		settings["number_of_replicas"] = 0
+	}
+
+	body, err := json.Marshal(mapping)
+	if err != nil {
+		return "", err
+	}
 
 	res, err := s.client.Indices.Create(
-		s.indexName,
-		s.client.Indices.Create.WithBody(bytes.NewReader([]byte(mapping))),
+		index,
+		s.client.Indices.Create.WithBody(bytes.NewReader(body)),
 		s.client.Indices.Create.WithContext(ctx),
 	)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return "", fmt.Errorf("failed to create index: %s", res.String())
+	}
+
+	return index, nil
+}
+
+// FinishBulkLoad 恢复全量写入时关闭的刷新和副本设置并刷新索引
+func (s *SearchService) FinishBulkLoad(ctx context.Context, index string) error {
+	settings := fmt.Sprintf(`{"index":{"refresh_interval":"1s","number_of_replicas":%d}}`, s.replicas)
+	res, err := s.client.Indices.PutSettings(
+		strings.NewReader(settings),
+		s.client.Indices.PutSettings.WithIndex(index),
+		s.client.Indices.PutSettings.WithContext(ctx),
+	)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("failed to update index settings: %s", res.String())
+	}
+
+	refresh, err := s.client.Indices.Refresh(
+		s.client.Indices.Refresh.WithIndex(index),
+		s.client.Indices.Refresh.WithContext(ctx),
+	)
+	if err != nil {
+		return err
+	}
+	refresh.Body.Close()
+
+	return nil
+}
+
+// ResolveAlias 返回别名当前指向的索引
+func (s *SearchService) ResolveAlias(ctx context.Context) ([]string, error) {
+	res, err := s.client.Indices.GetAlias(
+		s.client.Indices.GetAlias.WithName(s.indexName),
+		s.client.Indices.GetAlias.WithContext(ctx),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if res.IsError() {
+		return nil, fmt.Errorf("failed to get alias: %s", res.String())
+	}
+
+	var result map[string]interface{}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	indices := make([]string, 0, len(result))
+	for index := range result {
+		indices = append(indices, index)
+	}
+	sort.Strings(indices)
+	return indices, nil
+}
+
+// ListVersions 返回所有版本化索引（按版本升序）
+func (s *SearchService) ListVersions(ctx context.Context) ([]string, error) {
+	res, err := s.client.Cat.Indices(
+		s.client.Cat.Indices.WithIndex(s.indexName+"_v*"),
+		s.client.Cat.Indices.WithH("index"),
+		s.client.Cat.Indices.WithFormat("json"),
+		s.client.Cat.Indices.WithContext(ctx),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("failed to list indices: %s", res.String())
+	}
+
+	var rows []struct {
+		Index string `json:"index"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&rows); err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(rows))
+	for _, row := range rows {
+		versions = append(versions, row.Index)
+	}
+	sort.Strings(versions)
+	return versions, nil
+}
+
+// SwapAlias 原子地将别名切换到指定索引，返回切换前别名指向的索引
+// 若存在与别名同名的旧式实体索引，会在同一请求中删除
+func (s *SearchService) SwapAlias(ctx context.Context, index string) ([]string, error) {
+	previous, err := s.ResolveAlias(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var actions []map[string]interface{}
+	if len(previous) == 0 {
+		res, err := s.client.Indices.Exists([]string{s.indexName}, s.client.Indices.Exists.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		res.Body.Close()
+		if res.StatusCode == http.StatusOK {
+			actions = append(actions, map[string]interface{}{
+				"remove_index": map[string]interface{}{"index": s.indexName},
+			})
+		}
+	}
+	for _, old := range previous {
+		if old == index {
+			continue
+		}
+		actions = append(actions, map[string]interface{}{
+			"remove": map[string]interface{}{"index": old, "alias": s.indexName},
+		})
+	}
+	actions = append(actions, map[string]interface{}{
+		"add": map[string]interface{}{"index": index, "alias": s.indexName, "is_write_index": true},
+	})
+
+	body, err := json.Marshal(map[string]interface{}{"actions": actions})
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.client.Indices.UpdateAliases(
+		bytes.NewReader(body),
+		s.client.Indices.UpdateAliases.WithContext(ctx),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("failed to swap alias: %s", res.String())
+	}
+
+	return previous, nil
+}
+
+// DeleteIndices 删除指定索引
+func (s *SearchService) DeleteIndices(ctx context.Context, indices []string) error {
+	if len(indices) == 0 {
+		return nil
+	}
+
+	res, err := s.client.Indices.Delete(indices, s.client.Indices.Delete.WithContext(ctx))
 	if err != nil {
 		return err
 	}
 	defer res.Body.Close()
 
 	if res.IsError() {
-		return fmt.Errorf("failed to create index: %s", res.String())
+		return fmt.Errorf("failed to delete indices: %s", res.String())
 	}
 
 	return nil
 }
 
+// Info 返回别名与版本索引状态
+func (s *SearchService) Info(ctx context.Context) (*SearchIndexInfo, error) {
+	info := &SearchIndexInfo{Alias: s.indexName}
+
+	var err error
+	if info.Indices, err = s.ResolveAlias(ctx); err != nil {
+		return nil, err
+	}
+	if info.Versions, err = s.ListVersions(ctx); err != nil {
+		return nil, err
+	}
+
+	res, err := s.client.Count(
+		s.client.Count.WithIndex(s.indexName),
+		s.client.Count.WithContext(ctx),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if !res.IsError() {
+		var result struct {
+			Count int64 `json:"count"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&result); err == nil {
+			info.DocsCount = result.Count
+		}
+	}
+
+	return info, nil
+}
+
+// positionDocument 生成写入索引的文档，去掉 es:"-" 的关联数据
+func positionDocument(position *model.Position) ([]byte, error) {
+	doc := *position
+	doc.Announcements = nil
+	return json.Marshal(doc)
+}
+
 func (s *SearchService) IndexPosition(ctx context.Context, position *model.Position) error {
-	data, err := json.Marshal(position)
+	data, err := positionDocument(position)
 	if err != nil {
 		return err
 	}
@@ -153,45 +441,93 @@ func (s *SearchService) IndexPosition(ctx context.Context, position *model.Posit
 }
 
 func (s *SearchService) BulkIndexPositions(ctx context.Context, positions []model.Position) error {
+	failed, err := s.BulkSync(ctx, s.indexName, positions, nil)
+	if err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("bulk index failed for %d documents", len(failed))
+	}
+	return nil
+}
+
+// BulkSync 批量写入和删除文档，返回失败的文档ID及原因
+// 删除不存在的文档不视为失败
+func (s *SearchService) BulkSync(ctx context.Context, index string, upserts []model.Position, deletes []string) (map[string]string, error) {
+	if len(upserts) == 0 && len(deletes) == 0 {
+		return nil, nil
+	}
+
 	var buf bytes.Buffer
+	for i := range upserts {
+		meta, _ := json.Marshal(map[string]interface{}{
+			"index": map[string]interface{}{"_index": index, "_id": upserts[i].PositionID},
+		})
+		buf.Write(meta)
+		buf.WriteByte('\n')
 
-	for _, pos := range positions {
-		meta := map[string]interface{}{
-			"index": map[string]interface{}{
-				"_index": s.indexName,
-				"_id":    pos.PositionID,
-			},
+		data, err := positionDocument(&upserts[i])
+		if err != nil {
+			return nil, err
 		}
-		metaBytes, _ := json.Marshal(meta)
-		buf.Write(metaBytes)
+		buf.Write(data)
 		buf.WriteByte('\n')
-
-		dataBytes, _ := json.Marshal(pos)
-		buf.Write(dataBytes)
+	}
+	for _, id := range deletes {
+		meta, _ := json.Marshal(map[string]interface{}{
+			"delete": map[string]interface{}{"_index": index, "_id": id},
+		})
+		buf.Write(meta)
 		buf.WriteByte('\n')
 	}
 
 	res, err := s.client.Bulk(bytes.NewReader(buf.Bytes()), s.client.Bulk.WithContext(ctx))
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("%w: %v", ErrSearchUnavailable, err)
 	}
 	defer res.Body.Close()
 
+	if res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests {
+		return nil, fmt.Errorf("%w: bulk request failed: %s", ErrSearchUnavailable, res.String())
+	}
 	if res.IsError() {
-		return fmt.Errorf("bulk index failed: %s", res.String())
+		return nil, fmt.Errorf("bulk request failed: %s", res.String())
 	}
 
-	return nil
+	return parseBulkFailures(res.Body)
 }
 
-func (s *SearchService) Search(ctx context.Context, query SearchQuery) (*SearchResult, error) {
-	if query.Page < 1 {
-		query.Page = 1
+// parseBulkFailures 解析 bulk 响应中失败的条目
+func parseBulkFailures(body io.Reader) (map[string]string, error) {
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID     string          `json:"_id"`
+			Status int             `json:"status"`
+			Error  json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(body).Decode(&result); err != nil {
+		return nil, err
 	}
-	if query.PageSize < 1 {
-		query.PageSize = 20
+	if !result.Errors {
+		return nil, nil
 	}
 
+	failed := make(map[string]string)
+	for _, item := range result.Items {
+		for op, r := range item {
+			if r.Status < 300 || (op == "delete" && r.Status == http.StatusNotFound) {
+				continue
+			}
+			failed[r.ID] = string(r.Error)
+		}
+	}
+	return failed, nil
+}
+
+func (s *SearchService) Search(ctx context.Context, query SearchQuery) (*SearchResult, error) {
+	normalizeSearchQuery(&query)
 	from := (query.Page - 1) * query.PageSize
 
 	mustQueries := []map[string]interface{}{}
@@ -201,39 +537,80 @@ func (s *SearchService) Search(ctx context.Context, query SearchQuery) (*SearchR
 		mustQueries = append(mustQueries, map[string]interface{}{
 			"multi_match": map[string]interface{}{
 				"query":  query.Keyword,
-				"fields": []string{"position_name^3", "department_name^2", "major_specific"},
+				"fields": []string{"position_name^3", "department_name^2", "major_requirement", "remark"},
 				"type":   "best_fields",
 			},
 		})
 	}
 
-	if query.Province != "" {
-		filterQueries = append(filterQueries, map[string]interface{}{
-			"term": map[string]interface{}{"work_location_province": query.Province},
+	if query.Department != "" {
+		mustQueries = append(mustQueries, map[string]interface{}{
+			"match_phrase": map[string]interface{}{"department_name": query.Department},
 		})
 	}
 
-	if query.City != "" {
-		filterQueries = append(filterQueries, map[string]interface{}{
-			"term": map[string]interface{}{"work_location_city": query.City},
-		})
+	addTerms := func(field string, values []string) {
+		if len(values) > 0 {
+			filterQueries = append(filterQueries, map[string]interface{}{
+				"terms": map[string]interface{}{field: values},
+			})
+		}
 	}
-
-	if query.Education != "" {
+	addTerm := func(field string, value interface{}) {
 		filterQueries = append(filterQueries, map[string]interface{}{
-			"term": map[string]interface{}{"education_min": query.Education},
+			"term": map[string]interface{}{field: value},
 		})
 	}
 
-	if query.ExamType != "" {
+	addTerms("province", query.Provinces)
+	addTerms("education", query.Educations)
+	addTerms("exam_type", query.ExamTypes)
+	if query.City != "" {
+		addTerm("city", query.City)
+	}
+	if query.DepartmentLevel != "" {
+		addTerm("department_level", query.DepartmentLevel)
+	}
+	if query.MajorCategory != "" {
+		addTerm("major_category", query.MajorCategory)
+	}
+	if query.IsUnlimitedMajor != nil {
+		addTerm("is_unlimited_major", *query.IsUnlimitedMajor)
+	}
+	if query.IsForFreshGrad != nil {
+		if *query.IsForFreshGrad {
+			// 与数据库查询一致：未标注（NULL）视为应届可报
+			filterQueries = append(filterQueries, map[string]interface{}{
+				"bool": map[string]interface{}{
+					"must_not": map[string]interface{}{"term": map[string]interface{}{"is_for_fresh_graduate": false}},
+				},
+			})
+		} else {
+			addTerm("is_for_fresh_graduate", false)
+		}
+	}
+	if query.MinRecruitCount > 0 {
 		filterQueries = append(filterQueries, map[string]interface{}{
-			"term": map[string]interface{}{"exam_type": query.ExamType},
+			"range": map[string]interface{}{"recruit_count": map[string]interface{}{"gte": query.MinRecruitCount}},
 		})
 	}
+	switch query.RegistrationStatus {
+	case "registering":
+		filterQueries = append(filterQueries,
+			map[string]interface{}{"range": map[string]interface{}{"registration_start": map[string]interface{}{"lte": "now"}}},
+			map[string]interface{}{"range": map[string]interface{}{"registration_end": map[string]interface{}{"gte": "now"}}},
+		)
+	case "upcoming":
+		filterQueries = append(filterQueries,
+			map[string]interface{}{"range": map[string]interface{}{"registration_start": map[string]interface{}{"gt": "now"}}},
+		)
+	case "ended":
+		filterQueries = append(filterQueries,
+			map[string]interface{}{"range": map[string]interface{}{"registration_end": map[string]interface{}{"lt": "now"}}},
+		)
+	}
 
-	filterQueries = append(filterQueries, map[string]interface{}{
-		"term": map[string]interface{}{"status": "published"},
-	})
+	addTerm("status", model.PositionStatusPublished)
 
 	boolQuery := map[string]interface{}{}
 	if len(mustQueries) > 0 {
@@ -243,16 +620,34 @@ func (s *SearchService) Search(ctx context.Context, query SearchQuery) (*SearchR
 		boolQuery["filter"] = filterQueries
 	}
 
+	var sort []map[string]interface{}
+	if query.SortBy == "relevance" {
+		sort = append(sort, map[string]interface{}{"_score": map[string]string{"order": "desc"}})
+		sort = append(sort, map[string]interface{}{"created_at": map[string]string{"order": "desc"}})
+	} else {
+		sort = append(sort, map[string]interface{}{query.SortBy: map[string]string{"order": query.SortOrder, "missing": "_last"}})
+	}
+
 	searchBody := map[string]interface{}{
 		"from": from,
 		"size": query.PageSize,
 		"query": map[string]interface{}{
 			"bool": boolQuery,
 		},
-		"sort": []map[string]interface{}{
-			{"_score": map[string]string{"order": "desc"}},
-			{"created_at": map[string]string{"order": "desc"}},
-		},
+		"sort": sort,
+	}
+
+	if query.Highlight && query.Keyword != "" {
+		fields := make(map[string]interface{}, len(searchHighlightFields))
+		for _, f := range searchHighlightFields {
+			fields[f] = map[string]interface{}{"number_of_fragments": 0}
+		}
+		searchBody["highlight"] = map[string]interface{}{
+			"pre_tags":  []string{"<em>"},
+			"post_tags": []string{"</em>"},
+			"encoder":   "html",
+			"fields":    fields,
+		}
 	}
 
 	var buf bytes.Buffer
@@ -275,29 +670,73 @@ func (s *SearchService) Search(ctx context.Context, query SearchQuery) (*SearchR
 		return nil, fmt.Errorf("search failed: %s", res.String())
 	}
 
-	var result map[string]interface{}
+	var result struct {
+		Took int64 `json:"took"`
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				Source    model.Position      `json:"_source"`
+				Highlight map[string][]string `json:"highlight"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
 	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
 		return nil, err
 	}
 
-	hits := result["hits"].(map[string]interface{})
-	total := int64(hits["total"].(map[string]interface{})["value"].(float64))
-	took := int64(result["took"].(float64))
+	searchResult := &SearchResult{
+		Total:     result.Hits.Total.Value,
+		Positions: make([]model.Position, 0, len(result.Hits.Hits)),
+		Took:      result.Took,
+		Backend:   s.Name(),
+	}
+	for _, hit := range result.Hits.Hits {
+		searchResult.Positions = append(searchResult.Positions, hit.Source)
+		if len(hit.Highlight) > 0 {
+			if searchResult.Highlights == nil {
+				searchResult.Highlights = make(map[string]map[string][]string)
+			}
+			searchResult.Highlights[hit.Source.PositionID] = hit.Highlight
+		}
+	}
+
+	return searchResult, nil
+}
 
-	var positions []model.Position
-	for _, hit := range hits["hits"].([]interface{}) {
-		source := hit.(map[string]interface{})["_source"]
-		sourceBytes, _ := json.Marshal(source)
-		var pos model.Position
-		json.Unmarshal(sourceBytes, &pos)
-		positions = append(positions, pos)
+// normalizeSearchQuery 补全分页、排序默认值，并把单值筛选合并进多值筛选
+func normalizeSearchQuery(query *SearchQuery) {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 || query.PageSize > 100 {
+		query.PageSize = 20
 	}
 
-	return &SearchResult{
-		Total:     total,
-		Positions: positions,
-		Took:      took,
-	}, nil
+	if query.Province != "" {
+		query.Provinces = append(query.Provinces, query.Province)
+		query.Province = ""
+	}
+	if query.Education != "" {
+		query.Educations = append(query.Educations, query.Education)
+		query.Education = ""
+	}
+	if query.ExamType != "" {
+		query.ExamTypes = append(query.ExamTypes, query.ExamType)
+		query.ExamType = ""
+	}
+
+	if defaultOrder, ok := searchSortFields[query.SortBy]; ok {
+		if query.SortOrder != "asc" && query.SortOrder != "desc" {
+			query.SortOrder = defaultOrder
+		}
+	} else if query.Keyword != "" {
+		query.SortBy = "relevance"
+	} else {
+		query.SortBy = "created_at"
+		query.SortOrder = "desc"
+	}
 }
 
 func (s *SearchService) DeletePosition(ctx context.Context, positionID string) error {
@@ -348,6 +787,10 @@ func (s *SearchService) Suggest(ctx context.Context, prefix string, size int) ([
 	}
 	defer res.Body.Close()
 
+	if res.IsError() {
+		return nil, fmt.Errorf("suggest failed: %s", res.String())
+	}
+
 	var result map[string]interface{}
 	json.NewDecoder(res.Body).Decode(&result)
 
@@ -0,0 +1,428 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/what-cse/server/internal/model"
+	"github.com/what-cse/server/internal/repository"
+)
+
+var (
+	ErrSearchReindexRunning = errors.New("搜索索引正在重建")
+)
+
+const (
+	searchSyncMaxAttempts = 10
+	searchOutboxRetention = 7 * 24 * time.Hour
+	searchReindexBatch    = 500
+
+	// 单条记录失败后的重试退避：5s、10s、20s……最长 1 小时
+	searchRetryBaseDelay = 5 * time.Second
+	searchRetryMaxDelay  = time.Hour
+	// 集群不可用时探测恢复的间隔上限
+	searchProbeMaxDelay = 5 * time.Minute
+)
+
+// SearchSyncService 搜索索引同步服务
+// 负责把发件箱中的职位变更刷入 Elasticsearch，以及版本化索引的全量重建
+type SearchSyncService struct {
+	search       *SearchService
+	positionRepo *repository.PositionRepository
+	outboxRepo   *repository.SearchOutboxRepository
+	logger       *zap.Logger
+
+	batchSize   int
+	keepIndices int
+
+	syncMu    sync.Mutex // 保证同一时间只有一个同步批次
+	reindexMu sync.Mutex
+
+	// 集群不可用期间不消耗记录的尝试次数，只按退避间隔探测恢复（受 syncMu 保护）
+	unavailable bool
+	probeDelay  time.Duration
+	nextProbe   time.Time
+
+	mu          sync.Mutex
+	lastReindex *SearchReindexResult
+}
+
+// SearchReindexResult 全量重建结果
+type SearchReindexResult struct {
+	Index       string    `json:"index"`
+	Previous    []string  `json:"previous"`
+	Deleted     []string  `json:"deleted"`
+	Indexed     int       `json:"indexed"`
+	Failed      int       `json:"failed"`
+	CatchUp     int       `json:"catch_up"` // 重建期间变更、切换后补同步的数量
+	StartedAt   time.Time `json:"started_at"`
+	FinishedAt  time.Time `json:"finished_at"`
+	DurationSec float64   `json:"duration_sec"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// SearchSyncStatus 同步状态
+type SearchSyncStatus struct {
+	Index       *SearchIndexInfo         `json:"index"`
+	Outbox      *model.SearchOutboxStats `json:"outbox"`
+	Reindexing  bool                     `json:"reindexing"`
+	LastReindex *SearchReindexResult     `json:"last_reindex,omitempty"`
+}
+
+// NewSearchSyncService 创建搜索索引同步服务
+func NewSearchSyncService(
+	search *SearchService,
+	positionRepo *repository.PositionRepository,
+	outboxRepo *repository.SearchOutboxRepository,
+	batchSize, keepIndices int,
+	logger *zap.Logger,
+) *SearchSyncService {
+	if batchSize <= 0 {
+		batchSize = 200
+	}
+	if keepIndices < 0 {
+		keepIndices = 0
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &SearchSyncService{
+		search:       search,
+		positionRepo: positionRepo,
+		outboxRepo:   outboxRepo,
+		logger:       logger,
+		batchSize:    batchSize,
+		keepIndices:  keepIndices,
+	}
+}
+
+// =====================================================
+// 增量同步
+// =====================================================
+
+// RunScheduled 发件箱定时同步入口，集群不可用期间跳过
+func (s *SearchSyncService) RunScheduled(ctx context.Context) error {
+	if _, err := s.SyncPending(ctx); err != nil && !errors.Is(err, ErrSearchUnavailable) {
+		return err
+	}
+	return nil
+}
+
+// PurgeSearchOutbox 清理超过保留期的已同步记录。发件箱随职位写入追加，
+// 与 Elasticsearch 是否连接无关，因此由独立的定时任务执行
+func PurgeSearchOutbox(outboxRepo *repository.SearchOutboxRepository, logger *zap.Logger) error {
+	n, err := outboxRepo.PurgeProcessed(time.Now().Add(-searchOutboxRetention))
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		logger.Info("Purged processed search outbox entries", zap.Int64("count", n))
+	}
+	return nil
+}
+
+// SyncPending 处理发件箱中所有待同步记录，返回处理的记录数
+// 集群不可用时跳过本轮，直到探测到恢复
+func (s *SearchSyncService) SyncPending(ctx context.Context) (int, error) {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	if s.unavailable {
+		if time.Now().Before(s.nextProbe) {
+			return 0, ErrSearchUnavailable
+		}
+		if err := s.search.Ping(ctx); err != nil {
+			s.markUnavailable(err)
+			return 0, fmt.Errorf("%w: %v", ErrSearchUnavailable, err)
+		}
+		s.markRecovered()
+	}
+
+	total := 0
+	for {
+		entries, err := s.outboxRepo.GetPending(model.SearchEntityPosition, searchSyncMaxAttempts, s.batchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(entries) == 0 {
+			return total, nil
+		}
+
+		if err := s.syncBatch(ctx, entries); err != nil {
+			if errors.Is(err, ErrSearchUnavailable) {
+				s.markUnavailable(err)
+			}
+			return total, err
+		}
+		total += len(entries)
+
+		if len(entries) < s.batchSize {
+			return total, nil
+		}
+	}
+}
+
+// syncBatch 同步一批发件箱记录
+// 按职位当前状态决定写入或删除；同一职位的多条记录合并为一次操作
+func (s *SearchSyncService) syncBatch(ctx context.Context, entries []model.SearchOutbox) error {
+	entriesByPosition := make(map[uint][]model.SearchOutbox)
+	positionIDs := make([]uint, 0, len(entries))
+	for _, e := range entries {
+		if _, ok := entriesByPosition[e.EntityID]; !ok {
+			positionIDs = append(positionIDs, e.EntityID)
+		}
+		entriesByPosition[e.EntityID] = append(entriesByPosition[e.EntityID], e)
+	}
+
+	positions, err := s.positionRepo.FindForIndex(positionIDs)
+	if err != nil {
+		return err
+	}
+
+	_, failed, err := s.syncPositions(ctx, s.search.Alias(), positions)
+	if err != nil {
+		// 集群不可用不是记录本身的问题，不计入尝试次数
+		if !errors.Is(err, ErrSearchUnavailable) {
+			s.markFailed(entries, err.Error())
+		}
+		return err
+	}
+
+	// 已被物理删除的职位无法得知文档ID，直接视为已处理
+	var done []uint
+	for positionID, positionEntries := range entriesByPosition {
+		if msg, ok := failed[positionID]; ok {
+			s.markFailed(positionEntries, msg)
+			continue
+		}
+		for _, e := range positionEntries {
+			done = append(done, e.ID)
+		}
+	}
+
+	return s.outboxRepo.MarkProcessed(done)
+}
+
+// markFailed 记录失败并按各记录的尝试次数安排下次重试
+func (s *SearchSyncService) markFailed(entries []model.SearchOutbox, errMsg string) {
+	idsByAttempts := make(map[int][]uint)
+	for _, e := range entries {
+		idsByAttempts[e.Attempts] = append(idsByAttempts[e.Attempts], e.ID)
+	}
+	now := time.Now()
+	for attempts, ids := range idsByAttempts {
+		delay := searchRetryMaxDelay
+		if attempts < 10 {
+			delay = min(searchRetryBaseDelay<<attempts, searchRetryMaxDelay)
+		}
+		if err := s.outboxRepo.MarkFailed(ids, errMsg, now.Add(delay)); err != nil {
+			s.logger.Warn("Failed to record search outbox failure", zap.Error(err))
+		}
+	}
+}
+
+// markUnavailable 集群不可用：暂停同步，按指数退避探测恢复
+func (s *SearchSyncService) markUnavailable(err error) {
+	if !s.unavailable {
+		s.unavailable = true
+		s.probeDelay = searchRetryBaseDelay
+		s.logger.Warn("Elasticsearch unavailable, pausing search outbox sync", zap.Error(err))
+	} else {
+		s.probeDelay = min(s.probeDelay*2, searchProbeMaxDelay)
+	}
+	s.nextProbe = time.Now().Add(s.probeDelay)
+}
+
+// markRecovered 集群恢复：恢复同步。集群不可用不计入尝试次数，待同步记录自然继续；
+// 已达重试上限的记录是数据本身的问题，不随恢复重新入队，需通过 RetryFailed 手动重试
+func (s *SearchSyncService) markRecovered() {
+	s.unavailable = false
+	s.probeDelay = 0
+	s.logger.Info("Elasticsearch recovered, resuming search outbox sync")
+}
+
+// syncPositions 将职位写入或从指定索引删除，返回成功数和失败的职位主键
+func (s *SearchSyncService) syncPositions(ctx context.Context, index string, positions []model.Position) (int, map[uint]string, error) {
+	var upserts []model.Position
+	var deletes []string
+	docToPosition := make(map[string]uint, len(positions))
+
+	for _, p := range positions {
+		if p.PositionID == "" {
+			continue
+		}
+		docToPosition[p.PositionID] = p.ID
+		if p.DeletedAt.Valid {
+			deletes = append(deletes, p.PositionID)
+		} else {
+			upserts = append(upserts, p)
+		}
+	}
+
+	failedDocs, err := s.search.BulkSync(ctx, index, upserts, deletes)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	failed := make(map[uint]string, len(failedDocs))
+	for docID, msg := range failedDocs {
+		failed[docToPosition[docID]] = msg
+	}
+	return len(upserts) + len(deletes) - len(failed), failed, nil
+}
+
+// RetryFailed 重置失败记录使其重新同步
+func (s *SearchSyncService) RetryFailed() (int64, error) {
+	return s.outboxRepo.ResetFailed(model.SearchEntityPosition)
+}
+
+// =====================================================
+// 全量重建
+// =====================================================
+
+// Reindex 不停机全量重建索引
+//  1. 以 es 标签生成的映射创建新版本索引（关闭刷新加速写入）
+//  2. 按主键游标分批写入全部职位
+//  3. 原子切换别名到新索引
+//  4. 将重建期间变更的职位重新放入发件箱并立即同步，弥补切换前写入旧索引的变更
+//  5. 删除超出保留数量的旧版本索引
+func (s *SearchSyncService) Reindex(ctx context.Context) (*SearchReindexResult, error) {
+	if !s.reindexMu.TryLock() {
+		return nil, ErrSearchReindexRunning
+	}
+	defer s.reindexMu.Unlock()
+
+	result := &SearchReindexResult{StartedAt: time.Now()}
+	err := s.reindex(ctx, result)
+	result.FinishedAt = time.Now()
+	result.DurationSec = result.FinishedAt.Sub(result.StartedAt).Seconds()
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	s.mu.Lock()
+	s.lastReindex = result
+	s.mu.Unlock()
+
+	if err != nil {
+		s.logger.Error("Search reindex failed", zap.String("index", result.Index), zap.Error(err))
+		return result, err
+	}
+	s.logger.Info("Search reindex completed",
+		zap.String("index", result.Index),
+		zap.Int("indexed", result.Indexed),
+		zap.Int("failed", result.Failed),
+		zap.Float64("duration_sec", result.DurationSec),
+	)
+	return result, nil
+}
+
+func (s *SearchSyncService) reindex(ctx context.Context, result *SearchReindexResult) error {
+	index, err := s.search.CreateVersionedIndex(ctx, true)
+	if err != nil {
+		return err
+	}
+	result.Index = index
+
+	var afterID uint
+	for {
+		positions, err := s.positionRepo.ScanForIndex(afterID, searchReindexBatch)
+		if err != nil {
+			s.search.DeleteIndices(ctx, []string{index})
+			return err
+		}
+		if len(positions) == 0 {
+			break
+		}
+
+		indexed, failed, err := s.syncPositions(ctx, index, positions)
+		if err != nil {
+			s.search.DeleteIndices(ctx, []string{index})
+			return fmt.Errorf("bulk load after id %d: %w", afterID, err)
+		}
+		result.Indexed += indexed
+		result.Failed += len(failed)
+
+		afterID = positions[len(positions)-1].ID
+	}
+
+	if err := s.search.FinishBulkLoad(ctx, index); err != nil {
+		s.search.DeleteIndices(ctx, []string{index})
+		return err
+	}
+
+	previous, err := s.search.SwapAlias(ctx, index)
+	if err != nil {
+		s.search.DeleteIndices(ctx, []string{index})
+		return err
+	}
+	result.Previous = previous
+
+	// 切换前的增量可能已写入旧索引，重新入队并同步到新索引
+	changed, err := s.positionRepo.GetChangedSince(result.StartedAt)
+	if err == nil && len(changed) > 0 {
+		if err := s.outboxRepo.Enqueue(model.SearchEntityPosition, changed...); err == nil {
+			result.CatchUp = len(changed)
+		}
+	}
+	if _, err := s.SyncPending(ctx); err != nil {
+		s.logger.Warn("Search catch-up sync failed, will retry in background", zap.Error(err))
+	}
+
+	versions, err := s.search.ListVersions(ctx)
+	if err != nil {
+		return nil
+	}
+	var stale []string
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i] >= index {
+			continue
+		}
+		if s.keepIndices > 0 && len(versions)-1-i <= s.keepIndices {
+			continue
+		}
+		stale = append(stale, versions[i])
+	}
+	if err := s.search.DeleteIndices(ctx, stale); err != nil {
+		s.logger.Warn("Failed to delete stale search indices", zap.Strings("indices", stale), zap.Error(err))
+	} else {
+		result.Deleted = stale
+	}
+
+	return nil
+}
+
+// IsReindexing 是否正在全量重建
+func (s *SearchSyncService) IsReindexing() bool {
+	if s.reindexMu.TryLock() {
+		s.reindexMu.Unlock()
+		return false
+	}
+	return true
+}
+
+// GetStatus 获取索引与同步状态
+func (s *SearchSyncService) GetStatus(ctx context.Context) (*SearchSyncStatus, error) {
+	status := &SearchSyncStatus{Reindexing: s.IsReindexing()}
+
+	info, err := s.search.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status.Index = info
+
+	if status.Outbox, err = s.outboxRepo.GetStats(model.SearchEntityPosition); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	status.LastReindex = s.lastReindex
+	s.mu.Unlock()
+
+	return status, nil
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/what-cse/server/internal/model"
 	"github.com/what-cse/server/internal/repository"
+	"github.com/what-cse/server/internal/srs"
 	"gorm.io/gorm"
 )
 
@@ -22,7 +23,7 @@ var (
 
 // WrongQuestionExportRequest 错题导出请求
 type WrongQuestionExportRequest struct {
-	Format       string `json:"format"`                // 导出格式: json, csv, markdown, html
+	Format       string `json:"format"`                // 导出格式: json, csv, markdown, html, pdf, docx
 	CategoryID   uint   `json:"category_id,omitempty"` // 筛选分类
 	Status       string `json:"status,omitempty"`      // 筛选状态
 	IncludeNote  bool   `json:"include_note"`          // 是否包含笔记
@@ -41,6 +42,8 @@ type StudyNoteService struct {
 	noteRepo          *repository.StudyNoteRepository
 	noteLikeRepo      *repository.NoteLikeRepository
 	questionRepo      *repository.QuestionRepository
+	embeddingService  *EmbeddingService
+	reviewScheduler   *ReviewSchedulerService
 }
 
 func NewStudyNoteService(
@@ -57,11 +60,22 @@ func NewStudyNoteService(
 	}
 }
 
+// SetEmbeddingService sets the embedding service used for similar-question recommendations
+func (s *StudyNoteService) SetEmbeddingService(embeddingService *EmbeddingService) {
+	s.embeddingService = embeddingService
+}
+
+// SetReviewScheduler sets the shared spaced-repetition scheduler for wrong question reviews
+func (s *StudyNoteService) SetReviewScheduler(reviewScheduler *ReviewSchedulerService) {
+	s.reviewScheduler = reviewScheduler
+}
+
 // =====================================================
 // Wrong Question Operations
 // =====================================================
 
-// AddWrongQuestion adds a question to wrong question book
+// AddWrongQuestion adds a question to wrong question book.
+// Every wrong answer counts as a lapse and reschedules the question.
 func (s *StudyNoteService) AddWrongQuestion(userID, questionID uint) (*model.WrongQuestion, error) {
 	// Check if already exists
 	existing, err := s.wrongQuestionRepo.GetByUserAndQuestion(userID, questionID)
@@ -70,6 +84,9 @@ func (s *StudyNoteService) AddWrongQuestion(userID, questionID uint) (*model.Wro
 		if err := s.wrongQuestionRepo.IncrementWrongCount(existing.ID); err != nil {
 			return nil, err
 		}
+		if err := s.scheduleWrongQuestion(existing, srs.Again); err != nil {
+			return nil, err
+		}
 		// Reload and return
 		return s.wrongQuestionRepo.GetByID(existing.ID)
 	}
@@ -93,20 +110,30 @@ func (s *StudyNoteService) AddWrongQuestion(userID, questionID uint) (*model.Wro
 		LastWrongAt:  now,
 		WrongCount:   1,
 		Status:       model.WrongQuestionStatusActive,
+		EaseFactor:   2.5,
 	}
 
-	// Set initial next review time (1 day later)
-	nextReview := now.Add(24 * time.Hour)
-	wrong.NextReviewAt = &nextReview
-
 	if err := s.wrongQuestionRepo.Create(wrong); err != nil {
 		return nil, err
 	}
+	if err := s.scheduleWrongQuestion(wrong, srs.Again); err != nil {
+		return nil, err
+	}
 
 	// Reload with relations
 	return s.wrongQuestionRepo.GetByID(wrong.ID)
 }
 
+// scheduleWrongQuestion runs the shared scheduler for a wrong question and saves the new state
+func (s *StudyNoteService) scheduleWrongQuestion(wrong *model.WrongQuestion, rating srs.Rating) error {
+	next, _, err := s.reviewScheduler.Schedule(wrong.UserID, model.ReviewItemWrongQuestion, wrong.ID, wrongQuestionCard(wrong), rating, time.Now())
+	if err != nil {
+		return err
+	}
+	applyWrongQuestion(wrong, next)
+	return s.wrongQuestionRepo.UpdateSchedule(wrong)
+}
+
 // GetWrongQuestions gets user's wrong questions
 func (s *StudyNoteService) GetWrongQuestions(userID uint, params *repository.WrongQuestionQueryParams) ([]model.WrongQuestionResponse, int64, error) {
 	wrongs, total, err := s.wrongQuestionRepo.GetUserWrongQuestions(userID, params)
@@ -137,7 +164,43 @@ func (s *StudyNoteService) GetWrongQuestionByID(id, userID uint) (*model.WrongQu
 		return nil, ErrWrongQuestionNotFound
 	}
 
-	return wrong.ToResponse(), nil
+	resp := wrong.ToResponse()
+	resp.SimilarQuestions = s.similarQuestions(wrong.QuestionID, 5)
+	return resp, nil
+}
+
+// similarQuestions returns published questions semantically close to the given one.
+// Failures are swallowed: similar questions are a best-effort addition to the detail view.
+func (s *StudyNoteService) similarQuestions(questionID uint, limit int) []*model.QuestionBriefResponse {
+	if s.embeddingService == nil {
+		return nil
+	}
+
+	scored, err := s.embeddingService.SimilarByID(model.EmbeddingEntityQuestion, questionID, limit)
+	if err != nil || len(scored) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(scored))
+	for i, sc := range scored {
+		ids[i] = sc.ID
+	}
+	questions, err := s.questionRepo.GetByIDs(ids)
+	if err != nil {
+		return nil
+	}
+
+	byID := make(map[uint]*model.Question, len(questions))
+	for i := range questions {
+		byID[questions[i].ID] = &questions[i]
+	}
+	result := make([]*model.QuestionBriefResponse, 0, len(ids))
+	for _, id := range ids {
+		if q, ok := byID[id]; ok && q.Status == model.QuestionStatusPublished {
+			result = append(result, q.ToBriefResponse())
+		}
+	}
+	return result
 }
 
 // GetWrongQuestionStats gets user's wrong question statistics
@@ -230,59 +293,54 @@ func (s *StudyNoteService) RestoreWrongQuestion(id, userID uint) error {
 	return s.wrongQuestionRepo.UpdateStatus(id, model.WrongQuestionStatusActive)
 }
 
-// RecordReview records a review of a wrong question
-func (s *StudyNoteService) RecordReview(id, userID uint, isCorrect bool) error {
+// RecordReview records a review of a wrong question. rating is the optional
+// 1-4 self-assessment; when it is 0 the rating is derived from isCorrect.
+func (s *StudyNoteService) RecordReview(id, userID uint, isCorrect bool, rating int) (*model.ReviewResult, error) {
 	wrong, err := s.wrongQuestionRepo.GetByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrWrongQuestionNotFound
+			return nil, ErrWrongQuestionNotFound
 		}
-		return err
+		return nil, err
 	}
 
 	if wrong.UserID != userID {
-		return ErrWrongQuestionNotFound
+		return nil, ErrWrongQuestionNotFound
+	}
+
+	r := srs.RatingFromCorrect(isCorrect)
+	if rating != 0 {
+		r = srs.Rating(rating)
+		isCorrect = r != srs.Again
+	}
+
+	// Schedule the next review with the shared spaced-repetition scheduler
+	next, result, err := s.reviewScheduler.Schedule(userID, model.ReviewItemWrongQuestion, id, wrongQuestionCard(wrong), r, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	applyWrongQuestion(wrong, next)
+	wrong.ReviewCount++
+	if err := s.wrongQuestionRepo.UpdateSchedule(wrong); err != nil {
+		return nil, err
 	}
 
 	if isCorrect {
 		// Increment correct count
 		if err := s.wrongQuestionRepo.IncrementCorrectCount(id); err != nil {
-			return err
+			return nil, err
 		}
 
-		// If correct 3+ times, suggest marking as mastered
+		// Auto mark as mastered if user has gotten it right 3+ times
 		wrong.CorrectCount++
 		if wrong.CorrectCount >= 3 {
-			// Auto mark as mastered if user has gotten it right 3+ times
-			return s.wrongQuestionRepo.UpdateStatus(id, model.WrongQuestionStatusMastered)
-		}
-	}
-
-	// Calculate next review time using spaced repetition
-	nextReview := s.calculateNextReviewTime(wrong.ReviewCount, isCorrect)
-	return s.wrongQuestionRepo.UpdateReview(id, nextReview)
-}
-
-// calculateNextReviewTime calculates next review time using simple spaced repetition
-func (s *StudyNoteService) calculateNextReviewTime(reviewCount int, isCorrect bool) *time.Time {
-	now := time.Now()
-	var interval time.Duration
-
-	if !isCorrect {
-		// If wrong, review again in 1 day
-		interval = 24 * time.Hour
-	} else {
-		// Spaced repetition intervals: 1d, 3d, 7d, 14d, 30d
-		intervals := []int{1, 3, 7, 14, 30}
-		idx := reviewCount
-		if idx >= len(intervals) {
-			idx = len(intervals) - 1
+			if err := s.wrongQuestionRepo.UpdateStatus(id, model.WrongQuestionStatusMastered); err != nil {
+				return nil, err
+			}
 		}
-		interval = time.Duration(intervals[idx]) * 24 * time.Hour
 	}
 
-	nextReview := now.Add(interval)
-	return &nextReview
+	return result, nil
 }
 
 // GetNeedReviewQuestions gets questions that need review
@@ -303,8 +361,14 @@ func (s *StudyNoteService) GetNeedReviewQuestions(userID uint, limit int) ([]mod
 	return responses, nil
 }
 
-// ExportWrongQuestions exports wrong questions for a user
-func (s *StudyNoteService) ExportWrongQuestions(userID uint, req *WrongQuestionExportRequest) (*model.WrongQuestionExportData, error) {
+// GetWrongQuestionsForExport loads the wrong questions selected by an export request
+func (s *StudyNoteService) GetWrongQuestionsForExport(userID uint, req *WrongQuestionExportRequest) ([]model.WrongQuestion, error) {
+	// If specific IDs are provided, we need to handle differently
+	if len(req.IDs) > 0 {
+		// Get specific wrong questions by IDs
+		return s.wrongQuestionRepo.GetByIDs(userID, req.IDs)
+	}
+
 	// Build query params
 	params := &repository.WrongQuestionQueryParams{
 		CategoryID: req.CategoryID,
@@ -315,18 +379,14 @@ func (s *StudyNoteService) ExportWrongQuestions(userID uint, req *WrongQuestionE
 		PageSize:   1000, // Export up to 1000 items
 	}
 
-	// If specific IDs are provided, we need to handle differently
-	var wrongs []model.WrongQuestion
-	var err error
-
-	if len(req.IDs) > 0 {
-		// Get specific wrong questions by IDs
-		wrongs, err = s.wrongQuestionRepo.GetByIDs(userID, req.IDs)
-	} else {
-		// Get all matching wrong questions
-		wrongs, _, err = s.wrongQuestionRepo.GetUserWrongQuestions(userID, params)
-	}
+	// Get all matching wrong questions
+	wrongs, _, err := s.wrongQuestionRepo.GetUserWrongQuestions(userID, params)
+	return wrongs, err
+}
 
+// ExportWrongQuestions exports wrong questions for a user
+func (s *StudyNoteService) ExportWrongQuestions(userID uint, req *WrongQuestionExportRequest) (*model.WrongQuestionExportData, error) {
+	wrongs, err := s.GetWrongQuestionsForExport(userID, req)
 	if err != nil {
 		return nil, err
 	}
@@ -1026,4 +1086,5 @@ type UpdateWrongQuestionRequest struct {
 // RecordReviewRequest 记录复习请求
 type RecordReviewRequest struct {
 	IsCorrect bool `json:"is_correct"`
+	Rating    int  `json:"rating,omitempty"` // 可选 1-4 自评：忘记/困难/良好/简单
 }
@@ -2,10 +2,14 @@ package service
 
 import (
 	"errors"
+	"sort"
+	"sync"
 	"time"
 
+	"github.com/what-cse/server/internal/config"
 	"github.com/what-cse/server/internal/model"
 	"github.com/what-cse/server/internal/repository"
+	"go.uber.org/zap"
 )
 
 var (
@@ -790,53 +794,97 @@ func (s *LearningFavoriteService) DeleteFolder(userID, folderID uint) error {
 
 type KnowledgeMasteryService struct {
 	masteryRepo *repository.KnowledgeMasteryRepository
+	tracingRepo *repository.KnowledgeTracingRepository
+	cfg         config.KnowledgeConfig
+	logger      *zap.Logger
+
+	userLocks userLocks
+	syncMu    sync.Mutex
 }
 
-func NewKnowledgeMasteryService(masteryRepo *repository.KnowledgeMasteryRepository) *KnowledgeMasteryService {
+func NewKnowledgeMasteryService(
+	masteryRepo *repository.KnowledgeMasteryRepository,
+	tracingRepo *repository.KnowledgeTracingRepository,
+	cfg config.KnowledgeConfig,
+	logger *zap.Logger,
+) *KnowledgeMasteryService {
+	if cfg.MaxResponses <= 0 {
+		cfg.MaxResponses = 1000000
+	}
+	if cfg.MinResponses <= 0 {
+		cfg.MinResponses = 50
+	}
+	if cfg.WeakThreshold <= 0 {
+		cfg.WeakThreshold = 60
+	}
 	return &KnowledgeMasteryService{
 		masteryRepo: masteryRepo,
+		tracingRepo: tracingRepo,
+		cfg:         cfg,
+		logger:      logger,
 	}
 }
 
-// UpdateMastery 更新掌握度
+// UpdateMastery 按一次作答更新掌握度（贝叶斯知识追踪）
 func (s *KnowledgeMasteryService) UpdateMastery(userID, knowledgePointID uint, isCorrect bool) error {
-	return s.masteryRepo.Upsert(userID, knowledgePointID, isCorrect)
+	return s.update(userID, knowledgePointID, 0, isCorrect)
+}
+
+// WeakThreshold 薄弱知识点的掌握度阈值（0-100）
+func (s *KnowledgeMasteryService) WeakThreshold() float64 {
+	return s.cfg.WeakThreshold
 }
 
-// GetMasteryList 获取知识点掌握情况
+// GetMasteryList 获取知识点掌握情况（已按遗忘衰减到当前时间）
 func (s *KnowledgeMasteryService) GetMasteryList(userID uint, categoryID *uint) ([]*model.KnowledgeMasteryResponse, error) {
 	masteries, err := s.masteryRepo.GetByUser(userID, categoryID)
 	if err != nil {
 		return nil, err
 	}
+	s.applyForgetting(masteries, time.Now())
 
 	responses := make([]*model.KnowledgeMasteryResponse, len(masteries))
-	for i, m := range masteries {
-		responses[i] = m.ToResponse()
+	for i := range masteries {
+		responses[i] = s.toResponse(&masteries[i])
 	}
 
 	return responses, nil
 }
 
-// GetWeakPoints 获取薄弱知识点
+// GetWeakPoints 获取薄弱知识点，按当前掌握度从低到高
 func (s *KnowledgeMasteryService) GetWeakPoints(userID uint, limit int) ([]*model.KnowledgeMasteryResponse, error) {
 	if limit <= 0 {
 		limit = 10
 	}
 
-	masteries, err := s.masteryRepo.GetWeakPoints(userID, 60, limit)
+	masteries, err := s.masteryRepo.GetByUser(userID, nil)
 	if err != nil {
 		return nil, err
 	}
+	s.applyForgetting(masteries, time.Now())
 
-	responses := make([]*model.KnowledgeMasteryResponse, len(masteries))
-	for i, m := range masteries {
-		responses[i] = m.ToResponse()
+	sort.Slice(masteries, func(i, j int) bool {
+		return masteries[i].MasteryLevel < masteries[j].MasteryLevel
+	})
+
+	responses := make([]*model.KnowledgeMasteryResponse, 0, limit)
+	for i := range masteries {
+		if len(responses) >= limit || masteries[i].MasteryLevel >= s.cfg.WeakThreshold {
+			break
+		}
+		responses = append(responses, s.toResponse(&masteries[i]))
 	}
 
 	return responses, nil
 }
 
+// toResponse 转换为响应，薄弱判定使用配置的阈值
+func (s *KnowledgeMasteryService) toResponse(m *model.UserKnowledgeMastery) *model.KnowledgeMasteryResponse {
+	resp := m.ToResponse()
+	resp.IsWeak = m.MasteryLevel < s.cfg.WeakThreshold
+	return resp
+}
+
 // KnowledgeMasteryStatsResponse 掌握度统计响应
 type KnowledgeMasteryStatsResponse struct {
 	Mastered int64 `json:"mastered"` // 已掌握 (>=80%)
@@ -848,16 +896,24 @@ type KnowledgeMasteryStatsResponse struct {
 
 // GetStats 获取统计
 func (s *KnowledgeMasteryService) GetStats(userID uint) (*KnowledgeMasteryStatsResponse, error) {
-	stats, err := s.masteryRepo.GetMasteryStats(userID)
+	masteries, err := s.masteryRepo.GetByUser(userID, nil)
 	if err != nil {
 		return nil, err
 	}
-
-	resp := &KnowledgeMasteryStatsResponse{
-		Mastered: stats["mastered"],
-		Familiar: stats["familiar"],
-		Learning: stats["learning"],
-		Weak:     stats["weak"],
+	s.applyForgetting(masteries, time.Now())
+
+	resp := &KnowledgeMasteryStatsResponse{}
+	for _, m := range masteries {
+		switch {
+		case m.MasteryLevel >= 80:
+			resp.Mastered++
+		case m.MasteryLevel >= 60:
+			resp.Familiar++
+		case m.MasteryLevel >= 40:
+			resp.Learning++
+		default:
+			resp.Weak++
+		}
 	}
 	resp.Total = resp.Mastered + resp.Familiar + resp.Learning + resp.Weak
 
@@ -2,14 +2,20 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"go.uber.org/zap"
 
+	"github.com/what-cse/server/internal/config"
 	"github.com/what-cse/server/internal/crawler"
+	"github.com/what-cse/server/internal/feed"
 	"github.com/what-cse/server/internal/model"
 	"github.com/what-cse/server/internal/repository"
 )
@@ -20,6 +26,8 @@ var (
 	ErrWechatRSSSourceExists    = errors.New("wechat RSS source already exists")
 	ErrWechatRSSCrawlFailed     = errors.New("wechat RSS crawl failed")
 	ErrWechatMPAuthRequired     = errors.New("wechat MP authentication required")
+	ErrWechatRSSFeedInvalid     = errors.New("not a valid RSS/Atom/JSON feed URL")
+	ErrWechatRSSFeedPolling     = errors.New("feed sources are already being polled")
 )
 
 // WechatRSSService handles WeChat article subscription business logic
@@ -27,12 +35,17 @@ type WechatRSSService struct {
 	sourceRepo    *repository.WechatRSSSourceRepository
 	articleRepo   *repository.WechatRSSArticleRepository
 	articleParser *crawler.WechatArticleParser
+	feedFetcher   *crawler.FeedFetcher
 	mpAuthService *WechatMPAuthService
+	feedCfg       config.ArticleFeedConfig
 	logger        *zap.Logger
 
 	// Crawl control
 	crawlMutex   sync.Mutex
 	crawlRunning map[uint]bool
+
+	// Feed source polling
+	syncMu sync.Mutex
 }
 
 // NewWechatRSSService creates a new WeChat RSS service
@@ -45,8 +58,14 @@ func NewWechatRSSService(
 		sourceRepo:    sourceRepo,
 		articleRepo:   articleRepo,
 		articleParser: crawler.NewWechatArticleParser(logger),
-		logger:        logger,
-		crawlRunning:  make(map[uint]bool),
+		feedFetcher:   crawler.NewFeedFetcher(logger),
+		feedCfg: config.ArticleFeedConfig{
+			IntervalSeconds:  300,
+			MaxErrors:        8,
+			ContentMinLength: 200,
+		},
+		logger:       logger,
+		crawlRunning: make(map[uint]bool),
 	}
 }
 
@@ -55,6 +74,19 @@ func (s *WechatRSSService) SetMPAuthService(mpAuthService *WechatMPAuthService)
 	s.mpAuthService = mpAuthService
 }
 
+// SetFeedConfig sets the polling settings of feed sources; zero values keep the defaults
+func (s *WechatRSSService) SetFeedConfig(cfg config.ArticleFeedConfig) {
+	if cfg.IntervalSeconds > 0 {
+		s.feedCfg.IntervalSeconds = cfg.IntervalSeconds
+	}
+	if cfg.MaxErrors > 0 {
+		s.feedCfg.MaxErrors = cfg.MaxErrors
+	}
+	if cfg.ContentMinLength > 0 {
+		s.feedCfg.ContentMinLength = cfg.ContentMinLength
+	}
+}
+
 // === Source Management ===
 
 // GetSource gets a single source
@@ -121,6 +153,24 @@ func (s *WechatRSSService) UpdateSource(id uint, req *model.UpdateWechatRSSSourc
 	if req.Description != nil {
 		source.Description = *req.Description
 	}
+	if source.SourceType == model.WechatRSSSourceTypeFeed {
+		if req.RSSURL != nil && strings.TrimSpace(*req.RSSURL) != source.RSSURL {
+			rssURL, err := normalizeFeedURL(*req.RSSURL)
+			if err != nil {
+				return nil, err
+			}
+			if exists, _ := s.sourceRepo.ExistsByRSSURL(rssURL); exists {
+				return nil, ErrWechatRSSSourceExists
+			}
+			source.RSSURL = rssURL
+			// 新地址不能沿用旧地址的缓存校验值
+			source.FeedETag = ""
+			source.FeedModified = ""
+		}
+		if req.FetchContent != nil {
+			source.FetchContent = *req.FetchContent
+		}
+	}
 
 	if err := s.sourceRepo.Update(source); err != nil {
 		return nil, err
@@ -142,7 +192,7 @@ func (s *WechatRSSService) DeleteSource(id uint) error {
 
 // === Crawl Operations ===
 
-// CrawlSource crawls a single source using WeChat API
+// CrawlSource crawls a single source using WeChat API or, for feed sources, its feed URL
 func (s *WechatRSSService) CrawlSource(sourceID uint) (*CrawlSourceResult, error) {
 	source, err := s.sourceRepo.FindByID(sourceID)
 	if err != nil {
@@ -164,6 +214,9 @@ func (s *WechatRSSService) CrawlSource(sourceID uint) (*CrawlSourceResult, error
 		s.crawlMutex.Unlock()
 	}()
 
+	if source.SourceType == model.WechatRSSSourceTypeFeed {
+		return s.crawlSourceViaFeed(source)
+	}
 	return s.crawlSourceViaWechatAPI(source)
 }
 
@@ -284,6 +337,307 @@ func (s *WechatRSSService) CrawlAllDueSources() ([]*CrawlSourceResult, error) {
 	return results, nil
 }
 
+// === Feed Sources ===
+
+// CreateFeedSource subscribes to an RSS, Atom or JSON Feed URL. The URL is
+// fetched once to validate it and to fill in the name, description and icon.
+func (s *WechatRSSService) CreateFeedSource(req *model.CreateFeedSourceRequest) (*model.WechatRSSSourceResponse, error) {
+	rssURL, err := normalizeFeedURL(req.RSSURL)
+	if err != nil {
+		return nil, err
+	}
+	frequency := req.CrawlFrequency
+	if frequency == 0 {
+		frequency = 60
+	}
+	if frequency < 5 || frequency > 1440 {
+		return nil, fmt.Errorf("crawl frequency must be between 5 and 1440 minutes")
+	}
+
+	exists, _ := s.sourceRepo.ExistsByRSSURL(rssURL)
+	if exists {
+		return nil, ErrWechatRSSSourceExists
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := s.feedFetcher.Fetch(ctx, rssURL, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWechatRSSFeedInvalid, err)
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = feed.Summarize(result.Feed.Title, 100)
+	}
+	if name == "" {
+		u, _ := url.Parse(rssURL)
+		name = u.Host
+	}
+	iconURL := result.Feed.IconURL
+	if len(iconURL) > 500 {
+		iconURL = ""
+	}
+
+	// fake_id 有唯一索引，feed 类型用随机占位值
+	placeholder, err := newFeedToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	source := &model.WechatRSSSource{
+		Name:           name,
+		FakeID:         "feed:" + placeholder,
+		RSSURL:         rssURL,
+		SourceType:     model.WechatRSSSourceTypeFeed,
+		CrawlFrequency: frequency,
+		NextCrawlAt:    &now,
+		Status:         model.WechatRSSSourceStatusActive,
+		Description:    feed.Summarize(result.Feed.Description, 500),
+		IconURL:        iconURL,
+		FetchContent:   req.FetchContent,
+	}
+	if err := s.sourceRepo.Create(source); err != nil {
+		s.logger.Error("创建订阅源失败", zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("Feed source created",
+		zap.Uint("source_id", source.ID),
+		zap.String("name", source.Name),
+		zap.String("rss_url", source.RSSURL),
+	)
+
+	// Trigger initial crawl asynchronously
+	go s.CrawlSource(source.ID)
+
+	return source.ToResponse(), nil
+}
+
+// crawlSourceViaFeed polls a feed source and stores items that have not been seen before
+func (s *WechatRSSService) crawlSourceViaFeed(source *model.WechatRSSSource) (*CrawlSourceResult, error) {
+	s.logger.Info("Starting feed crawl", zap.Uint("source_id", source.ID), zap.String("rss_url", source.RSSURL))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	result, err := s.feedFetcher.Fetch(ctx, source.RSSURL, source.FeedETag, source.FeedModified)
+	cancel()
+	if err != nil {
+		s.recordFeedError(source, err)
+		return nil, err
+	}
+
+	now := time.Now()
+	nextCrawl := now.Add(time.Duration(source.CrawlFrequency) * time.Minute)
+	if result.ETag != source.FeedETag || result.LastModified != source.FeedModified {
+		s.sourceRepo.UpdateFeedValidators(source.ID, result.ETag, result.LastModified)
+	}
+
+	total, newCount := 0, 0
+	if !result.NotModified {
+		total = len(result.Feed.Items)
+		base, _ := url.Parse(source.RSSURL)
+		for i := range result.Feed.Items {
+			if s.saveFeedItem(source, base, &result.Feed.Items[i]) {
+				newCount++
+			}
+		}
+	}
+
+	s.sourceRepo.UpdateCrawlTime(source.ID, now, nextCrawl)
+	s.sourceRepo.UpdateStatus(source.ID, model.WechatRSSSourceStatusActive, "")
+	s.sourceRepo.IncrementArticleCount(source.ID, newCount)
+
+	s.logger.Info("Feed crawl completed",
+		zap.Uint("source_id", source.ID),
+		zap.Bool("not_modified", result.NotModified),
+		zap.Int("total_items", total),
+		zap.Int("new_items", newCount),
+	)
+
+	return &CrawlSourceResult{
+		SourceID:    source.ID,
+		TotalItems:  total,
+		NewItems:    newCount,
+		CrawlTime:   now,
+		NextCrawlAt: nextCrawl,
+	}, nil
+}
+
+// saveFeedItem stores a feed item as an article and reports whether it was new
+func (s *WechatRSSService) saveFeedItem(source *model.WechatRSSSource, base *url.URL, item *feed.Item) bool {
+	link := resolveFeedURL(base, item.Link)
+	guid := feedArticleGUID(source.ID, item.ID, link)
+	if guid == "" {
+		return false
+	}
+	if exists, _ := s.articleRepo.ExistsByGUID(guid); exists {
+		return false
+	}
+
+	content := item.Content
+	if source.FetchContent && link != "" && utf8.RuneCountInString(feed.Summarize(content, 0)) < s.feedCfg.ContentMinLength {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		full, err := s.feedFetcher.FetchArticle(ctx, link)
+		cancel()
+		if err != nil {
+			s.logger.Warn("Failed to fetch full article", zap.String("link", link), zap.Error(err))
+		} else {
+			content = full
+		}
+	}
+
+	title := feed.Summarize(item.Title, 200)
+	if title == "" {
+		title = feed.Summarize(content, 50)
+	}
+	description := feed.Summarize(item.Summary, 300)
+	if description == "" {
+		description = feed.Summarize(content, 300)
+	}
+	author := item.Author
+	if author == "" {
+		author = source.Name
+	}
+	var pubDate *time.Time
+	if !item.Published.IsZero() {
+		pubDate = &item.Published
+	}
+	imageURL := resolveFeedURL(base, item.ImageURL)
+	if len(imageURL) > 1000 {
+		imageURL = ""
+	}
+	if len(link) > 1000 {
+		link = ""
+	}
+
+	article := &model.WechatRSSArticle{
+		SourceID:    source.ID,
+		GUID:        guid,
+		Title:       title,
+		Link:        link,
+		Description: description,
+		Content:     content,
+		Author:      feed.Summarize(author, 100),
+		ImageURL:    imageURL,
+		PubDate:     pubDate,
+		ReadStatus:  model.WechatRSSReadStatusUnread,
+	}
+	if err := s.articleRepo.Create(article); err != nil {
+		s.logger.Warn("Failed to create article", zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// recordFeedError counts a failed poll and backs off exponentially; after
+// MaxErrors consecutive failures the source is marked as error and no longer polled
+func (s *WechatRSSService) recordFeedError(source *model.WechatRSSSource, crawlErr error) {
+	errorCount := source.ErrorCount + 1
+	var status model.WechatRSSSourceStatus
+	if errorCount >= s.feedCfg.MaxErrors {
+		status = model.WechatRSSSourceStatusError
+	}
+
+	shift := errorCount
+	if shift > 5 {
+		shift = 5
+	}
+	backoff := time.Duration(source.CrawlFrequency) * time.Minute << shift
+	if backoff > 24*time.Hour {
+		backoff = 24 * time.Hour
+	}
+
+	s.logger.Warn("Feed crawl failed",
+		zap.Uint("source_id", source.ID),
+		zap.Int("error_count", errorCount),
+		zap.Duration("retry_in", backoff),
+		zap.Error(crawlErr),
+	)
+	s.sourceRepo.RecordCrawlError(source.ID, crawlErr.Error(), time.Now().Add(backoff), status)
+}
+
+// CrawlDueFeedSources crawls the feed sources whose next crawl time has passed
+func (s *WechatRSSService) CrawlDueFeedSources() ([]*CrawlSourceResult, error) {
+	if !s.syncMu.TryLock() {
+		return nil, ErrWechatRSSFeedPolling
+	}
+	defer s.syncMu.Unlock()
+
+	sources, err := s.sourceRepo.ListDueToCrawlByType(model.WechatRSSSourceTypeFeed)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*CrawlSourceResult, 0, len(sources))
+	for _, source := range sources {
+		result, err := s.CrawlSource(source.ID)
+		if err != nil {
+			continue
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// RunScheduled polls the feed sources that are due; each source is crawled on
+// its own CrawlFrequency. A poll still in progress is skipped.
+func (s *WechatRSSService) RunScheduled(ctx context.Context) error {
+	if _, err := s.CrawlDueFeedSources(); err != nil && !errors.Is(err, ErrWechatRSSFeedPolling) {
+		return err
+	}
+	return nil
+}
+
+// normalizeFeedURL validates a feed URL; only http and https are accepted
+func normalizeFeedURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("%w: %q", ErrWechatRSSFeedInvalid, raw)
+	}
+	if len(raw) > 500 {
+		return "", fmt.Errorf("%w: URL longer than 500 characters", ErrWechatRSSFeedInvalid)
+	}
+	return raw, nil
+}
+
+// resolveFeedURL makes a link found in a feed absolute
+func resolveFeedURL(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || base == nil {
+		return ref
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return base.ResolveReference(u).String()
+}
+
+// feedArticleGUID derives the article GUID of a feed item. Item IDs that are
+// URLs are kept as they are, so the same article reached through several
+// sources (or through the WeChat API, which uses the link) is stored once;
+// other IDs are only unique within their feed and get a source prefix.
+func feedArticleGUID(sourceID uint, itemID, link string) string {
+	id := strings.TrimSpace(itemID)
+	if id == "" {
+		id = link
+	}
+	if id == "" {
+		return ""
+	}
+	guid := id
+	if !strings.HasPrefix(id, "http://") && !strings.HasPrefix(id, "https://") {
+		guid = fmt.Sprintf("feed:%d:%s", sourceID, id)
+	}
+	if len(guid) > 500 {
+		guid = fmt.Sprintf("feed:%d:%x", sourceID, sha256.Sum256([]byte(id)))
+	}
+	return guid
+}
+
 // === Article Management ===
 
 // ListArticles lists articles with filtering and pagination
@@ -0,0 +1,146 @@
+// Package srs implements the spaced-repetition scheduling shared by flashcards
+// and wrong questions: FSRS (default) and the classic SM-2, a per-user FSRS
+// weight optimizer, and interval fuzzing with day-level load balancing.
+package srs
+
+import (
+	"math"
+	"time"
+)
+
+// Rating is the user's self-assessment of a review
+type Rating int
+
+const (
+	Again Rating = 1 // forgot
+	Hard  Rating = 2 // recalled with serious difficulty
+	Good  Rating = 3 // recalled after some hesitation
+	Easy  Rating = 4 // recalled perfectly
+)
+
+// Valid reports whether r is one of the four ratings
+func (r Rating) Valid() bool {
+	return r >= Again && r <= Easy
+}
+
+// RatingFromCorrect maps a plain right/wrong answer to a rating
+func RatingFromCorrect(correct bool) Rating {
+	if correct {
+		return Good
+	}
+	return Again
+}
+
+// State is the learning phase of a card
+type State string
+
+const (
+	StateNew        State = "new"
+	StateLearning   State = "learning"
+	StateReview     State = "review"
+	StateRelearning State = "relearning"
+)
+
+// Card is the scheduling state of one reviewable item. FSRS uses Stability and
+// Difficulty, SM-2 uses Ease and Interval; both keep the other fields current.
+type Card struct {
+	State      State
+	Stability  float64 // days until retrievability drops to 90%
+	Difficulty float64 // FSRS difficulty, 1 (easy) - 10 (hard)
+	Ease       float64 // SM-2 ease factor
+	Interval   int     // scheduled interval in days, 0 while (re)learning
+	Reps       int
+	Lapses     int
+	LastReview *time.Time
+	Due        time.Time
+}
+
+// ElapsedDays returns the days between the last review and now
+func (c Card) ElapsedDays(now time.Time) float64 {
+	if c.LastReview == nil {
+		return 0
+	}
+	days := now.Sub(*c.LastReview).Hours() / 24
+	return math.Max(days, 0)
+}
+
+// Scheduler computes the next state of a card after a review
+type Scheduler interface {
+	// Name identifies the algorithm in review logs
+	Name() string
+	// Next returns the card after being reviewed with rating at now
+	Next(card Card, rating Rating, now time.Time) Card
+	// Retrievability estimates the probability of recalling the card at now
+	Retrievability(card Card, now time.Time) float64
+}
+
+// Algorithm names
+const (
+	AlgorithmFSRS = "fsrs"
+	AlgorithmSM2  = "sm2"
+)
+
+// forgettingCurve is the power forgetting curve shared by FSRS and the SM-2
+// retrievability estimate: R(t, S) = (1 + factor·t/S)^decay, R(S, S) = 0.9
+func forgettingCurve(elapsedDays, stability float64) float64 {
+	if stability <= 0 {
+		return 0
+	}
+	return math.Pow(1+fsrsFactor*elapsedDays/stability, fsrsDecay)
+}
+
+// FuzzRange returns the day range an interval may be moved within without
+// noticeably changing retention, following Anki's fuzz buckets
+func FuzzRange(interval, maximum int) (int, int) {
+	if interval < 3 {
+		return interval, interval
+	}
+	ivl := float64(interval)
+	delta := 1.0
+	for _, r := range []struct{ start, end, factor float64 }{
+		{2.5, 7, 0.15},
+		{7, 20, 0.1},
+		{20, math.Inf(1), 0.05},
+	} {
+		delta += r.factor * math.Max(math.Min(ivl, r.end)-r.start, 0)
+	}
+	lo := int(math.Round(ivl - delta))
+	hi := int(math.Round(ivl + delta))
+	if lo < 2 {
+		lo = 2
+	}
+	if maximum > 0 && hi > maximum {
+		hi = maximum
+	}
+	if lo > hi {
+		lo = hi
+	}
+	return lo, hi
+}
+
+// Balance picks the day within the fuzz range of interval that has the fewest
+// reviews already due, preferring days closer to the ideal interval on ties.
+// load returns the number of items due on the given day offset.
+func Balance(interval, maximum int, load func(day int) int) int {
+	lo, hi := FuzzRange(interval, maximum)
+	best, bestLoad := interval, -1
+	for day := lo; day <= hi; day++ {
+		l := load(day)
+		if bestLoad < 0 || l < bestLoad ||
+			(l == bestLoad && absInt(day-interval) < absInt(best-interval)) {
+			best, bestLoad = day, l
+		}
+	}
+	return best
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
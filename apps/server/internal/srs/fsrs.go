@@ -0,0 +1,145 @@
+package srs
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	fsrsDecay  = -0.5
+	fsrsFactor = 19.0 / 81.0 // 0.9^(1/decay) - 1, so that R(S, S) = 0.9
+)
+
+// DefaultWeights are the published FSRS-4.5 default parameters
+var DefaultWeights = []float64{
+	0.4872, 1.4003, 3.7145, 13.8206, 5.1618, 1.2298, 0.8975, 0.031, 1.6474,
+	0.1367, 1.0461, 2.1072, 0.0793, 0.3246, 1.587, 0.2272, 2.8755,
+}
+
+// weightBounds keep optimized weights in the ranges the reference optimizer allows
+var weightBounds = [][2]float64{
+	{0.1, 100}, {0.1, 100}, {0.1, 100}, {0.1, 100}, {1, 10}, {0.1, 5}, {0.1, 5},
+	{0, 0.5}, {0, 3}, {0.1, 0.8}, {0.01, 2.5}, {0.5, 5}, {0.01, 0.2}, {0.01, 0.9},
+	{0.01, 2}, {0, 1}, {1, 6},
+}
+
+// FSRS is the Free Spaced Repetition Scheduler (v4.5)
+type FSRS struct {
+	Weights          []float64
+	RequestRetention float64       // target recall probability at the due date
+	MaximumInterval  int           // days
+	LearningStep     time.Duration // delay before re-showing a forgotten card
+}
+
+// NewFSRS creates an FSRS scheduler, falling back to defaults for invalid settings
+func NewFSRS(weights []float64, requestRetention float64, maximumInterval int) *FSRS {
+	if len(weights) != len(DefaultWeights) {
+		weights = DefaultWeights
+	}
+	if requestRetention <= 0.5 || requestRetention >= 1 {
+		requestRetention = 0.9
+	}
+	if maximumInterval <= 0 {
+		maximumInterval = 36500
+	}
+	return &FSRS{
+		Weights:          weights,
+		RequestRetention: requestRetention,
+		MaximumInterval:  maximumInterval,
+		LearningStep:     10 * time.Minute,
+	}
+}
+
+// Name implements Scheduler
+func (f *FSRS) Name() string { return AlgorithmFSRS }
+
+// Retrievability implements Scheduler
+func (f *FSRS) Retrievability(card Card, now time.Time) float64 {
+	if card.State == StateNew {
+		return 0
+	}
+	return forgettingCurve(card.ElapsedDays(now), card.Stability)
+}
+
+// Next implements Scheduler
+func (f *FSRS) Next(card Card, rating Rating, now time.Time) Card {
+	w := f.Weights
+	next := card
+	if next.State == StateNew || next.Stability <= 0 {
+		next.Difficulty = initDifficulty(w, rating)
+		next.Stability = initStability(w, rating)
+	} else {
+		r := forgettingCurve(card.ElapsedDays(now), card.Stability)
+		next.Difficulty = nextDifficulty(w, card.Difficulty, rating)
+		if rating == Again {
+			next.Stability = forgetStability(w, next.Difficulty, card.Stability, r)
+		} else {
+			next.Stability = recallStability(w, next.Difficulty, card.Stability, r, rating)
+		}
+	}
+
+	next.Reps++
+	reviewed := now
+	next.LastReview = &reviewed
+	if rating == Again {
+		if card.State != StateNew {
+			next.Lapses++
+			next.State = StateRelearning
+		} else {
+			next.State = StateLearning
+		}
+		next.Interval = 0
+		next.Due = now.Add(f.LearningStep)
+		return next
+	}
+
+	next.State = StateReview
+	next.Interval = f.interval(next.Stability)
+	next.Due = now.AddDate(0, 0, next.Interval)
+	next.Ease = easeFromDifficulty(next.Difficulty)
+	return next
+}
+
+// interval converts stability into whole days at the requested retention
+func (f *FSRS) interval(stability float64) int {
+	days := stability / fsrsFactor * (math.Pow(f.RequestRetention, 1/fsrsDecay) - 1)
+	return int(clamp(math.Round(days), 1, float64(f.MaximumInterval)))
+}
+
+func initStability(w []float64, rating Rating) float64 {
+	return math.Max(w[int(rating)-1], 0.1)
+}
+
+func initDifficulty(w []float64, rating Rating) float64 {
+	return clamp(w[4]-float64(rating-3)*w[5], 1, 10)
+}
+
+func nextDifficulty(w []float64, d float64, rating Rating) float64 {
+	next := d - w[6]*float64(rating-3)
+	// mean reversion towards the difficulty of a first "good"
+	next = w[7]*initDifficulty(w, Good) + (1-w[7])*next
+	return clamp(next, 1, 10)
+}
+
+func recallStability(w []float64, d, s, r float64, rating Rating) float64 {
+	hardPenalty, easyBonus := 1.0, 1.0
+	if rating == Hard {
+		hardPenalty = w[15]
+	}
+	if rating == Easy {
+		easyBonus = w[16]
+	}
+	growth := math.Exp(w[8]) * (11 - d) * math.Pow(s, -w[9]) * (math.Exp(w[10]*(1-r)) - 1) * hardPenalty * easyBonus
+	return s * (growth + 1)
+}
+
+func forgetStability(w []float64, d, s, r float64) float64 {
+	next := w[11] * math.Pow(d, -w[12]) * (math.Pow(s+1, w[13]) - 1) * math.Exp(w[14]*(1-r))
+	return clamp(next, 0.1, s)
+}
+
+// easeFromDifficulty keeps the SM-2 ease roughly in sync so that switching
+// algorithms does not reset a user's progress
+func easeFromDifficulty(d float64) float64 {
+	return math.Round((3.0-(d-1)*(1.7/9))*100) / 100
+}
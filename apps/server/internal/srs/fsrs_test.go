@@ -0,0 +1,137 @@
+package srs
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func almostEqual(got, want, tol float64) bool {
+	return math.Abs(got-want) <= tol
+}
+
+func TestForgettingCurve(t *testing.T) {
+	// by construction retrievability is 90% after one stability
+	if got := forgettingCurve(7, 7); !almostEqual(got, 0.9, 1e-12) {
+		t.Errorf("R(S, S) = %v, want 0.9", got)
+	}
+	if got := forgettingCurve(0, 3); got != 1 {
+		t.Errorf("R(0, S) = %v, want 1", got)
+	}
+	if got := forgettingCurve(4, 3.7145); !almostEqual(got, 0.8934995, 1e-6) {
+		t.Errorf("R(4, 3.7145) = %v", got)
+	}
+}
+
+func TestFSRSFirstReview(t *testing.T) {
+	f := NewFSRS(nil, 0.9, 0)
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		rating     Rating
+		stability  float64
+		difficulty float64
+		state      State
+		interval   int
+	}{
+		{Again, 0.4872, 7.6214, StateLearning, 0},
+		{Hard, 1.4003, 6.3916, StateReview, 1},
+		{Good, 3.7145, 5.1618, StateReview, 4},
+		{Easy, 13.8206, 3.932, StateReview, 14},
+	}
+	for _, tt := range tests {
+		card := f.Next(Card{State: StateNew}, tt.rating, now)
+		if !almostEqual(card.Stability, tt.stability, 1e-9) || !almostEqual(card.Difficulty, tt.difficulty, 1e-9) {
+			t.Errorf("rating %d: S = %v, D = %v, want %v, %v", tt.rating, card.Stability, card.Difficulty, tt.stability, tt.difficulty)
+		}
+		if card.State != tt.state || card.Interval != tt.interval {
+			t.Errorf("rating %d: state %s interval %d, want %s %d", tt.rating, card.State, card.Interval, tt.state, tt.interval)
+		}
+		if card.Reps != 1 || card.Lapses != 0 {
+			t.Errorf("rating %d: reps %d lapses %d", tt.rating, card.Reps, card.Lapses)
+		}
+	}
+
+	again := f.Next(Card{State: StateNew}, Again, now)
+	if !again.Due.Equal(now.Add(10 * time.Minute)) {
+		t.Errorf("forgotten new card due %v, want after the learning step", again.Due)
+	}
+}
+
+func TestFSRSSecondReview(t *testing.T) {
+	f := NewFSRS(nil, 0.9, 0)
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	card := f.Next(Card{State: StateNew}, Good, start)
+	now := start.AddDate(0, 0, 4)
+
+	tests := []struct {
+		rating     Rating
+		stability  float64
+		difficulty float64
+		interval   int
+	}{
+		{Again, 1.4006057, 6.901155, 0},
+		{Hard, 5.8595091, 6.0314775, 6},
+		{Good, 14.8081005, 5.1618, 15},
+		{Easy, 40.3660249, 4.2921225, 40},
+	}
+	for _, tt := range tests {
+		next := f.Next(card, tt.rating, now)
+		if !almostEqual(next.Stability, tt.stability, 1e-6) || !almostEqual(next.Difficulty, tt.difficulty, 1e-6) {
+			t.Errorf("rating %d: S = %v, D = %v, want %v, %v", tt.rating, next.Stability, next.Difficulty, tt.stability, tt.difficulty)
+		}
+		if next.Interval != tt.interval {
+			t.Errorf("rating %d: interval %d, want %d", tt.rating, next.Interval, tt.interval)
+		}
+	}
+
+	lapsed := f.Next(card, Again, now)
+	if lapsed.State != StateRelearning || lapsed.Lapses != 1 || lapsed.Reps != 2 {
+		t.Errorf("lapse: state %s lapses %d reps %d", lapsed.State, lapsed.Lapses, lapsed.Reps)
+	}
+}
+
+func TestFSRSInterval(t *testing.T) {
+	tests := []struct {
+		retention float64
+		maximum   int
+		stability float64
+		want      int
+	}{
+		{0.9, 36500, 10, 10}, // at 90% retention the interval equals the stability
+		{0.8, 36500, 10, 24}, // 23.98
+		{0.95, 36500, 10, 5}, // 4.67
+		{0.9, 36500, 0.2, 1},
+		{0.9, 30, 100, 30},
+	}
+	for _, tt := range tests {
+		f := NewFSRS(nil, tt.retention, tt.maximum)
+		if got := f.interval(tt.stability); got != tt.want {
+			t.Errorf("interval(S=%v, retention %v) = %d, want %d", tt.stability, tt.retention, got, tt.want)
+		}
+	}
+}
+
+func TestSM2(t *testing.T) {
+	s := SM2{MaximumInterval: 36500}
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	card := s.Next(Card{State: StateNew}, Good, now)
+	wantIntervals := []int{1, 6, 15, 38}
+	for i, want := range wantIntervals {
+		if i > 0 {
+			card = s.Next(card, Good, now)
+		}
+		if card.Interval != want || card.Ease != 2.5 {
+			t.Errorf("review %d: interval %d ease %v, want %d 2.5", i+1, card.Interval, card.Ease, want)
+		}
+	}
+
+	hard := s.Next(card, Hard, now)
+	if !almostEqual(hard.Ease, 2.36, 1e-9) {
+		t.Errorf("hard ease = %v, want 2.36", hard.Ease)
+	}
+	lapsed := s.Next(card, Again, now)
+	if lapsed.Interval != 1 || lapsed.State != StateRelearning || lapsed.Lapses != 1 {
+		t.Errorf("lapse: interval %d state %s lapses %d", lapsed.Interval, lapsed.State, lapsed.Lapses)
+	}
+}
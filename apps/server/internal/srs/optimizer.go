@@ -0,0 +1,121 @@
+package srs
+
+import (
+	"math"
+)
+
+// Review is one entry of an item's review history used for optimization
+type Review struct {
+	Rating      Rating
+	ElapsedDays float64 // days since the previous review of the same item, 0 for the first
+}
+
+// OptimizeResult reports the outcome of fitting FSRS weights to a history
+type OptimizeResult struct {
+	Weights     []float64
+	Loss        float64 // mean log loss of recall predictions with the fitted weights
+	DefaultLoss float64 // the same loss with the default weights
+	Samples     int     // reviews that contributed to the loss
+}
+
+const (
+	optimizerPasses   = 12
+	optimizerStep     = 0.1  // initial step as a fraction of each weight's range
+	optimizerPenalty  = 1e-3 // pull towards the defaults so sparse histories do not overfit
+	optimizerMinDelta = 1e-7
+)
+
+// Optimize fits FSRS weights to the review histories of one user by coordinate
+// descent on the log loss of the predicted retrievability at each review made
+// on a later day than the previous one. Same-day reviews update the memory
+// state but are not scored, as in the reference optimizer.
+func Optimize(histories [][]Review, initial []float64) OptimizeResult {
+	if len(initial) != len(DefaultWeights) {
+		initial = DefaultWeights
+	}
+	w := append([]float64(nil), initial...)
+	defaultLoss, samples := historyLoss(histories, DefaultWeights)
+	if samples == 0 {
+		return OptimizeResult{Weights: w, Loss: defaultLoss, DefaultLoss: defaultLoss}
+	}
+
+	objective := func(w []float64) float64 {
+		loss, _ := historyLoss(histories, w)
+		var penalty float64
+		for i := range w {
+			span := weightBounds[i][1] - weightBounds[i][0]
+			d := (w[i] - DefaultWeights[i]) / span
+			penalty += d * d
+		}
+		return loss + optimizerPenalty*penalty
+	}
+
+	best := objective(w)
+	steps := make([]float64, len(w))
+	for i := range steps {
+		steps[i] = (weightBounds[i][1] - weightBounds[i][0]) * optimizerStep
+	}
+	for pass := 0; pass < optimizerPasses; pass++ {
+		improved := false
+		for i := range w {
+			for _, dir := range []float64{1, -1} {
+				candidate := clamp(w[i]+dir*steps[i], weightBounds[i][0], weightBounds[i][1])
+				if candidate == w[i] {
+					continue
+				}
+				old := w[i]
+				w[i] = candidate
+				if loss := objective(w); loss < best-optimizerMinDelta {
+					best = loss
+					improved = true
+					break
+				}
+				w[i] = old
+			}
+		}
+		if !improved {
+			for i := range steps {
+				steps[i] /= 2
+			}
+		}
+	}
+
+	loss, _ := historyLoss(histories, w)
+	return OptimizeResult{Weights: w, Loss: loss, DefaultLoss: defaultLoss, Samples: samples}
+}
+
+// historyLoss replays each history with weights w and returns the mean log
+// loss of the recall predictions together with the number of scored reviews
+func historyLoss(histories [][]Review, w []float64) (float64, int) {
+	var total float64
+	var n int
+	for _, history := range histories {
+		if len(history) == 0 {
+			continue
+		}
+		s := initStability(w, history[0].Rating)
+		d := initDifficulty(w, history[0].Rating)
+		for _, review := range history[1:] {
+			r := forgettingCurve(review.ElapsedDays, s)
+			if review.ElapsedDays >= 1 {
+				p := clamp(r, 1e-4, 1-1e-4)
+				if review.Rating == Again {
+					total -= math.Log(1 - p)
+				} else {
+					total -= math.Log(p)
+				}
+				n++
+			}
+			d = nextDifficulty(w, d, review.Rating)
+			if review.Rating == Again {
+				s = forgetStability(w, d, s, r)
+			} else {
+				s = recallStability(w, d, s, r, review.Rating)
+			}
+		}
+	}
+	if n == 0 {
+		return 0, 0
+	}
+	return total / float64(n), n
+}
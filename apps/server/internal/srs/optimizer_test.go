@@ -0,0 +1,86 @@
+package srs
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestHistoryLoss(t *testing.T) {
+	// the first review only sets the memory state; the other two are scored
+	history := []Review{{Rating: Good}, {Rating: Good, ElapsedDays: 4}, {Rating: Again, ElapsedDays: 10}}
+	loss, n := historyLoss([][]Review{history}, DefaultWeights)
+	if n != 2 || !almostEqual(loss, 1.3796574, 1e-6) {
+		t.Errorf("historyLoss = %v over %d reviews, want 1.3796574 over 2", loss, n)
+	}
+
+	// same-day reviews are not scored
+	if _, n := historyLoss([][]Review{{{Rating: Again}, {Rating: Good, ElapsedDays: 0.1}}}, DefaultWeights); n != 0 {
+		t.Errorf("same-day review scored: %d", n)
+	}
+}
+
+// simulateHistories draws review histories from a user who remembers better
+// than the defaults assume: a first "good" gives 10 days of stability
+func simulateHistories(rng *rand.Rand, items int) ([][]Review, []float64) {
+	truth := append([]float64(nil), DefaultWeights...)
+	truth[2] = 10
+	histories := make([][]Review, 0, items)
+	for i := 0; i < items; i++ {
+		history := []Review{{Rating: Good}}
+		s, d := initStability(truth, Good), initDifficulty(truth, Good)
+		for k := 0; k < 4; k++ {
+			elapsed := math.Max(1, math.Round(s*(0.5+rng.Float64())))
+			r := forgettingCurve(elapsed, s)
+			rating := Good
+			if rng.Float64() > r {
+				rating = Again
+			}
+			history = append(history, Review{Rating: rating, ElapsedDays: elapsed})
+			d = nextDifficulty(truth, d, rating)
+			if rating == Again {
+				s = forgetStability(truth, d, s, r)
+			} else {
+				s = recallStability(truth, d, s, r, rating)
+			}
+		}
+		histories = append(histories, history)
+	}
+	return histories, truth
+}
+
+func TestOptimize(t *testing.T) {
+	histories, truth := simulateHistories(rand.New(rand.NewSource(1)), 400)
+	result := Optimize(histories, nil)
+
+	if result.Samples != 1600 {
+		t.Errorf("Samples = %d, want 1600", result.Samples)
+	}
+	if result.Loss >= result.DefaultLoss {
+		t.Errorf("fitted loss %v not below default loss %v", result.Loss, result.DefaultLoss)
+	}
+	if truthLoss, _ := historyLoss(histories, truth); result.Loss > truthLoss+0.01 {
+		t.Errorf("fitted loss %v, generating weights reach %v", result.Loss, truthLoss)
+	}
+	// the first-good stability moves from the default 3.7 towards 10
+	if math.Abs(result.Weights[2]-truth[2]) >= math.Abs(DefaultWeights[2]-truth[2])/2 {
+		t.Errorf("w[2] = %v, want close to %v", result.Weights[2], truth[2])
+	}
+	for i, w := range result.Weights {
+		if w < weightBounds[i][0] || w > weightBounds[i][1] {
+			t.Errorf("w[%d] = %v outside %v", i, w, weightBounds[i])
+		}
+	}
+}
+
+func TestOptimizeWithoutScoredReviews(t *testing.T) {
+	result := Optimize([][]Review{{{Rating: Good}}}, nil)
+	if result.Samples != 0 || len(result.Weights) != len(DefaultWeights) {
+		t.Fatalf("result = %+v", result)
+	}
+	for i, w := range result.Weights {
+		if w != DefaultWeights[i] {
+			t.Errorf("w[%d] = %v changed without data", i, w)
+		}
+	}
+}
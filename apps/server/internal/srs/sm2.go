@@ -0,0 +1,62 @@
+package srs
+
+import (
+	"math"
+	"time"
+)
+
+// SM2 is the classic SuperMemo-2 scheduler, kept for users who prefer it
+type SM2 struct {
+	MaximumInterval int // days
+}
+
+// Name implements Scheduler
+func (s SM2) Name() string { return AlgorithmSM2 }
+
+// Retrievability implements Scheduler, treating the interval as the stability
+func (s SM2) Retrievability(card Card, now time.Time) float64 {
+	if card.State == StateNew || card.Interval <= 0 {
+		return 0
+	}
+	return forgettingCurve(card.ElapsedDays(now), float64(card.Interval))
+}
+
+// Next implements Scheduler
+func (s SM2) Next(card Card, rating Rating, now time.Time) Card {
+	next := card
+	if next.Ease < 1.3 {
+		next.Ease = 2.5
+	}
+	next.Reps++
+	reviewed := now
+	next.LastReview = &reviewed
+
+	if rating == Again {
+		if card.State != StateNew {
+			next.Lapses++
+			next.State = StateRelearning
+		} else {
+			next.State = StateLearning
+		}
+		next.Interval = 1
+	} else {
+		switch {
+		case card.Interval <= 0 || card.State != StateReview:
+			next.Interval = 1
+		case card.Interval == 1:
+			next.Interval = 6
+		default:
+			next.Interval = int(math.Round(float64(card.Interval) * next.Ease))
+		}
+		// quality 3/4/5 for hard/good/easy
+		q := float64(rating) + 1
+		next.Ease = math.Max(next.Ease+(0.1-(5-q)*(0.08+(5-q)*0.02)), 1.3)
+		next.State = StateReview
+	}
+	if s.MaximumInterval > 0 && next.Interval > s.MaximumInterval {
+		next.Interval = s.MaximumInterval
+	}
+	next.Stability = float64(next.Interval)
+	next.Due = now.AddDate(0, 0, next.Interval)
+	return next
+}
@@ -0,0 +1,77 @@
+// Package storage keeps downloaded files in a content-addressed blob store.
+// Blobs are keyed by the SHA-256 of their content, so identical files
+// fetched from different sites are stored once.
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNotFound is returned when a blob does not exist in the store
+var ErrNotFound = errors.New("blob not found")
+
+// Backend names accepted in Config.Backend
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+// BlobStore stores immutable blobs under content-derived keys
+type BlobStore interface {
+	// Put writes a blob; writing an existing key again is a no-op in effect
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get reads a blob, returning ErrNotFound when it is missing
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Exists reports whether a blob is present
+	Exists(ctx context.Context, key string) (bool, error)
+	// Backend returns the backend name recorded with each blob
+	Backend() string
+}
+
+// Config selects and configures a blob store backend
+type Config struct {
+	Backend  string // local | s3
+	LocalDir string // root directory of the local backend
+
+	// S3-compatible backend (AWS S3, MinIO, OSS/COS with S3 API)
+	Endpoint  string // host[:port], empty for AWS S3 in Region
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+	PathStyle bool // bucket in the path instead of the host name, required by MinIO
+	Timeout   time.Duration
+}
+
+// New creates the blob store described by config
+func New(config Config) (BlobStore, error) {
+	switch config.Backend {
+	case "", BackendLocal:
+		return NewLocalStore(config.LocalDir)
+	case BackendS3:
+		return NewS3Store(config)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", config.Backend)
+	}
+}
+
+// SHA256 returns the hex SHA-256 digest of data
+func SHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Key returns the blob key of a SHA-256 digest. Two levels of fan-out keep
+// directories small on the local backend.
+func Key(sha string) string {
+	if len(sha) < 4 {
+		return "sha256/" + sha
+	}
+	return "sha256/" + sha[:2] + "/" + sha[2:4] + "/" + sha
+}